@@ -224,6 +224,7 @@ type GetOriginatorByIDOpts struct {
 	XRequestID optional.String
 	Offset     optional.Int32
 	Limit      optional.Int32
+	Unmask     optional.Bool
 }
 
 /*
@@ -261,6 +262,9 @@ func (a *OriginatorsApiService) GetOriginatorByID(ctx _context.Context, originat
 	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
 		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
 	}
+	if localVarOptionals != nil && localVarOptionals.Unmask.IsSet() {
+		localVarQueryParams.Add("unmask", parameterToString(localVarOptionals.Unmask.Value(), ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -333,6 +337,7 @@ type GetOriginatorsOpts struct {
 	XRequestID optional.String
 	Offset     optional.Int32
 	Limit      optional.Int32
+	Unmask     optional.Bool
 }
 
 /*
@@ -368,6 +373,9 @@ func (a *OriginatorsApiService) GetOriginators(ctx _context.Context, xUserID str
 	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
 		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
 	}
+	if localVarOptionals != nil && localVarOptionals.Unmask.IsSet() {
+		localVarQueryParams.Add("unmask", parameterToString(localVarOptionals.Unmask.Value(), ""))
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -516,7 +524,7 @@ func (a *OriginatorsApiService) UpdateOriginator(ctx _context.Context, originato
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
-		if localVarHTTPResponse.StatusCode == 201 {
+		if localVarHTTPResponse.StatusCode == 200 {
 			var v Originator
 			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
 			if err != nil {
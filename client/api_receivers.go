@@ -27,6 +27,117 @@ var (
 // ReceiversApiService ReceiversApi service
 type ReceiversApiService service
 
+// AddAuthorizationOpts Optional parameters for the method 'AddAuthorization'
+type AddAuthorizationOpts struct {
+	XRequestID optional.String
+}
+
+/*
+AddAuthorization Record a Receiver's authorization for an Originator to debit them. Pull Transfers must reference an active Authorization.
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param xUserID Moov User ID
+ * @param createAuthorization
+ * @param optional nil or *AddAuthorizationOpts - Optional Parameters:
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+@return Authorization
+*/
+func (a *ReceiversApiService) AddAuthorization(ctx _context.Context, receiverID string, xUserID string, createAuthorization CreateAuthorization, localVarOptionals *AddAuthorizationOpts) (Authorization, *_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodPost
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  Authorization
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/authorizations"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/json"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	// body params
+	localVarPostBody = &createAuthorization
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 200 {
+			var v Authorization
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
 // AddReceiversOpts Optional parameters for the method 'AddReceivers'
 type AddReceiversOpts struct {
 	XIdempotencyKey optional.String
@@ -141,24 +252,23 @@ func (a *ReceiversApiService) AddReceivers(ctx _context.Context, xUserID string,
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
-// DeleteReceiverOpts Optional parameters for the method 'DeleteReceiver'
-type DeleteReceiverOpts struct {
-	Authorization optional.String
-	XRequestID    optional.String
+// ConfirmReceiverEmailOpts Optional parameters for the method 'ConfirmReceiverEmail'
+type ConfirmReceiverEmailOpts struct {
+	XRequestID optional.String
 }
 
 /*
-DeleteReceiver Permanently deletes a receiver and associated depositories and transfers. It cannot be undone. Immediately cancels any active Transfers for the receiver.
+ConfirmReceiverEmail Confirm the token emailed to a Receiver and mark the Receiver as verified
  * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
  * @param receiverID Receiver ID
  * @param xUserID Moov User ID
- * @param optional nil or *DeleteReceiverOpts - Optional Parameters:
- * @param "Authorization" (optional.String) -  OAuth2 Bearer token
+ * @param confirmReceiverEmail
+ * @param optional nil or *ConfirmReceiverEmailOpts - Optional Parameters:
  * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
 */
-func (a *ReceiversApiService) DeleteReceiver(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *DeleteReceiverOpts) (*_nethttp.Response, error) {
+func (a *ReceiversApiService) ConfirmReceiverEmail(ctx _context.Context, receiverID string, xUserID string, confirmReceiverEmail ConfirmReceiverEmail, localVarOptionals *ConfirmReceiverEmailOpts) (*_nethttp.Response, error) {
 	var (
-		localVarHTTPMethod   = _nethttp.MethodDelete
+		localVarHTTPMethod   = _nethttp.MethodPost
 		localVarPostBody     interface{}
 		localVarFormFileName string
 		localVarFileName     string
@@ -166,7 +276,7 @@ func (a *ReceiversApiService) DeleteReceiver(ctx _context.Context, receiverID st
 	)
 
 	// create path and map variables
-	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}"
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/verification/email/confirm"
 	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -174,7 +284,7 @@ func (a *ReceiversApiService) DeleteReceiver(ctx _context.Context, receiverID st
 	localVarFormParams := _neturl.Values{}
 
 	// to determine the Content-Type header
-	localVarHTTPContentTypes := []string{}
+	localVarHTTPContentTypes := []string{"application/json"}
 
 	// set Content-Type header
 	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
@@ -183,20 +293,19 @@ func (a *ReceiversApiService) DeleteReceiver(ctx _context.Context, receiverID st
 	}
 
 	// to determine the Accept header
-	localVarHTTPHeaderAccepts := []string{}
+	localVarHTTPHeaderAccepts := []string{"application/json"}
 
 	// set Accept header
 	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
 	if localVarHTTPHeaderAccept != "" {
 		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
 	}
-	if localVarOptionals != nil && localVarOptionals.Authorization.IsSet() {
-		localVarHeaderParams["Authorization"] = parameterToString(localVarOptionals.Authorization.Value(), "")
-	}
 	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
 		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
 	}
 	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	// body params
+	localVarPostBody = &confirmReceiverEmail
 	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
 	if err != nil {
 		return nil, err
@@ -218,56 +327,53 @@ func (a *ReceiversApiService) DeleteReceiver(ctx _context.Context, receiverID st
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+		}
 		return localVarHTTPResponse, newErr
 	}
 
 	return localVarHTTPResponse, nil
 }
 
-// GetDepositoriesByIDOpts Optional parameters for the method 'GetDepositoriesByID'
-type GetDepositoriesByIDOpts struct {
-	Offset     optional.Int32
-	Limit      optional.Int32
-	XRequestID optional.String
+// DeleteReceiverOpts Optional parameters for the method 'DeleteReceiver'
+type DeleteReceiverOpts struct {
+	Authorization optional.String
+	XRequestID    optional.String
 }
 
 /*
-GetDepositoriesByID Get a Depository accounts for a Receiver based on it's ID
+DeleteReceiver Permanently deletes a receiver and associated depositories and transfers. It cannot be undone. Immediately cancels any active Transfers for the receiver.
  * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
  * @param receiverID Receiver ID
- * @param depositoryID Depository ID
  * @param xUserID Moov User ID
- * @param optional nil or *GetDepositoriesByIDOpts - Optional Parameters:
- * @param "Offset" (optional.Int32) -  The number of items to skip before starting to collect the result set
- * @param "Limit" (optional.Int32) -  The number of items to return
+ * @param optional nil or *DeleteReceiverOpts - Optional Parameters:
+ * @param "Authorization" (optional.String) -  OAuth2 Bearer token
  * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
-@return Depository
 */
-func (a *ReceiversApiService) GetDepositoriesByID(ctx _context.Context, receiverID string, depositoryID string, xUserID string, localVarOptionals *GetDepositoriesByIDOpts) (Depository, *_nethttp.Response, error) {
+func (a *ReceiversApiService) DeleteReceiver(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *DeleteReceiverOpts) (*_nethttp.Response, error) {
 	var (
-		localVarHTTPMethod   = _nethttp.MethodGet
+		localVarHTTPMethod   = _nethttp.MethodDelete
 		localVarPostBody     interface{}
 		localVarFormFileName string
 		localVarFileName     string
 		localVarFileBytes    []byte
-		localVarReturnValue  Depository
 	)
 
 	// create path and map variables
-	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/depositories/{depositoryID}"
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}"
 	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
-	localVarPath = strings.Replace(localVarPath, "{"+"depositoryID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", depositoryID)), -1)
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := _neturl.Values{}
 	localVarFormParams := _neturl.Values{}
 
-	if localVarOptionals != nil && localVarOptionals.Offset.IsSet() {
-		localVarQueryParams.Add("offset", parameterToString(localVarOptionals.Offset.Value(), ""))
-	}
-	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
-		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
-	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -278,31 +384,34 @@ func (a *ReceiversApiService) GetDepositoriesByID(ctx _context.Context, receiver
 	}
 
 	// to determine the Accept header
-	localVarHTTPHeaderAccepts := []string{"application/json"}
+	localVarHTTPHeaderAccepts := []string{}
 
 	// set Accept header
 	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
 	if localVarHTTPHeaderAccept != "" {
 		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
 	}
+	if localVarOptionals != nil && localVarOptionals.Authorization.IsSet() {
+		localVarHeaderParams["Authorization"] = parameterToString(localVarOptionals.Authorization.Value(), "")
+	}
 	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
 		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
 	}
 	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
 	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
 	if err != nil {
-		return localVarReturnValue, nil, err
+		return nil, err
 	}
 
 	localVarHTTPResponse, err := a.client.callAPI(r)
 	if err != nil || localVarHTTPResponse == nil {
-		return localVarReturnValue, localVarHTTPResponse, err
+		return localVarHTTPResponse, err
 	}
 
 	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
 	localVarHTTPResponse.Body.Close()
 	if err != nil {
-		return localVarReturnValue, localVarHTTPResponse, err
+		return localVarHTTPResponse, err
 	}
 
 	if localVarHTTPResponse.StatusCode >= 300 {
@@ -310,73 +419,46 @@ func (a *ReceiversApiService) GetDepositoriesByID(ctx _context.Context, receiver
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
-		if localVarHTTPResponse.StatusCode == 200 {
-			var v Depository
-			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
-			if err != nil {
-				newErr.error = err.Error()
-				return localVarReturnValue, localVarHTTPResponse, newErr
-			}
-			newErr.model = v
-			return localVarReturnValue, localVarHTTPResponse, newErr
-		}
-		return localVarReturnValue, localVarHTTPResponse, newErr
-	}
-
-	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
-	if err != nil {
-		newErr := GenericOpenAPIError{
-			body:  localVarBody,
-			error: err.Error(),
-		}
-		return localVarReturnValue, localVarHTTPResponse, newErr
+		return localVarHTTPResponse, newErr
 	}
 
-	return localVarReturnValue, localVarHTTPResponse, nil
+	return localVarHTTPResponse, nil
 }
 
-// GetDepositoriesByReceiverIDOpts Optional parameters for the method 'GetDepositoriesByReceiverID'
-type GetDepositoriesByReceiverIDOpts struct {
-	Offset     optional.Int32
-	Limit      optional.Int32
+// GetAuthorizationByIDOpts Optional parameters for the method 'GetAuthorizationByID'
+type GetAuthorizationByIDOpts struct {
 	XRequestID optional.String
 }
 
 /*
-GetDepositoriesByReceiverID Get a list of Depository accounts for a Receiver
+GetAuthorizationByID Get a debit Authorization for a Receiver
  * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
  * @param receiverID Receiver ID
+ * @param authorizationID Authorization ID
  * @param xUserID Moov User ID
- * @param optional nil or *GetDepositoriesByReceiverIDOpts - Optional Parameters:
- * @param "Offset" (optional.Int32) -  The number of items to skip before starting to collect the result set
- * @param "Limit" (optional.Int32) -  The number of items to return
+ * @param optional nil or *GetAuthorizationByIDOpts - Optional Parameters:
  * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
-@return []Depository
+@return Authorization
 */
-func (a *ReceiversApiService) GetDepositoriesByReceiverID(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *GetDepositoriesByReceiverIDOpts) ([]Depository, *_nethttp.Response, error) {
+func (a *ReceiversApiService) GetAuthorizationByID(ctx _context.Context, receiverID string, authorizationID string, xUserID string, localVarOptionals *GetAuthorizationByIDOpts) (Authorization, *_nethttp.Response, error) {
 	var (
 		localVarHTTPMethod   = _nethttp.MethodGet
 		localVarPostBody     interface{}
 		localVarFormFileName string
 		localVarFileName     string
 		localVarFileBytes    []byte
-		localVarReturnValue  []Depository
+		localVarReturnValue  Authorization
 	)
 
 	// create path and map variables
-	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/depositories"
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/authorizations/{authorizationID}"
 	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"authorizationID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", authorizationID)), -1)
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := _neturl.Values{}
 	localVarFormParams := _neturl.Values{}
 
-	if localVarOptionals != nil && localVarOptionals.Offset.IsSet() {
-		localVarQueryParams.Add("offset", parameterToString(localVarOptionals.Offset.Value(), ""))
-	}
-	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
-		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
-	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -420,13 +502,14 @@ func (a *ReceiversApiService) GetDepositoriesByReceiverID(ctx _context.Context,
 			error: localVarHTTPResponse.Status,
 		}
 		if localVarHTTPResponse.StatusCode == 200 {
-			var v []Depository
+			var v Authorization
 			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
 			if err != nil {
 				newErr.error = err.Error()
 				return localVarReturnValue, localVarHTTPResponse, newErr
 			}
 			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
 		}
 		return localVarReturnValue, localVarHTTPResponse, newErr
 	}
@@ -443,48 +526,38 @@ func (a *ReceiversApiService) GetDepositoriesByReceiverID(ctx _context.Context,
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
-// GetReceiverByIDOpts Optional parameters for the method 'GetReceiverByID'
-type GetReceiverByIDOpts struct {
-	Offset     optional.Int32
-	Limit      optional.Int32
+// GetAuthorizationsOpts Optional parameters for the method 'GetAuthorizations'
+type GetAuthorizationsOpts struct {
 	XRequestID optional.String
 }
 
 /*
-GetReceiverByID Get a Receiver by ID
+GetAuthorizations Get a list of debit Authorizations for a Receiver
  * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
  * @param receiverID Receiver ID
  * @param xUserID Moov User ID
- * @param optional nil or *GetReceiverByIDOpts - Optional Parameters:
- * @param "Offset" (optional.Int32) -  The number of items to skip before starting to collect the result set
- * @param "Limit" (optional.Int32) -  The number of items to return
+ * @param optional nil or *GetAuthorizationsOpts - Optional Parameters:
  * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
-@return Receiver
+@return []Authorization
 */
-func (a *ReceiversApiService) GetReceiverByID(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *GetReceiverByIDOpts) (Receiver, *_nethttp.Response, error) {
+func (a *ReceiversApiService) GetAuthorizations(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *GetAuthorizationsOpts) ([]Authorization, *_nethttp.Response, error) {
 	var (
 		localVarHTTPMethod   = _nethttp.MethodGet
 		localVarPostBody     interface{}
 		localVarFormFileName string
 		localVarFileName     string
 		localVarFileBytes    []byte
-		localVarReturnValue  Receiver
+		localVarReturnValue  []Authorization
 	)
 
 	// create path and map variables
-	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}"
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/authorizations"
 	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := _neturl.Values{}
 	localVarFormParams := _neturl.Values{}
 
-	if localVarOptionals != nil && localVarOptionals.Offset.IsSet() {
-		localVarQueryParams.Add("offset", parameterToString(localVarOptionals.Offset.Value(), ""))
-	}
-	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
-		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
-	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -528,7 +601,7 @@ func (a *ReceiversApiService) GetReceiverByID(ctx _context.Context, receiverID s
 			error: localVarHTTPResponse.Status,
 		}
 		if localVarHTTPResponse.StatusCode == 200 {
-			var v Receiver
+			var v []Authorization
 			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
 			if err != nil {
 				newErr.error = err.Error()
@@ -552,35 +625,39 @@ func (a *ReceiversApiService) GetReceiverByID(ctx _context.Context, receiverID s
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
-// GetReceiversOpts Optional parameters for the method 'GetReceivers'
-type GetReceiversOpts struct {
+// GetDepositoriesByIDOpts Optional parameters for the method 'GetDepositoriesByID'
+type GetDepositoriesByIDOpts struct {
 	Offset     optional.Int32
 	Limit      optional.Int32
 	XRequestID optional.String
 }
 
 /*
-GetReceivers Gets a list of Receivers
+GetDepositoriesByID Get a Depository accounts for a Receiver based on it's ID
  * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param depositoryID Depository ID
  * @param xUserID Moov User ID
- * @param optional nil or *GetReceiversOpts - Optional Parameters:
+ * @param optional nil or *GetDepositoriesByIDOpts - Optional Parameters:
  * @param "Offset" (optional.Int32) -  The number of items to skip before starting to collect the result set
  * @param "Limit" (optional.Int32) -  The number of items to return
  * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
-@return []Receiver
+@return Depository
 */
-func (a *ReceiversApiService) GetReceivers(ctx _context.Context, xUserID string, localVarOptionals *GetReceiversOpts) ([]Receiver, *_nethttp.Response, error) {
+func (a *ReceiversApiService) GetDepositoriesByID(ctx _context.Context, receiverID string, depositoryID string, xUserID string, localVarOptionals *GetDepositoriesByIDOpts) (Depository, *_nethttp.Response, error) {
 	var (
 		localVarHTTPMethod   = _nethttp.MethodGet
 		localVarPostBody     interface{}
 		localVarFormFileName string
 		localVarFileName     string
 		localVarFileBytes    []byte
-		localVarReturnValue  []Receiver
+		localVarReturnValue  Depository
 	)
 
 	// create path and map variables
-	localVarPath := a.client.cfg.BasePath + "/receivers"
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/depositories/{depositoryID}"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"depositoryID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", depositoryID)), -1)
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := _neturl.Values{}
@@ -635,13 +712,14 @@ func (a *ReceiversApiService) GetReceivers(ctx _context.Context, xUserID string,
 			error: localVarHTTPResponse.Status,
 		}
 		if localVarHTTPResponse.StatusCode == 200 {
-			var v []Receiver
+			var v Depository
 			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
 			if err != nil {
 				newErr.error = err.Error()
 				return localVarReturnValue, localVarHTTPResponse, newErr
 			}
 			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
 		}
 		return localVarReturnValue, localVarHTTPResponse, newErr
 	}
@@ -658,6 +736,739 @@ func (a *ReceiversApiService) GetReceivers(ctx _context.Context, xUserID string,
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
+// GetDepositoriesByReceiverIDOpts Optional parameters for the method 'GetDepositoriesByReceiverID'
+type GetDepositoriesByReceiverIDOpts struct {
+	Offset     optional.Int32
+	Limit      optional.Int32
+	XRequestID optional.String
+}
+
+/*
+GetDepositoriesByReceiverID Get a list of Depository accounts for a Receiver
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param xUserID Moov User ID
+ * @param optional nil or *GetDepositoriesByReceiverIDOpts - Optional Parameters:
+ * @param "Offset" (optional.Int32) -  The number of items to skip before starting to collect the result set
+ * @param "Limit" (optional.Int32) -  The number of items to return
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+@return []Depository
+*/
+func (a *ReceiversApiService) GetDepositoriesByReceiverID(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *GetDepositoriesByReceiverIDOpts) ([]Depository, *_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodGet
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  []Depository
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/depositories"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	if localVarOptionals != nil && localVarOptionals.Offset.IsSet() {
+		localVarQueryParams.Add("offset", parameterToString(localVarOptionals.Offset.Value(), ""))
+	}
+	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
+		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
+	}
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 200 {
+			var v []Depository
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+// GetReceiverByIDOpts Optional parameters for the method 'GetReceiverByID'
+type GetReceiverByIDOpts struct {
+	Offset     optional.Int32
+	Limit      optional.Int32
+	Unmask     optional.Bool
+	XRequestID optional.String
+}
+
+/*
+GetReceiverByID Get a Receiver by ID
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param xUserID Moov User ID
+ * @param optional nil or *GetReceiverByIDOpts - Optional Parameters:
+ * @param "Offset" (optional.Int32) -  The number of items to skip before starting to collect the result set
+ * @param "Limit" (optional.Int32) -  The number of items to return
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+@return Receiver
+*/
+func (a *ReceiversApiService) GetReceiverByID(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *GetReceiverByIDOpts) (Receiver, *_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodGet
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  Receiver
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	if localVarOptionals != nil && localVarOptionals.Offset.IsSet() {
+		localVarQueryParams.Add("offset", parameterToString(localVarOptionals.Offset.Value(), ""))
+	}
+	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
+		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
+	}
+	if localVarOptionals != nil && localVarOptionals.Unmask.IsSet() {
+		localVarQueryParams.Add("unmask", parameterToString(localVarOptionals.Unmask.Value(), ""))
+	}
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 200 {
+			var v Receiver
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+// GetReceiversOpts Optional parameters for the method 'GetReceivers'
+type GetReceiversOpts struct {
+	Offset     optional.Int32
+	Limit      optional.Int32
+	Email      optional.String
+	Unmask     optional.Bool
+	XRequestID optional.String
+}
+
+/*
+GetReceivers Gets a list of Receivers
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param xUserID Moov User ID
+ * @param optional nil or *GetReceiversOpts - Optional Parameters:
+ * @param "Offset" (optional.Int32) -  The number of items to skip before starting to collect the result set
+ * @param "Limit" (optional.Int32) -  The number of items to return
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+@return []Receiver
+*/
+func (a *ReceiversApiService) GetReceivers(ctx _context.Context, xUserID string, localVarOptionals *GetReceiversOpts) ([]Receiver, *_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodGet
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  []Receiver
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	if localVarOptionals != nil && localVarOptionals.Offset.IsSet() {
+		localVarQueryParams.Add("offset", parameterToString(localVarOptionals.Offset.Value(), ""))
+	}
+	if localVarOptionals != nil && localVarOptionals.Limit.IsSet() {
+		localVarQueryParams.Add("limit", parameterToString(localVarOptionals.Limit.Value(), ""))
+	}
+	if localVarOptionals != nil && localVarOptionals.Email.IsSet() {
+		localVarQueryParams.Add("email", parameterToString(localVarOptionals.Email.Value(), ""))
+	}
+	if localVarOptionals != nil && localVarOptionals.Unmask.IsSet() {
+		localVarQueryParams.Add("unmask", parameterToString(localVarOptionals.Unmask.Value(), ""))
+	}
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 200 {
+			var v []Receiver
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+// LinkReceiverDepositoryOpts Optional parameters for the method 'LinkReceiverDepository'
+type LinkReceiverDepositoryOpts struct {
+	XRequestID optional.String
+}
+
+/*
+LinkReceiverDepository Link a Depository to a Receiver so Transfers can be made to it. A Receiver's defaultDepository is always linked.
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param depositoryID Depository ID
+ * @param xUserID Moov User ID
+ * @param optional nil or *LinkReceiverDepositoryOpts - Optional Parameters:
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+@return Depository
+*/
+func (a *ReceiversApiService) LinkReceiverDepository(ctx _context.Context, receiverID string, depositoryID string, xUserID string, localVarOptionals *LinkReceiverDepositoryOpts) (Depository, *_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodPut
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  Depository
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/depositories/{depositoryID}"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"depositoryID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", depositoryID)), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 200 {
+			var v Depository
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+// RevokeAuthorizationOpts Optional parameters for the method 'RevokeAuthorization'
+type RevokeAuthorizationOpts struct {
+	XRequestID          optional.String
+	RevokeAuthorization optional.Interface
+}
+
+/*
+RevokeAuthorization Revoke a debit Authorization so it can't be used for new Transfers. Authorizations are also revoked when a Transfer is returned with R07 or R10.
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param authorizationID Authorization ID
+ * @param xUserID Moov User ID
+ * @param optional nil or *RevokeAuthorizationOpts - Optional Parameters:
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+ * @param "RevokeAuthorization" (optional.Interface of RevokeAuthorization) -
+@return Authorization
+*/
+func (a *ReceiversApiService) RevokeAuthorization(ctx _context.Context, receiverID string, authorizationID string, xUserID string, localVarOptionals *RevokeAuthorizationOpts) (Authorization, *_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodPost
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+		localVarReturnValue  Authorization
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/authorizations/{authorizationID}/revoke"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"authorizationID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", authorizationID)), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/json"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	// body params
+	if localVarOptionals != nil && localVarOptionals.RevokeAuthorization.IsSet() {
+		localVarOptionalRevokeAuthorization, localVarOptionalRevokeAuthorizationok := localVarOptionals.RevokeAuthorization.Value().(RevokeAuthorization)
+		if !localVarOptionalRevokeAuthorizationok {
+			return localVarReturnValue, nil, reportError("revokeAuthorization should be RevokeAuthorization")
+		}
+		localVarPostBody = &localVarOptionalRevokeAuthorization
+	}
+
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 200 {
+			var v Authorization
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+// SendReceiverVerificationEmailOpts Optional parameters for the method 'SendReceiverVerificationEmail'
+type SendReceiverVerificationEmailOpts struct {
+	XRequestID optional.String
+}
+
+/*
+SendReceiverVerificationEmail Email a signed, expiring token to an unverified Receiver. Confirming the token verifies the Receiver, which is required for pull Transfers.
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param xUserID Moov User ID
+ * @param optional nil or *SendReceiverVerificationEmailOpts - Optional Parameters:
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+*/
+func (a *ReceiversApiService) SendReceiverVerificationEmail(ctx _context.Context, receiverID string, xUserID string, localVarOptionals *SendReceiverVerificationEmailOpts) (*_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodPost
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/verification/email"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+		}
+		return localVarHTTPResponse, newErr
+	}
+
+	return localVarHTTPResponse, nil
+}
+
+// UnlinkReceiverDepositoryOpts Optional parameters for the method 'UnlinkReceiverDepository'
+type UnlinkReceiverDepositoryOpts struct {
+	XRequestID optional.String
+}
+
+/*
+UnlinkReceiverDepository Unlink a Depository from a Receiver. A Receiver's defaultDepository cannot be unlinked.
+ * @param ctx _context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ * @param receiverID Receiver ID
+ * @param depositoryID Depository ID
+ * @param xUserID Moov User ID
+ * @param optional nil or *UnlinkReceiverDepositoryOpts - Optional Parameters:
+ * @param "XRequestID" (optional.String) -  Optional Request ID allows application developer to trace requests through the systems logs
+*/
+func (a *ReceiversApiService) UnlinkReceiverDepository(ctx _context.Context, receiverID string, depositoryID string, xUserID string, localVarOptionals *UnlinkReceiverDepositoryOpts) (*_nethttp.Response, error) {
+	var (
+		localVarHTTPMethod   = _nethttp.MethodDelete
+		localVarPostBody     interface{}
+		localVarFormFileName string
+		localVarFileName     string
+		localVarFileBytes    []byte
+	)
+
+	// create path and map variables
+	localVarPath := a.client.cfg.BasePath + "/receivers/{receiverID}/depositories/{depositoryID}"
+	localVarPath = strings.Replace(localVarPath, "{"+"receiverID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", receiverID)), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"depositoryID"+"}", _neturl.QueryEscape(fmt.Sprintf("%v", depositoryID)), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := _neturl.Values{}
+	localVarFormParams := _neturl.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if localVarOptionals != nil && localVarOptionals.XRequestID.IsSet() {
+		localVarHeaderParams["X-Request-ID"] = parameterToString(localVarOptionals.XRequestID.Value(), "")
+	}
+	localVarHeaderParams["X-User-ID"] = parameterToString(xUserID, "")
+	r, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, localVarFormFileName, localVarFileName, localVarFileBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(r)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarHTTPResponse, err
+	}
+
+	localVarBody, err := _ioutil.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	if err != nil {
+		return localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+			newErr.model = v
+		}
+		return localVarHTTPResponse, newErr
+	}
+
+	return localVarHTTPResponse, nil
+}
+
 // UpdateReceiverOpts Optional parameters for the method 'UpdateReceiver'
 type UpdateReceiverOpts struct {
 	XIdempotencyKey optional.String
@@ -0,0 +1,40 @@
+/*
+ * Paygate API
+ *
+ * Paygate is a RESTful API enabling Automated Clearing House ([ACH](https://en.wikipedia.org/wiki/Automated_Clearing_House)) transactions to be submitted and received without a deep understanding of a full NACHA file specification.
+ *
+ * API version: v1
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+// Authorization struct for Authorization
+type Authorization struct {
+	// Authorization ID
+	Id string `json:"id,omitempty"`
+	// ID of the Receiver who granted this Authorization
+	Receiver string `json:"receiver,omitempty"`
+	// ID of the Originator allowed to debit the Receiver
+	Originator string `json:"originator,omitempty"`
+	// How the Receiver gave their authorization. TEL transfers require oral and WEB transfers require online authorizations, all others require written.
+	Type string `json:"type,omitempty"`
+	// Identifies the proof of authorization, such as a signed document or the recording of an oral authorization. Required for oral authorizations.
+	Reference string `json:"reference,omitempty"`
+	// Whether the Authorization allows a single Transfer or recurring Transfers
+	Scope string `json:"scope,omitempty"`
+	// Optional maximum amount of each Transfer
+	AmountCap string `json:"amountCap,omitempty"`
+	// Optional timestamp after which the Authorization can't be used
+	Expires time.Time `json:"expires,omitempty"`
+	// When the Authorization was revoked, either by the user or from an R07 or R10 return
+	Revoked time.Time `json:"revoked,omitempty"`
+	// Why the Authorization was revoked
+	RevocationReason string    `json:"revocationReason,omitempty"`
+	Created          time.Time `json:"created,omitempty"`
+	Updated          time.Time `json:"updated,omitempty"`
+}
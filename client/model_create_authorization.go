@@ -0,0 +1,30 @@
+/*
+ * Paygate API
+ *
+ * Paygate is a RESTful API enabling Automated Clearing House ([ACH](https://en.wikipedia.org/wiki/Automated_Clearing_House)) transactions to be submitted and received without a deep understanding of a full NACHA file specification.
+ *
+ * API version: v1
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+// CreateAuthorization struct for CreateAuthorization
+type CreateAuthorization struct {
+	// ID of the Originator allowed to debit the Receiver
+	Originator string `json:"originator"`
+	// How the Receiver gave their authorization. TEL transfers require oral and WEB transfers require online authorizations, all others require written.
+	Type string `json:"type"`
+	// Identifies the proof of authorization, such as a signed document or the recording of an oral authorization. Required for oral authorizations.
+	Reference string `json:"reference,omitempty"`
+	// Whether the Authorization allows a single Transfer or recurring Transfers
+	Scope string `json:"scope"`
+	// Optional maximum amount of each Transfer
+	AmountCap string `json:"amountCap,omitempty"`
+	// Optional timestamp after which the Authorization can't be used
+	Expires time.Time `json:"expires,omitempty"`
+}
@@ -11,8 +11,8 @@ package openapi
 
 // CreateDepository struct for CreateDepository
 type CreateDepository struct {
-	// Legal name of the financial institution.
-	BankName string `json:"bankName"`
+	// Legal name of the financial institution. Filled in from the FED directory when empty, otherwise it must match the FED's name for routingNumber.
+	BankName string `json:"bankName,omitempty"`
 	// Legal holder name on the account
 	Holder string `json:"holder"`
 	// Defines the type of entity of the account holder as an *individual* or *company*
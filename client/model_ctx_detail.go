@@ -0,0 +1,16 @@
+/*
+ * Paygate API
+ *
+ * Paygate is a RESTful API enabling Automated Clearing House ([ACH](https://en.wikipedia.org/wiki/Automated_Clearing_House)) transactions to be submitted and received without a deep understanding of a full NACHA file specification.
+ *
+ * API version: v1
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+// CtxDetail struct for CtxDetail
+type CtxDetail struct {
+	// ANSI ASC X12 820 remittance data with segments terminated by a backslash (\) and data elements separated by an asterisk (*). It is split across as many addenda 05 records (80 characters each) as needed, up to 9,999.
+	PaymentInformation string `json:"paymentInformation"`
+}
@@ -0,0 +1,20 @@
+/*
+ * Paygate API
+ *
+ * Paygate is a RESTful API enabling Automated Clearing House ([ACH](https://en.wikipedia.org/wiki/Automated_Clearing_House)) transactions to be submitted and received without a deep understanding of a full NACHA file specification.
+ *
+ * API version: v1
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+// DepositoryVerification struct for DepositoryVerification
+type DepositoryVerification struct {
+	// How the Depository is being verified
+	Strategy string `json:"strategy,omitempty"`
+	// Status of the Depository after this step
+	Status string `json:"status,omitempty"`
+	// What happens next when the Depository is still unverified
+	Message string `json:"message,omitempty"`
+}
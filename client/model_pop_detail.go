@@ -0,0 +1,20 @@
+/*
+ * Paygate API
+ *
+ * Paygate is a RESTful API enabling Automated Clearing House ([ACH](https://en.wikipedia.org/wiki/Automated_Clearing_House)) transactions to be submitted and received without a deep understanding of a full NACHA file specification.
+ *
+ * API version: v1
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+// PopDetail struct for PopDetail
+type PopDetail struct {
+	// Serial number of the source check, up to 9 characters
+	CheckSerialNumber string `json:"checkSerialNumber"`
+	// Abbreviation of the city where the check was converted, up to 4 characters
+	TerminalCity string `json:"terminalCity"`
+	// Two letter abbreviation of the state where the check was converted
+	TerminalState string `json:"terminalState"`
+}
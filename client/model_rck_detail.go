@@ -0,0 +1,16 @@
+/*
+ * Paygate API
+ *
+ * Paygate is a RESTful API enabling Automated Clearing House ([ACH](https://en.wikipedia.org/wiki/Automated_Clearing_House)) transactions to be submitted and received without a deep understanding of a full NACHA file specification.
+ *
+ * API version: v1
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+// RckDetail struct for RckDetail
+type RckDetail struct {
+	// Serial number of the source check, up to 15 characters
+	CheckSerialNumber string `json:"checkSerialNumber"`
+}
@@ -29,6 +29,8 @@ type Transfer struct {
 	Receiver string `json:"receiver"`
 	// ID of the Receiver Depository to be used to override the default depository
 	ReceiverDepository string `json:"receiverDepository,omitempty"`
+	// ID of the Receiver's Authorization for the Originator to debit them. Required for pull transfers.
+	Authorization string `json:"authorization,omitempty"`
 	// Brief description of the transaction, that may appear on the receiving entity’s financial statement
 	Description string `json:"description"`
 	// Standard Entry Class code will be generated based on Receiver type for CCD and PPD
@@ -36,11 +38,24 @@ type Transfer struct {
 	// Defines the state of the Transfer
 	Status string `json:"status,omitempty"`
 	// When set to true this indicates the transfer should be processed the same day if possible.
-	SameDay    bool       `json:"sameDay,omitempty"`
-	ReturnCode ReturnCode `json:"returnCode,omitempty"`
-	Created    time.Time  `json:"created,omitempty"`
-	CCDDetail  CcdDetail  `json:"CCDDetail,omitempty"`
-	IATDetail  IatDetail  `json:"IATDetail,omitempty"`
-	TELDetail  TelDetail  `json:"TELDetail,omitempty"`
-	WEBDetail  WebDetail  `json:"WEBDetail,omitempty"`
+	SameDay bool `json:"sameDay,omitempty"`
+	// Optional text sent to the receiver in an addenda 05 record, up to 80 characters. Allowed on CCD, PPD and WEB transfers.
+	PaymentRelatedInformation string `json:"paymentRelatedInformation,omitempty"`
+	// Optional identifier of the receiver to the originator (e.g. an invoice or employee ID), up to 15 characters. A random value is used when empty. Allowed on CCD, CTX, PPD, TEL and WEB transfers.
+	IdentificationNumber string `json:"identificationNumber,omitempty"`
+	// Optional data for the originator and ODFI, up to 2 characters. Allowed on ARC, BOC, CCD, CTX, POP, PPD and RCK transfers.
+	DiscretionaryData string `json:"discretionaryData,omitempty"`
+	// Assigned when the transfer is created, the ODFI's 8-digit routing number followed by a 7-digit sequence which is unique for that ODFI.
+	TraceNumber string     `json:"traceNumber,omitempty"`
+	ReturnCode  ReturnCode `json:"returnCode,omitempty"`
+	Created     time.Time  `json:"created,omitempty"`
+	ARCDetail   ArcDetail  `json:"ARCDetail,omitempty"`
+	BOCDetail   BocDetail  `json:"BOCDetail,omitempty"`
+	CCDDetail   CcdDetail  `json:"CCDDetail,omitempty"`
+	CTXDetail   CtxDetail  `json:"CTXDetail,omitempty"`
+	IATDetail   IatDetail  `json:"IATDetail,omitempty"`
+	POPDetail   PopDetail  `json:"POPDetail,omitempty"`
+	RCKDetail   RckDetail  `json:"RCKDetail,omitempty"`
+	TELDetail   TelDetail  `json:"TELDetail,omitempty"`
+	WEBDetail   WebDetail  `json:"WEBDetail,omitempty"`
 }
@@ -0,0 +1,18 @@
+/*
+ * Paygate API
+ *
+ * Paygate is a RESTful API enabling Automated Clearing House ([ACH](https://en.wikipedia.org/wiki/Automated_Clearing_House)) transactions to be submitted and received without a deep understanding of a full NACHA file specification.
+ *
+ * API version: v1
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+// VerifyDepository struct for VerifyDepository
+type VerifyDepository struct {
+	// Micro deposit amounts to confirm, only used with the micro-deposits strategy.
+	Amounts []string `json:"amounts,omitempty"`
+	// Token from the instant account verification provider after the user links their account, only used with the instant strategy.
+	Token string `json:"token,omitempty"`
+}
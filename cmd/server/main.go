@@ -14,6 +14,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -21,16 +22,23 @@ import (
 	"github.com/moov-io/base/http/bind"
 	"github.com/moov-io/paygate"
 	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/internal/audit"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/internal/customers"
 	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/depository"
 	"github.com/moov-io/paygate/internal/events"
 	"github.com/moov-io/paygate/internal/features"
 	"github.com/moov-io/paygate/internal/fed"
 	"github.com/moov-io/paygate/internal/filetransfer"
 	"github.com/moov-io/paygate/internal/gateways"
+	"github.com/moov-io/paygate/internal/iav"
+	"github.com/moov-io/paygate/internal/mailer"
 	"github.com/moov-io/paygate/internal/microdeposit"
+	"github.com/moov-io/paygate/internal/ratelimit"
 	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/internal/tracing"
 	"github.com/moov-io/paygate/internal/util"
 	"github.com/moov-io/paygate/pkg/achclient"
 	"github.com/moov-io/paygate/pkg/id"
@@ -57,11 +65,32 @@ func main() {
 	}
 	cfg.Logger.Log("startup", fmt.Sprintf("Starting paygate server version %s", paygate.Version))
 
+	// Bind addresses from the config take precedence over our flags
+	if cfg.HTTP.BindAddress == "" {
+		cfg.HTTP.BindAddress = *httpAddr
+	}
+	if cfg.HTTP.AdminBindAddress == "" {
+		cfg.HTTP.AdminBindAddress = *adminAddr
+	}
+
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
 
+	// Export spans for requests, calls to other services and file transfers
+	tracer, err := tracing.Init(cfg.Logger, cfg.Tracing)
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: problem setting up tracing: %v", err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracer.Shutdown(ctx); err != nil {
+			cfg.Logger.Log("shutdown", fmt.Sprintf("problem exporting spans: %v", err))
+		}
+	}()
+
 	// migrate database
-	db, err := database.New(ctx, cfg.Logger, os.Getenv("DATABASE_TYPE"))
+	db, err := database.New(ctx, cfg.Logger, cfg.Database)
 	if err != nil {
 		panic(fmt.Sprintf("error creating database: %v", err))
 	}
@@ -79,14 +108,12 @@ func main() {
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
-	// Spin up admin HTTP server and optionally override -admin.addr
-	if v := os.Getenv("HTTP_ADMIN_BIND_ADDRESS"); v != "" {
-		*adminAddr = v
-	}
-	adminServer := admin.NewServer(*adminAddr)
+	// Spin up admin HTTP server on localhost, it's only reachable through adminGate
+	adminServer := admin.NewServer(":0")
 	adminServer.AddVersionHandler(paygate.Version) // Setup 'GET /version'
+	config.RegisterAdminRoutes(cfg.Logger, adminServer, cfg)
+	adminServer.AddReadinessCheck("database", healthCheck(db.PingContext))
 	go func() {
-		cfg.Logger.Log("admin", fmt.Sprintf("listening on %s", adminServer.BindAddr()))
 		if err := adminServer.Listen(); err != nil {
 			err = fmt.Errorf("problem starting admin http: %v", err)
 			cfg.Logger.Log("admin", err)
@@ -95,22 +122,60 @@ func main() {
 	}()
 	defer adminServer.Shutdown()
 
-	keeper, err := secrets.OpenSecretKeeper(ctx, "paygate-account-numbers", os.Getenv("CLOUD_PROVIDER"))
+	// Authenticate admin requests and record them in the audit log
+	auditRepo := audit.NewRepo(cfg.Logger, db)
+	auditRecorder := audit.NewRecorder(cfg.Logger, auditRepo)
+	audit.RegisterAdminRoutes(cfg.Logger, adminServer, auditRepo)
+	adminGate, err := setupAdminGate(cfg, auditRecorder, adminServer)
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: problem setting up admin authentication: %v", err))
+	}
+	adminGateServer := &http.Server{
+		Addr:         cfg.HTTP.AdminBindAddress,
+		Handler:      adminGate,
+		ReadTimeout:  45 * time.Second,
+		WriteTimeout: 45 * time.Second,
+		IdleTimeout:  45 * time.Second,
+	}
+	go func() {
+		cfg.Logger.Log("admin", fmt.Sprintf("listening on %s", adminGateServer.Addr))
+		if err := adminGateServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			err = fmt.Errorf("problem starting admin http: %v", err)
+			cfg.Logger.Log("admin", err)
+			errs <- err
+		}
+	}()
+
+	stringKeeper, err := secrets.OpenStringKeeper(ctx, "paygate-account-numbers", cfg.Secrets, 10*time.Second)
 	if err != nil {
 		panic(err)
 	}
-	stringKeeper := secrets.NewStringKeeper(keeper, 10*time.Second)
 
 	// Setup repositories
-	receiverRepo := internal.NewReceiverRepo(cfg.Logger, db)
+	receiverRepo := internal.NewReceiverRepo(cfg.Logger, db, stringKeeper)
 	defer receiverRepo.Close()
 
+	authorizationRepo := internal.NewAuthorizationRepo(cfg.Logger, db)
+	defer authorizationRepo.Close()
+
 	depositoryRepo := internal.NewDepositoryRepo(cfg.Logger, db, stringKeeper)
 	defer depositoryRepo.Close()
 
-	if err := internal.EncryptStoredAccountNumbers(cfg.Logger, depositoryRepo, stringKeeper); err != nil {
+	if err := internal.EncryptStoredAccountNumbers(ctx, cfg.Logger, depositoryRepo, stringKeeper); err != nil {
+		panic(err)
+	}
+	if err := internal.EncryptStoredPII(cfg.Logger, db, stringKeeper); err != nil {
+		panic(err)
+	}
+	if err := clearPlaintextAccountNumbers(cfg.Logger, depositoryRepo, stringKeeper, os.Getenv("ACCOUNT_NUMBERS_CLEAR_PLAINTEXT")); err != nil {
 		panic(err)
 	}
+	depository.RegisterPlaintextRoutes(cfg.Logger, adminServer, depositoryRepo, stringKeeper)
+	accountNumberRotation := internal.NewAccountNumberRotation(cfg.Logger, depositoryRepo, stringKeeper, 100)
+	depository.RegisterRotationRoutes(cfg.Logger, adminServer, accountNumberRotation)
+	if stringKeeper.HasPreviousKeys() {
+		accountNumberRotation.Start() // re-encrypt account numbers from previous keys in the background
+	}
 
 	eventRepo := events.NewRepo(cfg.Logger, db)
 	defer eventRepo.Close()
@@ -118,23 +183,28 @@ func main() {
 	gatewaysRepo := gateways.NewRepo(cfg.Logger, db)
 	defer gatewaysRepo.Close()
 
-	originatorsRepo := internal.NewOriginatorRepo(cfg.Logger, db)
+	originatorsRepo := internal.NewOriginatorRepo(cfg.Logger, db, stringKeeper)
 	defer originatorsRepo.Close()
 
 	transferRepo := internal.NewTransferRepo(cfg.Logger, db)
 	defer transferRepo.Close()
 
-	httpClient, err := internal.TLSHttpClient(os.Getenv("HTTP_CLIENT_CAFILE"))
+	traceNumberRepo := internal.NewTraceNumberRepo(cfg.Logger, db)
+	defer traceNumberRepo.Close()
+
+	httpClient, err := internal.TLSHttpClient(cfg.HTTP.ClientCAFile)
 	if err != nil {
 		panic(fmt.Sprintf("problem creating TLS ready *http.Client: %v", err))
 	}
+	httpClient.Transport = tracing.Transport(httpClient.Transport)
 
 	// Create our various Client instances
-	achClient := setupACHClient(cfg.Logger, os.Getenv("ACH_ENDPOINT"), adminServer, httpClient)
-	fedClient := setupFEDClient(cfg.Logger, os.Getenv("FED_ENDPOINT"), adminServer, httpClient)
+	achClient := setupACHClient(cfg.Logger, cfg.ACH.Endpoint, adminServer, httpClient)
+	fedClient := setupFEDClient(cfg, adminServer, httpClient)
+	iavClient := setupIAVClient(cfg, adminServer, httpClient)
 
 	// Bring up our Accounts Client
-	accountsClient := setupAccountsClient(cfg.Logger, adminServer, httpClient, os.Getenv("ACCOUNTS_ENDPOINT"), os.Getenv("ACCOUNTS_CALLS_DISABLED"))
+	accountsClient := setupAccountsClient(cfg, adminServer, httpClient)
 	accountsCallsDisabled := accountsClient == nil
 
 	customersClient := setupCustomersClient(cfg, adminServer, httpClient)
@@ -148,50 +218,71 @@ func main() {
 	features.AddRoutes(cfg.Logger, adminServer, accountsCallsDisabled, customersCallsDisabled)
 
 	// Start our periodic file operations
-	fileTransferRepo := filetransfer.NewRepository(configFilepath, db, os.Getenv("DATABASE_TYPE"))
+	fileTransferRepo, err := setupFileTransferRepo(cfg, configFilepath, db)
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: problem reading file transfer configs: %v", err))
+	}
 	defer fileTransferRepo.Close()
 	if err := filetransfer.ValidateTemplates(fileTransferRepo); err != nil {
 		panic(fmt.Sprintf("ERROR: problem validating outbound filename templates: %v", err))
 	}
 
-	achStorageDir := setupACHStorageDir(cfg.Logger)
+	achStorageDir := setupACHStorageDir(cfg.Logger, cfg.FileTransfer.StorageDir)
 	fileTransferController, err := filetransfer.NewController(cfg, achStorageDir, fileTransferRepo, achClient, accountsClient)
 	if err != nil {
 		panic(fmt.Sprintf("ERROR: creating ACH file transfer controller: %v", err))
 	}
-	shutdownFileTransferController := setupFileTransferController(cfg.Logger, fileTransferController, depositoryRepo, fileTransferRepo, transferRepo, adminServer)
-	defer shutdownFileTransferController()
+	shutdownFileTransferController := setupFileTransferController(cfg.Logger, fileTransferController, depositoryRepo, fileTransferRepo, transferRepo, authorizationRepo, adminServer)
+
+	// Check each ODFI's FTP/SFTP server so broken credentials are found before a cutoff time
+	fileTransferHealth := setupFileTransferHealthChecker(cfg, fileTransferController, fileTransferRepo, adminServer)
+	defer fileTransferHealth.Close()
+
+	// Void expired micro-deposits and report unverified depositories
+	microDepositSweeper := setupMicroDepositSweeper(cfg, depositoryRepo, eventRepo, accountsClient)
+	defer microDepositSweeper.Close()
 
-	// Register the micro-deposit admin route
+	// Register the micro-deposit and depository admin routes
 	microdeposit.RegisterAdminRoutes(cfg.Logger, adminServer, depositoryRepo)
+	depository.RegisterAdminRoutes(cfg.Logger, adminServer, depositoryRepo)
+
+	// Authenticate requests to our HTTP routes
+	apiKeyRepo := auth.NewAPIKeyRepo(cfg.Logger, db)
+	authMiddleware, err := setupAuthMiddleware(cfg, apiKeyRepo, adminServer)
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: problem setting up authentication: %v", err))
+	}
+	setupAuditSnapshots(auditRecorder, depositoryRepo, fileTransferRepo, apiKeyRepo)
 
 	// Create HTTP handler
 	handler := mux.NewRouter()
+	handler.Use(tracing.Handler)
+	handler.Use(internal.RequestTimeout(cfg.HTTP.RequestTimeout))
+	handler.Use(authMiddleware.Handler)
+	handler.Use(setupRateLimiter(cfg, db).Handler)
 	internal.AddReceiverRoutes(cfg.Logger, handler, customersClient, depositoryRepo, receiverRepo)
+	internal.AddReceiverVerificationRoutes(cfg.Logger, handler, setupReceiverEmailVerifier(cfg), receiverRepo)
+	internal.AddAuthorizationRoutes(cfg.Logger, handler, authorizationRepo, originatorsRepo, receiverRepo)
 	events.AddRoutes(cfg.Logger, handler, eventRepo)
 	gateways.AddRoutes(cfg.Logger, handler, gatewaysRepo)
-	internal.AddOriginatorRoutes(cfg.Logger, handler, accountsClient, customersClient, depositoryRepo, originatorsRepo)
+	internal.AddOriginatorRoutes(cfg.Logger, handler, accountsClient, customersClient, depositoryRepo, eventRepo, originatorsRepo)
 	internal.AddPingRoute(cfg.Logger, handler)
 
 	// Depository HTTP routes
-	odfiAccount := setupODFIAccount(accountsClient, stringKeeper)
-	depositoryRouter := internal.NewDepositoryRouter(cfg.Logger, odfiAccount, accountsClient, achClient, fedClient, depositoryRepo, eventRepo, stringKeeper)
+	odfiAccount := internal.NewODFIAccount(accountsClient, cfg.ODFI, stringKeeper)
+	depositoryRouter := internal.NewDepositoryRouter(cfg, odfiAccount, accountsClient, achClient, fedClient, iavClient, depositoryRepo, eventRepo, traceNumberRepo, stringKeeper)
 	depositoryRouter.RegisterRoutes(handler)
 
 	// Transfer HTTP routes
 	achClientFactory := func(userId id.User) *achclient.ACH {
-		return achclient.New(cfg.Logger, os.Getenv("ACH_ENDPOINT"), userId, httpClient)
+		return achclient.New(cfg.Logger, cfg.ACH.Endpoint, userId, httpClient)
 	}
-	xferRouter := internal.NewTransferRouter(cfg.Logger, depositoryRepo, eventRepo, receiverRepo, originatorsRepo, transferRepo, achClientFactory, accountsClient, customersClient)
+	xferRouter := internal.NewTransferRouter(cfg.Logger, depositoryRepo, eventRepo, receiverRepo, authorizationRepo, originatorsRepo, transferRepo, traceNumberRepo, achClientFactory, accountsClient, customersClient)
 	xferRouter.RegisterRoutes(handler)
 
-	// Check to see if our -http.addr flag has been overridden
-	if v := os.Getenv("HTTP_BIND_ADDRESS"); v != "" {
-		*httpAddr = v
-	}
 	// Create main HTTP server
 	serve := &http.Server{
-		Addr:    *httpAddr,
+		Addr:    cfg.HTTP.BindAddress,
 		Handler: handler,
 		TLSConfig: &tls.Config{
 			InsecureSkipVerify:       false,
@@ -202,22 +293,20 @@ func main() {
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
-	shutdownServer := func() {
-		if err := serve.Shutdown(context.TODO()); err != nil {
-			cfg.Logger.Log("shutdown", err)
-		}
+	if err := auth.ConfigureTLS(cfg.Auth, serve.TLSConfig); err != nil {
+		panic(fmt.Sprintf("ERROR: %v", err))
 	}
-	defer shutdownServer()
+	defer shutdown(cfg, serve, adminGateServer, shutdownFileTransferController)
 
 	// Start main HTTP server
 	go func() {
-		if certFile, keyFile := os.Getenv("HTTPS_CERT_FILE"), os.Getenv("HTTPS_KEY_FILE"); certFile != "" && keyFile != "" {
-			cfg.Logger.Log("startup", fmt.Sprintf("binding to %s for secure HTTP server", *httpAddr))
+		if certFile, keyFile := cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile; certFile != "" && keyFile != "" {
+			cfg.Logger.Log("startup", fmt.Sprintf("binding to %s for secure HTTP server", serve.Addr))
 			if err := serve.ListenAndServeTLS(certFile, keyFile); err != nil {
 				cfg.Logger.Log("exit", err)
 			}
 		} else {
-			cfg.Logger.Log("startup", fmt.Sprintf("binding to %s for HTTP server", *httpAddr))
+			cfg.Logger.Log("startup", fmt.Sprintf("binding to %s for HTTP server", serve.Addr))
 			if err := serve.ListenAndServe(); err != nil {
 				cfg.Logger.Log("exit", err)
 			}
@@ -234,19 +323,39 @@ func setupACHClient(logger log.Logger, endpoint string, svc *admin.Server, httpC
 	if client == nil {
 		panic("no ACH client created")
 	}
-	svc.AddLivenessCheck("ach", client.Ping)
+	svc.AddLivenessCheck("ach", healthCheck(client.Ping))
 	return client
 }
 
-func setupAccountsClient(logger log.Logger, svc *admin.Server, httpClient *http.Client, endpoint, disabled string) internal.AccountsClient {
-	if util.Yes(disabled) {
+// healthCheck gives each ping its own deadline as the admin server doesn't pass one along.
+func healthCheck(ping func(context.Context) error) func() error {
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return ping(ctx)
+	}
+}
+
+// clearPlaintextAccountNumbers removes verified plaintext account numbers on startup when enabled,
+// or only logs what would be removed when set to "dry-run".
+func clearPlaintextAccountNumbers(logger log.Logger, repo *internal.SQLDepositoryRepo, keeper *secrets.StringKeeper, enabled string) error {
+	dryRun := strings.EqualFold(enabled, "dry-run")
+	if !dryRun && !util.Yes(enabled) {
+		return nil
+	}
+	_, err := internal.ClearPlaintextAccountNumbers(logger, repo, keeper, dryRun)
+	return err
+}
+
+func setupAccountsClient(cfg *config.Config, svc *admin.Server, httpClient *http.Client) internal.AccountsClient {
+	if cfg.Accounts.Disabled {
 		return nil
 	}
-	accountsClient := internal.CreateAccountsClient(logger, endpoint, httpClient)
+	accountsClient := internal.CreateAccountsClient(cfg.Logger, cfg.Accounts.Endpoint, httpClient)
 	if accountsClient == nil {
 		panic("no Accounts client created")
 	}
-	svc.AddLivenessCheck("accounts", accountsClient.Ping)
+	svc.AddLivenessCheck("accounts", healthCheck(accountsClient.Ping))
 	return accountsClient
 }
 
@@ -258,7 +367,7 @@ func setupCustomersClient(cfg *config.Config, svc *admin.Server, httpClient *htt
 	if client == nil {
 		panic("no Customers client created")
 	}
-	svc.AddLivenessCheck("customers", client.Ping)
+	svc.AddLivenessCheck("customers", healthCheck(client.Ping))
 	return client
 }
 
@@ -274,32 +383,51 @@ func setupCustomersRefresher(cfg *config.Config, client customers.Client, db *sq
 	return refresher
 }
 
-func setupFEDClient(logger log.Logger, endpoint string, svc *admin.Server, httpClient *http.Client) fed.Client {
-	client := fed.NewClient(logger, endpoint, httpClient)
+func setupMicroDepositSweeper(cfg *config.Config, depRepo *internal.SQLDepositoryRepo, eventRepo events.Repository, accountsClient internal.AccountsClient) *internal.MicroDepositSweeper {
+	sweeper := internal.NewMicroDepositSweeper(cfg, depRepo, eventRepo, accountsClient)
+	go func() {
+		if err := sweeper.Start(cfg.MicroDeposits.CheckEvery); err != nil {
+			cfg.Logger.Log("microDeposits", fmt.Errorf("problem with micro-deposit sweeper: %v", err))
+		}
+	}()
+	return sweeper
+}
+
+func setupFEDClient(cfg *config.Config, svc *admin.Server, httpClient *http.Client) fed.Client {
+	client := fed.NewClient(cfg.Logger, cfg.FED.Endpoint, httpClient)
 	if client == nil {
 		panic("no FED client created")
 	}
-	svc.AddLivenessCheck("fed", client.Ping)
-	return client
+	svc.AddLivenessCheck("fed", healthCheck(client.Ping))
+	return fed.NewCachedClient(cfg.Logger, client, cfg.FED.CacheTTL)
 }
 
-func setupODFIAccount(accountsClient internal.AccountsClient, keeper *secrets.StringKeeper) *internal.ODFIAccount {
-	odfiAccountType := internal.Savings
-	if v := os.Getenv("ODFI_ACCOUNT_TYPE"); v != "" {
-		t := internal.AccountType(v)
-		if err := t.Validate(); err == nil {
-			odfiAccountType = t
-		}
+// setupIAVClient returns a client for the instant account verification provider, or nil when
+// the "instant" verification strategy isn't configured.
+func setupIAVClient(cfg *config.Config, svc *admin.Server, httpClient *http.Client) iav.Client {
+	client := iav.NewClient(cfg.Logger, cfg.Verification.Instant.Endpoint, httpClient)
+	if client == nil {
+		return nil
 	}
+	svc.AddLivenessCheck("iav", healthCheck(client.Ping))
+	return client
+}
 
-	accountNumber := util.Or(os.Getenv("ODFI_ACCOUNT_NUMBER"), "123")
-	routingNumber := util.Or(os.Getenv("ODFI_ROUTING_NUMBER"), "121042882")
-
-	return internal.NewODFIAccount(accountsClient, accountNumber, routingNumber, odfiAccountType, keeper)
+// setupReceiverEmailVerifier returns the verifier which emails Receivers a token to confirm their address.
+func setupReceiverEmailVerifier(cfg *config.Config) *internal.ReceiverEmailVerifier {
+	m, err := mailer.New(cfg.Logger, cfg.Email)
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: creating mailer: %v", err))
+	}
+	verifier, err := internal.NewReceiverEmailVerifier(cfg.Logger, cfg.Email, m)
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: creating receiver email verifier: %v", err))
+	}
+	return verifier
 }
 
-func setupACHStorageDir(logger log.Logger) string {
-	dir := filepath.Dir(os.Getenv("ACH_FILE_STORAGE_DIR"))
+func setupACHStorageDir(logger log.Logger, storageDir string) string {
+	dir := filepath.Dir(storageDir)
 	if dir == "." {
 		dir = "./storage/"
 	}
@@ -310,20 +438,126 @@ func setupACHStorageDir(logger log.Logger) string {
 	return dir
 }
 
-func setupFileTransferController(logger log.Logger, controller *filetransfer.Controller, depRepo internal.DepositoryRepository, fileTransferRepo filetransfer.Repository, transferRepo internal.TransferRepository, svc *admin.Server) context.CancelFunc {
+func setupAuthMiddleware(cfg *config.Config, apiKeyRepo *auth.SQLAPIKeyRepo, svc *admin.Server) (*auth.Middleware, error) {
+	if !cfg.Auth.Enabled() {
+		cfg.Logger.Log("auth", "WARNING: no authentication is configured, requests to user routes will be rejected")
+	}
+	if cfg.Auth.TrustedProxy.Enabled {
+		cfg.Logger.Log("auth", "trusting X-User-ID headers from a proxy")
+	}
+	if cfg.Auth.APIKeys.Enabled {
+		auth.RegisterAdminRoutes(cfg.Logger, svc, apiKeyRepo)
+	}
+	return auth.NewMiddleware(cfg.Logger, cfg.Auth, apiKeyRepo)
+}
+
+func setupAdminGate(cfg *config.Config, recorder *audit.Recorder, svc *admin.Server) (*auth.AdminGate, error) {
+	gate, err := auth.NewAdminGate(cfg.Logger, cfg.Auth.Admin, recorder, svc.BindAddr())
+	if err != nil {
+		return nil, err
+	}
+	if !gate.Enabled() {
+		cfg.Logger.Log("admin", "WARNING: no admin tokens are configured, only /live, /ready, /metrics and /version can be called")
+	}
+	return gate, nil
+}
+
+// setupAuditSnapshots records what admin routes which change configs, depositories or API keys
+// looked like before and after each request.
+func setupAuditSnapshots(recorder *audit.Recorder, depRepo internal.DepositoryRepository, fileTransferRepo filetransfer.Repository, apiKeyRepo auth.APIKeyRepository) {
+	recorder.AddSnapshot("/depositories/{depositoryId}", depository.StatusSnapshot(depRepo))
+
+	configSnapshot := filetransfer.ConfigSnapshot(fileTransferRepo)
+	recorder.AddSnapshot("/configs/uploads/cutoff-times/{routingNumber}", configSnapshot)
+	recorder.AddSnapshot("/configs/uploads/file-transfers/{routingNumber}", configSnapshot)
+	recorder.AddSnapshot("/configs/uploads/ftp/{routingNumber}", configSnapshot)
+	recorder.AddSnapshot("/configs/uploads/sftp/{routingNumber}", configSnapshot)
+
+	apiKeysSnapshot := auth.APIKeysSnapshot(apiKeyRepo)
+	recorder.AddSnapshot("/api-keys", apiKeysSnapshot)
+	recorder.AddSnapshot("/api-keys/{keyId}", apiKeysSnapshot)
+}
+
+// setupRateLimiter keeps token buckets in memory, or in the database when they're shared between instances.
+func setupRateLimiter(cfg *config.Config, db *sql.DB) *ratelimit.Limiter {
+	store := ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "database" {
+		store = ratelimit.NewSQLStore(db)
+	}
+	if cfg.RateLimit.Enabled() {
+		cfg.Logger.Log("ratelimit", fmt.Sprintf("rate limiting requests with %s store", cfg.RateLimit.Store))
+	}
+	return ratelimit.NewLimiter(cfg.Logger, cfg.RateLimit, store)
+}
+
+// setupFileTransferRepo returns a Repository which reloads from configFilepath when set, otherwise
+// file transfer configs are read from the database.
+func setupFileTransferRepo(cfg *config.Config, configFilepath string, db *sql.DB) (filetransfer.Repository, error) {
+	if configFilepath != "" {
+		return filetransfer.NewFileRepository(cfg.Logger, configFilepath)
+	}
+	return filetransfer.NewRepository(db, cfg.Database.Type), nil
+}
+
+// setupFileTransferController starts the controller's periodic file operations. The returned func stops
+// them, waiting until ctx is done for the current merge, upload or download to finish.
+func setupFileTransferController(logger log.Logger, controller *filetransfer.Controller, depRepo internal.DepositoryRepository, fileTransferRepo filetransfer.Repository, transferRepo internal.TransferRepository, authorizationRepo internal.AuthorizationRepository, svc *admin.Server) func(context.Context) error {
 	ctx, cancelFileSync := context.WithCancel(context.Background())
 
 	if controller == nil {
-		return cancelFileSync
+		return func(_ context.Context) error {
+			cancelFileSync()
+			return nil
+		}
 	}
 
 	flushIncoming, flushOutgoing := make(filetransfer.FlushChan, 1), make(filetransfer.FlushChan, 1) // buffered channels to allow only one concurrent operation
 
 	// start our controller's operations in an anon goroutine
-	go controller.StartPeriodicFileOperations(ctx, flushIncoming, flushOutgoing, depRepo, transferRepo)
+	go controller.StartPeriodicFileOperations(ctx, flushIncoming, flushOutgoing, depRepo, transferRepo, authorizationRepo)
 
 	filetransfer.AddFileTransferConfigRoutes(logger, svc, fileTransferRepo)
 	filetransfer.AddFileTransferSyncRoute(logger, svc, flushIncoming, flushOutgoing)
 
-	return cancelFileSync
+	return func(ctx context.Context) error {
+		cancelFileSync()
+		return controller.Shutdown(ctx)
+	}
+}
+
+// setupFileTransferHealthChecker periodically checks each ODFI's file transfer server, which is
+// only done when file transfers are enabled.
+func setupFileTransferHealthChecker(cfg *config.Config, controller *filetransfer.Controller, repo filetransfer.Repository, svc *admin.Server) *filetransfer.HealthChecker {
+	if controller == nil {
+		return nil
+	}
+	checker := filetransfer.NewHealthChecker(cfg, repo)
+	go func() {
+		if err := checker.Start(); err != nil {
+			cfg.Logger.Log("filetransfer", fmt.Errorf("problem with file transfer health checks: %v", err))
+		}
+	}()
+	filetransfer.AddFileTransferHealthRoutes(cfg.Logger, svc, checker)
+	return checker
+}
+
+// shutdown stops paygate from taking new HTTP requests and lets the requests and file transfer
+// operations in progress finish, all within cfg.HTTP.ShutdownTimeout. It's called before the
+// repositories and database are closed.
+func shutdown(cfg *config.Config, serve *http.Server, adminGateServer *http.Server, shutdownFileTransferController func(context.Context) error) {
+	cfg.Logger.Log("shutdown", fmt.Sprintf("waiting up to %v for requests and file transfers to finish", cfg.HTTP.ShutdownTimeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := serve.Shutdown(ctx); err != nil {
+		cfg.Logger.Log("shutdown", fmt.Sprintf("problem shutting down HTTP server: %v", err))
+	}
+	if err := shutdownFileTransferController(ctx); err != nil {
+		cfg.Logger.Log("shutdown", err)
+	}
+	// The admin server is last so it keeps answering probes while we're draining
+	if err := adminGateServer.Shutdown(ctx); err != nil {
+		cfg.Logger.Log("shutdown", fmt.Sprintf("problem shutting down admin HTTP server: %v", err))
+	}
 }
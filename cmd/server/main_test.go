@@ -5,14 +5,23 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/moov-io/base/admin"
+	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/internal/audit"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/filetransfer"
 	"github.com/moov-io/paygate/internal/secrets"
 
 	"github.com/go-kit/kit/log"
@@ -40,20 +49,66 @@ func TestMain__setupACHClient(t *testing.T) {
 }
 
 func TestMain__setupAccountsClient(t *testing.T) {
-	logger := log.NewNopLogger()
+	cfg := config.Empty()
 	svc := admin.NewServer(":0")
 	httpClient := &http.Client{}
 
-	client := setupAccountsClient(logger, svc, httpClient, "", "yes")
+	cfg.Accounts.Disabled = true
+	client := setupAccountsClient(cfg, svc, httpClient)
 	if client != nil {
 		t.Errorf("expected disabled (nil) AccountsClient: %v", client)
 	}
-	client = setupAccountsClient(logger, svc, httpClient, "", "")
+	cfg.Accounts.Disabled = false
+	client = setupAccountsClient(cfg, svc, httpClient)
 	if client == nil {
 		t.Error("expected non-nil AccountsClient")
 	}
 }
 
+func TestMain__clearPlaintextAccountNumbers(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	keeper := secrets.TestStringKeeper(t)
+	repo := internal.NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper)
+
+	encrypted, err := keeper.EncryptString("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := `insert into depositories (depository_id, account_number_encrypted, account_number_hashed, created_at)
+values ('dep', ?, '8d969eef6ecad3c29a3a629280e686cf0c3f5d5a86aff3ca12020c923adc6c92', ?);`
+	if _, err := db.DB.Exec(query, encrypted, time.Now().Add(-1*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB.Exec(`insert into plaintext_account_numbers (depository_id, account_number) values ('dep', '123456');`); err != nil {
+		t.Fatal(err)
+	}
+	read := func() string {
+		var num string
+		err := db.DB.QueryRow(`select account_number from plaintext_account_numbers where depository_id = 'dep';`).Scan(&num)
+		if err != nil && err != sql.ErrNoRows {
+			t.Fatal(err)
+		}
+		return num
+	}
+
+	for _, enabled := range []string{"", "no", "dry-run"} {
+		if err := clearPlaintextAccountNumbers(log.NewNopLogger(), repo, keeper, enabled); err != nil {
+			t.Fatal(err)
+		}
+		if num := read(); num != "123456" {
+			t.Errorf("%q: account number was cleared", enabled)
+		}
+	}
+	if err := clearPlaintextAccountNumbers(log.NewNopLogger(), repo, keeper, "yes"); err != nil {
+		t.Fatal(err)
+	}
+	if num := read(); num != "" {
+		t.Errorf("expected account number to be cleared: %q", num)
+	}
+}
+
 func TestMain__setupCustomersClient(t *testing.T) {
 	svc := admin.NewServer(":0")
 	httpClient := &http.Client{}
@@ -104,36 +159,47 @@ func TestMain__setupCustomersRefresherNil(t *testing.T) {
 }
 
 func TestMain__setupFEDClient(t *testing.T) {
-	logger := log.NewNopLogger()
+	cfg := config.Empty()
 	svc := admin.NewServer(":0")
 	httpClient := &http.Client{}
 
-	client := setupFEDClient(logger, "", svc, httpClient)
+	client := setupFEDClient(cfg, svc, httpClient)
 	if client == nil {
 		t.Error("expected FED client")
 	}
 }
 
-func TestMain__setupODFIAccount(t *testing.T) {
-	logger := log.NewNopLogger()
+func TestMain__setupReceiverEmailVerifier(t *testing.T) {
+	cfg := config.Empty()
+	cfg.Email.LogOnly = true
+	if verifier := setupReceiverEmailVerifier(cfg); verifier == nil {
+		t.Error("expected verifier")
+	}
+}
+
+func TestMain__ODFIAccount(t *testing.T) {
+	cfg := config.Empty()
+	if err := config.OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
 	svc := admin.NewServer(":0")
 	httpClient := &http.Client{}
 
 	keeper := secrets.TestStringKeeper(t)
 
-	accountsClient := setupAccountsClient(logger, svc, httpClient, "", "")
+	accountsClient := setupAccountsClient(cfg, svc, httpClient)
 	if accountsClient == nil {
 		t.Fatal("expected an Accounts client")
 	}
 
-	acct := setupODFIAccount(accountsClient, keeper)
+	acct := internal.NewODFIAccount(accountsClient, cfg.ODFI, keeper)
 	if acct == nil {
 		t.Error("expected ODFI account")
 	}
 }
 
 func TestMain__setupACHStorageDir(t *testing.T) {
-	dir := setupACHStorageDir(log.NewNopLogger())
+	dir := setupACHStorageDir(log.NewNopLogger(), "")
 
 	if dir != "./storage/" {
 		t.Errorf("unexpected ACH storage directory: %s", dir)
@@ -143,3 +209,116 @@ func TestMain__setupACHStorageDir(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestMain__setupFileTransferRepo(t *testing.T) {
+	cfg := config.Empty()
+
+	repo, err := setupFileTransferRepo(cfg, filepath.Join("..", "..", "testdata", "configs", "routing-good.yaml"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	if xs, _ := repo.GetConfigs(context.Background()); len(xs) != 1 || xs[0].RoutingNumber != "987654320" {
+		t.Errorf("unexpected configs: %#v", xs)
+	}
+
+	if _, err := setupFileTransferRepo(cfg, filepath.Join("testdata", "missing.yaml"), nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMain__setupFileTransferHealthChecker(t *testing.T) {
+	cfg := config.Empty()
+	if err := config.OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	svc := admin.NewServer(":0")
+
+	// nothing is checked when file transfers are disabled
+	if checker := setupFileTransferHealthChecker(cfg, nil, nil, svc); checker != nil {
+		t.Errorf("unexpected checker: %#v", checker)
+	}
+
+	dir, err := ioutil.TempDir("", "setupFileTransferHealthChecker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo, err := setupFileTransferRepo(cfg, filepath.Join("..", "..", "testdata", "configs", "routing-good.yaml"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	controller, err := filetransfer.NewController(cfg, dir, repo, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := setupFileTransferHealthChecker(cfg, controller, repo, svc)
+	if checker == nil {
+		t.Fatal("nil HealthChecker")
+	}
+	checker.Close()
+}
+
+func TestMain__setupAdminGate(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	cfg := config.Empty()
+	svc := admin.NewServer(":0")
+	recorder := audit.NewRecorder(cfg.Logger, audit.NewRepo(cfg.Logger, db.DB))
+
+	gate, err := setupAdminGate(cfg, recorder, svc)
+	if gate == nil || err != nil {
+		t.Fatalf("gate=%v error=%v", gate, err)
+	}
+	if gate.Enabled() {
+		t.Error("expected no admin tokens")
+	}
+
+	cfg.Auth.Admin.Tokens = []config.AdminToken{{Name: "alice", Role: "operator", SHA256: strings.Repeat("ab", 32)}}
+	if gate, err := setupAdminGate(cfg, recorder, svc); err != nil || !gate.Enabled() {
+		t.Errorf("gate=%v error=%v", gate, err)
+	}
+
+	setupAuditSnapshots(recorder, &internal.MockDepositoryRepository{}, filetransfer.NewRepository(nil, ""), auth.NewAPIKeyRepo(cfg.Logger, db.DB))
+}
+
+func TestMain__setupRateLimiter(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	cfg := config.Empty()
+	cfg.RateLimit.User = config.RateLimit{Rate: 1, Burst: 1}
+	for _, store := range []string{"memory", "database"} {
+		cfg.RateLimit.Store = store
+		if l := setupRateLimiter(cfg, db.DB); l == nil {
+			t.Errorf("%s: nil Limiter", store)
+		}
+	}
+}
+
+func TestMain__setupAuthMiddleware(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	cfg := config.Empty()
+	svc := admin.NewServer(":0")
+	apiKeyRepo := auth.NewAPIKeyRepo(cfg.Logger, db.DB)
+
+	if m, err := setupAuthMiddleware(cfg, apiKeyRepo, svc); m == nil || err != nil {
+		t.Errorf("middleware=%v error=%v", m, err)
+	}
+
+	cfg.Auth.APIKeys.Enabled = true
+	cfg.Auth.TrustedProxy.Enabled = true
+	if m, err := setupAuthMiddleware(cfg, apiKeyRepo, svc); m == nil || err != nil {
+		t.Errorf("middleware=%v error=%v", m, err)
+	}
+
+	cfg.Auth.JWT.JWKSFile = filepath.Join("testdata", "missing.json")
+	if _, err := setupAuthMiddleware(cfg, apiKeyRepo, svc); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -9,6 +9,7 @@ require (
 	github.com/gorilla/mux v1.7.3
 	github.com/hashicorp/vault/api v1.0.2
 	github.com/jlaffaye/ftp v0.0.0-20191025175106-a59fe673c9b2
+	github.com/lib/pq v1.3.0
 	github.com/lopezator/migrator v0.2.0
 	github.com/mattn/go-sqlite3 v1.11.0
 	github.com/moov-io/accounts v0.4.1
@@ -19,11 +20,19 @@ require (
 	github.com/ory/dockertest/v3 v3.5.2
 	github.com/pkg/sftp v1.10.1
 	github.com/prometheus/client_golang v1.3.0
+	go.opentelemetry.io/otel v1.7.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.7.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.7.0
+	go.opentelemetry.io/otel/sdk v1.7.0
+	go.opentelemetry.io/otel/trace v1.7.0
+	go.opentelemetry.io/proto/otlp v0.16.0
 	gocloud.dev v0.17.0
 	gocloud.dev/secrets/hashivault v0.17.0
-	golang.org/x/crypto v0.0.0-20191111213947-16651526fdb4
-	golang.org/x/oauth2 v0.0.0-20190604053449-0f29369cfe45
-	golang.org/x/text v0.3.2
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	golang.org/x/oauth2 v0.0.0-20211104180415-d3ed0bb246c8
+	golang.org/x/text v0.3.5
+	google.golang.org/protobuf v1.28.0
+	gopkg.in/square/go-jose.v2 v2.3.1
 	gopkg.in/yaml.v2 v2.2.5
 )
 
@@ -9,7 +9,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	accounts "github.com/moov-io/accounts/client"
 	"github.com/moov-io/base/http/bind"
@@ -21,11 +20,11 @@ import (
 )
 
 type AccountsClient interface {
-	Ping() error
+	Ping(ctx context.Context) error
 
-	PostTransaction(requestID string, userID id.User, lines []transactionLine) (*accounts.Transaction, error)
-	SearchAccounts(requestID string, userID id.User, dep *Depository) (*accounts.Account, error)
-	ReverseTransaction(requestID string, userID id.User, transactionID string) error
+	PostTransaction(ctx context.Context, requestID string, userID id.User, lines []transactionLine) (*accounts.Transaction, error)
+	SearchAccounts(ctx context.Context, requestID string, userID id.User, dep *Depository) (*accounts.Account, error)
+	ReverseTransaction(ctx context.Context, requestID string, userID id.User, transactionID string) error
 }
 
 type moovAccountsClient struct {
@@ -33,11 +32,7 @@ type moovAccountsClient struct {
 	logger     log.Logger
 }
 
-func (c *moovAccountsClient) Ping() error {
-	// create a context just for this so ping requests don't require the setup of one
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovAccountsClient) Ping(ctx context.Context) error {
 	resp, err := c.underlying.AccountsApi.Ping(ctx)
 	if resp != nil && resp.Body != nil {
 		resp.Body.Close()
@@ -57,14 +52,11 @@ type transactionLine struct {
 	Amount    int32
 }
 
-func (c *moovAccountsClient) PostTransaction(requestID string, userID id.User, lines []transactionLine) (*accounts.Transaction, error) {
+func (c *moovAccountsClient) PostTransaction(ctx context.Context, requestID string, userID id.User, lines []transactionLine) (*accounts.Transaction, error) {
 	if len(lines) == 0 {
 		return nil, errors.New("accounts: no transactionLine's")
 	}
 
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
 	var accountsLines []accounts.TransactionLine
 	for i := range lines {
 		accountsLines = append(accountsLines, accounts.TransactionLine{
@@ -86,10 +78,7 @@ func (c *moovAccountsClient) PostTransaction(requestID string, userID id.User, l
 	return &tx, nil
 }
 
-func (c *moovAccountsClient) SearchAccounts(requestID string, userID id.User, dep *Depository) (*accounts.Account, error) {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovAccountsClient) SearchAccounts(ctx context.Context, requestID string, userID id.User, dep *Depository) (*accounts.Account, error) {
 	c.logger.Log("accounts", fmt.Sprintf("searching for depository=%s account", dep.ID), "requestID", requestID)
 
 	num, err := dep.DecryptAccountNumber()
@@ -115,10 +104,7 @@ func (c *moovAccountsClient) SearchAccounts(requestID string, userID id.User, de
 	return &accounts[0], nil
 }
 
-func (c *moovAccountsClient) ReverseTransaction(requestID string, userID id.User, transactionID string) error {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovAccountsClient) ReverseTransaction(ctx context.Context, requestID string, userID id.User, transactionID string) error {
 	c.logger.Log("accounts", fmt.Sprintf("reversing transaction=%s", transactionID), "requestID", requestID)
 
 	opts := &accounts.ReverseTransactionOpts{
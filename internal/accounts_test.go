@@ -24,7 +24,8 @@ type testAccountsClient struct {
 	accounts    []accounts.Account
 	transaction *accounts.Transaction
 
-	postedTransactions []accountsTransaction
+	postedTransactions   []accountsTransaction
+	reversedTransactions []string
 
 	err error
 }
@@ -33,11 +34,11 @@ type accountsTransaction struct {
 	Lines []transactionLine
 }
 
-func (c *testAccountsClient) Ping() error {
+func (c *testAccountsClient) Ping(ctx context.Context) error {
 	return c.err
 }
 
-func (c *testAccountsClient) PostTransaction(requestID string, userID id.User, lines []transactionLine) (*accounts.Transaction, error) {
+func (c *testAccountsClient) PostTransaction(ctx context.Context, requestID string, userID id.User, lines []transactionLine) (*accounts.Transaction, error) {
 	if len(lines) == 0 {
 		return nil, errors.New("no transactionLine's")
 	}
@@ -50,7 +51,7 @@ func (c *testAccountsClient) PostTransaction(requestID string, userID id.User, l
 	return c.transaction, nil // yea, this doesn't match, but callers are expected to override testAccountsClient properties
 }
 
-func (c *testAccountsClient) SearchAccounts(requestID string, userID id.User, dep *Depository) (*accounts.Account, error) {
+func (c *testAccountsClient) SearchAccounts(ctx context.Context, requestID string, userID id.User, dep *Depository) (*accounts.Account, error) {
 	if c.err != nil {
 		return nil, c.err
 	}
@@ -60,8 +61,12 @@ func (c *testAccountsClient) SearchAccounts(requestID string, userID id.User, de
 	return nil, nil
 }
 
-func (c *testAccountsClient) ReverseTransaction(requestID string, userID id.User, transactionID string) error {
-	return c.err
+func (c *testAccountsClient) ReverseTransaction(ctx context.Context, requestID string, userID id.User, transactionID string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.reversedTransactions = append(c.reversedTransactions, transactionID)
+	return nil
 }
 
 type accountsDeployment struct {
@@ -105,7 +110,7 @@ func spawnAccounts(t *testing.T) *accountsDeployment {
 	addr := fmt.Sprintf("http://localhost:%s", resource.GetPort("8080/tcp"))
 	client := CreateAccountsClient(log.NewNopLogger(), addr, nil)
 	err = pool.Retry(func() error {
-		return client.Ping()
+		return client.Ping(context.Background())
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -121,7 +126,7 @@ func TestAccounts__client(t *testing.T) {
 
 	// Spawn Accounts Docker image and ping against it
 	deployment := spawnAccounts(t)
-	if err := deployment.client.Ping(); err != nil {
+	if err := deployment.client.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 	deployment.close(t) // close only if successful
@@ -151,7 +156,7 @@ func TestAccounts(t *testing.T) {
 		{AccountID: toAccount.ID, Purpose: "achcredit", Amount: 10000},
 		{AccountID: fromAccount.ID, Purpose: "achdebit", Amount: 10000},
 	}
-	tx, err := deployment.client.PostTransaction(base.ID(), userID, lines)
+	tx, err := deployment.client.PostTransaction(context.Background(), base.ID(), userID, lines)
 	if err != nil || tx == nil {
 		t.Fatalf("transaction=%v error=%v", tx, err)
 	}
@@ -167,7 +172,7 @@ func TestAccounts(t *testing.T) {
 	}
 	dep.ReplaceAccountNumber(fromAccount.AccountNumber)
 
-	account, err := deployment.client.SearchAccounts(base.ID(), userID, dep)
+	account, err := deployment.client.SearchAccounts(context.Background(), base.ID(), userID, dep)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -184,7 +189,7 @@ func TestAccounts(t *testing.T) {
 	}
 	dep.ReplaceAccountNumber(toAccount.AccountNumber)
 
-	account, err = deployment.client.SearchAccounts(base.ID(), userID, dep)
+	account, err = deployment.client.SearchAccounts(context.Background(), base.ID(), userID, dep)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -233,13 +238,13 @@ func TestAccounts__ReverseTransaction(t *testing.T) {
 		{AccountID: toAccount.ID, Purpose: "achcredit", Amount: 10000},
 		{AccountID: fromAccount.ID, Purpose: "achdebit", Amount: 10000},
 	}
-	tx, err := deployment.client.PostTransaction(base.ID(), userID, lines)
+	tx, err := deployment.client.PostTransaction(context.Background(), base.ID(), userID, lines)
 	if err != nil || tx == nil {
 		t.Fatalf("transaction=%v error=%v", tx, err)
 	}
 
 	// Reverse the posted Transaction
-	if err := client.ReverseTransaction("", userID, tx.ID); err != nil {
+	if err := client.ReverseTransaction(context.Background(), "", userID, tx.ID); err != nil {
 		t.Fatal(err)
 	}
 
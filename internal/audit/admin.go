@@ -0,0 +1,89 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/moov-io/base/admin"
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// RegisterAdminRoutes adds 'GET /audit' to the admin server, which returns the newest audit log
+// entries first. Entries can be filtered with the admin, since and until (RFC 3339) query
+// parameters and limit caps how many are returned.
+func RegisterAdminRoutes(logger log.Logger, svc *admin.Server, repo Repository) {
+	svc.AddHandler("/audit", listEntries(logger, repo))
+}
+
+func readListParams(r *http.Request) (listParams, error) {
+	q := r.URL.Query()
+	params := listParams{
+		Admin: q.Get("admin"),
+		Until: time.Now(),
+		Limit: defaultListLimit,
+	}
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return params, fmt.Errorf("invalid since: %v", err)
+		}
+		params.Since = t
+	}
+	if v := q.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return params, fmt.Errorf("invalid until: %v", err)
+		}
+		params.Until = t
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxListLimit {
+			return params, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		params.Limit = n
+	}
+	return params, nil
+}
+
+func listEntries(logger log.Logger, repo Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			moovhttp.Problem(w, fmt.Errorf("unsupported HTTP verb: %s", r.Method))
+			return
+		}
+
+		params, err := readListParams(r)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		entries, err := repo.list(r.Context(), params)
+		if err != nil {
+			logger.Log("audit", fmt.Sprintf("problem reading audit log: %v", err), "requestID", moovhttp.GetRequestID(r))
+			moovhttp.Problem(w, err)
+			return
+		}
+		if entries == nil {
+			entries = []*Entry{}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(entries)
+	}
+}
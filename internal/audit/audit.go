@@ -0,0 +1,299 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+// Package audit keeps an append-only log of requests made to the admin server.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moov-io/base"
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/tracing"
+	"github.com/moov-io/paygate/internal/util"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// maxRequestBody is how much of each request body is recorded, larger bodies are still
+	// passed along in full.
+	maxRequestBody int64 = 64 * 1024
+
+	auditFailures = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: "audit_log_failures",
+		Help: "Counter of admin requests which couldn't be written to the audit log",
+	}, nil)
+)
+
+// Entry records one request made to the admin server.
+type Entry struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+
+	// Admin is the name of the token which authenticated the request and Role is what it was granted.
+	// Both are empty for requests without valid credentials.
+	Admin string `json:"admin"`
+	Role  string `json:"role"`
+
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Route is the admin route template Path matched, e.g. /configs/uploads/ftp/{routingNumber}
+	Route string `json:"route"`
+
+	RequestID  string `json:"requestID"`
+	RemoteAddr string `json:"remoteAddr"`
+	Status     int    `json:"status"`
+
+	// Request is the request body with passwords, secrets, tokens and private keys redacted.
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// Before and After are snapshots of what the route changes, taken around requests which
+	// aren't GET or HEAD.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// SnapshotFunc returns the current state of whatever r would change. The result is marshaled
+// as JSON, so secrets need to be removed or masked first.
+type SnapshotFunc func(r *http.Request) (interface{}, error)
+
+// Recorder writes an Entry for each admin request. Entries are only ever appended.
+type Recorder struct {
+	logger    log.Logger
+	repo      Repository
+	snapshots map[string]SnapshotFunc
+}
+
+func NewRecorder(logger log.Logger, repo Repository) *Recorder {
+	return &Recorder{
+		logger:    logger,
+		repo:      repo,
+		snapshots: make(map[string]SnapshotFunc),
+	}
+}
+
+// AddSnapshot takes before and after snapshots with fn for requests which change route.
+func (rec *Recorder) AddSnapshot(route string, fn SnapshotFunc) {
+	rec.snapshots[route] = fn
+}
+
+// Append writes entry to the audit log. Failures are logged as the request has already been served.
+// Entries are written even when ctx was cancelled by the admin disconnecting.
+func (rec *Recorder) Append(ctx context.Context, entry *Entry) {
+	entry.ID = base.ID()
+	entry.Time = time.Now()
+	if err := rec.repo.append(util.Detach(ctx), entry); err != nil {
+		auditFailures.Add(1)
+		rec.logger.Log("audit", fmt.Sprintf("problem writing audit log entry: %v", err), "requestID", entry.RequestID, "admin", entry.Admin, "path", entry.Path)
+	}
+}
+
+// Record calls next and appends entry once it has returned. The fields describing the request and
+// its response are filled in from r and what next writes.
+func (rec *Recorder) Record(entry *Entry, w http.ResponseWriter, r *http.Request, next http.Handler) {
+	entry.Method = r.Method
+	entry.Path = r.URL.RequestURI()
+	entry.RequestID = moovhttp.GetRequestID(r)
+	entry.RemoteAddr = r.RemoteAddr
+
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestBody))
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		entry.Request = redactBody(body)
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	}
+
+	snapshot := rec.snapshots[entry.Route]
+	if r.Method == "GET" || r.Method == "HEAD" {
+		snapshot = nil
+	}
+	if snapshot != nil {
+		entry.Before = rec.snapshot(snapshot, r)
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(sw, r)
+	entry.Status = sw.status
+
+	if snapshot != nil {
+		entry.After = rec.snapshot(snapshot, r)
+	}
+	rec.Append(r.Context(), entry)
+}
+
+func (rec *Recorder) snapshot(fn SnapshotFunc, r *http.Request) json.RawMessage {
+	v, err := fn(r)
+	if err == nil {
+		var bs []byte
+		if bs, err = json.Marshal(v); err == nil {
+			return bs
+		}
+	}
+	rec.logger.Log("audit", fmt.Sprintf("problem taking snapshot of %s: %v", r.URL.Path, err), "requestID", moovhttp.GetRequestID(r))
+	bs, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return bs
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// redactBody returns body as JSON with the values of any sensitive looking fields replaced.
+// Bodies which aren't JSON are recorded by their length.
+func redactBody(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		bs, _ := json.Marshal(map[string]int{"length": len(body)})
+		return bs
+	}
+	bs, _ := json.Marshal(redactValue(v))
+	return bs
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			if sensitive(k) {
+				v[k] = "REDACTED"
+			} else {
+				v[k] = redactValue(v[k])
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return v
+}
+
+func sensitive(field string) bool {
+	field = strings.ToLower(field)
+	for _, s := range []string{"password", "secret", "token", "privatekey"} {
+		if strings.Contains(field, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Repository stores audit log entries. It can only append and read them.
+type Repository interface {
+	append(ctx context.Context, entry *Entry) error
+	list(ctx context.Context, params listParams) ([]*Entry, error)
+}
+
+type listParams struct {
+	Admin string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+func NewRepo(logger log.Logger, db *sql.DB) *SQLRepo {
+	return &SQLRepo{logger: logger, db: db}
+}
+
+type SQLRepo struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func (r *SQLRepo) Close() error {
+	return r.db.Close()
+}
+
+func (r *SQLRepo) append(ctx context.Context, entry *Entry) (err error) {
+	ctx, span := tracing.Start(ctx, "auditRepo.append", "route", entry.Route)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `insert into audit_log (entry_id, created_at, admin_name, admin_role, method, path, route, request_id, remote_addr, status, request, before_snapshot, after_snapshot)
+values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx,
+		entry.ID, entry.Time, entry.Admin, entry.Role, entry.Method, entry.Path, entry.Route,
+		entry.RequestID, entry.RemoteAddr, entry.Status,
+		string(entry.Request), string(entry.Before), string(entry.After),
+	)
+	return err
+}
+
+func (r *SQLRepo) list(ctx context.Context, params listParams) ([]*Entry, error) {
+	query := `select entry_id, created_at, admin_name, admin_role, method, path, route, request_id, remote_addr, status, request, before_snapshot, after_snapshot
+from audit_log where created_at >= ? and created_at <= ?`
+	args := []interface{}{params.Since, params.Until}
+	if params.Admin != "" {
+		query += ` and admin_name = ?`
+		args = append(args, params.Admin)
+	}
+	query += ` order by created_at desc limit ?;`
+	args = append(args, params.Limit)
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		var entry Entry
+		var request, before, after string
+		err := rows.Scan(
+			&entry.ID, &entry.Time, &entry.Admin, &entry.Role, &entry.Method, &entry.Path, &entry.Route,
+			&entry.RequestID, &entry.RemoteAddr, &entry.Status, &request, &before, &after,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.Request, entry.Before, entry.After = rawJSON(request), rawJSON(before), rawJSON(after)
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
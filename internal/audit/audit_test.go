@@ -0,0 +1,231 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base/admin"
+	"github.com/moov-io/paygate/internal/database"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRepo(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLRepo) {
+		defer repo.Close()
+
+		rec := NewRecorder(log.NewNopLogger(), repo)
+		rec.Append(context.Background(), &Entry{Admin: "alice", Role: "operator", Method: "POST", Path: "/files/flush", Route: "/files/flush", Status: 200})
+		rec.Append(context.Background(), &Entry{
+			Admin:   "bob",
+			Role:    "security",
+			Method:  "PUT",
+			Path:    "/configs/uploads/ftp/987654320",
+			Route:   "/configs/uploads/ftp/{routingNumber}",
+			Status:  200,
+			Request: json.RawMessage(`{"password":"REDACTED"}`),
+			Before:  json.RawMessage(`{"ftp":{"Password":"p**s"}}`),
+			After:   json.RawMessage(`{"ftp":{"Password":"n**w"}}`),
+		})
+
+		entries, err := repo.list(context.Background(), listParams{Until: time.Now().Add(time.Second), Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("unexpected entries: %#v", entries)
+		}
+		if entries[0].ID == "" || entries[0].Time.IsZero() || entries[0].Status != 200 {
+			t.Errorf("unexpected entry: %#v", entries[0])
+		}
+
+		// filter by admin
+		entries, err = repo.list(context.Background(), listParams{Admin: "bob", Until: time.Now().Add(time.Second), Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Route != "/configs/uploads/ftp/{routingNumber}" {
+			t.Fatalf("unexpected entries: %#v", entries)
+		}
+		if string(entries[0].Before) != `{"ftp":{"Password":"p**s"}}` || string(entries[0].After) != `{"ftp":{"Password":"n**w"}}` {
+			t.Errorf("unexpected snapshots: %s %s", entries[0].Before, entries[0].After)
+		}
+
+		// entries before since are skipped
+		entries, err = repo.list(context.Background(), listParams{Since: time.Now().Add(time.Hour), Until: time.Now().Add(2 * time.Hour), Limit: 10})
+		if err != nil || len(entries) != 0 {
+			t.Errorf("entries=%#v error=%v", entries, err)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewRepo(log.NewNopLogger(), sqliteDB.DB))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewRepo(log.NewNopLogger(), postgresDB.DB))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewRepo(log.NewNopLogger(), mysqlDB.DB))
+}
+
+func TestRecorder(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := NewRepo(log.NewNopLogger(), db.DB)
+
+	rec := NewRecorder(log.NewNopLogger(), repo)
+	status := "active"
+	rec.AddSnapshot("/status", func(r *http.Request) (interface{}, error) {
+		return map[string]string{"status": status}, nil
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		if r.Method == "PUT" && !strings.Contains(string(bs), "hunter2") {
+			t.Errorf("handler didn't get the original body: %s", bs)
+		}
+		status = "disabled"
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	req := httptest.NewRequest("PUT", "/status?force=yes", strings.NewReader(`{"status": "disabled", "creds": {"Password": "hunter2"}}`))
+	req.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	rec.Record(&Entry{Admin: "alice", Role: "operator", Route: "/status"}, w, req, next)
+	if w.Code != http.StatusAccepted {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+
+	entries, err := repo.list(context.Background(), listParams{Until: time.Now().Add(time.Second), Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("unexpected entries: %#v", entries)
+	}
+	entry := entries[0]
+	if entry.Admin != "alice" || entry.Method != "PUT" || entry.Path != "/status?force=yes" || entry.RequestID != "req-1" || entry.Status != http.StatusAccepted {
+		t.Errorf("unexpected entry: %#v", entry)
+	}
+	if string(entry.Request) != `{"creds":{"Password":"REDACTED"},"status":"disabled"}` {
+		t.Errorf("unexpected request: %s", entry.Request)
+	}
+	if string(entry.Before) != `{"status":"active"}` || string(entry.After) != `{"status":"disabled"}` {
+		t.Errorf("unexpected snapshots: %s %s", entry.Before, entry.After)
+	}
+
+	// GET requests don't take snapshots and failed snapshots are recorded
+	rec.AddSnapshot("/status", func(r *http.Request) (interface{}, error) {
+		return nil, errors.New("bad thing")
+	})
+	rec.Record(&Entry{Route: "/status"}, httptest.NewRecorder(), httptest.NewRequest("GET", "/status", nil), next)
+	rec.Record(&Entry{Route: "/status"}, httptest.NewRecorder(), httptest.NewRequest("DELETE", "/status", nil), next)
+
+	entries, err = repo.list(context.Background(), listParams{Until: time.Now().Add(time.Second), Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries[:2] {
+		switch entry.Method {
+		case "GET":
+			if entry.Before != nil || entry.After != nil {
+				t.Errorf("unexpected snapshots: %s %s", entry.Before, entry.After)
+			}
+		case "DELETE":
+			if string(entry.Before) != `{"error":"bad thing"}` {
+				t.Errorf("unexpected snapshot: %s", entry.Before)
+			}
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := map[string]string{
+		``:                                      ``,
+		`not json`:                              `{"length":8}`,
+		`{"scopes":["transfers:read"]}`:         `{"scopes":["transfers:read"]}`,
+		`[{"clientPrivateKey":"-----BEGIN"}]`:   `[{"clientPrivateKey":"REDACTED"}]`,
+		`{"secret":{"nested":"value"},"a":1}`:   `{"a":1,"secret":"REDACTED"}`,
+		`{"SERVER_TOKEN":"abc","user":"alice"}`: `{"SERVER_TOKEN":"REDACTED","user":"alice"}`,
+	}
+	for body, expected := range cases {
+		if out := string(redactBody([]byte(body))); out != expected {
+			t.Errorf("%s: got %s", body, out)
+		}
+	}
+}
+
+func TestAdminRoutes(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := NewRepo(log.NewNopLogger(), db.DB)
+
+	rec := NewRecorder(log.NewNopLogger(), repo)
+	rec.Append(context.Background(), &Entry{Admin: "alice", Method: "POST", Path: "/files/flush", Status: 200})
+	rec.Append(context.Background(), &Entry{Admin: "bob", Method: "GET", Path: "/config", Status: 200})
+
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+	RegisterAdminRoutes(log.NewNopLogger(), svc, repo)
+
+	get := func(query string) ([]*Entry, int) {
+		t.Helper()
+		resp, err := http.Get("http://" + svc.BindAddr() + "/audit" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var entries []*Entry
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return entries, resp.StatusCode
+	}
+
+	if entries, status := get(""); status != http.StatusOK || len(entries) != 2 {
+		t.Errorf("bogus HTTP status %d: %#v", status, entries)
+	}
+	if entries, status := get("?admin=alice&limit=5"); status != http.StatusOK || len(entries) != 1 || entries[0].Path != "/files/flush" {
+		t.Errorf("bogus HTTP status %d: %#v", status, entries)
+	}
+	if entries, status := get("?since=" + time.Now().Add(time.Hour).Format(time.RFC3339)); status != http.StatusOK || len(entries) != 0 {
+		t.Errorf("bogus HTTP status %d: %#v", status, entries)
+	}
+	for _, query := range []string{"?limit=0", "?limit=5000", "?since=yesterday", "?until=1"} {
+		if _, status := get(query); status != http.StatusBadRequest {
+			t.Errorf("%s: bogus HTTP status %d", query, status)
+		}
+	}
+
+	req, _ := http.NewRequest("DELETE", "http://"+svc.BindAddr()+"/audit", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/moov-io/base/admin"
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+// RegisterAdminRoutes adds routes to create, list and delete API keys. The user is read from the
+// X-User-ID header, as these routes are only on the admin server.
+func RegisterAdminRoutes(logger log.Logger, svc *admin.Server, repo APIKeyRepository) {
+	svc.AddHandler("/api-keys", manageAPIKeys(logger, repo))
+	svc.AddHandler("/api-keys/{keyId}", deleteAPIKey(logger, repo))
+}
+
+// APIKeysSnapshot returns the API keys (without the keys themselves) of the user in the X-User-ID
+// header, which the audit log records before and after keys are created or deleted.
+func APIKeysSnapshot(repo APIKeyRepository) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		userID := id.User(moovhttp.GetUserID(r))
+		if userID == "" {
+			return nil, nil
+		}
+		return repo.getUserKeys(r.Context(), userID)
+	}
+}
+
+type createAPIKeyRequest struct {
+	Scopes []string `json:"scopes"`
+}
+
+func manageAPIKeys(logger log.Logger, repo APIKeyRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := id.User(moovhttp.GetUserID(r))
+		if userID == "" {
+			moovhttp.Problem(w, errors.New("missing X-User-ID header"))
+			return
+		}
+		requestID := moovhttp.GetRequestID(r)
+
+		switch r.Method {
+		case "GET":
+			keys, err := repo.getUserKeys(r.Context(), userID)
+			if err != nil {
+				logger.Log("auth", fmt.Sprintf("problem reading API keys: %v", err), "requestID", requestID, "userID", userID)
+				moovhttp.Problem(w, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(keys)
+
+		case "POST":
+			var req createAPIKeyRequest
+			if err := json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&req); err != nil {
+				moovhttp.Problem(w, err)
+				return
+			}
+			if len(req.Scopes) == 0 {
+				moovhttp.Problem(w, errors.New("missing scopes"))
+				return
+			}
+			if err := validateScopes(req.Scopes); err != nil {
+				moovhttp.Problem(w, err)
+				return
+			}
+			key, hash, err := newAPIKey(userID, req.Scopes)
+			if err != nil {
+				moovhttp.Problem(w, err)
+				return
+			}
+			if err := repo.createKey(r.Context(), key, hash); err != nil {
+				logger.Log("auth", fmt.Sprintf("problem saving API key: %v", err), "requestID", requestID, "userID", userID)
+				moovhttp.Problem(w, err)
+				return
+			}
+			logger.Log("auth", fmt.Sprintf("created API key=%s", key.ID), "requestID", requestID, "userID", userID)
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(key)
+
+		default:
+			moovhttp.Problem(w, fmt.Errorf("unsupported HTTP verb: %s", r.Method))
+		}
+	}
+}
+
+func deleteAPIKey(logger log.Logger, repo APIKeyRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			moovhttp.Problem(w, fmt.Errorf("unsupported HTTP verb: %s", r.Method))
+			return
+		}
+		keyID := mux.Vars(r)["keyId"]
+		if keyID == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := repo.deleteKey(r.Context(), keyID); err != nil {
+			logger.Log("auth", fmt.Sprintf("problem deleting API key=%s: %v", keyID, err), "requestID", moovhttp.GetRequestID(r))
+			moovhttp.Problem(w, err)
+			return
+		}
+		logger.Log("auth", fmt.Sprintf("deleted API key=%s", keyID), "requestID", moovhttp.GetRequestID(r))
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/audit"
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+// Role is granted to each admin token. Viewers can read configs and features, operators can also
+// change how files are processed and security admins manage credentials, keys and the audit log.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleSecurity Role = "security"
+)
+
+// allows returns true if role can call a route which requires the given role. Every role
+// includes viewer, but operator and security are separate.
+func (role Role) allows(required Role) bool {
+	return role == required || (role != "" && required == RoleViewer)
+}
+
+type adminRoute struct {
+	path string
+	role Role // empty for public routes
+}
+
+// adminRoutes is the role each admin route requires. Routes not listed here (e.g. /debug/pprof)
+// require the security role.
+var adminRoutes = []adminRoute{
+	// probes and metrics are scraped without credentials
+	{path: "/live"},
+	{path: "/ready"},
+	{path: "/metrics"},
+	{path: "/version"},
+
+	{path: "/config", role: RoleViewer},
+	{path: "/features", role: RoleViewer},
+	{path: "/configs/uploads", role: RoleViewer},
+	{path: "/files/health", role: RoleViewer},
+
+	{path: "/configs/uploads/cutoff-times/{routingNumber}", role: RoleOperator},
+	{path: "/configs/uploads/file-transfers/{routingNumber}", role: RoleOperator},
+	{path: "/depositories/{depositoryId}", role: RoleOperator},
+	{path: "/depositories/{depositoryId}/micro-deposits", role: RoleOperator},
+	{path: "/files/flush", role: RoleOperator},
+	{path: "/files/flush/incoming", role: RoleOperator},
+	{path: "/files/flush/outgoing", role: RoleOperator},
+
+	{path: "/configs/uploads/ftp/{routingNumber}", role: RoleSecurity},
+	{path: "/configs/uploads/sftp/{routingNumber}", role: RoleSecurity},
+	{path: "/account-numbers/plaintext", role: RoleSecurity},
+	{path: "/account-numbers/rotation", role: RoleSecurity},
+	{path: "/api-keys", role: RoleSecurity},
+	{path: "/api-keys/{keyId}", role: RoleSecurity},
+	{path: "/audit", role: RoleSecurity},
+}
+
+type adminToken struct {
+	name string
+	role Role
+	hash []byte
+}
+
+// AdminGate sits in front of the admin server. It authenticates each request with a bearer token,
+// checks the token's Role is allowed to call the route and records the request in the audit log
+// before passing it along.
+type AdminGate struct {
+	logger   log.Logger
+	tokens   []adminToken
+	recorder *audit.Recorder
+	proxy    http.Handler
+	router   *mux.Router
+}
+
+// NewAdminGate returns an AdminGate which passes allowed requests to the admin server bound at adminAddr.
+func NewAdminGate(logger log.Logger, cfg config.AdminAuthConfig, recorder *audit.Recorder, adminAddr string) (*AdminGate, error) {
+	if recorder == nil {
+		return nil, errors.New("auth: admin gate needs an audit log")
+	}
+	g := &AdminGate{
+		logger:   logger,
+		recorder: recorder,
+		proxy:    httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: adminAddr}),
+		router:   mux.NewRouter(),
+	}
+	for _, token := range cfg.Tokens {
+		g.tokens = append(g.tokens, adminToken{
+			name: token.Name,
+			role: Role(token.Role),
+			hash: []byte(strings.ToLower(token.SHA256)),
+		})
+	}
+	for i := range adminRoutes {
+		g.router.Path(adminRoutes[i].path).Handler(g.handle(adminRoutes[i]))
+	}
+	g.router.PathPrefix("/").Handler(g.handle(adminRoute{role: RoleSecurity}))
+	return g, nil
+}
+
+// Enabled returns true if any admin tokens are configured, otherwise only public routes can be called.
+func (g *AdminGate) Enabled() bool {
+	return len(g.tokens) > 0
+}
+
+func (g *AdminGate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.router.ServeHTTP(w, r)
+}
+
+func (g *AdminGate) handle(route adminRoute) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route.role == "" {
+			g.proxy.ServeHTTP(w, r)
+			return
+		}
+
+		entry := &audit.Entry{Route: route.path}
+		token, err := g.authenticate(r)
+		if token != nil {
+			entry.Admin, entry.Role = token.name, string(token.role)
+		}
+		switch {
+		case err != nil:
+			authFailures.With("reason", "admin-invalid").Add(1)
+			g.reject(entry, w, r, http.StatusUnauthorized, err)
+		case token == nil:
+			authFailures.With("reason", "admin-unauthenticated").Add(1)
+			g.reject(entry, w, r, http.StatusUnauthorized, errUnauthenticated)
+		case !token.role.allows(route.role):
+			authFailures.With("reason", "admin-role").Add(1)
+			g.reject(entry, w, r, http.StatusForbidden, fmt.Errorf("requires the %s role", route.role))
+		default:
+			r.Header.Del("Authorization") // the admin server doesn't need the token
+			g.recorder.Record(entry, w, r, g.proxy)
+		}
+	})
+}
+
+func (g *AdminGate) reject(entry *audit.Entry, w http.ResponseWriter, r *http.Request, status int, err error) {
+	requestID := moovhttp.GetRequestID(r)
+	g.logger.Log("admin", fmt.Sprintf("rejected %s %s: %v", r.Method, r.URL.Path, err), "requestID", requestID, "admin", entry.Admin)
+
+	problem(w, status, err)
+
+	entry.Method = r.Method
+	entry.Path = r.URL.RequestURI()
+	entry.RequestID = requestID
+	entry.RemoteAddr = r.RemoteAddr
+	entry.Status = status
+	g.recorder.Append(r.Context(), entry)
+}
+
+// authenticate returns the admin token sent with r, nil when there's no token or an error if the
+// token isn't configured.
+func (g *AdminGate) authenticate(r *http.Request) (*adminToken, error) {
+	raw := r.Header.Get("Authorization")
+	if raw == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(raw, "Bearer ") {
+		return nil, errors.New("expected a bearer token")
+	}
+	hash := []byte(hashAPIKey(strings.TrimSpace(strings.TrimPrefix(raw, "Bearer "))))
+
+	var found *adminToken
+	for i := range g.tokens {
+		// compare every token so timing doesn't reveal which matched
+		if subtle.ConstantTimeCompare(g.tokens[i].hash, hash) == 1 {
+			found = &g.tokens[i]
+		}
+	}
+	if found == nil {
+		return nil, errors.New("unknown admin token")
+	}
+	return found, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/base/admin"
+	"github.com/moov-io/paygate/internal/audit"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/database"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestRole(t *testing.T) {
+	cases := []struct {
+		role, required Role
+		allowed        bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleOperator, RoleViewer, true},
+		{RoleSecurity, RoleViewer, true},
+		{RoleViewer, RoleOperator, false},
+		{RoleSecurity, RoleOperator, false},
+		{RoleOperator, RoleSecurity, false},
+		{RoleSecurity, RoleSecurity, true},
+		{"", RoleViewer, false},
+	}
+	for _, tc := range cases {
+		if tc.role.allows(tc.required) != tc.allowed {
+			t.Errorf("%q allows %q: expected %v", tc.role, tc.required, tc.allowed)
+		}
+	}
+}
+
+func TestAdminGate(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	auditRepo := audit.NewRepo(log.NewNopLogger(), db.DB)
+
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	flushed := 0
+	svc.AddHandler("/files/flush", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("admin token was passed along")
+		}
+		flushed++
+		w.WriteHeader(http.StatusOK)
+	})
+	audit.RegisterAdminRoutes(log.NewNopLogger(), svc, auditRepo)
+
+	cfg := config.AdminAuthConfig{
+		Tokens: []config.AdminToken{
+			{Name: "vera", Role: "viewer", SHA256: hashAPIKey("viewer-token")},
+			{Name: "otto", Role: "operator", SHA256: hashAPIKey("operator-token")},
+			{Name: "sam", Role: "security", SHA256: strings.ToUpper(hashAPIKey("security-token"))},
+		},
+	}
+	gate, err := NewAdminGate(log.NewNopLogger(), cfg, audit.NewRecorder(log.NewNopLogger(), auditRepo), svc.BindAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gate.Enabled() {
+		t.Error("expected gate to be enabled")
+	}
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		gate.ServeHTTP(w, req)
+		return w
+	}
+
+	// probes don't need a token
+	if w := do("GET", "/live", ""); w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// missing and unknown tokens
+	if w := do("POST", "/files/flush", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/files/flush", "guess"); w.Code != http.StatusUnauthorized {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// viewers and security admins can't flush files
+	if w := do("POST", "/files/flush", "viewer-token"); w.Code != http.StatusForbidden {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/files/flush", "security-token"); w.Code != http.StatusForbidden {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/files/flush", "operator-token"); w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if flushed != 1 {
+		t.Errorf("flushed %d times", flushed)
+	}
+
+	// unlisted routes require the security role
+	if w := do("GET", "/debug/pprof/", "operator-token"); w.Code != http.StatusForbidden {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/debug/pprof/", "security-token"); w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// only security admins read the audit log
+	if w := do("GET", "/audit", "operator-token"); w.Code != http.StatusForbidden {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	w := do("GET", "/audit?limit=20", "security-token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	var entries []*audit.Entry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	// every request except /live and the one reading the log is recorded
+	if len(entries) != 8 {
+		t.Fatalf("unexpected entries: %d", len(entries))
+	}
+	var rejected, allowed int
+	for _, entry := range entries {
+		switch {
+		case entry.Route == "/files/flush" && entry.Status == http.StatusOK:
+			allowed++
+			if entry.Admin != "otto" || entry.Role != "operator" || entry.Method != "POST" {
+				t.Errorf("unexpected entry: %#v", entry)
+			}
+		case entry.Status == http.StatusUnauthorized || entry.Status == http.StatusForbidden:
+			rejected++
+		}
+	}
+	if allowed != 1 || rejected != 6 {
+		t.Errorf("allowed=%d rejected=%d", allowed, rejected)
+	}
+}
+
+func TestAdminGate__noTokens(t *testing.T) {
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	gate, err := NewAdminGate(log.NewNopLogger(), config.AdminAuthConfig{}, audit.NewRecorder(log.NewNopLogger(), audit.NewRepo(log.NewNopLogger(), db.DB)), svc.BindAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gate.Enabled() {
+		t.Error("expected gate to be disabled")
+	}
+
+	w := httptest.NewRecorder()
+	gate.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	if _, err := NewAdminGate(log.NewNopLogger(), config.AdminAuthConfig{}, nil, svc.BindAddr()); err == nil {
+		t.Error("expected error")
+	}
+}
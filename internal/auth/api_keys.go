@@ -0,0 +1,184 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/tracing"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	errInvalidAPIKey = errors.New("invalid API key")
+)
+
+// APIKey is a credential for one user. Only a hash of the key is stored, the key itself is
+// returned once when it's created.
+type APIKey struct {
+	ID      string    `json:"keyID"`
+	UserID  id.User   `json:"userID"`
+	Scopes  []string  `json:"scopes"`
+	Created time.Time `json:"created"`
+
+	// Key is only set when the APIKey is created
+	Key string `json:"key,omitempty"`
+}
+
+// APIKeyRepository stores hashed API keys.
+type APIKeyRepository interface {
+	lookupKey(ctx context.Context, hash string) (*APIKey, error)
+	getUserKeys(ctx context.Context, userID id.User) ([]*APIKey, error)
+	createKey(ctx context.Context, key *APIKey, hash string) error
+	deleteKey(ctx context.Context, keyID string) error
+}
+
+// newAPIKey generates a random key for userID, returning the APIKey (with Key set) and the hash to store.
+func newAPIKey(userID id.User, scopes []string) (*APIKey, string, error) {
+	bs := make([]byte, 32)
+	if _, err := rand.Read(bs); err != nil {
+		return nil, "", err
+	}
+	key := &APIKey{
+		ID:      base.ID(),
+		UserID:  userID,
+		Scopes:  scopes,
+		Created: time.Now(),
+		Key:     hex.EncodeToString(bs),
+	}
+	return key, hashAPIKey(key.Key), nil
+}
+
+// hashAPIKey returns the SHA-256 hash of key. Keys are random 256-bit values so they don't need a
+// slower password hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyAuthenticator accepts keys sent in the X-API-Key header.
+type apiKeyAuthenticator struct {
+	repo APIKeyRepository
+}
+
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	key := strings.TrimSpace(r.Header.Get("X-API-Key"))
+	if key == "" {
+		return nil, nil
+	}
+	found, err := a.repo.lookupKey(r.Context(), hashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, errInvalidAPIKey
+	}
+	return &Identity{UserID: found.UserID, Scopes: found.Scopes, Method: "api-key"}, nil
+}
+
+func NewAPIKeyRepo(logger log.Logger, db *sql.DB) *SQLAPIKeyRepo {
+	return &SQLAPIKeyRepo{logger: logger, db: db}
+}
+
+type SQLAPIKeyRepo struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func (r *SQLAPIKeyRepo) Close() error {
+	return r.db.Close()
+}
+
+func (r *SQLAPIKeyRepo) lookupKey(ctx context.Context, hash string) (*APIKey, error) {
+	query := `select key_id, user_id, scopes, created_at from api_keys where key_hash = ? and deleted_at is null limit 1;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var key APIKey
+	var scopes string
+	if err := stmt.QueryRowContext(ctx, hash).Scan(&key.ID, &key.UserID, &scopes, &key.Created); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	return &key, nil
+}
+
+func (r *SQLAPIKeyRepo) getUserKeys(ctx context.Context, userID id.User) ([]*APIKey, error) {
+	query := `select key_id, scopes, created_at from api_keys where user_id = ? and deleted_at is null order by created_at;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key := &APIKey{UserID: userID}
+		var scopes string
+		if err := rows.Scan(&key.ID, &scopes, &key.Created); err != nil {
+			return nil, err
+		}
+		key.Scopes = strings.Fields(scopes)
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *SQLAPIKeyRepo) createKey(ctx context.Context, key *APIKey, hash string) (err error) {
+	ctx, span := tracing.Start(ctx, "apiKeyRepo.createKey")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `insert into api_keys (key_id, user_id, key_hash, scopes, created_at) values (?, ?, ?, ?, ?);`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, key.ID, key.UserID, hash, strings.Join(key.Scopes, " "), key.Created)
+	return err
+}
+
+func (r *SQLAPIKeyRepo) deleteKey(ctx context.Context, keyID string) (err error) {
+	ctx, span := tracing.Start(ctx, "apiKeyRepo.deleteKey", "keyID", keyID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `update api_keys set deleted_at = ? where key_id = ? and deleted_at is null;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, time.Now(), keyID)
+	return err
+}
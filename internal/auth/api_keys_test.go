@@ -0,0 +1,182 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/base/admin"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLAPIKeyRepo) {
+		defer repo.Close()
+
+		userID := id.User(base.ID())
+		key, hash, err := newAPIKey(userID, []string{TransfersRead})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.createKey(context.Background(), key, hash); err != nil {
+			t.Fatal(err)
+		}
+
+		a := &apiKeyAuthenticator{repo: repo}
+		req := httptest.NewRequest("GET", "/transfers", nil)
+		req.Header.Set("X-API-Key", key.Key)
+		identity, err := a.Authenticate(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if identity.UserID != userID || !identity.HasScope(TransfersRead) || identity.HasScope(TransfersWrite) {
+			t.Errorf("unexpected identity: %#v", identity)
+		}
+
+		keys, err := repo.getUserKeys(context.Background(), userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != 1 || keys[0].ID != key.ID || keys[0].Key != "" {
+			t.Errorf("unexpected keys: %#v", keys)
+		}
+
+		// unknown key
+		req.Header.Set("X-API-Key", "invalid")
+		if _, err := a.Authenticate(req); err != errInvalidAPIKey {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		// deleted keys are rejected
+		if err := repo.deleteKey(context.Background(), key.ID); err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-API-Key", key.Key)
+		if _, err := a.Authenticate(req); err != errInvalidAPIKey {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewAPIKeyRepo(log.NewNopLogger(), sqliteDB.DB))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewAPIKeyRepo(log.NewNopLogger(), postgresDB.DB))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewAPIKeyRepo(log.NewNopLogger(), mysqlDB.DB))
+}
+
+func TestAPIKeys__AdminRoutes(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := NewAPIKeyRepo(log.NewNopLogger(), db.DB)
+
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	RegisterAdminRoutes(log.NewNopLogger(), svc, repo)
+
+	userID := base.ID()
+	do := func(method, path, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, "http://"+svc.BindAddr()+path, bytes.NewReader([]byte(body)))
+		req.Header.Set("X-User-Id", userID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// create a key and use it
+	resp := do("POST", "/api-keys", `{"scopes": ["depositories:read"]}`)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d", resp.StatusCode)
+	}
+	var key APIKey
+	if err := json.NewDecoder(resp.Body).Decode(&key); err != nil {
+		t.Fatal(err)
+	}
+	if key.Key == "" || key.UserID.String() != userID {
+		t.Errorf("unexpected key: %#v", key)
+	}
+
+	cfg := &config.AuthConfig{}
+	cfg.APIKeys.Enabled = true
+	m, err := NewMiddleware(log.NewNopLogger(), cfg, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := testRouter(m)
+	req := httptest.NewRequest("GET", "/transfers", nil)
+	req.Header.Set("X-API-Key", key.Key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// unknown scope
+	resp = do("POST", "/api-keys", `{"scopes": ["everything"]}`)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", resp.StatusCode)
+	}
+
+	// list keys
+	resp = do("GET", "/api-keys", "")
+	defer resp.Body.Close()
+	var keys []*APIKey
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].ID != key.ID || keys[0].Key != "" {
+		t.Errorf("unexpected keys: %#v", keys)
+	}
+
+	// snapshots for the audit log don't include keys
+	req = httptest.NewRequest("DELETE", "/api-keys/"+key.ID, nil)
+	req.Header.Set("X-User-Id", userID)
+	if v, err := APIKeysSnapshot(repo)(req); err != nil {
+		t.Fatal(err)
+	} else if keys := v.([]*APIKey); len(keys) != 1 || keys[0].Key != "" {
+		t.Errorf("unexpected snapshot: %#v", keys)
+	}
+	req = httptest.NewRequest("GET", "/transfers", nil)
+	req.Header.Set("X-API-Key", key.Key)
+
+	// delete the key
+	resp = do("DELETE", "/api-keys/"+key.ID, "")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d", resp.StatusCode)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
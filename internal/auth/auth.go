@@ -0,0 +1,229 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Scopes which are required by the public HTTP routes.
+const (
+	AuthorizationsRead  = "authorizations:read"
+	AuthorizationsWrite = "authorizations:write"
+	DepositoriesRead    = "depositories:read"
+	DepositoriesWrite   = "depositories:write"
+	EventsRead          = "events:read"
+	GatewaysRead        = "gateways:read"
+	GatewaysWrite       = "gateways:write"
+	OriginatorsRead     = "originators:read"
+	OriginatorsWrite    = "originators:write"
+	ReceiversRead       = "receivers:read"
+	ReceiversWrite      = "receivers:write"
+	TransfersRead       = "transfers:read"
+	TransfersWrite      = "transfers:write"
+)
+
+var (
+	allScopes = []string{
+		AuthorizationsRead, AuthorizationsWrite,
+		DepositoriesRead, DepositoriesWrite,
+		EventsRead,
+		GatewaysRead, GatewaysWrite,
+		OriginatorsRead, OriginatorsWrite,
+		ReceiversRead, ReceiversWrite,
+		TransfersRead, TransfersWrite,
+	}
+
+	errUnauthenticated = errors.New("authentication required")
+
+	authFailures = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: "http_auth_failures",
+		Help: "Counter of HTTP requests rejected for invalid credentials, no credentials or a missing scope",
+	}, []string{"reason"})
+)
+
+// validateScopes returns an error if any scope isn't one of the known scopes.
+func validateScopes(scopes []string) error {
+	for i := range scopes {
+		if !contains(allScopes, scopes[i]) {
+			return fmt.Errorf("auth: unknown scope %q", scopes[i])
+		}
+	}
+	return nil
+}
+
+// scopesOrAll returns scopes when non-empty, otherwise every scope.
+func scopesOrAll(scopes []string) []string {
+	if len(scopes) == 0 {
+		return allScopes
+	}
+	return scopes
+}
+
+func contains(xs []string, s string) bool {
+	for i := range xs {
+		if xs[i] == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Identity is an authenticated user along with the scopes they've been granted.
+type Identity struct {
+	UserID id.User
+	Scopes []string
+
+	// Method is the Authenticator which accepted the request, e.g. "jwt" or "api-key"
+	Method string
+}
+
+// HasScope returns true if the Identity has been granted scope.
+func (i *Identity) HasScope(scope string) bool {
+	if i == nil {
+		return false
+	}
+	return contains(i.Scopes, scope)
+}
+
+type contextKey struct{}
+
+// FromContext returns the Identity of an authenticated request, or nil if the request wasn't authenticated.
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(contextKey{}).(*Identity)
+	return identity
+}
+
+// Authenticator reads credentials from an HTTP request.
+type Authenticator interface {
+	// Authenticate returns nil (and no error) when the request doesn't have credentials this Authenticator
+	// understands. An error is returned for credentials which are invalid.
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// Middleware authenticates every request made to a router and replaces the X-User-ID header with
+// the authenticated user. Requests without credentials continue without an Identity and are rejected
+// by each route which Require's a scope.
+type Middleware struct {
+	logger         log.Logger
+	authenticators []Authenticator
+}
+
+// NewMiddleware creates the Authenticators enabled in cfg. API keys are looked up in apiKeyRepo.
+func NewMiddleware(logger log.Logger, cfg *config.AuthConfig, apiKeyRepo APIKeyRepository) (*Middleware, error) {
+	m := &Middleware{logger: logger}
+
+	if cfg.MTLS.ClientCAFile != "" {
+		if err := validateScopes(cfg.MTLS.Scopes); err != nil {
+			return nil, fmt.Errorf("mtls: %v", err)
+		}
+		m.authenticators = append(m.authenticators, &mtlsAuthenticator{scopes: scopesOrAll(cfg.MTLS.Scopes)})
+	}
+	if cfg.APIKeys.Enabled {
+		if apiKeyRepo == nil {
+			return nil, errors.New("auth: api keys are enabled without a repository")
+		}
+		m.authenticators = append(m.authenticators, &apiKeyAuthenticator{repo: apiKeyRepo})
+	}
+	if cfg.JWT.JWKSFile != "" {
+		a, err := newJWTAuthenticator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		m.authenticators = append(m.authenticators, a)
+	}
+	if cfg.TrustedProxy.Enabled {
+		if err := validateScopes(cfg.TrustedProxy.Scopes); err != nil {
+			return nil, fmt.Errorf("trusted proxy: %v", err)
+		}
+		m.authenticators = append(m.authenticators, &headerAuthenticator{scopes: scopesOrAll(cfg.TrustedProxy.Scopes)})
+	}
+	return m, nil
+}
+
+func (m *Middleware) authenticate(r *http.Request) (*Identity, error) {
+	for i := range m.authenticators {
+		identity, err := m.authenticators[i].Authenticate(r)
+		if err != nil || identity != nil {
+			return identity, err
+		}
+	}
+	return nil, nil
+}
+
+// Handler wraps next and can be added to a router with its Use method.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := m.authenticate(r)
+		if err != nil {
+			m.logger.Log("auth", fmt.Sprintf("rejected credentials for %s %s: %v", r.Method, r.URL.Path, err), "requestID", moovhttp.GetRequestID(r))
+			authFailures.With("reason", "invalid").Add(1)
+			problem(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		// Clients can't choose their userID, it's always the authenticated user.
+		r.Header.Del("X-User-Id")
+		if identity != nil {
+			r.Header.Set("X-User-Id", identity.UserID.String())
+			r = r.WithContext(context.WithValue(r.Context(), contextKey{}, identity))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require wraps next so it's only called for authenticated requests granted scope.
+func Require(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity := FromContext(r.Context())
+		if identity == nil {
+			authFailures.With("reason", "unauthenticated").Add(1)
+			problem(w, http.StatusUnauthorized, errUnauthenticated)
+			return
+		}
+		if !identity.HasScope(scope) {
+			authFailures.With("reason", "scope").Add(1)
+			problem(w, http.StatusForbidden, fmt.Errorf("missing scope %s", scope))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func problem(w http.ResponseWriter, status int, err error) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="paygate"`)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": err.Error(),
+	})
+}
+
+// headerAuthenticator trusts the X-User-ID header, which is only safe behind a proxy that sets it.
+type headerAuthenticator struct {
+	scopes []string
+}
+
+func (a *headerAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	userID := moovhttp.GetUserID(r)
+	if userID == "" {
+		return nil, nil
+	}
+	return &Identity{UserID: id.User(userID), Scopes: a.scopes, Method: "trusted-proxy"}, nil
+}
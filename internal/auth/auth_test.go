@@ -0,0 +1,159 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+type mockAuthenticator struct {
+	identity *Identity
+	err      error
+}
+
+func (a *mockAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	return a.identity, a.err
+}
+
+func testRouter(m *Middleware) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(m.Handler)
+	router.Methods("GET").Path("/transfers").HandlerFunc(Require(TransfersRead, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(moovhttp.GetUserID(r)))
+	}))
+	router.Methods("GET").Path("/ping").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("PONG" + moovhttp.GetUserID(r)))
+	})
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	m := &Middleware{
+		logger: log.NewNopLogger(),
+		authenticators: []Authenticator{
+			&mockAuthenticator{},
+			&mockAuthenticator{identity: &Identity{UserID: id.User("alice"), Scopes: []string{TransfersRead}}},
+		},
+	}
+	router := testRouter(m)
+
+	// the header is replaced by the authenticated user
+	req := httptest.NewRequest("GET", "/transfers", nil)
+	req.Header.Set("X-User-Id", "mallory")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "alice" {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// missing scope
+	m.authenticators[1] = &mockAuthenticator{identity: &Identity{UserID: id.User("alice"), Scopes: []string{TransfersWrite}}}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/transfers", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// invalid credentials
+	m.authenticators[0] = &mockAuthenticator{err: errors.New("bad token")}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMiddleware__unauthenticated(t *testing.T) {
+	m, err := NewMiddleware(log.NewNopLogger(), &config.AuthConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := testRouter(m)
+
+	// X-User-ID isn't trusted without a proxy
+	req := httptest.NewRequest("GET", "/transfers", nil)
+	req.Header.Set("X-User-Id", "mallory")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// routes without a scope are still available, but without a user
+	req = httptest.NewRequest("GET", "/ping", nil)
+	req.Header.Set("X-User-Id", "mallory")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "PONG" {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMiddleware__trustedProxy(t *testing.T) {
+	cfg := &config.AuthConfig{}
+	cfg.TrustedProxy.Enabled = true
+	cfg.TrustedProxy.Scopes = []string{TransfersRead}
+
+	m, err := NewMiddleware(log.NewNopLogger(), cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := testRouter(m)
+
+	req := httptest.NewRequest("GET", "/transfers", nil)
+	req.Header.Set("X-User-Id", "alice")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "alice" {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/transfers", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// unknown scopes are rejected
+	cfg.TrustedProxy.Scopes = []string{"transfers:everything"}
+	if _, err := NewMiddleware(log.NewNopLogger(), cfg, nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMiddleware__errors(t *testing.T) {
+	cfg := &config.AuthConfig{}
+	cfg.APIKeys.Enabled = true
+	if _, err := NewMiddleware(log.NewNopLogger(), cfg, nil); err == nil {
+		t.Error("expected error")
+	}
+
+	cfg = &config.AuthConfig{}
+	cfg.JWT.JWKSFile = "missing.json"
+	if _, err := NewMiddleware(log.NewNopLogger(), cfg, nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	var identity *Identity
+	if identity.HasScope(TransfersRead) {
+		t.Error("nil Identity has no scopes")
+	}
+	identity = &Identity{Scopes: scopesOrAll(nil)}
+	if !identity.HasScope(TransfersWrite) || identity.HasScope("admin") {
+		t.Errorf("unexpected scopes: %v", identity.Scopes)
+	}
+}
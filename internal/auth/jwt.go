@@ -0,0 +1,130 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+var (
+	errInvalidToken = errors.New("invalid bearer token")
+
+	// jwtLeeway is the clock skew allowed when checking a token's exp, nbf and iat claims.
+	jwtLeeway = time.Minute
+)
+
+// jwtAuthenticator accepts signed bearer tokens, typically issued by an OIDC provider, which are
+// verified with keys from a local JWKS file.
+type jwtAuthenticator struct {
+	keys jose.JSONWebKeySet
+
+	issuer   string
+	audience string
+
+	userClaim  string
+	scopeClaim string
+}
+
+func newJWTAuthenticator(cfg config.JWTAuthConfig) (*jwtAuthenticator, error) {
+	bs, err := ioutil.ReadFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: problem reading JWKS: %v", err)
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(bs, &keys); err != nil {
+		return nil, fmt.Errorf("jwt: problem parsing JWKS %s: %v", cfg.JWKSFile, err)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("jwt: no keys found in %s", cfg.JWKSFile)
+	}
+	return &jwtAuthenticator{
+		keys:       keys,
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		userClaim:  cfg.UserClaim,
+		scopeClaim: cfg.ScopeClaim,
+	}, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return nil, nil
+	}
+	tok, err := jwt.ParseSigned(strings.TrimSpace(header[7:]))
+	if err != nil {
+		return nil, errInvalidToken
+	}
+
+	var claims jwt.Claims
+	extra := make(map[string]interface{})
+	if err := a.verify(tok, &claims, &extra); err != nil {
+		return nil, err
+	}
+
+	if claims.Expiry == nil {
+		return nil, errors.New("bearer token has no expiration")
+	}
+	expected := jwt.Expected{Issuer: a.issuer, Time: time.Now()}
+	if a.audience != "" {
+		expected.Audience = jwt.Audience{a.audience}
+	}
+	if err := claims.ValidateWithLeeway(expected, jwtLeeway); err != nil {
+		return nil, fmt.Errorf("bearer token: %v", err)
+	}
+
+	userID, _ := extra[a.userClaim].(string)
+	if userID == "" {
+		return nil, fmt.Errorf("bearer token is missing its %s claim", a.userClaim)
+	}
+	return &Identity{
+		UserID: id.User(userID),
+		Scopes: readScopes(extra[a.scopeClaim]),
+		Method: "jwt",
+	}, nil
+}
+
+// verify checks the token's signature with the key it names (kid), or each key when it names none.
+func (a *jwtAuthenticator) verify(tok *jwt.JSONWebToken, dest ...interface{}) error {
+	keys := a.keys.Keys
+	if len(tok.Headers) > 0 && tok.Headers[0].KeyID != "" {
+		keys = a.keys.Key(tok.Headers[0].KeyID)
+	}
+	for i := range keys {
+		if err := tok.Claims(keys[i].Public(), dest...); err == nil {
+			return nil
+		}
+	}
+	return errInvalidToken
+}
+
+// readScopes accepts a space separated string (OAuth2's scope claim) or a list of strings.
+func readScopes(v interface{}) []string {
+	switch scopes := v.(type) {
+	case string:
+		return strings.Fields(scopes)
+	case []interface{}:
+		var out []string
+		for i := range scopes {
+			if s, ok := scopes[i].(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
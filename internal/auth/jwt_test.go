@@ -0,0 +1,161 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+type testIssuer struct {
+	key      *rsa.PrivateKey
+	jwksFile string
+}
+
+func newTestIssuer(t *testing.T, dir string) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: "test", Algorithm: "RS256", Use: "sig"}},
+	}
+	bs, _ := json.Marshal(jwks)
+	path := filepath.Join(dir, "jwks.json")
+	if err := ioutil.WriteFile(path, bs, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return &testIssuer{key: key, jwksFile: path}
+}
+
+func (i *testIssuer) sign(t *testing.T, claims jwt.Claims, extra map[string]interface{}) string {
+	t.Helper()
+
+	opts := (&jose.SignerOptions{}).WithHeader("kid", "test")
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: i.key}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := jwt.Signed(signer).Claims(claims).Claims(extra).CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "jwt")
+	defer os.RemoveAll(dir)
+
+	issuer := newTestIssuer(t, dir)
+	a, err := newJWTAuthenticator(config.JWTAuthConfig{
+		JWKSFile:   issuer.jwksFile,
+		Issuer:     "https://id.example.com",
+		Audience:   "paygate",
+		UserClaim:  "sub",
+		ScopeClaim: "scope",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticate := func(token string) (*Identity, error) {
+		req := httptest.NewRequest("GET", "/transfers", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return a.Authenticate(req)
+	}
+	claims := jwt.Claims{
+		Issuer:   "https://id.example.com",
+		Audience: jwt.Audience{"paygate"},
+		Subject:  "alice",
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	extra := map[string]interface{}{"sub": "alice", "scope": "transfers:read transfers:write"}
+
+	identity, err := authenticate(issuer.sign(t, claims, extra))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.UserID != "alice" || !identity.HasScope(TransfersWrite) || identity.HasScope(DepositoriesRead) {
+		t.Errorf("unexpected identity: %#v", identity)
+	}
+
+	// no token
+	if identity, err := authenticate(""); identity != nil || err != nil {
+		t.Errorf("identity=%#v error=%v", identity, err)
+	}
+
+	// expired
+	expired := claims
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-1 * time.Hour))
+	if _, err := authenticate(issuer.sign(t, expired, extra)); err == nil {
+		t.Error("expected error")
+	}
+
+	// wrong audience
+	audience := claims
+	audience.Audience = jwt.Audience{"other"}
+	if _, err := authenticate(issuer.sign(t, audience, extra)); err == nil {
+		t.Error("expected error")
+	}
+
+	// signed by another key
+	other := newTestIssuer(t, dir)
+	if _, err := authenticate(other.sign(t, claims, extra)); err == nil {
+		t.Error("expected error")
+	}
+
+	// garbage
+	if _, err := authenticate("not-a-token"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestJWTAuthenticator__middleware(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "jwt")
+	defer os.RemoveAll(dir)
+
+	issuer := newTestIssuer(t, dir)
+	cfg := config.Empty()
+	if err := config.OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Auth.JWT.JWKSFile = issuer.jwksFile
+
+	m, err := NewMiddleware(cfg.Logger, cfg.Auth, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := testRouter(m)
+
+	token := issuer.sign(t, jwt.Claims{Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}, map[string]interface{}{
+		"sub":   "bob",
+		"scope": []string{TransfersRead},
+	})
+	req := httptest.NewRequest("GET", "/transfers", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "bob" {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
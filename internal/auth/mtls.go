@@ -0,0 +1,52 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+// ConfigureTLS has the server request client certificates and verify them against cfg.MTLS.ClientCAFile.
+// Clients without a certificate can still connect and authenticate another way.
+func ConfigureTLS(cfg *config.AuthConfig, tlsConfig *tls.Config) error {
+	if cfg.MTLS.ClientCAFile == "" {
+		return nil
+	}
+	bs, err := ioutil.ReadFile(cfg.MTLS.ClientCAFile)
+	if err != nil {
+		return fmt.Errorf("mtls: problem reading client CA file: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return fmt.Errorf("mtls: no certificates found in %s", cfg.MTLS.ClientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	return nil
+}
+
+// mtlsAuthenticator accepts verified client certificates, using their common name as the userID.
+type mtlsAuthenticator struct {
+	scopes []string
+}
+
+func (a *mtlsAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	if cert.Subject.CommonName == "" {
+		return nil, errors.New("client certificate has no common name")
+	}
+	return &Identity{UserID: id.User(cert.Subject.CommonName), Scopes: a.scopes, Method: "mtls"}, nil
+}
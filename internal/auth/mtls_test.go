@@ -0,0 +1,63 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moov-io/paygate/internal/config"
+)
+
+func TestMTLSAuthenticator(t *testing.T) {
+	a := &mtlsAuthenticator{scopes: []string{TransfersRead}}
+
+	req := httptest.NewRequest("GET", "/transfers", nil)
+	if identity, err := a.Authenticate(req); identity != nil || err != nil {
+		t.Errorf("identity=%#v error=%v", identity, err)
+	}
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "partner"}}
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	identity, err := a.Authenticate(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.UserID != "partner" || !identity.HasScope(TransfersRead) {
+		t.Errorf("unexpected identity: %#v", identity)
+	}
+
+	cert.Subject.CommonName = ""
+	if _, err := a.Authenticate(req); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestConfigureTLS(t *testing.T) {
+	cfg := &config.AuthConfig{}
+	tlsConfig := &tls.Config{}
+	if err := ConfigureTLS(cfg, tlsConfig); err != nil || tlsConfig.ClientCAs != nil {
+		t.Errorf("unexpected TLS config error=%v", err)
+	}
+
+	cfg.MTLS.ClientCAFile = filepath.Join("..", "..", "testdata", "missing.pem")
+	if err := ConfigureTLS(cfg, tlsConfig); err == nil {
+		t.Error("expected error")
+	}
+
+	dir, _ := ioutil.TempDir("", "mtls")
+	defer os.RemoveAll(dir)
+	cfg.MTLS.ClientCAFile = filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(cfg.MTLS.ClientCAFile, []byte("not a cert"), 0600)
+	if err := ConfigureTLS(cfg, tlsConfig); err == nil {
+		t.Error("expected error")
+	}
+}
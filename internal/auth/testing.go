@@ -0,0 +1,22 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+// NewTestRouter returns a *mux.Router which trusts the X-User-ID header and grants every scope,
+// as if requests came through a trusted proxy. It's used to test HTTP handlers.
+func NewTestRouter() *mux.Router {
+	m := &Middleware{
+		logger:         log.NewNopLogger(),
+		authenticators: []Authenticator{&headerAuthenticator{scopes: allScopes}},
+	}
+	router := mux.NewRouter()
+	router.Use(m.Handler)
+	return router
+}
@@ -0,0 +1,562 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
+	"github.com/moov-io/paygate/internal/route"
+	"github.com/moov-io/paygate/internal/tracing"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+type AuthorizationID string
+
+// Authorization is a Receiver's permission for an Originator to debit their account. NACHA requires
+// Originators to retain proof of authorization for debits, so pull Transfers must reference one.
+type Authorization struct {
+	// ID is a unique string representing this Authorization.
+	ID AuthorizationID `json:"id"`
+
+	// Receiver is the Receiver who granted this Authorization
+	Receiver ReceiverID `json:"receiver"`
+
+	// Originator is the Originator allowed to debit the Receiver
+	Originator OriginatorID `json:"originator"`
+
+	// Type is how the Receiver gave their authorization
+	Type AuthorizationType `json:"type"`
+
+	// Reference identifies the proof of authorization, such as a signed document, the recording of
+	// an oral (TEL) authorization or the online (WEB) session. It's required for oral authorizations.
+	Reference string `json:"reference,omitempty"`
+
+	// Scope is if the Authorization allows one or many Transfers
+	Scope AuthorizationScope `json:"scope"`
+
+	// AmountCap is an optional limit on the amount of each Transfer
+	AmountCap *Amount `json:"amountCap,omitempty"`
+
+	// Expires is an optional timestamp after which the Authorization can't be used
+	Expires *time.Time `json:"expires,omitempty"`
+
+	// Revoked is when the Authorization was revoked, either by the user or from an R07 or R10 return
+	Revoked *time.Time `json:"revoked,omitempty"`
+
+	// RevocationReason describes why the Authorization was revoked
+	RevocationReason string `json:"revocationReason,omitempty"`
+
+	// Created a timestamp representing the initial creation date of the object in ISO 8601
+	Created base.Time `json:"created"`
+
+	// Updated is a timestamp when the object was last modified in ISO8601 format
+	Updated base.Time `json:"updated"`
+
+	// consumed is when a single use Authorization was used by a Transfer
+	consumed *time.Time
+}
+
+// active returns an error if the Authorization is revoked or expired.
+func (a *Authorization) active(now time.Time) error {
+	if a.Revoked != nil {
+		return fmt.Errorf("authorization=%s was revoked", a.ID)
+	}
+	if a.Expires != nil && now.After(*a.Expires) {
+		return fmt.Errorf("authorization=%s expired", a.ID)
+	}
+	return nil
+}
+
+type AuthorizationType string
+
+const (
+	AuthorizationWritten AuthorizationType = "written"
+	AuthorizationOral    AuthorizationType = "oral"
+	AuthorizationOnline  AuthorizationType = "online"
+)
+
+func (at AuthorizationType) validate() error {
+	switch at {
+	case AuthorizationWritten, AuthorizationOral, AuthorizationOnline:
+		return nil
+	default:
+		return fmt.Errorf("AuthorizationType(%s) is invalid", at)
+	}
+}
+
+func (at *AuthorizationType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*at = AuthorizationType(strings.ToLower(s))
+	return at.validate()
+}
+
+// authorizationTypeFor returns the AuthorizationType NACHA requires for debits with the given SEC code.
+// TEL entries are authorized over the phone and WEB entries online, everything else is written.
+func authorizationTypeFor(standardEntryClassCode string) AuthorizationType {
+	switch standardEntryClassCode {
+	case ach.TEL:
+		return AuthorizationOral
+	case ach.WEB:
+		return AuthorizationOnline
+	default:
+		return AuthorizationWritten
+	}
+}
+
+type AuthorizationScope string
+
+const (
+	AuthorizationSingle    AuthorizationScope = "single"
+	AuthorizationRecurring AuthorizationScope = "recurring"
+)
+
+func (as AuthorizationScope) validate() error {
+	switch as {
+	case AuthorizationSingle, AuthorizationRecurring:
+		return nil
+	default:
+		return fmt.Errorf("AuthorizationScope(%s) is invalid", as)
+	}
+}
+
+func (as *AuthorizationScope) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*as = AuthorizationScope(strings.ToLower(s))
+	return as.validate()
+}
+
+type authorizationRequest struct {
+	Originator OriginatorID       `json:"originator"`
+	Type       AuthorizationType  `json:"type"`
+	Reference  string             `json:"reference,omitempty"`
+	Scope      AuthorizationScope `json:"scope"`
+	AmountCap  *Amount            `json:"amountCap,omitempty"`
+	Expires    *time.Time         `json:"expires,omitempty"`
+}
+
+func (r authorizationRequest) validate(now time.Time) error {
+	if r.Originator == "" {
+		return errors.New("missing authorizationRequest.Originator")
+	}
+	if err := r.Type.validate(); err != nil {
+		return err
+	}
+	if r.Type == AuthorizationOral && r.Reference == "" {
+		return errors.New("oral authorizations require a reference to their recording")
+	}
+	if err := r.Scope.validate(); err != nil {
+		return err
+	}
+	if r.AmountCap != nil {
+		if err := r.AmountCap.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Expires != nil && !r.Expires.After(now) {
+		return errors.New("authorization expires in the past")
+	}
+	return nil
+}
+
+// authorizeTransfer checks that auth allows the Transfer in req. Single use Authorizations are
+// consumed when the Transfer is saved, which fails if another Transfer used it first.
+func authorizeTransfer(auth *Authorization, req *transferRequest) error {
+	if auth == nil {
+		return fmt.Errorf("authorization=%s not found", req.Authorization)
+	}
+	if auth.Receiver != req.Receiver || auth.Originator != req.Originator {
+		return fmt.Errorf("authorization=%s is not for receiver=%s and originator=%s", auth.ID, req.Receiver, req.Originator)
+	}
+	if err := auth.active(time.Now()); err != nil {
+		return err
+	}
+	if expected := authorizationTypeFor(req.StandardEntryClassCode); auth.Type != expected {
+		return fmt.Errorf("%s transfers require an %s authorization, authorization=%s is %s", req.StandardEntryClassCode, expected, auth.ID, auth.Type)
+	}
+	if auth.AmountCap != nil {
+		if auth.AmountCap.symbol != req.Amount.symbol {
+			return ErrDifferentCurrencies
+		}
+		if req.Amount.Int() > auth.AmountCap.Int() {
+			return fmt.Errorf("amount %s exceeds authorization=%s cap of %s", req.Amount.String(), auth.ID, auth.AmountCap.String())
+		}
+	}
+	if auth.Scope == AuthorizationSingle {
+		if auth.consumed != nil {
+			return errAuthorizationUsed(auth.ID)
+		}
+		req.consumeAuthorization = true
+	}
+	return nil
+}
+
+func errAuthorizationUsed(id AuthorizationID) error {
+	return fmt.Errorf("single use authorization=%s was already used", id)
+}
+
+// shouldRevokeAuthorization returns true for return codes where the Receiver disputes the
+// Originator's authority to debit them.
+func shouldRevokeAuthorization(code *ach.ReturnCode) bool {
+	if code == nil {
+		return false
+	}
+	switch code.Code {
+	case "R07", // Authorization Revoked by Customer
+		"R10": // Customer Advises Not Authorized
+		return true
+	}
+	return false
+}
+
+// RevokeAuthorizationFromReturn revokes the Authorization of a returned Transfer if the ReturnCode
+// means the Receiver no longer authorizes debits.
+func RevokeAuthorizationFromReturn(ctx context.Context, transfer *Transfer, code *ach.ReturnCode, authorizationRepo AuthorizationRepository) (bool, error) {
+	if transfer == nil || transfer.Authorization == "" || !shouldRevokeAuthorization(code) {
+		return false, nil
+	}
+	reason := fmt.Sprintf("%s: %s returned for transfer=%s", code.Code, code.Reason, transfer.ID)
+	if err := authorizationRepo.RevokeAuthorization(ctx, transfer.Authorization, reason); err != nil {
+		return false, fmt.Errorf("problem revoking authorization=%s: %v", transfer.Authorization, err)
+	}
+	return true, nil
+}
+
+func AddAuthorizationRoutes(logger log.Logger, r *mux.Router, authorizationRepo AuthorizationRepository, originatorRepo originatorRepository, receiverRepo receiverRepository) {
+	r.Methods("GET").Path("/receivers/{receiverId}/authorizations").HandlerFunc(auth.Require(auth.AuthorizationsRead, getUserAuthorizations(logger, authorizationRepo, receiverRepo)))
+	r.Methods("POST").Path("/receivers/{receiverId}/authorizations").HandlerFunc(auth.Require(auth.AuthorizationsWrite, createUserAuthorization(logger, authorizationRepo, originatorRepo, receiverRepo)))
+	r.Methods("GET").Path("/receivers/{receiverId}/authorizations/{authorizationId}").HandlerFunc(auth.Require(auth.AuthorizationsRead, getUserAuthorization(logger, authorizationRepo)))
+	r.Methods("POST").Path("/receivers/{receiverId}/authorizations/{authorizationId}/revoke").HandlerFunc(auth.Require(auth.AuthorizationsWrite, revokeUserAuthorization(logger, authorizationRepo)))
+}
+
+func getAuthorizationID(r *http.Request) AuthorizationID {
+	v, ok := mux.Vars(r)["authorizationId"]
+	if !ok {
+		return AuthorizationID("")
+	}
+	return AuthorizationID(v)
+}
+
+func getUserAuthorizations(logger log.Logger, authorizationRepo AuthorizationRepository, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		receiverID := getReceiverID(r)
+		if receiver, err := receiverRepo.getUserReceiver(r.Context(), receiverID, responder.XUserID); err != nil || receiver == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		authorizations, err := authorizationRepo.getUserAuthorizations(r.Context(), receiverID, responder.XUserID)
+		if err != nil {
+			responder.Log("authorizations", fmt.Sprintf("problem reading authorizations for receiver=%s: %v", receiverID, err))
+			responder.Problem(err)
+			return
+		}
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(authorizations)
+		})
+	}
+}
+
+func createUserAuthorization(logger log.Logger, authorizationRepo AuthorizationRepository, originatorRepo originatorRepository, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		var req authorizationRequest
+		if err := json.NewDecoder(Read(r.Body)).Decode(&req); err != nil {
+			responder.Problem(err)
+			return
+		}
+		now := time.Now()
+		if err := req.validate(now); err != nil {
+			responder.Problem(err)
+			return
+		}
+
+		receiverID := getReceiverID(r)
+		if receiver, err := receiverRepo.getUserReceiver(r.Context(), receiverID, responder.XUserID); err != nil || receiver == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if orig, err := originatorRepo.getUserOriginator(r.Context(), req.Originator, responder.XUserID); err != nil || orig == nil {
+			responder.Problem(fmt.Errorf("originator %s does not exist", req.Originator))
+			return
+		}
+
+		auth := &Authorization{
+			ID:         AuthorizationID(base.ID()),
+			Receiver:   receiverID,
+			Originator: req.Originator,
+			Type:       req.Type,
+			Reference:  req.Reference,
+			Scope:      req.Scope,
+			AmountCap:  req.AmountCap,
+			Expires:    req.Expires,
+			Created:    base.NewTime(now),
+			Updated:    base.NewTime(now),
+		}
+		if err := authorizationRepo.createUserAuthorization(r.Context(), responder.XUserID, auth); err != nil {
+			responder.Log("authorizations", fmt.Sprintf("problem creating authorization for receiver=%s: %v", receiverID, err))
+			responder.Problem(err)
+			return
+		}
+
+		responder.Log("authorizations", fmt.Sprintf("created authorization=%s for receiver=%s", auth.ID, receiverID))
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(auth)
+		})
+	}
+}
+
+// getReceiverAuthorization reads the Authorization from the request path and checks it belongs to the Receiver.
+func getReceiverAuthorization(responder *route.Responder, r *http.Request, authorizationRepo AuthorizationRepository) (*Authorization, error) {
+	auth, err := authorizationRepo.getUserAuthorization(r.Context(), getAuthorizationID(r), responder.XUserID)
+	if err != nil {
+		return nil, err
+	}
+	if auth == nil || auth.Receiver != getReceiverID(r) {
+		return nil, nil
+	}
+	return auth, nil
+}
+
+func getUserAuthorization(logger log.Logger, authorizationRepo AuthorizationRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		auth, err := getReceiverAuthorization(responder, r, authorizationRepo)
+		if err != nil {
+			responder.Problem(err)
+			return
+		}
+		if auth == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(auth)
+		})
+	}
+}
+
+type revokeAuthorizationRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+func revokeUserAuthorization(logger log.Logger, authorizationRepo AuthorizationRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		var req revokeAuthorizationRequest
+		if r.Body != nil {
+			// the reason is optional, so an empty body is fine
+			json.NewDecoder(Read(r.Body)).Decode(&req)
+		}
+		if req.Reason == "" {
+			req.Reason = "revoked by user"
+		}
+
+		auth, err := getReceiverAuthorization(responder, r, authorizationRepo)
+		if err != nil {
+			responder.Problem(err)
+			return
+		}
+		if auth == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if auth.Revoked == nil {
+			if err := authorizationRepo.RevokeAuthorization(r.Context(), auth.ID, req.Reason); err != nil {
+				responder.Log("authorizations", fmt.Sprintf("problem revoking authorization=%s: %v", auth.ID, err))
+				responder.Problem(err)
+				return
+			}
+			if auth, err = authorizationRepo.getUserAuthorization(r.Context(), auth.ID, responder.XUserID); err != nil {
+				responder.Problem(err)
+				return
+			}
+			responder.Log("authorizations", fmt.Sprintf("revoked authorization=%s", auth.ID))
+		}
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(auth)
+		})
+	}
+}
+
+type AuthorizationRepository interface {
+	getUserAuthorizations(ctx context.Context, receiverID ReceiverID, userID id.User) ([]*Authorization, error)
+	getUserAuthorization(ctx context.Context, id AuthorizationID, userID id.User) (*Authorization, error)
+	createUserAuthorization(ctx context.Context, userID id.User, auth *Authorization) error
+
+	// RevokeAuthorization marks an Authorization as revoked. Authorizations which are already revoked keep
+	// their original reason.
+	RevokeAuthorization(ctx context.Context, id AuthorizationID, reason string) error
+}
+
+func NewAuthorizationRepo(logger log.Logger, db *sql.DB) *SQLAuthorizationRepo {
+	return &SQLAuthorizationRepo{logger: logger, db: db}
+}
+
+type SQLAuthorizationRepo struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func (r *SQLAuthorizationRepo) Close() error {
+	return r.db.Close()
+}
+
+func (r *SQLAuthorizationRepo) getUserAuthorizations(ctx context.Context, receiverID ReceiverID, userID id.User) ([]*Authorization, error) {
+	query := `select authorization_id from authorizations where receiver_id = ? and user_id = ? and deleted_at is null order by created_at;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, receiverID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authIDs []AuthorizationID
+	for rows.Next() {
+		var row AuthorizationID
+		if err := rows.Scan(&row); err != nil {
+			return nil, fmt.Errorf("getUserAuthorizations scan: %v", err)
+		}
+		authIDs = append(authIDs, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("getUserAuthorizations: rows.Err=%v", err)
+	}
+
+	var authorizations []*Authorization
+	for i := range authIDs {
+		auth, err := r.getUserAuthorization(ctx, authIDs[i], userID)
+		if err != nil {
+			return nil, err
+		}
+		if auth != nil {
+			authorizations = append(authorizations, auth)
+		}
+	}
+	return authorizations, nil
+}
+
+func (r *SQLAuthorizationRepo) getUserAuthorization(ctx context.Context, id AuthorizationID, userID id.User) (*Authorization, error) {
+	query := `select authorization_id, receiver_id, originator_id, type, reference, scope, amount_cap, expires_at, revoked_at, revocation_reason, created_at, last_updated_at, consumed_at
+from authorizations where authorization_id = ? and user_id = ? and deleted_at is null limit 1;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var (
+		auth      Authorization
+		reference *string
+		amountCap *string
+		reason    *string
+	)
+	row := stmt.QueryRowContext(ctx, id, userID)
+	err = row.Scan(&auth.ID, &auth.Receiver, &auth.Originator, &auth.Type, &reference, &auth.Scope, &amountCap, &auth.Expires, &auth.Revoked, &reason, &auth.Created.Time, &auth.Updated.Time, &auth.consumed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if reference != nil {
+		auth.Reference = *reference
+	}
+	if amountCap != nil && *amountCap != "" {
+		auth.AmountCap = &Amount{}
+		if err := auth.AmountCap.FromString(*amountCap); err != nil {
+			return nil, fmt.Errorf("authorization=%s has invalid amount cap: %v", auth.ID, err)
+		}
+	}
+	if reason != nil {
+		auth.RevocationReason = *reason
+	}
+	return &auth, nil
+}
+
+func (r *SQLAuthorizationRepo) createUserAuthorization(ctx context.Context, userID id.User, auth *Authorization) (err error) {
+	ctx, span := tracing.Start(ctx, "authorizationRepo.createUserAuthorization", "authorizationID", auth.ID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `insert into authorizations (authorization_id, user_id, receiver_id, originator_id, type, reference, scope, amount_cap, expires_at, created_at, last_updated_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var amountCap string
+	if auth.AmountCap != nil {
+		amountCap = auth.AmountCap.String()
+	}
+	_, err = stmt.ExecContext(ctx, auth.ID, userID, auth.Receiver, auth.Originator, auth.Type, auth.Reference, auth.Scope, amountCap, auth.Expires, auth.Created.Time, auth.Updated.Time)
+	return err
+}
+
+func (r *SQLAuthorizationRepo) RevokeAuthorization(ctx context.Context, id AuthorizationID, reason string) (err error) {
+	ctx, span := tracing.Start(ctx, "authorizationRepo.RevokeAuthorization", "authorizationID", id)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `update authorizations set revoked_at = ?, revocation_reason = ?, last_updated_at = ? where authorization_id = ? and revoked_at is null and deleted_at is null;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.ExecContext(ctx, now, reason, now, id)
+	return err
+}
@@ -0,0 +1,421 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestAuthorization__json(t *testing.T) {
+	var req authorizationRequest
+	body := `{"originator": "originator", "type": "Written", "scope": "RECURRING", "amountCap": "USD 100.00"}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Type != AuthorizationWritten || req.Scope != AuthorizationRecurring {
+		t.Errorf("unexpected request: %#v", req)
+	}
+	if req.AmountCap == nil || req.AmountCap.Int() != 10000 {
+		t.Errorf("unexpected AmountCap: %v", req.AmountCap)
+	}
+
+	if err := json.Unmarshal([]byte(`{"type": "email"}`), &req); err == nil {
+		t.Error("expected error")
+	}
+	if err := json.Unmarshal([]byte(`{"scope": "forever"}`), &req); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestAuthorizationRequest__validate(t *testing.T) {
+	now := time.Now()
+	req := authorizationRequest{
+		Originator: OriginatorID("originator"),
+		Type:       AuthorizationOral,
+		Reference:  "call-1234",
+		Scope:      AuthorizationSingle,
+	}
+	if err := req.validate(now); err != nil {
+		t.Fatal(err)
+	}
+
+	noReference := req
+	noReference.Reference = ""
+	if err := noReference.validate(now); err == nil {
+		t.Error("expected error")
+	}
+
+	expired := req
+	past := now.Add(-1 * time.Hour)
+	expired.Expires = &past
+	if err := expired.validate(now); err == nil {
+		t.Error("expected error")
+	}
+
+	noOriginator := req
+	noOriginator.Originator = ""
+	if err := noOriginator.validate(now); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestAuthorizations__authorizeTransfer(t *testing.T) {
+	amountCap, _ := NewAmount("USD", "25.00")
+	authorization := func() *Authorization {
+		return &Authorization{
+			ID:         AuthorizationID(base.ID()),
+			Receiver:   ReceiverID("receiver"),
+			Originator: OriginatorID("originator"),
+			Type:       AuthorizationWritten,
+			Scope:      AuthorizationRecurring,
+			AmountCap:  amountCap,
+		}
+	}
+	amt, _ := NewAmount("USD", "25.00")
+	req := &transferRequest{
+		Type:                   PullTransfer,
+		Amount:                 *amt,
+		Originator:             OriginatorID("originator"),
+		Receiver:               ReceiverID("receiver"),
+		StandardEntryClassCode: ach.PPD,
+	}
+
+	if err := authorizeTransfer(authorization(), req); err != nil {
+		t.Fatal(err)
+	}
+	if err := authorizeTransfer(nil, req); err == nil {
+		t.Error("expected error")
+	}
+
+	// different Receiver
+	auth := authorization()
+	auth.Receiver = ReceiverID("other")
+	if err := authorizeTransfer(auth, req); err == nil {
+		t.Error("expected error")
+	}
+
+	// expired
+	auth = authorization()
+	past := time.Now().Add(-1 * time.Minute)
+	auth.Expires = &past
+	if err := authorizeTransfer(auth, req); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// WEB and TEL entries need online and oral authorizations
+	web := *req
+	web.StandardEntryClassCode = ach.WEB
+	if err := authorizeTransfer(authorization(), &web); err == nil {
+		t.Error("expected error")
+	}
+	auth = authorization()
+	auth.Type = AuthorizationOnline
+	if err := authorizeTransfer(auth, &web); err != nil {
+		t.Error(err)
+	}
+	tel := *req
+	tel.StandardEntryClassCode = ach.TEL
+	auth.Type = AuthorizationOral
+	if err := authorizeTransfer(auth, &tel); err != nil {
+		t.Error(err)
+	}
+
+	// amount cap
+	over := *req
+	over.Amount = Amount{symbol: "USD", number: 2501}
+	if err := authorizeTransfer(authorization(), &over); err == nil {
+		t.Error("expected error")
+	}
+	other := *req
+	other.Amount = Amount{symbol: "GBP", number: 100}
+	if err := authorizeTransfer(authorization(), &other); err != ErrDifferentCurrencies {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// single use
+	if req.consumeAuthorization {
+		t.Error("recurring authorization is consumed")
+	}
+	auth = authorization()
+	auth.Scope = AuthorizationSingle
+	if err := authorizeTransfer(auth, req); err != nil {
+		t.Error(err)
+	}
+	if !req.consumeAuthorization {
+		t.Error("single use authorization isn't consumed")
+	}
+	now := time.Now()
+	auth.consumed = &now
+	if err := authorizeTransfer(auth, req); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestAuthorizations__RevokeAuthorizationFromReturn(t *testing.T) {
+	repo := &MockAuthorizationRepository{
+		Authorizations: []*Authorization{{ID: AuthorizationID("authorization")}},
+	}
+	xfer := &Transfer{ID: TransferID("transfer"), Authorization: AuthorizationID("authorization")}
+
+	if revoked, err := RevokeAuthorizationFromReturn(context.Background(), xfer, ach.LookupReturnCode("R01"), repo); revoked || err != nil {
+		t.Errorf("revoked=%v error=%v", revoked, err)
+	}
+	if revoked, err := RevokeAuthorizationFromReturn(context.Background(), &Transfer{}, ach.LookupReturnCode("R07"), repo); revoked || err != nil {
+		t.Errorf("revoked=%v error=%v", revoked, err)
+	}
+	if revoked, err := RevokeAuthorizationFromReturn(context.Background(), xfer, ach.LookupReturnCode("R10"), repo); !revoked || err != nil {
+		t.Errorf("revoked=%v error=%v", revoked, err)
+	}
+	if !strings.HasPrefix(repo.RevocationReason, "R10: ") {
+		t.Errorf("RevocationReason=%q", repo.RevocationReason)
+	}
+}
+
+func TestAuthorizations__SQLRepo(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLAuthorizationRepo) {
+		userID := id.User(base.ID())
+		receiverID := ReceiverID(base.ID())
+		amountCap, _ := NewAmount("USD", "100.00")
+		expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+		auth := &Authorization{
+			ID:         AuthorizationID(base.ID()),
+			Receiver:   receiverID,
+			Originator: OriginatorID(base.ID()),
+			Type:       AuthorizationOral,
+			Reference:  "call-1234",
+			Scope:      AuthorizationSingle,
+			AmountCap:  amountCap,
+			Expires:    &expires,
+			Created:    base.NewTime(time.Now()),
+			Updated:    base.NewTime(time.Now()),
+		}
+		if err := repo.createUserAuthorization(context.Background(), userID, auth); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := repo.getUserAuthorization(context.Background(), auth.ID, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Receiver != receiverID || found.Type != AuthorizationOral || found.Reference != "call-1234" || found.Scope != AuthorizationSingle {
+			t.Errorf("unexpected Authorization: %#v", found)
+		}
+		if found.AmountCap == nil || !found.AmountCap.Equal(*amountCap) {
+			t.Errorf("AmountCap=%v", found.AmountCap)
+		}
+		if found.Expires == nil || !found.Expires.Equal(expires) {
+			t.Errorf("Expires=%v", found.Expires)
+		}
+		if found.Revoked != nil {
+			t.Errorf("Revoked=%v", found.Revoked)
+		}
+
+		// other users can't read it
+		if found, err := repo.getUserAuthorization(context.Background(), auth.ID, id.User(base.ID())); found != nil || err != nil {
+			t.Errorf("found=%#v error=%v", found, err)
+		}
+
+		authorizations, err := repo.getUserAuthorizations(context.Background(), receiverID, userID)
+		if err != nil || len(authorizations) != 1 {
+			t.Errorf("authorizations=%#v error=%v", authorizations, err)
+		}
+
+		// Transfers consume single use Authorizations
+		amt, _ := NewAmount("USD", "12.00")
+		transferRepo := &SQLTransferRepo{db: repo.db, log: log.NewNopLogger()}
+		create := func() ([]*Transfer, error) {
+			return transferRepo.createUserTransfers(context.Background(), userID, []*transferRequest{
+				{
+					Type:                   PullTransfer,
+					Amount:                 *amt,
+					Originator:             auth.Originator,
+					OriginatorDepository:   id.Depository(base.ID()),
+					Receiver:               receiverID,
+					ReceiverDepository:     id.Depository(base.ID()),
+					Authorization:          auth.ID,
+					Description:            "payment",
+					StandardEntryClassCode: ach.TEL,
+					consumeAuthorization:   true,
+				},
+			})
+		}
+		transfers, err := create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found, err := repo.getUserAuthorization(context.Background(), auth.ID, userID); err != nil || found.consumed == nil {
+			t.Errorf("authorization wasn't consumed: %#v error=%v", found, err)
+		}
+		if _, err := create(); err == nil || !strings.Contains(err.Error(), "already used") {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if xfers, err := transferRepo.getUserTransfers(context.Background(), userID); err != nil || len(xfers) != 1 {
+			t.Errorf("transfers=%#v error=%v", xfers, err)
+		}
+
+		// deleting the Transfer releases the Authorization
+		if err := transferRepo.deleteUserTransfer(context.Background(), transfers[0].ID, userID); err != nil {
+			t.Fatal(err)
+		}
+		if found, err := repo.getUserAuthorization(context.Background(), auth.ID, userID); err != nil || found.consumed != nil {
+			t.Errorf("authorization is still consumed: %#v error=%v", found, err)
+		}
+		transfers, err = create()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// canceling the Transfer releases the Authorization, but only once
+		if err := transferRepo.UpdateTransferStatus(context.Background(), transfers[0].ID, TransferCanceled); err != nil {
+			t.Fatal(err)
+		}
+		if found, err := repo.getUserAuthorization(context.Background(), auth.ID, userID); err != nil || found.consumed != nil {
+			t.Errorf("authorization is still consumed: %#v error=%v", found, err)
+		}
+		if _, err := create(); err != nil {
+			t.Fatal(err)
+		}
+		if err := transferRepo.UpdateTransferStatus(context.Background(), transfers[0].ID, TransferCanceled); err != nil {
+			t.Fatal(err)
+		}
+		if found, err := repo.getUserAuthorization(context.Background(), auth.ID, userID); err != nil || found.consumed == nil {
+			t.Errorf("authorization wasn't consumed: %#v error=%v", found, err)
+		}
+
+		// Revoke, the first reason is kept
+		if err := repo.RevokeAuthorization(context.Background(), auth.ID, "R07: Authorization Revoked by Customer"); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RevokeAuthorization(context.Background(), auth.ID, "revoked by user"); err != nil {
+			t.Fatal(err)
+		}
+		found, err = repo.getUserAuthorization(context.Background(), auth.ID, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Revoked == nil || found.RevocationReason != "R07: Authorization Revoked by Customer" {
+			t.Errorf("unexpected Authorization: %#v", found)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewAuthorizationRepo(log.NewNopLogger(), sqliteDB.DB))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewAuthorizationRepo(log.NewNopLogger(), postgresDB.DB))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewAuthorizationRepo(log.NewNopLogger(), mysqlDB.DB))
+}
+
+func TestAuthorizations__HTTP(t *testing.T) {
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+
+	userID := id.User(base.ID())
+	authorizationRepo := NewAuthorizationRepo(log.NewNopLogger(), sqliteDB.DB)
+	receiverRepo := &mockReceiverRepository{
+		receivers: []*Receiver{{ID: ReceiverID("receiver"), Email: "jane@moov.io", Status: ReceiverVerified}},
+	}
+	origRepo := &mockOriginatorRepository{
+		originators: []*Originator{{ID: OriginatorID("originator")}},
+	}
+
+	router := auth.NewTestRouter()
+	AddAuthorizationRoutes(log.NewNopLogger(), router, authorizationRepo, origRepo, receiverRepo)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("x-user-id", userID.String())
+		router.ServeHTTP(w, req)
+		w.Flush()
+		return w
+	}
+
+	// invalid request
+	w := serve("POST", "/receivers/receiver/authorizations", `{"originator": "originator", "type": "oral", "scope": "single"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve("POST", "/receivers/receiver/authorizations", `{"originator": "originator", "type": "written", "reference": "signed-form.pdf", "scope": "recurring", "amountCap": "USD 250.00"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	var auth Authorization
+	if err := json.NewDecoder(w.Body).Decode(&auth); err != nil {
+		t.Fatal(err)
+	}
+	if auth.ID == "" || auth.Receiver != ReceiverID("receiver") || auth.AmountCap.String() != "USD 250.00" {
+		t.Errorf("unexpected Authorization: %#v", auth)
+	}
+
+	// list and get
+	w = serve("GET", "/receivers/receiver/authorizations", "")
+	var authorizations []*Authorization
+	if err := json.NewDecoder(w.Body).Decode(&authorizations); err != nil || len(authorizations) != 1 {
+		t.Errorf("authorizations=%#v error=%v", authorizations, err)
+	}
+	w = serve("GET", fmt.Sprintf("/receivers/receiver/authorizations/%s", auth.ID), "")
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	w = serve("GET", fmt.Sprintf("/receivers/other/authorizations/%s", auth.ID), "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// revoke
+	w = serve("POST", fmt.Sprintf("/receivers/receiver/authorizations/%s/revoke", auth.ID), `{"reason": "customer called"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	var revoked Authorization
+	if err := json.NewDecoder(w.Body).Decode(&revoked); err != nil {
+		t.Fatal(err)
+	}
+	if revoked.Revoked == nil || revoked.RevocationReason != "customer called" {
+		t.Errorf("unexpected Authorization: %#v", revoked)
+	}
+
+	// unknown Originator
+	origRepo.originators = nil
+	w = serve("POST", "/receivers/receiver/authorizations", `{"originator": "other", "type": "online", "scope": "single"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// unknown Receiver
+	receiverRepo.receivers = nil
+	w = serve("GET", "/receivers/receiver/authorizations", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
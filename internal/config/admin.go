@@ -0,0 +1,98 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/moov-io/base/admin"
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+	"gopkg.in/yaml.v2"
+)
+
+// RegisterAdminRoutes adds 'GET /config' to the admin server, which returns the loaded Config
+// as YAML with passwords, secrets and keys redacted.
+func RegisterAdminRoutes(logger log.Logger, svc *admin.Server, cfg *Config) {
+	svc.AddHandler("/config", getConfig(logger, cfg))
+}
+
+func getConfig(logger log.Logger, cfg *Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			moovhttp.Problem(w, fmt.Errorf("unsupported HTTP verb: %s", r.Method))
+			return
+		}
+
+		bs, err := yaml.Marshal(cfg.Redacted())
+		if err != nil {
+			logger.Log("config", fmt.Sprintf("problem rendering config: %v", err))
+			moovhttp.Problem(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(bs)
+	}
+}
+
+// Redacted returns a copy of the Config which is safe to display. Passwords, secrets, tokens,
+// keys and account numbers which are set are replaced.
+func (cfg *Config) Redacted() *Config {
+	out := *cfg
+
+	auth := *cfg.Auth
+	auth.Admin.Tokens = make([]AdminToken, len(cfg.Auth.Admin.Tokens))
+	for i, token := range cfg.Auth.Admin.Tokens {
+		token.SHA256 = redact(token.SHA256)
+		auth.Admin.Tokens[i] = token
+	}
+	out.Auth = &auth
+
+	tracing := *cfg.Tracing
+	if len(tracing.OTLP.Headers) > 0 {
+		tracing.OTLP.Headers = make(map[string]string)
+		for name, value := range cfg.Tracing.OTLP.Headers {
+			tracing.OTLP.Headers[name] = redact(value)
+		}
+	}
+	out.Tracing = &tracing
+
+	database := *cfg.Database
+	database.MySQL.Password = redact(database.MySQL.Password)
+	database.Postgres.Password = redact(database.Postgres.Password)
+	out.Database = &database
+
+	secrets := *cfg.Secrets
+	if len(secrets.PreviousKeys) > 0 {
+		secrets.PreviousKeys = make(map[string]string)
+		for version, url := range cfg.Secrets.PreviousKeys {
+			secrets.PreviousKeys[version] = redact(url)
+		}
+	}
+	secrets.Local.Base64Key = redact(secrets.Local.Base64Key)
+	secrets.Vault.ServerToken = redact(secrets.Vault.ServerToken)
+	out.Secrets = &secrets
+
+	odfi := *cfg.ODFI
+	odfi.AccountNumber = redact(odfi.AccountNumber)
+	out.ODFI = &odfi
+
+	email := *cfg.Email
+	email.SMTP.Password = redact(email.SMTP.Password)
+	email.Verification.Secret = redact(email.Verification.Secret)
+	out.Email = &email
+
+	return &out
+}
+
+func redact(v string) string {
+	if v == "" {
+		return ""
+	}
+	return "REDACTED"
+}
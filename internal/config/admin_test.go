@@ -0,0 +1,64 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/base/admin"
+
+	"github.com/go-kit/kit/log"
+	"gopkg.in/yaml.v2"
+)
+
+func TestConfig__AdminRoutes(t *testing.T) {
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Database.Postgres.Password = "secret"
+	cfg.Email.Verification.Secret = "signing-secret"
+
+	RegisterAdminRoutes(log.NewNopLogger(), svc, cfg)
+
+	resp, err := http.DefaultClient.Get("http://" + svc.BindAddr() + "/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d", resp.StatusCode)
+	}
+	bs, _ := ioutil.ReadAll(resp.Body)
+	if strings.Contains(string(bs), "signing-secret") {
+		t.Errorf("secrets weren't redacted:\n%s", string(bs))
+	}
+
+	var out Config
+	if err := yaml.Unmarshal(bs, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Database.Postgres.Password != "REDACTED" || out.FileTransfer.Interval != cfg.FileTransfer.Interval {
+		t.Errorf("unexpected config: %#v %#v", out.Database, out.FileTransfer)
+	}
+
+	// only GET is supported
+	resp, err = http.DefaultClient.Post("http://"+svc.BindAddr()+"/config", "text/yaml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", resp.StatusCode)
+	}
+}
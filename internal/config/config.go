@@ -5,22 +5,268 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/moov-io/paygate/internal/util"
+
 	"github.com/go-kit/kit/log"
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
-	Logger    log.Logger
-	LogFormat string `yaml:"log_format"`
+	Logger    log.Logger `yaml:"-"`
+	LogFormat string     `yaml:"log_format"`
+
+	HTTP      *HTTPConfig      `yaml:"http"`
+	Auth      *AuthConfig      `yaml:"auth"`
+	RateLimit *RateLimitConfig `yaml:"rateLimit"`
+	Tracing   *TracingConfig   `yaml:"tracing"`
+	Database  *DatabaseConfig  `yaml:"database"`
+	Secrets   *SecretsConfig   `yaml:"secrets"`
+
+	ACH           *ACHConfig           `yaml:"ach"`
+	Accounts      *AccountsConfig      `yaml:"accounts"`
+	Customers     *CustomersConfig     `yaml:"customers"`
+	Email         *EmailConfig         `yaml:"email"`
+	FED           *FEDConfig           `yaml:"fed"`
+	FileTransfer  *FileTransferConfig  `yaml:"fileTransfer"`
+	MicroDeposits *MicroDepositsConfig `yaml:"microDeposits"`
+	ODFI          *ODFIConfig          `yaml:"odfi"`
+	Verification  *VerificationConfig  `yaml:"verification"`
+}
+
+// HTTPConfig sets up the HTTP servers paygate binds and the clients it makes calls with.
+type HTTPConfig struct {
+	// BindAddress and AdminBindAddress override the -http.addr and -admin.addr flags.
+	BindAddress      string `yaml:"bindAddress"`
+	AdminBindAddress string `yaml:"adminBindAddress"`
+
+	// TLSCertFile and TLSKeyFile are served by the HTTP server when both are set.
+	TLSCertFile string `yaml:"tlsCertFile"`
+	TLSKeyFile  string `yaml:"tlsKeyFile"`
+
+	// ClientCAFile has additional (CA) certificates trusted by each http.Client.
+	ClientCAFile string `yaml:"clientCAFile"`
+
+	// RequestTimeout is how long the HTTP server works on a request, including the calls it makes
+	// to other services and the database, before giving up on it.
+	RequestTimeout time.Duration `yaml:"requestTimeout"`
+
+	// ShutdownTimeout is how long paygate waits on shutdown for HTTP requests and the file transfer
+	// operation in progress (merging, uploading or downloading ACH files) to finish.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+}
+
+// AuthConfig chooses how requests to the public HTTP server are authenticated. Each enabled
+// authenticator is tried in turn and requests without valid credentials are rejected.
+type AuthConfig struct {
+	// TrustedProxy accepts the X-User-ID header as-is. Only enable this when paygate is
+	// behind a proxy which authenticates requests and sets the header itself.
+	TrustedProxy TrustedProxyAuthConfig `yaml:"trustedProxy"`
+
+	JWT     JWTAuthConfig     `yaml:"jwt"`
+	APIKeys APIKeysAuthConfig `yaml:"apiKeys"`
+	MTLS    MTLSAuthConfig    `yaml:"mtls"`
+
+	// Admin authenticates requests to the admin server, which are separate from the public HTTP server.
+	Admin AdminAuthConfig `yaml:"admin"`
+}
+
+type TrustedProxyAuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Scopes are granted to every request from the proxy, all scopes are granted when empty.
+	Scopes []string `yaml:"scopes"`
+}
+
+// JWTAuthConfig verifies bearer tokens (e.g. from an OIDC provider) against keys in a local JWKS file.
+type JWTAuthConfig struct {
+	// JWKSFile is a JSON Web Key Set, bearer tokens are accepted when it's set.
+	JWKSFile string `yaml:"jwksFile"`
+
+	// Issuer and Audience must match the token's iss and aud claims when set.
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+
+	// UserClaim is the claim holding the userID and ScopeClaim holds space separated scopes.
+	UserClaim  string `yaml:"userClaim"`
+	ScopeClaim string `yaml:"scopeClaim"`
+}
 
-	Customers *CustomersConfig
+// APIKeysAuthConfig accepts keys sent in the X-API-Key header, which are created and stored
+// (hashed) through the admin server.
+type APIKeysAuthConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// MTLSAuthConfig accepts client certificates signed by ClientCAFile. The certificate's common
+// name is used as the userID. The HTTP server needs to be serving TLS.
+type MTLSAuthConfig struct {
+	ClientCAFile string `yaml:"clientCAFile"`
+
+	// Scopes are granted to every client certificate, all scopes are granted when empty.
+	Scopes []string `yaml:"scopes"`
+}
+
+// AdminAuthConfig lists the bearer tokens accepted by the admin server. Every admin route other than
+// /live, /ready, /metrics and /version is rejected when there are no tokens.
+type AdminAuthConfig struct {
+	Tokens []AdminToken `yaml:"tokens"`
+}
+
+// AdminToken grants Role (one of "viewer", "operator" or "security") to requests sent with
+// an 'Authorization: Bearer <token>' header. Only the token's hex encoded SHA-256 hash is configured.
+type AdminToken struct {
+	Name   string `yaml:"name"`
+	Role   string `yaml:"role"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// Enabled returns true if any authenticator is turned on.
+func (cfg *AuthConfig) Enabled() bool {
+	return cfg.TrustedProxy.Enabled || cfg.JWT.JWKSFile != "" || cfg.APIKeys.Enabled || cfg.MTLS.ClientCAFile != ""
+}
+
+// RateLimitConfig throttles requests to the HTTP server with token buckets kept for each user
+// (or client IP when a request isn't authenticated). Requests over a limit get a 429 response.
+type RateLimitConfig struct {
+	// User limits each user's requests across every route.
+	User RateLimit `yaml:"user"`
+
+	// Routes limits each user's requests to one route, keyed by method and path template
+	// (e.g. "POST /transfers" or "POST /depositories/{depositoryId}/micro-deposits").
+	Routes map[string]RateLimit `yaml:"routes"`
+
+	// Store is where buckets are kept, "memory" (the default) or "database" to share
+	// limits between paygate instances.
+	Store string `yaml:"store"`
+}
+
+// RateLimit allows Rate requests per second on average, with bursts of up to Burst requests.
+// A zero Rate is unlimited.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// Enabled returns true if any limit is set.
+func (cfg *RateLimitConfig) Enabled() bool {
+	return cfg.User.Rate > 0 || len(cfg.Routes) > 0
+}
+
+// TracingConfig exports spans for HTTP requests, calls to other services, database writes and
+// file transfers. Exporter is one of "stdout" or "otlp", tracing is off when it's empty.
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+
+	// ServiceName is reported as the service.name of every span.
+	ServiceName string `yaml:"serviceName"`
+
+	// SampleRatio is the fraction of new traces which are recorded, every trace is recorded when it's
+	// zero. Requests which are part of a trace started by the caller follow the caller's decision.
+	SampleRatio float64 `yaml:"sampleRatio"`
+
+	OTLP OTLPConfig `yaml:"otlp"`
+}
+
+// OTLPConfig sends spans to an OpenTelemetry collector with OTLP over HTTP.
+type OTLPConfig struct {
+	// Endpoint is the collector's base URL, spans are posted to its /v1/traces path.
+	Endpoint string `yaml:"endpoint"`
+
+	// Headers are added to every export request, e.g. for authenticating with a vendor.
+	Headers map[string]string `yaml:"headers"`
+
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// DatabaseConfig chooses the database paygate stores records in. Type is one of
+// "sqlite", "mysql" or "postgres".
+type DatabaseConfig struct {
+	Type string `yaml:"type"`
+
+	MySQL    MySQLConfig    `yaml:"mysql"`
+	Postgres PostgresConfig `yaml:"postgres"`
+	SQLite   SQLiteConfig   `yaml:"sqlite"`
+}
+
+type MySQLConfig struct {
+	// Address is the TCP address of the server, e.g. tcp(hostname:3306)
+	Address  string `yaml:"address"`
+	Database string `yaml:"database"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+
+	MaxConnections int           `yaml:"maxConnections"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+type PostgresConfig struct {
+	// Address is the host and port of the server, e.g. hostname:5432
+	Address  string `yaml:"address"`
+	Database string `yaml:"database"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+
+	// SSLMode is passed to the server as-is, PostgreSQL's default of require is used when empty.
+	SSLMode string `yaml:"sslMode"`
+
+	MaxConnections int           `yaml:"maxConnections"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+type SQLiteConfig struct {
+	Path string `yaml:"path"`
+}
+
+// SecretsConfig sets up the keys account numbers and other PII are encrypted with. Provider
+// is one of "local", "gcp" or "vault".
+type SecretsConfig struct {
+	Provider string `yaml:"provider"`
+
+	// KeyVersion is tagged onto values encrypted with the current key.
+	KeyVersion string `yaml:"keyVersion"`
+
+	// PreviousKeys maps a key version to the Go CDK keeper URL of a rotated key, which is only used
+	// to decrypt values. An empty version is for values written before versions were tagged.
+	PreviousKeys map[string]string `yaml:"previousKeys"`
+
+	Local LocalSecretsConfig `yaml:"local"`
+	GCP   GCPSecretsConfig   `yaml:"gcp"`
+	Vault VaultSecretsConfig `yaml:"vault"`
+}
+
+type LocalSecretsConfig struct {
+	// Base64Key looks like base64key://value where value is a 32 byte random key.
+	Base64Key string `yaml:"base64Key"`
+}
+
+type GCPSecretsConfig struct {
+	// KeyResourceID has the form projects/MYPROJECT/locations/MYLOCATION/keyRings/MYKEYRING/cryptoKeys/MYKEY
+	KeyResourceID string `yaml:"keyResourceID"`
+}
+
+type VaultSecretsConfig struct {
+	ServerURL   string `yaml:"serverURL"`
+	ServerToken string `yaml:"serverToken"`
+}
+
+type ACHConfig struct {
+	Endpoint string `yaml:"endpoint"`
+}
+
+type AccountsConfig struct {
+	Disabled bool   `yaml:"disabled"`
+	Endpoint string `yaml:"endpoint"`
 }
 
 type CustomersConfig struct {
@@ -31,14 +277,490 @@ type CustomersConfig struct {
 	OFACRefreshEvery time.Duration `yaml:"ofacRefreshEvery"`
 }
 
+// EmailConfig sets up how emails are sent. SMTP is used when an address is set, otherwise emails
+// are written into Directory. One of them is required unless LogOnly is set.
+type EmailConfig struct {
+	From string `yaml:"from"`
+
+	SMTP      SMTPConfig `yaml:"smtp"`
+	Directory string     `yaml:"directory"`
+
+	// LogOnly drops emails after logging their subject and a masked recipient. It's only
+	// meant for local development as Receivers can't verify their email address.
+	LogOnly bool `yaml:"logOnly"`
+
+	Verification EmailVerificationConfig `yaml:"verification"`
+}
+
+type SMTPConfig struct {
+	// Address is the host:port of an SMTP server
+	Address  string `yaml:"address"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// EmailVerificationConfig controls the tokens sent to Receivers to confirm their email address.
+type EmailVerificationConfig struct {
+	// Secret signs verification tokens. A random secret is used when empty, which invalidates
+	// tokens sent before paygate restarts.
+	Secret string `yaml:"secret"`
+
+	// Expiration is how long a verification token can be confirmed for.
+	Expiration time.Duration `yaml:"expiration"`
+
+	// URL is included in emails with {token} replaced by the verification token, e.g.
+	// https://app.example.com/verify-email?token={token}
+	URL string `yaml:"url"`
+}
+
+type FEDConfig struct {
+	Endpoint string `yaml:"endpoint"`
+
+	// CacheTTL is how long routing number lookups are kept. Expired lookups are still
+	// used when the FED service is unavailable.
+	CacheTTL time.Duration `yaml:"cacheTTL"`
+}
+
+// FileTransferConfig controls how ACH files are merged and sync'd with their remote servers.
+//
+// The per-routing number configs, cutoff times and FTP/SFTP credentials are read from the same
+// fileTransfer section of the config file by the filetransfer package.
+type FileTransferConfig struct {
+	// Disabled turns off merging and uploading ACH files.
+	Disabled bool `yaml:"disabled"`
+
+	// Interval is how often to merge and upload ACH files and download incoming files.
+	Interval time.Duration `yaml:"interval"`
+
+	// BatchSize is the number of records pulled from the database at a time.
+	BatchSize int `yaml:"batchSize"`
+
+	// MaxLines is the line count before an ACH file is uploaded. NACHA guidelines have a hard
+	// limit of 10,000 lines.
+	MaxLines int `yaml:"maxLines"`
+
+	// ForcedCutoffUploadDelta is how long before a cutoff time files are uploaded.
+	ForcedCutoffUploadDelta time.Duration `yaml:"forcedCutoffUploadDelta"`
+
+	// StorageDir is a scratch directory for outbound and incoming ACH files.
+	StorageDir string `yaml:"storageDir"`
+
+	// HealthCheckInterval is how often each ODFI's FTP or SFTP server is checked by logging in and
+	// listing its inbound and outbound directories.
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval"`
+
+	// Readiness is how ODFI server checks gate paygate's readiness. "all" needs every ODFI's server
+	// to pass its last check and "any" needs at least one to.
+	Readiness string `yaml:"readiness"`
+
+	FTP  FTPConfig  `yaml:"ftp"`
+	SFTP SFTPConfig `yaml:"sftp"`
+}
+
+type FTPConfig struct {
+	DialTimeout time.Duration `yaml:"dialTimeout"`
+
+	// DisabledEPSV stops EPSV from being used, even if the server supports it.
+	DisabledEPSV bool `yaml:"disabledEPSV"`
+
+	// CAFile has additional (CA) certificates trusted by each FTP client.
+	CAFile string `yaml:"caFile"`
+}
+
+type SFTPConfig struct {
+	DialTimeout time.Duration `yaml:"dialTimeout"`
+
+	// MaxConnectionsPerFile is the maximum number of concurrent requests to a file.
+	//
+	// See: https://godoc.org/github.com/pkg/sftp#MaxConcurrentRequestsPerFile
+	MaxConnectionsPerFile int `yaml:"maxConnectionsPerFile"`
+
+	// MaxPacketSize is the maximum size of each packet in bytes. Try lowering this
+	// on "failed to send packet header: EOF" errors.
+	MaxPacketSize int `yaml:"maxPacketSize"`
+}
+
+type MicroDepositsConfig struct {
+	// Expiration is how long after being initiated micro-deposits can be confirmed.
+	// Unconfirmed micro-deposits are voided afterwards and need to be resent.
+	Expiration time.Duration `yaml:"expiration"`
+
+	// EscalateAfter is how long a Depository can remain unverified before it's reported.
+	EscalateAfter time.Duration `yaml:"escalateAfter"`
+
+	// CheckEvery is how often expired micro-deposits and unverified Depositories are checked.
+	CheckEvery time.Duration `yaml:"checkEvery"`
+}
+
+// ODFIConfig is the Financial Institution (and its account) which originates micro-deposits.
+type ODFIConfig struct {
+	RoutingNumber string `yaml:"routingNumber"`
+	AccountNumber string `yaml:"accountNumber"`
+
+	// AccountType is either "checking" or "savings".
+	AccountType string `yaml:"accountType"`
+
+	Identification string `yaml:"identification"`
+	BankName       string `yaml:"bankName"`
+	Holder         string `yaml:"holder"`
+}
+
+// VerificationConfig chooses how Depositories are verified. Strategies are one of
+// "micro-deposits", "prenote" or "instant".
+type VerificationConfig struct {
+	// Default is the strategy used when no user or holder type policy matches.
+	Default string `yaml:"default"`
+
+	// Users maps a userID to the strategy used for their Depositories.
+	// User policies take precedence over holder type policies.
+	Users map[string]string `yaml:"users"`
+
+	// HolderTypes maps a Depository HolderType (individual or business) to a strategy.
+	HolderTypes map[string]string `yaml:"holderTypes"`
+
+	// PrenoteBankingDays is how many banking days after a prenote is uploaded without
+	// a return before its Depository is verified.
+	PrenoteBankingDays int `yaml:"prenoteBankingDays"`
+
+	Instant InstantVerificationConfig `yaml:"instant"`
+}
+
+type InstantVerificationConfig struct {
+	// Endpoint is the HTTP address of an instant account verification provider.
+	// The instant strategy is unavailable when it's empty.
+	Endpoint string `yaml:"endpoint"`
+}
+
+func (cfg *HTTPConfig) validate() error {
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return errors.New("config: http.tlsCertFile and http.tlsKeyFile must both be set")
+	}
+	return nil
+}
+
+func (cfg *AuthConfig) validate() error {
+	names := make(map[string]bool)
+	for _, token := range cfg.Admin.Tokens {
+		if token.Name == "" || names[token.Name] {
+			return fmt.Errorf("config: admin token names must be unique and non-empty: %q", token.Name)
+		}
+		names[token.Name] = true
+
+		switch token.Role {
+		case "viewer", "operator", "security":
+		default:
+			return fmt.Errorf("config: unknown role %q for admin token %s", token.Role, token.Name)
+		}
+		if bs, err := hex.DecodeString(token.SHA256); err != nil || len(bs) != sha256.Size {
+			return fmt.Errorf("config: admin token %s needs a hex encoded SHA-256 hash", token.Name)
+		}
+	}
+	return nil
+}
+
+func (cfg *RateLimitConfig) validate() error {
+	switch cfg.Store {
+	case "memory", "database":
+	default:
+		return fmt.Errorf("config: unknown rate limit store %q", cfg.Store)
+	}
+	if err := cfg.User.validate("user"); err != nil {
+		return err
+	}
+	for route, limit := range cfg.Routes {
+		if parts := strings.Fields(route); len(parts) != 2 || !strings.HasPrefix(parts[1], "/") {
+			return fmt.Errorf("config: rate limited route %q needs to be a method and path", route)
+		}
+		if err := limit.validate(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (limit RateLimit) validate(name string) error {
+	if limit.Rate < 0 || limit.Burst < 0 || (limit.Rate > 0 && limit.Burst == 0) {
+		return fmt.Errorf("config: %s rate limit needs a positive rate and burst: %v", name, limit)
+	}
+	return nil
+}
+
+func (cfg *TracingConfig) validate() error {
+	switch cfg.Exporter {
+	case "", "stdout":
+	case "otlp":
+		u, err := url.Parse(cfg.OTLP.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("config: tracing.otlp.endpoint needs to be an http(s) URL: %q", cfg.OTLP.Endpoint)
+		}
+	default:
+		return fmt.Errorf("config: unknown tracing exporter %q", cfg.Exporter)
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return fmt.Errorf("config: tracing.sampleRatio needs to be from 0 to 1: %v", cfg.SampleRatio)
+	}
+	return nil
+}
+
+func (cfg *DatabaseConfig) validate() error {
+	switch strings.ToLower(cfg.Type) {
+	case "sqlite":
+		return nil
+	case "mysql":
+		return checkConnection("mysql", cfg.MySQL.Address, cfg.MySQL.Database, cfg.MySQL.MaxConnections, cfg.MySQL.Timeout)
+	case "postgres", "postgresql":
+		return checkConnection("postgres", cfg.Postgres.Address, cfg.Postgres.Database, cfg.Postgres.MaxConnections, cfg.Postgres.Timeout)
+	}
+	return fmt.Errorf("config: unknown database type %q", cfg.Type)
+}
+
+func checkConnection(name, address, database string, maxConnections int, timeout time.Duration) error {
+	if address == "" || database == "" {
+		return fmt.Errorf("config: database.%s.address and database.%s.database are required", name, name)
+	}
+	if maxConnections <= 0 {
+		return fmt.Errorf("config: database.%s.maxConnections must be positive: %d", name, maxConnections)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("config: database.%s.timeout must be positive: %v", name, timeout)
+	}
+	return nil
+}
+
+func (cfg *SecretsConfig) validate() error {
+	switch strings.ToLower(cfg.Provider) {
+	case "local", "vault":
+	case "gcp":
+		if cfg.GCP.KeyResourceID == "" {
+			return errors.New("config: secrets.gcp.keyResourceID is required for the gcp provider")
+		}
+	default:
+		return fmt.Errorf("config: unknown secrets provider %q", cfg.Provider)
+	}
+	if strings.Contains(cfg.KeyVersion, ":") {
+		return fmt.Errorf("config: secrets.keyVersion %q cannot contain ':'", cfg.KeyVersion)
+	}
+	for version, url := range cfg.PreviousKeys {
+		if url == "" {
+			return fmt.Errorf("config: secrets.previousKeys has no key URL for version %q", version)
+		}
+	}
+	return nil
+}
+
+func (cfg *FileTransferConfig) validate() error {
+	if !cfg.Disabled && cfg.Interval <= 0 {
+		return fmt.Errorf("config: fileTransfer.interval must be positive: %v", cfg.Interval)
+	}
+	if cfg.BatchSize <= 0 {
+		return fmt.Errorf("config: fileTransfer.batchSize must be positive: %d", cfg.BatchSize)
+	}
+	if cfg.MaxLines <= 0 {
+		return fmt.Errorf("config: fileTransfer.maxLines must be positive: %d", cfg.MaxLines)
+	}
+	if cfg.ForcedCutoffUploadDelta <= 0 {
+		return fmt.Errorf("config: fileTransfer.forcedCutoffUploadDelta must be positive: %v", cfg.ForcedCutoffUploadDelta)
+	}
+	switch cfg.Readiness {
+	case "all", "any":
+	default:
+		return fmt.Errorf("config: unknown fileTransfer.readiness %q", cfg.Readiness)
+	}
+	if cfg.FTP.DialTimeout <= 0 || cfg.SFTP.DialTimeout <= 0 {
+		return errors.New("config: fileTransfer ftp.dialTimeout and sftp.dialTimeout must be positive")
+	}
+	if cfg.SFTP.MaxConnectionsPerFile <= 0 {
+		return fmt.Errorf("config: fileTransfer.sftp.maxConnectionsPerFile must be positive: %d", cfg.SFTP.MaxConnectionsPerFile)
+	}
+	if cfg.SFTP.MaxPacketSize <= 0 {
+		return fmt.Errorf("config: fileTransfer.sftp.maxPacketSize must be positive: %d", cfg.SFTP.MaxPacketSize)
+	}
+	return nil
+}
+
+func (cfg *ODFIConfig) validate() error {
+	if len(cfg.RoutingNumber) != 9 {
+		return fmt.Errorf("config: odfi.routingNumber %q must be 9 digits", cfg.RoutingNumber)
+	}
+	if _, err := strconv.ParseUint(cfg.RoutingNumber, 10, 64); err != nil {
+		return fmt.Errorf("config: odfi.routingNumber %q must be 9 digits", cfg.RoutingNumber)
+	}
+	if cfg.AccountNumber == "" {
+		return errors.New("config: odfi.accountNumber is required")
+	}
+	switch strings.ToLower(cfg.AccountType) {
+	case "checking", "savings":
+	default:
+		return fmt.Errorf("config: odfi.accountType %q must be checking or savings", cfg.AccountType)
+	}
+	return nil
+}
+
+var verificationStrategies = []string{"micro-deposits", "prenote", "instant"}
+
+func (cfg *VerificationConfig) validate() error {
+	check := func(strategy string) error {
+		for i := range verificationStrategies {
+			if strategy == verificationStrategies[i] {
+				return nil
+			}
+		}
+		return fmt.Errorf("config: unknown verification strategy %q", strategy)
+	}
+	if err := check(cfg.Default); err != nil {
+		return err
+	}
+	for _, strategy := range cfg.Users {
+		if err := check(strategy); err != nil {
+			return err
+		}
+	}
+	for _, strategy := range cfg.HolderTypes {
+		if err := check(strategy); err != nil {
+			return err
+		}
+	}
+	if cfg.PrenoteBankingDays < 0 {
+		return fmt.Errorf("config: negative prenote banking days: %d", cfg.PrenoteBankingDays)
+	}
+	return nil
+}
+
+// validate checks each section of the Config, returning the first problem found.
+func (cfg *Config) validate() error {
+	if cfg.Auth.MTLS.ClientCAFile != "" && cfg.HTTP.TLSCertFile == "" {
+		return errors.New("config: auth.mtls.clientCAFile requires http.tlsCertFile and http.tlsKeyFile")
+	}
+	validators := []interface{ validate() error }{
+		cfg.HTTP,
+		cfg.Auth,
+		cfg.RateLimit,
+		cfg.Tracing,
+		cfg.Database,
+		cfg.Secrets,
+		cfg.FileTransfer,
+		cfg.ODFI,
+		cfg.Verification,
+	}
+	for i := range validators {
+		if err := validators[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseStrategies reads a comma separated list of key=strategy pairs (e.g. business=prenote,individual=instant)
+func parseStrategies(v string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("config: invalid verification policy %q", pair)
+		}
+		out[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return out, nil
+}
+
+// parseAdminTokens reads a comma separated list of name:role:sha256 entries (e.g. alice:operator:9f86d0...)
+func parseAdminTokens(v string) ([]AdminToken, error) {
+	var out []AdminToken
+	for _, entry := range strings.Split(v, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("config: invalid AUTH_ADMIN_TOKENS entry %q", entry)
+		}
+		out = append(out, AdminToken{Name: parts[0], Role: parts[1], SHA256: strings.ToLower(parts[2])})
+	}
+	return out, nil
+}
+
+// parseRateLimit reads a rate:burst pair (e.g. 10:20 for 10 requests per second with bursts of 20)
+func parseRateLimit(v string) (RateLimit, error) {
+	var limit RateLimit
+	parts := strings.Split(strings.TrimSpace(v), ":")
+	if len(parts) != 2 {
+		return limit, fmt.Errorf("config: invalid rate limit %q", v)
+	}
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return limit, fmt.Errorf("config: invalid rate limit %q: %v", v, err)
+	}
+	burst, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return limit, fmt.Errorf("config: invalid rate limit %q: %v", v, err)
+	}
+	return RateLimit{Rate: rate, Burst: burst}, nil
+}
+
+// parseRateLimitRoutes reads a comma separated list of route=rate:burst pairs (e.g. POST /transfers=1:5,GET /transfers=5:10)
+func parseRateLimitRoutes(v string) (map[string]RateLimit, error) {
+	out := make(map[string]RateLimit)
+	for _, pair := range strings.Split(v, ",") {
+		idx := strings.LastIndex(pair, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("config: invalid RATE_LIMIT_ROUTES entry %q", pair)
+		}
+		limit, err := parseRateLimit(pair[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		out[strings.Join(strings.Fields(pair[:idx]), " ")] = limit
+	}
+	return out, nil
+}
+
+// parseHeaders reads a comma separated list of name=value pairs (e.g. x-api-key=abc,x-team=payments)
+func parseHeaders(v string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("config: invalid TRACING_OTLP_HEADERS entry %q", pair)
+		}
+		out[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return out, nil
+}
+
+// parseKeyVersions reads a comma separated list of version=url pairs (e.g. v1=base64key://...,v2=gcpkms://...)
+func parseKeyVersions(v string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("config: invalid SECRETS_PREVIOUS_KEYS entry for version %q", parts[0])
+		}
+		out[parts[0]] = parts[1]
+	}
+	return out, nil
+}
+
 func Empty() *Config {
 	cfg := Config{
-		Logger:    log.NewNopLogger(),
-		Customers: &CustomersConfig{},
+		Logger:        log.NewNopLogger(),
+		HTTP:          &HTTPConfig{},
+		Auth:          &AuthConfig{},
+		RateLimit:     &RateLimitConfig{},
+		Tracing:       &TracingConfig{},
+		Database:      &DatabaseConfig{},
+		Secrets:       &SecretsConfig{},
+		ACH:           &ACHConfig{},
+		Accounts:      &AccountsConfig{},
+		Customers:     &CustomersConfig{},
+		Email:         &EmailConfig{},
+		FED:           &FEDConfig{},
+		FileTransfer:  &FileTransferConfig{},
+		MicroDeposits: &MicroDepositsConfig{},
+		ODFI:          &ODFIConfig{},
+		Verification:  &VerificationConfig{},
 	}
 	return &cfg
 }
 
+// LoadConfig reads the YAML file at path (if non-empty), overrides keys with any environment
+// variables that are set and validates the result.
 func LoadConfig(path string, logFormat *string) (*Config, error) {
 	cfg := Empty()
 
@@ -79,25 +801,333 @@ func override(env string, field *string) {
 	}
 }
 
+// overrideBool accepts yes and no along with the values strconv.ParseBool does.
+func overrideBool(env string, field *bool) error {
+	if v := os.Getenv(env); v != "" {
+		if util.Yes(v) || strings.EqualFold(v, "no") {
+			*field = util.Yes(v)
+			return nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("config: invalid %s: %v", env, err)
+		}
+		*field = b
+	}
+	return nil
+}
+
+func overrideInt(env string, field *int) error {
+	if v := os.Getenv(env); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("config: invalid %s: %v", env, err)
+		}
+		*field = n
+	}
+	return nil
+}
+
+func overrideFloat(env string, field *float64) error {
+	if v := os.Getenv(env); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("config: invalid %s: %v", env, err)
+		}
+		*field = f
+	}
+	return nil
+}
+
+func overrideDuration(env string, field *time.Duration) error {
+	if v := os.Getenv(env); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("config: invalid %s: %v", env, err)
+		}
+		*field = dur
+	}
+	return nil
+}
+
+// OverrideWithEnvVars replaces config values with environment variables that are set, fills in
+// defaults for any values which are still empty and validates the config.
 func OverrideWithEnvVars(cfg *Config) error {
-	var err error
+	// keep the first error, but still apply every override
+	var firstErr error
+	check := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
 
-	override("CUSTOMERS_ENDPOINT", &cfg.Customers.Endpoint)
-	if v := os.Getenv("CUSTOMERS_CALLS_DISABLED"); v != "" {
-		cfg.Customers.Disabled, err = strconv.ParseBool(v)
+	override("HTTP_BIND_ADDRESS", &cfg.HTTP.BindAddress)
+	override("HTTP_ADMIN_BIND_ADDRESS", &cfg.HTTP.AdminBindAddress)
+	override("HTTPS_CERT_FILE", &cfg.HTTP.TLSCertFile)
+	override("HTTPS_KEY_FILE", &cfg.HTTP.TLSKeyFile)
+	override("HTTP_CLIENT_CAFILE", &cfg.HTTP.ClientCAFile)
+	check(overrideDuration("HTTP_REQUEST_TIMEOUT", &cfg.HTTP.RequestTimeout))
+	if cfg.HTTP.RequestTimeout == 0*time.Second {
+		cfg.HTTP.RequestTimeout = 30 * time.Second
+	}
+	check(overrideDuration("SHUTDOWN_TIMEOUT", &cfg.HTTP.ShutdownTimeout))
+	if cfg.HTTP.ShutdownTimeout == 0*time.Second {
+		cfg.HTTP.ShutdownTimeout = time.Minute
+	}
+
+	check(overrideBool("AUTH_TRUSTED_PROXY", &cfg.Auth.TrustedProxy.Enabled))
+	override("AUTH_JWT_JWKS_FILE", &cfg.Auth.JWT.JWKSFile)
+	override("AUTH_JWT_ISSUER", &cfg.Auth.JWT.Issuer)
+	override("AUTH_JWT_AUDIENCE", &cfg.Auth.JWT.Audience)
+	if cfg.Auth.JWT.UserClaim == "" {
+		cfg.Auth.JWT.UserClaim = "sub"
+	}
+	if cfg.Auth.JWT.ScopeClaim == "" {
+		cfg.Auth.JWT.ScopeClaim = "scope"
+	}
+	check(overrideBool("AUTH_API_KEYS", &cfg.Auth.APIKeys.Enabled))
+	override("AUTH_MTLS_CLIENT_CAFILE", &cfg.Auth.MTLS.ClientCAFile)
+	if v := os.Getenv("AUTH_ADMIN_TOKENS"); v != "" {
+		tokens, err := parseAdminTokens(v)
+		check(err)
+		cfg.Auth.Admin.Tokens = tokens
+	}
+
+	if v := os.Getenv("RATE_LIMIT_USER"); v != "" {
+		limit, err := parseRateLimit(v)
+		check(err)
+		cfg.RateLimit.User = limit
+	}
+	if v := os.Getenv("RATE_LIMIT_ROUTES"); v != "" {
+		routes, err := parseRateLimitRoutes(v)
+		check(err)
+		cfg.RateLimit.Routes = routes
+	}
+	override("RATE_LIMIT_STORE", &cfg.RateLimit.Store)
+	if cfg.RateLimit.Store == "" {
+		cfg.RateLimit.Store = "memory"
+	}
+
+	override("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	override("TRACING_SERVICE_NAME", &cfg.Tracing.ServiceName)
+	if cfg.Tracing.ServiceName == "" {
+		cfg.Tracing.ServiceName = "paygate"
+	}
+	check(overrideFloat("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio))
+	if cfg.Tracing.SampleRatio == 0 {
+		cfg.Tracing.SampleRatio = 1.0
+	}
+	override("TRACING_OTLP_ENDPOINT", &cfg.Tracing.OTLP.Endpoint)
+	if cfg.Tracing.OTLP.Endpoint == "" {
+		cfg.Tracing.OTLP.Endpoint = "http://localhost:4318"
+	}
+	if v := os.Getenv("TRACING_OTLP_HEADERS"); v != "" {
+		headers, err := parseHeaders(v)
+		check(err)
+		cfg.Tracing.OTLP.Headers = headers
+	}
+	check(overrideDuration("TRACING_OTLP_TIMEOUT", &cfg.Tracing.OTLP.Timeout))
+	if cfg.Tracing.OTLP.Timeout == 0*time.Second {
+		cfg.Tracing.OTLP.Timeout = 10 * time.Second
+	}
+
+	override("DATABASE_TYPE", &cfg.Database.Type)
+	if cfg.Database.Type == "" {
+		cfg.Database.Type = "sqlite"
+	}
+	override("MYSQL_ADDRESS", &cfg.Database.MySQL.Address)
+	override("MYSQL_DATABASE", &cfg.Database.MySQL.Database)
+	override("MYSQL_USER", &cfg.Database.MySQL.User)
+	override("MYSQL_PASSWORD", &cfg.Database.MySQL.Password)
+	check(overrideInt("MYSQL_MAX_CONNECTIONS", &cfg.Database.MySQL.MaxConnections))
+	if cfg.Database.MySQL.MaxConnections == 0 {
+		cfg.Database.MySQL.MaxConnections = 16
+	}
+	check(overrideDuration("MYSQL_TIMEOUT", &cfg.Database.MySQL.Timeout))
+	if cfg.Database.MySQL.Timeout == 0*time.Second {
+		cfg.Database.MySQL.Timeout = 30 * time.Second
+	}
+	override("POSTGRES_ADDRESS", &cfg.Database.Postgres.Address)
+	override("POSTGRES_DATABASE", &cfg.Database.Postgres.Database)
+	override("POSTGRES_USER", &cfg.Database.Postgres.User)
+	override("POSTGRES_PASSWORD", &cfg.Database.Postgres.Password)
+	override("POSTGRES_SSLMODE", &cfg.Database.Postgres.SSLMode)
+	check(overrideInt("POSTGRES_MAX_CONNECTIONS", &cfg.Database.Postgres.MaxConnections))
+	if cfg.Database.Postgres.MaxConnections == 0 {
+		cfg.Database.Postgres.MaxConnections = 16
+	}
+	check(overrideDuration("POSTGRES_TIMEOUT", &cfg.Database.Postgres.Timeout))
+	if cfg.Database.Postgres.Timeout == 0*time.Second {
+		cfg.Database.Postgres.Timeout = 30 * time.Second
+	}
+	override("SQLITE_DB_PATH", &cfg.Database.SQLite.Path)
+	if cfg.Database.SQLite.Path == "" {
+		cfg.Database.SQLite.Path = "paygate.db"
+	}
+
+	override("CLOUD_PROVIDER", &cfg.Secrets.Provider)
+	if cfg.Secrets.Provider == "" {
+		cfg.Secrets.Provider = "local"
+	}
+	override("SECRETS_KEY_VERSION", &cfg.Secrets.KeyVersion)
+	if v := os.Getenv("SECRETS_PREVIOUS_KEYS"); v != "" {
+		previous, err := parseKeyVersions(v)
+		check(err)
+		cfg.Secrets.PreviousKeys = previous
 	}
-	if v := os.Getenv("CUSTOMERS_OFAC_BATCH_SIZE"); v != "" {
-		cfg.Customers.OFACBatchSize, err = strconv.Atoi(v)
+	override("SECRETS_LOCAL_BASE64_KEY", &cfg.Secrets.Local.Base64Key)
+	override("SECRETS_GCP_KEY_RESOURCE_ID", &cfg.Secrets.GCP.KeyResourceID)
+	override("VAULT_SERVER_URL", &cfg.Secrets.Vault.ServerURL)
+	if cfg.Secrets.Vault.ServerURL == "" {
+		cfg.Secrets.Vault.ServerURL = "http://127.0.0.1:8200"
 	}
+	override("VAULT_SERVER_TOKEN", &cfg.Secrets.Vault.ServerToken)
+
+	override("ACH_ENDPOINT", &cfg.ACH.Endpoint)
+
+	override("ACCOUNTS_ENDPOINT", &cfg.Accounts.Endpoint)
+	check(overrideBool("ACCOUNTS_CALLS_DISABLED", &cfg.Accounts.Disabled))
+
+	override("CUSTOMERS_ENDPOINT", &cfg.Customers.Endpoint)
+	check(overrideBool("CUSTOMERS_CALLS_DISABLED", &cfg.Customers.Disabled))
+	check(overrideInt("CUSTOMERS_OFAC_BATCH_SIZE", &cfg.Customers.OFACBatchSize))
 	if cfg.Customers.OFACBatchSize == 0 {
 		cfg.Customers.OFACBatchSize = 100
 	}
-	if v := os.Getenv("CUSTOMERS_OFAC_REFRESH_EVERY"); v != "" {
-		cfg.Customers.OFACRefreshEvery, err = time.ParseDuration(v)
-	}
+	check(overrideDuration("CUSTOMERS_OFAC_REFRESH_EVERY", &cfg.Customers.OFACRefreshEvery))
 	if cfg.Customers.OFACRefreshEvery == 0*time.Second {
 		cfg.Customers.OFACRefreshEvery = 7 * 24 * time.Hour // weekly
 	}
 
-	return err
+	override("EMAIL_FROM", &cfg.Email.From)
+	if cfg.Email.From == "" {
+		cfg.Email.From = "paygate@localhost"
+	}
+	override("SMTP_ADDRESS", &cfg.Email.SMTP.Address)
+	override("SMTP_USERNAME", &cfg.Email.SMTP.Username)
+	override("SMTP_PASSWORD", &cfg.Email.SMTP.Password)
+	override("EMAIL_DIRECTORY", &cfg.Email.Directory)
+	check(overrideBool("EMAIL_LOG_ONLY", &cfg.Email.LogOnly))
+	override("EMAIL_VERIFICATION_SECRET", &cfg.Email.Verification.Secret)
+	check(overrideDuration("EMAIL_VERIFICATION_EXPIRATION", &cfg.Email.Verification.Expiration))
+	if cfg.Email.Verification.Expiration == 0*time.Second {
+		cfg.Email.Verification.Expiration = 72 * time.Hour
+	}
+	override("EMAIL_VERIFICATION_URL", &cfg.Email.Verification.URL)
+
+	override("FED_ENDPOINT", &cfg.FED.Endpoint)
+	check(overrideDuration("FED_CACHE_TTL", &cfg.FED.CacheTTL))
+	if cfg.FED.CacheTTL == 0*time.Second {
+		cfg.FED.CacheTTL = 24 * time.Hour
+	}
+
+	// ACH_FILE_TRANSFER_INTERVAL=off has always disabled file transfers
+	if v := os.Getenv("ACH_FILE_TRANSFER_INTERVAL"); strings.EqualFold(v, "off") {
+		cfg.FileTransfer.Disabled = true
+	} else {
+		check(overrideDuration("ACH_FILE_TRANSFER_INTERVAL", &cfg.FileTransfer.Interval))
+	}
+	if cfg.FileTransfer.Interval == 0*time.Second {
+		cfg.FileTransfer.Interval = 10 * time.Minute
+	}
+	check(overrideInt("ACH_FILE_BATCH_SIZE", &cfg.FileTransfer.BatchSize))
+	if cfg.FileTransfer.BatchSize == 0 {
+		cfg.FileTransfer.BatchSize = 100
+	}
+	check(overrideInt("ACH_FILE_MAX_LINES", &cfg.FileTransfer.MaxLines))
+	if cfg.FileTransfer.MaxLines == 0 {
+		cfg.FileTransfer.MaxLines = 10000
+	}
+	check(overrideDuration("FORCED_CUTOFF_UPLOAD_DELTA", &cfg.FileTransfer.ForcedCutoffUploadDelta))
+	if cfg.FileTransfer.ForcedCutoffUploadDelta == 0*time.Second {
+		cfg.FileTransfer.ForcedCutoffUploadDelta = 5 * time.Minute
+	}
+	override("ACH_FILE_STORAGE_DIR", &cfg.FileTransfer.StorageDir)
+	if cfg.FileTransfer.StorageDir == "" {
+		cfg.FileTransfer.StorageDir = "./storage/"
+	}
+	check(overrideDuration("ACH_FILE_TRANSFER_HEALTH_CHECK_INTERVAL", &cfg.FileTransfer.HealthCheckInterval))
+	if cfg.FileTransfer.HealthCheckInterval == 0*time.Second {
+		cfg.FileTransfer.HealthCheckInterval = 5 * time.Minute
+	}
+	override("ACH_FILE_TRANSFER_READINESS", &cfg.FileTransfer.Readiness)
+	if cfg.FileTransfer.Readiness == "" {
+		cfg.FileTransfer.Readiness = "all"
+	}
+	check(overrideDuration("FTP_DIAL_TIMEOUT", &cfg.FileTransfer.FTP.DialTimeout))
+	if cfg.FileTransfer.FTP.DialTimeout == 0*time.Second {
+		cfg.FileTransfer.FTP.DialTimeout = 10 * time.Second
+	}
+	check(overrideBool("FTP_DIAL_WITH_DISABLED_ESPV", &cfg.FileTransfer.FTP.DisabledEPSV))
+	override("ACH_FILE_TRANSFERS_CAFILE", &cfg.FileTransfer.FTP.CAFile)
+	check(overrideDuration("SFTP_DIAL_TIMEOUT", &cfg.FileTransfer.SFTP.DialTimeout))
+	if cfg.FileTransfer.SFTP.DialTimeout == 0*time.Second {
+		cfg.FileTransfer.SFTP.DialTimeout = 10 * time.Second
+	}
+	check(overrideInt("SFTP_MAX_CONNS_PER_FILE", &cfg.FileTransfer.SFTP.MaxConnectionsPerFile))
+	if cfg.FileTransfer.SFTP.MaxConnectionsPerFile == 0 {
+		cfg.FileTransfer.SFTP.MaxConnectionsPerFile = 8 // pkg/sftp's default is 64
+	}
+	check(overrideInt("SFTP_MAX_PACKET_SIZE", &cfg.FileTransfer.SFTP.MaxPacketSize))
+	if cfg.FileTransfer.SFTP.MaxPacketSize == 0 {
+		cfg.FileTransfer.SFTP.MaxPacketSize = 20480 // pkg/sftp's default is 32768
+	}
+
+	check(overrideDuration("MICRO_DEPOSITS_EXPIRATION", &cfg.MicroDeposits.Expiration))
+	if cfg.MicroDeposits.Expiration == 0*time.Second {
+		cfg.MicroDeposits.Expiration = 14 * 24 * time.Hour
+	}
+	check(overrideDuration("MICRO_DEPOSITS_ESCALATE_AFTER", &cfg.MicroDeposits.EscalateAfter))
+	if cfg.MicroDeposits.EscalateAfter == 0*time.Second {
+		cfg.MicroDeposits.EscalateAfter = 30 * 24 * time.Hour
+	}
+	check(overrideDuration("MICRO_DEPOSITS_CHECK_EVERY", &cfg.MicroDeposits.CheckEvery))
+	if cfg.MicroDeposits.CheckEvery == 0*time.Second {
+		cfg.MicroDeposits.CheckEvery = time.Hour
+	}
+
+	override("ODFI_ROUTING_NUMBER", &cfg.ODFI.RoutingNumber)
+	if cfg.ODFI.RoutingNumber == "" {
+		cfg.ODFI.RoutingNumber = "121042882"
+	}
+	override("ODFI_ACCOUNT_NUMBER", &cfg.ODFI.AccountNumber)
+	if cfg.ODFI.AccountNumber == "" {
+		cfg.ODFI.AccountNumber = "123"
+	}
+	override("ODFI_ACCOUNT_TYPE", &cfg.ODFI.AccountType)
+	if cfg.ODFI.AccountType == "" {
+		cfg.ODFI.AccountType = "savings"
+	}
+	override("ODFI_IDENTIFICATION", &cfg.ODFI.Identification)
+	if cfg.ODFI.Identification == "" {
+		cfg.ODFI.Identification = "001"
+	}
+	override("ODFI_BANK_NAME", &cfg.ODFI.BankName)
+	if cfg.ODFI.BankName == "" {
+		cfg.ODFI.BankName = "Moov, Inc"
+	}
+	override("ODFI_HOLDER", &cfg.ODFI.Holder)
+	if cfg.ODFI.Holder == "" {
+		cfg.ODFI.Holder = "Moov, Inc"
+	}
+
+	override("VERIFICATION_DEFAULT_STRATEGY", &cfg.Verification.Default)
+	if cfg.Verification.Default == "" {
+		cfg.Verification.Default = "micro-deposits"
+	}
+	if v := os.Getenv("VERIFICATION_HOLDER_TYPES"); v != "" {
+		holderTypes, err := parseStrategies(v)
+		check(err)
+		cfg.Verification.HolderTypes = holderTypes
+	}
+	check(overrideInt("VERIFICATION_PRENOTE_BANKING_DAYS", &cfg.Verification.PrenoteBankingDays))
+	if cfg.Verification.PrenoteBankingDays == 0 {
+		cfg.Verification.PrenoteBankingDays = 3
+	}
+	override("INSTANT_VERIFICATION_ENDPOINT", &cfg.Verification.Instant.Endpoint)
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return cfg.validate()
 }
@@ -5,9 +5,12 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestConfig__Load(t *testing.T) {
@@ -25,6 +28,359 @@ func TestConfig__Load(t *testing.T) {
 	}
 }
 
+func TestConfig__MicroDeposits(t *testing.T) {
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MicroDeposits.Expiration != 14*24*time.Hour || cfg.MicroDeposits.EscalateAfter != 30*24*time.Hour || cfg.MicroDeposits.CheckEvery != time.Hour {
+		t.Errorf("unexpected defaults: %#v", cfg.MicroDeposits)
+	}
+
+	os.Setenv("MICRO_DEPOSITS_EXPIRATION", "72h")
+	defer os.Unsetenv("MICRO_DEPOSITS_EXPIRATION")
+
+	cfg = Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MicroDeposits.Expiration != 72*time.Hour {
+		t.Errorf("Expiration=%v", cfg.MicroDeposits.Expiration)
+	}
+}
+
+func TestConfig__FED(t *testing.T) {
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.FED.CacheTTL != 24*time.Hour {
+		t.Errorf("CacheTTL=%v", cfg.FED.CacheTTL)
+	}
+
+	os.Setenv("FED_ENDPOINT", "http://fed:8080")
+	os.Setenv("FED_CACHE_TTL", "1h")
+	defer os.Unsetenv("FED_ENDPOINT")
+	defer os.Unsetenv("FED_CACHE_TTL")
+
+	cfg = Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.FED.Endpoint != "http://fed:8080" || cfg.FED.CacheTTL != time.Hour {
+		t.Errorf("unexpected config: %#v", cfg.FED)
+	}
+}
+
+func TestConfig__Email(t *testing.T) {
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Email.From != "paygate@localhost" || cfg.Email.Verification.Expiration != 72*time.Hour {
+		t.Errorf("unexpected defaults: %#v", cfg.Email)
+	}
+
+	os.Setenv("SMTP_ADDRESS", "smtp.example.com:587")
+	os.Setenv("EMAIL_VERIFICATION_EXPIRATION", "1h")
+	os.Setenv("EMAIL_LOG_ONLY", "yes")
+	defer os.Unsetenv("SMTP_ADDRESS")
+	defer os.Unsetenv("EMAIL_VERIFICATION_EXPIRATION")
+	defer os.Unsetenv("EMAIL_LOG_ONLY")
+
+	cfg = Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Email.SMTP.Address != "smtp.example.com:587" || cfg.Email.Verification.Expiration != time.Hour || !cfg.Email.LogOnly {
+		t.Errorf("unexpected config: %#v", cfg.Email)
+	}
+}
+
+func TestConfig__Verification(t *testing.T) {
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Verification.Default != "micro-deposits" || cfg.Verification.PrenoteBankingDays != 3 {
+		t.Errorf("unexpected defaults: %#v", cfg.Verification)
+	}
+
+	os.Setenv("VERIFICATION_HOLDER_TYPES", "business=prenote, individual=instant")
+	defer os.Unsetenv("VERIFICATION_HOLDER_TYPES")
+
+	cfg = Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if v := cfg.Verification.HolderTypes["business"]; v != "prenote" {
+		t.Errorf("business=%q", v)
+	}
+	if v := cfg.Verification.HolderTypes["individual"]; v != "instant" {
+		t.Errorf("individual=%q", v)
+	}
+
+	// unknown strategies are rejected
+	os.Setenv("VERIFICATION_HOLDER_TYPES", "business=carrier-pigeon")
+	if err := OverrideWithEnvVars(Empty()); err == nil {
+		t.Error("expected error")
+	}
+	os.Setenv("VERIFICATION_HOLDER_TYPES", "business")
+	if err := OverrideWithEnvVars(Empty()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestConfig__Defaults(t *testing.T) {
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Type != "sqlite" || cfg.Database.SQLite.Path != "paygate.db" || cfg.Database.MySQL.MaxConnections != 16 {
+		t.Errorf("unexpected defaults: %#v", cfg.Database)
+	}
+	if cfg.Secrets.Provider != "local" || cfg.Secrets.Vault.ServerURL != "http://127.0.0.1:8200" {
+		t.Errorf("unexpected defaults: %#v", cfg.Secrets)
+	}
+	ft := cfg.FileTransfer
+	if ft.Disabled || ft.Interval != 10*time.Minute || ft.BatchSize != 100 || ft.MaxLines != 10000 || ft.ForcedCutoffUploadDelta != 5*time.Minute || ft.HealthCheckInterval != 5*time.Minute || ft.Readiness != "all" {
+		t.Errorf("unexpected defaults: %#v", ft)
+	}
+	if ft.FTP.DialTimeout != 10*time.Second || ft.SFTP.MaxConnectionsPerFile != 8 || ft.SFTP.MaxPacketSize != 20480 {
+		t.Errorf("unexpected defaults: %#v", ft)
+	}
+	if cfg.ODFI.RoutingNumber != "121042882" || cfg.ODFI.AccountType != "savings" || cfg.ODFI.Identification != "001" {
+		t.Errorf("unexpected defaults: %#v", cfg.ODFI)
+	}
+}
+
+func TestConfig__File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "paygate.yaml")
+	raw := `
+http:
+  bindAddress: ":9000"
+database:
+  type: postgres
+  postgres:
+    address: "db:5432"
+    database: paygate
+    password: secret
+fileTransfer:
+  interval: 1m
+  sftp:
+    maxPacketSize: 1024
+  configs:
+    - routingNumber: "121042882"
+odfi:
+  routingNumber: "987654320"
+`
+	if err := ioutil.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("POSTGRES_DATABASE", "override")
+	defer os.Unsetenv("POSTGRES_DATABASE")
+
+	logFormat := ""
+	cfg, err := LoadConfig(path, &logFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HTTP.BindAddress != ":9000" || cfg.Database.Type != "postgres" || cfg.Database.Postgres.Password != "secret" {
+		t.Errorf("unexpected config: %#v %#v", cfg.HTTP, cfg.Database)
+	}
+	if cfg.Database.Postgres.Database != "override" {
+		t.Errorf("POSTGRES_DATABASE wasn't used: %q", cfg.Database.Postgres.Database)
+	}
+	if cfg.FileTransfer.Interval != time.Minute || cfg.FileTransfer.SFTP.MaxPacketSize != 1024 || cfg.FileTransfer.MaxLines != 10000 {
+		t.Errorf("unexpected config: %#v", cfg.FileTransfer)
+	}
+	if cfg.ODFI.RoutingNumber != "987654320" {
+		t.Errorf("unexpected config: %#v", cfg.ODFI)
+	}
+}
+
+func TestConfig__Env(t *testing.T) {
+	env := map[string]string{
+		"HTTP_BIND_ADDRESS":           ":8000",
+		"HTTP_REQUEST_TIMEOUT":        "45s",
+		"SHUTDOWN_TIMEOUT":            "2m",
+		"AUTH_TRUSTED_PROXY":          "yes",
+		"AUTH_JWT_JWKS_FILE":          "jwks.json",
+		"AUTH_ADMIN_TOKENS":           "alice:operator:" + strings.Repeat("a1", 32) + ", bob:security:" + strings.Repeat("B2", 32),
+		"RATE_LIMIT_USER":             "10:20",
+		"RATE_LIMIT_ROUTES":           "POST  /transfers=0.5:5, GET /depositories/{depositoryId}=2:2",
+		"TRACING_EXPORTER":            "otlp",
+		"TRACING_SAMPLE_RATIO":        "0.25",
+		"TRACING_OTLP_ENDPOINT":       "https://collector:4318",
+		"TRACING_OTLP_HEADERS":        "x-api-key=abc, x-team = payments",
+		"DATABASE_TYPE":               "mysql",
+		"MYSQL_ADDRESS":               "tcp(db:3306)",
+		"MYSQL_DATABASE":              "paygate",
+		"MYSQL_TIMEOUT":               "5s",
+		"SECRETS_PREVIOUS_KEYS":       "=base64key://a, v1=base64key://b",
+		"ACCOUNTS_CALLS_DISABLED":     "yes",
+		"ACH_FILE_TRANSFER_INTERVAL":  "off",
+		"ACH_FILE_MAX_LINES":          "500",
+		"FTP_DIAL_WITH_DISABLED_ESPV": "true",
+		"ACH_FILE_TRANSFER_READINESS": "any",
+		"ODFI_ACCOUNT_TYPE":           "checking",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HTTP.BindAddress != ":8000" || cfg.HTTP.RequestTimeout != 45*time.Second || cfg.HTTP.ShutdownTimeout != 2*time.Minute || cfg.Database.Type != "mysql" || cfg.Database.MySQL.Timeout != 5*time.Second {
+		t.Errorf("unexpected config: %#v %#v", cfg.HTTP, cfg.Database)
+	}
+	if !cfg.Auth.TrustedProxy.Enabled || cfg.Auth.JWT.JWKSFile != "jwks.json" || cfg.Auth.JWT.UserClaim != "sub" || !cfg.Auth.Enabled() {
+		t.Errorf("unexpected config: %#v", cfg.Auth)
+	}
+	if tokens := cfg.Auth.Admin.Tokens; len(tokens) != 2 || tokens[0].Name != "alice" || tokens[1].Role != "security" || tokens[1].SHA256 != strings.Repeat("b2", 32) {
+		t.Errorf("unexpected admin tokens: %#v", tokens)
+	}
+	if cfg.RateLimit.User.Rate != 10 || cfg.RateLimit.User.Burst != 20 || cfg.RateLimit.Store != "memory" || !cfg.RateLimit.Enabled() {
+		t.Errorf("unexpected config: %#v", cfg.RateLimit)
+	}
+	if limit := cfg.RateLimit.Routes["POST /transfers"]; len(cfg.RateLimit.Routes) != 2 || limit.Rate != 0.5 || limit.Burst != 5 {
+		t.Errorf("unexpected routes: %#v", cfg.RateLimit.Routes)
+	}
+	if cfg.Tracing.Exporter != "otlp" || cfg.Tracing.ServiceName != "paygate" || cfg.Tracing.SampleRatio != 0.25 || cfg.Tracing.OTLP.Timeout != 10*time.Second {
+		t.Errorf("unexpected config: %#v", cfg.Tracing)
+	}
+	if headers := cfg.Tracing.OTLP.Headers; len(headers) != 2 || headers["x-api-key"] != "abc" || headers["x-team"] != "payments" {
+		t.Errorf("unexpected headers: %#v", headers)
+	}
+	if len(cfg.Secrets.PreviousKeys) != 2 || cfg.Secrets.PreviousKeys[""] != "base64key://a" || cfg.Secrets.PreviousKeys["v1"] != "base64key://b" {
+		t.Errorf("unexpected previous keys: %#v", cfg.Secrets.PreviousKeys)
+	}
+	if !cfg.Accounts.Disabled {
+		t.Error("expected Accounts calls to be disabled")
+	}
+	if !cfg.FileTransfer.Disabled || cfg.FileTransfer.MaxLines != 500 || !cfg.FileTransfer.FTP.DisabledEPSV || cfg.FileTransfer.Readiness != "any" {
+		t.Errorf("unexpected config: %#v", cfg.FileTransfer)
+	}
+	if cfg.ODFI.AccountType != "checking" {
+		t.Errorf("unexpected config: %#v", cfg.ODFI)
+	}
+}
+
+func TestConfig__Invalid(t *testing.T) {
+	cases := map[string]string{
+		"ACH_FILE_MAX_LINES":          "many",
+		"ACH_FILE_TRANSFER_READINESS": "some",
+		"SFTP_DIAL_TIMEOUT":           "10",
+		"DATABASE_TYPE":               "oracle",
+		"CLOUD_PROVIDER":              "aws",
+		"SECRETS_PREVIOUS_KEYS":       "v1",
+		"HTTPS_CERT_FILE":             "cert.pem",
+		"ODFI_ROUTING_NUMBER":         "12345",
+		"ODFI_ACCOUNT_TYPE":           "loan",
+		"AUTH_TRUSTED_PROXY":          "sometimes",
+		"AUTH_ADMIN_TOKENS":           "alice:operator",
+		"RATE_LIMIT_USER":             "10",
+		"RATE_LIMIT_ROUTES":           "/transfers=1:1",
+		"RATE_LIMIT_STORE":            "redis",
+		"TRACING_EXPORTER":            "zipkin",
+		"TRACING_SAMPLE_RATIO":        "2",
+		"TRACING_OTLP_HEADERS":        "x-api-key",
+		// client certificates need TLS
+		"AUTH_MTLS_CLIENT_CAFILE": "ca.pem",
+	}
+	for k, v := range cases {
+		os.Setenv(k, v)
+		err := OverrideWithEnvVars(Empty())
+		os.Unsetenv(k)
+
+		if err == nil || !strings.HasPrefix(err.Error(), "config: ") {
+			t.Errorf("%s=%s: unexpected error: %v", k, v, err)
+		}
+	}
+
+	// rate limits need a burst
+	for _, limit := range []string{"5:0", "-1:1", "fast:1"} {
+		os.Setenv("RATE_LIMIT_USER", limit)
+		if err := OverrideWithEnvVars(Empty()); err == nil || !strings.HasPrefix(err.Error(), "config: ") {
+			t.Errorf("%s: unexpected error: %v", limit, err)
+		}
+	}
+	os.Unsetenv("RATE_LIMIT_USER")
+
+	// the OTLP exporter needs a collector URL
+	os.Setenv("TRACING_EXPORTER", "otlp")
+	os.Setenv("TRACING_OTLP_ENDPOINT", "collector:4318")
+	if err := OverrideWithEnvVars(Empty()); err == nil || !strings.HasPrefix(err.Error(), "config: ") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	os.Unsetenv("TRACING_EXPORTER")
+	os.Unsetenv("TRACING_OTLP_ENDPOINT")
+
+	// admin tokens need a known role, a unique name and a SHA-256 hash
+	hash := strings.Repeat("ab", 32)
+	for _, tokens := range []string{"alice:admin:" + hash, "alice:viewer:abc", ":viewer:" + hash, "alice:viewer:" + hash + ",alice:security:" + hash} {
+		os.Setenv("AUTH_ADMIN_TOKENS", tokens)
+		if err := OverrideWithEnvVars(Empty()); err == nil || !strings.HasPrefix(err.Error(), "config: ") {
+			t.Errorf("%s: unexpected error: %v", tokens, err)
+		}
+	}
+	os.Unsetenv("AUTH_ADMIN_TOKENS")
+
+	// mysql needs to know where to connect
+	os.Setenv("DATABASE_TYPE", "mysql")
+	defer os.Unsetenv("DATABASE_TYPE")
+	if err := OverrideWithEnvVars(Empty()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestConfig__Redacted(t *testing.T) {
+	cfg := Empty()
+	if err := OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Database.MySQL.Password = "mysql-password"
+	cfg.Secrets.Local.Base64Key = "base64key://secret"
+	cfg.Secrets.PreviousKeys = map[string]string{"v1": "base64key://old"}
+	cfg.Email.SMTP.Password = "smtp-password"
+	cfg.Auth.Admin.Tokens = []AdminToken{{Name: "alice", Role: "viewer", SHA256: strings.Repeat("ab", 32)}}
+	cfg.Tracing.OTLP.Headers = map[string]string{"x-api-key": "abc"}
+	cfg.ODFI.AccountNumber = "123456789"
+
+	out := cfg.Redacted()
+	if out.Database.MySQL.Password != "REDACTED" || out.Secrets.Local.Base64Key != "REDACTED" || out.Secrets.PreviousKeys["v1"] != "REDACTED" {
+		t.Errorf("unexpected config: %#v %#v", out.Database, out.Secrets)
+	}
+	if out.Email.SMTP.Password != "REDACTED" || out.Email.Verification.Secret != "" {
+		t.Errorf("unexpected config: %#v", out.Email)
+	}
+	if tokens := out.Auth.Admin.Tokens; len(tokens) != 1 || tokens[0].Name != "alice" || tokens[0].SHA256 != "REDACTED" {
+		t.Errorf("unexpected admin tokens: %#v", tokens)
+	}
+
+	if out.Tracing.OTLP.Headers["x-api-key"] != "REDACTED" {
+		t.Errorf("unexpected headers: %#v", out.Tracing.OTLP.Headers)
+	}
+	if out.ODFI.AccountNumber != "REDACTED" {
+		t.Errorf("unexpected ODFI: %#v", out.ODFI)
+	}
+
+	// the original config is unchanged
+	if cfg.Tracing.OTLP.Headers["x-api-key"] != "abc" || cfg.Database.MySQL.Password != "mysql-password" || cfg.Secrets.PreviousKeys["v1"] != "base64key://old" || cfg.Email.SMTP.Password != "smtp-password" || cfg.Auth.Admin.Tokens[0].SHA256 == "REDACTED" || cfg.ODFI.AccountNumber != "123456789" {
+		t.Error("original config was modified")
+	}
+}
+
 func TestConfig__override(t *testing.T) {
 	type config struct {
 		Foo string
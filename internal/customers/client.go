@@ -21,15 +21,15 @@ import (
 )
 
 type Client interface {
-	Ping() error
+	Ping(ctx context.Context) error
 
-	Create(opts *Request) (*moovcustomers.Customer, error)
-	Lookup(customerID string, requestID string, userID id.User) (*moovcustomers.Customer, error)
+	Create(ctx context.Context, opts *Request) (*moovcustomers.Customer, error)
+	Lookup(ctx context.Context, customerID string, requestID string, userID id.User) (*moovcustomers.Customer, error)
 
-	GetDisclaimers(customerID, requestID string, userID id.User) ([]moovcustomers.Disclaimer, error)
+	GetDisclaimers(ctx context.Context, customerID, requestID string, userID id.User) ([]moovcustomers.Disclaimer, error)
 
-	LatestOFACSearch(customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error)
-	RefreshOFACSearch(customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error)
+	LatestOFACSearch(ctx context.Context, customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error)
+	RefreshOFACSearch(ctx context.Context, customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error)
 }
 
 type moovClient struct {
@@ -37,11 +37,7 @@ type moovClient struct {
 	logger     log.Logger
 }
 
-func (c *moovClient) Ping() error {
-	// create a context just for this so ping requests don't require the setup of one
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) Ping(ctx context.Context) error {
 	resp, err := c.underlying.CustomersApi.Ping(ctx)
 	if resp != nil && resp.Body != nil {
 		resp.Body.Close()
@@ -76,10 +72,7 @@ func breakupName(in string) (string, string) {
 	return parts[0], parts[len(parts)-1]
 }
 
-func (c *moovClient) Create(opts *Request) (*moovcustomers.Customer, error) {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) Create(ctx context.Context, opts *Request) (*moovcustomers.Customer, error) {
 	first, last := breakupName(opts.Name)
 	req := moovcustomers.CreateCustomer{
 		FirstName: first,
@@ -107,10 +100,7 @@ func (c *moovClient) Create(opts *Request) (*moovcustomers.Customer, error) {
 	return &cust, nil
 }
 
-func (c *moovClient) Lookup(customerID string, requestID string, userID id.User) (*moovcustomers.Customer, error) {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) Lookup(ctx context.Context, customerID string, requestID string, userID id.User) (*moovcustomers.Customer, error) {
 	cust, resp, err := c.underlying.CustomersApi.GetCustomer(ctx, customerID, &moovcustomers.GetCustomerOpts{
 		XRequestID: optional.NewString(requestID),
 		XUserID:    optional.NewString(userID.String()),
@@ -127,10 +117,7 @@ func (c *moovClient) Lookup(customerID string, requestID string, userID id.User)
 	return &cust, nil
 }
 
-func (c *moovClient) GetDisclaimers(customerID, requestID string, userID id.User) ([]moovcustomers.Disclaimer, error) {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) GetDisclaimers(ctx context.Context, customerID, requestID string, userID id.User) ([]moovcustomers.Disclaimer, error) {
 	disclaimers, resp, err := c.underlying.CustomersApi.GetCustomerDisclaimers(ctx, customerID, &moovcustomers.GetCustomerDisclaimersOpts{
 		XRequestID: optional.NewString(requestID),
 		XUserID:    optional.NewString(userID.String()),
@@ -147,10 +134,7 @@ func (c *moovClient) GetDisclaimers(customerID, requestID string, userID id.User
 	return disclaimers, nil
 }
 
-func (c *moovClient) LatestOFACSearch(customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) LatestOFACSearch(ctx context.Context, customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
 	result, resp, err := c.underlying.CustomersApi.GetLatestOFACSearch(ctx, customerID, &moovcustomers.GetLatestOFACSearchOpts{
 		XRequestID: optional.NewString(requestID),
 		XUserID:    optional.NewString(userID.String()),
@@ -167,10 +151,7 @@ func (c *moovClient) LatestOFACSearch(customerID, requestID string, userID id.Us
 	return &result, nil
 }
 
-func (c *moovClient) RefreshOFACSearch(customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) RefreshOFACSearch(ctx context.Context, customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
 	result, resp, err := c.underlying.CustomersApi.RefreshOFACSearch(ctx, customerID, &moovcustomers.RefreshOFACSearchOpts{
 		XRequestID: optional.NewString(requestID),
 		XUserID:    optional.NewString(userID.String()),
@@ -214,8 +195,8 @@ func NewClient(logger log.Logger, endpoint string, httpClient *http.Client) Clie
 
 // HasAcceptedAllDisclaimers will return an error if there's a disclaimer which has not been accepted
 // for the given customerID. If no disclaimers exist or all have been accepted a nil error will be returned.
-func HasAcceptedAllDisclaimers(client Client, customerID string, requestID string, userID id.User) error {
-	ds, err := client.GetDisclaimers(customerID, requestID, userID)
+func HasAcceptedAllDisclaimers(ctx context.Context, client Client, customerID string, requestID string, userID id.User) error {
+	ds, err := client.GetDisclaimers(ctx, customerID, requestID, userID)
 	if err != nil {
 		return err
 	}
@@ -90,7 +90,7 @@ func spawnCustomers(t *testing.T) *customersDeployment {
 	addr := fmt.Sprintf("http://localhost:%s", customersContainer.GetPort("8080/tcp"))
 	client := NewClient(log.NewNopLogger(), addr, nil)
 	err = pool.Retry(func() error {
-		return client.Ping()
+		return client.Ping(context.Background())
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -110,7 +110,7 @@ func TestCustomers__client(t *testing.T) {
 
 	// Spawn an Customers Docker image and ping against it
 	deployment := spawnCustomers(t)
-	if err := deployment.client.Ping(); err != nil {
+	if err := deployment.client.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 	deployment.close(t) // close only if successful
@@ -119,11 +119,11 @@ func TestCustomers__client(t *testing.T) {
 func TestCustomers(t *testing.T) {
 	deployment := spawnCustomers(t)
 
-	if err := deployment.client.Ping(); err != nil {
+	if err := deployment.client.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
-	cust, err := deployment.client.Create(&Request{
+	cust, err := deployment.client.Create(context.Background(), &Request{
 		Name:  "John Smith",
 		Email: "john.smith@moov.io",
 		SSN:   "12314567",
@@ -135,7 +135,7 @@ func TestCustomers(t *testing.T) {
 		t.Fatal("nil Customer")
 	}
 
-	cust, err = deployment.client.Lookup(cust.ID, base.ID(), id.User(base.ID()))
+	cust, err = deployment.client.Lookup(context.Background(), cust.ID, base.ID(), id.User(base.ID()))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -149,7 +149,7 @@ func TestCustomers(t *testing.T) {
 func TestCustomers__disclaimers(t *testing.T) {
 	deployment := spawnCustomers(t)
 
-	if err := deployment.client.Ping(); err != nil {
+	if err := deployment.client.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
@@ -166,7 +166,7 @@ func TestCustomers__disclaimers(t *testing.T) {
 		t.Errorf("bogus HTTP status: %s", resp.Status)
 	}
 
-	disclaimers, err := deployment.client.GetDisclaimers(customerID, base.ID(), id.User(base.ID()))
+	disclaimers, err := deployment.client.GetDisclaimers(context.Background(), customerID, base.ID(), id.User(base.ID()))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -174,7 +174,7 @@ func TestCustomers__disclaimers(t *testing.T) {
 		t.Errorf("got %d disclaimers: %#v", n, disclaimers)
 	}
 
-	if err := HasAcceptedAllDisclaimers(deployment.client, customerID, base.ID(), id.User(base.ID())); err == nil {
+	if err := HasAcceptedAllDisclaimers(context.Background(), deployment.client, customerID, base.ID(), id.User(base.ID())); err == nil {
 		t.Error("expected error")
 	} else {
 		if !strings.Contains(err.Error(), fmt.Sprintf("disclaimer=%s is not accepted", disclaimers[0].ID)) {
@@ -193,7 +193,7 @@ func TestCustomers__disclaimers(t *testing.T) {
 		}
 		resp.Body.Close()
 
-		if err := HasAcceptedAllDisclaimers(deployment.client, customerID, base.ID(), id.User(base.ID())); err != nil {
+		if err := HasAcceptedAllDisclaimers(context.Background(), deployment.client, customerID, base.ID(), id.User(base.ID())); err != nil {
 			t.Error(err)
 		}
 	} else {
@@ -214,17 +214,17 @@ func TestCustomers__hasAcceptedAllDisclaimers(t *testing.T) {
 	}
 	customerID := base.ID()
 
-	if err := HasAcceptedAllDisclaimers(client, customerID, base.ID(), id.User(base.ID())); err == nil {
+	if err := HasAcceptedAllDisclaimers(context.Background(), client, customerID, base.ID(), id.User(base.ID())); err == nil {
 		t.Error("expected error (unaccepted disclaimer)")
 	}
 
 	client.Disclaimers[0].AcceptedAt = time.Now()
-	if err := HasAcceptedAllDisclaimers(client, customerID, base.ID(), id.User(base.ID())); err != nil {
+	if err := HasAcceptedAllDisclaimers(context.Background(), client, customerID, base.ID(), id.User(base.ID())); err != nil {
 		t.Errorf("expected no error: %v", err)
 	}
 
 	client.Err = errors.New("bad error")
-	if err := HasAcceptedAllDisclaimers(client, customerID, base.ID(), id.User(base.ID())); err == nil {
+	if err := HasAcceptedAllDisclaimers(context.Background(), client, customerID, base.ID(), id.User(base.ID())); err == nil {
 		t.Error("expeced error")
 	}
 }
@@ -232,11 +232,11 @@ func TestCustomers__hasAcceptedAllDisclaimers(t *testing.T) {
 func TestCustomers__OFACSearch(t *testing.T) {
 	deployment := spawnCustomers(t)
 
-	if err := deployment.client.Ping(); err != nil {
+	if err := deployment.client.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
-	cust, err := deployment.client.Create(&Request{
+	cust, err := deployment.client.Create(context.Background(), &Request{
 		Name:  "John Smith",
 		Email: "john.smith@moov.io",
 		SSN:   "12314567",
@@ -245,12 +245,12 @@ func TestCustomers__OFACSearch(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err = deployment.client.LatestOFACSearch(cust.ID, "requestID", "userID")
+	_, err = deployment.client.LatestOFACSearch(context.Background(), cust.ID, "requestID", "userID")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	result, err := deployment.client.RefreshOFACSearch(cust.ID, "requestID", "userID")
+	result, err := deployment.client.RefreshOFACSearch(context.Background(), cust.ID, "requestID", "userID")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -5,6 +5,7 @@
 package customers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -58,27 +59,27 @@ func (cur *Cursor) Close() error {
 	return nil
 }
 
-func (cur *Cursor) Next() ([]Cust, error) {
-	origCustomers, err := cur.grabOriginatorBatch()
+func (cur *Cursor) Next(ctx context.Context) ([]Cust, error) {
+	origCustomers, err := cur.grabOriginatorBatch(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("originators: %v", err)
 	}
-	recCustomers, err := cur.grabReceiverBatch()
+	recCustomers, err := cur.grabReceiverBatch(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("receivers: %v", err)
 	}
 	return append(origCustomers, recCustomers...), nil
 }
 
-func (cur *Cursor) grabOriginatorBatch() ([]Cust, error) {
+func (cur *Cursor) grabOriginatorBatch(ctx context.Context) ([]Cust, error) {
 	query := `select originator_id, customer_id, created_at from originators where created_at > ? order by created_at asc`
-	stmt, err := cur.db.Prepare(query)
+	stmt, err := cur.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(cur.originatorNewerThan)
+	rows, err := stmt.QueryContext(ctx, cur.originatorNewerThan)
 	if err != nil {
 		return nil, err
 	}
@@ -100,15 +101,15 @@ func (cur *Cursor) grabOriginatorBatch() ([]Cust, error) {
 	return out, rows.Err()
 }
 
-func (cur *Cursor) grabReceiverBatch() ([]Cust, error) {
+func (cur *Cursor) grabReceiverBatch(ctx context.Context) ([]Cust, error) {
 	query := `select receiver_id, customer_id, created_at from receivers where created_at > ? order by created_at asc`
-	stmt, err := cur.db.Prepare(query)
+	stmt, err := cur.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(cur.receiverNewerThan)
+	rows, err := stmt.QueryContext(ctx, cur.receiverNewerThan)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@
 package customers
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -22,7 +23,7 @@ func TestCursor(t *testing.T) {
 	cur := NewCursor(log.NewNopLogger(), db.DB, 2)
 	defer cur.Close()
 
-	customers, err := cur.Next()
+	customers, err := cur.Next(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -41,7 +42,7 @@ func TestCursor(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	customers, err = cur.Next()
+	customers, err = cur.Next(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -59,7 +60,7 @@ func TestCursor(t *testing.T) {
 	}
 
 	// call again and get nothing
-	customers, err = cur.Next()
+	customers, err = cur.Next(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -5,6 +5,8 @@
 package customers
 
 import (
+	"context"
+
 	moovcustomers "github.com/moov-io/customers/client"
 	"github.com/moov-io/paygate/pkg/id"
 )
@@ -17,39 +19,39 @@ type TestClient struct {
 	Err error
 }
 
-func (c *TestClient) Ping() error {
+func (c *TestClient) Ping(ctx context.Context) error {
 	return c.Err
 }
 
-func (c *TestClient) Create(opts *Request) (*moovcustomers.Customer, error) {
+func (c *TestClient) Create(ctx context.Context, opts *Request) (*moovcustomers.Customer, error) {
 	if c.Err != nil {
 		return nil, c.Err
 	}
 	return c.Customer, nil
 }
 
-func (c *TestClient) Lookup(customerID string, requestID string, userID id.User) (*moovcustomers.Customer, error) {
+func (c *TestClient) Lookup(ctx context.Context, customerID string, requestID string, userID id.User) (*moovcustomers.Customer, error) {
 	if c.Err != nil {
 		return nil, c.Err
 	}
 	return c.Customer, nil
 }
 
-func (c *TestClient) GetDisclaimers(customerID, requestID string, userID id.User) ([]moovcustomers.Disclaimer, error) {
+func (c *TestClient) GetDisclaimers(ctx context.Context, customerID, requestID string, userID id.User) ([]moovcustomers.Disclaimer, error) {
 	if c.Err != nil {
 		return nil, c.Err
 	}
 	return c.Disclaimers, nil
 }
 
-func (c *TestClient) LatestOFACSearch(customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
+func (c *TestClient) LatestOFACSearch(ctx context.Context, customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
 	if c.Err != nil {
 		return nil, c.Err
 	}
 	return c.Result, nil
 }
 
-func (c *TestClient) RefreshOFACSearch(customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
+func (c *TestClient) RefreshOFACSearch(ctx context.Context, customerID, requestID string, userID id.User) (*moovcustomers.OfacSearch, error) {
 	if c.Err != nil {
 		return nil, c.Err
 	}
@@ -8,22 +8,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/moov-io/paygate/internal/config"
+
 	"github.com/go-kit/kit/log"
 	"github.com/lopezator/migrator"
 )
 
-func New(ctx context.Context, logger log.Logger, _type string) (*sql.DB, error) {
-	logger.Log("database", fmt.Sprintf("looking for %s database provider", _type))
-	switch strings.ToLower(_type) {
+func New(ctx context.Context, logger log.Logger, cfg *config.DatabaseConfig) (*sql.DB, error) {
+	logger.Log("database", fmt.Sprintf("looking for %s database provider", cfg.Type))
+	switch strings.ToLower(cfg.Type) {
 	case "sqlite", "":
-		return sqliteConnection(logger, getSqlitePath()).Connect(ctx)
+		return sqliteConnection(logger, getSqlitePath(cfg.SQLite.Path)).Connect(ctx)
 	case "mysql":
-		return mysqlConnection(logger, os.Getenv("MYSQL_USER"), os.Getenv("MYSQL_PASSWORD"), os.Getenv("MYSQL_ADDRESS"), os.Getenv("MYSQL_DATABASE")).Connect(ctx)
+		return mysqlConnection(logger, &cfg.MySQL).Connect(ctx)
+	case "postgres", "postgresql":
+		return postgresConnection(logger, &cfg.Postgres).Connect(ctx)
 	}
-	return nil, fmt.Errorf("unknown database type %q", _type)
+	return nil, fmt.Errorf("unknown database type %q", cfg.Type)
 }
 
 func execsql(name, raw string) *migrator.MigrationNoTx {
@@ -36,8 +39,23 @@ func execsql(name, raw string) *migrator.MigrationNoTx {
 	}
 }
 
+// execsqlTx runs each statement in one transaction, for migrations which can't be left half applied.
+func execsqlTx(name string, raws ...string) *migrator.Migration {
+	return &migrator.Migration{
+		Name: name,
+		Func: func(tx *sql.Tx) error {
+			for i := range raws {
+				if _, err := tx.Exec(raws[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
 // UniqueViolation returns true when the provided error matches a database error
 // for duplicate entries (violating a unique table constraint).
 func UniqueViolation(err error) bool {
-	return MySQLUniqueViolation(err) || SqliteUniqueViolation(err)
+	return MySQLUniqueViolation(err) || PostgresUniqueViolation(err) || SqliteUniqueViolation(err)
 }
@@ -19,4 +19,9 @@ func TestUniqueViolation(t *testing.T) {
 	if !UniqueViolation(err) {
 		t.Error("should have matched unique violation")
 	}
+
+	err = errors.New(`problem upserting depository="7d676c65eccd48090ff238a0d5e35eb6126c23f2", userId="80cfe1311d9eb7659d02cba9ee6cb04ed3739a85": pq: duplicate key value violates unique constraint "depositories_pkey"`)
+	if !UniqueViolation(err) {
+		t.Error("should have matched unique violation")
+	}
 }
@@ -8,13 +8,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/moov-io/base/docker"
+	"github.com/moov-io/paygate/internal/config"
 
 	"github.com/go-kit/kit/log"
 	kitprom "github.com/go-kit/kit/metrics/prometheus"
@@ -34,15 +33,6 @@ var (
 	// https://dev.mysql.com/doc/refman/8.0/en/server-error-reference.html#error_er_dup_entry
 	mySQLErrDuplicateKey uint16 = 1062
 
-	maxActiveMySQLConnections = func() int {
-		if v := os.Getenv("MYSQL_MAX_CONNECTIONS"); v != "" {
-			if n, _ := strconv.ParseInt(v, 10, 32); n > 0 {
-				return int(n)
-			}
-		}
-		return 16
-	}()
-
 	mysqlMigrations = migrator.Migrations(
 		execsql(
 			"create_depositories",
@@ -148,6 +138,126 @@ var (
 			"create_event_metadata",
 			"create table event_metadata(event_id varchar(40), user_id varchar(40), `key` varchar(128), value varchar(256));",
 		),
+		execsql(
+			"create_transfer_addenda",
+			"create table transfer_addenda(transfer_id varchar(40), sequence_number integer, payment_related_information varchar(80), created_at datetime, primary key (transfer_id, sequence_number));",
+		),
+		execsql(
+			"add_payment_related_information_to_transfers",
+			"alter table transfers add column payment_related_information varchar(80) default '';",
+		),
+		execsql(
+			"add_identification_number_to_transfers",
+			"alter table transfers add column identification_number varchar(15) default '';",
+		),
+		execsql(
+			"add_discretionary_data_to_transfers",
+			"alter table transfers add column discretionary_data varchar(2) default '';",
+		),
+		execsql(
+			"create_trace_numbers",
+			"create table trace_numbers(odfi_identification varchar(8) primary key, sequence bigint not null, updated_at datetime);",
+		),
+		execsql(
+			"add_escalated_at_to_depositories",
+			"alter table depositories add column escalated_at datetime;",
+		),
+		execsql(
+			"create_depository_prenotes",
+			`create table if not exists depository_prenotes(depository_id varchar(40), user_id varchar(40), file_id varchar(100), trace_number varchar(15), return_code varchar(10) default '', merged_filename varchar(100), merged_at datetime, created_at datetime, deleted_at datetime);`,
+		),
+		execsql(
+			"add_identification_encrypted_to_originators",
+			"alter table originators add column identification_encrypted varchar(512) default '';",
+		),
+		execsql(
+			"add_identification_hashed_to_originators",
+			"alter table originators add column identification_hashed varchar(64) default '';",
+		),
+		execsql(
+			"add_email_encrypted_to_receivers",
+			"alter table receivers add column email_encrypted varchar(512) default '';",
+		),
+		execsql(
+			"add_email_hashed_to_receivers",
+			"alter table receivers add column email_hashed varchar(64) default '';",
+		),
+		execsql(
+			"create_receiver_depositories",
+			"create table receiver_depositories(receiver_id varchar(40), depository_id varchar(40), created_at datetime, primary key (receiver_id, depository_id));",
+		),
+		execsql(
+			"link_receiver_default_depositories",
+			"insert into receiver_depositories (receiver_id, depository_id, created_at) select receiver_id, default_depository, created_at from receivers where default_depository <> '' and deleted_at is null;",
+		),
+		execsql(
+			"create_authorizations",
+			"create table authorizations(authorization_id varchar(40) primary key, user_id varchar(40), receiver_id varchar(40), originator_id varchar(40), type varchar(10), reference varchar(255), scope varchar(10), amount_cap varchar(30), expires_at datetime, revoked_at datetime, revocation_reason varchar(255), created_at datetime, last_updated_at datetime, deleted_at datetime);",
+		),
+		execsql(
+			"add_authorization_id_to_transfers",
+			"alter table transfers add column authorization_id varchar(40);",
+		),
+		execsql(
+			"create_api_keys",
+			"create table api_keys(key_id varchar(40) primary key, user_id varchar(40), key_hash varchar(64), scopes varchar(512), created_at datetime, deleted_at datetime);",
+		),
+		execsql(
+			"create_api_keys_hash_idx",
+			"create unique index api_keys_hash_idx on api_keys(key_hash);",
+		),
+		execsql(
+			"create_audit_log",
+			"create table audit_log(entry_id varchar(40) primary key, created_at datetime, admin_name varchar(100), admin_role varchar(20), method varchar(10), path varchar(512), route varchar(255), request_id varchar(100), remote_addr varchar(100), status integer, request mediumtext, before_snapshot mediumtext, after_snapshot mediumtext);",
+		),
+		execsql(
+			"create_audit_log_created_at_idx",
+			"create index audit_log_created_at_idx on audit_log(created_at);",
+		),
+		execsql(
+			"create_rate_limit_buckets",
+			"create table rate_limit_buckets(bucket_key varchar(255) primary key, tokens double, updated_at bigint);",
+		),
+		execsql(
+			"create_plaintext_account_numbers",
+			"create table plaintext_account_numbers(depository_id varchar(40) primary key, account_number varchar(15));",
+		),
+		execsql(
+			"move_plaintext_account_numbers",
+			"insert into plaintext_account_numbers (depository_id, account_number) select depository_id, account_number from depositories where account_number <> '';",
+		),
+		execsql(
+			"drop_account_number_from_depositories",
+			"alter table depositories drop column account_number;",
+		),
+		execsql(
+			"add_withdraw_transaction_id_to_micro_deposits",
+			"alter table micro_deposits add column withdraw_transaction_id varchar(40) default '';",
+		),
+		execsql(
+			"drop_identification_hashed_from_originators",
+			"alter table originators drop column identification_hashed;",
+		),
+		execsql(
+			"add_consumed_at_to_authorizations",
+			"alter table authorizations add column consumed_at datetime;",
+		),
+		execsql(
+			"consume_used_single_authorizations",
+			"update authorizations set consumed_at = last_updated_at where scope = 'single' and authorization_id in (select authorization_id from transfers where status not in ('canceled', 'failed') and deleted_at is null);",
+		),
+		execsql(
+			"add_micro_deposit_id_to_micro_deposits",
+			"alter table micro_deposits add column micro_deposit_id varchar(40);",
+		),
+		execsql(
+			"set_micro_deposit_ids",
+			"update micro_deposits set micro_deposit_id = replace(uuid(), '-', '') where micro_deposit_id is null;",
+		),
+		execsql(
+			"create_transfer_check_details",
+			"create table transfer_check_details(transfer_id varchar(40) primary key, check_serial_number varchar(15), terminal_city varchar(4), terminal_state varchar(2), created_at datetime);",
+		),
 	)
 )
 
@@ -160,8 +270,9 @@ func init() {
 }
 
 type mysql struct {
-	dsn    string
-	logger log.Logger
+	dsn            string
+	maxConnections int
+	logger         log.Logger
 
 	connections *kitprom.Gauge
 }
@@ -171,7 +282,7 @@ func (my *mysql) Connect(ctx context.Context) (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	db.SetMaxOpenConns(maxActiveMySQLConnections)
+	db.SetMaxOpenConns(my.maxConnections)
 
 	// Check out DB is up and working
 	if err := db.Ping(); err != nil {
@@ -206,17 +317,14 @@ func (my *mysql) Connect(ctx context.Context) (*sql.DB, error) {
 	return db, nil
 }
 
-func mysqlConnection(logger log.Logger, user, pass string, address string, database string) *mysql {
-	timeout := "30s"
-	if v := os.Getenv("MYSQL_TIMEOUT"); v != "" {
-		timeout = v
-	}
-	params := fmt.Sprintf("timeout=%s&charset=utf8mb4&parseTime=true&sql_mode=ALLOW_INVALID_DATES", timeout)
-	dsn := fmt.Sprintf("%s:%s@%s/%s?%s", user, pass, address, database, params)
+func mysqlConnection(logger log.Logger, cfg *config.MySQLConfig) *mysql {
+	params := fmt.Sprintf("timeout=%v&charset=utf8mb4&parseTime=true&sql_mode=ALLOW_INVALID_DATES", cfg.Timeout)
+	dsn := fmt.Sprintf("%s:%s@%s/%s?%s", cfg.User, cfg.Password, cfg.Address, cfg.Database, params)
 	return &mysql{
-		dsn:         dsn,
-		logger:      logger,
-		connections: mysqlConnections,
+		dsn:            dsn,
+		maxConnections: cfg.MaxConnections,
+		logger:         logger,
+		connections:    mysqlConnections,
 	}
 }
 
@@ -290,7 +398,14 @@ func CreateTestMySQLDB(t *testing.T) *TestMySQLDB {
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
 
-	db, err := mysqlConnection(logger, "moov", "secret", address, "paygate").Connect(ctx)
+	db, err := mysqlConnection(logger, &config.MySQLConfig{
+		Address:        address,
+		Database:       "paygate",
+		User:           "moov",
+		Password:       "secret",
+		MaxConnections: 16,
+		Timeout:        30 * time.Second,
+	}).Connect(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -8,6 +8,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
 
 	"github.com/go-kit/kit/log"
 )
@@ -21,7 +24,13 @@ func TestMySQL__basic(t *testing.T) {
 	}
 
 	// create a phony MySQL
-	m := mysqlConnection(log.NewNopLogger(), "user", "pass", "127.0.0.1:3006", "db")
+	m := mysqlConnection(log.NewNopLogger(), &config.MySQLConfig{
+		Address:  "tcp(127.0.0.1:3006)",
+		Database: "db",
+		User:     "user",
+		Password: "pass",
+		Timeout:  time.Second,
+	})
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
 
@@ -0,0 +1,485 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/base/docker"
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	kitprom "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/lib/pq"
+	"github.com/lopezator/migrator"
+	"github.com/ory/dockertest/v3"
+	stdprom "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	postgresConnections = kitprom.NewGaugeFrom(stdprom.GaugeOpts{
+		Name: "postgres_connections",
+		Help: "How many PostgreSQL connections and what status they're in.",
+	}, []string{"state"})
+
+	// postgresErrUniqueViolation is the SQLSTATE code for duplicate entries
+	// https://www.postgresql.org/docs/current/errcodes-appendix.html
+	postgresErrUniqueViolation pq.ErrorCode = "23505"
+
+	postgresMigrations = migrator.Migrations(
+		execsql(
+			"create_depositories",
+			`create table if not exists depositories(depository_id varchar(40) primary key, user_id varchar(40), bank_name varchar(50), holder varchar(50), holder_type varchar(20), type varchar(20), routing_number varchar(10), account_number varchar(15), status varchar(20), metadata varchar(100), created_at timestamptz, last_updated_at timestamptz, deleted_at timestamptz);`,
+		),
+		execsql(
+			"create_micro_deposits",
+			`create table if not exists micro_deposits(depository_id varchar(40), user_id varchar(40), amount varchar(10), file_id varchar(40), created_at timestamptz, deleted_at timestamptz);`,
+		),
+		execsql(
+			"create_events",
+			`create table if not exists events(event_id varchar(40) primary key, user_id varchar(40), topic varchar(100), message varchar(250), type varchar(20), created_at timestamptz);`,
+		),
+		execsql(
+			"create_gateways",
+			`create table if not exists gateways(gateway_id varchar(40) primary key, user_id varchar(40), origin varchar(10), origin_name varchar(50), destination varchar(10), destination_name varchar(50), created_at timestamptz, deleted_at timestamptz);`,
+		),
+		execsql(
+			"create_originators",
+			`create table if not exists originators(originator_id varchar(40) primary key, user_id varchar(40), default_depository varchar(40), identification varchar(50), metadata varchar(100), created_at timestamptz, last_updated_at timestamptz, deleted_at timestamptz);`,
+		),
+		execsql(
+			"create_receivers",
+			`create table if not exists receivers(receiver_id varchar(40) primary key, user_id varchar(40), email varchar(100), default_depository varchar(40), status varchar(10), metadata varchar(100), created_at timestamptz, last_updated_at timestamptz, deleted_at timestamptz);`,
+		),
+		execsql(
+			"create_transfers",
+			`create table if not exists transfers(transfer_id varchar(40), user_id varchar(40), type varchar(10), amount varchar(10), originator_id varchar(40), originator_depository varchar(40), receiver varchar(40), receiver_depository varchar(40), description varchar(200), standard_entry_class_code varchar(5), status varchar(10), same_day boolean, file_id varchar(40), transaction_id varchar(40), merged_filename varchar(100), return_code varchar(10), trace_number varchar(20), created_at timestamptz, last_updated_at timestamptz, deleted_at timestamptz);`,
+		),
+		execsql(
+			"create_cutoff_times",
+			`create table if not exists cutoff_times(routing_number varchar(10), cutoff varchar(10), location varchar(25));`,
+		),
+		execsql(
+			"create_file_transfer_configs",
+			`create table if not exists file_transfer_configs(routing_number varchar(10), inbound_path varchar(100), outbound_path varchar(100), return_path varchar(100));`,
+		),
+		execsql(
+			"create_ftp_configs",
+			`create table if not exists ftp_configs(routing_number varchar(10), hostname varchar(100), username varchar(25), password varchar(25));`,
+		),
+		execsql(
+			"create_sftp_configs",
+			`create table if not exists sftp_configs(routing_number varchar(10), hostname varchar(100), username varchar(25), password varchar(25), client_private_key varchar(2100), host_public_key varchar(2100));`,
+		),
+		execsql(
+			"add_merged_filename_to_micro_deposits",
+			"alter table micro_deposits add column merged_filename varchar(100);",
+		),
+		execsql(
+			"grow_micro_deposits_file_id",
+			"alter table micro_deposits alter column file_id type varchar(100);",
+		),
+		execsql(
+			"unique_cutoff_times",
+			`create unique index cutoff_times_idx on cutoff_times(routing_number);`,
+		),
+		execsql(
+			"unique_ftp_configs",
+			`create unique index ftp_configs_idx on ftp_configs(routing_number);`,
+		),
+		execsql(
+			"unique_sftp_configs",
+			`create unique index sftp_configs_idx on sftp_configs(routing_number);`,
+		),
+		execsql(
+			"add_return_code_to_micro_deposits",
+			"alter table micro_deposits add column return_code varchar(10) default '';",
+		),
+		execsql(
+			"add_transaction_id_to_micro_deposits",
+			"alter table micro_deposits add column transaction_id varchar(40) default '';",
+		),
+		execsql(
+			"file_transfer_configs",
+			"alter table file_transfer_configs add column outbound_filename_template varchar(512) default '';",
+		),
+		execsql(
+			"add_customer_id_to_originators",
+			"alter table originators add column customer_id varchar(40) default '';",
+		),
+		execsql(
+			"add_customer_id_to_receivers",
+			"alter table receivers add column customer_id varchar(40) default '';",
+		),
+		execsql(
+			"create_micro_deposit_attempts",
+			"create table micro_deposit_attempts(depository_id varchar(40), amounts varchar(20), attempted_at timestamptz);",
+		),
+		execsql(
+			"add_account_number_encrypted_to_depositories",
+			"alter table depositories add column account_number_encrypted varchar(512) default '';",
+		),
+		execsql(
+			"add_account_number_hashed_to_depositories",
+			"alter table depositories add column account_number_hashed varchar(64) default '';",
+		),
+		execsql(
+			"add_allowed_ips_to_file_transfer_configs",
+			"alter table file_transfer_configs add column allowed_ips varchar(160) default '';",
+		),
+		execsql(
+			"create_event_metadata",
+			"create table event_metadata(event_id varchar(40), user_id varchar(40), key varchar(128), value varchar(256));",
+		),
+		execsql(
+			"create_transfer_addenda",
+			"create table transfer_addenda(transfer_id varchar(40), sequence_number integer, payment_related_information varchar(80), created_at timestamptz, primary key (transfer_id, sequence_number));",
+		),
+		execsql(
+			"add_payment_related_information_to_transfers",
+			"alter table transfers add column payment_related_information varchar(80) default '';",
+		),
+		execsql(
+			"add_identification_number_to_transfers",
+			"alter table transfers add column identification_number varchar(15) default '';",
+		),
+		execsql(
+			"add_discretionary_data_to_transfers",
+			"alter table transfers add column discretionary_data varchar(2) default '';",
+		),
+		execsql(
+			"create_trace_numbers",
+			"create table trace_numbers(odfi_identification varchar(8) primary key, sequence bigint not null, updated_at timestamptz);",
+		),
+		execsql(
+			"add_escalated_at_to_depositories",
+			"alter table depositories add column escalated_at timestamptz;",
+		),
+		execsql(
+			"create_depository_prenotes",
+			`create table if not exists depository_prenotes(depository_id varchar(40), user_id varchar(40), file_id varchar(100), trace_number varchar(15), return_code varchar(10) default '', merged_filename varchar(100), merged_at timestamptz, created_at timestamptz, deleted_at timestamptz);`,
+		),
+		execsql(
+			"add_identification_encrypted_to_originators",
+			"alter table originators add column identification_encrypted varchar(512) default '';",
+		),
+		execsql(
+			"add_identification_hashed_to_originators",
+			"alter table originators add column identification_hashed varchar(64) default '';",
+		),
+		execsql(
+			"add_email_encrypted_to_receivers",
+			"alter table receivers add column email_encrypted varchar(512) default '';",
+		),
+		execsql(
+			"add_email_hashed_to_receivers",
+			"alter table receivers add column email_hashed varchar(64) default '';",
+		),
+		execsql(
+			"create_receiver_depositories",
+			"create table receiver_depositories(receiver_id varchar(40), depository_id varchar(40), created_at timestamptz, primary key (receiver_id, depository_id));",
+		),
+		execsql(
+			"link_receiver_default_depositories",
+			"insert into receiver_depositories (receiver_id, depository_id, created_at) select receiver_id, default_depository, created_at from receivers where default_depository <> '' and deleted_at is null;",
+		),
+		execsql(
+			"create_authorizations",
+			"create table authorizations(authorization_id varchar(40) primary key, user_id varchar(40), receiver_id varchar(40), originator_id varchar(40), type varchar(10), reference varchar(255), scope varchar(10), amount_cap varchar(30), expires_at timestamptz, revoked_at timestamptz, revocation_reason varchar(255), created_at timestamptz, last_updated_at timestamptz, deleted_at timestamptz);",
+		),
+		execsql(
+			"add_authorization_id_to_transfers",
+			"alter table transfers add column authorization_id varchar(40);",
+		),
+		execsql(
+			"create_api_keys",
+			"create table api_keys(key_id varchar(40) primary key, user_id varchar(40), key_hash varchar(64), scopes varchar(512), created_at timestamptz, deleted_at timestamptz);",
+		),
+		execsql(
+			"create_api_keys_hash_idx",
+			"create unique index api_keys_hash_idx on api_keys(key_hash);",
+		),
+		execsql(
+			"create_audit_log",
+			"create table audit_log(entry_id varchar(40) primary key, created_at timestamptz, admin_name varchar(100), admin_role varchar(20), method varchar(10), path varchar(512), route varchar(255), request_id varchar(100), remote_addr varchar(100), status integer, request text, before_snapshot text, after_snapshot text);",
+		),
+		execsql(
+			"create_audit_log_created_at_idx",
+			"create index audit_log_created_at_idx on audit_log(created_at);",
+		),
+		execsql(
+			"create_rate_limit_buckets",
+			"create table rate_limit_buckets(bucket_key varchar(255) primary key, tokens double precision, updated_at bigint);",
+		),
+		execsql(
+			"create_plaintext_account_numbers",
+			"create table plaintext_account_numbers(depository_id varchar(40) primary key, account_number varchar(15));",
+		),
+		execsql(
+			"move_plaintext_account_numbers",
+			"insert into plaintext_account_numbers (depository_id, account_number) select depository_id, account_number from depositories where account_number <> '';",
+		),
+		execsql(
+			"drop_account_number_from_depositories",
+			"alter table depositories drop column account_number;",
+		),
+		execsql(
+			"add_withdraw_transaction_id_to_micro_deposits",
+			"alter table micro_deposits add column withdraw_transaction_id varchar(40) default '';",
+		),
+		execsql(
+			"drop_identification_hashed_from_originators",
+			"alter table originators drop column identification_hashed;",
+		),
+		execsql(
+			"add_consumed_at_to_authorizations",
+			"alter table authorizations add column consumed_at timestamptz;",
+		),
+		execsql(
+			"consume_used_single_authorizations",
+			"update authorizations set consumed_at = last_updated_at where scope = 'single' and authorization_id in (select authorization_id from transfers where status not in ('canceled', 'failed') and deleted_at is null);",
+		),
+		execsql(
+			"add_micro_deposit_id_to_micro_deposits",
+			"alter table micro_deposits add column micro_deposit_id varchar(40);",
+		),
+		execsql(
+			"set_micro_deposit_ids",
+			"update micro_deposits set micro_deposit_id = md5(random()::text || clock_timestamp()::text) where micro_deposit_id is null;",
+		),
+		execsql(
+			"create_transfer_check_details",
+			"create table transfer_check_details(transfer_id varchar(40) primary key, check_serial_number varchar(15), terminal_city varchar(4), terminal_state varchar(2), created_at timestamptz);",
+		),
+	)
+)
+
+type postgres struct {
+	dsn            string
+	maxConnections int
+	logger         log.Logger
+
+	connections *kitprom.Gauge
+}
+
+func (pg *postgres) Connect(ctx context.Context) (*sql.DB, error) {
+	connector, err := pq.NewConnector(pg.dsn)
+	if err != nil {
+		return nil, err
+	}
+	// Queries are written with ? placeholders, so rewrite them for PostgreSQL.
+	db := sql.OpenDB(&postgresConnector{connector: connector})
+	db.SetMaxOpenConns(pg.maxConnections)
+
+	// Check out DB is up and working
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Migrate our database
+	if m, err := migrator.New(postgresMigrations); err != nil {
+		return nil, err
+	} else {
+		if err := m.Migrate(db); err != nil {
+			return nil, err
+		}
+	}
+
+	// Setup metrics after the database is setup
+	go func() {
+		t := time.NewTicker(1 * time.Minute)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				stats := db.Stats()
+				pg.connections.With("state", "idle").Set(float64(stats.Idle))
+				pg.connections.With("state", "inuse").Set(float64(stats.InUse))
+				pg.connections.With("state", "open").Set(float64(stats.OpenConnections))
+			}
+		}
+	}()
+
+	return db, nil
+}
+
+func postgresConnection(logger log.Logger, cfg *config.PostgresConfig) *postgres {
+	params := url.Values{}
+	params.Set("connect_timeout", fmt.Sprintf("%.0f", cfg.Timeout.Seconds()))
+	if cfg.SSLMode != "" {
+		params.Set("sslmode", cfg.SSLMode)
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     cfg.Address,
+		Path:     "/" + cfg.Database,
+		RawQuery: params.Encode(),
+	}
+	return &postgres{
+		dsn:            dsn.String(),
+		maxConnections: cfg.MaxConnections,
+		logger:         logger,
+		connections:    postgresConnections,
+	}
+}
+
+// TestPostgresDB is a wrapper around sql.DB for PostgreSQL connections designed for tests to provide
+// a clean database for each testcase.  Callers should cleanup with Close() when finished.
+type TestPostgresDB struct {
+	DB *sql.DB
+
+	container *dockertest.Resource
+
+	// drop removes the database created on an existing server, see POSTGRES_TEST_ADDRESS
+	drop func() error
+
+	shutdown func() // context shutdown func
+}
+
+func (r *TestPostgresDB) Close() error {
+	r.shutdown()
+
+	// Verify all connections are closed before closing DB
+	if conns := r.DB.Stats().OpenConnections; conns != 0 {
+		panic(fmt.Sprintf("found %d open PostgreSQL connections", conns))
+	}
+	if err := r.DB.Close(); err != nil {
+		return err
+	}
+
+	if r.container != nil {
+		return r.container.Close()
+	}
+	if r.drop != nil {
+		return r.drop()
+	}
+	return nil
+}
+
+// CreateTestPostgresDB returns a TestPostgresDB which can be used in tests
+// as a clean PostgreSQL database. All migrations are ran on the db before.
+//
+// A PostgreSQL container is started with Docker unless POSTGRES_TEST_ADDRESS points to an
+// existing server (such as a local or embedded PostgreSQL for offline runs), where a new
+// database is created for each test and dropped afterwards. POSTGRES_TEST_USER and
+// POSTGRES_TEST_PASSWORD are used to connect to that server.
+//
+// Callers should call close on the returned *TestPostgresDB.
+func CreateTestPostgresDB(t *testing.T) *TestPostgresDB {
+	if testing.Short() {
+		t.Skip("-short flag enabled")
+	}
+	if address := os.Getenv("POSTGRES_TEST_ADDRESS"); address != "" {
+		return createTestPostgresDatabase(t, address, os.Getenv("POSTGRES_TEST_USER"), os.Getenv("POSTGRES_TEST_PASSWORD"))
+	}
+	if !docker.Enabled() {
+		t.Skip("Docker not enabled")
+	}
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "12",
+		Env: []string{
+			"POSTGRES_USER=moov",
+			"POSTGRES_PASSWORD=secret",
+			"POSTGRES_DB=paygate",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := fmt.Sprintf("localhost:%s", resource.GetPort("5432/tcp"))
+	err = pool.Retry(func() error {
+		db, err := sql.Open("postgres", fmt.Sprintf("postgres://moov:secret@%s/paygate?sslmode=disable", address))
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return db.Ping()
+	})
+	if err != nil {
+		resource.Close()
+		t.Fatal(err)
+	}
+
+	db, shutdown := connectTestPostgresDB(t, address, "moov", "secret", "paygate")
+	return &TestPostgresDB{DB: db, container: resource, shutdown: shutdown}
+}
+
+func createTestPostgresDatabase(t *testing.T, address, user, pass string) *TestPostgresDB {
+	admin, err := sql.Open("postgres", (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, pass),
+		Host:     address,
+		Path:     "/postgres",
+		RawQuery: "sslmode=disable",
+	}).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "paygate_" + base.ID()[:16]
+	if _, err := admin.Exec("create database " + name); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+	drop := func() error {
+		defer admin.Close()
+		_, err := admin.Exec("drop database " + name)
+		return err
+	}
+
+	db, shutdown := connectTestPostgresDB(t, address, user, pass, name)
+	return &TestPostgresDB{DB: db, drop: drop, shutdown: shutdown}
+}
+
+func connectTestPostgresDB(t *testing.T, address, user, pass, database string) (*sql.DB, func()) {
+	t.Helper()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	db, err := postgresConnection(log.NewNopLogger(), &config.PostgresConfig{
+		Address:        address,
+		Database:       database,
+		User:           user,
+		Password:       pass,
+		SSLMode:        "disable",
+		MaxConnections: 16,
+		Timeout:        30 * time.Second,
+	}).Connect(ctx)
+	if err != nil {
+		cancelFunc()
+		t.Fatal(err)
+	}
+
+	// Don't allow idle connections so we can verify all are closed at the end of testing
+	db.SetMaxIdleConns(0)
+
+	return db, cancelFunc
+}
+
+// PostgresUniqueViolation returns true when the provided error matches the PostgreSQL code
+// for duplicate entries (violating a unique table constraint).
+func PostgresUniqueViolation(err error) bool {
+	match := strings.Contains(err.Error(), "duplicate key value violates unique constraint")
+	if e, ok := err.(*pq.Error); ok {
+		return match || e.Code == postgresErrUniqueViolation
+	}
+	return match
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// postgresConnector wraps a PostgreSQL driver so queries written for SQLite and MySQL run unchanged.
+//
+// Placeholders (?) are rewritten into PostgreSQL's $1, $2, ... and backtick quoted identifiers
+// into double quoted ones. Inserts inside of a transaction are run under a savepoint as PostgreSQL
+// aborts the entire transaction on any error, but our repositories insert and then update rows
+// which violate a unique constraint.
+type postgresConnector struct {
+	connector driver.Connector
+}
+
+func (c *postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresConn{conn: conn}, nil
+}
+
+func (c *postgresConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// postgresConn is only used by one goroutine at a time (per database/sql), so inTx needs no locking.
+type postgresConn struct {
+	conn driver.Conn
+	inTx bool
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = rebind(query)
+
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &postgresStmt{Stmt: stmt, conn: c, insert: isInsert(query)}, nil
+}
+
+func (c *postgresConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *postgresConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *postgresConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	var err error
+	if b, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = b.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.inTx = true
+	return &postgresTx{tx: tx, conn: c}, nil
+}
+
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	query = rebind(query)
+	return c.exec(ctx, isInsert(query), func() (driver.Result, error) {
+		return execer.ExecContext(ctx, query, args)
+	})
+}
+
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return queryer.QueryContext(ctx, rebind(query), args)
+}
+
+func (c *postgresConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// exec runs fn under a savepoint when it's an insert inside of a transaction. Errors from fn are
+// returned as-is so callers can check them with UniqueViolation.
+func (c *postgresConn) exec(ctx context.Context, insert bool, fn func() (driver.Result, error)) (driver.Result, error) {
+	if !insert || !c.inTx {
+		return fn()
+	}
+	if err := c.execRaw(ctx, "savepoint paygate_insert"); err != nil {
+		return nil, err
+	}
+	res, err := fn()
+	if err != nil {
+		if rollbackErr := c.execRaw(ctx, "rollback to savepoint paygate_insert"); rollbackErr != nil {
+			return nil, fmt.Errorf("%v (rollback to savepoint: %v)", err, rollbackErr)
+		}
+		return nil, err
+	}
+	return res, c.execRaw(ctx, "release savepoint paygate_insert")
+}
+
+func (c *postgresConn) execRaw(ctx context.Context, query string) error {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return fmt.Errorf("%T does not support ExecContext", c.conn)
+	}
+	_, err := execer.ExecContext(ctx, query, nil)
+	return err
+}
+
+type postgresStmt struct {
+	driver.Stmt
+
+	conn   *postgresConn
+	insert bool
+}
+
+func (s *postgresStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.exec(ctx, s.insert, func() (driver.Result, error) {
+		if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+			return e.ExecContext(ctx, args)
+		}
+		return s.Stmt.Exec(namedValues(args))
+	})
+}
+
+func (s *postgresStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return q.QueryContext(ctx, args)
+	}
+	return s.Stmt.Query(namedValues(args))
+}
+
+type postgresTx struct {
+	tx   driver.Tx
+	conn *postgresConn
+}
+
+func (tx *postgresTx) Commit() error {
+	tx.conn.inTx = false
+	return tx.tx.Commit()
+}
+
+func (tx *postgresTx) Rollback() error {
+	tx.conn.inTx = false
+	return tx.tx.Rollback()
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i := range args {
+		values[i] = args[i].Value
+	}
+	return values
+}
+
+// rebind rewrites ? placeholders into $1, $2, ... and backtick quoted identifiers into double
+// quoted identifiers. Anything inside of a quoted string is left alone.
+func rebind(query string) string {
+	var buf strings.Builder
+	buf.Grow(len(query))
+
+	n, inString := 0, false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inString = !inString
+			buf.WriteRune(r)
+		case inString:
+			buf.WriteRune(r)
+		case r == '?':
+			n++
+			buf.WriteString("$" + strconv.Itoa(n))
+		case r == '`':
+			buf.WriteRune('"')
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+func isInsert(query string) bool {
+	query = strings.TrimSpace(query)
+	return len(query) >= 6 && strings.EqualFold(query[:6], "insert")
+}
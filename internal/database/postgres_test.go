@@ -0,0 +1,246 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"github.com/lib/pq"
+)
+
+func TestPostgres__basic(t *testing.T) {
+	db := CreateTestPostgresDB(t)
+	defer db.Close()
+
+	if err := db.DB.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	// create a phony PostgreSQL
+	pg := postgresConnection(log.NewNopLogger(), &config.PostgresConfig{
+		Address:  "127.0.0.1:5433",
+		Database: "db",
+		User:     "user",
+		Password: "pass",
+		SSLMode:  "disable",
+		Timeout:  time.Second,
+	})
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	conn, err := pg.Connect(ctx)
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	if conn != nil || err == nil {
+		t.Fatalf("conn=%#v expected error", conn)
+	}
+}
+
+func TestPostgres__uniqueViolationInTx(t *testing.T) {
+	db := CreateTestPostgresDB(t)
+	defer db.Close()
+
+	if _, err := db.DB.Exec(`create table upserts(id varchar(10) primary key, value varchar(10));`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB.Exec(`insert into upserts (id, value) values (?, ?);`, "a", "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Like our repositories, insert and update the row when it already exists
+	tx, err := db.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`insert into upserts (id, value) values (?, ?);`, "a", "second"); !UniqueViolation(err) {
+		t.Fatalf("expected unique violation: %v", err)
+	}
+	if _, err := tx.Exec(`update upserts set value = ? where id = ?;`, "second", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var value string
+	if err := db.DB.QueryRow(`select value from upserts where id = ?;`, "a").Scan(&value); err != nil {
+		t.Fatal(err)
+	}
+	if value != "second" {
+		t.Errorf("value=%q", value)
+	}
+}
+
+func TestPostgresUniqueViolation(t *testing.T) {
+	err := errors.New(`pq: duplicate key value violates unique constraint "depositories_pkey"`)
+	if !UniqueViolation(err) {
+		t.Error("should have matched unique violation")
+	}
+	if !PostgresUniqueViolation(&pq.Error{Code: "23505"}) {
+		t.Error("should have matched unique violation")
+	}
+	if PostgresUniqueViolation(&pq.Error{Code: "23503"}) {
+		t.Error("foreign key violation matched")
+	}
+}
+
+func TestPostgres__rebind(t *testing.T) {
+	cases := map[string]string{
+		`select 1;`: `select 1;`,
+		`select * from receivers where receiver_id = ? and user_id = ? limit 1`:                          `select * from receivers where receiver_id = $1 and user_id = $2 limit 1`,
+		"insert into event_metadata (event_id, user_id, `key`, value) values (?, ?, ?, ?);":              `insert into event_metadata (event_id, user_id, "key", value) values ($1, $2, $3, $4);`,
+		`insert into receivers (receiver_id, email, status) values (?, '', ?);`:                          `insert into receivers (receiver_id, email, status) values ($1, '', $2);`,
+		"select 'what?', '`a`', 'it''s?' from t where a = ?":                                             "select 'what?', '`a`', 'it''s?' from t where a = $1",
+		`update trace_numbers set sequence = sequence + ?, updated_at = ? where odfi_identification = ?`: `update trace_numbers set sequence = sequence + $1, updated_at = $2 where odfi_identification = $3`,
+	}
+	for input, expected := range cases {
+		if got := rebind(input); got != expected {
+			t.Errorf("rebind(%q)\n got %q\nwant %q", input, got, expected)
+		}
+	}
+}
+
+func TestPostgres__isInsert(t *testing.T) {
+	if !isInsert("insert into a values (1)") || !isInsert("\n  INSERT into a values (1)") {
+		t.Error("expected insert")
+	}
+	if isInsert("update a set b = 1") || isInsert("ins") {
+		t.Error("unexpected insert")
+	}
+}
+
+func TestPostgres__savepoints(t *testing.T) {
+	fake := &fakePostgresConnector{}
+	db := sql.OpenDB(&postgresConnector{connector: fake})
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// Outside of a transaction statements run as-is
+	if _, err := db.Exec(`insert into a (b) values (?)`, 1); err != nil {
+		t.Fatal(err)
+	}
+	fake.expect(t, `insert into a (b) values ($1)`)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// inserts are wrapped in a savepoint
+	if _, err := tx.Exec(`insert into a (b) values (?)`, 1); err != nil {
+		t.Fatal(err)
+	}
+	fake.expect(t, "savepoint paygate_insert", `insert into a (b) values ($1)`, "release savepoint paygate_insert")
+
+	// and rolled back to it on errors, which are returned unchanged
+	fake.fail = &pq.Error{Code: "23505"}
+	stmt, err := tx.Prepare(`insert into a (b) values (?)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt.Exec(1); !UniqueViolation(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	stmt.Close()
+	fake.expect(t, "savepoint paygate_insert", `insert into a (b) values ($1)`, "rollback to savepoint paygate_insert")
+
+	// other statements aren't
+	if _, err := tx.Exec(`update a set b = ?`, 2); err != nil {
+		t.Fatal(err)
+	}
+	fake.expect(t, `update a set b = $1`)
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`insert into a (b) values (?)`, 1); err != nil {
+		t.Fatal(err)
+	}
+	fake.expect(t, `insert into a (b) values ($1)`)
+}
+
+// fakePostgresConnector records the queries executed against it, which lets us test
+// postgresConnector without a PostgreSQL server.
+type fakePostgresConnector struct {
+	queries []string
+	fail    error // returned once by the next non-savepoint query
+}
+
+func (c *fakePostgresConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return &fakePostgresConn{c}, nil
+}
+
+func (c *fakePostgresConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *fakePostgresConnector) exec(query string) (driver.Result, error) {
+	c.queries = append(c.queries, query)
+	if c.fail != nil && !strings.Contains(query, "savepoint") {
+		err := c.fail
+		c.fail = nil
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakePostgresConnector) expect(t *testing.T, queries ...string) {
+	t.Helper()
+	if !reflect.DeepEqual(c.queries, queries) {
+		t.Errorf("got queries %q\nexpected %q", c.queries, queries)
+	}
+	c.queries = nil
+}
+
+type fakePostgresConn struct {
+	c *fakePostgresConnector
+}
+
+func (c *fakePostgresConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakePostgresStmt{c: c.c, query: query}, nil
+}
+func (c *fakePostgresConn) Close() error              { return nil }
+func (c *fakePostgresConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakePostgresConn) Commit() error             { return nil }
+func (c *fakePostgresConn) Rollback() error           { return nil }
+
+func (c *fakePostgresConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	return c.c.exec(query)
+}
+
+type fakePostgresStmt struct {
+	c     *fakePostgresConnector
+	query string
+}
+
+func (s *fakePostgresStmt) Close() error  { return nil }
+func (s *fakePostgresStmt) NumInput() int { return -1 }
+func (s *fakePostgresStmt) Exec(_ []driver.Value) (driver.Result, error) {
+	return s.c.exec(s.query)
+}
+func (s *fakePostgresStmt) Query(_ []driver.Value) (driver.Rows, error) {
+	return &fakePostgresRows{}, nil
+}
+
+type fakePostgresRows struct{}
+
+func (r *fakePostgresRows) Columns() []string           { return nil }
+func (r *fakePostgresRows) Close() error                { return nil }
+func (r *fakePostgresRows) Next(_ []driver.Value) error { return io.EOF }
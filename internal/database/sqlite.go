@@ -132,6 +132,132 @@ var (
 			"create_event_metadata",
 			"create table event_metadata(event_id, user_id, key, value);",
 		),
+		execsql(
+			"create_transfer_addenda",
+			"create table transfer_addenda(transfer_id, sequence_number integer, payment_related_information, created_at datetime, primary key (transfer_id, sequence_number));",
+		),
+		execsql(
+			"add_payment_related_information_to_transfers",
+			"alter table transfers add column payment_related_information default '';",
+		),
+		execsql(
+			"add_identification_number_to_transfers",
+			"alter table transfers add column identification_number default '';",
+		),
+		execsql(
+			"add_discretionary_data_to_transfers",
+			"alter table transfers add column discretionary_data default '';",
+		),
+		execsql(
+			"create_trace_numbers",
+			"create table trace_numbers(odfi_identification primary key, sequence integer not null, updated_at datetime);",
+		),
+		execsql(
+			"add_escalated_at_to_depositories",
+			"alter table depositories add column escalated_at datetime;",
+		),
+		execsql(
+			"create_depository_prenotes",
+			`create table if not exists depository_prenotes(depository_id, user_id, file_id, trace_number, return_code default '', merged_filename, merged_at datetime, created_at datetime, deleted_at datetime);`,
+		),
+		execsql(
+			"add_identification_encrypted_to_originators",
+			"alter table originators add column identification_encrypted default '';",
+		),
+		execsql(
+			"add_identification_hashed_to_originators",
+			"alter table originators add column identification_hashed default '';",
+		),
+		execsql(
+			"add_email_encrypted_to_receivers",
+			"alter table receivers add column email_encrypted default '';",
+		),
+		execsql(
+			"add_email_hashed_to_receivers",
+			"alter table receivers add column email_hashed default '';",
+		),
+		execsql(
+			"create_receiver_depositories",
+			"create table receiver_depositories(receiver_id, depository_id, created_at datetime, primary key (receiver_id, depository_id));",
+		),
+		execsql(
+			"link_receiver_default_depositories",
+			"insert into receiver_depositories (receiver_id, depository_id, created_at) select receiver_id, default_depository, created_at from receivers where default_depository <> '' and deleted_at is null;",
+		),
+		execsql(
+			"create_authorizations",
+			"create table authorizations(authorization_id primary key, user_id, receiver_id, originator_id, type, reference, scope, amount_cap, expires_at datetime, revoked_at datetime, revocation_reason, created_at datetime, last_updated_at datetime, deleted_at datetime);",
+		),
+		execsql(
+			"add_authorization_id_to_transfers",
+			"alter table transfers add column authorization_id;",
+		),
+		execsql(
+			"create_api_keys",
+			"create table api_keys(key_id primary key, user_id, key_hash, scopes, created_at datetime, deleted_at datetime);",
+		),
+		execsql(
+			"create_api_keys_hash_idx",
+			"create unique index api_keys_hash_idx on api_keys(key_hash);",
+		),
+		execsql(
+			"create_audit_log",
+			"create table audit_log(entry_id primary key, created_at datetime, admin_name, admin_role, method, path, route, request_id, remote_addr, status integer, request, before_snapshot, after_snapshot);",
+		),
+		execsql(
+			"create_audit_log_created_at_idx",
+			"create index audit_log_created_at_idx on audit_log(created_at);",
+		),
+		execsql(
+			"create_rate_limit_buckets",
+			"create table rate_limit_buckets(bucket_key primary key, tokens real, updated_at integer);",
+		),
+		execsql(
+			"create_plaintext_account_numbers",
+			"create table plaintext_account_numbers(depository_id primary key, account_number);",
+		),
+		execsql(
+			"move_plaintext_account_numbers",
+			"insert into plaintext_account_numbers (depository_id, account_number) select depository_id, account_number from depositories where account_number <> '';",
+		),
+		execsqlTx(
+			"drop_account_number_from_depositories",
+			"create table depositories_new(depository_id primary key, user_id, bank_name, holder, holder_type, type, routing_number, status, metadata, created_at datetime, last_updated_at datetime, deleted_at datetime, account_number_encrypted default '', account_number_hashed default '', escalated_at datetime);",
+			"insert into depositories_new select depository_id, user_id, bank_name, holder, holder_type, type, routing_number, status, metadata, created_at, last_updated_at, deleted_at, account_number_encrypted, account_number_hashed, escalated_at from depositories;",
+			"drop table depositories;",
+			"alter table depositories_new rename to depositories;",
+		),
+		execsql(
+			"add_withdraw_transaction_id_to_micro_deposits",
+			"alter table micro_deposits add column withdraw_transaction_id default '';",
+		),
+		execsqlTx(
+			"drop_identification_hashed_from_originators",
+			"create table originators_new(originator_id primary key, user_id, default_depository, identification, metadata, created_at datetime, last_updated_at datetime, deleted_at datetime, customer_id default '', identification_encrypted default '');",
+			"insert into originators_new select originator_id, user_id, default_depository, identification, metadata, created_at, last_updated_at, deleted_at, customer_id, identification_encrypted from originators;",
+			"drop table originators;",
+			"alter table originators_new rename to originators;",
+		),
+		execsql(
+			"add_consumed_at_to_authorizations",
+			"alter table authorizations add column consumed_at datetime;",
+		),
+		execsql(
+			"consume_used_single_authorizations",
+			"update authorizations set consumed_at = last_updated_at where scope = 'single' and authorization_id in (select authorization_id from transfers where status not in ('canceled', 'failed') and deleted_at is null);",
+		),
+		execsql(
+			"add_micro_deposit_id_to_micro_deposits",
+			"alter table micro_deposits add column micro_deposit_id;",
+		),
+		execsql(
+			"set_micro_deposit_ids",
+			"update micro_deposits set micro_deposit_id = lower(hex(randomblob(16))) where micro_deposit_id is null;",
+		),
+		execsql(
+			"create_transfer_check_details",
+			"create table transfer_check_details(transfer_id primary key, check_serial_number, terminal_city, terminal_state, created_at datetime);",
+		),
 	)
 )
 
@@ -200,8 +326,7 @@ func sqliteConnection(logger log.Logger, path string) *sqlite {
 	}
 }
 
-func getSqlitePath() string {
-	path := os.Getenv("SQLITE_DB_PATH")
+func getSqlitePath(path string) string {
 	if path == "" || strings.Contains(path, "..") {
 		// set default if empty or trying to escape
 		// don't filepath.ABS to avoid full-fs reads
@@ -41,7 +41,13 @@ func TestSQLite__basic(t *testing.T) {
 }
 
 func TestSQLite__getSqlitePath(t *testing.T) {
-	if v := getSqlitePath(); v != "paygate.db" {
+	if v := getSqlitePath(""); v != "paygate.db" {
+		t.Errorf("got %s", v)
+	}
+	if v := getSqlitePath("../paygate.db"); v != "paygate.db" {
+		t.Errorf("got %s", v)
+	}
+	if v := getSqlitePath("/opt/paygate/paygate.db"); v != "/opt/paygate/paygate.db" {
 		t.Errorf("got %s", v)
 	}
 }
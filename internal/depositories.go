@@ -5,6 +5,7 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -12,15 +13,20 @@ import (
 	"net/http"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/moov-io/ach"
 	"github.com/moov-io/base"
 	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/auth"
+	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/internal/events"
 	"github.com/moov-io/paygate/internal/fed"
+	"github.com/moov-io/paygate/internal/iav"
 	"github.com/moov-io/paygate/internal/route"
 	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/internal/tracing"
 	"github.com/moov-io/paygate/pkg/achclient"
 	"github.com/moov-io/paygate/pkg/id"
 
@@ -157,10 +163,9 @@ type depositoryRequest struct {
 	hashedAccountNumber string
 }
 
+// missingFields checks the required fields of a depositoryRequest. BankName isn't required as
+// it's filled in from the FED lookup of RoutingNumber.
 func (r depositoryRequest) missingFields() error {
-	if r.bankName == "" {
-		return errors.New("missing depositoryRequest.BankName")
-	}
 	if r.holder == "" {
 		return errors.New("missing depositoryRequest.Holder")
 	}
@@ -294,50 +299,74 @@ type DepositoryRouter struct {
 
 	microDepositAttemper attempter
 
+	verifiers          map[VerificationStrategy]Verifier
+	verificationPolicy *verificationPolicy
+
 	depositoryRepo DepositoryRepository
 	eventRepo      events.Repository
+	traceNumbers   traceNumberRepository
+
+	// microDepositExpiration is how long micro-deposits can be confirmed for, zero means they never expire.
+	microDepositExpiration time.Duration
 
 	keeper *secrets.StringKeeper
 }
 
 func NewDepositoryRouter(
-	logger log.Logger,
+	cfg *config.Config,
 	odfiAccount *ODFIAccount,
 	accountsClient AccountsClient,
 	achClient *achclient.ACH,
 	fedClient fed.Client,
+	iavClient iav.Client,
 	depositoryRepo DepositoryRepository,
 	eventRepo events.Repository,
+	traceNumbers traceNumberRepository,
 	keeper *secrets.StringKeeper,
 ) *DepositoryRouter {
 
 	router := &DepositoryRouter{
-		logger:         logger,
+		logger:         cfg.Logger,
 		odfiAccount:    odfiAccount,
 		achClient:      achClient,
 		accountsClient: accountsClient,
 		fedClient:      fedClient,
 		depositoryRepo: depositoryRepo,
 		eventRepo:      eventRepo,
+		traceNumbers:   traceNumbers,
 		keeper:         keeper,
+
+		microDepositExpiration: cfg.MicroDeposits.Expiration,
+
+		verificationPolicy: newVerificationPolicy(cfg.Verification),
 	}
 	if r, ok := depositoryRepo.(*SQLDepositoryRepo); ok {
 		// only allow 5 micro-deposit verification steps
-		router.microDepositAttemper = NewAttemper(logger, r.db, 5)
+		router.microDepositAttemper = NewAttemper(cfg.Logger, r.db, 5)
+	}
+	router.verifiers = map[VerificationStrategy]Verifier{
+		MicroDepositVerification: &microDepositVerifier{router: router},
+		PrenoteVerification:      &prenoteVerifier{router: router, bankingDays: cfg.Verification.PrenoteBankingDays},
+	}
+	if iavClient != nil {
+		router.verifiers[InstantVerification] = &instantVerifier{router: router, client: iavClient}
 	}
 	return router
 }
 
 func (r *DepositoryRouter) RegisterRoutes(router *mux.Router) {
-	router.Methods("GET").Path("/depositories").HandlerFunc(r.getUserDepositories())
-	router.Methods("POST").Path("/depositories").HandlerFunc(r.createUserDepository())
+	router.Methods("GET").Path("/depositories").HandlerFunc(auth.Require(auth.DepositoriesRead, r.getUserDepositories()))
+	router.Methods("POST").Path("/depositories").HandlerFunc(auth.Require(auth.DepositoriesWrite, r.createUserDepository()))
 
-	router.Methods("GET").Path("/depositories/{depositoryId}").HandlerFunc(r.getUserDepository())
-	router.Methods("PATCH").Path("/depositories/{depositoryId}").HandlerFunc(r.updateUserDepository())
-	router.Methods("DELETE").Path("/depositories/{depositoryId}").HandlerFunc(r.deleteUserDepository())
+	router.Methods("GET").Path("/depositories/{depositoryId}").HandlerFunc(auth.Require(auth.DepositoriesRead, r.getUserDepository()))
+	router.Methods("PATCH").Path("/depositories/{depositoryId}").HandlerFunc(auth.Require(auth.DepositoriesWrite, r.updateUserDepository()))
+	router.Methods("DELETE").Path("/depositories/{depositoryId}").HandlerFunc(auth.Require(auth.DepositoriesWrite, r.deleteUserDepository()))
 
-	router.Methods("POST").Path("/depositories/{depositoryId}/micro-deposits").HandlerFunc(r.initiateMicroDeposits())
-	router.Methods("POST").Path("/depositories/{depositoryId}/micro-deposits/confirm").HandlerFunc(r.confirmMicroDeposits())
+	router.Methods("POST").Path("/depositories/{depositoryId}/verify").HandlerFunc(auth.Require(auth.DepositoriesWrite, r.verifyDepository()))
+
+	router.Methods("POST").Path("/depositories/{depositoryId}/micro-deposits").HandlerFunc(auth.Require(auth.DepositoriesWrite, r.initiateMicroDeposits()))
+	router.Methods("POST").Path("/depositories/{depositoryId}/micro-deposits/confirm").HandlerFunc(auth.Require(auth.DepositoriesWrite, r.confirmMicroDeposits()))
+	router.Methods("POST").Path("/depositories/{depositoryId}/micro-deposits/resend").HandlerFunc(auth.Require(auth.DepositoriesWrite, r.resendMicroDeposits()))
 }
 
 // GET /depositories
@@ -349,7 +378,7 @@ func (r *DepositoryRouter) getUserDepositories() http.HandlerFunc {
 			return
 		}
 
-		deposits, err := r.depositoryRepo.GetUserDepositories(responder.XUserID)
+		deposits, err := r.depositoryRepo.GetUserDepositories(httpReq.Context(), responder.XUserID)
 		if err != nil {
 			responder.Log("depositories", fmt.Sprintf("problem reading user depositories"))
 			responder.Problem(err)
@@ -435,13 +464,20 @@ func (r *DepositoryRouter) createUserDepository() http.HandlerFunc {
 		// TODO(adam): We should check and reject duplicate Depositories (by ABA and AccountNumber) on creation
 
 		// Check FED for the routing number
-		if err := r.fedClient.LookupRoutingNumber(req.routingNumber); err != nil {
+		bankName, err := r.lookupBankName(httpReq.Context(), req.routingNumber, req.bankName)
+		if err != nil {
 			responder.Log("depositories", fmt.Sprintf("problem with FED routing number lookup %q: %v", req.routingNumber, err.Error()))
+			problemWithBankName(responder, err)
+			return
+		}
+		if bankName == "" {
+			err = fmt.Errorf("%v: missing depositoryRequest.BankName", ErrMissingRequiredJson)
 			responder.Problem(err)
 			return
 		}
+		depository.BankName = bankName
 
-		if err := r.depositoryRepo.UpsertUserDepository(responder.XUserID, depository); err != nil {
+		if err := r.depositoryRepo.UpsertUserDepository(httpReq.Context(), responder.XUserID, depository); err != nil {
 			responder.Log("depositories", err.Error())
 			responder.Problem(err)
 			return
@@ -454,6 +490,137 @@ func (r *DepositoryRouter) createUserDepository() http.HandlerFunc {
 	}
 }
 
+// errFEDUnavailable is returned by lookupBankName when FED can't be reached and there's no BankName to use instead.
+var errFEDUnavailable = errors.New("FED is unavailable to lookup the bank name, retry or provide bankName")
+
+// lookupBankName checks FED for routingNumber and returns the BankName a Depository should have.
+// An empty bankName is filled in with the participant's name, otherwise the two must match.
+// The result is empty only if FED has no name and bankName is empty.
+//
+// When FED can't be reached the lookup fails open and bankName is used as-is, unless it's empty.
+func (r *DepositoryRouter) lookupBankName(ctx context.Context, routingNumber, bankName string) (string, error) {
+	participant, err := r.fedClient.LookupRoutingNumber(ctx, routingNumber)
+	if err != nil {
+		if err == fed.ErrParticipantNotFound {
+			return "", err
+		}
+		r.logger.Log("depositories", fmt.Sprintf("skipping FED lookup of routing number %s: %v", routingNumber, err))
+		if bankName == "" {
+			return "", errFEDUnavailable
+		}
+		return bankName, nil
+	}
+	if !participant.AcceptsACH {
+		return "", fmt.Errorf("routing number %s does not accept ACH entries", routingNumber)
+	}
+	if participant.Name == "" {
+		return bankName, nil // nothing to compare against
+	}
+	if bankName != "" && !bankNamesMatch(bankName, participant.Name) {
+		return "", fmt.Errorf("bankName %q does not match %q for routing number %s", bankName, participant.Name, routingNumber)
+	}
+	return participant.Name, nil
+}
+
+// problemWithBankName responds with the error from lookupBankName, which is a 503 when FED is unavailable.
+func problemWithBankName(responder *route.Responder, err error) {
+	if err != errFEDUnavailable {
+		responder.Problem(err)
+		return
+	}
+	responder.Respond(func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	})
+}
+
+// fedNameLength is the longest name in the FEDACH directory, longer names are truncated.
+const fedNameLength = 36
+
+// bankNamesMatch compares a user supplied bankName against the FEDACH participant's name after normalizing both,
+// FED's name is allowed to be a truncated prefix of bankName.
+func bankNamesMatch(bankName, fedName string) bool {
+	user, participant := normalizeBankName(bankName), normalizeBankName(fedName)
+	if user == participant {
+		return true
+	}
+	return len(fedName) >= fedNameLength && participant != "" && strings.HasPrefix(user, participant)
+}
+
+var (
+	// bankNameAbbreviations expands the abbreviations commonly found in FEDACH participant names.
+	bankNameAbbreviations = map[string][]string{
+		"&":     {"AND"},
+		"ASSN":  {"ASSOCIATION"},
+		"ASSOC": {"ASSOCIATION"},
+		"BK":    {"BANK"},
+		"BNK":   {"BANK"},
+		"CO":    {"COMPANY"},
+		"CORP":  {"CORPORATION"},
+		"CU":    {"CREDIT", "UNION"},
+		"FED":   {"FEDERAL"},
+		"FEDL":  {"FEDERAL"},
+		"FIN":   {"FINANCIAL"},
+		"FINL":  {"FINANCIAL"},
+		"INTL":  {"INTERNATIONAL"},
+		"NATL":  {"NATIONAL"},
+		"SAV":   {"SAVINGS"},
+		"SVGS":  {"SAVINGS"},
+		"TR":    {"TRUST"},
+	}
+
+	// bankNameSuffixes are legal suffixes dropped from the end of names, e.g. "N.A." for National Association.
+	bankNameSuffixes = [][]string{
+		{"NA"},
+		{"NATIONAL", "ASSOCIATION"},
+		{"INC"},
+		{"INCORPORATED"},
+		{"LLC"},
+	}
+)
+
+// normalizeBankName uppercases name, expands common abbreviations and drops punctuation, spaces, "The" and
+// legal suffixes so "The Wells Fargo Bank, N.A." matches "WELLS FARGO BK" and "First Natl Bank" matches
+// "FIRST NATIONAL BANK".
+func normalizeBankName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '.' || r == '\'':
+			return -1 // N.A. is NA and PEOPLE'S is PEOPLES
+		case r == '&' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToUpper(r)
+		}
+		return ' '
+	}, strings.Replace(name, "&", " & ", -1))
+
+	// Join initials split by spaces (N A) and expand abbreviations
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if n := len(words); n > 0 && len(word) == 1 && len(words[n-1]) == 1 && word != "&" && words[n-1] != "&" {
+			words[n-1] += word
+			continue
+		}
+		words = append(words, word)
+	}
+	var expanded []string
+	for _, word := range words {
+		if full, exists := bankNameAbbreviations[word]; exists {
+			expanded = append(expanded, full...)
+		} else if word != "THE" {
+			expanded = append(expanded, word)
+		}
+	}
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, suffix := range bankNameSuffixes {
+			if n := len(expanded) - len(suffix); n > 0 && strings.Join(expanded[n:], " ") == strings.Join(suffix, " ") {
+				expanded, trimmed = expanded[:n], true
+			}
+		}
+	}
+	return strings.Join(expanded, "")
+}
+
 func (r *DepositoryRouter) getUserDepository() http.HandlerFunc {
 	return func(w http.ResponseWriter, httpReq *http.Request) {
 		responder := route.NewResponder(r.logger, w, httpReq)
@@ -466,7 +633,7 @@ func (r *DepositoryRouter) getUserDepository() http.HandlerFunc {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		depository, err := r.depositoryRepo.GetUserDepository(depID, responder.XUserID)
+		depository, err := r.depositoryRepo.GetUserDepository(httpReq.Context(), depID, responder.XUserID)
 		if err != nil {
 			responder.Log("depositories", err.Error())
 			responder.Problem(err)
@@ -500,7 +667,7 @@ func (r *DepositoryRouter) updateUserDepository() http.HandlerFunc {
 			return
 		}
 
-		depository, err := r.depositoryRepo.GetUserDepository(depID, responder.XUserID)
+		depository, err := r.depositoryRepo.GetUserDepository(httpReq.Context(), depID, responder.XUserID)
 		if err != nil {
 			r.logger.Log("depositories", err.Error())
 			moovhttp.Problem(w, err)
@@ -548,12 +715,25 @@ func (r *DepositoryRouter) updateUserDepository() http.HandlerFunc {
 			depository.Status = DepositoryUnverified
 		}
 
+		// Check FED when the bank changes, a new RoutingNumber without a BankName takes FED's name.
+		if req.routingNumber != "" || req.bankName != "" {
+			bankName, err := r.lookupBankName(httpReq.Context(), depository.RoutingNumber, req.bankName)
+			if err != nil {
+				responder.Log("depositories", fmt.Sprintf("problem with FED routing number lookup %q: %v", depository.RoutingNumber, err.Error()))
+				problemWithBankName(responder, err)
+				return
+			}
+			if bankName != "" {
+				depository.BankName = bankName
+			}
+		}
+
 		if err := depository.validate(); err != nil {
 			responder.Problem(err)
 			return
 		}
 
-		if err := r.depositoryRepo.UpsertUserDepository(responder.XUserID, depository); err != nil {
+		if err := r.depositoryRepo.UpsertUserDepository(httpReq.Context(), responder.XUserID, depository); err != nil {
 			responder.Log("depositories", err.Error())
 			responder.Problem(err)
 			return
@@ -578,7 +758,7 @@ func (r *DepositoryRouter) deleteUserDepository() http.HandlerFunc {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		if err := r.depositoryRepo.deleteUserDepository(depID, responder.XUserID); err != nil {
+		if err := r.depositoryRepo.deleteUserDepository(httpReq.Context(), depID, responder.XUserID); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		}
@@ -595,34 +775,43 @@ func GetDepositoryID(r *http.Request) id.Depository {
 	return id.Depository(v)
 }
 
-func markDepositoryVerified(repo DepositoryRepository, depID id.Depository, userID id.User) error {
-	dep, err := repo.GetUserDepository(depID, userID)
+func markDepositoryVerified(ctx context.Context, repo DepositoryRepository, depID id.Depository, userID id.User) error {
+	dep, err := repo.GetUserDepository(ctx, depID, userID)
 	if err != nil {
 		return fmt.Errorf("markDepositoryVerified: depository %v (userID=%v): %v", depID, userID, err)
 	}
 	dep.Status = DepositoryVerified
-	return repo.UpsertUserDepository(userID, dep)
+	return repo.UpsertUserDepository(ctx, userID, dep)
 }
 
 type DepositoryRepository interface {
-	GetDepository(id id.Depository) (*Depository, error) // admin endpoint
-	GetUserDepositories(userID id.User) ([]*Depository, error)
-	GetUserDepository(id id.Depository, userID id.User) (*Depository, error)
-
-	UpsertUserDepository(userID id.User, dep *Depository) error
-	UpdateDepositoryStatus(id id.Depository, status DepositoryStatus) error
-	deleteUserDepository(id id.Depository, userID id.User) error
-
-	GetMicroDeposits(id id.Depository) ([]*MicroDeposit, error) // admin endpoint
-	getMicroDepositsForUser(id id.Depository, userID id.User) ([]*MicroDeposit, error)
-
-	LookupDepositoryFromReturn(routingNumber string, accountNumber string) (*Depository, error)
-	LookupMicroDepositFromReturn(id id.Depository, amount *Amount) (*MicroDeposit, error)
-	SetReturnCode(id id.Depository, amount Amount, returnCode string) error
-
-	InitiateMicroDeposits(id id.Depository, userID id.User, microDeposit []*MicroDeposit) error
-	confirmMicroDeposits(id id.Depository, userID id.User, amounts []Amount) error
+	GetDepository(ctx context.Context, id id.Depository) (*Depository, error) // admin endpoint
+	GetUserDepositories(ctx context.Context, userID id.User) ([]*Depository, error)
+	GetUserDepository(ctx context.Context, id id.Depository, userID id.User) (*Depository, error)
+
+	UpsertUserDepository(ctx context.Context, userID id.User, dep *Depository) error
+	UpdateDepositoryStatus(ctx context.Context, id id.Depository, status DepositoryStatus) error
+	deleteUserDepository(ctx context.Context, id id.Depository, userID id.User) error
+
+	GetMicroDeposits(ctx context.Context, id id.Depository) ([]*MicroDeposit, error) // admin endpoint
+	getMicroDepositsForUser(ctx context.Context, id id.Depository, userID id.User) ([]*MicroDeposit, error)
+
+	LookupDepositoryFromReturn(ctx context.Context, routingNumber string, accountNumber string) (*Depository, error)
+	LookupMicroDepositFromReturn(ctx context.Context, id id.Depository, amount *Amount) (*MicroDeposit, error)
+	SetReturnCode(ctx context.Context, id id.Depository, amount Amount, returnCode string) error
+
+	InitiateMicroDeposits(ctx context.Context, id id.Depository, userID id.User, microDeposit []*MicroDeposit) error
+	getVoidableMicroDeposits(ctx context.Context, id id.Depository, userID id.User) ([]*voidableMicroDeposit, error)
+	voidMicroDeposit(ctx context.Context, md *voidableMicroDeposit) (int64, error)
+	clearWithdrawTransaction(ctx context.Context, md *voidableMicroDeposit) error
+	confirmMicroDeposits(ctx context.Context, id id.Depository, userID id.User, amounts []Amount) error
 	GetMicroDepositCursor(batchSize int) *MicroDepositCursor
+
+	createPrenote(ctx context.Context, id id.Depository, userID id.User, prenote *Prenote) error
+	getLatestPrenote(ctx context.Context, id id.Depository, userID id.User) (*Prenote, error)
+	LookupPrenoteFromReturn(ctx context.Context, id id.Depository, traceNumber string) (*Prenote, error)
+	SetPrenoteReturnCode(ctx context.Context, id id.Depository, traceNumber string, returnCode string) error
+	GetPrenoteCursor(batchSize int) *PrenoteCursor
 }
 
 func NewDepositoryRepo(logger log.Logger, db *sql.DB, keeper *secrets.StringKeeper) *SQLDepositoryRepo {
@@ -639,16 +828,16 @@ func (r *SQLDepositoryRepo) Close() error {
 	return r.db.Close()
 }
 
-func (r *SQLDepositoryRepo) GetDepository(depID id.Depository) (*Depository, error) {
+func (r *SQLDepositoryRepo) GetDepository(ctx context.Context, depID id.Depository) (*Depository, error) {
 	query := `select user_id from depositories where depository_id = ? and deleted_at is null limit 1;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
 	var userID string
-	if err := stmt.QueryRow(depID).Scan(&userID); err != nil {
+	if err := stmt.QueryRowContext(ctx, depID).Scan(&userID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -658,22 +847,22 @@ func (r *SQLDepositoryRepo) GetDepository(depID id.Depository) (*Depository, err
 		return nil, nil // not found
 	}
 
-	dep, err := r.GetUserDepository(depID, id.User(userID))
+	dep, err := r.GetUserDepository(ctx, depID, id.User(userID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return dep, err
 }
 
-func (r *SQLDepositoryRepo) GetUserDepositories(userID id.User) ([]*Depository, error) {
+func (r *SQLDepositoryRepo) GetUserDepositories(ctx context.Context, userID id.User) ([]*Depository, error) {
 	query := `select depository_id from depositories where user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(userID)
+	rows, err := stmt.QueryContext(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -692,7 +881,7 @@ func (r *SQLDepositoryRepo) GetUserDepositories(userID id.User) ([]*Depository,
 
 	var depositories []*Depository
 	for i := range depositoryIds {
-		dep, err := r.GetUserDepository(id.Depository(depositoryIds[i]), userID)
+		dep, err := r.GetUserDepository(ctx, id.Depository(depositoryIds[i]), userID)
 		if err == nil && dep != nil && dep.BankName != "" {
 			depositories = append(depositories, dep)
 		}
@@ -700,18 +889,18 @@ func (r *SQLDepositoryRepo) GetUserDepositories(userID id.User) ([]*Depository,
 	return depositories, rows.Err()
 }
 
-func (r *SQLDepositoryRepo) GetUserDepository(id id.Depository, userID id.User) (*Depository, error) {
+func (r *SQLDepositoryRepo) GetUserDepository(ctx context.Context, id id.Depository, userID id.User) (*Depository, error) {
 	query := `select depository_id, bank_name, holder, holder_type, type, routing_number, account_number_encrypted, account_number_hashed, status, metadata, created_at, last_updated_at
 from depositories
 where depository_id = ? and user_id = ? and deleted_at is null
 limit 1`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("GetUserDepository: prepare: %v", err)
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(id, userID)
+	row := stmt.QueryRowContext(ctx, id, userID)
 
 	dep := &Depository{userID: userID}
 	var (
@@ -725,7 +914,7 @@ limit 1`
 		}
 		return nil, fmt.Errorf("GetUserDepository: scan: %v", err)
 	}
-	dep.ReturnCodes = r.getMicroDepositReturnCodes(dep.ID)
+	dep.ReturnCodes = r.getMicroDepositReturnCodes(ctx, dep.ID)
 	dep.Created = base.NewTime(created)
 	dep.Updated = base.NewTime(updated)
 	if dep.ID == "" || dep.BankName == "" {
@@ -735,8 +924,13 @@ limit 1`
 	return dep, nil
 }
 
-func (r *SQLDepositoryRepo) UpsertUserDepository(userID id.User, dep *Depository) error {
-	tx, err := r.db.Begin()
+func (r *SQLDepositoryRepo) UpsertUserDepository(ctx context.Context, userID id.User, dep *Depository) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.UpsertUserDepository", "depositoryID", dep.ID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -749,13 +943,13 @@ func (r *SQLDepositoryRepo) UpsertUserDepository(userID id.User, dep *Depository
 
 	query := `insert into depositories (depository_id, user_id, bank_name, holder, holder_type, type, routing_number, account_number_encrypted, account_number_hashed, status, metadata, created_at, last_updated_at)
 values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.Exec(dep.ID, userID, dep.BankName, dep.Holder, dep.HolderType, dep.Type, dep.RoutingNumber, dep.EncryptedAccountNumber, dep.hashedAccountNumber, dep.Status, dep.Metadata, dep.Created.Time, dep.Updated.Time)
+	res, err := stmt.ExecContext(ctx, dep.ID, userID, dep.BankName, dep.Holder, dep.HolderType, dep.Type, dep.RoutingNumber, dep.EncryptedAccountNumber, dep.hashedAccountNumber, dep.Status, dep.Metadata, dep.Created.Time, dep.Updated.Time)
 	stmt.Close()
 	if err != nil && !database.UniqueViolation(err) {
 		return fmt.Errorf("problem upserting depository=%q, userID=%q: %v", dep.ID, userID, err)
@@ -769,12 +963,12 @@ values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 set bank_name = ?, holder = ?, holder_type = ?, type = ?, routing_number = ?,
 account_number_encrypted = ?, account_number_hashed = ?, status = ?, metadata = ?, last_updated_at = ?
 where depository_id = ? and user_id = ? and deleted_at is null`
-	stmt, err = tx.Prepare(query)
+	stmt, err = tx.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
-	_, err = stmt.Exec(
+	_, err = stmt.ExecContext(ctx,
 		dep.BankName, dep.Holder, dep.HolderType, dep.Type, dep.RoutingNumber,
 		dep.EncryptedAccountNumber, dep.hashedAccountNumber, dep.Status, dep.Metadata, time.Now(), dep.ID, userID)
 	stmt.Close()
@@ -784,53 +978,63 @@ where depository_id = ? and user_id = ? and deleted_at is null`
 	return tx.Commit()
 }
 
-func (r *SQLDepositoryRepo) UpdateDepositoryStatus(id id.Depository, status DepositoryStatus) error {
+func (r *SQLDepositoryRepo) UpdateDepositoryStatus(ctx context.Context, id id.Depository, status DepositoryStatus) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.UpdateDepositoryStatus", "depositoryID", id, "status", status)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update depositories set status = ?, last_updated_at = ? where depository_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.Exec(status, time.Now(), id); err != nil {
+	if _, err := stmt.ExecContext(ctx, status, time.Now(), id); err != nil {
 		return fmt.Errorf("error updating status depository_id=%q: %v", id, err)
 	}
 	return nil
 }
 
-func (r *SQLDepositoryRepo) deleteUserDepository(id id.Depository, userID id.User) error {
+func (r *SQLDepositoryRepo) deleteUserDepository(ctx context.Context, id id.Depository, userID id.User) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.deleteUserDepository", "depositoryID", id)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update depositories set deleted_at = ? where depository_id = ? and user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.Exec(time.Now(), id, userID); err != nil && err != sql.ErrNoRows {
+	if _, err := stmt.ExecContext(ctx, time.Now(), id, userID); err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("error deleting depository_id=%q, user_id=%q: %v", id, userID, err)
 	}
 	return nil
 }
 
-func (r *SQLDepositoryRepo) LookupDepositoryFromReturn(routingNumber string, accountNumber string) (*Depository, error) {
+func (r *SQLDepositoryRepo) LookupDepositoryFromReturn(ctx context.Context, routingNumber string, accountNumber string) (*Depository, error) {
 	hash, err := hashAccountNumber(accountNumber)
 	if err != nil {
 		return nil, err
 	}
 	// order by created_at to ignore older rows with non-null deleted_at's
 	query := `select depository_id, user_id from depositories where routing_number = ? and account_number_hashed = ? and deleted_at is null order by created_at desc limit 1;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
 	depID, userID := "", ""
-	if err := stmt.QueryRow(routingNumber, hash).Scan(&depID, &userID); err != nil {
+	if err := stmt.QueryRowContext(ctx, routingNumber, hash).Scan(&depID, &userID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("LookupDepositoryFromReturn: %v", err)
 	}
-	return r.GetUserDepository(id.Depository(depID), id.User(userID))
+	return r.GetUserDepository(ctx, id.Depository(depID), id.User(userID))
 }
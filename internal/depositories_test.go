@@ -6,6 +6,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,13 +19,13 @@ import (
 
 	"github.com/moov-io/base"
 	client "github.com/moov-io/paygate/client"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/internal/fed"
 	"github.com/moov-io/paygate/internal/secrets"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
-	"github.com/gorilla/mux"
 )
 
 func TestDepositoryJSON(t *testing.T) {
@@ -172,12 +173,12 @@ func TestDepositories__emptyDB(t *testing.T) {
 
 	check := func(t *testing.T, repo DepositoryRepository) {
 		userID := id.User(base.ID())
-		if err := repo.deleteUserDepository(id.Depository(base.ID()), userID); err != nil {
+		if err := repo.deleteUserDepository(context.Background(), id.Depository(base.ID()), userID); err != nil {
 			t.Errorf("expected no error, but got %v", err)
 		}
 
 		// all depositories for a user
-		deps, err := repo.GetUserDepositories(userID)
+		deps, err := repo.GetUserDepositories(context.Background(), userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -186,7 +187,7 @@ func TestDepositories__emptyDB(t *testing.T) {
 		}
 
 		// specific Depository
-		dep, err := repo.GetUserDepository(id.Depository(base.ID()), userID)
+		dep, err := repo.GetUserDepository(context.Background(), id.Depository(base.ID()), userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -195,7 +196,7 @@ func TestDepositories__emptyDB(t *testing.T) {
 		}
 
 		// depository check
-		dep, err = repo.GetUserDepository(id.Depository(base.ID()), userID)
+		dep, err = repo.GetUserDepository(context.Background(), id.Depository(base.ID()), userID)
 		if dep != nil {
 			t.Errorf("dep=%#v expected no depository", dep)
 		}
@@ -203,7 +204,7 @@ func TestDepositories__emptyDB(t *testing.T) {
 			t.Error(err)
 		}
 
-		dep, err = repo.GetDepository(id.Depository(base.ID()))
+		dep, err = repo.GetDepository(context.Background(), id.Depository(base.ID()))
 		if dep != nil || err != nil {
 			t.Errorf("expected no depository: %#v: %v", dep, err)
 		}
@@ -216,6 +217,11 @@ func TestDepositories__emptyDB(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -238,16 +244,16 @@ func TestDepositories__upsert(t *testing.T) {
 			Status:                 DepositoryVerified,
 			Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
 		}
-		if d, err := repo.GetUserDepository(dep.ID, userID); err != nil || d != nil {
+		if d, err := repo.GetUserDepository(context.Background(), dep.ID, userID); err != nil || d != nil {
 			t.Errorf("expected empty, d=%v | err=%v", d, err)
 		}
 
 		// write, then verify
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Error(err)
 		}
 
-		d, err := repo.GetUserDepository(dep.ID, userID)
+		d, err := repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -259,7 +265,7 @@ func TestDepositories__upsert(t *testing.T) {
 		}
 
 		// get all for our user
-		depositories, err := repo.GetUserDepositories(userID)
+		depositories, err := repo.GetUserDepositories(context.Background(), userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -273,10 +279,10 @@ func TestDepositories__upsert(t *testing.T) {
 		// update, verify default depository changed
 		bankName := "my new bank"
 		dep.BankName = bankName
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Error(err)
 		}
-		d, err = repo.GetUserDepository(dep.ID, userID)
+		d, err = repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -287,11 +293,11 @@ func TestDepositories__upsert(t *testing.T) {
 			t.Errorf("status: %s", d.Status)
 		}
 
-		dep, err = repo.GetUserDepository(dep.ID, userID)
+		dep, err = repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if dep == nil || err != nil {
 			t.Errorf("DepositoryId should exist: %v", err)
 		}
-		dep, err = repo.GetDepository(dep.ID)
+		dep, err = repo.GetDepository(context.Background(), dep.ID)
 		if dep == nil || err != nil {
 			t.Errorf("expected depository=%#v: %v", dep, err)
 		}
@@ -304,6 +310,11 @@ func TestDepositories__upsert(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -326,32 +337,32 @@ func TestDepositories__delete(t *testing.T) {
 			Status:                 DepositoryUnverified,
 			Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
 		}
-		if d, err := repo.GetUserDepository(dep.ID, userID); err != nil || d != nil {
+		if d, err := repo.GetUserDepository(context.Background(), dep.ID, userID); err != nil || d != nil {
 			t.Errorf("expected empty, d=%v | err=%v", d, err)
 		}
 
 		// write
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Error(err)
 		}
 
 		// verify
-		d, err := repo.GetUserDepository(dep.ID, userID)
+		d, err := repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if err != nil || d == nil {
 			t.Errorf("expected depository, d=%v, err=%v", d, err)
 		}
 
 		// delete
-		if err := repo.deleteUserDepository(dep.ID, userID); err != nil {
+		if err := repo.deleteUserDepository(context.Background(), dep.ID, userID); err != nil {
 			t.Error(err)
 		}
 
 		// verify tombstoned
-		if d, err := repo.GetUserDepository(dep.ID, userID); err != nil || d != nil {
+		if d, err := repo.GetUserDepository(context.Background(), dep.ID, userID); err != nil || d != nil {
 			t.Errorf("expected empty, d=%v | err=%v", d, err)
 		}
 
-		dep, err = repo.GetUserDepository(dep.ID, userID)
+		dep, err = repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if dep != nil || err != nil {
 			t.Errorf("dep=%#v expected none: error=%v", dep, err)
 		}
@@ -364,6 +375,11 @@ func TestDepositories__delete(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -388,15 +404,15 @@ func TestDepositories__UpdateDepositoryStatus(t *testing.T) {
 		}
 
 		// write
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Error(err)
 		}
 
 		// upsert and read back
-		if err := repo.UpdateDepositoryStatus(dep.ID, DepositoryVerified); err != nil {
+		if err := repo.UpdateDepositoryStatus(context.Background(), dep.ID, DepositoryVerified); err != nil {
 			t.Fatal(err)
 		}
-		dep2, err := repo.GetUserDepository(dep.ID, userID)
+		dep2, err := repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -415,6 +431,11 @@ func TestDepositories__UpdateDepositoryStatus(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -439,12 +460,12 @@ func TestDepositories__markApproved(t *testing.T) {
 		}
 
 		// write
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Error(err)
 		}
 
 		// read
-		d, err := repo.GetUserDepository(dep.ID, userID)
+		d, err := repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if err != nil || d == nil {
 			t.Errorf("expected depository, d=%v, err=%v", d, err)
 		}
@@ -453,11 +474,11 @@ func TestDepositories__markApproved(t *testing.T) {
 		}
 
 		// Verify, then re-check
-		if err := markDepositoryVerified(repo, dep.ID, userID); err != nil {
+		if err := markDepositoryVerified(context.Background(), repo, dep.ID, userID); err != nil {
 			t.Fatal(err)
 		}
 
-		d, err = repo.GetUserDepository(dep.ID, userID)
+		d, err = repo.GetUserDepository(context.Background(), dep.ID, userID)
 		if err != nil || d == nil {
 			t.Errorf("expected depository, d=%v, err=%v", d, err)
 		}
@@ -473,6 +494,11 @@ func TestDepositories__markApproved(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -501,7 +527,7 @@ func TestDepositories__HTTPCreate(t *testing.T) {
 		depositoryRepo: repo,
 		keeper:         keeper,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	body := strings.NewReader(`{
@@ -554,13 +580,131 @@ func TestDepositories__HTTPCreate(t *testing.T) {
 	}
 }
 
+func TestDepositories__HTTPCreateBankName(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	userID := id.User(base.ID())
+	keeper := secrets.TestStringKeeper(t)
+
+	fedClient := &fed.TestClient{
+		Participant: &fed.Participant{RoutingNumber: "121042882", Name: "WELLS FARGO BANK NA", AcceptsACH: true},
+	}
+	router := &DepositoryRouter{
+		logger:         log.NewNopLogger(),
+		odfiAccount:    makeTestODFIAccount(),
+		accountsClient: &testAccountsClient{},
+		fedClient:      fedClient,
+		depositoryRepo: NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper),
+		keeper:         keeper,
+	}
+	r := auth.NewTestRouter()
+	router.RegisterRoutes(r)
+
+	create := func(bankName string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"bankName": %q, "holder": "holder", "holderType": "Individual", "type": "Checking", "routingNumber": "121042882", "accountNumber": "1321"}`, bankName)
+		req := httptest.NewRequest("POST", "/depositories", strings.NewReader(body))
+		req.Header.Set("x-user-id", userID.String())
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		w.Flush()
+		return w
+	}
+
+	// BankName is filled in from FED
+	for _, bankName := range []string{"", "Wells Fargo Bank, N.A."} {
+		w := create(bankName)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		var depository Depository
+		if err := json.NewDecoder(w.Body).Decode(&depository); err != nil {
+			t.Fatal(err)
+		}
+		if depository.BankName != "WELLS FARGO BANK NA" {
+			t.Errorf("BankName=%q", depository.BankName)
+		}
+	}
+
+	// other names are rejected
+	if w := create("First Bank"); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "does not match") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// routing numbers FED doesn't know are rejected
+	fedClient.Err = fed.ErrParticipantNotFound
+	if w := create("Wells Fargo"); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// when FED is down the user's BankName is used, or the request can be retried
+	fedClient.Err = errors.New("connection refused")
+	if w := create("Wells Fargo"); w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"bankName":"Wells Fargo"`) {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := create(""); w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "FED is unavailable") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	fedClient.Err = nil
+
+	// as are banks which don't take ACH
+	fedClient.Participant.AcceptsACH = false
+	if w := create(""); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "does not accept ACH") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// FED without a name requires one from the user
+	fedClient.Participant = nil
+	if w := create(""); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "BankName") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDepositories__normalizeBankName(t *testing.T) {
+	if a, b := normalizeBankName("Wells Fargo Bank, N.A."), normalizeBankName("WELLS FARGO BANK NA"); a != b {
+		t.Errorf("%q != %q", a, b)
+	}
+	if normalizeBankName("First Bank") == normalizeBankName("First Bank of Ohio") {
+		t.Error("expected different names")
+	}
+
+	matches := map[string]string{
+		"Wells Fargo Bank":                           "WELLS FARGO BANK, N.A.",
+		"The First National Bank of Omaha":           "FIRST NATL BK OF OMAHA",
+		"Bank of America, N. A.":                     "BANK OF AMERICA NA",
+		"Citizens Bank & Trust Company":              "CITIZENS BANK AND TRUST CO",
+		"Navy Federal Credit Union":                  "NAVY FEDERAL CU",
+		"People's United Bank, National Association": "PEOPLES UNITED BANK",
+	}
+	for user, fed := range matches {
+		if a, b := normalizeBankName(user), normalizeBankName(fed); a != b {
+			t.Errorf("%q (%s) != %q (%s)", user, a, fed, b)
+		}
+	}
+}
+
+func TestDepositories__bankNamesMatch(t *testing.T) {
+	if !bankNamesMatch("First Natl Bank", "FIRST NATIONAL BANK") {
+		t.Error("expected match")
+	}
+	if bankNamesMatch("First Bank of Ohio", "FIRST BANK") {
+		t.Error("expected different names")
+	}
+
+	// FEDACH names are truncated to 36 characters
+	if !bankNamesMatch("Farmers and Merchants Savings Bank of Southern Iowa", "FARMERS AND MERCHANTS SAVINGS BANK O") {
+		t.Error("expected truncated FED name to match")
+	}
+}
+
 func TestDepositories__HTTPCreateNoUserID(t *testing.T) {
 	repo := &MockDepositoryRepository{}
 	router := &DepositoryRouter{
 		logger:         log.NewNopLogger(),
 		depositoryRepo: repo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	body := strings.NewReader(`{"key": "value"}`)
@@ -570,7 +714,7 @@ func TestDepositories__HTTPCreateNoUserID(t *testing.T) {
 	r.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -599,10 +743,10 @@ func TestDepositories__HTTPUpdate(t *testing.T) {
 	if err := dep.ReplaceAccountNumber("1234"); err != nil {
 		t.Fatal(err)
 	}
-	if err := repo.UpsertUserDepository(userID, dep); err != nil {
+	if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		t.Fatal(err)
 	}
-	if dep, _ := repo.GetUserDepository(dep.ID, userID); dep == nil {
+	if dep, _ := repo.GetUserDepository(context.Background(), dep.ID, userID); dep == nil {
 		t.Fatal("nil Depository")
 	}
 
@@ -614,10 +758,11 @@ func TestDepositories__HTTPUpdate(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		odfiAccount:    testODFIAccount,
 		accountsClient: accountsClient,
+		fedClient:      &fed.TestClient{},
 		depositoryRepo: repo,
 		keeper:         keeper,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	body := strings.NewReader(`{"accountNumber": "2515219", "bankName": "bar", "holder": "foo", "holderType": "business", "metadata": "updated"}`)
@@ -669,7 +814,7 @@ func TestDepositories__HTTPUpdateNoUserID(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		depositoryRepo: repo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	body := strings.NewReader(`{"key": "value"}`)
@@ -679,7 +824,7 @@ func TestDepositories__HTTPUpdateNoUserID(t *testing.T) {
 	r.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -718,7 +863,7 @@ func TestDepositories__HTTPGet(t *testing.T) {
 		depositoryRepo: repo,
 		keeper:         keeper,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	req := httptest.NewRequest("GET", fmt.Sprintf("/depositories/%s", dep.ID), nil)
@@ -753,7 +898,7 @@ func TestDepositories__HTTPGetNoUserID(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		depositoryRepo: repo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	body := strings.NewReader(`{"key": "value"}`)
@@ -763,7 +908,7 @@ func TestDepositories__HTTPGetNoUserID(t *testing.T) {
 	r.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -774,7 +919,7 @@ func TestDepositoriesHTTP__delete(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		depositoryRepo: repo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	req := httptest.NewRequest("DELETE", "/depositories/foo", nil)
@@ -805,7 +950,7 @@ func TestDepositoriesHTTP__deleteNoUserID(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		depositoryRepo: repo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	req := httptest.NewRequest("DELETE", "/depositories/foo", nil)
@@ -814,7 +959,7 @@ func TestDepositoriesHTTP__deleteNoUserID(t *testing.T) {
 	r.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -827,7 +972,7 @@ func TestDepositories__LookupDepositoryFromReturn(t *testing.T) {
 		routingNumber, accountNumber := "987654320", "152311"
 
 		// lookup when nothing will be returned
-		dep, err := repo.LookupDepositoryFromReturn(routingNumber, accountNumber)
+		dep, err := repo.LookupDepositoryFromReturn(context.Background(), routingNumber, accountNumber)
 		if dep != nil || err != nil {
 			t.Fatalf("depository=%#v error=%v", dep, err)
 		}
@@ -847,12 +992,12 @@ func TestDepositories__LookupDepositoryFromReturn(t *testing.T) {
 		if err := dep.ReplaceAccountNumber(accountNumber); err != nil {
 			t.Fatal(err)
 		}
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Fatal(err)
 		}
 
 		// lookup again now after we wrote the Depository
-		dep, err = repo.LookupDepositoryFromReturn(routingNumber, accountNumber)
+		dep, err = repo.LookupDepositoryFromReturn(context.Background(), routingNumber, accountNumber)
 		if dep == nil || err != nil {
 			t.Fatalf("depository=%#v error=%v", dep, err)
 		}
@@ -868,6 +1013,11 @@ func TestDepositories__LookupDepositoryFromReturn(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -22,6 +22,18 @@ func RegisterAdminRoutes(logger log.Logger, svc *admin.Server, depRepo internal.
 	svc.AddHandler("/depositories/{depositoryId}", overrideDepositoryStatus(logger, depRepo))
 }
 
+// StatusSnapshot returns the status of the depository in a request's path, which the audit log
+// records before and after it's overridden.
+func StatusSnapshot(depRepo internal.DepositoryRepository) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		dep, err := depRepo.GetDepository(r.Context(), internal.GetDepositoryID(r))
+		if err != nil || dep == nil {
+			return nil, err
+		}
+		return &request{Status: dep.Status}, nil
+	}
+}
+
 type request struct {
 	Status internal.DepositoryStatus `json:"status"`
 }
@@ -46,17 +58,17 @@ func overrideDepositoryStatus(logger log.Logger, depRepo internal.DepositoryRepo
 		}
 
 		// read the depository so we know it exists
-		dep, err := depRepo.GetDepository(depID)
+		dep, err := depRepo.GetDepository(r.Context(), depID)
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
 		}
-		if err := depRepo.UpdateDepositoryStatus(depID, req.Status); err != nil {
+		if err := depRepo.UpdateDepositoryStatus(r.Context(), depID, req.Status); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		}
 		// re-read for marshaling
-		dep, err = depRepo.GetUserDepository(depID, id.User(dep.UserID()))
+		dep, err = depRepo.GetUserDepository(r.Context(), depID, id.User(dep.UserID()))
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
@@ -5,10 +5,12 @@
 package depository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -21,6 +23,7 @@ import (
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
 )
 
 func TestDepository__overrideDepositoryStatus(t *testing.T) {
@@ -37,7 +40,7 @@ func TestDepository__overrideDepositoryStatus(t *testing.T) {
 
 	repo := internal.NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
 
-	if err := repo.UpsertUserDepository(userID, &internal.Depository{
+	if err := repo.UpsertUserDepository(context.Background(), userID, &internal.Depository{
 		ID:            id.Depository(depID),
 		BankName:      "bank name",
 		Holder:        "holder",
@@ -68,7 +71,7 @@ func TestDepository__overrideDepositoryStatus(t *testing.T) {
 		t.Errorf("bogus HTTP status: %s: %v", resp.Status, string(bs))
 	}
 
-	dep, err := repo.GetUserDepository(id.Depository(depID), userID)
+	dep, err := repo.GetUserDepository(context.Background(), id.Depository(depID), userID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -77,6 +80,43 @@ func TestDepository__overrideDepositoryStatus(t *testing.T) {
 	}
 }
 
+func TestDepository__StatusSnapshot(t *testing.T) {
+	depID, userID := base.ID(), id.User(base.ID())
+
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+
+	repo := internal.NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t))
+	if err := repo.UpsertUserDepository(context.Background(), userID, &internal.Depository{
+		ID:            id.Depository(depID),
+		BankName:      "bank name",
+		Holder:        "holder",
+		HolderType:    internal.Individual,
+		Type:          internal.Checking,
+		RoutingNumber: "123",
+		Status:        internal.DepositoryVerified,
+		Created:       base.NewTime(time.Now()),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := StatusSnapshot(repo)
+	req := mux.SetURLVars(httptest.NewRequest("PUT", "/depositories/"+depID, nil), map[string]string{"depositoryId": depID})
+	v, err := snapshot(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := v.(*request); !ok || r.Status != internal.DepositoryVerified {
+		t.Errorf("unexpected snapshot: %#v", v)
+	}
+
+	// unknown depositories have an empty snapshot
+	req = mux.SetURLVars(httptest.NewRequest("PUT", "/depositories/other", nil), map[string]string{"depositoryId": "other"})
+	if v, err := snapshot(req); v != nil || err != nil {
+		t.Errorf("snapshot=%#v error=%v", v, err)
+	}
+}
+
 func TestDepository__overrideDepositoryStatusErr(t *testing.T) {
 	svc := admin.NewServer(":0")
 	go svc.Listen()
@@ -0,0 +1,50 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package depository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/moov-io/base/admin"
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/internal/secrets"
+
+	"github.com/go-kit/kit/log"
+)
+
+// RegisterPlaintextRoutes adds an admin endpoint to report on (GET) and clear (DELETE) plaintext
+// account numbers which have been verified against their encrypted values.
+func RegisterPlaintextRoutes(logger log.Logger, svc *admin.Server, repo *internal.SQLDepositoryRepo, keeper *secrets.StringKeeper) {
+	svc.AddHandler("/account-numbers/plaintext", managePlaintextAccountNumbers(logger, repo, keeper))
+}
+
+func managePlaintextAccountNumbers(logger log.Logger, repo *internal.SQLDepositoryRepo, keeper *secrets.StringKeeper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = internal.Wrap(logger, w, r)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		var dryRun bool
+		switch r.Method {
+		case "GET":
+			dryRun = true
+		case "DELETE":
+			logger.Log("depositories", "clearing plaintext account numbers", "requestID", moovhttp.GetRequestID(r))
+		default:
+			moovhttp.Problem(w, fmt.Errorf("unsupported HTTP verb: %s", r.Method))
+			return
+		}
+
+		report, err := internal.ClearPlaintextAccountNumbers(logger, repo, keeper, dryRun)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(report)
+	}
+}
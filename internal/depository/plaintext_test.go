@@ -0,0 +1,105 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package depository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/base/admin"
+	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestDepository__managePlaintextAccountNumbers(t *testing.T) {
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+
+	keeper := secrets.TestStringKeeper(t)
+	repo := internal.NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
+
+	// write a Depository with both plaintext and encrypted account numbers
+	encrypted, err := keeper.EncryptString("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dep := &internal.Depository{
+		ID:                     id.Depository(base.ID()),
+		RoutingNumber:          "987654320",
+		Type:                   internal.Checking,
+		BankName:               "bank name",
+		Holder:                 "holder",
+		HolderType:             internal.Individual,
+		Status:                 internal.DepositoryUnverified,
+		EncryptedAccountNumber: encrypted,
+		Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
+	}
+	if err := repo.UpsertUserDepository(context.Background(), id.User(base.ID()), dep); err != nil {
+		t.Fatal(err)
+	}
+	query := `update depositories set account_number_hashed = '8d969eef6ecad3c29a3a629280e686cf0c3f5d5a86aff3ca12020c923adc6c92' where depository_id = ?;`
+	if _, err := sqliteDB.DB.Exec(query, dep.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqliteDB.DB.Exec(`insert into plaintext_account_numbers (depository_id, account_number) values (?, '123456');`, dep.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterPlaintextRoutes(log.NewNopLogger(), svc, repo, keeper)
+
+	addr := fmt.Sprintf("http://%s/account-numbers/plaintext", svc.BindAddr())
+	do := func(method string) *internal.PlaintextAccountNumberReport {
+		t.Helper()
+
+		req, _ := http.NewRequest(method, addr, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("bogus HTTP status: %s", resp.Status)
+		}
+		var report internal.PlaintextAccountNumberReport
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		}
+		return &report
+	}
+
+	if report := do("GET"); !report.DryRun || report.Verified != 1 || report.Cleared != 0 {
+		t.Errorf("unexpected report: %#v", report)
+	}
+	if report := do("DELETE"); report.DryRun || report.Verified != 1 || report.Cleared != 1 {
+		t.Errorf("unexpected report: %#v", report)
+	}
+	if report := do("GET"); report.Scanned != 0 {
+		t.Errorf("unexpected report: %#v", report)
+	}
+
+	// invalid route
+	req, _ := http.NewRequest("POST", addr, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %s", resp.Status)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package depository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/moov-io/base/admin"
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal"
+
+	"github.com/go-kit/kit/log"
+)
+
+// RegisterRotationRoutes adds admin endpoints to start re-encrypting account numbers with the current
+// key version and to check on the progress of it.
+func RegisterRotationRoutes(logger log.Logger, svc *admin.Server, rotation *internal.AccountNumberRotation) {
+	svc.AddHandler("/account-numbers/rotation", manageAccountNumberRotation(logger, rotation))
+}
+
+func manageAccountNumberRotation(logger log.Logger, rotation *internal.AccountNumberRotation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = internal.Wrap(logger, w, r)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		requestID := moovhttp.GetRequestID(r)
+
+		switch r.Method {
+		case "GET":
+			w.WriteHeader(http.StatusOK)
+
+		case "POST":
+			if !rotation.Start() {
+				moovhttp.Problem(w, errors.New("account number rotation is already running"))
+				return
+			}
+			logger.Log("depositories", fmt.Sprintf("started account number rotation to key version %q", rotation.Progress().Version), "requestID", requestID)
+			w.WriteHeader(http.StatusAccepted)
+
+		default:
+			moovhttp.Problem(w, fmt.Errorf("unsupported HTTP verb: %s", r.Method))
+			return
+		}
+		json.NewEncoder(w).Encode(rotation.Progress())
+	}
+}
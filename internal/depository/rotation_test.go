@@ -0,0 +1,86 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package depository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base/admin"
+	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/secrets"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestDepository__manageAccountNumberRotation(t *testing.T) {
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+
+	keeper := secrets.TestStringKeeper(t)
+	repo := internal.NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
+	rotation := internal.NewAccountNumberRotation(log.NewNopLogger(), repo, keeper, 10)
+
+	RegisterRotationRoutes(log.NewNopLogger(), svc, rotation)
+
+	addr := fmt.Sprintf("http://%s/account-numbers/rotation", svc.BindAddr())
+	read := func(resp *http.Response) internal.AccountNumberRotationProgress {
+		t.Helper()
+		defer resp.Body.Close()
+
+		var progress internal.AccountNumberRotationProgress
+		if err := json.NewDecoder(resp.Body).Decode(&progress); err != nil {
+			t.Fatal(err)
+		}
+		return progress
+	}
+
+	resp, err := http.Post(addr, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("bogus HTTP status: %s", resp.Status)
+	}
+	if progress := read(resp); progress.StartedAt == nil {
+		t.Errorf("unexpected progress: %#v", progress)
+	}
+
+	for i := 0; i < 50; i++ {
+		resp, err := http.Get(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("bogus HTTP status: %s", resp.Status)
+		}
+		if progress := read(resp); !progress.Running {
+			if progress.FinishedAt == nil || progress.LastError != "" {
+				t.Errorf("unexpected progress: %#v", progress)
+			}
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// invalid route
+	req, _ := http.NewRequest("DELETE", addr, nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %s", resp.Status)
+	}
+}
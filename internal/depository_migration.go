@@ -5,9 +5,13 @@
 package internal
 
 import (
+	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/moov-io/paygate/internal/secrets"
@@ -16,20 +20,21 @@ import (
 	"github.com/go-kit/kit/log"
 )
 
-func EncryptStoredAccountNumbers(logger log.Logger, repo *SQLDepositoryRepo, keeper *secrets.StringKeeper) error {
-	var max time.Time
+func EncryptStoredAccountNumbers(ctx context.Context, logger log.Logger, repo *SQLDepositoryRepo, keeper *secrets.StringKeeper) error {
+	var cur migrationCursor
 	for {
-		rows, err := grabEncryptableDepositories(logger, repo, max, 100)
+		rows, err := grabEncryptableDepositories(logger, repo, cur, 100)
 		if err != nil {
 			return err
 		}
 		if len(rows) == 0 {
 			return nil // no more records, so done
 		} else {
-			max = rows[len(rows)-1].createdAt // update our next starting point
+			last := rows[len(rows)-1]
+			cur = migrationCursor{createdAt: last.createdAt, id: last.id} // update our next starting point
 		}
 		for i := range rows {
-			dep, err := repo.GetDepository(id.Depository(rows[i].id))
+			dep, err := repo.GetDepository(ctx, id.Depository(rows[i].id))
 			if err != nil {
 				return err
 			}
@@ -44,13 +49,26 @@ func EncryptStoredAccountNumbers(logger log.Logger, repo *SQLDepositoryRepo, kee
 				dep.hashedAccountNumber = hash
 			}
 
-			if err := repo.UpsertUserDepository(id.User(dep.UserID()), dep); err != nil {
+			if err := repo.UpsertUserDepository(ctx, id.User(dep.UserID()), dep); err != nil {
 				return err
 			}
 		}
 	}
 }
 
+// migrationCursor is where a batched migration left off. Rows are walked in (created_at, id) order
+// as created_at alone isn't unique, MySQL only stores whole seconds.
+type migrationCursor struct {
+	createdAt time.Time
+	id        string
+}
+
+// where returns a condition matching rows after cur, and its arguments, for the given columns.
+func (cur migrationCursor) where(createdAtColumn, idColumn string) (string, []interface{}) {
+	cond := fmt.Sprintf("(%s > ? or (%s = ? and %s > ?))", createdAtColumn, createdAtColumn, idColumn)
+	return cond, []interface{}{cur.createdAt, cur.createdAt, cur.id}
+}
+
 func hashAccountNumber(num string) (string, error) {
 	ss := sha256.New()
 	n, err := ss.Write([]byte(num))
@@ -66,17 +84,19 @@ type encryptableDepository struct {
 	createdAt     time.Time
 }
 
-func grabEncryptableDepositories(logger log.Logger, repo *SQLDepositoryRepo, newerThan time.Time, batchSize int) ([]encryptableDepository, error) {
-	query := `select depository_id, account_number, created_at from depositories
-where account_number <> '' and account_number_encrypted = '' and created_at > ?
-order by created_at asc limit ?;`
+func grabEncryptableDepositories(logger log.Logger, repo *SQLDepositoryRepo, cur migrationCursor, batchSize int) ([]encryptableDepository, error) {
+	after, args := cur.where("d.created_at", "d.depository_id")
+	query := fmt.Sprintf(`select d.depository_id, p.account_number, d.created_at from depositories as d
+inner join plaintext_account_numbers as p on d.depository_id = p.depository_id
+where d.account_number_encrypted = '' and %s
+order by d.created_at asc, d.depository_id asc limit ?;`, after)
 	stmt, err := repo.db.Prepare(query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(newerThan, batchSize)
+	rows, err := stmt.Query(append(args, batchSize)...)
 	if err != nil {
 		return nil, err
 	}
@@ -92,3 +112,352 @@ order by created_at asc limit ?;`
 	}
 	return out, rows.Err()
 }
+
+// AccountNumberRotation re-encrypts Depository account numbers, Originator identification and Receiver
+// email addresses written with a previous key version so the previous key can be retired. Each table
+// is walked in batches ordered by created_at and ID.
+type AccountNumberRotation struct {
+	logger    log.Logger
+	repo      *SQLDepositoryRepo
+	keeper    *secrets.StringKeeper
+	batchSize int
+
+	mu       sync.Mutex
+	progress AccountNumberRotationProgress
+}
+
+// AccountNumberRotationProgress reports on the current (or most recent) run of an AccountNumberRotation.
+type AccountNumberRotationProgress struct {
+	Running bool   `json:"running"`
+	Version string `json:"version"`
+
+	// Scanned is how many encrypted values have been checked, Rotated how many of them were re-encrypted
+	// and Failed how many couldn't be.
+	Scanned int `json:"scanned"`
+	Rotated int `json:"rotated"`
+	Failed  int `json:"failed"`
+
+	LastError  string     `json:"lastError,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+func NewAccountNumberRotation(logger log.Logger, repo *SQLDepositoryRepo, keeper *secrets.StringKeeper, batchSize int) *AccountNumberRotation {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &AccountNumberRotation{
+		logger:    logger,
+		repo:      repo,
+		keeper:    keeper,
+		batchSize: batchSize,
+	}
+}
+
+// Progress returns a copy of the rotation's current progress.
+func (rot *AccountNumberRotation) Progress() AccountNumberRotationProgress {
+	rot.mu.Lock()
+	defer rot.mu.Unlock()
+	return rot.progress
+}
+
+// Start begins a rotation in the background and returns false if one is already running.
+func (rot *AccountNumberRotation) Start() bool {
+	if !rot.begin() {
+		return false
+	}
+	go func() {
+		if err := rot.run(); err != nil {
+			rot.logger.Log("depositories", fmt.Sprintf("ERROR rotating account numbers: %v", err))
+		}
+	}()
+	return true
+}
+
+// Run re-encrypts every value not from the current key version and blocks until done.
+func (rot *AccountNumberRotation) Run() error {
+	if !rot.begin() {
+		return errors.New("account number rotation is already running")
+	}
+	return rot.run()
+}
+
+func (rot *AccountNumberRotation) begin() bool {
+	rot.mu.Lock()
+	defer rot.mu.Unlock()
+
+	if rot.progress.Running {
+		return false
+	}
+	now := time.Now()
+	rot.progress = AccountNumberRotationProgress{
+		Running:   true,
+		Version:   rot.keeper.Version(),
+		StartedAt: &now,
+	}
+	return true
+}
+
+// rotatedColumns are every column encrypted by the StringKeeper.
+var rotatedColumns = []encryptedColumn{
+	{table: "depositories", idColumn: "depository_id", column: "account_number_encrypted"},
+	{table: "originators", idColumn: "originator_id", column: "identification_encrypted"},
+	{table: "receivers", idColumn: "receiver_id", column: "email_encrypted"},
+}
+
+func (rot *AccountNumberRotation) run() error {
+	var err error
+	defer func() {
+		rot.mu.Lock()
+		now := time.Now()
+		rot.progress.Running = false
+		rot.progress.FinishedAt = &now
+		if err != nil {
+			rot.progress.LastError = err.Error()
+		}
+		p := rot.progress
+		rot.mu.Unlock()
+
+		rot.logger.Log("depositories", fmt.Sprintf("account number rotation to key version %q finished: scanned=%d rotated=%d failed=%d", p.Version, p.Scanned, p.Rotated, p.Failed))
+	}()
+
+	for i := range rotatedColumns {
+		if err = rot.runColumn(rotatedColumns[i]); err != nil {
+			return fmt.Errorf("rotating %s.%s: %v", rotatedColumns[i].table, rotatedColumns[i].column, err)
+		}
+	}
+	return nil
+}
+
+func (rot *AccountNumberRotation) runColumn(col encryptedColumn) error {
+	var cur migrationCursor
+	for {
+		rows, err := col.grabRotatable(rot.repo.db, cur, rot.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil // no more records, so done
+		}
+		last := rows[len(rows)-1]
+		cur = migrationCursor{createdAt: last.createdAt, id: last.id} // update our next starting point
+
+		for i := range rows {
+			rotated, rerr := rot.rotate(col, rows[i])
+			rot.mu.Lock()
+			rot.progress.Scanned++
+			if rerr != nil {
+				rot.progress.Failed++
+				rot.progress.LastError = fmt.Sprintf("%s %s=%s: %v", col.table, col.idColumn, rows[i].id, rerr)
+			}
+			if rotated {
+				rot.progress.Rotated++
+			}
+			rot.mu.Unlock()
+		}
+	}
+}
+
+func (rot *AccountNumberRotation) rotate(col encryptedColumn, row rotatableValue) (bool, error) {
+	if !rot.keeper.NeedsRotation(row.encrypted) {
+		return false, nil
+	}
+	encrypted, err := rot.keeper.RotateString(row.encrypted)
+	if err != nil {
+		return false, err
+	}
+	// Only replace the value we read so concurrent updates to the row aren't overwritten
+	query := fmt.Sprintf(`update %s set %s = ? where %s = ? and %s = ?;`, col.table, col.column, col.idColumn, col.column)
+	stmt, err := rot.repo.db.Prepare(query)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.Exec(encrypted, row.id, row.encrypted)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+// encryptedColumn is a column of values encrypted with the StringKeeper.
+type encryptedColumn struct {
+	table    string
+	idColumn string
+	column   string
+}
+
+type rotatableValue struct {
+	id        string
+	encrypted string
+	createdAt time.Time
+}
+
+// grabRotatable returns rows with an encrypted value, including deleted ones, so every value from a
+// previous key is rotated.
+func (col encryptedColumn) grabRotatable(db *sql.DB, cur migrationCursor, batchSize int) ([]rotatableValue, error) {
+	after, args := cur.where("created_at", col.idColumn)
+	query := fmt.Sprintf(`select %s, %s, created_at from %s
+where %s <> '' and %s
+order by created_at asc, %s asc limit ?;`, col.idColumn, col.column, col.table, col.column, after, col.idColumn)
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(append(args, batchSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []rotatableValue
+	for rows.Next() {
+		var row rotatableValue
+		if err := rows.Scan(&row.id, &row.encrypted, &row.createdAt); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// PlaintextAccountNumberReport summarizes a pass over Depositories which still have a plaintext account number.
+// The depositories.account_number column has been dropped and its non-empty values moved to plaintext_account_numbers.
+//
+// A plaintext value is only cleared once its encrypted copy decrypts back to the same value and its hash
+// matches. Rows which fail either check are left as-is and reported so an operator can investigate.
+type PlaintextAccountNumberReport struct {
+	DryRun bool `json:"dryRun"`
+
+	// Scanned is how many Depositories have a plaintext account number, Verified how many of them
+	// round-trip through their encrypted value and Cleared how many plaintext values were removed.
+	Scanned  int `json:"scanned"`
+	Verified int `json:"verified"`
+	Cleared  int `json:"cleared"`
+
+	// Unencrypted Depositories have no encrypted account number yet and Mismatched ones decrypt to
+	// a different value (or hash) than the plaintext column. Failed counts decryption errors.
+	Unencrypted []string `json:"unencrypted,omitempty"`
+	Mismatched  []string `json:"mismatched,omitempty"`
+	Failed      []string `json:"failed,omitempty"`
+}
+
+// ClearPlaintextAccountNumbers verifies every plaintext account number against its Depository's encrypted
+// and hashed values and deletes the plaintext of rows which match. With dryRun nothing is written.
+func ClearPlaintextAccountNumbers(logger log.Logger, repo *SQLDepositoryRepo, keeper *secrets.StringKeeper, dryRun bool) (*PlaintextAccountNumberReport, error) {
+	return clearPlaintextAccountNumbers(logger, repo, keeper, dryRun, 100)
+}
+
+func clearPlaintextAccountNumbers(logger log.Logger, repo *SQLDepositoryRepo, keeper *secrets.StringKeeper, dryRun bool, batchSize int) (*PlaintextAccountNumberReport, error) {
+	report := &PlaintextAccountNumberReport{DryRun: dryRun}
+
+	var cur migrationCursor
+	for {
+		rows, err := grabPlaintextDepositories(repo, cur, batchSize)
+		if err != nil {
+			return report, err
+		}
+		if len(rows) == 0 {
+			break // no more records, so done
+		}
+		last := rows[len(rows)-1]
+		cur = migrationCursor{createdAt: last.createdAt, id: last.id} // update our next starting point
+
+		for i := range rows {
+			report.Scanned++
+
+			row := rows[i]
+			if row.encryptedAccountNumber == "" {
+				report.Unencrypted = append(report.Unencrypted, row.id)
+				continue
+			}
+			num, err := keeper.DecryptString(row.encryptedAccountNumber)
+			if err != nil {
+				logger.Log("depositories", fmt.Sprintf("problem decrypting account number of depository=%s: %v", row.id, err))
+				report.Failed = append(report.Failed, row.id)
+				continue
+			}
+			hash, err := hashAccountNumber(row.accountNumber)
+			if err != nil {
+				return report, err
+			}
+			if num != row.accountNumber || hash != row.hashedAccountNumber {
+				report.Mismatched = append(report.Mismatched, row.id)
+				continue
+			}
+			report.Verified++
+
+			if dryRun {
+				continue
+			}
+			if cleared, err := clearPlaintextAccountNumber(repo, row); err != nil {
+				return report, err
+			} else if cleared {
+				report.Cleared++
+			}
+		}
+	}
+	logger.Log("depositories", fmt.Sprintf("plaintext account numbers (dryRun=%v): scanned=%d verified=%d cleared=%d unencrypted=%d mismatched=%d failed=%d",
+		dryRun, report.Scanned, report.Verified, report.Cleared, len(report.Unencrypted), len(report.Mismatched), len(report.Failed)))
+	return report, nil
+}
+
+type plaintextDepository struct {
+	id                     string
+	accountNumber          string
+	encryptedAccountNumber string
+	hashedAccountNumber    string
+	createdAt              time.Time
+}
+
+// grabPlaintextDepositories returns Depositories, including deleted ones, which still have a plaintext account number.
+func grabPlaintextDepositories(repo *SQLDepositoryRepo, cur migrationCursor, batchSize int) ([]plaintextDepository, error) {
+	after, args := cur.where("d.created_at", "d.depository_id")
+	query := fmt.Sprintf(`select d.depository_id, p.account_number, d.account_number_encrypted, d.account_number_hashed, d.created_at from depositories as d
+inner join plaintext_account_numbers as p on d.depository_id = p.depository_id
+where %s
+order by d.created_at asc, d.depository_id asc limit ?;`, after)
+	stmt, err := repo.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(append(args, batchSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []plaintextDepository
+	for rows.Next() {
+		var row plaintextDepository
+		if err := rows.Scan(&row.id, &row.accountNumber, &row.encryptedAccountNumber, &row.hashedAccountNumber, &row.createdAt); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func clearPlaintextAccountNumber(repo *SQLDepositoryRepo, row plaintextDepository) (bool, error) {
+	// Only clear the row if nothing we verified has changed since reading it
+	query := `delete from plaintext_account_numbers where depository_id = ? and account_number = ?
+and exists (select depository_id from depositories where depository_id = ? and account_number_encrypted = ? and account_number_hashed = ?);`
+	stmt, err := repo.db.Prepare(query)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.Exec(row.id, row.accountNumber, row.id, row.encryptedAccountNumber, row.hashedAccountNumber)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
@@ -5,7 +5,12 @@
 package internal
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,15 +23,21 @@ import (
 )
 
 func writeAccountNumber(t *testing.T, number string, depID id.Depository, db *sql.DB) {
-	query := `update depositories set account_number = ?, account_number_encrypted = '', account_number_hashed = '' where depository_id = ?;`
-	stmt, err := db.Prepare(query)
-	if err != nil {
+	query := `update depositories set account_number_encrypted = '', account_number_hashed = '' where depository_id = ?;`
+	if _, err := db.Exec(query, depID); err != nil {
 		t.Fatal(err)
 	}
-	defer stmt.Close()
+	writePlaintextAccountNumber(t, number, depID, db)
+}
+
+// writePlaintextAccountNumber stores a plaintext account number like the migration dropping depositories.account_number does
+func writePlaintextAccountNumber(t *testing.T, number string, depID id.Depository, db *sql.DB) {
+	t.Helper()
 
-	_, err = stmt.Exec(number, depID)
-	if err != nil {
+	if _, err := db.Exec(`delete from plaintext_account_numbers where depository_id = ?;`, depID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`insert into plaintext_account_numbers (depository_id, account_number) values (?, ?);`, depID, number); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -50,17 +61,17 @@ func TestDepository__grabEncryptableDepositories(t *testing.T) {
 			Status:        DepositoryUnverified,
 			Created:       base.NewTime(time.Now().Add(-1 * time.Second)),
 		}
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Fatal(err)
 		}
 
 		writeAccountNumber(t, "123456", depID, repo.db)
 
-		if err := EncryptStoredAccountNumbers(log.NewNopLogger(), repo, keeper); err != nil {
+		if err := EncryptStoredAccountNumbers(context.Background(), log.NewNopLogger(), repo, keeper); err != nil {
 			t.Fatal(err)
 		}
 
-		dep, err := repo.GetDepository(dep.ID)
+		dep, err := repo.GetDepository(context.Background(), dep.ID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -80,12 +91,378 @@ func TestDepository__grabEncryptableDepositories(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), mysqlDB.DB, keeper))
 }
 
+func TestDepository__AccountNumberRotation(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, db *sql.DB) {
+		// write account numbers with the untagged key
+		oldKeeper := secrets.TestStringKeeper(t)
+		oldRepo := NewDepositoryRepo(log.NewNopLogger(), db, oldKeeper)
+
+		// every Depository is created in the same second so batches have to be split by ID
+		userID, created := id.User(base.ID()), time.Now().Add(-5*time.Second).Truncate(time.Second)
+		var depIDs []id.Depository
+		for i := 0; i < 3; i++ {
+			dep := &Depository{
+				ID:            id.Depository(base.ID()),
+				RoutingNumber: "987654320",
+				Type:          Checking,
+				BankName:      "bank name",
+				Holder:        "holder",
+				HolderType:    Individual,
+				Status:        DepositoryUnverified,
+				Created:       base.NewTime(created),
+				keeper:        oldKeeper,
+			}
+			if err := dep.ReplaceAccountNumber(fmt.Sprintf("12345%d", i)); err != nil {
+				t.Fatal(err)
+			}
+			if err := oldRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+				t.Fatal(err)
+			}
+			depIDs = append(depIDs, dep.ID)
+		}
+
+		// and an Originator's identification and Receiver's email
+		originatorID, receiverID := base.ID(), base.ID()
+		identification, err := oldKeeper.EncryptString("123456789")
+		if err != nil {
+			t.Fatal(err)
+		}
+		email, err := oldKeeper.EncryptString("john@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`insert into originators (originator_id, user_id, identification_encrypted, created_at) values (?, ?, ?, ?);`, originatorID, userID, identification, time.Now().Add(-time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(`insert into receivers (receiver_id, user_id, email_encrypted, created_at) values (?, ?, ?, ?);`, receiverID, userID, email, time.Now().Add(-time.Second)); err != nil {
+			t.Fatal(err)
+		}
+
+		// rotate to a new key
+		newKey, _ := secrets.OpenLocal(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("2"), 32)))
+		keeper, err := secrets.NewVersionedStringKeeper(newKey, "v2", time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		previous, _ := secrets.OpenLocal("")
+		if err := keeper.AddPreviousKeeper("", previous); err != nil {
+			t.Fatal(err)
+		}
+		repo := NewDepositoryRepo(log.NewNopLogger(), db, keeper)
+
+		rotation := NewAccountNumberRotation(log.NewNopLogger(), repo, keeper, 2)
+		if err := rotation.Run(); err != nil {
+			t.Fatal(err)
+		}
+		progress := rotation.Progress()
+		if progress.Running || progress.Version != "v2" || progress.Scanned < 5 || progress.Rotated < 5 || progress.Failed != 0 {
+			t.Errorf("unexpected progress: %#v", progress)
+		}
+		if progress.StartedAt == nil || progress.FinishedAt == nil {
+			t.Errorf("unexpected progress: %#v", progress)
+		}
+
+		for i := range depIDs {
+			dep, err := repo.GetDepository(context.Background(), depIDs[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secrets.KeyVersion(dep.EncryptedAccountNumber) != "v2" {
+				t.Errorf("unexpected value %q", dep.EncryptedAccountNumber)
+			}
+			if num, err := keeper.DecryptString(dep.EncryptedAccountNumber); err != nil || num != fmt.Sprintf("12345%d", i) {
+				t.Errorf("num=%q error=%v", num, err)
+			}
+		}
+		readRotated := func(query, rowID, expected string) {
+			t.Helper()
+			var encrypted string
+			if err := db.QueryRow(query, rowID).Scan(&encrypted); err != nil {
+				t.Fatal(err)
+			}
+			if secrets.KeyVersion(encrypted) != "v2" {
+				t.Errorf("unexpected value %q", encrypted)
+			}
+			if value, err := keeper.DecryptString(encrypted); err != nil || value != expected {
+				t.Errorf("value=%q error=%v", value, err)
+			}
+		}
+		readRotated(`select identification_encrypted from originators where originator_id = ?;`, originatorID, "123456789")
+		readRotated(`select email_encrypted from receivers where receiver_id = ?;`, receiverID, "john@example.com")
+
+		// nothing to do on a second run
+		if err := rotation.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if progress := rotation.Progress(); progress.Rotated != 0 {
+			t.Errorf("unexpected progress: %#v", progress)
+		}
+	}
+
+	// SQLite
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, sqliteDB.DB)
+
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
+	// MySQL
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, mysqlDB.DB)
+}
+
+func TestDepository__AccountNumberRotationErr(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	keeper := secrets.TestStringKeeper(t)
+	repo := NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper)
+
+	dep := &Depository{
+		ID:                     id.Depository(base.ID()),
+		RoutingNumber:          "987654320",
+		Type:                   Checking,
+		BankName:               "bank name",
+		Holder:                 "holder",
+		HolderType:             Individual,
+		Status:                 DepositoryUnverified,
+		EncryptedAccountNumber: "v1:bm90IGVuY3J5cHRlZA==", // unknown key version
+		Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
+	}
+	if err := repo.UpsertUserDepository(context.Background(), id.User(base.ID()), dep); err != nil {
+		t.Fatal(err)
+	}
+
+	rotation := NewAccountNumberRotation(log.NewNopLogger(), repo, keeper, 0)
+	if err := rotation.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if progress := rotation.Progress(); progress.Failed != 1 || !strings.Contains(progress.LastError, "unknown key version") {
+		t.Errorf("unexpected progress: %#v", progress)
+	}
+
+	rotation.progress.Running = true
+	if err := rotation.Run(); err == nil {
+		t.Error("expected error")
+	}
+	if rotation.Start() {
+		t.Error("expected rotation to already be running")
+	}
+}
+
+func TestDepository__ClearPlaintextAccountNumbers(t *testing.T) {
+	t.Parallel()
+
+	readPlaintext := func(t *testing.T, db *sql.DB, depID id.Depository) string {
+		t.Helper()
+		var number string
+		err := db.QueryRow(`select account_number from plaintext_account_numbers where depository_id = ?;`, depID).Scan(&number)
+		if err != nil && err != sql.ErrNoRows {
+			t.Fatal(err)
+		}
+		return number
+	}
+
+	check := func(t *testing.T, db *sql.DB) {
+		keeper := secrets.TestStringKeeper(t)
+		repo := NewDepositoryRepo(log.NewNopLogger(), db, keeper)
+
+		userID := id.User(base.ID())
+		upsert := func(num string, encrypted string) id.Depository {
+			dep := &Depository{
+				ID:                     id.Depository(base.ID()),
+				RoutingNumber:          "987654320",
+				Type:                   Checking,
+				BankName:               "bank name",
+				Holder:                 "holder",
+				HolderType:             Individual,
+				Status:                 DepositoryUnverified,
+				EncryptedAccountNumber: encrypted,
+				Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
+				keeper:                 keeper,
+			}
+			if num != "" {
+				if err := dep.ReplaceAccountNumber(num); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+				t.Fatal(err)
+			}
+			return dep.ID
+		}
+
+		verified := upsert("123456", "")
+		writePlaintextAccountNumber(t, "123456", verified, db)
+
+		mismatched := upsert("123456", "")
+		writePlaintextAccountNumber(t, "654321", mismatched, db)
+
+		failed := upsert("", "v1:bm90IGVuY3J5cHRlZA==") // unknown key version
+		writePlaintextAccountNumber(t, "123456", failed, db)
+
+		unencrypted := upsert("", "")
+		writeAccountNumber(t, "123456", unencrypted, db)
+
+		// dry run doesn't clear anything
+		report, err := ClearPlaintextAccountNumbers(log.NewNopLogger(), repo, keeper, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.DryRun || report.Scanned != 4 || report.Verified != 1 || report.Cleared != 0 {
+			t.Errorf("unexpected report: %#v", report)
+		}
+		if len(report.Mismatched) != 1 || report.Mismatched[0] != mismatched.String() {
+			t.Errorf("mismatched: %v", report.Mismatched)
+		}
+		if len(report.Failed) != 1 || report.Failed[0] != failed.String() {
+			t.Errorf("failed: %v", report.Failed)
+		}
+		if len(report.Unencrypted) != 1 || report.Unencrypted[0] != unencrypted.String() {
+			t.Errorf("unencrypted: %v", report.Unencrypted)
+		}
+		if num := readPlaintext(t, db, verified); num != "123456" {
+			t.Errorf("dry run cleared account number: %q", num)
+		}
+
+		// only clear the verified row
+		report, err = ClearPlaintextAccountNumbers(log.NewNopLogger(), repo, keeper, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.DryRun || report.Scanned != 4 || report.Verified != 1 || report.Cleared != 1 {
+			t.Errorf("unexpected report: %#v", report)
+		}
+		if num := readPlaintext(t, db, verified); num != "" {
+			t.Errorf("expected cleared account number: %q", num)
+		}
+		for _, depID := range []id.Depository{mismatched, failed, unencrypted} {
+			if num := readPlaintext(t, db, depID); num == "" {
+				t.Errorf("depository=%s account number was cleared", depID)
+			}
+		}
+		dep, err := repo.GetDepository(context.Background(), verified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if num, err := dep.DecryptAccountNumber(); err != nil || num != "123456" {
+			t.Errorf("num=%q error=%v", num, err)
+		}
+
+		// cleared rows aren't scanned again
+		report, err = ClearPlaintextAccountNumbers(log.NewNopLogger(), repo, keeper, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Scanned != 3 || report.Cleared != 0 {
+			t.Errorf("unexpected report: %#v", report)
+		}
+	}
+
+	// SQLite
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, sqliteDB.DB)
+
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
+	// MySQL
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, mysqlDB.DB)
+}
+
+func TestDepository__ClearPlaintextAccountNumbersSameTime(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, db *sql.DB) {
+		keeper := secrets.TestStringKeeper(t)
+		repo := NewDepositoryRepo(log.NewNopLogger(), db, keeper)
+
+		// every Depository is created in the same second so batches have to be split by ID
+		userID, created := id.User(base.ID()), time.Now().Add(-5*time.Second).Truncate(time.Second)
+		var depIDs []id.Depository
+		for i := 0; i < 5; i++ {
+			dep := &Depository{
+				ID:            id.Depository(base.ID()),
+				RoutingNumber: "987654320",
+				Type:          Checking,
+				BankName:      "bank name",
+				Holder:        "holder",
+				HolderType:    Individual,
+				Status:        DepositoryUnverified,
+				Created:       base.NewTime(created),
+				keeper:        keeper,
+			}
+			if err := dep.ReplaceAccountNumber("123456"); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+				t.Fatal(err)
+			}
+			writePlaintextAccountNumber(t, "123456", dep.ID, db)
+			depIDs = append(depIDs, dep.ID)
+		}
+
+		report, err := clearPlaintextAccountNumbers(log.NewNopLogger(), repo, keeper, true, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Scanned != 5 || report.Verified != 5 {
+			t.Errorf("unexpected report: %#v", report)
+		}
+
+		report, err = clearPlaintextAccountNumbers(log.NewNopLogger(), repo, keeper, false, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Scanned != 5 || report.Cleared != 5 {
+			t.Errorf("unexpected report: %#v", report)
+		}
+		for i := range depIDs {
+			var n int
+			if err := db.QueryRow(`select count(*) from plaintext_account_numbers where depository_id = ?;`, depIDs[i]).Scan(&n); err != nil || n != 0 {
+				t.Errorf("depository=%s has %d plaintext account numbers: %v", depIDs[i], n, err)
+			}
+		}
+	}
+
+	// SQLite
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, sqliteDB.DB)
+
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
+	// MySQL
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, mysqlDB.DB)
+}
+
 func TestDepositories__hashAccountNumber(t *testing.T) {
 	if num, err := hashAccountNumber("1234"); err != nil {
 		t.Fatal(err)
@@ -20,7 +20,7 @@ type EventType string
 const (
 	// TODO(adam): more EventType values?
 	// ReceiverEvent   EventType = "Receiver"
-	// DepositoryEvent EventType = "Depository"
-	// OriginatorEvent EventType = "Originator"
-	TransferEvent EventType = "Transfer"
+	DepositoryEvent EventType = "Depository"
+	OriginatorEvent EventType = "Originator"
+	TransferEvent   EventType = "Transfer"
 )
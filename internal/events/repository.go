@@ -5,23 +5,25 @@
 package events
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/moov-io/paygate/internal/tracing"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
 )
 
 type Repository interface {
-	GetEvent(eventID EventID, userID id.User) (*Event, error)
-	GetUserEvents(userID id.User) ([]*Event, error)
+	GetEvent(ctx context.Context, eventID EventID, userID id.User) (*Event, error)
+	GetUserEvents(ctx context.Context, userID id.User) ([]*Event, error)
 
-	GetUserEventsByMetadata(userID id.User, metadata map[string]string) ([]*Event, error)
+	GetUserEventsByMetadata(ctx context.Context, userID id.User, metadata map[string]string) ([]*Event, error)
 
-	WriteEvent(userID id.User, event *Event) error
+	WriteEvent(ctx context.Context, userID id.User, event *Event) error
 }
 
 func NewRepo(logger log.Logger, db *sql.DB) *SQLRepository {
@@ -37,49 +39,54 @@ func (r *SQLRepository) Close() error {
 	return r.db.Close()
 }
 
-func (r *SQLRepository) WriteEvent(userID id.User, event *Event) error {
-	tx, err := r.db.Begin()
+func (r *SQLRepository) WriteEvent(ctx context.Context, userID id.User, event *Event) (err error) {
+	ctx, span := tracing.Start(ctx, "eventRepo.WriteEvent", "eventID", event.ID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("write event: begin: error=%v rollback=%v", err, tx.Rollback())
 	}
 
 	query := `insert into events (event_id, user_id, topic, message, type, created_at) values (?, ?, ?, ?, ?, ?)`
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("write event: prepare: error=%v rollback=%v", err, tx.Rollback())
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(event.ID, userID, event.Topic, event.Message, event.Type, time.Now())
+	_, err = stmt.ExecContext(ctx, event.ID, userID, event.Topic, event.Message, event.Type, time.Now())
 	if err != nil {
 		return fmt.Errorf("write event: exec: error=%v rollback=%v", err, tx.Rollback())
 	}
 
 	query = "insert into event_metadata (event_id, user_id, `key`, value) values (?, ?, ?, ?);"
-	stmt, err = tx.Prepare(query)
+	stmt, err = tx.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("write event: metadata prepare: error=%v rollback=%v", err, tx.Rollback())
 	}
 	defer stmt.Close()
 	for k, v := range event.Metadata {
-		if _, err := stmt.Exec(event.ID, userID, k, v); err != nil {
+		if _, err := stmt.ExecContext(ctx, event.ID, userID, k, v); err != nil {
 			return fmt.Errorf("write event metadata: error=%v rollback=%v", err, tx.Rollback())
 		}
 	}
 	return tx.Commit()
 }
 
-func (r *SQLRepository) GetEvent(eventID EventID, userID id.User) (*Event, error) {
+func (r *SQLRepository) GetEvent(ctx context.Context, eventID EventID, userID id.User) (*Event, error) {
 	query := `select event_id, topic, message, type from events
 where event_id = ? and user_id = ?
 limit 1`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(eventID, userID)
+	row := stmt.QueryRowContext(ctx, eventID, userID)
 
 	var event Event
 	if err := row.Scan(&event.ID, &event.Topic, &event.Message, &event.Type); err != nil {
@@ -91,19 +98,19 @@ limit 1`
 	if event.ID == "" {
 		return nil, nil // event not found
 	}
-	event.Metadata = r.getEventMetadata(event.ID)
+	event.Metadata = r.getEventMetadata(ctx, event.ID)
 	return &event, nil
 }
 
-func (r *SQLRepository) GetUserEvents(userID id.User) ([]*Event, error) {
+func (r *SQLRepository) GetUserEvents(ctx context.Context, userID id.User) ([]*Event, error) {
 	query := `select event_id from events where user_id = ?`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(userID)
+	rows, err := stmt.QueryContext(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +128,7 @@ func (r *SQLRepository) GetUserEvents(userID id.User) ([]*Event, error) {
 	}
 	var events []*Event
 	for i := range eventIDs {
-		event, err := r.GetEvent(EventID(eventIDs[i]), userID)
+		event, err := r.GetEvent(ctx, EventID(eventIDs[i]), userID)
 		if err == nil && event != nil {
 			events = append(events, event)
 		}
@@ -129,19 +136,19 @@ func (r *SQLRepository) GetUserEvents(userID id.User) ([]*Event, error) {
 	return events, rows.Err()
 }
 
-func (r *SQLRepository) GetUserEventsByMetadata(userID id.User, metadata map[string]string) ([]*Event, error) {
+func (r *SQLRepository) GetUserEventsByMetadata(ctx context.Context, userID id.User, metadata map[string]string) ([]*Event, error) {
 	query := `select distinct event_id from event_metadata where user_id = ?` + strings.Repeat(` and key = ? and value = ?`, len(metadata))
 	var args = []interface{}{userID.String()}
 	for k, v := range metadata {
 		args = append(args, k, v)
 	}
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("get events by metadata: prepare: %v", err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(args...)
+	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get events by metadata: query: %v", err)
 	}
@@ -153,7 +160,7 @@ func (r *SQLRepository) GetUserEventsByMetadata(userID id.User, metadata map[str
 		if err := rows.Scan(&id); err != nil {
 			return nil, fmt.Errorf("get events by metadata: scan: %v", err)
 		}
-		if evt, err := r.GetEvent(EventID(id), userID); err != nil {
+		if evt, err := r.GetEvent(ctx, EventID(id), userID); err != nil {
 			return nil, fmt.Errorf("get events by metadata: get: %v", err)
 		} else {
 			events = append(events, evt)
@@ -162,15 +169,15 @@ func (r *SQLRepository) GetUserEventsByMetadata(userID id.User, metadata map[str
 	return events, nil
 }
 
-func (r *SQLRepository) getEventMetadata(eventID EventID) map[string]string {
+func (r *SQLRepository) getEventMetadata(ctx context.Context, eventID EventID) map[string]string {
 	query := "select `key`, value from event_metadata where event_id = ?;"
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(eventID)
+	rows, err := stmt.QueryContext(ctx, eventID)
 	if err != nil {
 		return nil
 	}
@@ -5,6 +5,7 @@
 package events
 
 import (
+	"context"
 	"testing"
 
 	"github.com/moov-io/base"
@@ -22,10 +23,10 @@ func TestSQLRepository(t *testing.T) {
 
 		eventID, userID := EventID(base.ID()), id.User(base.ID())
 
-		if event, err := repo.GetEvent(eventID, userID); event != nil || err != nil {
+		if event, err := repo.GetEvent(context.Background(), eventID, userID); event != nil || err != nil {
 			t.Fatalf("expected nil event=%v: %v", event, err)
 		}
-		if events, err := repo.GetUserEvents(userID); len(events) != 0 || err != nil {
+		if events, err := repo.GetUserEvents(context.Background(), userID); len(events) != 0 || err != nil {
 			t.Fatalf("expected nil events=%v: %v", events, err)
 		}
 
@@ -40,11 +41,11 @@ func TestSQLRepository(t *testing.T) {
 			Type:     TransferEvent,
 			Metadata: metadata,
 		}
-		if err := repo.WriteEvent(userID, evt); err != nil {
+		if err := repo.WriteEvent(context.Background(), userID, evt); err != nil {
 			t.Fatal(err)
 		}
 
-		if event, err := repo.GetEvent(eventID, userID); event == nil || err != nil {
+		if event, err := repo.GetEvent(context.Background(), eventID, userID); event == nil || err != nil {
 			t.Fatalf("expected nil event=%v: %v", event, err)
 		} else {
 			if event.ID != eventID {
@@ -54,7 +55,7 @@ func TestSQLRepository(t *testing.T) {
 				t.Errorf("transferID=%s", event.Metadata["transferID"])
 			}
 		}
-		if events, err := repo.GetUserEvents(userID); len(events) != 1 || err != nil {
+		if events, err := repo.GetUserEvents(context.Background(), userID); len(events) != 1 || err != nil {
 			t.Fatalf("expected nil events=%v: %v", events, err)
 		} else {
 			if events[0].ID != eventID {
@@ -71,6 +72,11 @@ func TestSQLRepository(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewRepo(log.NewNopLogger(), sqliteDB.DB))
 
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewRepo(log.NewNopLogger(), postgresDB.DB))
+
 	// MySQL
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
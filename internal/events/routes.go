@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/route"
 
 	"github.com/go-kit/kit/log"
@@ -16,8 +17,8 @@ import (
 )
 
 func AddRoutes(logger log.Logger, r *mux.Router, eventRepo Repository) {
-	r.Methods("GET").Path("/events").HandlerFunc(getUserEvents(logger, eventRepo))
-	r.Methods("GET").Path("/events/{eventID}").HandlerFunc(getEventHandler(logger, eventRepo))
+	r.Methods("GET").Path("/events").HandlerFunc(auth.Require(auth.EventsRead, getUserEvents(logger, eventRepo)))
+	r.Methods("GET").Path("/events/{eventID}").HandlerFunc(auth.Require(auth.EventsRead, getEventHandler(logger, eventRepo)))
 }
 
 func getUserEvents(logger log.Logger, eventRepo Repository) http.HandlerFunc {
@@ -27,7 +28,7 @@ func getUserEvents(logger log.Logger, eventRepo Repository) http.HandlerFunc {
 			return
 		}
 
-		events, err := eventRepo.GetUserEvents(responder.XUserID)
+		events, err := eventRepo.GetUserEvents(r.Context(), responder.XUserID)
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
@@ -54,7 +55,7 @@ func getEventHandler(logger log.Logger, eventRepo Repository) http.HandlerFunc {
 		}
 
 		// grab event
-		event, err := eventRepo.GetEvent(eventID, responder.XUserID)
+		event, err := eventRepo.GetEvent(r.Context(), eventID, responder.XUserID)
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
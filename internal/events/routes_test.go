@@ -5,6 +5,7 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,11 +14,11 @@ import (
 	"testing"
 
 	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
-	"github.com/gorilla/mux"
 )
 
 func TestEvents__getUserEvents(t *testing.T) {
@@ -31,11 +32,11 @@ func TestEvents__getUserEvents(t *testing.T) {
 			Message: "This is a test",
 			Type:    "TestEvent",
 		}
-		if err := repo.WriteEvent(userID, event); err != nil {
+		if err := repo.WriteEvent(context.Background(), userID, event); err != nil {
 			t.Fatal(err)
 		}
 
-		router := mux.NewRouter()
+		router := auth.NewTestRouter()
 		AddRoutes(log.NewNopLogger(), router, repo)
 
 		req, _ := http.NewRequest("GET", "/events", nil)
@@ -66,6 +67,11 @@ func TestEvents__getUserEvents(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewRepo(log.NewNopLogger(), sqliteDB.DB))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewRepo(log.NewNopLogger(), postgresDB.DB))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -83,11 +89,11 @@ func TestEvents__getEvent(t *testing.T) {
 			Message: "This is a test",
 			Type:    "TestEvent",
 		}
-		if err := repo.WriteEvent(userID, event); err != nil {
+		if err := repo.WriteEvent(context.Background(), userID, event); err != nil {
 			t.Fatal(err)
 		}
 
-		router := mux.NewRouter()
+		router := auth.NewTestRouter()
 		AddRoutes(log.NewNopLogger(), router, repo)
 
 		req, _ := http.NewRequest("GET", fmt.Sprintf("/events/%s", event.ID), nil)
@@ -115,6 +121,11 @@ func TestEvents__getEvent(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewRepo(log.NewNopLogger(), sqliteDB.DB))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewRepo(log.NewNopLogger(), postgresDB.DB))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -134,19 +145,19 @@ func TestEvents__metadata(t *testing.T) {
 		},
 	}
 
-	events, err := repo.GetUserEventsByMetadata(userID, metadata)
+	events, err := repo.GetUserEventsByMetadata(context.Background(), userID, metadata)
 	if events == nil || err != nil {
 		t.Fatal(err)
 	}
 
 	repo.Event = nil
-	events, err = repo.GetUserEventsByMetadata(userID, metadata)
+	events, err = repo.GetUserEventsByMetadata(context.Background(), userID, metadata)
 	if events != nil || err != nil {
 		t.Fatal(err)
 	}
 
 	repo.Err = errors.New("bad error")
-	events, err = repo.GetUserEventsByMetadata(userID, metadata)
+	events, err = repo.GetUserEventsByMetadata(context.Background(), userID, metadata)
 	if events != nil || err == nil {
 		t.Error("expected error")
 	}
@@ -155,7 +166,7 @@ func TestEvents__metadata(t *testing.T) {
 func TestEvents__errors(t *testing.T) {
 	repo := &TestRepository{Err: errors.New("bad error")}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddRoutes(log.NewNopLogger(), router, repo)
 
 	req, _ := http.NewRequest("GET", "/events", nil)
@@ -5,6 +5,7 @@
 package events
 
 import (
+	"context"
 	"github.com/moov-io/paygate/pkg/id"
 )
 
@@ -13,14 +14,14 @@ type TestRepository struct {
 	Event *Event
 }
 
-func (r *TestRepository) GetEvent(eventID EventID, userID id.User) (*Event, error) {
+func (r *TestRepository) GetEvent(ctx context.Context, eventID EventID, userID id.User) (*Event, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
 	return r.Event, nil
 }
 
-func (r *TestRepository) GetUserEvents(userID id.User) ([]*Event, error) {
+func (r *TestRepository) GetUserEvents(ctx context.Context, userID id.User) ([]*Event, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -30,11 +31,11 @@ func (r *TestRepository) GetUserEvents(userID id.User) ([]*Event, error) {
 	return nil, nil
 }
 
-func (r *TestRepository) WriteEvent(userID id.User, event *Event) error {
+func (r *TestRepository) WriteEvent(ctx context.Context, userID id.User, event *Event) error {
 	return r.Err
 }
 
-func (r *TestRepository) GetUserEventsByMetadata(userID id.User, metadata map[string]string) ([]*Event, error) {
+func (r *TestRepository) GetUserEventsByMetadata(ctx context.Context, userID id.User, metadata map[string]string) ([]*Event, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
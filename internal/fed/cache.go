@@ -0,0 +1,83 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package fed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// NewCachedClient wraps a Client and keeps successful routing number lookups for ttl.
+//
+// When the FED service can't be reached an expired lookup is returned instead of the error,
+// so an outage only blocks routing numbers we've never seen. Routing numbers FED reports
+// as missing are not cached.
+func NewCachedClient(logger log.Logger, client Client, ttl time.Duration) Client {
+	if ttl <= 0 {
+		return client
+	}
+	return &cachedClient{
+		underlying: client,
+		logger:     logger,
+		ttl:        ttl,
+		entries:    make(map[string]*cacheEntry),
+	}
+}
+
+type cachedClient struct {
+	underlying Client
+	logger     log.Logger
+
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	participant *Participant
+	expiresAt   time.Time
+}
+
+func (c *cachedClient) Ping(ctx context.Context) error {
+	return c.underlying.Ping(ctx)
+}
+
+func (c *cachedClient) LookupRoutingNumber(ctx context.Context, routingNumber string) (*Participant, error) {
+	c.mu.Lock()
+	entry, exists := c.entries[routingNumber]
+	c.mu.Unlock()
+
+	if exists && time.Now().Before(entry.expiresAt) {
+		return entry.participant, nil
+	}
+
+	participant, err := c.underlying.LookupRoutingNumber(ctx, routingNumber)
+	if err != nil {
+		if exists && err != ErrParticipantNotFound {
+			c.logger.Log("fed", fmt.Sprintf("using expired lookup of %s: %v", routingNumber, err))
+			return entry.participant, nil
+		}
+		if err == ErrParticipantNotFound {
+			c.mu.Lock()
+			delete(c.entries, routingNumber)
+			c.mu.Unlock()
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[routingNumber] = &cacheEntry{
+		participant: participant,
+		expiresAt:   time.Now().Add(c.ttl),
+	}
+	c.mu.Unlock()
+
+	return participant, nil
+}
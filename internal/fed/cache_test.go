@@ -0,0 +1,72 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package fed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+type countingClient struct {
+	TestClient
+	lookups int
+}
+
+func (c *countingClient) LookupRoutingNumber(ctx context.Context, routingNumber string) (*Participant, error) {
+	c.lookups++
+	return c.TestClient.LookupRoutingNumber(ctx, routingNumber)
+}
+
+func TestFED__cachedClient(t *testing.T) {
+	underlying := &countingClient{
+		TestClient: TestClient{Participant: &Participant{RoutingNumber: "121042882", Name: "WELLS FARGO BANK NA", AcceptsACH: true}},
+	}
+	client := NewCachedClient(log.NewNopLogger(), underlying, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if p, err := client.LookupRoutingNumber(context.Background(), "121042882"); err != nil || p.Name != "WELLS FARGO BANK NA" {
+			t.Fatalf("participant=%#v error=%v", p, err)
+		}
+	}
+	if underlying.lookups != 1 {
+		t.Errorf("got %d lookups", underlying.lookups)
+	}
+
+	// expire our entry, FED is down so it's returned anyway
+	cc := client.(*cachedClient)
+	cc.entries["121042882"].expiresAt = time.Now().Add(-1 * time.Second)
+	underlying.Err = errors.New("connection refused")
+	if p, err := client.LookupRoutingNumber(context.Background(), "121042882"); err != nil || p == nil {
+		t.Errorf("participant=%#v error=%v", p, err)
+	}
+	if underlying.lookups != 2 {
+		t.Errorf("got %d lookups", underlying.lookups)
+	}
+
+	// routing numbers we haven't seen fail
+	if _, err := client.LookupRoutingNumber(context.Background(), "231380104"); err == nil {
+		t.Error("expected error")
+	}
+
+	// routing numbers FED no longer has are dropped
+	underlying.Err = ErrParticipantNotFound
+	if _, err := client.LookupRoutingNumber(context.Background(), "121042882"); err != ErrParticipantNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, exists := cc.entries["121042882"]; exists {
+		t.Error("expected entry to be removed")
+	}
+}
+
+func TestFED__NewCachedClient(t *testing.T) {
+	underlying := &TestClient{}
+	if client := NewCachedClient(log.NewNopLogger(), underlying, 0*time.Second); client != underlying {
+		t.Errorf("expected uncached client: %T", client)
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
+	"strings"
 
 	"github.com/moov-io/base/http/bind"
 	"github.com/moov-io/base/k8s"
@@ -19,9 +19,59 @@ import (
 	"github.com/go-kit/kit/log"
 )
 
+var (
+	// ErrParticipantNotFound is returned when a routing number isn't in the FEDACH directory.
+	ErrParticipantNotFound = errors.New("no ACH participants found")
+)
+
 type Client interface {
-	Ping() error
-	LookupRoutingNumber(routingNumber string) error
+	Ping(ctx context.Context) error
+
+	// LookupRoutingNumber returns the FEDACH participant for routingNumber or ErrParticipantNotFound.
+	LookupRoutingNumber(ctx context.Context, routingNumber string) (*Participant, error)
+}
+
+// Participant is a financial institution from the FEDACH directory.
+type Participant struct {
+	RoutingNumber string
+
+	// NewRoutingNumber is set when the institution has merged or been renumbered.
+	NewRoutingNumber string
+
+	// Name is the institution's name as the Federal Reserve has it.
+	Name        string
+	Address     Address
+	PhoneNumber string
+
+	// AcceptsACH is true when the institution receives government and commercial ACH entries.
+	AcceptsACH bool
+}
+
+type Address struct {
+	Address    string
+	City       string
+	State      string
+	PostalCode string
+}
+
+func convertParticipant(p moovfed.AchParticipant) *Participant {
+	postalCode := p.AchLocation.PostalCode
+	if p.AchLocation.PostalExtension != "" {
+		postalCode = fmt.Sprintf("%s-%s", postalCode, p.AchLocation.PostalExtension)
+	}
+	return &Participant{
+		RoutingNumber:    p.RoutingNumber,
+		NewRoutingNumber: p.NewRoutingNumber,
+		Name:             strings.TrimSpace(p.CustomerName),
+		Address: Address{
+			Address:    strings.TrimSpace(p.AchLocation.Address),
+			City:       strings.TrimSpace(p.AchLocation.City),
+			State:      p.AchLocation.State,
+			PostalCode: postalCode,
+		},
+		PhoneNumber: p.PhoneNumber,
+		AcceptsACH:  p.StatusCode == "1",
+	}
 }
 
 type moovClient struct {
@@ -29,11 +79,7 @@ type moovClient struct {
 	logger     log.Logger
 }
 
-func (c *moovClient) Ping() error {
-	// create a context just for this so ping requests don't require the setup of one
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) Ping(ctx context.Context) error {
 	resp, err := c.underlying.FEDApi.Ping(ctx)
 	if resp != nil && resp.Body != nil {
 		resp.Body.Close()
@@ -47,11 +93,7 @@ func (c *moovClient) Ping() error {
 	return err
 }
 
-func (c *moovClient) LookupRoutingNumber(routingNumber string) error {
-	// create a context just for this so ping requests don't require the setup of one
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancelFn()
-
+func (c *moovClient) LookupRoutingNumber(ctx context.Context, routingNumber string) (*Participant, error) {
 	achDict, resp, err := c.underlying.FEDApi.SearchFEDACH(ctx, &moovfed.SearchFEDACHOpts{
 		RoutingNumber: optional.NewString(routingNumber),
 	})
@@ -59,17 +101,17 @@ func (c *moovClient) LookupRoutingNumber(routingNumber string) error {
 		resp.Body.Close()
 	}
 	if resp == nil {
-		return fmt.Errorf("FED ping failed: %v", err)
+		return nil, fmt.Errorf("FED lookup failed: %v", err)
 	}
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return fmt.Errorf("FED ping got status: %s", resp.Status)
+		return nil, fmt.Errorf("FED lookup got status: %s", resp.Status)
 	}
 	for i := range achDict.ACHParticipants {
 		if achDict.ACHParticipants[i].RoutingNumber == routingNumber {
-			return nil // found match
+			return convertParticipant(achDict.ACHParticipants[i]), nil // found match
 		}
 	}
-	return errors.New("no ACH participants found")
+	return nil, ErrParticipantNotFound
 }
 
 func NewClient(logger log.Logger, endpoint string, httpClient *http.Client) Client {
@@ -5,6 +5,7 @@
 package fed
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -22,7 +23,7 @@ func TestFED(t *testing.T) {
 	}))
 
 	client := NewClient(log.NewNopLogger(), svc.URL, nil)
-	if err := client.Ping(); err != nil {
+	if err := client.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 	svc.Close()
@@ -34,13 +35,25 @@ func TestFED(t *testing.T) {
 		}
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"achParticipants": [{"routingNumber": "121042882"}]}`)) // partial fed.AchDictionary response
+		// partial fed.AchDictionary response
+		w.Write([]byte(`{"achParticipants": [{"routingNumber": "121042882", "customerName": "WELLS FARGO BANK NA  ", "statusCode": "1",
+"achLocation": {"address": "255 2ND AVE SOUTH", "city": "MINNEAPOLIS", "state": "MN", "postalCode": "55479", "postalExtension": "0000"}}]}`))
 	}))
 
 	client = NewClient(log.NewNopLogger(), svc.URL, nil)
-	if err := client.LookupRoutingNumber("121042882"); err != nil {
+	participant, err := client.LookupRoutingNumber(context.Background(), "121042882")
+	if err != nil {
 		t.Fatal(err)
 	}
+	if participant.Name != "WELLS FARGO BANK NA" || !participant.AcceptsACH {
+		t.Errorf("unexpected participant: %#v", participant)
+	}
+	if participant.Address.City != "MINNEAPOLIS" || participant.Address.PostalCode != "55479-0000" {
+		t.Errorf("unexpected address: %#v", participant.Address)
+	}
+	if _, err := client.LookupRoutingNumber(context.Background(), "231380104"); err != ErrParticipantNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
 	svc.Close()
 }
 
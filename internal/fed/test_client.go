@@ -4,14 +4,28 @@
 
 package fed
 
+import (
+	"context"
+)
+
 type TestClient struct {
+	// Participant is returned from LookupRoutingNumber. When nil an unnamed
+	// participant which accepts ACH is returned.
+	Participant *Participant
+
 	Err error
 }
 
-func (c *TestClient) Ping() error {
+func (c *TestClient) Ping(ctx context.Context) error {
 	return c.Err
 }
 
-func (c *TestClient) LookupRoutingNumber(routingNumber string) error {
-	return c.Err
+func (c *TestClient) LookupRoutingNumber(ctx context.Context, routingNumber string) (*Participant, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	if c.Participant != nil {
+		return c.Participant, nil
+	}
+	return &Participant{RoutingNumber: routingNumber, AcceptsACH: true}, nil
 }
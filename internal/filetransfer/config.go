@@ -6,6 +6,7 @@ package filetransfer
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/moov-io/base/admin"
 	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/tracing"
 
 	"github.com/go-kit/kit/log"
 	"github.com/gorilla/mux"
@@ -30,42 +32,39 @@ var (
 )
 
 type Repository interface {
-	GetConfigs() ([]*Config, error)
-	upsertConfig(cfg *Config) error
-	deleteConfig(routingNumber string) error
+	GetConfigs(ctx context.Context) ([]*Config, error)
+	upsertConfig(ctx context.Context, cfg *Config) error
+	deleteConfig(ctx context.Context, routingNumber string) error
 
-	GetCutoffTimes() ([]*CutoffTime, error)
-	upsertCutoffTime(routingNumber string, cutoff int, loc *time.Location) error
-	deleteCutoffTime(routingNumber string) error
+	GetCutoffTimes(ctx context.Context) ([]*CutoffTime, error)
+	upsertCutoffTime(ctx context.Context, routingNumber string, cutoff int, loc *time.Location) error
+	deleteCutoffTime(ctx context.Context, routingNumber string) error
 
-	GetFTPConfigs() ([]*FTPConfig, error)
-	upsertFTPConfigs(routingNumber, host, user, pass string) error
-	deleteFTPConfig(routingNumber string) error
+	GetFTPConfigs(ctx context.Context) ([]*FTPConfig, error)
+	upsertFTPConfigs(ctx context.Context, routingNumber, host, user, pass string) error
+	deleteFTPConfig(ctx context.Context, routingNumber string) error
 
-	GetSFTPConfigs() ([]*SFTPConfig, error)
-	upsertSFTPConfigs(routingNumber, host, user, pass, privateKey, publicKey string) error
-	deleteSFTPConfig(routingNumber string) error
+	GetSFTPConfigs(ctx context.Context) ([]*SFTPConfig, error)
+	upsertSFTPConfigs(ctx context.Context, routingNumber, host, user, pass, privateKey, publicKey string) error
+	deleteSFTPConfig(ctx context.Context, routingNumber string) error
 
 	Close() error
 }
 
-func NewRepository(filepath string, db *sql.DB, dbType string) Repository {
+// NewRepository returns a Repository backed by the database, or hardcoded local development values
+// when db is nil or empty. Use NewFileRepository for configs read from a file.
+func NewRepository(db *sql.DB, dbType string) Repository {
 	if db == nil {
 		repo := &staticRepository{}
 		repo.populate()
 		return repo
 	}
 
-	// If we've got a config from a file on the filesystem let's use that
-	if filepath != "" {
-		repo, _ := readConfigFile(filepath)
-		return repo
-	}
-
 	sqliteRepo := &sqlRepository{db}
 
-	if strings.EqualFold(dbType, "mysql") {
-		// On 'mysql' database setups return that over the local (hardcoded) values.
+	switch strings.ToLower(dbType) {
+	case "mysql", "postgres", "postgresql":
+		// On 'mysql' and 'postgres' database setups return that over the local (hardcoded) values.
 		return sqliteRepo
 	}
 
@@ -107,16 +106,16 @@ func (r *sqlRepository) GetCounts() (int, int, int) {
 	return count("cutoff_times"), count("ftp_configs"), count("file_transfer_configs")
 }
 
-func (r *sqlRepository) GetConfigs() ([]*Config, error) {
+func (r *sqlRepository) GetConfigs(ctx context.Context) ([]*Config, error) {
 	query := `select routing_number, inbound_path, outbound_path, return_path, outbound_filename_template, allowed_ips from file_transfer_configs;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
 	var configs []*Config
-	rows, err := stmt.Query()
+	rows, err := stmt.QueryContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -131,16 +130,16 @@ func (r *sqlRepository) GetConfigs() ([]*Config, error) {
 	return configs, rows.Err()
 }
 
-func (r *sqlRepository) GetCutoffTimes() ([]*CutoffTime, error) {
+func (r *sqlRepository) GetCutoffTimes(ctx context.Context) ([]*CutoffTime, error) {
 	query := `select routing_number, cutoff, location from cutoff_times;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
 	var times []*CutoffTime
-	rows, err := stmt.Query()
+	rows, err := stmt.QueryContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -161,17 +160,44 @@ func (r *sqlRepository) GetCutoffTimes() ([]*CutoffTime, error) {
 	return times, rows.Err()
 }
 
-func exec(db *sql.DB, rawQuery string, args ...interface{}) error {
-	stmt, err := db.Prepare(rawQuery)
+func exec(ctx context.Context, db *sql.DB, rawQuery string, args ...interface{}) error {
+	stmt, err := db.PrepareContext(ctx, rawQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, args...)
+	return err
+}
+
+// replace deletes the routing number's row from table before inserting a new one with rawQuery.
+// This works like "replace into", which PostgreSQL doesn't support.
+func replace(ctx context.Context, tx *sql.Tx, table string, routingNumber string, rawQuery string, args ...interface{}) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`delete from %s where routing_number = ?;`, table), routingNumber); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, rawQuery)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(args...)
+	_, err = stmt.ExecContext(ctx, args...)
 	return err
 }
 
+func replaceRow(ctx context.Context, db *sql.DB, table string, routingNumber string, rawQuery string, args ...interface{}) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := replace(ctx, tx, table, routingNumber, rawQuery, args...); err != nil {
+		return fmt.Errorf("error replacing %s row: error=%v rollback=%v", table, err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
 func (r *sqlRepository) getOutboundFilenameTemplates() ([]string, error) {
 	query := `select outbound_filename_template from file_transfer_configs where outbound_filename_template <> '';`
 	stmt, err := r.db.Prepare(query)
@@ -197,36 +223,56 @@ func (r *sqlRepository) getOutboundFilenameTemplates() ([]string, error) {
 	return templates, rows.Err()
 }
 
-func (r *sqlRepository) upsertConfig(cfg *Config) error {
-	query := `replace into file_transfer_configs (routing_number, inbound_path, outbound_path, return_path, outbound_filename_template) values (?, ?, ?, ?, ?);`
-	return exec(r.db, query, cfg.RoutingNumber, cfg.InboundPath, cfg.OutboundPath, cfg.ReturnPath, cfg.OutboundFilenameTemplate)
+func (r *sqlRepository) upsertConfig(ctx context.Context, cfg *Config) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.upsertConfig", "routingNumber", cfg.RoutingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `insert into file_transfer_configs (routing_number, inbound_path, outbound_path, return_path, outbound_filename_template) values (?, ?, ?, ?, ?);`
+	return replaceRow(ctx, r.db, "file_transfer_configs", cfg.RoutingNumber, query, cfg.RoutingNumber, cfg.InboundPath, cfg.OutboundPath, cfg.ReturnPath, cfg.OutboundFilenameTemplate)
 }
 
-func (r *sqlRepository) deleteConfig(routingNumber string) error {
+func (r *sqlRepository) deleteConfig(ctx context.Context, routingNumber string) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.deleteConfig", "routingNumber", routingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `delete from file_transfer_configs where routing_number = ?;`
-	return exec(r.db, query, routingNumber)
+	return exec(ctx, r.db, query, routingNumber)
 }
 
-func (r *sqlRepository) upsertCutoffTime(routingNumber string, cutoff int, loc *time.Location) error {
-	query := `replace into cutoff_times (routing_number, cutoff, location) values (?, ?, ?);`
-	return exec(r.db, query, routingNumber, cutoff, loc.String())
+func (r *sqlRepository) upsertCutoffTime(ctx context.Context, routingNumber string, cutoff int, loc *time.Location) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.upsertCutoffTime", "routingNumber", routingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `insert into cutoff_times (routing_number, cutoff, location) values (?, ?, ?);`
+	return replaceRow(ctx, r.db, "cutoff_times", routingNumber, query, routingNumber, cutoff, loc.String())
 }
 
-func (r *sqlRepository) deleteCutoffTime(routingNumber string) error {
+func (r *sqlRepository) deleteCutoffTime(ctx context.Context, routingNumber string) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.deleteCutoffTime", "routingNumber", routingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `delete from cutoff_times where routing_number = ?;`
-	return exec(r.db, query, routingNumber)
+	return exec(ctx, r.db, query, routingNumber)
 }
 
-func (r *sqlRepository) GetFTPConfigs() ([]*FTPConfig, error) {
+func (r *sqlRepository) GetFTPConfigs(ctx context.Context) ([]*FTPConfig, error) {
 	query := `select routing_number, hostname, username, password from ftp_configs;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
 	var configs []*FTPConfig
-	rows, err := stmt.Query()
+	rows, err := stmt.QueryContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -241,19 +287,24 @@ func (r *sqlRepository) GetFTPConfigs() ([]*FTPConfig, error) {
 	return configs, rows.Err()
 }
 
-func (r *sqlRepository) upsertFTPConfigs(routingNumber, host, user, pass string) error {
-	tx, err := r.db.Begin()
+func (r *sqlRepository) upsertFTPConfigs(ctx context.Context, routingNumber, host, user, pass string) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.upsertFTPConfigs", "routingNumber", routingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	stmt, err := tx.Prepare(`select password from ftp_configs where routing_number = ? limit 1;`)
+	stmt, err := tx.PrepareContext(ctx, `select password from ftp_configs where routing_number = ? limit 1;`)
 	if err != nil {
 		return fmt.Errorf("error reading existing password: error=%v rollback=%v", err, tx.Rollback())
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(routingNumber)
+	row := stmt.QueryRowContext(ctx, routingNumber)
 	var existingPass string
 	if err := row.Scan(&existingPass); err != nil {
 		return fmt.Errorf("error scanning existing password: error=%v rollback=%v", err, tx.Rollback())
@@ -262,34 +313,34 @@ func (r *sqlRepository) upsertFTPConfigs(routingNumber, host, user, pass string)
 		pass = existingPass
 	}
 
-	query := `replace into ftp_configs (routing_number, hostname, username, password) values (?, ?, ?, ?);`
-	stmt, err = tx.Prepare(query)
-	if err != nil {
-		return fmt.Errorf("error preparing replace: error=%v rollback=%v", err, tx.Rollback())
-	}
-	defer stmt.Close()
-	if _, err := stmt.Exec(routingNumber, host, user, pass); err != nil {
+	query := `insert into ftp_configs (routing_number, hostname, username, password) values (?, ?, ?, ?);`
+	if err := replace(ctx, tx, "ftp_configs", routingNumber, query, routingNumber, host, user, pass); err != nil {
 		return fmt.Errorf("error replacing ftp config error=%v rollback=%v", err, tx.Rollback())
 	}
 
 	return tx.Commit()
 }
 
-func (r *sqlRepository) deleteFTPConfig(routingNumber string) error {
+func (r *sqlRepository) deleteFTPConfig(ctx context.Context, routingNumber string) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.deleteFTPConfig", "routingNumber", routingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `delete from ftp_configs where routing_number = ?;`
-	return exec(r.db, query, routingNumber)
+	return exec(ctx, r.db, query, routingNumber)
 }
 
-func (r *sqlRepository) GetSFTPConfigs() ([]*SFTPConfig, error) {
+func (r *sqlRepository) GetSFTPConfigs(ctx context.Context) ([]*SFTPConfig, error) {
 	query := `select routing_number, hostname, username, password, client_private_key, host_public_key from sftp_configs;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
 	var configs []*SFTPConfig
-	rows, err := stmt.Query()
+	rows, err := stmt.QueryContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -304,14 +355,19 @@ func (r *sqlRepository) GetSFTPConfigs() ([]*SFTPConfig, error) {
 	return configs, rows.Err()
 }
 
-func (r *sqlRepository) upsertSFTPConfigs(routingNumber, host, user, pass, privateKey, publicKey string) error {
-	tx, err := r.db.Begin()
+func (r *sqlRepository) upsertSFTPConfigs(ctx context.Context, routingNumber, host, user, pass, privateKey, publicKey string) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.upsertSFTPConfigs", "routingNumber", routingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
 	query := `select password, client_private_key, host_public_key from sftp_configs where routing_number = ? limit 1;`
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("error preparing read: error=%v rollback=%v", err, tx.Rollback())
 	}
@@ -319,7 +375,7 @@ func (r *sqlRepository) upsertSFTPConfigs(routingNumber, host, user, pass, priva
 
 	// read existing values
 	ePass, ePriv, ePub := "", "", ""
-	if err := stmt.QueryRow(routingNumber).Scan(&ePass, &ePriv, &ePub); err != nil {
+	if err := stmt.QueryRowContext(ctx, routingNumber).Scan(&ePass, &ePriv, &ePub); err != nil {
 		return fmt.Errorf("error reading existing: error=%v rollback=%v", err, tx.Rollback())
 	}
 
@@ -334,26 +390,25 @@ func (r *sqlRepository) upsertSFTPConfigs(routingNumber, host, user, pass, priva
 	}
 
 	// update/insert entire row
-	query = `replace into sftp_configs (routing_number, hostname, username, password, client_private_key, host_public_key) values (?, ?, ?, ?, ?, ?);`
-	stmt, err = tx.Prepare(query)
-	if err != nil {
-		return fmt.Errorf("error preparing replace: error=%v rollback=%v", err, tx.Rollback())
-	}
-	defer stmt.Close()
-
-	if _, err := stmt.Exec(routingNumber, host, user, pass, privateKey, publicKey); err != nil {
-		return fmt.Errorf("error executing repalce: error=%v rollback=%v", err, tx.Rollback())
+	query = `insert into sftp_configs (routing_number, hostname, username, password, client_private_key, host_public_key) values (?, ?, ?, ?, ?, ?);`
+	if err := replace(ctx, tx, "sftp_configs", routingNumber, query, routingNumber, host, user, pass, privateKey, publicKey); err != nil {
+		return fmt.Errorf("error executing replace: error=%v rollback=%v", err, tx.Rollback())
 	}
 
 	return tx.Commit()
 }
 
-func (r *sqlRepository) deleteSFTPConfig(routingNumber string) error {
+func (r *sqlRepository) deleteSFTPConfig(ctx context.Context, routingNumber string) (err error) {
+	ctx, span := tracing.Start(ctx, "filetransferRepo.deleteSFTPConfig", "routingNumber", routingNumber)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `delete from sftp_configs where routing_number = ?;`
-	return exec(r.db, query, routingNumber)
+	return exec(ctx, r.db, query, routingNumber)
 }
 
-func readConfigFile(path string) (Repository, error) {
+func readConfigFile(path string) (*staticRepository, error) {
 	bs, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -451,19 +506,19 @@ func (r *staticRepository) populateSFTPConfigs() {
 	})
 }
 
-func (r *staticRepository) GetConfigs() ([]*Config, error) {
+func (r *staticRepository) GetConfigs(ctx context.Context) ([]*Config, error) {
 	return r.configs, nil
 }
 
-func (r *staticRepository) GetCutoffTimes() ([]*CutoffTime, error) {
+func (r *staticRepository) GetCutoffTimes(ctx context.Context) ([]*CutoffTime, error) {
 	return r.cutoffTimes, nil
 }
 
-func (r *staticRepository) GetFTPConfigs() ([]*FTPConfig, error) {
+func (r *staticRepository) GetFTPConfigs(ctx context.Context) ([]*FTPConfig, error) {
 	return r.ftpConfigs, nil
 }
 
-func (r *staticRepository) GetSFTPConfigs() ([]*SFTPConfig, error) {
+func (r *staticRepository) GetSFTPConfigs(ctx context.Context) ([]*SFTPConfig, error) {
 	return r.sftpConfigs, nil
 }
 
@@ -471,35 +526,35 @@ func (r *staticRepository) Close() error {
 	return nil
 }
 
-func (r *staticRepository) upsertConfig(cfg *Config) error {
+func (r *staticRepository) upsertConfig(ctx context.Context, cfg *Config) error {
 	return nil
 }
 
-func (r *staticRepository) deleteConfig(routingNumber string) error {
+func (r *staticRepository) deleteConfig(ctx context.Context, routingNumber string) error {
 	return nil
 }
 
-func (r *staticRepository) upsertCutoffTime(routingNumber string, cutoff int, loc *time.Location) error {
+func (r *staticRepository) upsertCutoffTime(ctx context.Context, routingNumber string, cutoff int, loc *time.Location) error {
 	return nil
 }
 
-func (r *staticRepository) deleteCutoffTime(routingNumber string) error {
+func (r *staticRepository) deleteCutoffTime(ctx context.Context, routingNumber string) error {
 	return nil
 }
 
-func (r *staticRepository) upsertFTPConfigs(routingNumber, host, user, pass string) error {
+func (r *staticRepository) upsertFTPConfigs(ctx context.Context, routingNumber, host, user, pass string) error {
 	return nil
 }
 
-func (r *staticRepository) deleteFTPConfig(routingNumber string) error {
+func (r *staticRepository) deleteFTPConfig(ctx context.Context, routingNumber string) error {
 	return nil
 }
 
-func (r *staticRepository) upsertSFTPConfigs(routingNumber, host, user, pass, privateKey, publicKey string) error {
+func (r *staticRepository) upsertSFTPConfigs(ctx context.Context, routingNumber, host, user, pass, privateKey, publicKey string) error {
 	return nil
 }
 
-func (r *staticRepository) deleteSFTPConfig(routingNumber string) error {
+func (r *staticRepository) deleteSFTPConfig(ctx context.Context, routingNumber string) error {
 	return nil
 }
 
@@ -512,6 +567,73 @@ func AddFileTransferConfigRoutes(logger log.Logger, svc *admin.Server, repo Repo
 	svc.AddHandler("/configs/uploads/sftp/{routingNumber}", manageSFTPConfig(logger, repo))
 }
 
+type routingNumberSnapshot struct {
+	CutoffTime *cutoffTimeSnapshot `json:"cutoffTime,omitempty"`
+	Config     *Config             `json:"config,omitempty"`
+	FTP        *FTPConfig          `json:"ftp,omitempty"`
+	SFTP       *SFTPConfig         `json:"sftp,omitempty"`
+}
+
+type cutoffTimeSnapshot struct {
+	Cutoff   int    `json:"cutoff"`
+	Location string `json:"location"`
+}
+
+// ConfigSnapshot returns every config for the routing number in a request's path with passwords and
+// private keys masked, which the audit log records before and after they're changed.
+func ConfigSnapshot(repo Repository) func(*http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		routingNumber := getRoutingNumber(r)
+		out := &routingNumberSnapshot{}
+
+		cutoffs, err := repo.GetCutoffTimes(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		for i := range cutoffs {
+			if cutoffs[i].RoutingNumber == routingNumber {
+				out.CutoffTime = &cutoffTimeSnapshot{Cutoff: cutoffs[i].Cutoff, Location: cutoffs[i].Loc.String()}
+			}
+		}
+		configs, err := repo.GetConfigs(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		for i := range configs {
+			if configs[i].RoutingNumber == routingNumber {
+				cfg := *configs[i]
+				out.Config = &cfg
+			}
+		}
+		ftpConfigs, err := repo.GetFTPConfigs(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		for i := range ftpConfigs {
+			if ftpConfigs[i].RoutingNumber == routingNumber {
+				cfg := *ftpConfigs[i] // copy so the stored password isn't masked
+				cfg.Password = maskPassword(cfg.Password)
+				out.FTP = &cfg
+			}
+		}
+		sftpConfigs, err := repo.GetSFTPConfigs(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		for i := range sftpConfigs {
+			if sftpConfigs[i].RoutingNumber == routingNumber {
+				cfg := *sftpConfigs[i]
+				cfg.Password = maskPassword(cfg.Password)
+				if cfg.ClientPrivateKey != "" {
+					cfg.ClientPrivateKey = "REDACTED"
+				}
+				out.SFTP = &cfg
+			}
+		}
+		return out, nil
+	}
+}
+
 func getRoutingNumber(r *http.Request) string {
 	rtn, ok := mux.Vars(r)["routingNumber"]
 	if !ok {
@@ -536,25 +658,25 @@ func GetConfigs(logger log.Logger, repo Repository) http.HandlerFunc {
 		}
 
 		resp := &adminConfigResponse{}
-		if v, err := repo.GetCutoffTimes(); err != nil {
+		if v, err := repo.GetCutoffTimes(r.Context()); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		} else {
 			resp.CutoffTimes = v
 		}
-		if v, err := repo.GetConfigs(); err != nil {
+		if v, err := repo.GetConfigs(r.Context()); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		} else {
 			resp.FileTransferConfigs = v
 		}
-		if v, err := repo.GetFTPConfigs(); err != nil {
+		if v, err := repo.GetFTPConfigs(r.Context()); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		} else {
 			resp.FTPConfigs = maskFTPPasswords(v)
 		}
-		if v, err := repo.GetSFTPConfigs(); err != nil {
+		if v, err := repo.GetSFTPConfigs(r.Context()); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		} else {
@@ -618,14 +740,14 @@ func manageCutoffTimeConfig(logger log.Logger, repo Repository) http.HandlerFunc
 				moovhttp.Problem(w, fmt.Errorf("time: %s: %v", req.Location, err))
 				return
 			}
-			if err := repo.upsertCutoffTime(routingNumber, req.Cutoff, loc); err != nil {
+			if err := repo.upsertCutoffTime(r.Context(), routingNumber, req.Cutoff, loc); err != nil {
 				moovhttp.Problem(w, err)
 				return
 			}
 			logger.Log("file-transfer-configs", fmt.Sprintf("updating cutoff time config routingNumber=%s", routingNumber), "requestID", moovhttp.GetRequestID(r))
 
 		case "DELETE":
-			if err := repo.deleteCutoffTime(routingNumber); err != nil {
+			if err := repo.deleteCutoffTime(r.Context(), routingNumber); err != nil {
 				moovhttp.Problem(w, err)
 				return
 			}
@@ -666,7 +788,7 @@ func manageFileTransferConfig(logger log.Logger, repo Repository) http.HandlerFu
 					return
 				}
 			}
-			err := repo.upsertConfig(&Config{
+			err := repo.upsertConfig(r.Context(), &Config{
 				RoutingNumber:            routingNumber,
 				InboundPath:              req.InboundPath,
 				OutboundPath:             req.OutboundPath,
@@ -681,7 +803,7 @@ func manageFileTransferConfig(logger log.Logger, repo Repository) http.HandlerFu
 			w.WriteHeader(http.StatusOK)
 
 		case "DELETE":
-			if err := repo.deleteConfig(routingNumber); err != nil {
+			if err := repo.deleteConfig(r.Context(), routingNumber); err != nil {
 				moovhttp.Problem(w, err)
 				return
 			}
@@ -718,14 +840,14 @@ func manageFTPConfig(logger log.Logger, repo Repository) http.HandlerFunc {
 				moovhttp.Problem(w, errors.New("missing hostname, or username"))
 				return
 			}
-			if err := repo.upsertFTPConfigs(routingNumber, req.Hostname, req.Username, req.Password); err != nil {
+			if err := repo.upsertFTPConfigs(r.Context(), routingNumber, req.Hostname, req.Username, req.Password); err != nil {
 				moovhttp.Problem(w, err)
 				return
 			}
 			logger.Log("file-transfer-configs", fmt.Sprintf("updating FTP configs routingNumber=%s", routingNumber), "requestID", moovhttp.GetRequestID(r))
 
 		case "DELETE":
-			if err := repo.deleteFTPConfig(routingNumber); err != nil {
+			if err := repo.deleteFTPConfig(r.Context(), routingNumber); err != nil {
 				moovhttp.Problem(w, err)
 				return
 			}
@@ -764,14 +886,14 @@ func manageSFTPConfig(logger log.Logger, repo Repository) http.HandlerFunc {
 				moovhttp.Problem(w, errors.New("missing hostname, or username"))
 				return
 			}
-			if err := repo.upsertSFTPConfigs(routingNumber, req.Hostname, req.Username, req.Password, req.ClientPrivateKey, req.HostPublicKey); err != nil {
+			if err := repo.upsertSFTPConfigs(r.Context(), routingNumber, req.Hostname, req.Username, req.Password, req.ClientPrivateKey, req.HostPublicKey); err != nil {
 				moovhttp.Problem(w, err)
 				return
 			}
 			logger.Log("file-transfer-configs", fmt.Sprintf("updating SFTP config routingNumber=%s", routingNumber), "requestID", moovhttp.GetRequestID(r))
 
 		case "DELETE":
-			if err := repo.deleteSFTPConfig(routingNumber); err != nil {
+			if err := repo.deleteSFTPConfig(r.Context(), routingNumber); err != nil {
 				moovhttp.Problem(w, err)
 				return
 			}
@@ -0,0 +1,205 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	// ErrReadOnlyConfig is returned when modifying file transfer configs which were loaded
+	// from a file. Changes need to be made in the file, which is reloaded automatically.
+	ErrReadOnlyConfig = errors.New("filetransfer: configs are loaded from a file and are read-only, edit the file instead")
+
+	fileRepositoryReloadInterval = 10 * time.Second
+)
+
+// fileRepository is a Repository backed by a YAML file on disk. The file is checked for
+// changes periodically and its configs are swapped in atomically after they've been read
+// and validated. A file which fails to read or validate leaves the previous configs in place.
+type fileRepository struct {
+	logger log.Logger
+	path   string
+
+	mu      sync.RWMutex
+	current *staticRepository
+	modTime time.Time
+	size    int64
+
+	ctx      context.Context
+	shutdown context.CancelFunc
+}
+
+// NewFileRepository reads file transfer configs from the YAML file at path and watches it
+// for changes. Callers should Close the returned Repository to stop watching.
+func NewFileRepository(logger log.Logger, path string) (Repository, error) {
+	return newFileRepository(logger, path, fileRepositoryReloadInterval)
+}
+
+func newFileRepository(logger log.Logger, path string, interval time.Duration) (*fileRepository, error) {
+	ctx, shutdown := context.WithCancel(context.Background())
+	repo := &fileRepository{
+		logger:   logger,
+		path:     path,
+		ctx:      ctx,
+		shutdown: shutdown,
+	}
+	if _, err := repo.reload(); err != nil {
+		shutdown()
+		return nil, err
+	}
+	go repo.watch(interval)
+	return repo, nil
+}
+
+func (r *fileRepository) watch(interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				r.logger.Log("file-transfer-configs", fmt.Sprintf("problem reloading %s: %v", r.path, err))
+				continue
+			}
+			if reloaded {
+				r.logger.Log("file-transfer-configs", fmt.Sprintf("reloaded configs from %s", r.path))
+			}
+
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// reload reads the file if it's changed since it was last read and returns true when new
+// configs were swapped in.
+func (r *fileRepository) reload() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.current != nil && info.ModTime().Equal(r.modTime) && info.Size() == r.size
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	repo, err := readConfigFile(r.path)
+	if err != nil {
+		return false, err
+	}
+	for i := range repo.configs {
+		if tmpl := repo.configs[i].OutboundFilenameTemplate; tmpl != "" {
+			if err := validateTemplate(tmpl); err != nil {
+				return false, fmt.Errorf("routingNumber=%s invalid outbound filename template: %v", repo.configs[i].RoutingNumber, err)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.current = repo
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+func (r *fileRepository) read() *staticRepository {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// The Get methods return copies as callers (i.e. the admin routes) mask passwords in place.
+
+func (r *fileRepository) GetConfigs(ctx context.Context) ([]*Config, error) {
+	cfgs := r.read().configs
+	out := make([]*Config, len(cfgs))
+	for i := range cfgs {
+		cfg := *cfgs[i]
+		out[i] = &cfg
+	}
+	return out, nil
+}
+
+func (r *fileRepository) GetCutoffTimes(ctx context.Context) ([]*CutoffTime, error) {
+	times := r.read().cutoffTimes
+	out := make([]*CutoffTime, len(times))
+	for i := range times {
+		cutoff := *times[i]
+		out[i] = &cutoff
+	}
+	return out, nil
+}
+
+func (r *fileRepository) GetFTPConfigs(ctx context.Context) ([]*FTPConfig, error) {
+	cfgs := r.read().ftpConfigs
+	out := make([]*FTPConfig, len(cfgs))
+	for i := range cfgs {
+		cfg := *cfgs[i]
+		out[i] = &cfg
+	}
+	return out, nil
+}
+
+func (r *fileRepository) GetSFTPConfigs(ctx context.Context) ([]*SFTPConfig, error) {
+	cfgs := r.read().sftpConfigs
+	out := make([]*SFTPConfig, len(cfgs))
+	for i := range cfgs {
+		cfg := *cfgs[i]
+		out[i] = &cfg
+	}
+	return out, nil
+}
+
+func (r *fileRepository) Close() error {
+	r.shutdown()
+	return nil
+}
+
+func (r *fileRepository) upsertConfig(ctx context.Context, cfg *Config) error {
+	return ErrReadOnlyConfig
+}
+
+func (r *fileRepository) deleteConfig(ctx context.Context, routingNumber string) error {
+	return ErrReadOnlyConfig
+}
+
+func (r *fileRepository) upsertCutoffTime(ctx context.Context, routingNumber string, cutoff int, loc *time.Location) error {
+	return ErrReadOnlyConfig
+}
+
+func (r *fileRepository) deleteCutoffTime(ctx context.Context, routingNumber string) error {
+	return ErrReadOnlyConfig
+}
+
+func (r *fileRepository) upsertFTPConfigs(ctx context.Context, routingNumber, host, user, pass string) error {
+	return ErrReadOnlyConfig
+}
+
+func (r *fileRepository) deleteFTPConfig(ctx context.Context, routingNumber string) error {
+	return ErrReadOnlyConfig
+}
+
+func (r *fileRepository) upsertSFTPConfigs(ctx context.Context, routingNumber, host, user, pass, privateKey, publicKey string) error {
+	return ErrReadOnlyConfig
+}
+
+func (r *fileRepository) deleteSFTPConfig(ctx context.Context, routingNumber string) error {
+	return ErrReadOnlyConfig
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base/admin"
+
+	"github.com/go-kit/kit/log"
+)
+
+func writeTestConfigFile(t *testing.T, path string, routingNumber string, modTime time.Time) {
+	t.Helper()
+
+	bs, err := ioutil.ReadFile(filepath.Join("..", "..", "testdata", "configs", "routing-good.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs = []byte(strings.Replace(string(bs), "987654320", routingNumber, -1))
+	if err := ioutil.WriteFile(path, bs, 0600); err != nil {
+		t.Fatal(err)
+	}
+	// set the modification time explicitly as some filesystems have a coarse resolution
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileRepository(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "fileRepository")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "paygate.yaml")
+	writeTestConfigFile(t, path, "987654320", time.Now().Add(-1*time.Hour))
+
+	repo, err := newFileRepository(log.NewNopLogger(), path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	if xs, _ := repo.GetConfigs(context.Background()); len(xs) != 1 || xs[0].RoutingNumber != "987654320" {
+		t.Errorf("got %#v", xs)
+	}
+	if xs, _ := repo.GetCutoffTimes(context.Background()); len(xs) != 1 || xs[0].Cutoff != 1500 {
+		t.Errorf("got %#v", xs)
+	}
+
+	// masking passwords (like the admin routes do) can't change what's stored
+	ftpConfigs, _ := repo.GetFTPConfigs(context.Background())
+	maskFTPPasswords(ftpConfigs)
+	if xs, _ := repo.GetFTPConfigs(context.Background()); len(xs) != 1 || xs[0].Password != "secret" {
+		t.Errorf("got %#v", xs)
+	}
+	sftpConfigs, _ := repo.GetSFTPConfigs(context.Background())
+	maskSFTPPasswords(sftpConfigs)
+	if xs, _ := repo.GetSFTPConfigs(context.Background()); len(xs) != 1 || xs[0].Password != "super-secret" {
+		t.Errorf("got %#v", xs)
+	}
+
+	// an unchanged file isn't read again
+	if reloaded, err := repo.reload(); reloaded || err != nil {
+		t.Errorf("reloaded=%v error=%v", reloaded, err)
+	}
+
+	// pick up changes
+	writeTestConfigFile(t, path, "121042882", time.Now())
+	if reloaded, err := repo.reload(); !reloaded || err != nil {
+		t.Fatalf("reloaded=%v error=%v", reloaded, err)
+	}
+	if xs, _ := repo.GetConfigs(context.Background()); len(xs) != 1 || xs[0].RoutingNumber != "121042882" {
+		t.Errorf("got %#v", xs)
+	}
+	if xs, _ := repo.GetFTPConfigs(context.Background()); len(xs) != 1 || xs[0].RoutingNumber != "121042882" {
+		t.Errorf("got %#v", xs)
+	}
+
+	// an invalid file keeps the previous configs
+	if err := ioutil.WriteFile(path, []byte("fileTransfer: [invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := repo.reload(); reloaded || err == nil {
+		t.Errorf("expected error: reloaded=%v", reloaded)
+	}
+	if xs, _ := repo.GetConfigs(context.Background()); len(xs) != 1 || xs[0].RoutingNumber != "121042882" {
+		t.Errorf("got %#v", xs)
+	}
+}
+
+func TestFileRepository__watch(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "fileRepository")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "paygate.yaml")
+	writeTestConfigFile(t, path, "987654320", time.Now().Add(-1*time.Hour))
+
+	repo, err := newFileRepository(log.NewNopLogger(), path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	writeTestConfigFile(t, path, "121042882", time.Now())
+
+	for i := 0; i < 100; i++ {
+		if xs, _ := repo.GetCutoffTimes(context.Background()); len(xs) == 1 && xs[0].RoutingNumber == "121042882" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("configs were never reloaded")
+}
+
+func TestFileRepository__invalid(t *testing.T) {
+	if _, err := NewFileRepository(log.NewNopLogger(), filepath.Join("testdata", "missing.yaml")); err == nil {
+		t.Error("expected error")
+	}
+
+	dir, _ := ioutil.TempDir("", "fileRepository")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "paygate.yaml")
+	conf := `fileTransfer:
+  configs:
+    - routingNumber: '987654320'
+      outboundFilenameTemplate: '{{ .Invalid'
+`
+	if err := ioutil.WriteFile(path, []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileRepository(log.NewNopLogger(), path); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestFileRepository__readOnly(t *testing.T) {
+	repo, err := NewFileRepository(log.NewNopLogger(), filepath.Join("..", "..", "testdata", "configs", "routing-good.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	if err := repo.upsertConfig(context.Background(), &Config{RoutingNumber: "987654320"}); err != ErrReadOnlyConfig {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := repo.deleteCutoffTime(context.Background(), "987654320"); err != ErrReadOnlyConfig {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	AddFileTransferConfigRoutes(log.NewNopLogger(), svc, repo)
+
+	body := strings.NewReader(`{"hostname": "ftp.bank.com", "username": "paygate"}`)
+	req, _ := http.NewRequest("PUT", "http://"+svc.BindAddr()+"/configs/uploads/ftp/987654320", body)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	bs, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(bs), "read-only") {
+		t.Errorf("bogus HTTP status: %d: %s", resp.StatusCode, string(bs))
+	}
+
+	req, _ = http.NewRequest("DELETE", "http://"+svc.BindAddr()+"/configs/uploads/sftp/987654320", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", resp.StatusCode)
+	}
+}
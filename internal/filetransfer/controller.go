@@ -12,7 +12,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,27 +28,6 @@ import (
 )
 
 var (
-	// fileMaxLines is the maximum line count before an ACH file is uploaded
-	// to its remote server. NACHA guidelines have a hard limit of 10,000 lines.
-	fileMaxLines = func() int {
-		if n, err := strconv.Atoi(os.Getenv("ACH_FILE_MAX_LINES")); err == nil {
-			return n
-		}
-		return 10000
-	}()
-
-	// forcedCutoffUploadDelta is the duration before a cutoff time where an ACH file is uploaded
-	// without merging into a file.
-	// TODO(adam): Should we hold off uploading instead?
-	forcedCutoffUploadDelta = func() time.Duration {
-		if v := os.Getenv("FORCED_CUTOFF_UPLOAD_DELTA"); v != "" {
-			if dur, _ := time.ParseDuration(v); dur > 0 {
-				return dur
-			}
-		}
-		return 5 * time.Minute
-	}()
-
 	missingFileUploadConfigs = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 		Name: "missing_ach_file_upload_configs",
 		Help: "Counter of missing configurations for file upload - ftp, sftp, or file transfer config(s)",
@@ -70,6 +48,18 @@ type Controller struct {
 	// interval is how often to pull records from the database and operate on
 	interval time.Duration
 
+	// maxLines is the maximum line count before an ACH file is uploaded to its remote server.
+	maxLines int
+
+	// forcedCutoffUploadDelta is the duration before a cutoff time where an ACH file is uploaded
+	// without merging into a file.
+	// TODO(adam): Should we hold off uploading instead?
+	forcedCutoffUploadDelta time.Duration
+
+	// ftp and sftp are used when connecting to remote servers
+	ftp  config.FTPConfig
+	sftp config.SFTPConfig
+
 	repo Repository
 
 	ach            *achclient.ACH
@@ -78,36 +68,26 @@ type Controller struct {
 	keeper *secrets.StringKeeper
 
 	logger log.Logger
+
+	// shutdown tracks the work in progress so Shutdown can wait on it
+	shutdown shutdownState
 }
 
 // NewController returns a Controller which is responsible for uploading ACH files
 // to their SFTP host for processing.
 //
-// To change the refresh duration set ACH_FILE_TRANSFER_INTERVAL with a Go time.Duration value. (i.e. 10m for 10 minutes)
+// A nil Controller is returned when cfg.FileTransfer.Disabled is set.
 func NewController(cfg *config.Config, dir string, repo Repository, achClient *achclient.ACH, accountsClient internal.AccountsClient) (*Controller, error) {
 	if _, err := os.Stat(dir); dir == "" || err != nil {
 		return nil, fmt.Errorf("file-transfer-controller: problem with storage directory %q: %v", dir, err)
 	}
 
-	var interval time.Duration
-	if v := os.Getenv("ACH_FILE_TRANSFER_INTERVAL"); strings.EqualFold(v, "off") {
-		cfg.Logger.Log("file-transfer-controller", "disabling Controller via config (ACH_FILE_TRANSFER_INTERVAL)")
+	transfers := cfg.FileTransfer
+	if transfers.Disabled {
+		cfg.Logger.Log("file-transfer-controller", "disabling Controller via config")
 		return nil, nil // disabled, so return nothing
-	} else {
-		dur, err := time.ParseDuration(v)
-		if err != nil {
-			interval = 10 * time.Minute
-		} else {
-			interval = dur
-		}
-	}
-	batchSize := 100
-	if v := os.Getenv("ACH_FILE_BATCH_SIZE"); v != "" {
-		if n, _ := strconv.Atoi(v); n > 0 {
-			batchSize = n
-		}
 	}
-	cfg.Logger.Log("NewController", fmt.Sprintf("starting ACH file transfer controller: interval=%v batchSize=%d", interval, batchSize))
+	cfg.Logger.Log("NewController", fmt.Sprintf("starting ACH file transfer controller: interval=%v batchSize=%d", transfers.Interval, transfers.BatchSize))
 
 	rootDir, err := filepath.Abs(dir)
 	if err != nil || strings.Contains(dir, "..") {
@@ -118,20 +98,24 @@ func NewController(cfg *config.Config, dir string, repo Repository, achClient *a
 	}
 
 	controller := &Controller{
-		rootDir:        rootDir,
-		interval:       interval,
-		batchSize:      batchSize,
-		repo:           repo,
-		ach:            achClient,
-		logger:         cfg.Logger,
-		accountsClient: accountsClient,
+		rootDir:                 rootDir,
+		interval:                transfers.Interval,
+		batchSize:               transfers.BatchSize,
+		maxLines:                transfers.MaxLines,
+		forcedCutoffUploadDelta: transfers.ForcedCutoffUploadDelta,
+		ftp:                     transfers.FTP,
+		sftp:                    transfers.SFTP,
+		repo:                    repo,
+		ach:                     achClient,
+		logger:                  cfg.Logger,
+		accountsClient:          accountsClient,
 	}
 
 	return controller, nil
 }
 
-func (c *Controller) findFileTransferConfig(routingNumber string) *Config {
-	cfgs, err := c.repo.GetConfigs()
+func (c *Controller) findFileTransferConfig(ctx context.Context, routingNumber string) *Config {
+	cfgs, err := c.repo.GetConfigs(ctx)
 	if err != nil {
 		return nil
 	}
@@ -145,8 +129,12 @@ func (c *Controller) findFileTransferConfig(routingNumber string) *Config {
 
 // findTransferType will return a string from matching the provided routingNumber against
 // FTP, SFTP (and future) file transport protocols. This string needs to match New.
-func (c *Controller) findTransferType(routingNumber string) string {
-	ftpConfigs, err := c.repo.GetFTPConfigs()
+func (c *Controller) findTransferType(ctx context.Context, routingNumber string) string {
+	return findTransferType(ctx, c.repo, routingNumber)
+}
+
+func findTransferType(ctx context.Context, repo Repository, routingNumber string) string {
+	ftpConfigs, err := repo.GetFTPConfigs(ctx)
 	if err != nil {
 		return fmt.Sprintf("unknown: error=%v", err)
 	}
@@ -156,7 +144,7 @@ func (c *Controller) findTransferType(routingNumber string) string {
 		}
 	}
 
-	sftpConfigs, err := c.repo.GetSFTPConfigs()
+	sftpConfigs, err := repo.GetSFTPConfigs(ctx)
 	if err != nil {
 		return fmt.Sprintf("unknown: error=%v", err)
 	}
@@ -189,13 +177,21 @@ type periodicFileOperationsRequest struct {
 // portion of this pooling loop, which is used by admin endpoints and to make testing easier.
 //
 // Uploads will be completed before their cutoff time which is set for a given ABA routing number.
-func (c *Controller) StartPeriodicFileOperations(ctx context.Context, flushIncoming FlushChan, flushOutgoing FlushChan, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository) {
+//
+// Cancelling ctx stops new operations from starting, but the current operations are finished
+// so merged files aren't left half written. Call Shutdown to wait for them.
+func (c *Controller) StartPeriodicFileOperations(ctx context.Context, flushIncoming FlushChan, flushOutgoing FlushChan, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository, authorizationRepo internal.AuthorizationRepository) {
 	tick := time.NewTicker(c.interval)
 	defer tick.Stop()
 
+	// Operations keep running after ctx is cancelled, Shutdown aborts them if they run past its deadline.
+	stopped, workCtx := c.start(ctx)
+	defer stopped()
+
 	// Grab shared transfer cursor for new transfers to merge into local files
 	transferCursor := transferRepo.GetTransferCursor(c.batchSize, depRepo)
 	microDepositCursor := depRepo.GetMicroDepositCursor(c.batchSize)
+	prenoteCursor := depRepo.GetPrenoteCursor(c.batchSize)
 
 	finish := func(req *periodicFileOperationsRequest, wg *sync.WaitGroup, errs chan error) {
 		// Wait for all operations to complete
@@ -219,6 +215,12 @@ func (c *Controller) StartPeriodicFileOperations(ctx context.Context, flushIncom
 	}
 
 	for {
+		// Don't start anything new once we're shutting down, even if a flush or tick is also ready
+		if ctx.Err() != nil {
+			c.logger.Log("StartPeriodicFileOperations", "Shutting down due to context.Done()")
+			return
+		}
+
 		// Setup our concurrnet waiting
 		var wg sync.WaitGroup
 		errs := make(chan error, 10)
@@ -226,16 +228,20 @@ func (c *Controller) StartPeriodicFileOperations(ctx context.Context, flushIncom
 		select {
 		case req := <-flushIncoming:
 			c.logger.Log("StartPeriodicFileOperations", "flushing inbound ACH files", "requestID", req.requestID, "userID", req.userID)
-			if err := c.downloadAndProcessIncomingFiles(req, depRepo, transferRepo); err != nil {
+			done := c.track("downloadAndProcessIncomingFiles")
+			if err := c.downloadAndProcessIncomingFiles(workCtx, req, depRepo, transferRepo, authorizationRepo); err != nil {
 				errs <- fmt.Errorf("downloadAndProcessIncomingFiles: %v", err)
 			}
+			done()
 			finish(req, &wg, errs)
 
 		case req := <-flushOutgoing:
 			c.logger.Log("StartPeriodicFileOperations", "flushing ACH files to their outbound destination", "requestID", req.requestID, "userID", req.userID)
-			if err := c.mergeAndUploadFiles(transferCursor, microDepositCursor, transferRepo, req, &mergeUploadOpts{force: true}); err != nil {
+			done := c.track("mergeAndUploadFiles")
+			if err := c.mergeAndUploadFiles(workCtx, transferCursor, microDepositCursor, prenoteCursor, transferRepo, req, &mergeUploadOpts{force: true}); err != nil {
 				errs <- fmt.Errorf("mergeAndUploadFiles: %v", err)
 			}
+			done()
 			finish(req, &wg, errs)
 
 		case <-tick.C:
@@ -244,7 +250,8 @@ func (c *Controller) StartPeriodicFileOperations(ctx context.Context, flushIncom
 			req := &periodicFileOperationsRequest{}
 			wg.Add(1)
 			go func() {
-				if err := c.downloadAndProcessIncomingFiles(req, depRepo, transferRepo); err != nil {
+				defer c.track("downloadAndProcessIncomingFiles")()
+				if err := c.downloadAndProcessIncomingFiles(workCtx, req, depRepo, transferRepo, authorizationRepo); err != nil {
 					errs <- fmt.Errorf("downloadAndProcessIncomingFiles: %v", err)
 				}
 				wg.Done()
@@ -252,7 +259,8 @@ func (c *Controller) StartPeriodicFileOperations(ctx context.Context, flushIncom
 			// Grab transfers, merge them into files, and upload any which are complete.
 			wg.Add(1)
 			go func() {
-				if err := c.mergeAndUploadFiles(transferCursor, microDepositCursor, transferRepo, req, &mergeUploadOpts{}); err != nil {
+				defer c.track("mergeAndUploadFiles")()
+				if err := c.mergeAndUploadFiles(workCtx, transferCursor, microDepositCursor, prenoteCursor, transferRepo, req, &mergeUploadOpts{}); err != nil {
 					errs <- fmt.Errorf("mergeAndUploadFiles: %v", err)
 				}
 				wg.Done()
@@ -345,18 +353,30 @@ func (f *achFile) lineCount() int {
 }
 
 // write will overwrite f.filepath with the ach.File contents underlying achFile.
+//
+// The contents are written to a temporary file which replaces f.filepath once it's flushed, so a
+// shutdown or crash never leaves a partial file behind to be uploaded.
 func (f *achFile) write() error {
-	fd, err := os.Create(f.filepath)
+	tmp := f.filepath + ".tmp"
+	fd, err := os.Create(tmp)
 	if err != nil {
 		return err
 	}
 	if err := ach.NewWriter(fd).Write(f.File); err != nil {
+		fd.Close()
+		os.Remove(tmp)
 		return err
 	}
 	if err := fd.Sync(); err != nil {
+		fd.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		os.Remove(tmp)
 		return err
 	}
-	return fd.Close()
+	return os.Rename(tmp, f.filepath)
 }
 
 // notes
@@ -29,6 +29,17 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// testConfig returns a config.Config with the defaults paygate starts with.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := config.Empty()
+	if err := config.OverrideWithEnvVars(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
 func TestController(t *testing.T) {
 	dir, err := ioutil.TempDir("", "Controller")
 	if err != nil {
@@ -36,9 +47,9 @@ func TestController(t *testing.T) {
 	}
 	defer os.RemoveAll(dir)
 
-	repo := NewRepository("", nil, "") // localFileTransferRepository
+	repo := NewRepository(nil, "") // localFileTransferRepository
 
-	cfg := config.Empty()
+	cfg := testConfig(t)
 	controller, err := NewController(cfg, dir, repo, nil, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -49,16 +60,25 @@ func TestController(t *testing.T) {
 	if controller.batchSize != 100 {
 		t.Errorf("batchSize: %d", controller.batchSize)
 	}
+	if controller.maxLines != 10000 || controller.forcedCutoffUploadDelta != 5*time.Minute {
+		t.Errorf("maxLines=%d forcedCutoffUploadDelta=%v", controller.maxLines, controller.forcedCutoffUploadDelta)
+	}
 
-	cutoffTimes, err := controller.repo.GetCutoffTimes()
+	cutoffTimes, err := controller.repo.GetCutoffTimes(context.Background())
 	if len(cutoffTimes) != 1 || err != nil {
 		t.Errorf("local len(cutoffTimes)=%d error=%v", len(cutoffTimes), err)
 	}
-	ftpConfigs, err := controller.repo.GetFTPConfigs()
+	ftpConfigs, err := controller.repo.GetFTPConfigs(context.Background())
 	if len(ftpConfigs) != 1 || err != nil {
 		t.Errorf("local len(ftpConfigs)=%d error=%v", len(ftpConfigs), err)
 	}
 
+	// disabled controllers aren't created
+	cfg.FileTransfer.Disabled = true
+	if c, err := NewController(cfg, dir, repo, nil, nil); c != nil || err != nil {
+		t.Errorf("controller=%v error=%v", c, err)
+	}
+
 	// force the localFileTransferRepository into SFTP mode
 	if r, ok := controller.repo.(*staticRepository); ok {
 		r.protocol = "sftp"
@@ -66,7 +86,7 @@ func TestController(t *testing.T) {
 	} else {
 		t.Fatalf("got %#v", controller.repo)
 	}
-	sftpConfigs, err := controller.repo.GetSFTPConfigs()
+	sftpConfigs, err := controller.repo.GetSFTPConfigs(context.Background())
 	if len(sftpConfigs) != 1 || err != nil {
 		t.Errorf("local len(sftpConfigs)=%d error=%v", len(sftpConfigs), err)
 	}
@@ -91,7 +111,7 @@ func TestController__findFileTransferConfig(t *testing.T) {
 	controller := &Controller{repo: repo}
 
 	// happy path - found
-	fileTransferConf := controller.findFileTransferConfig(cutoff.RoutingNumber)
+	fileTransferConf := controller.findFileTransferConfig(context.Background(), cutoff.RoutingNumber)
 	if fileTransferConf == nil {
 		t.Fatalf("fileTransferConf=%v", fileTransferConf)
 	}
@@ -100,14 +120,14 @@ func TestController__findFileTransferConfig(t *testing.T) {
 	}
 
 	// not found
-	fileTransferConf = controller.findFileTransferConfig("456")
+	fileTransferConf = controller.findFileTransferConfig(context.Background(), "456")
 	if fileTransferConf != nil {
 		t.Fatalf("fileTransferConf=%v", fileTransferConf)
 	}
 
 	// error
 	repo.err = errors.New("bad errors")
-	if conf := controller.findFileTransferConfig("987654320"); conf != nil {
+	if conf := controller.findFileTransferConfig(context.Background(), "987654320"); conf != nil {
 		t.Error("expected nil config")
 	}
 }
@@ -117,10 +137,10 @@ func TestController__findTransferType(t *testing.T) {
 		repo: &mockRepository{},
 	}
 
-	if v := controller.findTransferType(""); v != "unknown" {
+	if v := controller.findTransferType(context.Background(), ""); v != "unknown" {
 		t.Errorf("got %s", v)
 	}
-	if v := controller.findTransferType("987654320"); v != "unknown" {
+	if v := controller.findTransferType(context.Background(), "987654320"); v != "unknown" {
 		t.Errorf("got %s", v)
 	}
 
@@ -132,7 +152,7 @@ func TestController__findTransferType(t *testing.T) {
 			},
 		},
 	}
-	if v := controller.findTransferType("987654320"); v != "sftp" {
+	if v := controller.findTransferType(context.Background(), "987654320"); v != "sftp" {
 		t.Errorf("got %s", v)
 	}
 
@@ -144,7 +164,7 @@ func TestController__findTransferType(t *testing.T) {
 			},
 		},
 	}
-	if v := controller.findTransferType("987654320"); v != "ftp" {
+	if v := controller.findTransferType(context.Background(), "987654320"); v != "ftp" {
 		t.Errorf("got %s", v)
 	}
 
@@ -154,7 +174,7 @@ func TestController__findTransferType(t *testing.T) {
 			err: errors.New("bad error"),
 		},
 	}
-	if v := controller.findTransferType("ftp"); !strings.Contains(v, "unknown: error") {
+	if v := controller.findTransferType(context.Background(), "ftp"); !strings.Contains(v, "unknown: error") {
 		t.Errorf("got %s", v)
 	}
 }
@@ -167,7 +187,7 @@ func TestController__startPeriodicFileOperations(t *testing.T) {
 	dir, _ := ioutil.TempDir("", "startPeriodicFileOperations")
 	defer os.RemoveAll(dir)
 
-	repo := NewRepository("", nil, "")
+	repo := NewRepository(nil, "")
 
 	db := database.CreateTestSqliteDB(t)
 	defer db.Close()
@@ -179,6 +199,7 @@ func TestController__startPeriodicFileOperations(t *testing.T) {
 			BatchSize: 5,
 			DepRepo:   innerDepRepo,
 		},
+		PrenoteCur: innerDepRepo.GetPrenoteCursor(5),
 	}
 	transferRepo := &internal.MockTransferRepository{
 		Cur: &internal.TransferCursor{
@@ -189,7 +210,7 @@ func TestController__startPeriodicFileOperations(t *testing.T) {
 
 	// write a micro-deposit
 	amt, _ := internal.NewAmount("USD", "0.22")
-	if err := innerDepRepo.InitiateMicroDeposits(id.Depository("depositoryID"), "userID", []*internal.MicroDeposit{{Amount: *amt, FileID: "fileID"}}); err != nil {
+	if err := innerDepRepo.InitiateMicroDeposits(context.Background(), id.Depository("depositoryID"), "userID", []*internal.MicroDeposit{{Amount: *amt, FileID: "fileID"}}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -199,7 +220,7 @@ func TestController__startPeriodicFileOperations(t *testing.T) {
 	defer achServer.Close()
 
 	// setup transfer controller to start a manual merge and upload
-	cfg := config.Empty()
+	cfg := testConfig(t)
 	controller, err := NewController(cfg, dir, repo, achClient, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -208,7 +229,7 @@ func TestController__startPeriodicFileOperations(t *testing.T) {
 	flushIncoming, flushOutgoing := make(FlushChan, 1), make(FlushChan, 1)
 	ctx, cancelFileSync := context.WithCancel(context.Background())
 
-	go controller.StartPeriodicFileOperations(ctx, flushIncoming, flushOutgoing, depRepo, transferRepo) // async call to register the polling loop
+	go controller.StartPeriodicFileOperations(ctx, flushIncoming, flushOutgoing, depRepo, transferRepo, &internal.MockAuthorizationRepository{}) // async call to register the polling loop
 	// trigger the calls
 	flushIncoming <- &periodicFileOperationsRequest{}
 	flushOutgoing <- &periodicFileOperationsRequest{}
@@ -216,6 +237,13 @@ func TestController__startPeriodicFileOperations(t *testing.T) {
 	time.Sleep(250 * time.Millisecond)
 
 	cancelFileSync()
+
+	// the loop exits once its operations are finished
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := controller.Shutdown(shutdownCtx); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func readFileAsCloser(path string) io.ReadCloser {
@@ -232,24 +260,26 @@ type mockFileTransferAgent struct {
 	returnFiles  []File
 	uploadedFile *File        // non-nil on file upload
 	deletedFile  string       // filepath of last deleted file
+	pingErr      error        // returned from Ping
+	hang         bool         // Ping blocks until ctx is done
 	mu           sync.RWMutex // protects all fields
 }
 
-func (a *mockFileTransferAgent) GetInboundFiles() ([]File, error) {
+func (a *mockFileTransferAgent) GetInboundFiles(ctx context.Context) ([]File, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
 	return a.inboundFiles, nil
 }
 
-func (a *mockFileTransferAgent) GetReturnFiles() ([]File, error) {
+func (a *mockFileTransferAgent) GetReturnFiles(ctx context.Context) ([]File, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
 	return a.returnFiles, nil
 }
 
-func (a *mockFileTransferAgent) UploadFile(f File) error {
+func (a *mockFileTransferAgent) UploadFile(ctx context.Context, f File) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -260,7 +290,18 @@ func (a *mockFileTransferAgent) UploadFile(f File) error {
 	return nil
 }
 
-func (a *mockFileTransferAgent) Delete(path string) error {
+func (a *mockFileTransferAgent) Ping(ctx context.Context) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return a.pingErr
+}
+
+func (a *mockFileTransferAgent) Delete(ctx context.Context, path string) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -304,6 +345,9 @@ func TestController__ACHFile(t *testing.T) {
 	if fd, err := os.Stat(f.filepath); err != nil || fd.Size() == 0 {
 		t.Fatalf("fd=%v err=%v", fd, err)
 	}
+	if _, err := os.Stat(f.filepath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected temporary file to be renamed: %v", err)
+	}
 	if n := f.lineCount(); n != 10 {
 		t.Errorf("got %d for line count", n)
 	}
@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -14,7 +15,7 @@ import (
 	"github.com/moov-io/paygate/pkg/id"
 )
 
-func (c *Controller) handleNOCFile(req *periodicFileOperationsRequest, file *ach.File, filename string, depRepo internal.DepositoryRepository) error {
+func (c *Controller) handleNOCFile(ctx context.Context, req *periodicFileOperationsRequest, file *ach.File, filename string, depRepo internal.DepositoryRepository) error {
 	for i := range file.NotificationOfChange {
 		entries := file.NotificationOfChange[i].GetEntries()
 		for j := range entries {
@@ -36,7 +37,7 @@ func (c *Controller) handleNOCFile(req *periodicFileOperationsRequest, file *ach
 				break
 			}
 
-			dep, _ := depRepo.LookupDepositoryFromReturn(file.Header.ImmediateDestination, strings.TrimSpace(entries[j].DFIAccountNumber))
+			dep, _ := depRepo.LookupDepositoryFromReturn(ctx, file.Header.ImmediateDestination, strings.TrimSpace(entries[j].DFIAccountNumber))
 			if dep == nil {
 				c.logger.Log(
 					"handleNOCFile", fmt.Sprintf("depository not found file=%s", filename),
@@ -51,7 +52,7 @@ func (c *Controller) handleNOCFile(req *periodicFileOperationsRequest, file *ach
 					"userID", req.userID, "requestID", req.requestID)
 			}
 
-			if err := c.updateDepositoryFromChangeCode(changeCode, entries[j], dep, depRepo); err != nil {
+			if err := c.updateDepositoryFromChangeCode(ctx, changeCode, entries[j], dep, depRepo); err != nil {
 				c.logger.Log(
 					"handleNOCFile", fmt.Sprintf("error updating depository=%s from NOC code=%s", dep.ID, changeCode.Code), "error", err,
 					"traceNumber", entries[j].TraceNumber,
@@ -69,7 +70,7 @@ func (c *Controller) handleNOCFile(req *periodicFileOperationsRequest, file *ach
 	return nil
 }
 
-func (c *Controller) updateDepositoryFromChangeCode(code *ach.ChangeCode, ed *ach.EntryDetail, dep *internal.Depository, depRepo internal.DepositoryRepository) error {
+func (c *Controller) updateDepositoryFromChangeCode(ctx context.Context, code *ach.ChangeCode, ed *ach.EntryDetail, dep *internal.Depository, depRepo internal.DepositoryRepository) error {
 	if dep == nil {
 		return errors.New("depository not found")
 	}
@@ -92,7 +93,7 @@ func (c *Controller) updateDepositoryFromChangeCode(code *ach.ChangeCode, ed *ac
 		dep.RoutingNumber = cor.RoutingNumber
 	}
 	// Upsert the Depository after our changes
-	if err := depRepo.UpsertUserDepository(id.User(dep.UserID()), dep); err != nil {
+	if err := depRepo.UpsertUserDepository(ctx, id.User(dep.UserID()), dep); err != nil {
 		return err
 	}
 
@@ -109,10 +110,10 @@ func (c *Controller) updateDepositoryFromChangeCode(code *ach.ChangeCode, ed *ac
 	switch code.Code {
 	case "C08": // Incorrect Receiving DFI Identification (IAT Only) // unsupported
 		c.logger.Log("changeCode", fmt.Sprintf("rejecting depository=%s for IAT changeCode=%s", dep.ID, code.Code))
-		return depRepo.UpdateDepositoryStatus(dep.ID, internal.DepositoryRejected)
+		return depRepo.UpdateDepositoryStatus(ctx, dep.ID, internal.DepositoryRejected)
 
 	case "C05", "C06", "C07":
-		err := depRepo.UpdateDepositoryStatus(dep.ID, internal.DepositoryRejected)
+		err := depRepo.UpdateDepositoryStatus(ctx, dep.ID, internal.DepositoryRejected)
 		return fmt.Errorf("rejecting originalTrace=%s after new transactionCode=%d was returned: %v", ed.Addenda98.OriginalTrace, cor.TransactionCode, err)
 
 	// Internal errors
@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -15,7 +16,6 @@ import (
 	"github.com/moov-io/ach"
 	"github.com/moov-io/base"
 	"github.com/moov-io/paygate/internal"
-	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/internal/secrets"
 	"github.com/moov-io/paygate/pkg/id"
@@ -40,7 +40,7 @@ func depositoryChangeCode(t *testing.T, controller *Controller, changeCode strin
 		BankName: "my bank",
 		Status:   internal.DepositoryVerified,
 	}
-	if err := repo.UpsertUserDepository(userID, dep); err != nil {
+	if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		return nil, err
 	}
 
@@ -56,11 +56,11 @@ func depositoryChangeCode(t *testing.T, controller *Controller, changeCode strin
 	}
 	cc := &ach.ChangeCode{Code: changeCode}
 
-	if err := controller.updateDepositoryFromChangeCode(cc, ed, dep, repo); err != nil {
+	if err := controller.updateDepositoryFromChangeCode(context.Background(), cc, ed, dep, repo); err != nil {
 		return nil, err
 	}
 
-	dep, _ = repo.GetUserDepository(dep.ID, userID)
+	dep, _ = repo.GetUserDepository(context.Background(), dep.ID, userID)
 	return dep, nil
 }
 
@@ -71,11 +71,11 @@ func TestDepositories__updateDepositoryFromChangeCode(t *testing.T) {
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
 
-	repo := NewRepository("", nil, "")
+	repo := NewRepository(nil, "")
 
 	keeper := secrets.TestStringKeeper(t)
 
-	cfg := config.Empty()
+	cfg := testConfig(t)
 	controller, err := NewController(cfg, dir, repo, nil, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -113,12 +113,12 @@ func TestController__handleNOCFile(t *testing.T) {
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
 
-	repo := NewRepository("", nil, "")
+	repo := NewRepository(nil, "")
 
 	keeper := secrets.TestStringKeeper(t)
 	depRepo := internal.NewDepositoryRepo(logger, sqliteDB.DB, keeper)
 
-	cfg := config.Empty()
+	cfg := testConfig(t)
 	controller, err := NewController(cfg, dir, repo, nil, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -147,27 +147,27 @@ func TestController__handleNOCFile(t *testing.T) {
 		Status:        internal.DepositoryVerified,
 		Created:       base.NewTime(time.Now().Add(-1 * time.Second)),
 	}
-	if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		t.Fatal(err)
 	}
-	dep, _ = depRepo.GetDepository(dep.ID) // this method sets the keeper
+	dep, _ = depRepo.GetDepository(context.Background(), dep.ID) // this method sets the keeper
 
 	accountNumber := strings.TrimSpace(file.Batches[0].GetEntries()[0].DFIAccountNumber)
 	if err := dep.ReplaceAccountNumber(accountNumber); err != nil {
 		t.Fatal(err)
 	}
-	if err := depRepo.UpsertUserDepository(userID, dep); err != nil { // write encrypted account number
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil { // write encrypted account number
 		t.Fatal(err)
 	}
 
 	// run the controller
 	req := &periodicFileOperationsRequest{}
-	if err := controller.handleNOCFile(req, &file, "cor-c01.ach", depRepo); err != nil {
+	if err := controller.handleNOCFile(context.Background(), req, &file, "cor-c01.ach", depRepo); err != nil {
 		t.Error(err)
 	}
 
 	// check the Depository status
-	dep, err = depRepo.GetUserDepository(dep.ID, userID)
+	dep, err = depRepo.GetUserDepository(context.Background(), dep.ID, userID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -194,9 +194,9 @@ func TestController__handleNOCFileEmpty(t *testing.T) {
 	dir, _ := ioutil.TempDir("", "handleNOCFile")
 	defer os.RemoveAll(dir)
 
-	repo := NewRepository("", nil, "")
+	repo := NewRepository(nil, "")
 
-	cfg := config.Empty()
+	cfg := testConfig(t)
 	controller, err := NewController(cfg, dir, repo, nil, nil)
 	if err != nil {
 		t.Fatal(err)
@@ -215,13 +215,13 @@ func TestController__handleNOCFileEmpty(t *testing.T) {
 
 	// handoff the file but watch it be skipped
 	req := &periodicFileOperationsRequest{}
-	if err := controller.handleNOCFile(req, &file, "ppd-debit.ach", nil); err != nil {
+	if err := controller.handleNOCFile(context.Background(), req, &file, "ppd-debit.ach", nil); err != nil {
 		t.Error(err)
 	}
 
 	// fake a NotificationOfChange array item (but it's missing Addenda98)
 	file.NotificationOfChange = append(file.NotificationOfChange, file.Batches[0])
-	if err := controller.handleNOCFile(req, &file, "foo.ach", nil); err != nil {
+	if err := controller.handleNOCFile(context.Background(), req, &file, "foo.ach", nil); err != nil {
 		t.Error(err)
 	}
 }
@@ -233,12 +233,12 @@ func TestCorrectionsErr__updateDepositoryFromChangeCode(t *testing.T) {
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
 
-	repo := NewRepository("", nil, "")
+	repo := NewRepository(nil, "")
 
 	cc := &ach.ChangeCode{Code: "C14"}
 	ed := &ach.EntryDetail{Addenda98: &ach.Addenda98{}}
 
-	cfg := config.Empty()
+	cfg := testConfig(t)
 
 	dir, err := ioutil.TempDir("", "Controller")
 	if err != nil {
@@ -253,7 +253,7 @@ func TestCorrectionsErr__updateDepositoryFromChangeCode(t *testing.T) {
 
 	depRepo := internal.NewDepositoryRepo(logger, sqliteDB.DB, keeper)
 
-	if err := controller.updateDepositoryFromChangeCode(cc, ed, nil, depRepo); err == nil {
+	if err := controller.updateDepositoryFromChangeCode(context.Background(), cc, ed, nil, depRepo); err == nil {
 		t.Error("nil Depository, expected error")
 	} else {
 		if !strings.Contains(err.Error(), "depository not found") {
@@ -267,7 +267,7 @@ func TestCorrectionsErr__updateDepositoryFromChangeCode(t *testing.T) {
 		RoutingNumber:          "987654320",
 		EncryptedAccountNumber: "4512",
 	}
-	if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		t.Fatal(err)
 	}
 
@@ -277,7 +277,7 @@ func TestCorrectionsErr__updateDepositoryFromChangeCode(t *testing.T) {
 	ed.Addenda98.CorrectedData = ach.WriteCorrectionData(cc.Code, &ach.CorrectedData{
 		Name: "john smith",
 	})
-	if err := controller.updateDepositoryFromChangeCode(cc, ed, dep, depRepo); err == nil {
+	if err := controller.updateDepositoryFromChangeCode(context.Background(), cc, ed, dep, depRepo); err == nil {
 		t.Error("expected error")
 	} else {
 		if !strings.Contains(err.Error(), "skipping receiver individual name") {
@@ -288,7 +288,7 @@ func TestCorrectionsErr__updateDepositoryFromChangeCode(t *testing.T) {
 	// unknown change code
 	cc.Code = "C99"
 	ed.Addenda98.CorrectedData = ""
-	if err := controller.updateDepositoryFromChangeCode(cc, ed, dep, depRepo); err == nil {
+	if err := controller.updateDepositoryFromChangeCode(context.Background(), cc, ed, dep, depRepo); err == nil {
 		t.Error("expected error")
 	} else {
 		if !strings.Contains(err.Error(), "missing Addenda98 record") {
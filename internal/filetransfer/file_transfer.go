@@ -6,12 +6,15 @@ package filetransfer
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
 	"time"
 
+	"github.com/moov-io/paygate/internal/config"
+
 	"github.com/go-kit/kit/log"
 )
 
@@ -45,10 +48,14 @@ func (f File) Close() error {
 
 // Agent represents an interface for uploading and retrieving ACH files from a remote service.
 type Agent interface {
-	GetInboundFiles() ([]File, error)
-	GetReturnFiles() ([]File, error)
-	UploadFile(f File) error
-	Delete(path string) error
+	GetInboundFiles(ctx context.Context) ([]File, error)
+	GetReturnFiles(ctx context.Context) ([]File, error)
+	UploadFile(ctx context.Context, f File) error
+	Delete(ctx context.Context, path string) error
+
+	// Ping checks the server can be reached with our credentials by listing the inbound and outbound directories.
+	// The Agent is closed if ctx is done before the server answers.
+	Ping(ctx context.Context) error
 
 	hostname() string
 
@@ -61,26 +68,45 @@ type Agent interface {
 
 // New returns an implementation of a Agent which is used to upload files to a remote server.
 //
-// This function reads ACH_FILE_TRANSFERS_ROOT_CAFILE for a file with additional root certificates to be used in all secured connections.
-func New(logger log.Logger, _type string, cfg *Config, repo Repository) (Agent, error) {
+// ftpConfig and sftpConfig control how connections are made, ftpConfig.CAFile is a file with additional
+// root certificates to be used in all secured FTP connections.
+func New(ctx context.Context, logger log.Logger, _type string, cfg *Config, repo Repository, ftpConfig config.FTPConfig, sftpConfig config.SFTPConfig) (Agent, error) {
 	switch strings.ToLower(_type) {
 	case "ftp":
-		ftpConfigs, err := repo.GetFTPConfigs()
+		ftpConfigs, err := repo.GetFTPConfigs(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("filetransfer: error creating new FTP client: %v", err)
 		}
-		return newFTPTransferAgent(logger, cfg, ftpConfigs)
+		return newFTPTransferAgent(logger, cfg, ftpConfigs, ftpConfig)
 	case "sftp":
-		sftpConfigs, err := repo.GetSFTPConfigs()
+		sftpConfigs, err := repo.GetSFTPConfigs(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("filetransfer: error creating new SFTP client: %v", err)
 		}
-		return newSFTPTransferAgent(logger, cfg, sftpConfigs)
+		return newSFTPTransferAgent(logger, cfg, sftpConfigs, sftpConfig)
 	default:
 		return nil, fmt.Errorf("filetransfer: unknown type '%s'", _type)
 	}
 }
 
+// closeOnDone closes c if ctx is done before the returned func is called, which unblocks
+// calls stuck waiting on a server that never answers. c is never closed after stop returns.
+func closeOnDone(ctx context.Context, c io.Closer) (stop func()) {
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
 // CutoffTime represents the time of a banking day when all ACH files need to be uploaded in order
 // to be processed for that day. Files which miss the cutoff time won't be processed until the next day.
 //
@@ -6,6 +6,7 @@ package filetransfer
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"strings"
 	"testing"
@@ -76,3 +77,37 @@ func TestCutoffTime__JSON(t *testing.T) {
 		t.Error(buf.String())
 	}
 }
+
+type closeCounter struct {
+	closed chan struct{}
+}
+
+func (c *closeCounter) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestCloseOnDone(t *testing.T) {
+	// stopped before ctx is done
+	c := &closeCounter{closed: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	closeOnDone(ctx, c)()
+	cancel()
+	select {
+	case <-c.closed:
+		t.Error("closed after stop")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// ctx is done first
+	c = &closeCounter{closed: make(chan struct{})}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	stop := closeOnDone(ctx, c)
+	defer stop()
+	select {
+	case <-c.closed:
+	case <-time.After(5 * time.Second):
+		t.Error("expected Close")
+	}
+}
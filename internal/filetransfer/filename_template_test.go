@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -97,7 +98,7 @@ func TestFilenameTemplate__validateTemplate(t *testing.T) {
 }
 
 func TestFilenameTemplate__ValidateTemplates(t *testing.T) {
-	if err := ValidateTemplates(NewRepository("", nil, "")); err != nil {
+	if err := ValidateTemplates(NewRepository(nil, "")); err != nil {
 		t.Errorf("expected no error: %v", err)
 	}
 
@@ -107,7 +108,7 @@ func TestFilenameTemplate__ValidateTemplates(t *testing.T) {
 	}
 
 	// write a valid template and check it
-	err := repo.upsertConfig(&Config{
+	err := repo.upsertConfig(context.Background(), &Config{
 		RoutingNumber:            "987654320",
 		OutboundFilenameTemplate: `{{ date "20060102" }}`,
 	})
@@ -119,7 +120,7 @@ func TestFilenameTemplate__ValidateTemplates(t *testing.T) {
 	}
 
 	// write an invalid template and check it
-	err = repo.upsertConfig(&Config{
+	err = repo.upsertConfig(context.Background(), &Config{
 		RoutingNumber:            "123456789",
 		OutboundFilenameTemplate: `{{ .Invalid }`,
 	})
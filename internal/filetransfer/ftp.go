@@ -6,37 +6,22 @@ package filetransfer
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
 	"path/filepath"
 	"strings"
 	"sync"
-	"time"
+
+	"github.com/moov-io/paygate/internal/config"
 
 	"github.com/go-kit/kit/log"
 	"github.com/jlaffaye/ftp"
 )
 
-var (
-	ftpDialTimeout = func() time.Duration {
-		if v := os.Getenv("FTP_DIAL_TIMEOUT"); v != "" {
-			if dur, _ := time.ParseDuration(v); dur > 0 {
-				return dur
-			}
-		}
-		return 10 * time.Second
-	}()
-
-	ftpDialWithDisabledEPSV = func() bool {
-		v := os.Getenv("FTP_DIAL_WITH_DISABLED_ESPV")
-		return strings.EqualFold(v, "true") || strings.EqualFold(v, "yes")
-	}()
-)
-
 type FTPConfig struct {
 	RoutingNumber string `yaml:"routingNumber"`
 	Hostname      string `yaml:"hostname"`
@@ -87,17 +72,17 @@ func (a *FTPTransferAgent) findConfig() *FTPConfig {
 	return nil
 }
 
-func newFTPTransferAgent(logger log.Logger, cfg *Config, ftpConfigs []*FTPConfig) (*FTPTransferAgent, error) {
+func newFTPTransferAgent(logger log.Logger, cfg *Config, ftpConfigs []*FTPConfig, settings config.FTPConfig) (*FTPTransferAgent, error) {
 	agent := &FTPTransferAgent{cfg: cfg, ftpConfigs: ftpConfigs, logger: logger}
 	ftpConf := agent.findConfig()
 	if ftpConf == nil {
 		return nil, fmt.Errorf("ftp: unable to find config for %s", cfg.RoutingNumber)
 	}
 	opts := []ftp.DialOption{
-		ftp.DialWithTimeout(ftpDialTimeout),
-		ftp.DialWithDisabledEPSV(ftpDialWithDisabledEPSV),
+		ftp.DialWithTimeout(settings.DialTimeout),
+		ftp.DialWithDisabledEPSV(settings.DisabledEPSV),
 	}
-	tlsOpt, err := tlsDialOption(os.Getenv("ACH_FILE_TRANSFERS_CAFILE"))
+	tlsOpt, err := tlsDialOption(settings.CAFile)
 	if err != nil {
 		return nil, err
 	}
@@ -108,10 +93,11 @@ func newFTPTransferAgent(logger log.Logger, cfg *Config, ftpConfigs []*FTPConfig
 	// Make the first connection
 	conn, err := ftp.Dial(ftpConf.Hostname, opts...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("ftp: connect to %s: %v", ftpConf.Hostname, err)
 	}
 	if err := conn.Login(ftpConf.Username, ftpConf.Password); err != nil {
-		return nil, err
+		conn.Quit()
+		return nil, fmt.Errorf("ftp: login as %s: %v", ftpConf.Username, err)
 	}
 	agent.conn = conn
 	return agent, nil
@@ -156,7 +142,23 @@ func (agent *FTPTransferAgent) ReturnPath() string {
 	return agent.cfg.ReturnPath
 }
 
-func (agent *FTPTransferAgent) Delete(path string) error {
+func (agent *FTPTransferAgent) Ping(ctx context.Context) error {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+	defer closeOnDone(ctx, agent)()
+
+	for _, dir := range []string{agent.cfg.InboundPath, agent.cfg.OutboundPath} {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := agent.conn.List(dir); err != nil {
+			return fmt.Errorf("ftp: ping %s: %v", dir, err)
+		}
+	}
+	return nil
+}
+
+func (agent *FTPTransferAgent) Delete(ctx context.Context, path string) error {
 	if path == "" || strings.HasSuffix(path, "/") {
 		return fmt.Errorf("FTPTransferAgent: invalid path %v", path)
 	}
@@ -166,12 +168,16 @@ func (agent *FTPTransferAgent) Delete(path string) error {
 // uploadFile saves the content of File at the given filename in the OutboundPath directory
 //
 // The File's contents will always be closed
-func (agent *FTPTransferAgent) UploadFile(f File) error {
+func (agent *FTPTransferAgent) UploadFile(ctx context.Context, f File) error {
 	defer f.Close()
 
 	agent.mu.Lock()
 	defer agent.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	ftpConf := agent.findConfig()
 	if ftpConf == nil {
 		return fmt.Errorf("ftp.uploadFile: unable to find config for %s", agent.cfg.RoutingNumber)
@@ -197,15 +203,15 @@ func (agent *FTPTransferAgent) UploadFile(f File) error {
 	return agent.conn.Stor(filepath.Base(f.Filename), f.Contents)
 }
 
-func (agent *FTPTransferAgent) GetInboundFiles() ([]File, error) {
-	return agent.readFiles(agent.cfg.InboundPath)
+func (agent *FTPTransferAgent) GetInboundFiles(ctx context.Context) ([]File, error) {
+	return agent.readFiles(ctx, agent.cfg.InboundPath)
 }
 
-func (agent *FTPTransferAgent) GetReturnFiles() ([]File, error) {
-	return agent.readFiles(agent.cfg.ReturnPath)
+func (agent *FTPTransferAgent) GetReturnFiles(ctx context.Context) ([]File, error) {
+	return agent.readFiles(ctx, agent.cfg.ReturnPath)
 }
 
-func (agent *FTPTransferAgent) readFiles(path string) ([]File, error) {
+func (agent *FTPTransferAgent) readFiles(ctx context.Context, path string) ([]File, error) {
 	agent.mu.Lock()
 	defer agent.mu.Unlock()
 
@@ -231,6 +237,9 @@ func (agent *FTPTransferAgent) readFiles(path string) ([]File, error) {
 	}
 	var files []File
 	for i := range items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		resp, err := agent.conn.Retr(items[i])
 		if err != nil {
 			return nil, fmt.Errorf("problem retrieving %s: %v", items[i], err)
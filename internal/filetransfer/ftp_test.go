@@ -6,6 +6,7 @@ package filetransfer
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -144,7 +145,7 @@ func createTestFTPAgent(t *testing.T) (*server.Server, *FTPTransferAgent) {
 			Password: auth.Password,
 		},
 	}
-	agent, err := newFTPTransferAgent(log.NewNopLogger(), conf, ftpConfigs)
+	agent, err := newFTPTransferAgent(log.NewNopLogger(), conf, ftpConfigs, testConfig(t).FileTransfer.FTP)
 	if err != nil {
 		svc.Shutdown()
 		t.Fatalf("problem creating FileTransferAgent: %v", err)
@@ -170,6 +171,30 @@ func TestFTPAgent(t *testing.T) {
 	}
 }
 
+func TestFTPAgent__Ping(t *testing.T) {
+	svc, agent := createTestFTPAgent(t)
+	defer agent.Close()
+	defer svc.Shutdown()
+
+	// Create outbound directory
+	os.Mkdir(filepath.Join("..", "..", "testdata", "ftp-server", agent.OutboundPath()), 0777)
+
+	if err := agent.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	agent.cfg.InboundPath = "missing"
+	if err := agent.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "ftp: ping missing") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := agent.Ping(ctx); err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestFTPAgent__hostname(t *testing.T) {
 	agent := &FTPTransferAgent{
 		cfg: &Config{
@@ -213,7 +238,7 @@ func TestFTP__getInboundFiles(t *testing.T) {
 	defer agent.Close()
 	defer svc.Shutdown()
 
-	files, err := agent.GetInboundFiles()
+	files, err := agent.GetInboundFiles(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -231,7 +256,7 @@ func TestFTP__getInboundFiles(t *testing.T) {
 	}
 
 	// make sure we perform the same call and get the same result
-	files, err = agent.GetInboundFiles()
+	files, err = agent.GetInboundFiles(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -254,7 +279,7 @@ func TestFTP__getReturnFiles(t *testing.T) {
 	defer agent.Close()
 	defer svc.Shutdown()
 
-	files, err := agent.GetReturnFiles()
+	files, err := agent.GetReturnFiles(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -271,7 +296,7 @@ func TestFTP__getReturnFiles(t *testing.T) {
 	}
 
 	// make sure we perform the same call and get the same result
-	files, err = agent.GetReturnFiles()
+	files, err = agent.GetReturnFiles(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -298,7 +323,7 @@ func TestFTP__uploadFile(t *testing.T) {
 	parent := filepath.Join("..", "..", "testdata", "ftp-server", agent.OutboundPath())
 	os.Mkdir(parent, 0777)
 
-	if err := agent.UploadFile(f); err != nil {
+	if err := agent.UploadFile(context.Background(), f); err != nil {
 		t.Fatal(err)
 	}
 
@@ -318,13 +343,13 @@ func TestFTP__uploadFile(t *testing.T) {
 	}
 
 	// delete the file
-	if err := agent.Delete(f.Filename); err != nil {
+	if err := agent.Delete(context.Background(), f.Filename); err != nil {
 		t.Fatal(err)
 	}
 
 	// get an error with no FTP configs
 	agent.ftpConfigs = nil
-	if err := agent.UploadFile(f); err == nil {
+	if err := agent.UploadFile(context.Background(), f); err == nil {
 		t.Error("expected error")
 	}
 }
@@ -0,0 +1,253 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moov-io/base/admin"
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	endpointUp = prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Name: "file_transfer_endpoint_up",
+		Help: "Gauge of ODFI file transfer servers paygate could connect, login and list directories on (1) or not (0)",
+	}, []string{"routing_number", "type"})
+
+	endpointLatency = prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Name: "file_transfer_endpoint_check_seconds",
+		Help: "Gauge of how long the last check of an ODFI's file transfer server took",
+	}, []string{"routing_number", "type"})
+)
+
+// endpointCheckTimeout bounds how long checking one ODFI's server can take, which covers
+// servers that accept connections but never answer.
+const endpointCheckTimeout = 30 * time.Second
+
+// EndpointStatus is the result of checking an ODFI's FTP or SFTP server.
+type EndpointStatus struct {
+	RoutingNumber string    `json:"routingNumber"`
+	Type          string    `json:"type"`
+	Hostname      string    `json:"hostname,omitempty"`
+	Healthy       bool      `json:"healthy"`
+	Error         string    `json:"error,omitempty"`
+	LatencyMillis int64     `json:"latencyMillis"`
+	CheckedAt     time.Time `json:"checkedAt"`
+}
+
+// HealthChecker periodically connects to each ODFI's file transfer server, logs in and lists the inbound
+// and outbound directories so broken credentials or paths are found before a cutoff time.
+type HealthChecker struct {
+	logger log.Logger
+	repo   Repository
+
+	ftp  config.FTPConfig
+	sftp config.SFTPConfig
+
+	interval  time.Duration
+	readiness string // "all" or "any" ODFI servers need to pass for Ready
+
+	// newAgent connects to an ODFI's server, tests replace it
+	newAgent func(ctx context.Context, logger log.Logger, _type string, cfg *Config, repo Repository, ftpConfig config.FTPConfig, sftpConfig config.SFTPConfig) (Agent, error)
+
+	mu       sync.RWMutex
+	statuses []*EndpointStatus // sorted by routing number, nil until the first check
+
+	ctx      context.Context
+	shutdown context.CancelFunc
+}
+
+func NewHealthChecker(cfg *config.Config, repo Repository) *HealthChecker {
+	ctx, shutdown := context.WithCancel(context.Background())
+	return &HealthChecker{
+		logger:    cfg.Logger,
+		repo:      repo,
+		ftp:       cfg.FileTransfer.FTP,
+		sftp:      cfg.FileTransfer.SFTP,
+		interval:  cfg.FileTransfer.HealthCheckInterval,
+		readiness: cfg.FileTransfer.Readiness,
+		newAgent:  New,
+		ctx:       ctx,
+		shutdown:  shutdown,
+	}
+}
+
+func (hc *HealthChecker) Close() {
+	if hc == nil {
+		return
+	}
+	hc.shutdown()
+}
+
+// Start checks every ODFI's server right away and then on each interval until Close is called.
+func (hc *HealthChecker) Start() error {
+	if hc == nil || hc.repo == nil {
+		return errors.New("nil HealthChecker or file transfer Repository")
+	}
+
+	tick := time.NewTicker(hc.interval)
+	defer tick.Stop()
+	hc.logger.Log("file-transfer-health", fmt.Sprintf("checking ODFI file transfer servers every %v", hc.interval))
+
+	for {
+		if err := hc.CheckAll(hc.ctx); err != nil {
+			hc.logger.Log("file-transfer-health", err.Error())
+		}
+
+		select {
+		case <-tick.C:
+		case <-hc.ctx.Done():
+			hc.logger.Log("file-transfer-health", "HealthChecker: shutdown")
+			return nil
+		}
+	}
+}
+
+// CheckAll checks the server of every ODFI with a file transfer config at the same time and records the results.
+func (hc *HealthChecker) CheckAll(ctx context.Context) error {
+	configs, err := hc.repo.GetConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("problem reading file transfer configs: %v", err)
+	}
+
+	statuses := make([]*EndpointStatus, len(configs))
+	var wg sync.WaitGroup
+	for i := range configs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = hc.check(ctx, configs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].RoutingNumber < statuses[j].RoutingNumber })
+	for i := range statuses {
+		up := 0.0
+		if statuses[i].Healthy {
+			up = 1.0
+		} else {
+			hc.logger.Log("file-transfer-health", fmt.Sprintf("problem with %s server for %s: %s", statuses[i].Type, statuses[i].RoutingNumber, statuses[i].Error))
+		}
+		endpointUp.With("routing_number", statuses[i].RoutingNumber, "type", statuses[i].Type).Set(up)
+		endpointLatency.With("routing_number", statuses[i].RoutingNumber, "type", statuses[i].Type).Set(float64(statuses[i].LatencyMillis) / 1000)
+	}
+
+	hc.mu.Lock()
+	hc.statuses = statuses
+	hc.mu.Unlock()
+
+	return nil
+}
+
+// check connects and logs into cfg's server, which fails on bad credentials, and then lists its directories.
+// Servers outside of cfg.AllowedIPs fail the check as uploads to them are blocked.
+func (hc *HealthChecker) check(ctx context.Context, cfg *Config) *EndpointStatus {
+	status := &EndpointStatus{
+		RoutingNumber: cfg.RoutingNumber,
+		Type:          findTransferType(ctx, hc.repo, cfg.RoutingNumber),
+		CheckedAt:     time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, endpointCheckTimeout)
+	defer cancel()
+
+	err := func() error {
+		agent, err := hc.newAgent(ctx, hc.logger, status.Type, cfg, hc.repo, hc.ftp, hc.sftp)
+		if err != nil {
+			return err
+		}
+		defer agent.Close()
+
+		status.Hostname = agent.hostname()
+		if err := rejectOutboundIPRange(cfg, status.Hostname); err != nil {
+			return fmt.Errorf("uploads are blocked: %v", err)
+		}
+		return agent.Ping(ctx)
+	}()
+
+	status.LatencyMillis = time.Since(status.CheckedAt).Milliseconds()
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%v: %v", ctx.Err(), err)
+		}
+		status.Error = err.Error()
+	} else {
+		status.Healthy = true
+	}
+	return status
+}
+
+// Statuses returns the result of the last check for each ODFI.
+func (hc *HealthChecker) Statuses() []*EndpointStatus {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.statuses
+}
+
+// Ready returns an error listing the ODFIs whose server failed the last check, or when no check has finished yet.
+// With a readiness of "any" only one ODFI's server needs to pass.
+func (hc *HealthChecker) Ready() error {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+
+	if hc.statuses == nil {
+		return errors.New("file transfer servers haven't been checked yet")
+	}
+	var failed []string
+	for i := range hc.statuses {
+		if !hc.statuses[i].Healthy {
+			failed = append(failed, fmt.Sprintf("%s: %s", hc.statuses[i].RoutingNumber, hc.statuses[i].Error))
+		}
+	}
+	if len(failed) == 0 || (hc.readiness == "any" && len(failed) < len(hc.statuses)) {
+		return nil
+	}
+	return fmt.Errorf("file transfer servers failed their check: %s", strings.Join(failed, ", "))
+}
+
+// AddFileTransferHealthRoutes registers the readiness check and admin HTTP route for checking ODFI file transfer servers.
+func AddFileTransferHealthRoutes(logger log.Logger, svc *admin.Server, checker *HealthChecker) {
+	svc.AddReadinessCheck("fileTransfers", checker.Ready)
+	svc.AddHandler("/files/health", getEndpointStatuses(logger, checker))
+}
+
+// getEndpointStatuses returns the last check of each ODFI's server. Calling with ?refresh checks them again first.
+func getEndpointStatuses(logger log.Logger, checker *HealthChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			moovhttp.Problem(w, fmt.Errorf("unsupported HTTP verb %s", r.Method))
+			return
+		}
+		if _, exists := r.URL.Query()["refresh"]; exists {
+			if err := checker.CheckAll(r.Context()); err != nil {
+				moovhttp.Problem(w, err)
+				return
+			}
+		}
+
+		statuses := checker.Statuses()
+		if statuses == nil {
+			statuses = []*EndpointStatus{}
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(statuses)
+	}
+}
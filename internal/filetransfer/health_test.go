@@ -0,0 +1,209 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+)
+
+func testHealthChecker(t *testing.T) *HealthChecker {
+	t.Helper()
+
+	repo := &mockRepository{
+		configs: []*Config{
+			{RoutingNumber: "987654320", InboundPath: "inbound/", OutboundPath: "outbound/"},
+			{RoutingNumber: "121042882", InboundPath: "inbound/", OutboundPath: "outbound/"},
+			{RoutingNumber: "231380104", InboundPath: "inbound/", OutboundPath: "outbound/"},
+		},
+		ftpConfigs: []*FTPConfig{
+			{RoutingNumber: "121042882", Hostname: "ftp.example.com"},
+		},
+		sftpConfigs: []*SFTPConfig{
+			{RoutingNumber: "987654320", Hostname: "sftp.example.com"},
+			{RoutingNumber: "231380104", Hostname: "sftp.example.com"},
+		},
+	}
+	checker := NewHealthChecker(testConfig(t), repo)
+	checker.logger = log.NewNopLogger()
+	checker.newAgent = func(ctx context.Context, logger log.Logger, _type string, cfg *Config, repo Repository, ftpConfig config.FTPConfig, sftpConfig config.SFTPConfig) (Agent, error) {
+		switch cfg.RoutingNumber {
+		case "121042882":
+			return nil, errors.New("ftp: login as moov: 530 Login incorrect")
+		case "231380104":
+			return &mockFileTransferAgent{pingErr: errors.New("sftp: ping outbound/: permission denied")}, nil
+		}
+		return &mockFileTransferAgent{}, nil
+	}
+	return checker
+}
+
+func TestHealthChecker(t *testing.T) {
+	checker := testHealthChecker(t)
+
+	if err := checker.Ready(); err == nil || !strings.Contains(err.Error(), "haven't been checked") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checker.CheckAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := checker.Statuses()
+	if len(statuses) != 3 {
+		t.Fatalf("got %d statuses", len(statuses))
+	}
+	if s := statuses[0]; s.RoutingNumber != "121042882" || s.Type != "ftp" || s.Healthy || !strings.Contains(s.Error, "530 Login incorrect") {
+		t.Errorf("unexpected status: %#v", s)
+	}
+	if s := statuses[1]; s.RoutingNumber != "231380104" || s.Type != "sftp" || s.Healthy || s.Hostname != "moov.io" || !strings.Contains(s.Error, "permission denied") {
+		t.Errorf("unexpected status: %#v", s)
+	}
+	if s := statuses[2]; s.RoutingNumber != "987654320" || !s.Healthy || s.Error != "" || s.CheckedAt.IsZero() {
+		t.Errorf("unexpected status: %#v", s)
+	}
+
+	err := checker.Ready()
+	if err == nil || !strings.Contains(err.Error(), "121042882: ftp: login") || !strings.Contains(err.Error(), "231380104: sftp: ping") || strings.Contains(err.Error(), "987654320") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// one healthy server is enough
+	checker.readiness = "any"
+	if err := checker.Ready(); err != nil {
+		t.Error(err)
+	}
+
+	// every server is healthy
+	checker.readiness = "all"
+	checker.newAgent = func(ctx context.Context, logger log.Logger, _type string, cfg *Config, repo Repository, ftpConfig config.FTPConfig, sftpConfig config.SFTPConfig) (Agent, error) {
+		return &mockFileTransferAgent{}, nil
+	}
+	if err := checker.CheckAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := checker.Ready(); err != nil {
+		t.Error(err)
+	}
+
+	// no server is healthy
+	checker.readiness = "any"
+	checker.newAgent = func(ctx context.Context, logger log.Logger, _type string, cfg *Config, repo Repository, ftpConfig config.FTPConfig, sftpConfig config.SFTPConfig) (Agent, error) {
+		return nil, errors.New("connection refused")
+	}
+	if err := checker.CheckAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := checker.Ready(); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHealthChecker__check(t *testing.T) {
+	checker := testHealthChecker(t)
+	cfg := &Config{RoutingNumber: "987654320", InboundPath: "inbound/", OutboundPath: "outbound/"}
+
+	if s := checker.check(context.Background(), cfg); !s.Healthy || s.Hostname != "moov.io" {
+		t.Errorf("unexpected status: %#v", s)
+	}
+
+	// moov.io isn't an allowed IP so uploads to it are blocked
+	cfg.AllowedIPs = "10.0.0.0/8"
+	if s := checker.check(context.Background(), cfg); s.Healthy || !strings.Contains(s.Error, "uploads are blocked") {
+		t.Errorf("unexpected status: %#v", s)
+	}
+	cfg.AllowedIPs = ""
+
+	// the server never answers
+	checker.newAgent = func(ctx context.Context, logger log.Logger, _type string, cfg *Config, repo Repository, ftpConfig config.FTPConfig, sftpConfig config.SFTPConfig) (Agent, error) {
+		return &mockFileTransferAgent{hang: true}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if s := checker.check(ctx, cfg); s.Healthy || !strings.Contains(s.Error, "context deadline exceeded") {
+		t.Errorf("unexpected status: %#v", s)
+	}
+}
+
+func TestHealthChecker__repoError(t *testing.T) {
+	checker := testHealthChecker(t)
+	checker.repo = &mockRepository{err: errors.New("bad error")}
+
+	if err := checker.CheckAll(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+	if statuses := checker.Statuses(); statuses != nil {
+		t.Errorf("unexpected statuses: %#v", statuses)
+	}
+}
+
+func TestHealthChecker__Start(t *testing.T) {
+	checker := testHealthChecker(t)
+	checker.interval = time.Hour
+
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Start()
+	}()
+
+	// the first check happens right away
+	for i := 0; i < 100 && len(checker.Statuses()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := len(checker.Statuses()); n != 3 {
+		t.Errorf("got %d statuses", n)
+	}
+
+	checker.Close()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+
+	var nilChecker *HealthChecker
+	if err := nilChecker.Start(); err == nil {
+		t.Error("expected error")
+	}
+	nilChecker.Close()
+}
+
+func TestHealthChecker__getEndpointStatuses(t *testing.T) {
+	checker := testHealthChecker(t)
+	handler := getEndpointStatuses(log.NewNopLogger(), checker)
+
+	// nothing has been checked
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/files/health", nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/files/health?refresh", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	var statuses []*EndpointStatus
+	if err := json.NewDecoder(w.Body).Decode(&statuses); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 || statuses[2].RoutingNumber != "987654320" || !statuses[2].Healthy {
+		t.Errorf("unexpected statuses: %#v", statuses)
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/files/health", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+}
@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -27,19 +28,19 @@ var (
 // downloadAndProcessIncomingFiles will take each cutoffTime initialized with the controller and retrieve all files
 // on the remote server for them. After this method will call processInboundFiles and processReturnFiles on each
 // downloaded file.
-func (c *Controller) downloadAndProcessIncomingFiles(req *periodicFileOperationsRequest, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository) error {
+func (c *Controller) downloadAndProcessIncomingFiles(ctx context.Context, req *periodicFileOperationsRequest, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository, authorizationRepo internal.AuthorizationRepository) error {
 	dir, err := ioutil.TempDir(c.rootDir, "downloaded")
 	if err != nil {
 		return err
 	}
 	defer os.RemoveAll(dir)
 
-	cutoffTimes, err := c.repo.GetCutoffTimes()
+	cutoffTimes, err := c.repo.GetCutoffTimes(ctx)
 	if err != nil {
 		return fmt.Errorf("cutoff times: %v", err)
 	}
 	for i := range cutoffTimes {
-		fileTransferConf := c.findFileTransferConfig(cutoffTimes[i].RoutingNumber)
+		fileTransferConf := c.findFileTransferConfig(ctx, cutoffTimes[i].RoutingNumber)
 		if fileTransferConf == nil {
 			c.logger.Log(
 				"downloadAndProcessIncomingFiles", fmt.Sprintf("missing file transfer config for %s", cutoffTimes[i].RoutingNumber),
@@ -47,7 +48,7 @@ func (c *Controller) downloadAndProcessIncomingFiles(req *periodicFileOperations
 			missingFileUploadConfigs.With("routing_number", cutoffTimes[i].RoutingNumber).Add(1)
 			continue
 		}
-		if err := c.downloadAllFiles(dir, fileTransferConf); err != nil {
+		if err := c.downloadAllFiles(ctx, dir, fileTransferConf); err != nil {
 			c.logger.Log(
 				"downloadAndProcessIncomingFiles", fmt.Sprintf("error downloading files into %s", dir), "error", err,
 				"userID", req.userID, "requestID", req.requestID)
@@ -55,13 +56,13 @@ func (c *Controller) downloadAndProcessIncomingFiles(req *periodicFileOperations
 		}
 
 		// Read and process inbound and returned files
-		if err := c.processInboundFiles(req, filepath.Join(dir, fileTransferConf.InboundPath), depRepo); err != nil {
+		if err := c.processInboundFiles(ctx, req, filepath.Join(dir, fileTransferConf.InboundPath), depRepo); err != nil {
 			c.logger.Log(
 				"downloadAndProcessIncomingFiles", fmt.Sprintf("problem reading inbound files in %s", dir), "error", err,
 				"userID", req.userID, "requestID", req.requestID)
 			continue
 		}
-		if err := c.processReturnFiles(filepath.Join(dir, fileTransferConf.ReturnPath), depRepo, transferRepo); err != nil {
+		if err := c.processReturnFiles(ctx, filepath.Join(dir, fileTransferConf.ReturnPath), depRepo, transferRepo, authorizationRepo); err != nil {
 			c.logger.Log(
 				"downloadAndProcessIncomingFiles", fmt.Sprintf("problem reading return files in %s", dir), "error", err,
 				"userID", req.userID, "requestID", req.requestID)
@@ -73,21 +74,21 @@ func (c *Controller) downloadAndProcessIncomingFiles(req *periodicFileOperations
 }
 
 // downloadAllFiles will setup directories for each routing number and initiate downloading and writing the files to sub-directories.
-func (c *Controller) downloadAllFiles(dir string, fileTransferConf *Config) error {
-	agent, err := New(c.logger, c.findTransferType(fileTransferConf.RoutingNumber), fileTransferConf, c.repo)
+func (c *Controller) downloadAllFiles(ctx context.Context, dir string, fileTransferConf *Config) error {
+	agent, err := New(ctx, c.logger, c.findTransferType(ctx, fileTransferConf.RoutingNumber), fileTransferConf, c.repo, c.ftp, c.sftp)
 	if err != nil {
 		return fmt.Errorf("downloadAllFiles: problem with %s file transfer agent init: %v", fileTransferConf.RoutingNumber, err)
 	}
 	defer agent.Close()
 
 	// Setup file downloads
-	if err := c.saveRemoteFiles(agent, dir); err != nil {
+	if err := c.saveRemoteFiles(ctx, agent, dir); err != nil {
 		c.logger.Log("downloadAllFiles", fmt.Sprintf("ERROR downloading files (ABA: %s)", fileTransferConf.RoutingNumber), "error", err)
 	}
 	return nil
 }
 
-func (c *Controller) processInboundFiles(req *periodicFileOperationsRequest, dir string, depRepo internal.DepositoryRepository) error {
+func (c *Controller) processInboundFiles(ctx context.Context, req *periodicFileOperationsRequest, dir string, depRepo internal.DepositoryRepository) error {
 	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if (err != nil && err != filepath.SkipDir) || info.IsDir() {
 			return nil // Ignore SkipDir and directories
@@ -107,7 +108,7 @@ func (c *Controller) processInboundFiles(req *periodicFileOperationsRequest, dir
 		// Handle any NOC Batches
 		if len(file.NotificationOfChange) > 0 {
 			inboundFilesProcessed.With("destination", file.Header.ImmediateDestination, "origin", file.Header.ImmediateOrigin).Add(1)
-			if err := c.handleNOCFile(req, file, info.Name(), depRepo); err != nil {
+			if err := c.handleNOCFile(ctx, req, file, info.Name(), depRepo); err != nil {
 				c.logger.Log(
 					"processInboundFiles", fmt.Sprintf("problem with inbound NOC file %s", path), "error", err,
 					"userID", req.userID, "requestID", req.requestID)
@@ -123,11 +124,11 @@ func (c *Controller) processInboundFiles(req *periodicFileOperationsRequest, dir
 }
 
 // saveRemoteFiles will write all inbound and return ACH files for a given routing number to the specified directory
-func (c *Controller) saveRemoteFiles(agent Agent, dir string) error {
+func (c *Controller) saveRemoteFiles(ctx context.Context, agent Agent, dir string) error {
 	var errors []string
 
 	// Download and save inbound files
-	files, err := agent.GetInboundFiles()
+	files, err := agent.GetInboundFiles(ctx)
 	if err != nil {
 		errors = append(errors, fmt.Sprintf("%T: GetInboundFiles error=%v", agent, err))
 	}
@@ -141,13 +142,13 @@ func (c *Controller) saveRemoteFiles(agent Agent, dir string) error {
 	for i := range files {
 		c.logger.Log("saveRemoteFiles", fmt.Sprintf("%T: copied down inbound file %s", agent, files[i].Filename))
 
-		if err := agent.Delete(filepath.Join(agent.InboundPath(), files[i].Filename)); err != nil {
+		if err := agent.Delete(ctx, filepath.Join(agent.InboundPath(), files[i].Filename)); err != nil {
 			errors = append(errors, fmt.Sprintf("%T: inbound Delete filename=%s error=%v", agent, files[i].Filename, err))
 		}
 	}
 
 	// Download and save returned files
-	files, err = agent.GetReturnFiles()
+	files, err = agent.GetReturnFiles(ctx)
 	if err != nil {
 		errors = append(errors, fmt.Sprintf("%T: GetReturnFiles error=%v", agent, err))
 	}
@@ -161,7 +162,7 @@ func (c *Controller) saveRemoteFiles(agent Agent, dir string) error {
 	for i := range files {
 		c.logger.Log("saveRemoteFiles", fmt.Sprintf("%T: copied down return file %s", agent, files[i].Filename))
 
-		if err := agent.Delete(filepath.Join(agent.ReturnPath(), files[i].Filename)); err != nil {
+		if err := agent.Delete(ctx, filepath.Join(agent.ReturnPath(), files[i].Filename)); err != nil {
 			errors = append(errors, fmt.Sprintf("%T: return Delete filename=%s error=%v", agent, files[i].Filename, err))
 		}
 	}
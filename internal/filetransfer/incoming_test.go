@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -64,7 +65,7 @@ func TestController__saveRemoteFiles(t *testing.T) {
 		rootDir: dir, // use our temp dir
 		logger:  log.NewNopLogger(),
 	}
-	if err := controller.saveRemoteFiles(agent, dir); err != nil {
+	if err := controller.saveRemoteFiles(context.Background(), agent, dir); err != nil {
 		t.Error(err)
 	}
 
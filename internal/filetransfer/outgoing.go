@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/moov-io/ach"
 	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/internal/tracing"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/metrics/prometheus"
@@ -36,7 +38,7 @@ var (
 
 // mergeTransfer will attempt to add the Batches from `file` into our mergableFile. If mergableFile exceeds ACH
 // file size/length limitations then a new file will be created and the old returned for uplaod.
-func (c *Controller) mergeTransfer(file *ach.File, mergableFile *achFile) (*achFile, error) {
+func (c *Controller) mergeTransfer(ctx context.Context, file *ach.File, mergableFile *achFile) (*achFile, error) {
 	if len(file.Batches) == 0 {
 		return nil, errors.New("mergeTransfer: empty batches")
 	}
@@ -62,7 +64,7 @@ func (c *Controller) mergeTransfer(file *ach.File, mergableFile *achFile) (*achF
 				// indicates an error
 				return nil, fmt.Errorf("mergable file %s has no lineCount", mergableFile.filepath)
 			}
-			if lines > fileMaxLines {
+			if lines > c.maxLines {
 				mergableFile.File.RemoveBatch(file.Batches[i])
 				if err := mergableFile.Create(); err != nil {
 					c.logger.Log("mergeTransfer", fmt.Sprintf("problem with mergable file %s Create", mergableFile.filepath), "error", err)
@@ -77,7 +79,7 @@ func (c *Controller) mergeTransfer(file *ach.File, mergableFile *achFile) (*achF
 				file.Batches = file.Batches[i:]
 
 				// create a new mergableFile
-				cfg := c.findFileTransferConfig(file.Header.ImmediateDestination)
+				cfg := c.findFileTransferConfig(ctx, file.Header.ImmediateDestination)
 				dir, filename := filepath.Split(mergableFile.filepath)
 				filename, err := renderACHFilename(cfg.outboundFilenameTemplate(), filenameData{
 					RoutingNumber: file.Header.ImmediateDestination,
@@ -118,7 +120,14 @@ type mergeUploadOpts struct {
 // mergeAndUploadFiles will retrieve all Transfer objects written to paygate's database but have not yet been added
 // to a file for upload to a Fed server. Any files which are ready to be upload will be uploaded, their transfer status
 // updated and local copy deleted.
-func (c *Controller) mergeAndUploadFiles(transferCur *internal.TransferCursor, microDepositCur *internal.MicroDepositCursor, transferRepo internal.TransferRepository, req *periodicFileOperationsRequest, opts *mergeUploadOpts) error {
+func (c *Controller) mergeAndUploadFiles(ctx context.Context, transferCur *internal.TransferCursor, microDepositCur *internal.MicroDepositCursor, prenoteCur *internal.PrenoteCursor, transferRepo internal.TransferRepository, req *periodicFileOperationsRequest, opts *mergeUploadOpts) (err error) {
+	ctx = tracing.WithRequestID(ctx, req.requestID)
+	ctx, span := tracing.Start(ctx, "filetransfer.mergeAndUploadFiles", "force", opts.force)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	// Our "merged" directory can exist from a previous run since we want to merge as many Transfer objects (ACH files) into a file as possible.
 	//
 	// FI's pay for each file that's uploaded, so it's important to merge and consolidate files to reduce their cost. ACH files have a maximum
@@ -136,31 +145,57 @@ func (c *Controller) mergeAndUploadFiles(transferCur *internal.TransferCursor, m
 	// Should we mark Transfers? We need to have a code branch that sweeps all transfers to ensure we aren't missing any.
 	//
 	// See: https://github.com/moov-io/paygate/issues/178
-	groupedTransfers, err := groupTransfers(transferCur.Next())
+	mergeCtx, mergeSpan := tracing.Start(ctx, "filetransfer.mergeTransfers")
+	groupedTransfers, err := groupTransfers(transferCur.Next(mergeCtx))
 	if err != nil {
+		mergeSpan.RecordError(err)
+		mergeSpan.End()
 		return fmt.Errorf("problem grouping transfers: %v", err)
 	}
 	// Group transfers by ABA and add to mergable files
 	for i := range groupedTransfers {
 		for j := range groupedTransfers[i] {
-			if fileToUpload := c.mergeGroupableTransfer(mergedDir, groupedTransfers[i][j], transferRepo); fileToUpload != nil {
+			if fileToUpload := c.mergeGroupableTransfer(mergeCtx, mergedDir, groupedTransfers[i][j], transferRepo); fileToUpload != nil {
 				filesToUpload = append(filesToUpload, fileToUpload)
 			}
 		}
 	}
+	mergeSpan.SetAttributes("groups", len(groupedTransfers))
+	mergeSpan.End()
 
 	// TODO(adam): What would it take to read these as Transfer objects and re-use this method's logic? This is a lot to duplicate.
 	// We need to read an ACH file back into its Transfer (see: groupableTransfer), which is doable since submitMicroDeposits creates an ACH file.
-	microDeposits, err := microDepositCur.Next()
+	mergeCtx, mergeSpan = tracing.Start(ctx, "filetransfer.mergeMicroDeposits")
+	microDeposits, err := microDepositCur.Next(ctx)
 	if err != nil {
+		mergeSpan.RecordError(err)
+		mergeSpan.End()
 		return fmt.Errorf("problem getting micro-deposits: %v", err)
 	}
 	// Group micro-deposits by ABA and add to mergable files
 	for i := range microDeposits {
-		if file := c.mergeMicroDeposit(mergedDir, microDeposits[i], microDepositCur.DepRepo); file != nil {
+		if file := c.mergeMicroDeposit(mergeCtx, mergedDir, microDeposits[i], microDepositCur.DepRepo); file != nil {
 			filesToUpload = append(filesToUpload, file)
 		}
 	}
+	mergeSpan.SetAttributes("microDeposits", len(microDeposits))
+	mergeSpan.End()
+
+	// Prenotes sent to verify Depositories are merged the same way
+	mergeCtx, mergeSpan = tracing.Start(ctx, "filetransfer.mergePrenotes")
+	prenotes, err := prenoteCur.Next(ctx)
+	if err != nil {
+		mergeSpan.RecordError(err)
+		mergeSpan.End()
+		return fmt.Errorf("problem getting prenotes: %v", err)
+	}
+	for i := range prenotes {
+		if file := c.mergePrenote(mergeCtx, mergedDir, prenotes[i], prenoteCur.DepRepo); file != nil {
+			filesToUpload = append(filesToUpload, file)
+		}
+	}
+	mergeSpan.SetAttributes("prenotes", len(prenotes))
+	mergeSpan.End()
 
 	// If we're being forced to upload everything then grab all files and upload them
 	if opts.force {
@@ -172,11 +207,11 @@ func (c *Controller) mergeAndUploadFiles(transferCur *internal.TransferCursor, m
 		filesToUpload = files // upload everything found
 	} else {
 		// Find files close to their cutoff to enqueue
-		cutoffTimes, err := c.repo.GetCutoffTimes()
+		cutoffTimes, err := c.repo.GetCutoffTimes(ctx)
 		if err != nil {
 			return fmt.Errorf("cutoff times: %v", err)
 		}
-		toUpload, err := filesNearTheirCutoff(cutoffTimes, mergedDir)
+		toUpload, err := filesNearTheirCutoff(cutoffTimes, mergedDir, c.forcedCutoffUploadDelta)
 		if err != nil {
 			return fmt.Errorf("problem with filesNearTheirCutoff: %v", err)
 		}
@@ -185,7 +220,7 @@ func (c *Controller) mergeAndUploadFiles(transferCur *internal.TransferCursor, m
 	}
 
 	// Upload any merged files that are ready
-	if err := c.startUpload(filesToUpload); err != nil {
+	if err := c.startUpload(ctx, filesToUpload); err != nil {
 		return fmt.Errorf("problem uploading ACH files: %v", err)
 	}
 	return nil
@@ -213,7 +248,7 @@ func grabAllFiles(dir string) ([]*achFile, error) {
 	return out, nil
 }
 
-func filesNearTheirCutoff(cutoffTimes []*CutoffTime, dir string) ([]*achFile, error) {
+func filesNearTheirCutoff(cutoffTimes []*CutoffTime, dir string, delta time.Duration) ([]*achFile, error) {
 	var filesToUpload []*achFile
 
 	for i := range cutoffTimes {
@@ -225,7 +260,7 @@ func filesNearTheirCutoff(cutoffTimes []*CutoffTime, dir string) ([]*achFile, er
 		// If we're close to the cutoffTime then enqueue for upload
 		diff := cutoffTimes[i].Diff(time.Now().In(cutoffTimes[i].Loc))
 
-		if diff > 0*time.Second && diff <= forcedCutoffUploadDelta {
+		if diff > 0*time.Second && diff <= delta {
 			for j := range matches {
 				file, err := parseACHFilepath(matches[j])
 				if err != nil {
@@ -243,8 +278,8 @@ func filesNearTheirCutoff(cutoffTimes []*CutoffTime, dir string) ([]*achFile, er
 }
 
 // loadRemoteACHFile will retrieve a transfer's ACH file contents and parse into an ach.File object
-func (c *Controller) loadRemoteACHFile(fileId string) (*ach.File, error) {
-	buf, err := c.ach.GetFileContents(fileId) // read from our ACH service
+func (c *Controller) loadRemoteACHFile(ctx context.Context, fileId string) (*ach.File, error) {
+	buf, err := c.ach.GetFileContents(ctx, fileId) // read from our ACH service
 	if err != nil {
 		return nil, err
 	}
@@ -257,25 +292,25 @@ func (c *Controller) loadRemoteACHFile(fileId string) (*ach.File, error) {
 }
 
 // mergeGroupableTransfer will inspect a Transfer, load the backing ACH file and attempt to merge that transfer into an existing merge file for upload.
-func (c *Controller) mergeGroupableTransfer(mergedDir string, xfer *internal.GroupableTransfer, transferRepo internal.TransferRepository) *achFile {
-	fileId, err := transferRepo.GetFileIDForTransfer(xfer.ID, id.User(xfer.UserID()))
+func (c *Controller) mergeGroupableTransfer(ctx context.Context, mergedDir string, xfer *internal.GroupableTransfer, transferRepo internal.TransferRepository) *achFile {
+	fileId, err := transferRepo.GetFileIDForTransfer(ctx, xfer.ID, id.User(xfer.UserID()))
 	if err != nil || fileId == "" {
 		return nil
 	}
-	file, err := c.loadRemoteACHFile(fileId)
+	file, err := c.loadRemoteACHFile(ctx, fileId)
 	if err != nil {
 		c.logger.Log("mergeGroupableTransfer", fmt.Sprintf("problem loading ACH file conents for transfer %s", xfer.ID), "error", err)
 		return nil
 	}
 
 	// Find (or create) a mergable file for this transfer's destination
-	mergableFile, err := c.grabLatestMergedACHFile(xfer.Destination, file, mergedDir)
+	mergableFile, err := c.grabLatestMergedACHFile(ctx, xfer.Destination, file, mergedDir)
 	if err != nil {
 		c.logger.Log("mergeGroupableTransfer", fmt.Sprintf("unable to find mergable file for transfer %s", xfer.ID), "error", err)
 		return nil
 	}
 	// Merge our transfer's file into mergableFile
-	fileToUpload, err := c.mergeTransfer(file, mergableFile)
+	fileToUpload, err := c.mergeTransfer(ctx, file, mergableFile)
 	if err != nil {
 		c.logger.Log("mergeGroupableTransfer", fmt.Sprintf("merging: %v", err))
 		return nil
@@ -288,7 +323,7 @@ func (c *Controller) mergeGroupableTransfer(mergedDir string, xfer *internal.Gro
 	if len(file.Batches) > 0 && len(file.Batches[0].GetEntries()) > 0 {
 		traceNumber = file.Batches[0].GetEntries()[0].TraceNumberField()
 	}
-	if err := transferRepo.MarkTransferAsMerged(xfer.ID, filepath.Base(mergableFile.filepath), traceNumber); err != nil {
+	if err := transferRepo.MarkTransferAsMerged(ctx, xfer.ID, filepath.Base(mergableFile.filepath), traceNumber); err != nil {
 		c.logger.Log("mergeGroupableTransfer", fmt.Sprintf("BAD ERROR - unable to mark transfer %s as merged: %v", xfer.ID, err))
 		// TODO(adam): This error is bad because we could end up merging the transfer into multiple files (i.e. duplicate it)
 		return nil
@@ -302,32 +337,32 @@ func (c *Controller) mergeGroupableTransfer(mergedDir string, xfer *internal.Gro
 }
 
 // mergeMicroDeposit will grab the ACH file for a micro-deposit and merge it into a larger ACH file for upload to the ODFI.
-func (c *Controller) mergeMicroDeposit(mergedDir string, mc internal.UploadableMicroDeposit, depRepo *internal.SQLDepositoryRepo) *achFile {
-	file, err := c.loadRemoteACHFile(mc.FileID)
+func (c *Controller) mergeMicroDeposit(ctx context.Context, mergedDir string, mc internal.UploadableMicroDeposit, depRepo *internal.SQLDepositoryRepo) *achFile {
+	file, err := c.loadRemoteACHFile(ctx, mc.FileID)
 	if err != nil {
 		c.logger.Log("mergeMicroDeposit", fmt.Sprintf("error reading ACH file=%s: %v", mc.FileID, err))
 		return nil
 	}
-	dep, err := depRepo.GetUserDepository(id.Depository(mc.DepositoryID), id.User(mc.UserID))
+	dep, err := depRepo.GetUserDepository(ctx, id.Depository(mc.DepositoryID), id.User(mc.UserID))
 	if dep == nil || err != nil {
 		c.logger.Log("mergeMicroDeposit", fmt.Sprintf("problem reading micro-deposit depository=%s: %v", mc.DepositoryID, err))
 		return nil
 	}
 
 	// Find (or create) a mergable file for this transfer's destination
-	mergableFile, err := c.grabLatestMergedACHFile(dep.RoutingNumber, file, mergedDir)
+	mergableFile, err := c.grabLatestMergedACHFile(ctx, dep.RoutingNumber, file, mergedDir)
 	if err != nil {
 		c.logger.Log("mergeMicroDeposit", "unable to find mergable file for micro-deposit", "userId", mc.UserID, "error", err)
 		return nil
 	}
 	// Merge our transfer's file into mergableFile
-	fileToUpload, err := c.mergeTransfer(file, mergableFile)
+	fileToUpload, err := c.mergeTransfer(ctx, file, mergableFile)
 	if err != nil {
 		c.logger.Log("mergeMicroDeposit", fmt.Sprintf("problem during micro-deposit merging: %v", err))
 		return nil
 	}
 	// Mark the micro-deposit as merged and record in which merged file
-	if err := depRepo.MarkMicroDepositAsMerged(filepath.Base(mergableFile.filepath), mc); err != nil {
+	if err := depRepo.MarkMicroDepositAsMerged(ctx, filepath.Base(mergableFile.filepath), mc); err != nil {
 		c.logger.Log("mergeMicroDeposit", fmt.Sprintf("BAD ERROR - unable to mark micro-deposit as merged: %v", err), "userId", mc.UserID)
 		// TODO(adam): This error is bad because we could end up merging the transfer into multiple files (i.e. duplicate it)
 		return nil
@@ -340,6 +375,43 @@ func (c *Controller) mergeMicroDeposit(mergedDir string, mc internal.UploadableM
 	return nil
 }
 
+// mergePrenote will grab the ACH file for a prenote and merge it into a larger ACH file for upload to the ODFI.
+func (c *Controller) mergePrenote(ctx context.Context, mergedDir string, p internal.UploadablePrenote, depRepo *internal.SQLDepositoryRepo) *achFile {
+	file, err := c.loadRemoteACHFile(ctx, p.FileID)
+	if err != nil {
+		c.logger.Log("mergePrenote", fmt.Sprintf("error reading ACH file=%s: %v", p.FileID, err))
+		return nil
+	}
+	dep, err := depRepo.GetUserDepository(ctx, id.Depository(p.DepositoryID), id.User(p.UserID))
+	if dep == nil || err != nil {
+		c.logger.Log("mergePrenote", fmt.Sprintf("problem reading prenote depository=%s: %v", p.DepositoryID, err))
+		return nil
+	}
+
+	// Find (or create) a mergable file for this prenote's destination
+	mergableFile, err := c.grabLatestMergedACHFile(ctx, dep.RoutingNumber, file, mergedDir)
+	if err != nil {
+		c.logger.Log("mergePrenote", "unable to find mergable file for prenote", "userId", p.UserID, "error", err)
+		return nil
+	}
+	fileToUpload, err := c.mergeTransfer(ctx, file, mergableFile)
+	if err != nil {
+		c.logger.Log("mergePrenote", fmt.Sprintf("problem during prenote merging: %v", err))
+		return nil
+	}
+	// Mark the prenote as merged, which starts its waiting period
+	if err := depRepo.MarkPrenoteAsMerged(ctx, filepath.Base(mergableFile.filepath), p); err != nil {
+		c.logger.Log("mergePrenote", fmt.Sprintf("BAD ERROR - unable to mark prenote as merged: %v", err), "userId", p.UserID)
+		return nil
+	}
+	if fileToUpload != nil { // this is only set if existing mergableFile surpasses ACH file line limit
+		c.logger.Log("mergePrenote",
+			fmt.Sprintf("merging: scheduling %s for upload ABA:%s", fileToUpload.filepath, fileToUpload.File.Header.ImmediateDestination))
+		return fileToUpload
+	}
+	return nil
+}
+
 func rejectOutboundIPRange(cfg *Config, hostname string) error {
 	if cfg.AllowedIPs == "" {
 		return nil
@@ -373,11 +445,32 @@ func rejectOutboundIPRange(cfg *Config, hostname string) error {
 // to them (so we can find their upload configs).
 //
 // After uploading a file this method renames it to avoid uploading the file multiple times.
-func (c *Controller) startUpload(filesToUpload []*achFile) error {
+// When ctx is cancelled no more uploads are started, but an upload in progress is finished and renamed.
+func (c *Controller) startUpload(ctx context.Context, filesToUpload []*achFile) error {
+	c.markPending(filesToUpload)
+	defer func() {
+		for i := range filesToUpload {
+			c.clearPending(filesToUpload[i])
+		}
+	}()
+
 	for i := range filesToUpload {
 		file := filesToUpload[i]
 
-		if err := c.maybeUploadFile(file); err != nil {
+		if err := ctx.Err(); err != nil {
+			var remaining []string
+			for j := i; j < len(filesToUpload); j++ {
+				remaining = append(remaining, filepath.Base(filesToUpload[j].filepath))
+			}
+			return fmt.Errorf("stopped before uploading %s: %v", strings.Join(remaining, ", "), err)
+		}
+
+		spanCtx, span := tracing.Start(ctx, "filetransfer.uploadFile", "filename", filepath.Base(file.filepath),
+			"origin", file.Header.ImmediateOrigin, "destination", file.Header.ImmediateDestination)
+		err := c.maybeUploadFile(spanCtx, file)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
 			return fmt.Errorf("problem uploading %s: %v", file.filepath, err)
 		}
 
@@ -387,18 +480,19 @@ func (c *Controller) startUpload(filesToUpload []*achFile) error {
 			// the underlying FS is failing what other errors would paygate run into?
 			return fmt.Errorf("error renaming %s after upload: %v", file.filepath, err)
 		}
+		c.clearPending(file)
 	}
 	return nil
 }
 
 // maybeUploadFile will grab the needed configs and upload an given file to the ODFI's server
-func (c *Controller) maybeUploadFile(file *achFile) error {
-	cfg := c.findFileTransferConfig(file.Header.ImmediateOrigin)
+func (c *Controller) maybeUploadFile(ctx context.Context, file *achFile) error {
+	cfg := c.findFileTransferConfig(ctx, file.Header.ImmediateOrigin)
 	if cfg == nil {
 		return fmt.Errorf("missing file transfer config for %s", file.Header.ImmediateOrigin)
 	}
 
-	agent, err := New(c.logger, c.findTransferType(cfg.RoutingNumber), cfg, c.repo)
+	agent, err := New(ctx, c.logger, c.findTransferType(ctx, cfg.RoutingNumber), cfg, c.repo, c.ftp, c.sftp)
 	if err != nil {
 		return fmt.Errorf("problem creating fileTransferAgent for %s: %v", cfg.RoutingNumber, err)
 	}
@@ -413,17 +507,17 @@ func (c *Controller) maybeUploadFile(file *achFile) error {
 	// TODO(adam): I think we should have a DB table for tracking file uploads (?ach_file_uploads?)
 	// with the following fields: routing number, filename, timestamp.
 
-	return c.uploadFile(agent, file)
+	return c.uploadFile(ctx, agent, file)
 }
 
-func (c *Controller) uploadFile(agent Agent, f *achFile) error {
+func (c *Controller) uploadFile(ctx context.Context, agent Agent, f *achFile) error {
 	fd, err := os.Open(f.filepath)
 	if err != nil {
 		return fmt.Errorf("problem opening %s for upload: %v", f.filepath, err)
 	}
 	defer fd.Close()
 
-	if err := agent.UploadFile(File{Filename: filepath.Base(f.filepath), Contents: fd}); err != nil {
+	if err := agent.UploadFile(ctx, File{Filename: filepath.Base(f.filepath), Contents: fd}); err != nil {
 		return fmt.Errorf("problem uploading %s: %v", f.filepath, err)
 	}
 
@@ -441,7 +535,7 @@ func (c *Controller) uploadFile(agent Agent, f *achFile) error {
 //
 // TODO(adam): What if we have multiple origin routing numbers? Do we need to account for this
 // in the mergable file picked/returned?
-func (c *Controller) grabLatestMergedACHFile(destinationRoutingNumber string, incoming *ach.File, dir string) (*achFile, error) {
+func (c *Controller) grabLatestMergedACHFile(ctx context.Context, destinationRoutingNumber string, incoming *ach.File, dir string) (*achFile, error) {
 	matches, err := filepath.Glob(filepath.Join(dir, "*.ach"))
 	if err != nil {
 		return nil, err
@@ -454,7 +548,7 @@ func (c *Controller) grabLatestMergedACHFile(destinationRoutingNumber string, in
 		incoming.Header.FileCreationDate = now.Format("060102") // YYMMDD
 		incoming.Header.FileCreationTime = now.Format("1504")   // HHMM
 
-		cfg := c.findFileTransferConfig(destinationRoutingNumber)
+		cfg := c.findFileTransferConfig(ctx, destinationRoutingNumber)
 		filename, err := renderACHFilename(cfg.outboundFilenameTemplate(), filenameData{
 			RoutingNumber: incoming.Header.ImmediateDestination,
 			N:             "1",
@@ -497,7 +591,7 @@ func (c *Controller) grabLatestMergedACHFile(destinationRoutingNumber string, in
 	}
 
 	// Otherwise, we had matches but found nothing so create a file.
-	cfg := c.findFileTransferConfig(destinationRoutingNumber)
+	cfg := c.findFileTransferConfig(ctx, destinationRoutingNumber)
 	filename, err := renderACHFilename(cfg.outboundFilenameTemplate(), filenameData{
 		RoutingNumber: incoming.Header.ImmediateDestination,
 		N:             "1",
@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -100,7 +101,7 @@ func TestController__filesNearTheirCutoff(t *testing.T) {
 		},
 	}
 
-	outFiles, err := filesNearTheirCutoff(cutoffTimes, dir)
+	outFiles, err := filesNearTheirCutoff(cutoffTimes, dir, 5*time.Minute)
 	if err != nil {
 		t.Error(err)
 	}
@@ -119,7 +120,7 @@ func TestController__filesNearTheirCutoff(t *testing.T) {
 
 	// bump out time ahead
 	cutoffTimes[0].Cutoff += 100 // add one hour
-	outFiles, err = filesNearTheirCutoff(cutoffTimes, dir)
+	outFiles, err = filesNearTheirCutoff(cutoffTimes, dir, 5*time.Minute)
 	if err != nil {
 		t.Error(err)
 	}
@@ -171,7 +172,8 @@ func TestController__mergeTransfer(t *testing.T) {
 
 	// call .mergeTransfer
 	controller := &Controller{
-		logger: log.NewNopLogger(),
+		maxLines: 10000,
+		logger:   log.NewNopLogger(),
 		repo: &mockRepository{
 			configs: []*Config{
 				{
@@ -181,7 +183,7 @@ func TestController__mergeTransfer(t *testing.T) {
 			},
 		},
 	}
-	fileToUpload, err := controller.mergeTransfer(file, mergableFile)
+	fileToUpload, err := controller.mergeTransfer(context.Background(), file, mergableFile)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -190,7 +192,7 @@ func TestController__mergeTransfer(t *testing.T) {
 	}
 
 	// grab the latest mergable file and verify it's '*-2.ach'
-	mergableFile, err = controller.grabLatestMergedACHFile(webFile.Header.ImmediateDestination, file, dir)
+	mergableFile, err = controller.grabLatestMergedACHFile(context.Background(), webFile.Header.ImmediateDestination, file, dir)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -231,8 +233,9 @@ func TestController__mergeGroupableTransfer(t *testing.T) {
 	defer os.RemoveAll(dir)
 
 	controller := &Controller{
-		ach:    achClient,
-		logger: log.NewNopLogger(),
+		ach:      achClient,
+		maxLines: 10000,
+		logger:   log.NewNopLogger(),
 		repo: &mockRepository{
 			configs: []*Config{
 				{
@@ -255,18 +258,18 @@ func TestController__mergeGroupableTransfer(t *testing.T) {
 
 	repo := &internal.MockTransferRepository{}
 	repo.FileID = "foo" // some non-empty value, our test ACH server doesn't care
-	if fileToUpload := controller.mergeGroupableTransfer(dir, xfer, repo); fileToUpload != nil {
+	if fileToUpload := controller.mergeGroupableTransfer(context.Background(), dir, xfer, repo); fileToUpload != nil {
 		t.Errorf("didn't expect fileToUpload=%v", fileToUpload)
 	}
 
 	// technically we load it twice, but we're reading the same file..
-	file, err := controller.loadRemoteACHFile("foo")
+	file, err := controller.loadRemoteACHFile(context.Background(), "foo")
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// check our mergable files
-	mergableFile, err := controller.grabLatestMergedACHFile(xfer.Destination, file, dir)
+	mergableFile, err := controller.grabLatestMergedACHFile(context.Background(), xfer.Destination, file, dir)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -293,8 +296,9 @@ func TestController__mergeMicroDeposit(t *testing.T) {
 	defer os.RemoveAll(dir)
 
 	controller := &Controller{
-		ach:    achClient,
-		logger: log.NewNopLogger(),
+		ach:      achClient,
+		maxLines: 10000,
+		logger:   log.NewNopLogger(),
 		repo: &mockRepository{
 			configs: []*Config{
 				{
@@ -319,12 +323,12 @@ func TestController__mergeMicroDeposit(t *testing.T) {
 		FileID:       "fileID",
 		Amount:       amt,
 	}
-	if err := depRepo.InitiateMicroDeposits(id.Depository("depositoryID"), "userID", []*internal.MicroDeposit{{Amount: *amt, FileID: "fileID"}}); err != nil {
+	if err := depRepo.InitiateMicroDeposits(context.Background(), id.Depository("depositoryID"), "userID", []*internal.MicroDeposit{{Amount: *amt, FileID: "fileID"}}); err != nil {
 		t.Fatal(err)
 	}
 
 	// write a Depository
-	if err := depRepo.UpsertUserDepository("userID", &internal.Depository{
+	if err := depRepo.UpsertUserDepository(context.Background(), "userID", &internal.Depository{
 		ID:            "depositoryID",
 		BankName:      "Mooc, Inc",
 		RoutingNumber: "987654320",
@@ -332,7 +336,7 @@ func TestController__mergeMicroDeposit(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if fileToUpload := controller.mergeMicroDeposit(dir, mc, depRepo); fileToUpload != nil {
+	if fileToUpload := controller.mergeMicroDeposit(context.Background(), dir, mc, depRepo); fileToUpload != nil {
 		t.Errorf("didn't expect an ACH file to upload: %#v", fileToUpload)
 	}
 
@@ -345,6 +349,65 @@ func TestController__mergeMicroDeposit(t *testing.T) {
 	}
 }
 
+func TestController__mergePrenote(t *testing.T) {
+	achClient, _, achServer := achclient.MockClientServer("mergePrenote", func(r *mux.Router) {
+		achFileContentsRoute(r)
+	})
+	defer achServer.Close()
+
+	dir, err := ioutil.TempDir("", "mergePrenote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	controller := &Controller{
+		ach:      achClient,
+		maxLines: 10000,
+		logger:   log.NewNopLogger(),
+		repo: &mockRepository{
+			configs: []*Config{
+				{
+					RoutingNumber:            "987654320",
+					OutboundFilenameTemplate: defaultFilenameTemplate,
+				},
+			},
+		},
+	}
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	keeper := secrets.TestStringKeeper(t)
+	depRepo := internal.NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper)
+
+	// write a Depository and read back its prenote from a cursor
+	if err := depRepo.UpsertUserDepository(context.Background(), "userID", &internal.Depository{
+		ID:            "depositoryID",
+		BankName:      "Mooc, Inc",
+		RoutingNumber: "987654320",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB.Exec(`insert into depository_prenotes (depository_id, user_id, file_id, trace_number, created_at) values (?, ?, ?, ?, ?);`,
+		"depositoryID", "userID", "fileID", "121042880000001", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	prenotes, err := depRepo.GetPrenoteCursor(5).Next(context.Background())
+	if err != nil || len(prenotes) != 1 {
+		t.Fatalf("got %d prenotes: %v", len(prenotes), err)
+	}
+
+	if fileToUpload := controller.mergePrenote(context.Background(), dir, prenotes[0], depRepo); fileToUpload != nil {
+		t.Errorf("didn't expect an ACH file to upload: %#v", fileToUpload)
+	}
+
+	// the prenote isn't merged again
+	if prenotes, err := depRepo.GetPrenoteCursor(5).Next(context.Background()); err != nil || len(prenotes) != 0 {
+		t.Errorf("got %d prenotes: %v", len(prenotes), err)
+	}
+}
+
 func TestController__startUploadError(t *testing.T) {
 	nyc, _ := time.LoadLocation("America/New_York")
 	controller := &Controller{
@@ -375,7 +438,7 @@ func TestController__startUploadError(t *testing.T) {
 		{File: file, filepath: "/dev/null"}, // invalid filepath
 	}
 
-	if err := controller.startUpload(filesToUpload); err == nil {
+	if err := controller.startUpload(context.Background(), filesToUpload); err == nil {
 		t.Error("expected error")
 	}
 }
@@ -389,7 +452,7 @@ func TestController__uploadFile(t *testing.T) {
 	controller := &Controller{
 		logger: log.NewNopLogger(),
 	}
-	if err := controller.uploadFile(agent, &achFile{File: file, filepath: filepath.Join("..", "..", "testdata", "ppd-debit.ach")}); err != nil {
+	if err := controller.uploadFile(context.Background(), agent, &achFile{File: file, filepath: filepath.Join("..", "..", "testdata", "ppd-debit.ach")}); err != nil {
 		t.Error(err)
 	}
 
@@ -478,7 +541,7 @@ func TestController__grabLatestMergedACHFile(t *testing.T) {
 	}
 
 	incoming, _ := parseACHFilepath(filepath.Join("..", "..", "testdata", "ppd-debit.ach"))
-	file, err := controller.grabLatestMergedACHFile(origin, incoming, dir)
+	file, err := controller.grabLatestMergedACHFile(context.Background(), origin, incoming, dir)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -515,7 +578,7 @@ func TestController__grabLatestMergedACHFile(t *testing.T) {
 			},
 		},
 	}
-	file, err = controller.grabLatestMergedACHFile(incoming.Header.ImmediateDestination, incoming, dir)
+	file, err = controller.grabLatestMergedACHFile(context.Background(), incoming.Header.ImmediateDestination, incoming, dir)
 	if err != nil {
 		t.Fatal(err)
 	}
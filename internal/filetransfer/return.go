@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -28,7 +29,7 @@ var (
 	}, []string{"destination", "origin"})
 )
 
-func (c *Controller) processReturnFiles(dir string, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository) error {
+func (c *Controller) processReturnFiles(ctx context.Context, dir string, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository, authorizationRepo internal.AuthorizationRepository) error {
 	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if (err != nil && err != filepath.SkipDir) || info.IsDir() {
 			return nil // Ignore SkipDir and directories
@@ -55,7 +56,7 @@ func (c *Controller) processReturnFiles(dir string, depRepo internal.DepositoryR
 					c.logger.Log("processReturnFiles", "empty Addenda99 (or ReturnCode)", "traceNumber", entries[j].TraceNumber)
 					continue
 				}
-				if err := c.processReturnEntry(file.Header, file.ReturnEntries[i].GetHeader(), entries[j], depRepo, transferRepo); err != nil {
+				if err := c.processReturnEntry(ctx, file.Header, file.ReturnEntries[i].GetHeader(), entries[j], depRepo, transferRepo, authorizationRepo); err != nil {
 					c.logger.Log("processReturnFiles", "error processing EntryDetail", "traceNumber", entries[j].TraceNumber, "error", err)
 					continue
 				}
@@ -65,7 +66,7 @@ func (c *Controller) processReturnFiles(dir string, depRepo internal.DepositoryR
 	})
 }
 
-func (c *Controller) processReturnEntry(fileHeader ach.FileHeader, header *ach.BatchHeader, entry *ach.EntryDetail, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository) error {
+func (c *Controller) processReturnEntry(ctx context.Context, fileHeader ach.FileHeader, header *ach.BatchHeader, entry *ach.EntryDetail, depRepo internal.DepositoryRepository, transferRepo internal.TransferRepository, authorizationRepo internal.AuthorizationRepository) error {
 	amount, err := internal.NewAmountFromInt("USD", entry.Amount)
 	if err != nil {
 		return fmt.Errorf("invalid amount: %v", entry.Amount)
@@ -79,26 +80,26 @@ func (c *Controller) processReturnEntry(fileHeader ach.FileHeader, header *ach.B
 	returnCode := entry.Addenda99.ReturnCodeField()
 
 	// Do we find a Transfer related to the ach.EntryDetail?
-	transfer, err := transferRepo.LookupTransferFromReturn(header.StandardEntryClassCode, amount, entry.TraceNumber, effectiveEntryDate)
+	transfer, err := transferRepo.LookupTransferFromReturn(ctx, header.StandardEntryClassCode, amount, entry.TraceNumber, effectiveEntryDate)
 	if transfer != nil {
-		if err := c.processTransferReturn(requestID, transfer, transferRepo, returnCode); err != nil {
+		if err := c.processTransferReturn(ctx, requestID, transfer, transferRepo, authorizationRepo, returnCode); err != nil {
 			return fmt.Errorf("processTransferReturn: %v", err)
 		}
 		c.logger.Log("processReturnEntry", fmt.Sprintf("matched traceNumber=%s to transfer=%s with returnCode=%s", entry.TraceNumber, transfer.ID, returnCode), "requestID", requestID)
 
 		// Grab the full Depository objects for our Transfer
-		origDep, err := depRepo.GetUserDepository(transfer.OriginatorDepository, id.User(transfer.UserID))
+		origDep, err := depRepo.GetUserDepository(ctx, transfer.OriginatorDepository, id.User(transfer.UserID))
 		if err != nil {
 			return fmt.Errorf("processTransferReturn: error finding originator depository=%s: %v", transfer.OriginatorDepository, err)
 		}
-		recDep, err := depRepo.GetUserDepository(transfer.ReceiverDepository, id.User(transfer.UserID))
+		recDep, err := depRepo.GetUserDepository(ctx, transfer.ReceiverDepository, id.User(transfer.UserID))
 		if err != nil {
 			return fmt.Errorf("processTransferReturn: error finding receiver depository=%s: %v", transfer.ReceiverDepository, err)
 		}
 		c.logger.Log("processReturnEntry", fmt.Sprintf("found deposiories for transfer=%s (originator=%s) (receiver=%s)", transfer.ID, origDep.ID, recDep.ID), "requestID", requestID)
 
 		// Optionally update the Depositories for this Transfer if the return code justifies it
-		if err := updateDepositoryFromReturnCode(c.logger, returnCode, origDep, recDep, depRepo); err != nil {
+		if err := updateDepositoryFromReturnCode(ctx, c.logger, returnCode, origDep, recDep, depRepo); err != nil {
 			return fmt.Errorf("problem with updateDepositoryFromReturnCode transfer=%q: %v", transfer.ID, err)
 		}
 		return nil
@@ -109,19 +110,19 @@ func (c *Controller) processReturnEntry(fileHeader ach.FileHeader, header *ach.B
 	}
 
 	// No Transfer, so maybe a Depository? It could be a micro-deposit.
-	dep, err := depRepo.LookupDepositoryFromReturn(fileHeader.ImmediateDestination, entry.DFIAccountNumber)
+	dep, err := depRepo.LookupDepositoryFromReturn(ctx, fileHeader.ImmediateDestination, entry.DFIAccountNumber)
 	if dep == nil || err != nil {
 		return fmt.Errorf("problem looking up Depository: %v", err)
 	}
-	microDeposit, err := depRepo.LookupMicroDepositFromReturn(dep.ID, amount)
+	microDeposit, err := depRepo.LookupMicroDepositFromReturn(ctx, dep.ID, amount)
 	if microDeposit != nil {
-		if err := c.processMicroDepositReturn(requestID, id.User(dep.UserID()), dep.ID, microDeposit, depRepo, returnCode); err != nil {
+		if err := c.processMicroDepositReturn(ctx, requestID, id.User(dep.UserID()), dep.ID, microDeposit, depRepo, returnCode); err != nil {
 			return fmt.Errorf("processMicroDepositReturn: %v", err)
 		}
 		c.logger.Log("processReturnEntry", fmt.Sprintf("matched micro-deposit to depository=%s with returnCode=%s", dep.ID, returnCode), "requestID", requestID)
 
 		// Optionally update the Depository for this micro-deposit if the return code justifies it
-		if err := updateDepositoryFromReturnCode(c.logger, returnCode, dep, dep, depRepo); err != nil {
+		if err := updateDepositoryFromReturnCode(ctx, c.logger, returnCode, dep, dep, depRepo); err != nil {
 			return fmt.Errorf("problem with updateDepositoryFromReturnCode transfer=%q: %v", transfer.ID, err)
 		}
 		return nil
@@ -131,6 +132,21 @@ func (c *Controller) processReturnEntry(fileHeader ach.FileHeader, header *ach.B
 		}
 	}
 
+	// Maybe it's a prenote sent to verify the Depository, which are matched by their original trace number
+	prenote, err := depRepo.LookupPrenoteFromReturn(ctx, dep.ID, entry.Addenda99.OriginalTrace)
+	if prenote != nil {
+		if err := depRepo.SetPrenoteReturnCode(ctx, dep.ID, prenote.TraceNumber, returnCode.Code); err != nil {
+			return fmt.Errorf("problem setting prenote code=%s: %v", returnCode.Code, err)
+		}
+		// Any returned prenote means the account can't receive entries as-is
+		c.logger.Log("processReturnEntry", fmt.Sprintf("rejecting depository=%s for returned prenote with returnCode=%s", dep.ID, returnCode.Code), "requestID", requestID)
+		return depRepo.UpdateDepositoryStatus(ctx, dep.ID, internal.DepositoryRejected)
+	} else {
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("problem with returned prenote: %v", err)
+		}
+	}
+
 	return fmt.Errorf("unable to match return file origin=%s traceNumber=%s", fileHeader.ImmediateOrigin, entry.TraceNumber)
 }
 
@@ -141,7 +157,7 @@ func (c *Controller) processReturnEntry(fileHeader ach.FileHeader, header *ach.B
 //
 // You can find all the NACHA return codes in their guidelines PDF, but some websites also republish the list.
 // See: https://docs.moderntreasury.com/reference#ach-return-reason-codes
-func updateDepositoryFromReturnCode(logger log.Logger, code *ach.ReturnCode, origDep *internal.Depository, destDep *internal.Depository, depRepo internal.DepositoryRepository) error {
+func updateDepositoryFromReturnCode(ctx context.Context, logger log.Logger, code *ach.ReturnCode, origDep *internal.Depository, destDep *internal.Depository, depRepo internal.DepositoryRepository) error {
 	switch code.Code {
 	// The following codes mark the Receiver Depository as Rejected because of a reason similar to
 	// authorization changing, incorrect account/routing numbers, or human interaction is required.
@@ -165,7 +181,7 @@ func updateDepositoryFromReturnCode(logger log.Logger, code *ach.ReturnCode, ori
 		"R38", // Stop Payment on Source Document
 		"R39": // Improper Source Document/Source Document Presented for Payment
 		logger.Log("processReturnEntry", fmt.Sprintf("rejecting depository=%s for returnCode=%s", destDep.ID, code.Code))
-		return depRepo.UpdateDepositoryStatus(destDep.ID, internal.DepositoryRejected)
+		return depRepo.UpdateDepositoryStatus(ctx, destDep.ID, internal.DepositoryRejected)
 
 	// The following codes do not impact a Depository, but are handled here for informational logs.
 	// Many of these return codes likely signal there's a bug in paygate or moov's ACH library.
@@ -194,10 +210,10 @@ func updateDepositoryFromReturnCode(logger log.Logger, code *ach.ReturnCode, ori
 
 	case "R14", "R15": // "Representative payee deceased or unable to continue in that capacity", "Beneficiary or bank account holder"
 		logger.Log("processReturnEntry", fmt.Sprintf("rejecting depository=%s and depository=%s for returnCode=%s", origDep.ID, destDep.ID, code.Code))
-		if err := depRepo.UpdateDepositoryStatus(origDep.ID, internal.DepositoryRejected); err != nil {
+		if err := depRepo.UpdateDepositoryStatus(ctx, origDep.ID, internal.DepositoryRejected); err != nil {
 			return err
 		}
-		return depRepo.UpdateDepositoryStatus(destDep.ID, internal.DepositoryRejected)
+		return depRepo.UpdateDepositoryStatus(ctx, destDep.ID, internal.DepositoryRejected)
 	}
 	return fmt.Errorf("unhandled return code: %s", code.Code)
 }
@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -35,7 +36,7 @@ func depositoryReturnCode(t *testing.T, code string) (*internal.Depository, *int
 		BankName: "originator bank",
 		Status:   internal.DepositoryVerified,
 	}
-	if err := repo.UpsertUserDepository(userID, origDep); err != nil {
+	if err := repo.UpsertUserDepository(context.Background(), userID, origDep); err != nil {
 		t.Fatal(err)
 	}
 	recDep := &internal.Depository{
@@ -43,18 +44,18 @@ func depositoryReturnCode(t *testing.T, code string) (*internal.Depository, *int
 		BankName: "receiver bank",
 		Status:   internal.DepositoryVerified,
 	}
-	if err := repo.UpsertUserDepository(userID, recDep); err != nil {
+	if err := repo.UpsertUserDepository(context.Background(), userID, recDep); err != nil {
 		t.Fatal(err)
 	}
 
 	rc := &ach.ReturnCode{Code: code}
-	if err := updateDepositoryFromReturnCode(logger, rc, origDep, recDep, repo); err != nil {
+	if err := updateDepositoryFromReturnCode(context.Background(), logger, rc, origDep, recDep, repo); err != nil {
 		t.Fatal(err)
 	}
 
 	// re-read and return the Depository objects
-	oDep, _ := repo.GetUserDepository(origDep.ID, userID)
-	rDep, _ := repo.GetUserDepository(recDep.ID, userID)
+	oDep, _ := repo.GetUserDepository(context.Background(), origDep.ID, userID)
+	rDep, _ := repo.GetUserDepository(context.Background(), recDep.ID, userID)
 	return oDep, rDep
 }
 
@@ -158,21 +159,21 @@ func TestFiles__UpdateDepositoryFromReturnCode(t *testing.T) {
 
 		// Setup depositories
 		origDep, receiverDep := setupReturnCodeDepository(), setupReturnCodeDepository()
-		repo.UpsertUserDepository(userID, origDep)
-		repo.UpsertUserDepository(userID, receiverDep)
+		repo.UpsertUserDepository(context.Background(), userID, origDep)
+		repo.UpsertUserDepository(context.Background(), userID, receiverDep)
 
 		// after writing Depositories call updateDepositoryFromReturnCode
-		if err := updateDepositoryFromReturnCode(logger, &ach.ReturnCode{Code: code}, origDep, receiverDep, repo); err != nil {
+		if err := updateDepositoryFromReturnCode(context.Background(), logger, &ach.ReturnCode{Code: code}, origDep, receiverDep, repo); err != nil {
 			t.Error(err)
 		}
 		var dep *internal.Depository
 		if cond == Orig {
-			dep, _ = repo.GetUserDepository(origDep.ID, userID)
+			dep, _ = repo.GetUserDepository(context.Background(), origDep.ID, userID)
 			if dep.ID != origDep.ID {
 				t.Error("read wrong Depository")
 			}
 		} else {
-			dep, _ = repo.GetUserDepository(receiverDep.ID, userID)
+			dep, _ = repo.GetUserDepository(context.Background(), receiverDep.ID, userID)
 			if dep.ID != receiverDep.ID {
 				t.Error("read wrong Depository")
 			}
@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/moov-io/ach"
@@ -12,14 +13,14 @@ import (
 	"github.com/moov-io/paygate/pkg/id"
 )
 
-func (c *Controller) processMicroDepositReturn(requestID string, userID id.User, depID id.Depository, md *internal.MicroDeposit, depRepo internal.DepositoryRepository, code *ach.ReturnCode) error {
-	if err := depRepo.SetReturnCode(depID, md.Amount, code.Code); err != nil {
+func (c *Controller) processMicroDepositReturn(ctx context.Context, requestID string, userID id.User, depID id.Depository, md *internal.MicroDeposit, depRepo internal.DepositoryRepository, code *ach.ReturnCode) error {
+	if err := depRepo.SetReturnCode(ctx, depID, md.Amount, code.Code); err != nil {
 		return fmt.Errorf("problem setting micro-deposit code=%s: %v", code.Code, err)
 	}
 
 	// Reverse micro-deposit transaction
 	if c.accountsClient != nil && md.TransactionID != "" {
-		if err := c.accountsClient.ReverseTransaction(requestID, userID, md.TransactionID); err != nil {
+		if err := c.accountsClient.ReverseTransaction(ctx, requestID, userID, md.TransactionID); err != nil {
 			return fmt.Errorf("problem reversing micro-deposit transaction=%s: %v", md.TransactionID, err)
 		}
 	} else {
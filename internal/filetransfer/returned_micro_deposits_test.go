@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"io/ioutil"
@@ -14,7 +15,6 @@ import (
 
 	"github.com/moov-io/base"
 	"github.com/moov-io/paygate/internal"
-	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/pkg/id"
 )
 
@@ -66,15 +66,15 @@ func TestController__processReturnMicroDeposit(t *testing.T) {
 	dir, _ := ioutil.TempDir("", "processReturnEntry")
 	defer os.RemoveAll(dir)
 
-	repo := NewRepository("", nil, "")
+	repo := NewRepository(nil, "")
 
-	cfg := config.Empty()
+	cfg := testConfig(t)
 	controller, err := NewController(cfg, dir, repo, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if err := controller.processReturnEntry(file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo); err != nil {
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo, &internal.MockAuthorizationRepository{}); err != nil {
 		t.Error(err)
 	}
 
@@ -91,13 +91,13 @@ func TestController__processReturnMicroDeposit(t *testing.T) {
 
 	// Check quick error conditions
 	depRepo.Err = errors.New("bad error")
-	if err := controller.processReturnEntry(file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo); err == nil {
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo, &internal.MockAuthorizationRepository{}); err == nil {
 		t.Error("expected error")
 	}
 	depRepo.Err = nil
 
 	transferRepo.Err = errors.New("bad error")
-	if err := controller.processReturnEntry(file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo); err == nil {
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo, &internal.MockAuthorizationRepository{}); err == nil {
 		t.Error("expected error")
 	}
 	transferRepo.Err = nil
@@ -0,0 +1,72 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+func TestController__processReturnPrenote(t *testing.T) {
+	file, err := parseACHFilepath(filepath.Join("..", "..", "testdata", "return-WEB.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := file.Batches[0]
+	entry := b.GetEntries()[0]
+	entry.Addenda99.ReturnCode = "R03" // "No Account/Unable to Locate Account"
+
+	depRepo := &internal.MockDepositoryRepository{
+		Depositories: []*internal.Depository{
+			{
+				ID:                     id.Depository(base.ID()),
+				BankName:               "their bank",
+				Holder:                 "john doe",
+				HolderType:             internal.Individual,
+				Type:                   internal.Checking,
+				RoutingNumber:          file.Header.ImmediateDestination,
+				EncryptedAccountNumber: entry.DFIAccountNumber,
+				Status:                 internal.DepositoryUnverified,
+			},
+		},
+	}
+	transferRepo := &internal.MockTransferRepository{
+		Err: sql.ErrNoRows,
+	}
+
+	dir, _ := ioutil.TempDir("", "processReturnPrenote")
+	defer os.RemoveAll(dir)
+
+	controller, err := NewController(testConfig(t), dir, NewRepository(nil, ""), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without a matching prenote the return is unmatched
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), entry, depRepo, transferRepo, &internal.MockAuthorizationRepository{}); err == nil {
+		t.Error("expected error")
+	}
+
+	depRepo.Prenotes = []*internal.Prenote{
+		{FileID: "fileID", TraceNumber: entry.Addenda99.OriginalTrace},
+	}
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), entry, depRepo, transferRepo, &internal.MockAuthorizationRepository{}); err != nil {
+		t.Fatal(err)
+	}
+	if depRepo.ReturnCode != "R03" {
+		t.Errorf("unexpected return code: %s", depRepo.ReturnCode)
+	}
+	if depRepo.Status != internal.DepositoryRejected {
+		t.Errorf("unexpected status: %v", depRepo.Status)
+	}
+}
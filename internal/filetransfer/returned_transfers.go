@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/moov-io/ach"
@@ -12,18 +13,18 @@ import (
 	"github.com/moov-io/paygate/pkg/id"
 )
 
-func (c *Controller) processTransferReturn(requestID string, transfer *internal.Transfer, transferRepo internal.TransferRepository, returnCode *ach.ReturnCode) error {
+func (c *Controller) processTransferReturn(ctx context.Context, requestID string, transfer *internal.Transfer, transferRepo internal.TransferRepository, authorizationRepo internal.AuthorizationRepository, returnCode *ach.ReturnCode) error {
 	// Set the ReturnCode and update the transfer's status
-	if err := transferRepo.SetReturnCode(transfer.ID, returnCode.Code); err != nil {
+	if err := transferRepo.SetReturnCode(ctx, transfer.ID, returnCode.Code); err != nil {
 		return fmt.Errorf("problem updating ReturnCode transfer=%q: %v", transfer.ID, err)
 	}
-	if err := transferRepo.UpdateTransferStatus(transfer.ID, internal.TransferReclaimed); err != nil {
+	if err := transferRepo.UpdateTransferStatus(ctx, transfer.ID, internal.TransferReclaimed); err != nil {
 		return fmt.Errorf("problem updating transfer=%q: %v", transfer.ID, err)
 	}
 
 	// Reverse the transaction against Accounts
 	if c.accountsClient != nil && transfer.TransactionID != "" {
-		if err := c.accountsClient.ReverseTransaction(requestID, id.User(transfer.UserID), transfer.TransactionID); err != nil {
+		if err := c.accountsClient.ReverseTransaction(ctx, requestID, id.User(transfer.UserID), transfer.TransactionID); err != nil {
 			return fmt.Errorf("problem with accounts ReverseTransaction: %v", err)
 		}
 	} else {
@@ -32,5 +33,13 @@ func (c *Controller) processTransferReturn(requestID string, transfer *internal.
 		}
 	}
 
+	// Debits returned as unauthorized can't be made again with the same Authorization. The ledger is
+	// already reversed, so a failure here is logged rather than failing the return.
+	if revoked, err := internal.RevokeAuthorizationFromReturn(ctx, transfer, returnCode, authorizationRepo); err != nil {
+		c.logger.Log("processTransferReturn", fmt.Sprintf("problem revoking authorization=%s for transfer=%s: %v", transfer.Authorization, transfer.ID, err), "requestID", requestID, "userID", transfer.UserID)
+	} else if revoked {
+		c.logger.Log("processTransferReturn", fmt.Sprintf("revoked authorization=%s for transfer=%s returnCode=%s", transfer.Authorization, transfer.ID, returnCode.Code), "requestID", requestID, "userID", transfer.UserID)
+	}
+
 	return nil
 }
@@ -5,15 +5,16 @@
 package filetransfer
 
 import (
+	"context"
 	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/moov-io/base"
 	"github.com/moov-io/paygate/internal"
-	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/pkg/id"
 )
 
@@ -71,19 +72,21 @@ func TestController__processReturnTransfer(t *testing.T) {
 		},
 	}
 
+	authorizationRepo := &internal.MockAuthorizationRepository{}
+
 	dir, _ := ioutil.TempDir("", "processReturnEntry")
 	defer os.RemoveAll(dir)
 
-	repo := NewRepository("", nil, "")
+	repo := NewRepository(nil, "")
 
-	cfg := config.Empty()
+	cfg := testConfig(t)
 	controller, err := NewController(cfg, dir, repo, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	// transferRepo.xfer will be returned inside processReturnEntry and the Transfer path will be executed
-	if err := controller.processReturnEntry(file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo); err != nil {
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo, authorizationRepo); err != nil {
 		t.Error(err)
 	}
 
@@ -100,14 +103,111 @@ func TestController__processReturnTransfer(t *testing.T) {
 
 	// Check quick error conditions
 	depRepo.Err = errors.New("bad error")
-	if err := controller.processReturnEntry(file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo); err == nil {
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo, authorizationRepo); err == nil {
 		t.Error("expected error")
 	}
 	depRepo.Err = nil
 
 	transferRepo.Err = errors.New("bad error")
-	if err := controller.processReturnEntry(file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo); err == nil {
+	if err := controller.processReturnEntry(context.Background(), file.Header, b.GetHeader(), b.GetEntries()[0], depRepo, transferRepo, authorizationRepo); err == nil {
 		t.Error("expected error")
 	}
 	transferRepo.Err = nil
 }
+
+func TestController__processReturnTransferRevokesAuthorization(t *testing.T) {
+	file, err := parseACHFilepath(filepath.Join("..", "..", "testdata", "return-WEB.ach"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := file.Batches[0]
+
+	amt, _ := internal.NewAmount("USD", "52.12")
+	transferRepo := &internal.MockTransferRepository{
+		Xfer: &internal.Transfer{
+			ID:                     internal.TransferID(base.ID()),
+			Type:                   internal.PullTransfer,
+			Amount:                 *amt,
+			Originator:             internal.OriginatorID("originator"),
+			OriginatorDepository:   id.Depository("orig-depository"),
+			Receiver:               internal.ReceiverID("receiver"),
+			ReceiverDepository:     id.Depository("rec-depository"),
+			Authorization:          internal.AuthorizationID("authorization"),
+			Description:            "transfer",
+			StandardEntryClassCode: "WEB",
+			UserID:                 base.ID(),
+		},
+	}
+	authorizationRepo := &internal.MockAuthorizationRepository{
+		Authorizations: []*internal.Authorization{
+			{
+				ID:         internal.AuthorizationID("authorization"),
+				Receiver:   internal.ReceiverID("receiver"),
+				Originator: internal.OriginatorID("originator"),
+				Type:       internal.AuthorizationOnline,
+				Scope:      internal.AuthorizationRecurring,
+			},
+		},
+	}
+
+	dir, _ := ioutil.TempDir("", "processReturnEntry")
+	defer os.RemoveAll(dir)
+
+	controller, err := NewController(testConfig(t), dir, NewRepository(nil, ""), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// R02 doesn't revoke the authorization
+	b.GetEntries()[0].Addenda99.ReturnCode = "R02"
+	if err := controller.processTransferReturn(context.Background(), base.ID(), transferRepo.Xfer, transferRepo, authorizationRepo, b.GetEntries()[0].Addenda99.ReturnCodeField()); err != nil {
+		t.Fatal(err)
+	}
+	if auth := authorizationRepo.Authorizations[0]; auth.Revoked != nil {
+		t.Errorf("unexpected revocation: %v", auth.RevocationReason)
+	}
+
+	for _, code := range []string{"R07", "R10"} {
+		authorizationRepo.Authorizations[0].Revoked = nil
+		b.GetEntries()[0].Addenda99.ReturnCode = code
+		if err := controller.processTransferReturn(context.Background(), base.ID(), transferRepo.Xfer, transferRepo, authorizationRepo, b.GetEntries()[0].Addenda99.ReturnCodeField()); err != nil {
+			t.Fatal(err)
+		}
+		if auth := authorizationRepo.Authorizations[0]; auth.Revoked == nil || !strings.HasPrefix(auth.RevocationReason, code) {
+			t.Errorf("%s: authorization wasn't revoked: %#v", code, auth)
+		}
+	}
+
+	// the transaction is reversed even when the Authorization can't be revoked
+	accountsClient := &reversingAccountsClient{}
+	controller.accountsClient = accountsClient
+	transferRepo.Xfer.TransactionID = base.ID()
+	authorizationRepo.Err = errors.New("bad error")
+	if err := controller.processTransferReturn(context.Background(), base.ID(), transferRepo.Xfer, transferRepo, authorizationRepo, b.GetEntries()[0].Addenda99.ReturnCodeField()); err != nil {
+		t.Fatal(err)
+	}
+	if len(accountsClient.reversed) != 1 || accountsClient.reversed[0] != transferRepo.Xfer.TransactionID {
+		t.Errorf("unexpected reversals: %v", accountsClient.reversed)
+	}
+
+	accountsClient.err = errors.New("bad error")
+	if err := controller.processTransferReturn(context.Background(), base.ID(), transferRepo.Xfer, transferRepo, authorizationRepo, b.GetEntries()[0].Addenda99.ReturnCodeField()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// reversingAccountsClient records reversed transactions, other methods aren't implemented.
+type reversingAccountsClient struct {
+	internal.AccountsClient
+
+	reversed []string
+	err      error
+}
+
+func (c *reversingAccountsClient) ReverseTransaction(ctx context.Context, requestID string, userID id.User, transactionID string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.reversed = append(c.reversed, transactionID)
+	return nil
+}
@@ -6,54 +6,24 @@ package filetransfer
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/moov-io/paygate/internal/config"
+
 	"github.com/go-kit/kit/log"
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
 
-var (
-	sftpDialTimeout = func() time.Duration {
-		if v := os.Getenv("SFTP_DIAL_TIMEOUT"); v != "" {
-			if dur, _ := time.ParseDuration(v); dur > 0 {
-				return dur
-			}
-		}
-		return 10 * time.Second
-	}()
-
-	// sftpMaxConnsPerFile is the maximum number of concurrent connections to a file
-	//
-	// See: https://godoc.org/github.com/pkg/sftp#MaxConcurrentRequestsPerFile
-	sftpMaxConnsPerFile = func() int {
-		if n, err := strconv.Atoi(os.Getenv("SFTP_MAX_CONNS_PER_FILE")); err == nil {
-			return n
-		}
-		return 8 // pkg/sftp's default is 64
-	}()
-
-	// sftpMaxPacketSize is the maximum size for each packet sent over SFTP.
-	//
-	// Their docs suggest lowering this on "failed to send packet header: EOF" errors,
-	// so we're going to lower it by default (which is 32768).
-	sftpMaxPacketSize = func() int {
-		if n, err := strconv.Atoi(os.Getenv("SFTP_MAX_PACKET_SIZE")); err == nil {
-			return n
-		}
-		return 20480
-	}()
-)
-
 type SFTPConfig struct {
 	RoutingNumber string `yaml:"routingNumber"`
 
@@ -103,14 +73,14 @@ func (a *SFTPTransferAgent) findConfig() *SFTPConfig {
 	return nil
 }
 
-func newSFTPTransferAgent(logger log.Logger, cfg *Config, sftpConfigs []*SFTPConfig) (*SFTPTransferAgent, error) {
+func newSFTPTransferAgent(logger log.Logger, cfg *Config, sftpConfigs []*SFTPConfig, settings config.SFTPConfig) (*SFTPTransferAgent, error) {
 	agent := &SFTPTransferAgent{cfg: cfg, sftpConfigs: sftpConfigs}
 	sftpConf := agent.findConfig()
 	if sftpConf == nil {
 		return nil, fmt.Errorf("sftp: unable to find config for %s", cfg.RoutingNumber)
 	}
 
-	conn, stdin, stdout, err := sftpConnect(logger, sftpConf)
+	conn, stdin, stdout, err := sftpConnect(logger, sftpConf, settings.DialTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("filetransfer: %v", err)
 	}
@@ -118,8 +88,8 @@ func newSFTPTransferAgent(logger log.Logger, cfg *Config, sftpConfigs []*SFTPCon
 
 	// Setup our SFTP client
 	var opts = []sftp.ClientOption{
-		sftp.MaxConcurrentRequestsPerFile(sftpMaxConnsPerFile),
-		sftp.MaxPacket(sftpMaxPacketSize),
+		sftp.MaxConcurrentRequestsPerFile(settings.MaxConnectionsPerFile),
+		sftp.MaxPacket(settings.MaxPacketSize),
 	}
 	// client, err := sftp.NewClient(conn, opts...)
 	client, err := sftp.NewClientPipe(stdout, stdin, opts...)
@@ -139,10 +109,10 @@ var (
 	}
 )
 
-func sftpConnect(logger log.Logger, sftpConf *SFTPConfig) (*ssh.Client, io.WriteCloser, io.Reader, error) {
+func sftpConnect(logger log.Logger, sftpConf *SFTPConfig, dialTimeout time.Duration) (*ssh.Client, io.WriteCloser, io.Reader, error) {
 	conf := &ssh.ClientConfig{
 		User:    sftpConf.Username,
-		Timeout: sftpDialTimeout,
+		Timeout: dialTimeout,
 	}
 	conf.SetDefaults()
 
@@ -235,10 +205,18 @@ func readSigner(raw string) (ssh.Signer, error) {
 	return ssh.ParsePrivateKey([]byte(raw))
 }
 
-func (a *SFTPTransferAgent) Ping() error {
-	_, err := a.client.ReadDir(".")
-	if err != nil {
-		return fmt.Errorf("sftp: ping %v", err)
+func (a *SFTPTransferAgent) Ping(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	defer closeOnDone(ctx, a)()
+
+	for _, dir := range []string{a.cfg.InboundPath, a.cfg.OutboundPath} {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := a.client.ReadDir(dir); err != nil {
+			return fmt.Errorf("sftp: ping %s: %v", dir, err)
+		}
 	}
 	return nil
 }
@@ -267,7 +245,7 @@ func (agent *SFTPTransferAgent) ReturnPath() string {
 	return agent.cfg.ReturnPath
 }
 
-func (agent *SFTPTransferAgent) Delete(path string) error {
+func (agent *SFTPTransferAgent) Delete(ctx context.Context, path string) error {
 	info, err := agent.client.Stat(path)
 	if err != nil {
 		return fmt.Errorf("sftp: delete stat: %v", err)
@@ -283,12 +261,16 @@ func (agent *SFTPTransferAgent) Delete(path string) error {
 // uploadFile saves the content of File at the given filename in the OutboundPath directory
 //
 // The File's contents will always be closed
-func (agent *SFTPTransferAgent) UploadFile(f File) error {
+func (agent *SFTPTransferAgent) UploadFile(ctx context.Context, f File) error {
 	defer f.Close()
 
 	agent.mu.Lock()
 	defer agent.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Create OutboundPath if it doesn't exist
 	info, err := agent.client.Stat(agent.cfg.OutboundPath)
 	if info == nil || (err != nil && os.IsNotExist(err)) {
@@ -315,15 +297,15 @@ func (agent *SFTPTransferAgent) UploadFile(f File) error {
 	return nil
 }
 
-func (agent *SFTPTransferAgent) GetInboundFiles() ([]File, error) {
-	return agent.readFiles(agent.cfg.InboundPath)
+func (agent *SFTPTransferAgent) GetInboundFiles(ctx context.Context) ([]File, error) {
+	return agent.readFiles(ctx, agent.cfg.InboundPath)
 }
 
-func (agent *SFTPTransferAgent) GetReturnFiles() ([]File, error) {
-	return agent.readFiles(agent.cfg.ReturnPath)
+func (agent *SFTPTransferAgent) GetReturnFiles(ctx context.Context) ([]File, error) {
+	return agent.readFiles(ctx, agent.cfg.ReturnPath)
 }
 
-func (agent *SFTPTransferAgent) readFiles(dir string) ([]File, error) {
+func (agent *SFTPTransferAgent) readFiles(ctx context.Context, dir string) ([]File, error) {
 	infos, err := agent.client.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("sftp: readdir %s: %v", dir, err)
@@ -331,6 +313,9 @@ func (agent *SFTPTransferAgent) readFiles(dir string) ([]File, error) {
 
 	var files []File
 	for i := range infos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		fd, err := agent.client.Open(filepath.Join(dir, infos[i].Name()))
 		if err != nil {
 			return nil, fmt.Errorf("sftp: open %s: %v", infos[i].Name(), err)
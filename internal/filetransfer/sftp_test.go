@@ -5,6 +5,7 @@
 package filetransfer
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -18,6 +19,7 @@ import (
 	"time"
 
 	"github.com/moov-io/base/docker"
+	"github.com/moov-io/paygate/internal/config"
 
 	"github.com/go-kit/kit/log"
 	"github.com/ory/dockertest/v3"
@@ -66,6 +68,7 @@ func spawnSFTP(t *testing.T) *sftpDeployment {
 
 	// Setup a temp directory for our SFTP instance
 	dir, uid, gid := mkdir(t)
+	os.MkdirAll(filepath.Join(dir, "inbound"), 0777) // Ping lists the inbound directory
 
 	// Start our Docker image
 	pool, err := dockertest.NewPool("")
@@ -99,7 +102,7 @@ func spawnSFTP(t *testing.T) *sftpDeployment {
 		t.Fatal(err)
 	}
 	err = pool.Retry(func() error {
-		return agent.Ping()
+		return agent.Ping(context.Background())
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -147,7 +150,12 @@ func newAgent(host, user, pass, passFile string) (*SFTPTransferAgent, error) {
 	} else {
 		sftpConfigs[0].ClientPrivateKey = passFile
 	}
-	return newSFTPTransferAgent(log.NewNopLogger(), cfg, sftpConfigs)
+	settings := config.SFTPConfig{
+		DialTimeout:           10 * time.Second,
+		MaxConnectionsPerFile: 8,
+		MaxPacketSize:         20480,
+	}
+	return newSFTPTransferAgent(log.NewNopLogger(), cfg, sftpConfigs, settings)
 }
 
 func cp(from, to string) error {
@@ -167,11 +175,11 @@ func TestSFTP__password(t *testing.T) {
 	deployment := spawnSFTP(t)
 	defer deployment.close(t)
 
-	if err := deployment.agent.Ping(); err != nil {
+	if err := deployment.agent.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
-	err := deployment.agent.UploadFile(File{
+	err := deployment.agent.UploadFile(context.Background(), File{
 		Filename: "upload.ach",
 		Contents: ioutil.NopCloser(strings.NewReader("test data")),
 	})
@@ -179,7 +187,7 @@ func TestSFTP__password(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := deployment.agent.Delete(deployment.agent.OutboundPath() + "upload.ach"); err != nil {
+	if err := deployment.agent.Delete(context.Background(), deployment.agent.OutboundPath() + "upload.ach"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -192,7 +200,7 @@ func TestSFTP__password(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	files, err := deployment.agent.GetInboundFiles()
+	files, err := deployment.agent.GetInboundFiles(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -209,7 +217,7 @@ func TestSFTP__password(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	files, err = deployment.agent.GetReturnFiles()
+	files, err = deployment.agent.GetReturnFiles(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -223,12 +231,12 @@ func TestSFTP__readFilesEmpty(t *testing.T) {
 	deployment := spawnSFTP(t)
 	defer deployment.close(t)
 
-	if err := deployment.agent.Ping(); err != nil {
+	if err := deployment.agent.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
 	// Upload an empty file
-	err := deployment.agent.UploadFile(File{
+	err := deployment.agent.UploadFile(context.Background(), File{
 		Filename: "upload.ach",
 		Contents: ioutil.NopCloser(strings.NewReader("")),
 	})
@@ -251,7 +259,7 @@ func TestSFTP__readFilesEmpty(t *testing.T) {
 	}
 
 	// Read the empty file
-	files, err := deployment.agent.readFiles(deployment.agent.OutboundPath())
+	files, err := deployment.agent.readFiles(context.Background(), deployment.agent.OutboundPath())
 	if err == nil || !strings.Contains(err.Error(), "sftp: read (n=0) on upload.ach") {
 		t.Fatal(err)
 	}
@@ -260,7 +268,7 @@ func TestSFTP__readFilesEmpty(t *testing.T) {
 	}
 
 	// read a non-existent directory
-	files, err = deployment.agent.readFiles("/dev/null")
+	files, err = deployment.agent.readFiles(context.Background(), "/dev/null")
 	if err == nil {
 		t.Errorf("expected error -- files: %#v", files)
 	}
@@ -283,13 +291,13 @@ func TestSFTP__uploadFile(t *testing.T) {
 	deployment := spawnSFTP(t)
 	defer deployment.close(t)
 
-	if err := deployment.agent.Ping(); err != nil {
+	if err := deployment.agent.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
 	// force out OutboundPath to create more directories
 	deployment.agent.cfg.OutboundPath = filepath.Join("upload", "foo")
-	err := deployment.agent.UploadFile(File{
+	err := deployment.agent.UploadFile(context.Background(), File{
 		Filename: "upload.ach",
 		Contents: ioutil.NopCloser(strings.NewReader("test data")),
 	})
@@ -299,7 +307,7 @@ func TestSFTP__uploadFile(t *testing.T) {
 
 	// fail to create the OutboundPath
 	deployment.agent.cfg.OutboundPath = string(os.PathSeparator) + filepath.Join("home", "bad-path")
-	err = deployment.agent.UploadFile(File{
+	err = deployment.agent.UploadFile(context.Background(), File{
 		Filename: "upload.ach",
 		Contents: ioutil.NopCloser(strings.NewReader("test data")),
 	})
@@ -359,7 +367,7 @@ wg/HcAJWY60xZTJDFN+Qfx8ZQvBEin6c2/h+zZi5IVY=
 func TestSFTP__sftpConnect(t *testing.T) {
 	client, _, _, err := sftpConnect(log.NewNopLogger(), &SFTPConfig{
 		Username: "foo",
-	})
+	}, time.Second)
 	if client != nil || err == nil {
 		t.Errorf("client=%v err=%v", client, err)
 	}
@@ -367,7 +375,7 @@ func TestSFTP__sftpConnect(t *testing.T) {
 	// bad host public key
 	_, _, _, err = sftpConnect(log.NewNopLogger(), &SFTPConfig{
 		HostPublicKey: "bad key material",
-	})
+	}, time.Second)
 	if err == nil {
 		t.Errorf("expected error")
 	}
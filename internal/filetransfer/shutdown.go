@@ -0,0 +1,151 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moov-io/paygate/internal/util"
+)
+
+// shutdownState records what a Controller is working on so Shutdown can wait for it to finish
+// and report anything which didn't.
+type shutdownState struct {
+	mu sync.Mutex
+
+	// stopped is closed once StartPeriodicFileOperations returns
+	stopped chan struct{}
+
+	// abort cancels the context operations run with
+	abort context.CancelFunc
+
+	// operations maps each running operation to when it started
+	operations map[string]time.Time
+
+	// pending holds merged files which are waiting on (or in the middle of) their upload
+	pending map[string]bool
+}
+
+func (s *shutdownState) stoppedChan() chan struct{} {
+	if s.stopped == nil {
+		s.stopped = make(chan struct{})
+	}
+	return s.stopped
+}
+
+// start prepares for StartPeriodicFileOperations to run. The returned context is used for every
+// operation and is only cancelled by Shutdown, the returned func is called once the loop exits.
+func (c *Controller) start(ctx context.Context) (func(), context.Context) {
+	workCtx, abort := context.WithCancel(util.Detach(ctx))
+
+	c.shutdown.mu.Lock()
+	defer c.shutdown.mu.Unlock()
+
+	c.shutdown.abort = abort
+	stopped := c.shutdown.stoppedChan()
+
+	return func() {
+		abort()
+		close(stopped)
+	}, workCtx
+}
+
+// track records that the named operation is running until the returned func is called.
+func (c *Controller) track(operation string) func() {
+	c.shutdown.mu.Lock()
+	defer c.shutdown.mu.Unlock()
+
+	if c.shutdown.operations == nil {
+		c.shutdown.operations = make(map[string]time.Time)
+	}
+	c.shutdown.operations[operation] = time.Now()
+
+	return func() {
+		c.shutdown.mu.Lock()
+		defer c.shutdown.mu.Unlock()
+		delete(c.shutdown.operations, operation)
+	}
+}
+
+// markPending records files which are about to be uploaded.
+func (c *Controller) markPending(files []*achFile) {
+	c.shutdown.mu.Lock()
+	defer c.shutdown.mu.Unlock()
+
+	if c.shutdown.pending == nil {
+		c.shutdown.pending = make(map[string]bool)
+	}
+	for i := range files {
+		c.shutdown.pending[files[i].filepath] = true
+	}
+}
+
+// clearPending removes a file once its upload is finished or abandoned.
+func (c *Controller) clearPending(file *achFile) {
+	c.shutdown.mu.Lock()
+	defer c.shutdown.mu.Unlock()
+	delete(c.shutdown.pending, file.filepath)
+}
+
+// Shutdown waits for StartPeriodicFileOperations to finish its current operations and return, which
+// happens after its context is cancelled. If ctx is done first the operations are aborted at their next
+// safe point (an upload in progress is finished and renamed) and an error describing them is returned.
+//
+// Files which weren't uploaded stay in the merged directory and are picked up once paygate restarts.
+func (c *Controller) Shutdown(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.shutdown.mu.Lock()
+	stopped := c.shutdown.stoppedChan()
+	c.shutdown.mu.Unlock()
+
+	select {
+	case <-stopped:
+		c.logger.Log("file-transfer-controller", "finished file operations for shutdown")
+		return nil
+
+	case <-ctx.Done():
+		c.shutdown.mu.Lock()
+		defer c.shutdown.mu.Unlock()
+
+		if c.shutdown.abort != nil {
+			c.shutdown.abort()
+		}
+		err := fmt.Errorf("file-transfer-controller: shutdown interrupted %s", c.shutdown.describe())
+		c.logger.Log("file-transfer-controller", err.Error())
+		return err
+	}
+}
+
+// describe lists the running operations and pending uploads. The caller must hold s.mu.
+func (s *shutdownState) describe() string {
+	var ops []string
+	for name, started := range s.operations {
+		ops = append(ops, fmt.Sprintf("%s (running for %v)", name, time.Since(started).Round(time.Millisecond)))
+	}
+	sort.Strings(ops)
+
+	var files []string
+	for path := range s.pending {
+		files = append(files, filepath.Base(path))
+	}
+	sort.Strings(files)
+
+	if len(ops) == 0 {
+		ops = append(ops, "no operations")
+	}
+	out := strings.Join(ops, ", ")
+	if len(files) > 0 {
+		out += fmt.Sprintf(" with %d files not uploaded: %s", len(files), strings.Join(files, ", "))
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestController__Shutdown(t *testing.T) {
+	controller := &Controller{logger: log.NewNopLogger()}
+
+	ctx, cancelFileSync := context.WithCancel(context.Background())
+	stopped, workCtx := controller.start(ctx)
+
+	// cancelling the loop's context doesn't cancel its operations
+	done := controller.track("mergeAndUploadFiles")
+	controller.markPending([]*achFile{{filepath: "/tmp/20200101-987654320-1.ach"}})
+	cancelFileSync()
+	if err := workCtx.Err(); err != nil {
+		t.Fatalf("operations were cancelled: %v", err)
+	}
+
+	// give up waiting on the operation
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := controller.Shutdown(shutdownCtx)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "mergeAndUploadFiles (running for") || !strings.Contains(err.Error(), "1 files not uploaded: 20200101-987654320-1.ach") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := workCtx.Err(); err != context.Canceled {
+		t.Errorf("expected operations to be aborted: %v", err)
+	}
+
+	// once the loop exits Shutdown returns right away
+	done()
+	stopped()
+	if err := controller.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestController__ShutdownNil(t *testing.T) {
+	var controller *Controller
+	if err := controller.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestController__startUploadCancelled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "startUploadCancelled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "20200101-987654320-1.ach")
+	if err := writeACHFile(path); err != nil {
+		t.Fatal(err)
+	}
+	file, err := parseACHFilepath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	controller := &Controller{logger: log.NewNopLogger()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = controller.startUpload(ctx, []*achFile{{File: file, filepath: path}})
+	if err == nil || !strings.Contains(err.Error(), "stopped before uploading 20200101-987654320-1.ach") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the file is left to be uploaded later
+	if _, err := os.Stat(path); err != nil {
+		t.Error(err)
+	}
+	if len(controller.shutdown.pending) != 0 {
+		t.Errorf("unexpected pending files: %v", controller.shutdown.pending)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	moovhttp "github.com/moov-io/base/http"
 	"github.com/moov-io/paygate/internal"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/route"
 
 	"github.com/go-kit/kit/log"
@@ -42,8 +43,8 @@ func (r gatewayRequest) missingFields() error {
 }
 
 func AddRoutes(logger log.Logger, r *mux.Router, gatewayRepo Repository) {
-	r.Methods("GET").Path("/gateways").HandlerFunc(getUserGateway(logger, gatewayRepo))
-	r.Methods("POST").Path("/gateways").HandlerFunc(createUserGateway(logger, gatewayRepo))
+	r.Methods("GET").Path("/gateways").HandlerFunc(auth.Require(auth.GatewaysRead, getUserGateway(logger, gatewayRepo)))
+	r.Methods("POST").Path("/gateways").HandlerFunc(auth.Require(auth.GatewaysWrite, createUserGateway(logger, gatewayRepo)))
 }
 
 func getUserGateway(logger log.Logger, gatewayRepo Repository) http.HandlerFunc {
@@ -53,7 +54,7 @@ func getUserGateway(logger log.Logger, gatewayRepo Repository) http.HandlerFunc
 			return
 		}
 
-		gateway, err := gatewayRepo.getUserGateway(responder.XUserID)
+		gateway, err := gatewayRepo.getUserGateway(r.Context(), responder.XUserID)
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
@@ -83,7 +84,7 @@ func createUserGateway(logger log.Logger, gatewayRepo Repository) http.HandlerFu
 			return
 		}
 
-		gateway, err := gatewayRepo.createUserGateway(responder.XUserID, wrapper)
+		gateway, err := gatewayRepo.createUserGateway(r.Context(), responder.XUserID, wrapper)
 		if err != nil {
 			responder.Problem(err)
 			return
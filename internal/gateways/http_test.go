@@ -12,8 +12,8 @@ import (
 	"testing"
 
 	"github.com/go-kit/kit/log"
-	"github.com/gorilla/mux"
 	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/database"
 )
 
@@ -30,7 +30,7 @@ func TestGateways__HTTPGetNoUserID(t *testing.T) {
 
 	repo := &SQLGatewayRepo{db.DB, log.NewNopLogger()}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddRoutes(log.NewNopLogger(), router, repo)
 
 	body := strings.NewReader(`{"key": "value"}`)
@@ -40,7 +40,7 @@ func TestGateways__HTTPGetNoUserID(t *testing.T) {
 	router.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status=%d: %v", w.Code, w.Body.String())
 	}
 }
@@ -51,7 +51,7 @@ func TestGateways__HTTPCreate(t *testing.T) {
 
 	repo := &SQLGatewayRepo{db.DB, log.NewNopLogger()}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddRoutes(log.NewNopLogger(), router, repo)
 
 	body := strings.NewReader(`{"origin": "987654320", "originName": "bank", "destination": "123456780", "destinationName": "other bank"}`)
@@ -83,7 +83,7 @@ func TestGateways__HTTPCreateNoUserID(t *testing.T) {
 
 	repo := &SQLGatewayRepo{db.DB, log.NewNopLogger()}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddRoutes(log.NewNopLogger(), router, repo)
 
 	body := strings.NewReader(`{"key": "value"}`)
@@ -93,7 +93,7 @@ func TestGateways__HTTPCreateNoUserID(t *testing.T) {
 	router.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status=%d: %v", w.Code, w.Body.String())
 	}
 }
@@ -104,7 +104,7 @@ func TestGateways__HTTPCreateErr(t *testing.T) {
 
 	repo := &SQLGatewayRepo{db.DB, log.NewNopLogger()}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddRoutes(log.NewNopLogger(), router, repo)
 
 	// invalid JSON
@@ -5,20 +5,22 @@
 package gateways
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/moov-io/base"
 	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/tracing"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
 )
 
 type Repository interface {
-	getUserGateway(userID id.User) (*Gateway, error)
-	createUserGateway(userID id.User, req gatewayRequest) (*Gateway, error)
+	getUserGateway(ctx context.Context, userID id.User) (*Gateway, error)
+	createUserGateway(ctx context.Context, userID id.User, req gatewayRequest) (*Gateway, error)
 }
 
 func NewRepo(logger log.Logger, db *sql.DB) *SQLGatewayRepo {
@@ -34,7 +36,12 @@ func (r *SQLGatewayRepo) Close() error {
 	return r.db.Close()
 }
 
-func (r *SQLGatewayRepo) createUserGateway(userID id.User, req gatewayRequest) (*Gateway, error) {
+func (r *SQLGatewayRepo) createUserGateway(ctx context.Context, userID id.User, req gatewayRequest) (_ *Gateway, err error) {
+	ctx, span := tracing.Start(ctx, "gatewayRepo.createUserGateway")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	gateway := &Gateway{
 		Origin:          req.Origin,
 		OriginName:      req.OriginName,
@@ -46,19 +53,19 @@ func (r *SQLGatewayRepo) createUserGateway(userID id.User, req gatewayRequest) (
 		return nil, err
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	query := `select gateway_id from gateways where user_id = ? and deleted_at is null`
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(userID)
+	row := stmt.QueryRowContext(ctx, userID)
 
 	var gatewayID string
 	err = row.Scan(&gatewayID)
@@ -72,24 +79,24 @@ func (r *SQLGatewayRepo) createUserGateway(userID id.User, req gatewayRequest) (
 
 	// insert/update row
 	query = `insert into gateways (gateway_id, user_id, origin, origin_name, destination, destination_name, created_at) values (?, ?, ?, ?, ?, ?, ?)`
-	stmt, err = tx.Prepare(query)
+	stmt, err = tx.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("createUserGateway: prepare error=%v rollback=%v", err, tx.Rollback())
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(gatewayID, userID, gateway.Origin, gateway.OriginName, gateway.Destination, gateway.DestinationName, gateway.Created.Time)
+	_, err = stmt.ExecContext(ctx, gatewayID, userID, gateway.Origin, gateway.OriginName, gateway.Destination, gateway.DestinationName, gateway.Created.Time)
 	stmt.Close()
 	if err != nil {
 		// We need to update the row as it already exists.
 		if database.UniqueViolation(err) {
 			query = `update gateways set origin = ?, origin_name = ?, destination = ?, destination_name = ? where gateway_id = ? and user_id = ?`
-			stmt, err = tx.Prepare(query)
+			stmt, err = tx.PrepareContext(ctx, query)
 			if err != nil {
 				return nil, fmt.Errorf("createUserGateway: update: error=%v rollback=%v", err, tx.Rollback())
 			}
 			defer stmt.Close()
-			_, err = stmt.Exec(gateway.Origin, gateway.OriginName, gateway.Destination, gateway.DestinationName, gatewayID, userID)
+			_, err = stmt.ExecContext(ctx, gateway.Origin, gateway.OriginName, gateway.Destination, gateway.DestinationName, gatewayID, userID)
 			stmt.Close()
 			if err != nil {
 				return nil, fmt.Errorf("createUserGateway: update exec: error=%v rollback=%v", err, tx.Rollback())
@@ -104,16 +111,16 @@ func (r *SQLGatewayRepo) createUserGateway(userID id.User, req gatewayRequest) (
 	return gateway, nil
 }
 
-func (r *SQLGatewayRepo) getUserGateway(userID id.User) (*Gateway, error) {
+func (r *SQLGatewayRepo) getUserGateway(ctx context.Context, userID id.User) (*Gateway, error) {
 	query := `select gateway_id, origin, origin_name, destination, destination_name, created_at
 from gateways where user_id = ? and deleted_at is null limit 1`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(userID)
+	row := stmt.QueryRowContext(ctx, userID)
 
 	gateway := &Gateway{}
 	var created time.Time
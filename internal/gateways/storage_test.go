@@ -5,6 +5,7 @@
 package gateways
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -30,7 +31,7 @@ func TestGateways_getUserGateways(t *testing.T) {
 			Destination:     "031300012",
 			DestinationName: "my other bank",
 		}
-		gateway, err := repo.createUserGateway(userID, req)
+		gateway, err := repo.createUserGateway(context.Background(), userID, req)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -60,6 +61,11 @@ func TestGateways_getUserGateways(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewRepo(log.NewNopLogger(), sqliteDB.DB))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewRepo(log.NewNopLogger(), postgresDB.DB))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -77,13 +83,13 @@ func TestGateways_update(t *testing.T) {
 			Destination:     "031300012",
 			DestinationName: "my other bank",
 		}
-		gateway, err := repo.createUserGateway(userID, req)
+		gateway, err := repo.createUserGateway(context.Background(), userID, req)
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		// read gateway
-		gw, err := repo.getUserGateway(userID)
+		gw, err := repo.getUserGateway(context.Background(), userID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -93,11 +99,11 @@ func TestGateways_update(t *testing.T) {
 
 		// Update Origin
 		req.Origin = "031300012"
-		_, err = repo.createUserGateway(userID, req)
+		_, err = repo.createUserGateway(context.Background(), userID, req)
 		if err != nil {
 			t.Fatal(err)
 		}
-		gw, err = repo.getUserGateway(userID)
+		gw, err = repo.getUserGateway(context.Background(), userID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -114,6 +120,11 @@ func TestGateways_update(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, &SQLGatewayRepo{sqliteDB.DB, log.NewNopLogger()})
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, &SQLGatewayRepo{postgresDB.DB, log.NewNopLogger()})
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -5,6 +5,7 @@
 package internal
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -55,6 +56,21 @@ func AddPingRoute(logger log.Logger, r *mux.Router) {
 	})
 }
 
+// RequestTimeout is HTTP middleware which cancels each request's context after d, so the calls
+// made while serving it give up once the deadline passes. A zero d leaves requests without a deadline.
+func RequestTimeout(d time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func Wrap(logger log.Logger, w http.ResponseWriter, r *http.Request) http.ResponseWriter {
 	name := fmt.Sprintf("%s-%s", strings.ToLower(r.Method), route.CleanPath(r.URL.Path))
 	return moovhttp.Wrap(logger, route.Histogram.With("route", name), w, r)
@@ -6,6 +6,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/pem"
 	"io/ioutil"
@@ -59,6 +60,32 @@ func TestHttp__AddPingRoute(t *testing.T) {
 	}
 }
 
+func TestHTTP__RequestTimeout(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(RequestTimeout(time.Millisecond))
+	router.Methods("GET").Path("/slow").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		if err := r.Context().Err(); err != context.DeadlineExceeded {
+			t.Errorf("unexpected error: %v", err)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+
+	// no deadline is set when disabled
+	handler := RequestTimeout(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			t.Error("unexpected deadline")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
 func TestHTTP__TLSHttpClient(t *testing.T) {
 	client, err := TLSHttpClient("")
 	if err != nil {
@@ -0,0 +1,120 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+// Package iav is a client for instant account verification (IAV) providers. Users link their bank
+// account with the provider (typically by logging into their online banking) and paygate exchanges
+// the resulting token for the account's routing and account numbers.
+package iav
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Account is a bank account the user linked with the instant account verification provider.
+type Account struct {
+	RoutingNumber string `json:"routingNumber"`
+	AccountNumber string `json:"accountNumber"`
+}
+
+type Client interface {
+	Ping(ctx context.Context) error
+
+	// LookupAccount exchanges a token from the provider for the bank account the user linked.
+	LookupAccount(ctx context.Context, requestID string, userID id.User, token string) (*Account, error)
+}
+
+type httpClient struct {
+	endpoint   string
+	underlying *http.Client
+	logger     log.Logger
+}
+
+func (c *httpClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequest("GET", c.endpoint+"/ping", nil)
+	if err != nil {
+		return fmt.Errorf("IAV ping: %v", err)
+	}
+	resp, err := c.underlying.Do(req.WithContext(ctx))
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if resp == nil {
+		return fmt.Errorf("IAV ping failed: %v", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("IAV ping got status: %s", resp.Status)
+	}
+	return err
+}
+
+type lookupRequest struct {
+	Token string `json:"token"`
+}
+
+func (c *httpClient) LookupAccount(ctx context.Context, requestID string, userID id.User, token string) (*Account, error) {
+	if token == "" {
+		return nil, errors.New("IAV: missing token")
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(lookupRequest{Token: token}); err != nil {
+		return nil, fmt.Errorf("IAV: encoding request: %v", err)
+	}
+	req, err := http.NewRequest("POST", c.endpoint+"/accounts/lookup", &body)
+	if err != nil {
+		return nil, fmt.Errorf("IAV: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", requestID)
+	req.Header.Set("X-User-ID", string(userID))
+
+	resp, err := c.underlying.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("IAV: account lookup failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("IAV: account lookup got status: %s", resp.Status)
+	}
+
+	var acct Account
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&acct); err != nil {
+		return nil, fmt.Errorf("IAV: reading account: %v", err)
+	}
+	if acct.RoutingNumber == "" || acct.AccountNumber == "" {
+		return nil, errors.New("IAV: incomplete account returned")
+	}
+	return &acct, nil
+}
+
+// NewClient returns a Client for the instant account verification provider at endpoint, or nil
+// if no endpoint is configured.
+func NewClient(logger log.Logger, endpoint string, underlying *http.Client) Client {
+	if endpoint == "" {
+		return nil
+	}
+	if underlying == nil {
+		underlying = &http.Client{}
+	}
+
+	logger.Log("iav", fmt.Sprintf("using %s for instant account verification", endpoint))
+
+	return &httpClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		underlying: underlying,
+		logger:     logger,
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package iav
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestIAV(t *testing.T) {
+	stub := NewStub()
+	stub.Link("good-token", &Account{RoutingNumber: "121042882", AccountNumber: "151"})
+
+	svc := httptest.NewServer(stub)
+	defer svc.Close()
+
+	client := NewClient(log.NewNopLogger(), svc.URL+"/", nil)
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	acct, err := client.LookupAccount(context.Background(), base.ID(), id.User(base.ID()), "good-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.RoutingNumber != "121042882" || acct.AccountNumber != "151" {
+		t.Errorf("unexpected account: %#v", acct)
+	}
+
+	// unknown and missing tokens
+	if _, err := client.LookupAccount(context.Background(), base.ID(), id.User(base.ID()), "other"); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := client.LookupAccount(context.Background(), base.ID(), id.User(base.ID()), ""); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestIAV__NewClient(t *testing.T) {
+	if client := NewClient(log.NewNopLogger(), "", nil); client != nil {
+		t.Errorf("expected nil Client: %#v", client)
+	}
+	if client := NewClient(log.NewNopLogger(), "http://localhost:8080", nil); client == nil {
+		t.Error("expected Client")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package iav
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Stub is a local instant account verification provider for tests and development. Tokens are
+// registered with the account they resolve to and unknown tokens return a 404.
+type Stub struct {
+	mu       sync.Mutex
+	accounts map[string]*Account
+}
+
+func NewStub() *Stub {
+	return &Stub{accounts: make(map[string]*Account)}
+}
+
+// Link registers token as the result of a user linking the given account.
+func (s *Stub) Link(token string, acct *Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[token] = acct
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/ping":
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("PONG"))
+
+	case r.Method == "POST" && r.URL.Path == "/accounts/lookup":
+		var req lookupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		acct, exists := s.accounts[req.Token]
+		s.mu.Unlock()
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(acct)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
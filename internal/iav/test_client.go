@@ -0,0 +1,26 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package iav
+
+import (
+	"context"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+type TestClient struct {
+	Account *Account
+	Err     error
+}
+
+func (c *TestClient) Ping(ctx context.Context) error {
+	return c.Err
+}
+
+func (c *TestClient) LookupAccount(ctx context.Context, requestID string, userID id.User, token string) (*Account, error) {
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	return c.Account, nil
+}
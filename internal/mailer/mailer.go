@@ -0,0 +1,118 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/util"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// New returns a Mailer which sends emails over SMTP when an address is configured, otherwise
+// emails are written into a directory. Emails are only logged when LogOnly is explicitly set.
+func New(logger log.Logger, cfg *config.EmailConfig) (Mailer, error) {
+	if cfg == nil {
+		return nil, errors.New("mailer: missing email config")
+	}
+	if cfg.SMTP.Address != "" {
+		host, _, err := net.SplitHostPort(cfg.SMTP.Address)
+		if err != nil {
+			return nil, fmt.Errorf("mailer: invalid SMTP address %q: %v", cfg.SMTP.Address, err)
+		}
+		m := &smtpMailer{
+			address: cfg.SMTP.Address,
+			from:    cfg.From,
+		}
+		if cfg.SMTP.Username != "" {
+			m.auth = smtp.PlainAuth("", cfg.SMTP.Username, cfg.SMTP.Password, host)
+		}
+		return m, nil
+	}
+	if cfg.Directory != "" {
+		if err := os.MkdirAll(cfg.Directory, 0777); err != nil {
+			return nil, fmt.Errorf("mailer: creating %s: %v", cfg.Directory, err)
+		}
+		return &directoryMailer{dir: cfg.Directory, from: cfg.From}, nil
+	}
+	if cfg.LogOnly {
+		return &logMailer{logger: logger}, nil
+	}
+	return nil, errors.New("mailer: no SMTP address or email directory configured (set EMAIL_LOG_ONLY=yes for local development)")
+}
+
+func encode(from string, msg *Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	buf.WriteString(strings.Replace(msg.Body, "\n", "\r\n", -1))
+	return buf.Bytes()
+}
+
+type smtpMailer struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+func (m *smtpMailer) Send(msg *Message) error {
+	if err := smtp.SendMail(m.address, m.auth, m.from, []string{msg.To}, encode(m.from, msg)); err != nil {
+		return fmt.Errorf("mailer: sending to %s: %v", m.address, err)
+	}
+	return nil
+}
+
+// directoryMailer writes each email as a .eml file, which is useful for local development
+type directoryMailer struct {
+	dir  string
+	from string
+}
+
+func (m *directoryMailer) Send(msg *Message) error {
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, msg.To))
+	return ioutil.WriteFile(filepath.Join(m.dir, name), encode(m.from, msg), 0644)
+}
+
+type logMailer struct {
+	logger log.Logger
+}
+
+// Send logs the subject and a masked recipient. The body is never logged since it can
+// contain verification tokens.
+func (m *logMailer) Send(msg *Message) error {
+	m.logger.Log("mailer", fmt.Sprintf("dropping email to=%s subject=%q", util.MaskEmail(msg.To), msg.Subject))
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package mailer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestMailer__New(t *testing.T) {
+	logger := log.NewNopLogger()
+
+	if _, err := New(logger, nil); err == nil {
+		t.Error("expected error")
+	}
+
+	cfg := &config.EmailConfig{From: "paygate@moov.io"}
+	if _, err := New(logger, cfg); err == nil {
+		t.Error("expected error without SMTP or a directory")
+	}
+	cfg.LogOnly = true
+	if m, err := New(logger, cfg); err != nil {
+		t.Fatal(err)
+	} else if _, ok := m.(*logMailer); !ok {
+		t.Errorf("unexpected Mailer: %T", m)
+	}
+
+	cfg.SMTP.Address = "smtp.example.com:587"
+	cfg.SMTP.Username = "user"
+	if m, err := New(logger, cfg); err != nil {
+		t.Fatal(err)
+	} else if sm, ok := m.(*smtpMailer); !ok || sm.auth == nil {
+		t.Errorf("unexpected Mailer: %#v", m)
+	}
+
+	cfg.SMTP.Address = "smtp.example.com"
+	if _, err := New(logger, cfg); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMailer__directory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mailer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.EmailConfig{From: "paygate@moov.io", Directory: filepath.Join(dir, "emails")}
+
+	m, err := New(log.NewNopLogger(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(&Message{To: "jane@moov.io", Subject: "Hello", Body: "line one\nline two"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fds, err := ioutil.ReadDir(cfg.Directory)
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("fds=%v error=%v", fds, err)
+	}
+	bs, err := ioutil.ReadFile(filepath.Join(cfg.Directory, fds[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := string(bs)
+	for _, expected := range []string{"From: paygate@moov.io\r\n", "To: jane@moov.io\r\n", "Subject: Hello\r\n", "\r\n\r\nline one\r\nline two"} {
+		if !strings.Contains(email, expected) {
+			t.Errorf("missing %q in %q", expected, email)
+		}
+	}
+}
+
+func TestMailer__log(t *testing.T) {
+	var buf bytes.Buffer
+	m, err := New(log.NewLogfmtLogger(&buf), &config.EmailConfig{LogOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(&Message{To: "jane@moov.io", Subject: "Hello", Body: "token=secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "j***@moov.io") || strings.Contains(out, "jane") || strings.Contains(out, "secret") {
+		t.Errorf("unexpected log: %q", out)
+	}
+}
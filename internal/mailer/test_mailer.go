@@ -0,0 +1,20 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package mailer
+
+type TestMailer struct {
+	// Messages holds every sent Message
+	Messages []*Message
+
+	Err error
+}
+
+func (m *TestMailer) Send(msg *Message) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Messages = append(m.Messages, msg)
+	return nil
+}
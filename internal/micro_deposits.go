@@ -5,6 +5,7 @@
 package internal
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/json"
@@ -13,7 +14,6 @@ import (
 	"io"
 	"math/big"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,9 +23,10 @@ import (
 	"github.com/moov-io/ach"
 	"github.com/moov-io/base"
 	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/internal/route"
 	"github.com/moov-io/paygate/internal/secrets"
-	"github.com/moov-io/paygate/internal/util"
+	"github.com/moov-io/paygate/internal/tracing"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
@@ -51,6 +52,10 @@ type ODFIAccount struct {
 	routingNumber string
 	accountType   AccountType
 
+	identification string
+	bankName       string
+	holder         string
+
 	client AccountsClient
 
 	keeper *secrets.StringKeeper
@@ -59,17 +64,20 @@ type ODFIAccount struct {
 	accountID string
 }
 
-func NewODFIAccount(accountsClient AccountsClient, accountNumber string, routingNumber string, accountType AccountType, keeper *secrets.StringKeeper) *ODFIAccount {
+func NewODFIAccount(accountsClient AccountsClient, cfg *config.ODFIConfig, keeper *secrets.StringKeeper) *ODFIAccount {
 	return &ODFIAccount{
-		client:        accountsClient,
-		accountNumber: accountNumber,
-		routingNumber: routingNumber,
-		accountType:   accountType,
-		keeper:        keeper,
+		client:         accountsClient,
+		accountNumber:  cfg.AccountNumber,
+		routingNumber:  cfg.RoutingNumber,
+		accountType:    AccountType(strings.ToLower(cfg.AccountType)),
+		identification: cfg.Identification,
+		bankName:       cfg.BankName,
+		holder:         cfg.Holder,
+		keeper:         keeper,
 	}
 }
 
-func (a *ODFIAccount) getID(requestID string, userID id.User) (string, error) {
+func (a *ODFIAccount) getID(ctx context.Context, requestID string, userID id.User) (string, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -91,7 +99,7 @@ func (a *ODFIAccount) getID(requestID string, userID id.User) (string, error) {
 	dep.keeper = a.keeper
 	dep.ReplaceAccountNumber(a.accountNumber)
 
-	acct, err := a.client.SearchAccounts(requestID, userID, dep)
+	acct, err := a.client.SearchAccounts(ctx, requestID, userID, dep)
 	if err != nil || (acct == nil || acct.ID == "") {
 		return "", fmt.Errorf("ODFIAccount: problem getting accountID: %v", err)
 	}
@@ -103,7 +111,7 @@ func (a *ODFIAccount) metadata() (*Originator, *Depository) {
 	orig := &Originator{
 		ID:                "odfi", // TODO(adam): make this NOT querable via db.
 		DefaultDepository: id.Depository("odfi"),
-		Identification:    util.Or(os.Getenv("ODFI_IDENTIFICATION"), "001"),
+		Identification:    a.identification,
 		Metadata:          "Moov - paygate micro-deposits",
 	}
 	num, err := a.keeper.EncryptString(a.accountNumber)
@@ -112,8 +120,8 @@ func (a *ODFIAccount) metadata() (*Originator, *Depository) {
 	}
 	dep := &Depository{
 		ID:                     id.Depository("odfi"),
-		BankName:               util.Or(os.Getenv("ODFI_BANK_NAME"), "Moov, Inc"),
-		Holder:                 util.Or(os.Getenv("ODFI_HOLDER"), "Moov, Inc"),
+		BankName:               a.bankName,
+		Holder:                 a.holder,
 		HolderType:             Individual,
 		Type:                   a.accountType,
 		RoutingNumber:          a.routingNumber,
@@ -128,6 +136,10 @@ type MicroDeposit struct {
 	Amount        Amount
 	FileID        string
 	TransactionID string
+	CreatedAt     time.Time
+
+	// WithdrawTransactionID is the Accounts transaction which debited every micro-deposit back out
+	WithdrawTransactionID string
 }
 
 func (m MicroDeposit) MarshalJSON() ([]byte, error) {
@@ -169,7 +181,7 @@ func (r *DepositoryRouter) initiateMicroDeposits() http.HandlerFunc {
 		}
 
 		// Check the depository status and confirm it belongs to the user
-		dep, err := r.depositoryRepo.GetUserDepository(depID, responder.XUserID)
+		dep, err := r.depositoryRepo.GetUserDepository(httpReq.Context(), depID, responder.XUserID)
 		if err != nil {
 			responder.Log("microDeposits", err)
 			moovhttp.Problem(w, err)
@@ -186,40 +198,149 @@ func (r *DepositoryRouter) initiateMicroDeposits() http.HandlerFunc {
 			moovhttp.Problem(w, err)
 			return
 		}
-		if r.microDepositAttemper != nil {
-			if !r.microDepositAttemper.Available(dep.ID) {
-				moovhttp.Problem(w, errors.New("no micro-deposit attempts available"))
-				return
-			}
-		}
-
-		// Our Depository needs to be Verified so let's submit some micro deposits to it.
-		amounts := microDepositAmounts()
-		microDeposits, err := r.submitMicroDeposits(responder.XUserID, responder.XRequestID, amounts, dep)
-		if err != nil {
-			err = fmt.Errorf("problem submitting micro-deposits: %v", err)
+		if err := r.requireMicroDepositVerification(responder.XUserID, dep); err != nil {
 			responder.Log("microDeposits", err)
 			moovhttp.Problem(w, err)
 			return
 		}
-		responder.Log("microDeposits", fmt.Sprintf("submitted %d micro-deposits for depository=%s", len(microDeposits), dep.ID))
-
-		// Write micro deposits into our db
-		if err := r.depositoryRepo.InitiateMicroDeposits(depID, responder.XUserID, microDeposits); err != nil {
-			responder.Log("microDeposits", err)
+		if err := r.sendMicroDeposits(httpReq.Context(), responder, dep, false); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		}
-		responder.Log("microDeposits", fmt.Sprintf("stored micro-deposits for depository=%s", dep.ID))
 
-		microDepositsInitiated.With("destination", dep.RoutingNumber).Add(1)
+		w.WriteHeader(http.StatusCreated) // 201 - Micro deposits initiated
+		w.Write([]byte("{}"))
+	}
+}
+
+// resendMicroDeposits voids a Depository's unconfirmed micro-deposits and sends new ones. This is how users
+// can retry after their micro-deposits expire and each resend counts as another micro-deposit attempt.
+func (r *DepositoryRouter) resendMicroDeposits() http.HandlerFunc {
+	return func(w http.ResponseWriter, httpReq *http.Request) {
+		responder := route.NewResponder(r.logger, w, httpReq)
+		if responder == nil {
+			return
+		}
+
+		depID := GetDepositoryID(httpReq)
+		if depID == "" {
+			// 404 - A depository with the specified ID was not found.
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "depository not found"}`))
+			return
+		}
+
+		dep, err := r.depositoryRepo.GetUserDepository(httpReq.Context(), depID, responder.XUserID)
+		if err != nil {
+			responder.Log("resendMicroDeposits", err)
+			responder.Problem(err)
+			return
+		}
+		if dep == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		dep.keeper = r.keeper
+		if dep.Status != DepositoryUnverified {
+			err = fmt.Errorf("depository %s in bogus status %s", dep.ID, dep.Status)
+			responder.Log("resendMicroDeposits", err)
+			responder.Problem(err)
+			return
+		}
+		if err := r.requireMicroDepositVerification(responder.XUserID, dep); err != nil {
+			responder.Log("resendMicroDeposits", err)
+			responder.Problem(err)
+			return
+		}
+
+		if err := r.sendMicroDeposits(httpReq.Context(), responder, dep, true); err != nil {
+			responder.Problem(err)
+			return
+		}
 
 		w.WriteHeader(http.StatusCreated) // 201 - Micro deposits initiated
 		w.Write([]byte("{}"))
 	}
 }
 
-func postMicroDepositTransaction(logger log.Logger, client AccountsClient, accountID string, userID id.User, lines []transactionLine, requestID string) (*accounts.Transaction, error) {
+// requireMicroDepositVerification rejects the micro-deposit routes for Depositories which the
+// verificationPolicy verifies with another VerificationStrategy.
+func (r *DepositoryRouter) requireMicroDepositVerification(userID id.User, dep *Depository) error {
+	if strategy := r.verificationPolicy.strategy(userID, dep); strategy != MicroDepositVerification {
+		return fmt.Errorf("depository %s is verified with %s, not micro-deposits", dep.ID, strategy)
+	}
+	return nil
+}
+
+// sendMicroDeposits submits new micro-deposits to an unverified Depository and stores them for confirmation.
+// When replace is true any existing micro-deposits are voided first, otherwise they cause an error.
+func (r *DepositoryRouter) sendMicroDeposits(ctx context.Context, responder *route.Responder, dep *Depository, replace bool) error {
+	if r.microDepositAttemper != nil {
+		if !r.microDepositAttemper.Available(dep.ID) {
+			return errors.New("no micro-deposit attempts available")
+		}
+	}
+
+	// Void the previous micro-deposits so they can't be confirmed and reverse their Accounts transactions.
+	// If they haven't been merged yet their ACH file (including the withdraw) is never uploaded.
+	if replace {
+		if err := r.voidMicroDeposits(ctx, responder, dep); err != nil {
+			responder.Log("microDeposits", fmt.Sprintf("problem voiding micro-deposits: %v", err))
+			return err
+		}
+		responder.Log("microDeposits", fmt.Sprintf("voided previous micro-deposits for depository=%s", dep.ID))
+	}
+
+	// Our Depository needs to be Verified so let's submit some micro deposits to it.
+	amounts := microDepositAmounts()
+	microDeposits, err := r.submitMicroDeposits(ctx, responder.XUserID, responder.XRequestID, amounts, dep)
+	if err != nil {
+		err = fmt.Errorf("problem submitting micro-deposits: %v", err)
+		responder.Log("microDeposits", err)
+		return err
+	}
+	responder.Log("microDeposits", fmt.Sprintf("submitted %d micro-deposits for depository=%s", len(microDeposits), dep.ID))
+
+	// Write micro deposits into our db
+	if err := r.depositoryRepo.InitiateMicroDeposits(ctx, dep.ID, responder.XUserID, microDeposits); err != nil {
+		responder.Log("microDeposits", err)
+		return err
+	}
+	responder.Log("microDeposits", fmt.Sprintf("stored micro-deposits for depository=%s", dep.ID))
+
+	microDepositsInitiated.With("destination", dep.RoutingNumber).Add(1)
+	return nil
+}
+
+// voidMicroDeposits reverses and removes each of a Depository's micro-deposits the same way expired ones are.
+func (r *DepositoryRouter) voidMicroDeposits(ctx context.Context, responder *route.Responder, dep *Depository) error {
+	microDeposits, err := r.depositoryRepo.getVoidableMicroDeposits(ctx, dep.ID, responder.XUserID)
+	if err != nil {
+		return err
+	}
+	withdrawsReversed := make(map[string]bool)
+	for i := range microDeposits {
+		if _, err := voidMicroDeposit(ctx, r.accountsClient, r.depositoryRepo, responder.XRequestID, microDeposits[i], withdrawsReversed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// microDepositsExpired returns true if micro-deposits were initiated longer ago than the router's expiration.
+func (r *DepositoryRouter) microDepositsExpired(microDeposits []*MicroDeposit) bool {
+	if r.microDepositExpiration <= 0 {
+		return false
+	}
+	for i := range microDeposits {
+		if microDeposits[i].CreatedAt.Add(r.microDepositExpiration).Before(time.Now()) {
+			return true
+		}
+	}
+	return false
+}
+
+func postMicroDepositTransaction(ctx context.Context, logger log.Logger, client AccountsClient, accountID string, userID id.User, lines []transactionLine, requestID string) (*accounts.Transaction, error) {
 	if client == nil {
 		return nil, errors.New("nil Accounts client")
 	}
@@ -227,7 +348,7 @@ func postMicroDepositTransaction(logger log.Logger, client AccountsClient, accou
 	var transaction *accounts.Transaction
 	var err error
 	for i := 0; i < 3; i++ {
-		transaction, err = client.PostTransaction(requestID, userID, lines)
+		transaction, err = client.PostTransaction(ctx, requestID, userID, lines)
 		if err == nil {
 			break // quit after successful call
 		}
@@ -239,18 +360,18 @@ func postMicroDepositTransaction(logger log.Logger, client AccountsClient, accou
 	return transaction, nil
 }
 
-func updateMicroDepositsWithTransactionIDs(logger log.Logger, ODFIAccount *ODFIAccount, client AccountsClient, userID id.User, dep *Depository, microDeposits []*MicroDeposit, sum int, requestID string) ([]*accounts.Transaction, error) {
+func updateMicroDepositsWithTransactionIDs(ctx context.Context, logger log.Logger, ODFIAccount *ODFIAccount, client AccountsClient, userID id.User, dep *Depository, microDeposits []*MicroDeposit, sum int, requestID string) ([]*accounts.Transaction, error) {
 	if client == nil {
 		return nil, errors.New("nil Accounts client")
 	}
 	if len(microDeposits) != 2 {
 		return nil, fmt.Errorf("updateMicroDepositsWithTransactionIDs: got %d micro-deposits", len(microDeposits))
 	}
-	acct, err := client.SearchAccounts(requestID, userID, dep)
+	acct, err := client.SearchAccounts(ctx, requestID, userID, dep)
 	if err != nil || acct == nil {
 		return nil, fmt.Errorf("error reading account user=%s depository=%s: %v", userID, dep.ID, err)
 	}
-	ODFIAccountID, err := ODFIAccount.getID(requestID, userID)
+	ODFIAccountID, err := ODFIAccount.getID(ctx, requestID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("posting micro-deposits: %v", err)
 	}
@@ -262,7 +383,7 @@ func updateMicroDepositsWithTransactionIDs(logger log.Logger, ODFIAccount *ODFIA
 			{AccountID: acct.ID, Purpose: "ACHCredit", Amount: int32(microDeposits[i].Amount.Int())},
 			{AccountID: ODFIAccountID, Purpose: "ACHDebit", Amount: int32(microDeposits[i].Amount.Int())},
 		}
-		tx, err := postMicroDepositTransaction(logger, client, acct.ID, userID, lines, requestID)
+		tx, err := postMicroDepositTransaction(ctx, logger, client, acct.ID, userID, lines, requestID)
 		if err != nil {
 			return nil, err // we retried and failed, so just exit early
 		}
@@ -274,10 +395,13 @@ func updateMicroDepositsWithTransactionIDs(logger log.Logger, ODFIAccount *ODFIA
 		{AccountID: acct.ID, Purpose: "ACHDebit", Amount: int32(sum)},
 		{AccountID: ODFIAccountID, Purpose: "ACHCredit", Amount: int32(sum)},
 	}
-	tx, err := postMicroDepositTransaction(logger, client, acct.ID, userID, lines, requestID)
+	tx, err := postMicroDepositTransaction(ctx, logger, client, acct.ID, userID, lines, requestID)
 	if err != nil {
 		return nil, fmt.Errorf("postMicroDepositTransaction: on sum transaction post: %v", err)
 	}
+	for i := range microDeposits {
+		microDeposits[i].WithdrawTransactionID = tx.ID
+	}
 	transactions = append(transactions, tx)
 	return transactions, nil
 }
@@ -299,7 +423,7 @@ func stringifyAmounts(amounts []Amount) string {
 // - Write micro-deposits to SQL table (used in /confirm endpoint)
 //
 // submitMicroDeposits assumes there are 2 amounts to credit and a third to debit.
-func (r *DepositoryRouter) submitMicroDeposits(userID id.User, requestID string, amounts []Amount, dep *Depository) ([]*MicroDeposit, error) {
+func (r *DepositoryRouter) submitMicroDeposits(ctx context.Context, userID id.User, requestID string, amounts []Amount, dep *Depository) ([]*MicroDeposit, error) {
 	odfiOriginator, odfiDepository := r.odfiAccount.metadata()
 	if odfiOriginator == nil || odfiDepository == nil {
 		return nil, errors.New("unable to find ODFI originator or depository")
@@ -320,6 +444,12 @@ func (r *DepositoryRouter) submitMicroDeposits(userID id.User, requestID string,
 		return nil, fmt.Errorf("error with withdrawAmount: %v", err)
 	}
 
+	// Reserve a trace number for each micro-deposit and the withdraw, which are incremented as entries are added.
+	traceNumber, err := r.traceNumbers.reserve(ctx, odfiDepository.RoutingNumber, len(amounts)+1)
+	if err != nil {
+		return nil, fmt.Errorf("problem reserving micro-deposit trace numbers: %v", err)
+	}
+
 	idempotencyKey := base.ID()
 	rec := &Receiver{
 		ID:       ReceiverID(fmt.Sprintf("%s-micro-deposit-verify", base.ID())),
@@ -345,6 +475,7 @@ func (r *DepositoryRouter) submitMicroDeposits(userID id.User, requestID string,
 
 		if file == nil {
 			xfer := req.asTransfer(string(rec.ID))
+			xfer.TraceNumber = traceNumber
 			f, err := constructACHFile(string(rec.ID), idempotencyKey, userID, xfer, rec, dep, odfiOriginator, odfiDepository)
 			if err != nil {
 				err = fmt.Errorf("problem constructing ACH file for userID=%s: %v", userID, err)
@@ -377,19 +508,19 @@ func (r *DepositoryRouter) submitMicroDeposits(userID id.User, requestID string,
 		microDeposits = append(microDeposits, &MicroDeposit{Amount: amounts[i]})
 
 		// Store the Transfer creation as an event
-		if err := writeTransferEvent(userID, req, r.eventRepo); err != nil {
+		if err := writeTransferEvent(ctx, userID, req, r.eventRepo); err != nil {
 			return nil, fmt.Errorf("userID=%s problem writing micro-deposit transfer event: %v", userID, err)
 		}
 	}
 
 	// Submit the ACH file against moov's ACH service after adding every micro-deposit
-	fileID, err := r.achClient.CreateFile(idempotencyKey, file)
+	fileID, err := r.achClient.CreateFile(ctx, idempotencyKey, file)
 	if err != nil {
 		err = fmt.Errorf("problem creating ACH file for userID=%s: %v", userID, err)
 		r.logger.Log("microDeposits", err, "requestID", requestID, "userID", userID)
 		return nil, err
 	}
-	if err := checkACHFile(r.logger, r.achClient, fileID, userID); err != nil {
+	if err := checkACHFile(ctx, r.logger, r.achClient, fileID, userID); err != nil {
 		return nil, err
 	}
 	r.logger.Log("microDeposits", fmt.Sprintf("created ACH file=%s for depository=%s", fileID, dep.ID), "requestID", requestID, "userID", userID)
@@ -400,7 +531,7 @@ func (r *DepositoryRouter) submitMicroDeposits(userID id.User, requestID string,
 
 	// Post the transaction against Accounts only if it's enabled (flagged via nil AccountsClient)
 	if r.accountsClient != nil {
-		transactions, err := updateMicroDepositsWithTransactionIDs(r.logger, r.odfiAccount, r.accountsClient, userID, dep, microDeposits, withdrawAmount.Int(), requestID)
+		transactions, err := updateMicroDepositsWithTransactionIDs(ctx, r.logger, r.odfiAccount, r.accountsClient, userID, dep, microDeposits, withdrawAmount.Int(), requestID)
 		if err != nil {
 			return microDeposits, fmt.Errorf("submitMicroDeposits: error posting to Accounts: %v", err)
 		}
@@ -418,9 +549,9 @@ func addMicroDeposit(file *ach.File, amt Amount) error {
 	ed := *file.Batches[0].GetEntries()[0] // copy previous EntryDetail
 	ed.ID = base.ID()[:8]
 
-	// increment trace number
+	// increment trace number, micro-deposits reserve one for each entry
 	if n, _ := strconv.Atoi(ed.TraceNumber); n > 0 {
-		ed.TraceNumber = strconv.Itoa(n + 1)
+		ed.TraceNumber = fmt.Sprintf("%015d", n+1) // keep the leading zeros of the ODFI's routing number
 	}
 
 	// use our calculated amount to withdraw all micro-deposits
@@ -452,9 +583,9 @@ func addMicroDepositWithdraw(file *ach.File, withdrawAmount *Amount) error {
 	//  27 CheckingDebit -> 22 CheckingCredit
 	ed.TransactionCode -= 5
 
-	// increment trace number
+	// increment trace number, micro-deposits reserve one for each entry
 	if n, _ := strconv.Atoi(ed.TraceNumber); n > 0 {
-		ed.TraceNumber = strconv.Itoa(n + 1)
+		ed.TraceNumber = fmt.Sprintf("%015d", n+1) // keep the leading zeros of the ODFI's routing number
 	}
 
 	// use our calculated amount to withdraw all micro-deposits
@@ -492,23 +623,26 @@ func (r *DepositoryRouter) confirmMicroDeposits() http.HandlerFunc {
 		}
 
 		// Check the depository status and confirm it belongs to the user
-		dep, err := r.depositoryRepo.GetUserDepository(depID, responder.XUserID)
+		dep, err := r.depositoryRepo.GetUserDepository(httpReq.Context(), depID, responder.XUserID)
 		if err != nil {
 			responder.Log("confirmMicroDeposits", err)
 			responder.Problem(err)
 			return
 		}
+		if dep == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 		if dep.Status != DepositoryUnverified {
 			err = fmt.Errorf("depository %s in bogus status %s", dep.ID, dep.Status)
 			responder.Log("confirmMicroDeposits", err)
 			responder.Problem(err)
 			return
 		}
-		if r.microDepositAttemper != nil {
-			if !r.microDepositAttemper.Available(dep.ID) {
-				responder.Problem(errors.New("no micro-deposit attempts available"))
-				return
-			}
+		if err := r.requireMicroDepositVerification(responder.XUserID, dep); err != nil {
+			responder.Log("confirmMicroDeposits", err)
+			responder.Problem(err)
+			return
 		}
 
 		// Read amounts from request JSON
@@ -519,52 +653,72 @@ func (r *DepositoryRouter) confirmMicroDeposits() http.HandlerFunc {
 			responder.Problem(err)
 			return
 		}
-
-		var amounts []Amount
-		for i := range req.Amounts {
-			amt := &Amount{}
-			if err := amt.FromString(req.Amounts[i]); err != nil {
-				continue
-			}
-			amounts = append(amounts, *amt)
-		}
-		if len(amounts) == 0 {
-			responder.Log("confirmMicroDeposits", "no micro-deposit amounts found")
-			// 400 - Invalid Amounts
-			responder.Problem(errors.New("invalid amounts, found none"))
-			return
-		}
-		if err := r.depositoryRepo.confirmMicroDeposits(depID, responder.XUserID, amounts); err != nil {
-			responder.Log("confirmMicroDeposits", fmt.Sprintf("problem confirming micro-deposits: %v", err))
+		if err := r.confirmDepositoryMicroDeposits(httpReq.Context(), responder, dep, req.Amounts); err != nil {
 			responder.Problem(err)
 			return
 		}
 
-		// Update Depository status
-		if err := markDepositoryVerified(r.depositoryRepo, depID, responder.XUserID); err != nil {
-			responder.Log("confirmMicroDeposits", fmt.Sprintf("problem marking depository as Verified: %v", err))
-			return
-		}
-
-		microDepositsConfirmed.With("destination", dep.RoutingNumber).Add(1)
-
 		// 200 - Micro deposits verified
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("{}"))
 	}
 }
 
+// confirmDepositoryMicroDeposits checks the amounts a user guessed against a Depository's micro-deposits
+// and marks the Depository as Verified when they match.
+func (r *DepositoryRouter) confirmDepositoryMicroDeposits(ctx context.Context, responder *route.Responder, dep *Depository, guesses []string) error {
+	if r.microDepositAttemper != nil {
+		if !r.microDepositAttemper.Available(dep.ID) {
+			return errors.New("no micro-deposit attempts available")
+		}
+	}
+	if microDeposits, err := r.depositoryRepo.getMicroDepositsForUser(ctx, dep.ID, responder.XUserID); err != nil {
+		responder.Log("confirmMicroDeposits", fmt.Sprintf("problem reading micro-deposits: %v", err))
+		return err
+	} else if r.microDepositsExpired(microDeposits) {
+		responder.Log("confirmMicroDeposits", fmt.Sprintf("micro-deposits expired for depository=%s", dep.ID))
+		return errors.New("micro-deposits have expired, resend them to try again")
+	}
+
+	var amounts []Amount
+	for i := range guesses {
+		amt := &Amount{}
+		if err := amt.FromString(guesses[i]); err != nil {
+			continue
+		}
+		amounts = append(amounts, *amt)
+	}
+	if len(amounts) == 0 {
+		responder.Log("confirmMicroDeposits", "no micro-deposit amounts found")
+		// 400 - Invalid Amounts
+		return errors.New("invalid amounts, found none")
+	}
+	if err := r.depositoryRepo.confirmMicroDeposits(ctx, dep.ID, responder.XUserID, amounts); err != nil {
+		responder.Log("confirmMicroDeposits", fmt.Sprintf("problem confirming micro-deposits: %v", err))
+		return err
+	}
+
+	// Update Depository status
+	if err := markDepositoryVerified(ctx, r.depositoryRepo, dep.ID, responder.XUserID); err != nil {
+		responder.Log("confirmMicroDeposits", fmt.Sprintf("problem marking depository as Verified: %v", err))
+		return err
+	}
+
+	microDepositsConfirmed.With("destination", dep.RoutingNumber).Add(1)
+	return nil
+}
+
 // GetMicroDeposits will retrieve the micro deposits for a given depository. This endpoint is designed for paygate's admin endpoints.
 // If an amount does not parse it will be discardded silently.
-func (r *SQLDepositoryRepo) GetMicroDeposits(id id.Depository) ([]*MicroDeposit, error) {
-	query := `select amount, file_id, transaction_id from micro_deposits where depository_id = ?`
-	stmt, err := r.db.Prepare(query)
+func (r *SQLDepositoryRepo) GetMicroDeposits(ctx context.Context, id id.Depository) ([]*MicroDeposit, error) {
+	query := `select amount, file_id, transaction_id, created_at from micro_deposits where depository_id = ?`
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(id)
+	rows, err := stmt.QueryContext(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -574,15 +728,15 @@ func (r *SQLDepositoryRepo) GetMicroDeposits(id id.Depository) ([]*MicroDeposit,
 }
 
 // getMicroDepositsForUser will retrieve the micro deposits for a given depository. If an amount does not parse it will be discardded silently.
-func (r *SQLDepositoryRepo) getMicroDepositsForUser(id id.Depository, userID id.User) ([]*MicroDeposit, error) {
-	query := `select amount, file_id, transaction_id from micro_deposits where user_id = ? and depository_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+func (r *SQLDepositoryRepo) getMicroDepositsForUser(ctx context.Context, id id.Depository, userID id.User) ([]*MicroDeposit, error) {
+	query := `select amount, file_id, transaction_id, created_at from micro_deposits where user_id = ? and depository_id = ? and deleted_at is null`
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(userID, id)
+	rows, err := stmt.QueryContext(ctx, userID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -596,7 +750,8 @@ func accumulateMicroDeposits(rows *sql.Rows) ([]*MicroDeposit, error) {
 	for rows.Next() {
 		fileID, transactionID := "", ""
 		var value string
-		if err := rows.Scan(&value, &fileID, &transactionID); err != nil {
+		var createdAt time.Time
+		if err := rows.Scan(&value, &fileID, &transactionID, &createdAt); err != nil {
 			continue
 		}
 
@@ -608,6 +763,7 @@ func accumulateMicroDeposits(rows *sql.Rows) ([]*MicroDeposit, error) {
 			Amount:        *amt,
 			FileID:        fileID,
 			TransactionID: transactionID,
+			CreatedAt:     createdAt,
 		})
 	}
 	return microDeposits, rows.Err()
@@ -615,27 +771,32 @@ func accumulateMicroDeposits(rows *sql.Rows) ([]*MicroDeposit, error) {
 
 // InitiateMicroDeposits will save the provided []Amount into our database. If amounts have already been saved then
 // no new amounts will be added.
-func (r *SQLDepositoryRepo) InitiateMicroDeposits(id id.Depository, userID id.User, microDeposits []*MicroDeposit) error {
-	existing, err := r.getMicroDepositsForUser(id, userID)
+func (r *SQLDepositoryRepo) InitiateMicroDeposits(ctx context.Context, id id.Depository, userID id.User, microDeposits []*MicroDeposit) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.InitiateMicroDeposits", "depositoryID", id)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	existing, err := r.getMicroDepositsForUser(ctx, id, userID)
 	if err != nil || len(existing) > 0 {
 		return fmt.Errorf("not initializing more micro deposits, already have %d or got error=%v", len(existing), err)
 	}
 
 	// write amounts
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	now, query := time.Now(), `insert into micro_deposits (depository_id, user_id, amount, file_id, transaction_id, created_at) values (?, ?, ?, ?, ?, ?)`
-	stmt, err := tx.Prepare(query)
+	now, query := time.Now(), `insert into micro_deposits (micro_deposit_id, depository_id, user_id, amount, file_id, transaction_id, withdraw_transaction_id, created_at) values (?, ?, ?, ?, ?, ?, ?, ?)`
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("InitiateMicroDeposits: prepare error=%v rollback=%v", err, tx.Rollback())
 	}
 	defer stmt.Close()
 
 	for i := range microDeposits {
-		_, err = stmt.Exec(id, userID, microDeposits[i].Amount.String(), microDeposits[i].FileID, microDeposits[i].TransactionID, now)
+		_, err = stmt.ExecContext(ctx, base.ID(), id, userID, microDeposits[i].Amount.String(), microDeposits[i].FileID, microDeposits[i].TransactionID, microDeposits[i].WithdrawTransactionID, now)
 		if err != nil {
 			return fmt.Errorf("InitiateMicroDeposits: scan error=%v rollback=%v", err, tx.Rollback())
 		}
@@ -646,8 +807,8 @@ func (r *SQLDepositoryRepo) InitiateMicroDeposits(id id.Depository, userID id.Us
 
 // confirmMicroDeposits will compare the provided guessAmounts against what's been persisted for a user. If the amounts do not match
 // or there are a mismatched amount the call will return a non-nil error.
-func (r *SQLDepositoryRepo) confirmMicroDeposits(id id.Depository, userID id.User, guessAmounts []Amount) error {
-	microDeposits, err := r.getMicroDepositsForUser(id, userID)
+func (r *SQLDepositoryRepo) confirmMicroDeposits(ctx context.Context, id id.Depository, userID id.User, guessAmounts []Amount) error {
+	microDeposits, err := r.getMicroDepositsForUser(ctx, id, userID)
 	if err != nil {
 		return fmt.Errorf("unable to confirm micro deposits, got error=%v", err)
 	}
@@ -711,15 +872,15 @@ type UploadableMicroDeposit struct {
 
 // Next returns a slice of micro-deposit objects from the current day. Next should be called to process
 // all objects for a given day in batches.
-func (cur *MicroDepositCursor) Next() ([]UploadableMicroDeposit, error) {
+func (cur *MicroDepositCursor) Next(ctx context.Context) ([]UploadableMicroDeposit, error) {
 	query := `select depository_id, user_id, amount, file_id, created_at from micro_deposits where deleted_at is null and merged_filename is null and created_at > ? order by created_at asc limit ?`
-	stmt, err := cur.DepRepo.db.Prepare(query)
+	stmt, err := cur.DepRepo.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("microDepositCursor.Next: prepare: %v", err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(cur.newerThan, cur.BatchSize)
+	rows, err := stmt.QueryContext(ctx, cur.newerThan, cur.BatchSize)
 	if err != nil {
 		return nil, fmt.Errorf("microDepositCursor.Next: query: %v", err)
 	}
@@ -749,29 +910,34 @@ func (cur *MicroDepositCursor) Next() ([]UploadableMicroDeposit, error) {
 
 // MarkMicroDepositAsMerged will set the merged_filename on micro-deposits so they aren't merged into multiple files
 // and the file uploaded to the Federal Reserve can be tracked.
-func (r *SQLDepositoryRepo) MarkMicroDepositAsMerged(filename string, mc UploadableMicroDeposit) error {
+func (r *SQLDepositoryRepo) MarkMicroDepositAsMerged(ctx context.Context, filename string, mc UploadableMicroDeposit) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.MarkMicroDepositAsMerged", "depositoryID", mc.DepositoryID, "filename", filename)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update micro_deposits set merged_filename = ?
 where depository_id = ? and file_id = ? and amount = ? and (merged_filename is null or merged_filename = '') and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("MarkMicroDepositAsMerged: filename=%s: %v", filename, err)
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(filename, mc.DepositoryID, mc.FileID, mc.Amount.String())
+	_, err = stmt.ExecContext(ctx, filename, mc.DepositoryID, mc.FileID, mc.Amount.String())
 	return err
 }
 
-func (r *SQLDepositoryRepo) LookupMicroDepositFromReturn(id id.Depository, amount *Amount) (*MicroDeposit, error) {
+func (r *SQLDepositoryRepo) LookupMicroDepositFromReturn(ctx context.Context, id id.Depository, amount *Amount) (*MicroDeposit, error) {
 	query := `select file_id from micro_deposits where depository_id = ? and amount = ? and deleted_at is null order by created_at desc limit 1;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("LookupMicroDepositFromReturn prepare: %v", err)
 	}
 	defer stmt.Close()
 
 	var fileID string
-	if err := stmt.QueryRow(id, amount.String()).Scan(&fileID); err != nil {
+	if err := stmt.QueryRowContext(ctx, id, amount.String()).Scan(&fileID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -784,29 +950,34 @@ func (r *SQLDepositoryRepo) LookupMicroDepositFromReturn(id id.Depository, amoun
 }
 
 // SetReturnCode will write the given returnCode (e.g. "R14") onto the row for one of a Depository's micro-deposit
-func (r *SQLDepositoryRepo) SetReturnCode(id id.Depository, amount Amount, returnCode string) error {
+func (r *SQLDepositoryRepo) SetReturnCode(ctx context.Context, id id.Depository, amount Amount, returnCode string) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.SetReturnCode", "depositoryID", id, "returnCode", returnCode)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update micro_deposits set return_code = ? where depository_id = ? and amount = ? and return_code = '' and deleted_at is null;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(returnCode, id, amount.String())
+	_, err = stmt.ExecContext(ctx, returnCode, id, amount.String())
 	return err
 }
 
-func (r *SQLDepositoryRepo) getMicroDepositReturnCodes(id id.Depository) []*ach.ReturnCode {
+func (r *SQLDepositoryRepo) getMicroDepositReturnCodes(ctx context.Context, id id.Depository) []*ach.ReturnCode {
 	query := `select distinct md.return_code from micro_deposits as md
 inner join depositories as deps on md.depository_id = deps.depository_id
 where md.depository_id = ? and deps.status = ? and md.return_code <> '' and md.deleted_at is null and deps.deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(id, DepositoryRejected)
+	rows, err := stmt.QueryContext(ctx, id, DepositoryRejected)
 	if err != nil {
 		return nil
 	}
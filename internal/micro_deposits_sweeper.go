@@ -0,0 +1,335 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/events"
+	"github.com/moov-io/paygate/internal/tracing"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	microDepositsExpired = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: "micro_deposits_expired",
+		Help: "Counter of unconfirmed micro-deposits voided after expiring",
+	}, nil)
+
+	unverifiedDepositories = prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Name: "micro_deposits_stuck_depositories",
+		Help: "Gauge of depositories which have been unverified longer than allowed",
+	}, nil)
+)
+
+// MicroDepositSweeper periodically voids expired micro-deposits and escalates Depositories
+// which have been unverified for too long.
+type MicroDepositSweeper struct {
+	logger log.Logger
+
+	depRepo        *SQLDepositoryRepo
+	eventRepo      events.Repository
+	accountsClient AccountsClient
+
+	expiration    time.Duration
+	escalateAfter time.Duration
+
+	ctx      context.Context
+	shutdown context.CancelFunc
+}
+
+// NewMicroDepositSweeper returns a MicroDepositSweeper which reverses the Accounts transactions of expired
+// micro-deposits with accountsClient, which is nil when Accounts is disabled.
+func NewMicroDepositSweeper(cfg *config.Config, depRepo *SQLDepositoryRepo, eventRepo events.Repository, accountsClient AccountsClient) *MicroDepositSweeper {
+	ctx, shutdown := context.WithCancel(context.Background())
+	return &MicroDepositSweeper{
+		logger:         cfg.Logger,
+		depRepo:        depRepo,
+		eventRepo:      eventRepo,
+		accountsClient: accountsClient,
+		expiration:     cfg.MicroDeposits.Expiration,
+		escalateAfter:  cfg.MicroDeposits.EscalateAfter,
+		ctx:            ctx,
+		shutdown:       shutdown,
+	}
+}
+
+func (s *MicroDepositSweeper) Close() {
+	if s == nil {
+		return
+	}
+	s.shutdown()
+}
+
+func (s *MicroDepositSweeper) Start(interval time.Duration) error {
+	if s == nil || s.depRepo == nil {
+		return errors.New("nil MicroDepositSweeper or Depository repository")
+	}
+
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	s.logger.Log("microDeposits", fmt.Sprintf("checking for expired micro-deposits every %v", interval))
+
+	for {
+		select {
+		case <-tick.C:
+			if err := s.sweep(s.ctx, time.Now()); err != nil {
+				s.logger.Log("microDeposits", err.Error())
+			}
+
+		case <-s.ctx.Done():
+			s.logger.Log("microDeposits", "MicroDepositSweeper: shutdown")
+			return nil
+		}
+	}
+}
+
+func (s *MicroDepositSweeper) sweep(ctx context.Context, now time.Time) error {
+	if s.expiration > 0 {
+		n, err := s.voidExpired(ctx, now.Add(-1*s.expiration))
+		if err != nil {
+			return fmt.Errorf("problem voiding expired micro-deposits: %v", err)
+		}
+		if n > 0 {
+			s.logger.Log("microDeposits", fmt.Sprintf("voided %d expired micro-deposits", n))
+			microDepositsExpired.Add(float64(n))
+		}
+	}
+	if s.escalateAfter > 0 {
+		stuck, err := s.depRepo.getStuckDepositories(now.Add(-1 * s.escalateAfter))
+		if err != nil {
+			return fmt.Errorf("problem reading unverified depositories: %v", err)
+		}
+		unverifiedDepositories.Set(float64(len(stuck)))
+
+		for i := range stuck {
+			if stuck[i].escalated {
+				continue
+			}
+			if err := s.escalate(ctx, stuck[i], now); err != nil {
+				s.logger.Log("microDeposits", fmt.Sprintf("problem escalating depository=%s: %v", stuck[i].id, err), "userID", stuck[i].userID)
+			}
+		}
+	}
+	return nil
+}
+
+// voidExpired reverses the Accounts transactions of micro-deposits created before olderThan for Depositories
+// which are still unverified and then voids them. Micro-deposits whose transactions couldn't be reversed are
+// left for the next sweep.
+func (s *MicroDepositSweeper) voidExpired(ctx context.Context, olderThan time.Time) (int64, error) {
+	expired, err := s.depRepo.getExpiredMicroDeposits(ctx, olderThan)
+	if err != nil {
+		return 0, err
+	}
+
+	var voided int64
+	requestID := base.ID()
+	withdrawsReversed := make(map[string]bool)
+	for _, md := range expired {
+		n, err := voidMicroDeposit(ctx, s.accountsClient, s.depRepo, requestID, md, withdrawsReversed)
+		if err != nil {
+			s.logger.Log("microDeposits", fmt.Sprintf("problem voiding micro-deposit of depository=%s: %v", md.depositoryID, err), "requestID", requestID, "userID", md.userID)
+			continue
+		}
+		voided += n
+	}
+	return voided, nil
+}
+
+// voidMicroDeposit reverses the Accounts transactions of a micro-deposit, unless accountsClient is nil, and then
+// removes it. The micro-deposit is kept if its transactions can't be reversed.
+//
+// The withdraw transaction is shared by each of a Depository's micro-deposits, so withdrawsReversed tracks the
+// ones already reversed and once reversed it's cleared from all of them.
+func voidMicroDeposit(ctx context.Context, accountsClient AccountsClient, repo DepositoryRepository, requestID string, md *voidableMicroDeposit, withdrawsReversed map[string]bool) (int64, error) {
+	if accountsClient != nil {
+		if md.withdrawTransactionID != "" && !withdrawsReversed[md.withdrawTransactionID] {
+			if err := accountsClient.ReverseTransaction(ctx, requestID, md.userID, md.withdrawTransactionID); err != nil {
+				return 0, fmt.Errorf("problem reversing withdraw transaction=%s: %v", md.withdrawTransactionID, err)
+			}
+			withdrawsReversed[md.withdrawTransactionID] = true
+			if err := repo.clearWithdrawTransaction(ctx, md); err != nil {
+				return 0, err
+			}
+		}
+		if md.transactionID != "" {
+			if err := accountsClient.ReverseTransaction(ctx, requestID, md.userID, md.transactionID); err != nil {
+				return 0, fmt.Errorf("problem reversing micro-deposit transaction=%s: %v", md.transactionID, err)
+			}
+		}
+	}
+	return repo.voidMicroDeposit(ctx, md)
+}
+
+// escalate reports a Depository stuck in unverified status to its user with an event. Each
+// Depository is only escalated once.
+func (s *MicroDepositSweeper) escalate(ctx context.Context, dep *stuckDepository, now time.Time) error {
+	s.logger.Log("microDeposits", fmt.Sprintf("depository=%s has been unverified since %v", dep.id, dep.created.Format(time.RFC3339)), "userID", dep.userID)
+
+	err := s.eventRepo.WriteEvent(ctx, dep.userID, &events.Event{
+		ID:      events.EventID(base.ID()),
+		Topic:   fmt.Sprintf("depository %s is unverified", dep.id),
+		Message: fmt.Sprintf("Depository %s has been unverified since %s, resend and confirm its micro-deposits.", dep.id, dep.created.Format("2006-01-02")),
+		Type:    events.DepositoryEvent,
+		Metadata: map[string]string{
+			"depository": string(dep.id),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return s.depRepo.markDepositoryEscalated(dep.id, now)
+}
+
+type stuckDepository struct {
+	id        id.Depository
+	userID    id.User
+	created   time.Time
+	escalated bool
+}
+
+type voidableMicroDeposit struct {
+	id                    string
+	depositoryID          id.Depository
+	userID                id.User
+	transactionID         string
+	withdrawTransactionID string
+}
+
+// getExpiredMicroDeposits returns micro-deposits created before olderThan for Depositories which are still unverified.
+func (r *SQLDepositoryRepo) getExpiredMicroDeposits(ctx context.Context, olderThan time.Time) ([]*voidableMicroDeposit, error) {
+	query := `select micro_deposit_id, depository_id, user_id, transaction_id, withdraw_transaction_id from micro_deposits
+where deleted_at is null and created_at < ?
+and depository_id in (select depository_id from depositories where status = ? and deleted_at is null)
+order by created_at asc`
+	return r.queryVoidableMicroDeposits(ctx, query, olderThan, DepositoryUnverified)
+}
+
+// getVoidableMicroDeposits returns a Depository's micro-deposits which haven't been voided.
+func (r *SQLDepositoryRepo) getVoidableMicroDeposits(ctx context.Context, id id.Depository, userID id.User) ([]*voidableMicroDeposit, error) {
+	query := `select micro_deposit_id, depository_id, user_id, transaction_id, withdraw_transaction_id from micro_deposits
+where user_id = ? and depository_id = ? and deleted_at is null order by created_at asc`
+	return r.queryVoidableMicroDeposits(ctx, query, userID, id)
+}
+
+func (r *SQLDepositoryRepo) queryVoidableMicroDeposits(ctx context.Context, query string, args ...interface{}) ([]*voidableMicroDeposit, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*voidableMicroDeposit
+	for rows.Next() {
+		var md voidableMicroDeposit
+		var transactionID, withdrawTransactionID *string
+		if err := rows.Scan(&md.id, &md.depositoryID, &md.userID, &transactionID, &withdrawTransactionID); err != nil {
+			return nil, err
+		}
+		if transactionID != nil {
+			md.transactionID = *transactionID
+		}
+		if withdrawTransactionID != nil {
+			md.withdrawTransactionID = *withdrawTransactionID
+		}
+		out = append(out, &md)
+	}
+	return out, rows.Err()
+}
+
+// voidMicroDeposit removes a single micro-deposit.
+func (r *SQLDepositoryRepo) voidMicroDeposit(ctx context.Context, md *voidableMicroDeposit) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.voidMicroDeposit", "depositoryID", md.depositoryID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `update micro_deposits set deleted_at = ? where micro_deposit_id = ? and deleted_at is null`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, time.Now(), md.id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *SQLDepositoryRepo) clearWithdrawTransaction(ctx context.Context, md *voidableMicroDeposit) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.clearWithdrawTransaction", "depositoryID", md.depositoryID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `update micro_deposits set withdraw_transaction_id = '' where depository_id = ? and withdraw_transaction_id = ?`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, md.depositoryID, md.withdrawTransactionID)
+	return err
+}
+
+// getStuckDepositories returns Depositories created before olderThan which are still unverified.
+func (r *SQLDepositoryRepo) getStuckDepositories(olderThan time.Time) ([]*stuckDepository, error) {
+	query := `select depository_id, user_id, created_at, escalated_at from depositories
+where status = ? and created_at < ? and deleted_at is null order by created_at asc`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(DepositoryUnverified, olderThan)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*stuckDepository
+	for rows.Next() {
+		var dep stuckDepository
+		var escalatedAt *time.Time
+		if err := rows.Scan(&dep.id, &dep.userID, &dep.created, &escalatedAt); err != nil {
+			return nil, err
+		}
+		dep.escalated = escalatedAt != nil
+		out = append(out, &dep)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLDepositoryRepo) markDepositoryEscalated(id id.Depository, when time.Time) error {
+	query := `update depositories set escalated_at = ? where depository_id = ? and deleted_at is null`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(when, id)
+	return err
+}
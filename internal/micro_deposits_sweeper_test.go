@@ -0,0 +1,179 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/events"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestMicroDepositSweeper(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLDepositoryRepo) {
+		userID := id.User(base.ID())
+		created := time.Now().Add(-72 * time.Hour)
+
+		// write an unverified and verified Depository with micro-deposits
+		unverified := &Depository{ID: id.Depository(base.ID()), Status: DepositoryUnverified, Created: base.NewTime(created), keeper: repo.keeper}
+		verified := &Depository{ID: id.Depository(base.ID()), Status: DepositoryVerified, Created: base.NewTime(created), keeper: repo.keeper}
+		amt, _ := NewAmount("USD", "0.11")
+		for _, dep := range []*Depository{unverified, verified} {
+			if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+				t.Fatal(err)
+			}
+			microDeposits := []*MicroDeposit{
+				{Amount: *amt, TransactionID: base.ID(), WithdrawTransactionID: "withdraw-" + dep.ID.String()},
+				{Amount: *amt, TransactionID: base.ID(), WithdrawTransactionID: "withdraw-" + dep.ID.String()},
+			}
+			if err := repo.InitiateMicroDeposits(context.Background(), dep.ID, userID, microDeposits); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		eventRepo := events.NewRepo(log.NewNopLogger(), repo.db)
+		cfg := config.Empty()
+		cfg.MicroDeposits.Expiration = time.Hour
+		cfg.MicroDeposits.EscalateAfter = 48 * time.Hour
+		accountsClient := &testAccountsClient{}
+		sweeper := NewMicroDepositSweeper(cfg, repo, eventRepo, accountsClient)
+		defer sweeper.Close()
+
+		// nothing has expired yet
+		if err := sweeper.sweep(context.Background(), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		mds, _ := repo.getMicroDepositsForUser(context.Background(), unverified.ID, userID)
+		if len(mds) != 2 {
+			t.Fatalf("got %d micro-deposits", len(mds))
+		}
+
+		// micro-deposits aren't voided while their transactions can't be reversed
+		accountsClient.err = errors.New("bad error")
+		if err := sweeper.sweep(context.Background(), time.Now().Add(2*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if mds, _ := repo.getMicroDepositsForUser(context.Background(), unverified.ID, userID); len(mds) != 2 {
+			t.Errorf("got %d micro-deposits", len(mds))
+		}
+		accountsClient.err = nil
+
+		// only the unverified Depository's micro-deposits are voided
+		if err := sweeper.sweep(context.Background(), time.Now().Add(2*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if mds, _ := repo.getMicroDepositsForUser(context.Background(), unverified.ID, userID); len(mds) != 0 {
+			t.Errorf("got %d micro-deposits", len(mds))
+		}
+		if mds, _ := repo.getMicroDepositsForUser(context.Background(), verified.ID, userID); len(mds) != 2 {
+			t.Errorf("got %d micro-deposits", len(mds))
+		}
+
+		// the withdraw is reversed once along with each micro-deposit's credit
+		reversed := accountsClient.reversedTransactions
+		if len(reversed) != 3 || reversed[0] != "withdraw-"+unverified.ID.String() {
+			t.Fatalf("reversed transactions: %v", reversed)
+		}
+		for i := range mds {
+			if mds[i].TransactionID != reversed[1] && mds[i].TransactionID != reversed[2] {
+				t.Errorf("micro-deposit transaction=%s wasn't reversed: %v", mds[i].TransactionID, reversed)
+			}
+		}
+
+		// the unverified Depository was escalated once
+		stuck, err := repo.getStuckDepositories(time.Now())
+		if err != nil || len(stuck) != 1 {
+			t.Fatalf("got %d stuck depositories: %v", len(stuck), err)
+		}
+		if stuck[0].id != unverified.ID || !stuck[0].escalated {
+			t.Errorf("unexpected stuck depository: %#v", stuck[0])
+		}
+		evts, err := eventRepo.GetUserEvents(context.Background(), userID)
+		if err != nil || len(evts) != 1 {
+			t.Fatalf("got %d events: %v", len(evts), err)
+		}
+		if evts[0].Type != events.DepositoryEvent {
+			t.Errorf("unexpected event: %#v", evts[0])
+		}
+
+		if err := sweeper.sweep(context.Background(), time.Now().Add(4*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if evts, _ := eventRepo.GetUserEvents(context.Background(), userID); len(evts) != 1 {
+			t.Errorf("escalated again, got %d events", len(evts))
+		}
+	}
+
+	keeper := secrets.TestStringKeeper(t)
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), mysqlDB.DB, keeper))
+}
+
+func TestMicroDepositSweeper__sameAmounts(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	repo := NewDepositoryRepo(log.NewNopLogger(), db.DB, secrets.TestStringKeeper(t))
+	userID := id.User(base.ID())
+	dep := &Depository{ID: id.Depository(base.ID()), Status: DepositoryUnverified, Created: base.NewTime(time.Now()), keeper: repo.keeper}
+	if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+		t.Fatal(err)
+	}
+
+	// micro-deposits without transactions (Accounts is disabled) and the same amount
+	amt, _ := NewAmount("USD", "0.11")
+	if err := repo.InitiateMicroDeposits(context.Background(), dep.ID, userID, []*MicroDeposit{{Amount: *amt}, {Amount: *amt}}); err != nil {
+		t.Fatal(err)
+	}
+	mds, err := repo.getVoidableMicroDeposits(context.Background(), dep.ID, userID)
+	if err != nil || len(mds) != 2 || mds[0].id == "" || mds[0].id == mds[1].id {
+		t.Fatalf("unexpected micro-deposits: %#v error=%v", mds, err)
+	}
+
+	// only the selected micro-deposit is voided
+	if n, err := voidMicroDeposit(context.Background(), nil, repo, base.ID(), mds[0], make(map[string]bool)); err != nil || n != 1 {
+		t.Fatalf("voided %d micro-deposits: %v", n, err)
+	}
+	remaining, _ := repo.getVoidableMicroDeposits(context.Background(), dep.ID, userID)
+	if len(remaining) != 1 || remaining[0].id != mds[1].id {
+		t.Errorf("unexpected micro-deposits: %#v", remaining)
+	}
+}
+
+func TestMicroDepositSweeper__disabled(t *testing.T) {
+	cfg := config.Empty()
+	sweeper := NewMicroDepositSweeper(cfg, nil, nil, nil)
+	if err := sweeper.Start(time.Second); err == nil {
+		t.Error("expected error")
+	}
+	if err := sweeper.sweep(context.Background(), time.Now()); err != nil {
+		t.Error(err)
+	}
+	sweeper.Close()
+}
@@ -6,10 +6,12 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -20,6 +22,8 @@ import (
 	accounts "github.com/moov-io/accounts/client"
 	"github.com/moov-io/ach"
 	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
+	"github.com/moov-io/paygate/internal/config"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/internal/events"
 	"github.com/moov-io/paygate/internal/fed"
@@ -33,8 +37,11 @@ import (
 
 func makeTestODFIAccount() *ODFIAccount {
 	return &ODFIAccount{
-		routingNumber: "121042882", // set as ODFIIdentification in PPD batches (used in tests)
-		accountID:     "odfi-account",
+		routingNumber:  "121042882", // set as ODFIIdentification in PPD batches (used in tests)
+		identification: "001",
+		bankName:       "Moov, Inc",
+		holder:         "Moov, Inc",
+		accountID:      "odfi-account",
 	}
 }
 
@@ -63,7 +70,7 @@ func TestODFIAccount(t *testing.T) {
 		t.Errorf("depository: %#v", dep)
 	}
 
-	if accountID, err := odfi.getID("", "userID"); accountID != "accountID" || err != nil {
+	if accountID, err := odfi.getID(context.Background(), "", "userID"); accountID != "accountID" || err != nil {
 		t.Errorf("accountID=%s error=%v", accountID, err)
 	}
 	odfi.accountID = "" // unset so we make the AccountsClient call
@@ -72,7 +79,7 @@ func TestODFIAccount(t *testing.T) {
 			ID: "accountID2",
 		},
 	}
-	if accountID, err := odfi.getID("", "userID"); accountID != "accountID2" || err != nil {
+	if accountID, err := odfi.getID(context.Background(), "", "userID"); accountID != "accountID2" || err != nil {
 		t.Errorf("accountID=%s error=%v", accountID, err)
 	}
 	if odfi.accountID != "accountID2" {
@@ -82,17 +89,40 @@ func TestODFIAccount(t *testing.T) {
 	// error on AccountsClient call
 	odfi.accountID = ""
 	accountsClient.err = errors.New("bad")
-	if accountID, err := odfi.getID("", "userID"); accountID != "" || err == nil {
+	if accountID, err := odfi.getID(context.Background(), "", "userID"); accountID != "" || err == nil {
 		t.Errorf("expected error accountID=%s", accountID)
 	}
 
 	// on nil AccountsClient expect an error
 	odfi.client = nil
-	if accountID, err := odfi.getID("", "userID"); accountID != "" || err == nil {
+	if accountID, err := odfi.getID(context.Background(), "", "userID"); accountID != "" || err == nil {
 		t.Errorf("expcted error accountID=%s", accountID)
 	}
 }
 
+func TestODFIAccount__config(t *testing.T) {
+	cfg := &config.ODFIConfig{
+		RoutingNumber:  "121042882",
+		AccountNumber:  "4321",
+		AccountType:    "Checking",
+		Identification: "002",
+		BankName:       "Bank",
+		Holder:         "Holder",
+	}
+	odfi := NewODFIAccount(nil, cfg, secrets.TestStringKeeper(t))
+
+	orig, dep := odfi.metadata()
+	if orig == nil || dep == nil {
+		t.Fatalf("\norig=%#v\ndep=%#v", orig, dep)
+	}
+	if orig.Identification != "002" {
+		t.Errorf("originator: %#v", orig)
+	}
+	if dep.Type != Checking || dep.RoutingNumber != "121042882" || dep.BankName != "Bank" || dep.Holder != "Holder" {
+		t.Errorf("depository: %#v", dep)
+	}
+}
+
 func TestMicroDeposits__json(t *testing.T) {
 	amt, _ := NewAmount("USD", "1.24")
 	bs, err := json.Marshal([]MicroDeposit{
@@ -227,10 +257,13 @@ func TestMicroDeposits__confirmMicroDeposits(t *testing.T) {
 
 	sqlite := database.CreateTestSqliteDB(t)
 	defer sqlite.Close()
+	postgres := database.CreateTestPostgresDB(t)
+	defer postgres.Close()
 	mysql := database.CreateTestMySQLDB(t)
 	defer mysql.Close()
 	databases := []*SQLDepositoryRepo{
 		NewDepositoryRepo(log.NewNopLogger(), sqlite.DB, keeper),
+		NewDepositoryRepo(log.NewNopLogger(), postgres.DB, keeper),
 		NewDepositoryRepo(log.NewNopLogger(), mysql.DB, keeper),
 	}
 
@@ -240,11 +273,11 @@ func TestMicroDeposits__confirmMicroDeposits(t *testing.T) {
 				depositoryID := id.Depository(base.ID())
 				userID := id.User(base.ID())
 
-				if err := db.InitiateMicroDeposits(depositoryID, userID, tc.state.microDeposits); err != nil {
+				if err := db.InitiateMicroDeposits(context.Background(), depositoryID, userID, tc.state.microDeposits); err != nil {
 					t.Fatal(err)
 				}
 
-				err := db.confirmMicroDeposits(depositoryID, userID, tc.state.guesses)
+				err := db.confirmMicroDeposits(context.Background(), depositoryID, userID, tc.state.guesses)
 				if tc.expectedErrMessage == "" {
 					if err != nil {
 						t.Errorf("nil was the expected result, got '%s' instead", err)
@@ -276,11 +309,11 @@ func TestMicroDeposits__insertMicroDepositVerify(t *testing.T) {
 		}
 		mcs := []*MicroDeposit{mc}
 
-		if err := repo.InitiateMicroDeposits(id, userID, mcs); err != nil {
+		if err := repo.InitiateMicroDeposits(context.Background(), id, userID, mcs); err != nil {
 			t.Fatal(err)
 		}
 
-		microDeposits, err := repo.getMicroDepositsForUser(id, userID)
+		microDeposits, err := repo.getMicroDepositsForUser(context.Background(), id, userID)
 		if n := len(microDeposits); err != nil || n == 0 {
 			t.Fatalf("n=%d error=%v", n, err)
 		}
@@ -299,6 +332,11 @@ func TestMicroDeposits__insertMicroDepositVerify(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -316,7 +354,7 @@ func TestMicroDeposits__initiateError(t *testing.T) {
 		depositoryRepo:       depRepo,
 		microDepositAttemper: NewAttemper(log.NewNopLogger(), db.DB, 5),
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	w := httptest.NewRecorder()
@@ -354,7 +392,7 @@ func TestMicroDeposits__initiateNoAttemptsLeft(t *testing.T) {
 		depositoryRepo:       depRepo,
 		microDepositAttemper: &testAttempter{err: errors.New("bad error")},
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	w := httptest.NewRecorder()
@@ -368,6 +406,41 @@ func TestMicroDeposits__initiateNoAttemptsLeft(t *testing.T) {
 	}
 }
 
+func TestMicroDeposits__verificationPolicy(t *testing.T) {
+	userID := base.ID()
+	dep := &Depository{
+		ID:                     id.Depository(base.ID()),
+		BankName:               "bank name",
+		Holder:                 "holder",
+		HolderType:             Individual,
+		Type:                   Checking,
+		RoutingNumber:          "121042882",
+		EncryptedAccountNumber: "151",
+		Status:                 DepositoryUnverified,
+	}
+	router := &DepositoryRouter{
+		logger:               log.NewNopLogger(),
+		depositoryRepo:       &MockDepositoryRepository{Depositories: []*Depository{dep}},
+		microDepositAttemper: &testAttempter{available: true},
+		verificationPolicy:   newVerificationPolicy(&config.VerificationConfig{Default: "prenote"}),
+	}
+	r := auth.NewTestRouter()
+	router.RegisterRoutes(r)
+
+	// Depositories verified with another strategy can't use micro-deposits
+	for _, path := range []string{"", "/confirm", "/resend"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", fmt.Sprintf("/depositories/%s/micro-deposits%s", dep.ID, path), strings.NewReader(`{"amounts": ["USD 0.11"]}`))
+		req.Header.Set("x-user-id", userID)
+		r.ServeHTTP(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "verified with prenote") {
+			t.Errorf("%s: bogus HTTP status %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
 func TestMicroDeposits__confirmError(t *testing.T) {
 	id, userID := id.Depository(base.ID()), base.ID()
 	depRepo := &MockDepositoryRepository{Err: errors.New("bad error")}
@@ -375,7 +448,7 @@ func TestMicroDeposits__confirmError(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		depositoryRepo: depRepo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	var buf bytes.Buffer
@@ -420,7 +493,7 @@ func TestMicroDeposits__confirmAttempts(t *testing.T) {
 			available: false,
 		},
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	var buf bytes.Buffer
@@ -490,7 +563,7 @@ func TestMicroDeposits__routes(t *testing.T) {
 			Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
 			keeper:                 keeper,
 		}
-		if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+		if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Fatal(err)
 		}
 
@@ -520,10 +593,11 @@ func TestMicroDeposits__routes(t *testing.T) {
 			fedClient:            fedClient,
 			depositoryRepo:       depRepo,
 			eventRepo:            eventRepo,
+			traceNumbers:         NewTraceNumberRepo(log.NewNopLogger(), db),
 			microDepositAttemper: NewAttemper(log.NewNopLogger(), db, 5),
 			keeper:               keeper,
 		}
-		r := mux.NewRouter()
+		r := auth.NewTestRouter()
 		router.RegisterRoutes(r)
 
 		// Set ACH_ENDPOINT to override the achclient.New call
@@ -542,6 +616,15 @@ func TestMicroDeposits__routes(t *testing.T) {
 			}
 		}
 
+		// both micro-deposits store the withdraw transaction so it can be reversed
+		var withdraws int
+		if err := db.QueryRow(`select count(*) from micro_deposits where depository_id = ? and withdraw_transaction_id = ?`, id, accountsClient.transaction.ID).Scan(&withdraws); err != nil {
+			t.Fatal(err)
+		}
+		if withdraws != 2 {
+			t.Errorf("got %d micro-deposits with the withdraw transaction", withdraws)
+		}
+
 		// confirm our deposits
 		var buf bytes.Buffer
 		var request confirmDepositoryRequest
@@ -580,12 +663,145 @@ func TestMicroDeposits__routes(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, sqliteDB.DB, keeper)
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB, keeper)
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
 	check(t, mysqlDB.DB, keeper)
 }
 
+func TestMicroDeposits__resend(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	keeper := secrets.TestStringKeeper(t)
+	depID, userID := id.Depository(base.ID()), id.User(base.ID())
+
+	depRepo := NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper)
+	num, _ := keeper.EncryptString("151")
+	dep := &Depository{
+		ID:                     depID,
+		BankName:               "bank name",
+		Holder:                 "holder",
+		HolderType:             Individual,
+		Type:                   Checking,
+		RoutingNumber:          "121042882",
+		EncryptedAccountNumber: num,
+		Status:                 DepositoryUnverified,
+		Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
+		keeper:                 keeper,
+	}
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+		t.Fatal(err)
+	}
+
+	accountsClient := &testAccountsClient{
+		accounts:    []accounts.Account{{ID: base.ID()}},
+		transaction: &accounts.Transaction{ID: base.ID()},
+	}
+	achClient, _, server := achclient.MockClientServer("micro-deposits", func(r *mux.Router) {
+		achclient.AddCreateRoute(nil, r)
+		achclient.AddValidateRoute(r)
+	})
+	defer server.Close()
+
+	testODFIAccount := makeTestODFIAccount()
+	testODFIAccount.keeper = keeper
+
+	router := &DepositoryRouter{
+		logger:               log.NewNopLogger(),
+		odfiAccount:          testODFIAccount,
+		accountsClient:       accountsClient,
+		achClient:            achClient,
+		fedClient:            &fed.TestClient{},
+		depositoryRepo:       depRepo,
+		eventRepo:            &events.TestRepository{},
+		traceNumbers:         NewTraceNumberRepo(log.NewNopLogger(), db.DB),
+		microDepositAttemper: NewAttemper(log.NewNopLogger(), db.DB, 2),
+		keeper:               keeper,
+	}
+	r := auth.NewTestRouter()
+	router.RegisterRoutes(r)
+
+	post := func(path string, body io.Reader) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", fmt.Sprintf("/depositories/%s/micro-deposits%s", depID, path), body)
+		req.Header.Set("x-user-id", userID.String())
+		r.ServeHTTP(w, req)
+		w.Flush()
+		return w
+	}
+
+	if w := post("", nil); w.Code != http.StatusCreated {
+		t.Fatalf("initiate got %d status: %v", w.Code, w.Body.String())
+	}
+	initial, err := depRepo.getMicroDepositsForUser(context.Background(), depID, userID)
+	if err != nil || len(initial) != 2 {
+		t.Fatalf("got %d micro-deposits: %v", len(initial), err)
+	}
+
+	// expired micro-deposits can't be confirmed
+	router.microDepositExpiration = time.Nanosecond
+	body := strings.NewReader(fmt.Sprintf(`{"amounts": ["%s", "%s"]}`, initial[0].Amount.String(), initial[1].Amount.String()))
+	if w := post("/confirm", body); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "expired") {
+		t.Errorf("confirm got %d status: %v", w.Code, w.Body.String())
+	}
+	router.microDepositExpiration = time.Hour
+
+	// micro-deposits are kept when their transactions can't be reversed
+	accountsClient.err = errors.New("bad error")
+	if w := post("/resend", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("resend got %d status: %v", w.Code, w.Body.String())
+	}
+	if mds, _ := depRepo.getMicroDepositsForUser(context.Background(), depID, userID); len(mds) != 2 {
+		t.Errorf("got %d micro-deposits", len(mds))
+	}
+	accountsClient.err = nil
+
+	// resend replaces our micro-deposits
+	if w := post("/resend", nil); w.Code != http.StatusCreated {
+		t.Fatalf("resend got %d status: %v", w.Code, w.Body.String())
+	}
+	resent, err := depRepo.getMicroDepositsForUser(context.Background(), depID, userID)
+	if err != nil || len(resent) != 2 {
+		t.Fatalf("got %d micro-deposits: %v", len(resent), err)
+	}
+	if all, _ := depRepo.GetMicroDeposits(context.Background(), depID); len(all) != 4 {
+		t.Errorf("expected voided micro-deposits to be kept, got %d", len(all))
+	}
+
+	// the withdraw and each credit of the voided micro-deposits were reversed
+	if n := len(accountsClient.reversedTransactions); n != 3 {
+		t.Errorf("reversed %d transactions: %v", n, accountsClient.reversedTransactions)
+	}
+
+	// our attempts are used up
+	if w := post("/resend", nil); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "no micro-deposit attempts available") {
+		t.Errorf("resend got %d status: %v", w.Code, w.Body.String())
+	}
+}
+
+func TestMicroDeposits__microDepositsExpired(t *testing.T) {
+	router := &DepositoryRouter{}
+	microDeposits := []*MicroDeposit{{CreatedAt: time.Now().Add(-48 * time.Hour)}}
+	if router.microDepositsExpired(microDeposits) {
+		t.Error("micro-deposits never expire without an expiration")
+	}
+
+	router.microDepositExpiration = 24 * time.Hour
+	if !router.microDepositsExpired(microDeposits) {
+		t.Error("expected expired micro-deposits")
+	}
+	microDeposits[0].CreatedAt = time.Now()
+	if router.microDepositsExpired(microDeposits) {
+		t.Error("micro-deposits aren't expired")
+	}
+}
+
 func TestMicroDeposits__MarkMicroDepositAsMerged(t *testing.T) {
 	t.Parallel()
 
@@ -594,7 +810,7 @@ func TestMicroDeposits__MarkMicroDepositAsMerged(t *testing.T) {
 		microDeposits := []*MicroDeposit{
 			{Amount: *amt, FileID: "fileID"},
 		}
-		if err := repo.InitiateMicroDeposits(id.Depository("id"), "userID", microDeposits); err != nil {
+		if err := repo.InitiateMicroDeposits(context.Background(), id.Depository("id"), "userID", microDeposits); err != nil {
 			t.Fatal(err)
 		}
 
@@ -604,7 +820,7 @@ func TestMicroDeposits__MarkMicroDepositAsMerged(t *testing.T) {
 			Amount:       amt,
 			FileID:       "fileID",
 		}
-		if err := repo.MarkMicroDepositAsMerged("filename", mc); err != nil {
+		if err := repo.MarkMicroDepositAsMerged(context.Background(), "filename", mc); err != nil {
 			t.Fatal(err)
 		}
 
@@ -625,6 +841,11 @@ func TestMicroDeposits__MarkMicroDepositAsMerged(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -640,18 +861,18 @@ func TestMicroDepositCursor__next(t *testing.T) {
 	depRepo := NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
 	cur := depRepo.GetMicroDepositCursor(2)
 
-	microDeposits, err := cur.Next()
+	microDeposits, err := cur.Next(context.Background())
 	if len(microDeposits) != 0 || err != nil {
 		t.Fatalf("microDeposits=%#v error=%v", microDeposits, err)
 	}
 
 	// Write a micro-deposit
 	amt, _ := NewAmount("USD", "0.11")
-	if err := depRepo.InitiateMicroDeposits(id.Depository("id"), "userID", []*MicroDeposit{{Amount: *amt, FileID: "fileID"}}); err != nil {
+	if err := depRepo.InitiateMicroDeposits(context.Background(), id.Depository("id"), "userID", []*MicroDeposit{{Amount: *amt, FileID: "fileID"}}); err != nil {
 		t.Fatal(err)
 	}
 	// our cursor should return this micro-deposit now since there's no mergedFilename
-	microDeposits, err = cur.Next()
+	microDeposits, err = cur.Next(context.Background())
 	if len(microDeposits) != 1 || err != nil {
 		t.Fatalf("microDeposits=%#v error=%v", microDeposits, err)
 	}
@@ -661,17 +882,17 @@ func TestMicroDepositCursor__next(t *testing.T) {
 	mc := microDeposits[0] // save for later
 
 	// verify calling our cursor again returns nothing
-	microDeposits, err = cur.Next()
+	microDeposits, err = cur.Next(context.Background())
 	if len(microDeposits) != 0 || err != nil {
 		t.Fatalf("microDeposits=%#v error=%v", microDeposits, err)
 	}
 
 	// mark the micro-deposit as merged (via merged_filename) and re-create the cursor to expect nothing returned in Next()
 	cur = depRepo.GetMicroDepositCursor(2)
-	if err := depRepo.MarkMicroDepositAsMerged("filename", mc); err != nil {
+	if err := depRepo.MarkMicroDepositAsMerged(context.Background(), "filename", mc); err != nil {
 		t.Fatal(err)
 	}
-	microDeposits, err = cur.Next()
+	microDeposits, err = cur.Next(context.Background())
 	if len(microDeposits) != 0 || err != nil {
 		t.Fatalf("microDeposits=%#v error=%v", microDeposits, err)
 	}
@@ -691,7 +912,7 @@ func TestMicroDeposits__addMicroDeposit(t *testing.T) {
 
 	ed := ach.NewEntryDetail()
 	ed.TransactionCode = ach.CheckingCredit
-	ed.TraceNumber = "123"
+	ed.TraceNumber = "076401250000001"
 	ed.Amount = 12 // $0.12
 
 	bh := ach.NewBatchHeader()
@@ -716,6 +937,9 @@ func TestMicroDeposits__addMicroDeposit(t *testing.T) {
 	if ed.Amount != amt.Int() {
 		t.Errorf("got ed.Amount=%d", ed.Amount)
 	}
+	if ed.TraceNumber != "076401250000002" {
+		t.Errorf("got ed.TraceNumber=%s", ed.TraceNumber)
+	}
 
 	// bad path
 	if err := addMicroDeposit(nil, *amt); err == nil {
@@ -726,7 +950,7 @@ func TestMicroDeposits__addMicroDeposit(t *testing.T) {
 func TestMicroDeposits__addMicroDepositWithdraw(t *testing.T) {
 	ed := ach.NewEntryDetail()
 	ed.TransactionCode = ach.CheckingCredit
-	ed.TraceNumber = "123"
+	ed.TraceNumber = "076401250000001"
 	ed.Amount = 12 // $0.12
 
 	bh := ach.NewBatchHeader()
@@ -772,7 +996,7 @@ func TestMicroDeposits__addMicroDepositWithdraw(t *testing.T) {
 	if entries[1].Amount != 14 {
 		t.Errorf("entries[1].Amount=%d", entries[1].Amount)
 	}
-	if entries[1].TraceNumber != "124" {
+	if entries[1].TraceNumber != "076401250000002" {
 		t.Errorf("entries[1].TraceNumber=%s", entries[1].TraceNumber)
 	}
 }
@@ -792,11 +1016,12 @@ func TestMicroDeposits_submitMicroDeposits(t *testing.T) {
 	testODFIAccount.keeper = keeper
 
 	router := &DepositoryRouter{
-		logger:      log.NewNopLogger(),
-		achClient:   achClient,
-		eventRepo:   &events.TestRepository{},
-		odfiAccount: testODFIAccount,
-		keeper:      keeper,
+		logger:       log.NewNopLogger(),
+		achClient:    achClient,
+		eventRepo:    &events.TestRepository{},
+		traceNumbers: &mockTraceNumberRepository{},
+		odfiAccount:  testODFIAccount,
+		keeper:       keeper,
 	}
 	router.accountsClient = nil // explicitly disable Accounts calls for this test
 	userID, requestID := id.User(base.ID()), base.ID()
@@ -819,7 +1044,7 @@ func TestMicroDeposits_submitMicroDeposits(t *testing.T) {
 		keeper:                 keeper,
 	}
 
-	microDeposits, err := router.submitMicroDeposits(userID, requestID, amounts, dep)
+	microDeposits, err := router.submitMicroDeposits(context.Background(), userID, requestID, amounts, dep)
 	if n := len(microDeposits); n != 2 || err != nil {
 		t.Fatalf("n=%d error=%v", n, err)
 	}
@@ -848,7 +1073,7 @@ func TestMicroDeposits__submitNoAttemptsLeft(t *testing.T) {
 		EncryptedAccountNumber: "151",
 		Status:                 DepositoryUnverified,
 	}
-	microDeposits, err := router.submitMicroDeposits(userID, requestID, amounts, dep)
+	microDeposits, err := router.submitMicroDeposits(context.Background(), userID, requestID, amounts, dep)
 	if len(microDeposits) != 0 || err == nil {
 		t.Errorf("expected error: microDeposits=%#v", microDeposits)
 	}
@@ -865,16 +1090,16 @@ func TestMicroDeposits__LookupMicroDepositFromReturn(t *testing.T) {
 		depID1, depID2 := id.Depository(base.ID()), id.Depository(base.ID())
 
 		// initial lookups with no rows written
-		if md, err := repo.LookupMicroDepositFromReturn(depID1, amt1); md != nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID1, amt1); md != nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
-		if md, err := repo.LookupMicroDepositFromReturn(depID1, amt2); md != nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID1, amt2); md != nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
-		if md, err := repo.LookupMicroDepositFromReturn(depID2, amt1); md != nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID2, amt1); md != nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
-		if md, err := repo.LookupMicroDepositFromReturn(depID2, amt2); md != nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID2, amt2); md != nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
 
@@ -883,23 +1108,23 @@ func TestMicroDeposits__LookupMicroDepositFromReturn(t *testing.T) {
 			{Amount: *amt1, FileID: "fileID", TransactionID: "transactionID"},
 			{Amount: *amt2, FileID: "fileID2", TransactionID: "transactionID2"},
 		}
-		if err := repo.InitiateMicroDeposits(depID1, userID, microDeposits); err != nil {
+		if err := repo.InitiateMicroDeposits(context.Background(), depID1, userID, microDeposits); err != nil {
 			t.Fatal(err)
 		}
 
 		// lookups (matching cases)
-		if md, err := repo.LookupMicroDepositFromReturn(depID1, amt1); md == nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID1, amt1); md == nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
-		if md, err := repo.LookupMicroDepositFromReturn(depID1, amt2); md == nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID1, amt2); md == nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
 
 		// lookups (not matching cases)
-		if md, err := repo.LookupMicroDepositFromReturn(depID2, amt1); md != nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID2, amt1); md != nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
-		if md, err := repo.LookupMicroDepositFromReturn(depID2, amt2); md != nil || err != nil {
+		if md, err := repo.LookupMicroDepositFromReturn(context.Background(), depID2, amt2); md != nil || err != nil {
 			t.Errorf("micro-deposit=%#v error=%v", md, err)
 		}
 	}
@@ -911,6 +1136,11 @@ func TestMicroDeposits__LookupMicroDepositFromReturn(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -948,7 +1178,7 @@ func TestMicroDeposits__SetReturnCode(t *testing.T) {
 			ID:     depID,
 			Status: DepositoryRejected, // needs to be rejected for getMicroDepositReturnCodes
 		}
-		if err := repo.UpsertUserDepository(userID, dep); err != nil {
+		if err := repo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Fatal(err)
 		}
 
@@ -961,10 +1191,10 @@ func TestMicroDeposits__SetReturnCode(t *testing.T) {
 		microDeposits := []*MicroDeposit{
 			{Amount: *amt, FileID: "fileID", TransactionID: "transactionID"},
 		}
-		if err := repo.InitiateMicroDeposits(depID, userID, microDeposits); err != nil {
+		if err := repo.InitiateMicroDeposits(context.Background(), depID, userID, microDeposits); err != nil {
 			t.Fatal(err)
 		}
-		if err := repo.SetReturnCode(depID, *amt, "R14"); err != nil {
+		if err := repo.SetReturnCode(context.Background(), depID, *amt, "R14"); err != nil {
 			t.Fatal(err)
 		}
 
@@ -973,7 +1203,7 @@ func TestMicroDeposits__SetReturnCode(t *testing.T) {
 			t.Errorf("code=%s", code)
 		}
 
-		xs, err := repo.getMicroDepositsForUser(depID, userID)
+		xs, err := repo.getMicroDepositsForUser(context.Background(), depID, userID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -982,7 +1212,7 @@ func TestMicroDeposits__SetReturnCode(t *testing.T) {
 		}
 
 		// lookup with our SQLDepositoryRepo method
-		codes := repo.getMicroDepositReturnCodes(depID)
+		codes := repo.getMicroDepositReturnCodes(context.Background(), depID)
 		if len(codes) != 1 {
 			t.Fatalf("got %d codes", len(codes))
 		}
@@ -998,6 +1228,11 @@ func TestMicroDeposits__SetReturnCode(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -1010,7 +1245,7 @@ func TestMicroDepositsHTTP__initiateNoUserID(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		depositoryRepo: repo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	req := httptest.NewRequest("POST", "/depositories/foo/micro-deposits", nil)
@@ -1019,7 +1254,7 @@ func TestMicroDepositsHTTP__initiateNoUserID(t *testing.T) {
 	r.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -1030,7 +1265,7 @@ func TestMicroDepositsHTTP__confirmNoUserID(t *testing.T) {
 		logger:         log.NewNopLogger(),
 		depositoryRepo: repo,
 	}
-	r := mux.NewRouter()
+	r := auth.NewTestRouter()
 	router.RegisterRoutes(r)
 
 	req := httptest.NewRequest("POST", "/depositories/foo/micro-deposits/confirm", nil)
@@ -1039,7 +1274,7 @@ func TestMicroDepositsHTTP__confirmNoUserID(t *testing.T) {
 	r.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -44,7 +44,7 @@ func getMicroDeposits(logger log.Logger, depositoryRepo internal.DepositoryRepos
 			return
 		}
 
-		microDeposits, err := depositoryRepo.GetMicroDeposits(id)
+		microDeposits, err := depositoryRepo.GetMicroDeposits(r.Context(), id)
 		if err != nil {
 			logger.Log("microDeposits", fmt.Sprintf("admin: problem reading micro-deposits for depository=%s: %v", id, err), "requestID", requestID, "userID", userID)
 			moovhttp.Problem(w, err)
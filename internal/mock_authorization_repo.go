@@ -0,0 +1,69 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+type MockAuthorizationRepository struct {
+	Authorizations []*Authorization
+
+	Err error
+
+	// Updated fields
+	RevocationReason string
+}
+
+func (r *MockAuthorizationRepository) getUserAuthorizations(ctx context.Context, receiverID ReceiverID, userID id.User) ([]*Authorization, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	var out []*Authorization
+	for i := range r.Authorizations {
+		if r.Authorizations[i].Receiver == receiverID {
+			out = append(out, r.Authorizations[i])
+		}
+	}
+	return out, nil
+}
+
+func (r *MockAuthorizationRepository) getUserAuthorization(ctx context.Context, id AuthorizationID, userID id.User) (*Authorization, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	for i := range r.Authorizations {
+		if r.Authorizations[i].ID == id {
+			return r.Authorizations[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MockAuthorizationRepository) createUserAuthorization(ctx context.Context, userID id.User, auth *Authorization) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	r.Authorizations = append(r.Authorizations, auth)
+	return nil
+}
+
+func (r *MockAuthorizationRepository) RevokeAuthorization(ctx context.Context, id AuthorizationID, reason string) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	for i := range r.Authorizations {
+		if r.Authorizations[i].ID == id && r.Authorizations[i].Revoked == nil {
+			now := time.Now()
+			r.Authorizations[i].Revoked = &now
+			r.Authorizations[i].RevocationReason = reason
+		}
+	}
+	r.RevocationReason = reason
+	return nil
+}
@@ -5,24 +5,27 @@
 package internal
 
 import (
+	"context"
 	"github.com/moov-io/paygate/pkg/id"
 )
 
 type MockDepositoryRepository struct {
 	Depositories  []*Depository
 	MicroDeposits []*MicroDeposit
+	Prenotes      []*Prenote
 	Err           error
 
 	DepID string
 
-	Cur *MicroDepositCursor
+	Cur        *MicroDepositCursor
+	PrenoteCur *PrenoteCursor
 
 	// Updated fields
 	Status     DepositoryStatus
 	ReturnCode string
 }
 
-func (r *MockDepositoryRepository) GetDepository(id id.Depository) (*Depository, error) {
+func (r *MockDepositoryRepository) GetDepository(ctx context.Context, id id.Depository) (*Depository, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -32,14 +35,14 @@ func (r *MockDepositoryRepository) GetDepository(id id.Depository) (*Depository,
 	return nil, nil
 }
 
-func (r *MockDepositoryRepository) GetUserDepositories(userID id.User) ([]*Depository, error) {
+func (r *MockDepositoryRepository) GetUserDepositories(ctx context.Context, userID id.User) ([]*Depository, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
 	return r.Depositories, nil
 }
 
-func (r *MockDepositoryRepository) GetUserDepository(id id.Depository, userID id.User) (*Depository, error) {
+func (r *MockDepositoryRepository) GetUserDepository(ctx context.Context, id id.Depository, userID id.User) (*Depository, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -49,34 +52,34 @@ func (r *MockDepositoryRepository) GetUserDepository(id id.Depository, userID id
 	return nil, nil
 }
 
-func (r *MockDepositoryRepository) UpsertUserDepository(userID id.User, dep *Depository) error {
+func (r *MockDepositoryRepository) UpsertUserDepository(ctx context.Context, userID id.User, dep *Depository) error {
 	return r.Err
 }
 
-func (r *MockDepositoryRepository) UpdateDepositoryStatus(id id.Depository, status DepositoryStatus) error {
+func (r *MockDepositoryRepository) UpdateDepositoryStatus(ctx context.Context, id id.Depository, status DepositoryStatus) error {
 	r.Status = status
 	return r.Err
 }
 
-func (r *MockDepositoryRepository) deleteUserDepository(id id.Depository, userID id.User) error {
+func (r *MockDepositoryRepository) deleteUserDepository(ctx context.Context, id id.Depository, userID id.User) error {
 	return r.Err
 }
 
-func (r *MockDepositoryRepository) GetMicroDeposits(id id.Depository) ([]*MicroDeposit, error) {
+func (r *MockDepositoryRepository) GetMicroDeposits(ctx context.Context, id id.Depository) ([]*MicroDeposit, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
 	return r.MicroDeposits, nil
 }
 
-func (r *MockDepositoryRepository) getMicroDepositsForUser(id id.Depository, userID id.User) ([]*MicroDeposit, error) {
+func (r *MockDepositoryRepository) getMicroDepositsForUser(ctx context.Context, id id.Depository, userID id.User) ([]*MicroDeposit, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
 	return r.MicroDeposits, nil
 }
 
-func (r *MockDepositoryRepository) LookupDepositoryFromReturn(routingNumber string, accountNumber string) (*Depository, error) {
+func (r *MockDepositoryRepository) LookupDepositoryFromReturn(ctx context.Context, routingNumber string, accountNumber string) (*Depository, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -86,7 +89,7 @@ func (r *MockDepositoryRepository) LookupDepositoryFromReturn(routingNumber stri
 	return nil, nil
 }
 
-func (r *MockDepositoryRepository) LookupMicroDepositFromReturn(id id.Depository, amount *Amount) (*MicroDeposit, error) {
+func (r *MockDepositoryRepository) LookupMicroDepositFromReturn(ctx context.Context, id id.Depository, amount *Amount) (*MicroDeposit, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -96,19 +99,69 @@ func (r *MockDepositoryRepository) LookupMicroDepositFromReturn(id id.Depository
 	return nil, nil
 }
 
-func (r *MockDepositoryRepository) SetReturnCode(id id.Depository, amount Amount, returnCode string) error {
+func (r *MockDepositoryRepository) SetReturnCode(ctx context.Context, id id.Depository, amount Amount, returnCode string) error {
 	r.ReturnCode = returnCode
 	return r.Err
 }
 
-func (r *MockDepositoryRepository) InitiateMicroDeposits(id id.Depository, userID id.User, microDeposit []*MicroDeposit) error {
+func (r *MockDepositoryRepository) InitiateMicroDeposits(ctx context.Context, id id.Depository, userID id.User, microDeposit []*MicroDeposit) error {
 	return r.Err
 }
 
-func (r *MockDepositoryRepository) confirmMicroDeposits(id id.Depository, userID id.User, amounts []Amount) error {
+func (r *MockDepositoryRepository) getVoidableMicroDeposits(ctx context.Context, id id.Depository, userID id.User) ([]*voidableMicroDeposit, error) {
+	return nil, r.Err
+}
+
+func (r *MockDepositoryRepository) voidMicroDeposit(ctx context.Context, md *voidableMicroDeposit) (int64, error) {
+	return 0, r.Err
+}
+
+func (r *MockDepositoryRepository) clearWithdrawTransaction(ctx context.Context, md *voidableMicroDeposit) error {
+	return r.Err
+}
+
+func (r *MockDepositoryRepository) confirmMicroDeposits(ctx context.Context, id id.Depository, userID id.User, amounts []Amount) error {
 	return r.Err
 }
 
 func (r *MockDepositoryRepository) GetMicroDepositCursor(batchSize int) *MicroDepositCursor {
 	return r.Cur
 }
+
+func (r *MockDepositoryRepository) createPrenote(ctx context.Context, id id.Depository, userID id.User, prenote *Prenote) error {
+	if r.Err == nil {
+		r.Prenotes = append(r.Prenotes, prenote)
+	}
+	return r.Err
+}
+
+func (r *MockDepositoryRepository) getLatestPrenote(ctx context.Context, id id.Depository, userID id.User) (*Prenote, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	if len(r.Prenotes) > 0 {
+		return r.Prenotes[len(r.Prenotes)-1], nil
+	}
+	return nil, nil
+}
+
+func (r *MockDepositoryRepository) LookupPrenoteFromReturn(ctx context.Context, id id.Depository, traceNumber string) (*Prenote, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	for i := range r.Prenotes {
+		if r.Prenotes[i].TraceNumber == traceNumber {
+			return r.Prenotes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MockDepositoryRepository) SetPrenoteReturnCode(ctx context.Context, id id.Depository, traceNumber string, returnCode string) error {
+	r.ReturnCode = returnCode
+	return r.Err
+}
+
+func (r *MockDepositoryRepository) GetPrenoteCursor(batchSize int) *PrenoteCursor {
+	return r.PrenoteCur
+}
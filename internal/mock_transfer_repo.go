@@ -5,6 +5,7 @@
 package internal
 
 import (
+	"context"
 	"time"
 
 	"github.com/moov-io/base"
@@ -24,7 +25,7 @@ type MockTransferRepository struct {
 	Status     TransferStatus
 }
 
-func (r *MockTransferRepository) getUserTransfers(userID id.User) ([]*Transfer, error) {
+func (r *MockTransferRepository) getUserTransfers(ctx context.Context, userID id.User) ([]*Transfer, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -34,33 +35,33 @@ func (r *MockTransferRepository) getUserTransfers(userID id.User) ([]*Transfer,
 	return nil, nil
 }
 
-func (r *MockTransferRepository) getUserTransfer(id TransferID, userID id.User) (*Transfer, error) {
+func (r *MockTransferRepository) getUserTransfer(ctx context.Context, id TransferID, userID id.User) (*Transfer, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
 	return r.Xfer, nil
 }
 
-func (r *MockTransferRepository) UpdateTransferStatus(id TransferID, status TransferStatus) error {
+func (r *MockTransferRepository) UpdateTransferStatus(ctx context.Context, id TransferID, status TransferStatus) error {
 	r.Status = status
 	return r.Err
 }
 
-func (r *MockTransferRepository) GetFileIDForTransfer(id TransferID, userID id.User) (string, error) {
+func (r *MockTransferRepository) GetFileIDForTransfer(ctx context.Context, id TransferID, userID id.User) (string, error) {
 	if r.Err != nil {
 		return "", r.Err
 	}
 	return r.FileID, nil
 }
 
-func (r *MockTransferRepository) LookupTransferFromReturn(sec string, amount *Amount, traceNumber string, effectiveEntryDate time.Time) (*Transfer, error) {
+func (r *MockTransferRepository) LookupTransferFromReturn(ctx context.Context, sec string, amount *Amount, traceNumber string, effectiveEntryDate time.Time) (*Transfer, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
 	return r.Xfer, nil
 }
 
-func (r *MockTransferRepository) SetReturnCode(id TransferID, returnCode string) error {
+func (r *MockTransferRepository) SetReturnCode(ctx context.Context, id TransferID, returnCode string) error {
 	r.ReturnCode = returnCode
 	return r.Err
 }
@@ -69,11 +70,11 @@ func (r *MockTransferRepository) GetTransferCursor(batchSize int, depRepo Deposi
 	return r.Cur
 }
 
-func (r *MockTransferRepository) MarkTransferAsMerged(id TransferID, filename string, traceNumber string) error {
+func (r *MockTransferRepository) MarkTransferAsMerged(ctx context.Context, id TransferID, filename string, traceNumber string) error {
 	return r.Err
 }
 
-func (r *MockTransferRepository) createUserTransfers(userID id.User, requests []*transferRequest) ([]*Transfer, error) {
+func (r *MockTransferRepository) createUserTransfers(ctx context.Context, userID id.User, requests []*transferRequest) ([]*Transfer, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -84,6 +85,6 @@ func (r *MockTransferRepository) createUserTransfers(userID id.User, requests []
 	return transfers, nil
 }
 
-func (r *MockTransferRepository) deleteUserTransfer(id TransferID, userID id.User) error {
+func (r *MockTransferRepository) deleteUserTransfer(ctx context.Context, id TransferID, userID id.User) error {
 	return r.Err
 }
@@ -5,18 +5,25 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/moov-io/base"
 	moovhttp "github.com/moov-io/base/http"
+	moovcustomers "github.com/moov-io/customers/client"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/customers"
+	"github.com/moov-io/paygate/internal/events"
 	"github.com/moov-io/paygate/internal/kyc"
 	"github.com/moov-io/paygate/internal/route"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/internal/tracing"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
@@ -83,6 +90,16 @@ func (o *Originator) validate() error {
 	return nil
 }
 
+// masked returns a copy of the Originator with its Identification masked for HTTP responses.
+func (o *Originator) masked() *Originator {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Identification = maskIdentification(o.Identification)
+	return &out
+}
+
 type originatorRequest struct {
 	// DefaultDepository the depository account to be used by default per transaction.
 	DefaultDepository id.Depository `json:"defaultDepository"`
@@ -113,12 +130,13 @@ func (r originatorRequest) missingFields() error {
 	return nil
 }
 
-func AddOriginatorRoutes(logger log.Logger, r *mux.Router, accountsClient AccountsClient, customersClient customers.Client, depositoryRepo DepositoryRepository, originatorRepo originatorRepository) {
-	r.Methods("GET").Path("/originators").HandlerFunc(getUserOriginators(logger, originatorRepo))
-	r.Methods("POST").Path("/originators").HandlerFunc(createUserOriginator(logger, accountsClient, customersClient, depositoryRepo, originatorRepo))
+func AddOriginatorRoutes(logger log.Logger, r *mux.Router, accountsClient AccountsClient, customersClient customers.Client, depositoryRepo DepositoryRepository, eventRepo events.Repository, originatorRepo originatorRepository) {
+	r.Methods("GET").Path("/originators").HandlerFunc(auth.Require(auth.OriginatorsRead, getUserOriginators(logger, originatorRepo)))
+	r.Methods("POST").Path("/originators").HandlerFunc(auth.Require(auth.OriginatorsWrite, createUserOriginator(logger, accountsClient, customersClient, depositoryRepo, originatorRepo)))
 
-	r.Methods("GET").Path("/originators/{originatorId}").HandlerFunc(getUserOriginator(logger, originatorRepo))
-	r.Methods("DELETE").Path("/originators/{originatorId}").HandlerFunc(deleteUserOriginator(logger, originatorRepo))
+	r.Methods("GET").Path("/originators/{originatorId}").HandlerFunc(auth.Require(auth.OriginatorsRead, getUserOriginator(logger, originatorRepo)))
+	r.Methods("PATCH").Path("/originators/{originatorId}").HandlerFunc(auth.Require(auth.OriginatorsWrite, updateUserOriginator(logger, accountsClient, customersClient, depositoryRepo, eventRepo, originatorRepo)))
+	r.Methods("DELETE").Path("/originators/{originatorId}").HandlerFunc(auth.Require(auth.OriginatorsWrite, deleteUserOriginator(logger, originatorRepo)))
 }
 
 func getUserOriginators(logger log.Logger, originatorRepo originatorRepository) http.HandlerFunc {
@@ -128,12 +146,17 @@ func getUserOriginators(logger log.Logger, originatorRepo originatorRepository)
 			return
 		}
 
-		origs, err := originatorRepo.getUserOriginators(responder.XUserID)
+		origs, err := originatorRepo.getUserOriginators(r.Context(), responder.XUserID)
 		if err != nil {
 			responder.Log("originators", fmt.Sprintf("problem reading user originators: %v", err))
 			responder.Problem(err)
 			return
 		}
+		if !unmaskPII(r) {
+			for i := range origs {
+				origs[i] = origs[i].masked()
+			}
+		}
 
 		responder.Respond(func(w http.ResponseWriter) {
 			w.WriteHeader(http.StatusOK)
@@ -170,7 +193,7 @@ func createUserOriginator(logger log.Logger, accountsClient AccountsClient, cust
 		userID, requestID := route.GetUserID(r), moovhttp.GetRequestID(r)
 
 		// Verify depository belongs to the user
-		dep, err := depositoryRepo.GetUserDepository(req.DefaultDepository, userID)
+		dep, err := depositoryRepo.GetUserDepository(r.Context(), req.DefaultDepository, userID)
 		if err != nil || dep == nil || dep.ID != req.DefaultDepository {
 			responder.Problem(fmt.Errorf("depository %s does not exist", req.DefaultDepository))
 			return
@@ -178,7 +201,7 @@ func createUserOriginator(logger log.Logger, accountsClient AccountsClient, cust
 
 		// Verify account exists in Accounts for receiver (userID)
 		if accountsClient != nil {
-			account, err := accountsClient.SearchAccounts(requestID, userID, dep)
+			account, err := accountsClient.SearchAccounts(r.Context(), requestID, userID, dep)
 			if err != nil || account == nil {
 				responder.Log("originators", fmt.Sprintf("problem finding account depository=%s: %v", dep.ID, err))
 				responder.Problem(err)
@@ -188,7 +211,7 @@ func createUserOriginator(logger log.Logger, accountsClient AccountsClient, cust
 
 		// Create the customer with Moov's service
 		if customersClient != nil {
-			customer, err := customersClient.Create(&customers.Request{
+			customer, err := customersClient.Create(r.Context(), &customers.Request{
 				// Email: "foo@moov.io", // TODO(adam): should we include this? (and thus require it from callers)
 				Name:      dep.Holder,
 				BirthDate: req.BirthDate,
@@ -209,12 +232,15 @@ func createUserOriginator(logger log.Logger, accountsClient AccountsClient, cust
 		}
 
 		// Write Originator to DB
-		orig, err := originatorRepo.createUserOriginator(userID, req)
+		orig, err := originatorRepo.createUserOriginator(r.Context(), userID, req)
 		if err != nil {
 			responder.Log("originators", fmt.Sprintf("problem creating originator: %v", err))
 			responder.Problem(err)
 			return
 		}
+		if !unmaskPII(r) {
+			orig = orig.masked()
+		}
 
 		responder.Respond(func(w http.ResponseWriter) {
 			w.WriteHeader(http.StatusOK)
@@ -231,12 +257,15 @@ func getUserOriginator(logger log.Logger, originatorRepo originatorRepository) h
 		}
 
 		origID := getOriginatorId(r)
-		orig, err := originatorRepo.getUserOriginator(origID, responder.XUserID)
+		orig, err := originatorRepo.getUserOriginator(r.Context(), origID, responder.XUserID)
 		if err != nil {
 			responder.Log("originators", fmt.Sprintf("problem reading originator=%s: %v", origID, err))
 			responder.Problem(err)
 			return
 		}
+		if !unmaskPII(r) {
+			orig = orig.masked()
+		}
 		responder.Respond(func(w http.ResponseWriter) {
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(orig)
@@ -244,6 +273,212 @@ func getUserOriginator(logger log.Logger, originatorRepo originatorRepository) h
 	}
 }
 
+func updateUserOriginator(logger log.Logger, accountsClient AccountsClient, customersClient customers.Client, depositoryRepo DepositoryRepository, eventRepo events.Repository, originatorRepo originatorRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		var req originatorRequest
+		if err := json.NewDecoder(Read(r.Body)).Decode(&req); err != nil {
+			responder.Problem(err)
+			return
+		}
+		if req.Address != nil && *req.Address == (kyc.Address{}) {
+			req.Address = nil // generated clients always send an address
+		}
+
+		origID := getOriginatorId(r)
+		orig, err := originatorRepo.getUserOriginator(r.Context(), origID, responder.XUserID)
+		if orig == nil || err != nil {
+			responder.Log("originators", fmt.Sprintf("problem getting originator=%s: %v", origID, err))
+			responder.Problem(fmt.Errorf("originator %s not found", origID))
+			return
+		}
+
+		// changed collects which fields were updated for the event we record
+		var changed []string
+		if req.DefaultDepository != "" && req.DefaultDepository != orig.DefaultDepository {
+			changed = append(changed, "defaultDepository")
+		}
+		if req.Identification != "" && req.Identification != orig.Identification {
+			changed = append(changed, "identification")
+		}
+		// BirthDate and Address are only stored in Customers, so any value is a change
+		if !req.BirthDate.IsZero() {
+			changed = append(changed, "birthDate")
+		}
+		if req.Address != nil {
+			changed = append(changed, "address")
+		}
+		if req.Metadata != "" && req.Metadata != orig.Metadata {
+			changed = append(changed, "metadata")
+		}
+
+		// Verify the user controls the Depository and it exists in Accounts
+		depID := orig.DefaultDepository
+		if req.DefaultDepository != "" {
+			depID = req.DefaultDepository
+		}
+		dep, err := depositoryRepo.GetUserDepository(r.Context(), depID, responder.XUserID)
+		if err != nil || dep == nil || dep.ID != depID {
+			responder.Problem(fmt.Errorf("depository %s does not exist", depID))
+			return
+		}
+		if accountsClient != nil && req.DefaultDepository != "" {
+			account, err := accountsClient.SearchAccounts(r.Context(), responder.XRequestID, responder.XUserID, dep)
+			if err != nil || account == nil {
+				responder.Log("originators", fmt.Sprintf("problem finding account depository=%s: %v", dep.ID, err))
+				responder.Problem(err)
+				return
+			}
+		}
+
+		orig.DefaultDepository = depID
+		if req.Identification != "" {
+			orig.Identification = req.Identification
+		}
+		if req.Metadata != "" {
+			orig.Metadata = req.Metadata
+		}
+		if err := orig.validate(); err != nil {
+			responder.Log("originators", fmt.Sprintf("problem validating updatable originator=%s: %v", orig.ID, err))
+			responder.Problem(err)
+			return
+		}
+
+		// Customers is updated first so a failure there leaves the Originator unchanged.
+		if customersClient != nil {
+			if orig.CustomerID == "" {
+				err = createOriginatorCustomer(r.Context(), responder, customersClient, orig, dep, req)
+			} else {
+				err = updateOriginatorCustomer(r.Context(), responder, customersClient, orig, dep, req, changed)
+			}
+			if err != nil {
+				responder.Log("originators", fmt.Sprintf("problem syncing customer for originator=%s: %v", orig.ID, err))
+				responder.Problem(err)
+				return
+			}
+		}
+
+		orig.Updated = base.NewTime(time.Now())
+		if err := originatorRepo.updateUserOriginator(r.Context(), responder.XUserID, orig); err != nil {
+			responder.Log("originators", fmt.Sprintf("problem updating originator=%s: %v", orig.ID, err))
+			responder.Problem(err)
+			return
+		}
+		if len(changed) > 0 {
+			if err := writeOriginatorUpdatedEvent(r.Context(), responder.XUserID, orig, changed, eventRepo); err != nil {
+				responder.Log("originators", fmt.Sprintf("problem writing event for originator=%s: %v", orig.ID, err))
+			}
+		}
+		if !unmaskPII(r) {
+			orig = orig.masked()
+		}
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(orig)
+		})
+	}
+}
+
+// updateOriginatorCustomer pushes changes to the identification, birth date or address of an
+// Originator to its Customer.
+//
+// Customers can't update those fields of an existing Customer, so a replacement Customer is created
+// from the existing one with the changes applied and the Originator is linked to it.
+func updateOriginatorCustomer(ctx context.Context, responder *route.Responder, customersClient customers.Client, orig *Originator, dep *Depository, req originatorRequest, changed []string) error {
+	replace := false
+	for i := range changed {
+		switch changed[i] {
+		case "identification", "birthDate", "address":
+			replace = true
+		}
+	}
+	if !replace {
+		return nil
+	}
+
+	existing, err := customersClient.Lookup(ctx, orig.CustomerID, responder.XRequestID, responder.XUserID)
+	if err != nil || existing == nil {
+		return fmt.Errorf("problem reading customer=%s: %v", orig.CustomerID, err)
+	}
+	opts := &customers.Request{
+		Name:      dep.Holder,
+		Email:     existing.Email,
+		SSN:       orig.Identification,
+		BirthDate: existing.BirthDate,
+		Addresses: kyc.ConvertAddress(req.Address),
+		RequestID: responder.XRequestID,
+		UserID:    responder.XUserID,
+	}
+	if !req.BirthDate.IsZero() {
+		opts.BirthDate = req.BirthDate
+	}
+	if req.Address == nil {
+		for _, addr := range existing.Addresses {
+			if addr.Active {
+				opts.Addresses = append(opts.Addresses, moovcustomers.CreateAddress{
+					Type:       addr.Type,
+					Address1:   addr.Address1,
+					Address2:   addr.Address2,
+					City:       addr.City,
+					State:      addr.State,
+					PostalCode: addr.PostalCode,
+					Country:    addr.Country,
+				})
+			}
+		}
+	}
+	for _, phone := range existing.Phones {
+		opts.Phones = append(opts.Phones, moovcustomers.CreatePhone{Number: phone.Number, Type: phone.Type})
+	}
+
+	customer, err := customersClient.Create(ctx, opts)
+	if err != nil || customer == nil {
+		return fmt.Errorf("error replacing customer=%s: %v", orig.CustomerID, err)
+	}
+	responder.Log("originators", fmt.Sprintf("replaced customer=%s with customer=%s for originator=%s", orig.CustomerID, customer.ID, orig.ID))
+	orig.CustomerID = customer.ID
+	return nil
+}
+
+// createOriginatorCustomer creates a Customer for an Originator which doesn't have one and links them.
+func createOriginatorCustomer(ctx context.Context, responder *route.Responder, customersClient customers.Client, orig *Originator, dep *Depository, req originatorRequest) error {
+	customer, err := customersClient.Create(ctx, &customers.Request{
+		Name:      dep.Holder,
+		BirthDate: req.BirthDate,
+		Addresses: kyc.ConvertAddress(req.Address),
+		SSN:       orig.Identification,
+		RequestID: responder.XRequestID,
+		UserID:    responder.XUserID,
+	})
+	if err != nil || customer == nil {
+		return fmt.Errorf("error creating Customer: %v", err)
+	}
+	responder.Log("originators", fmt.Sprintf("linked customer=%s to originator=%s", customer.ID, orig.ID))
+	orig.CustomerID = customer.ID
+	return nil
+}
+
+func writeOriginatorUpdatedEvent(ctx context.Context, userID id.User, orig *Originator, changed []string, eventRepo events.Repository) error {
+	if eventRepo == nil {
+		return nil
+	}
+	return eventRepo.WriteEvent(ctx, userID, &events.Event{
+		ID:      events.EventID(base.ID()),
+		Topic:   fmt.Sprintf("originator %s updated", orig.ID),
+		Message: fmt.Sprintf("Originator %s updated %s", orig.ID, strings.Join(changed, ", ")),
+		Type:    events.OriginatorEvent,
+		Metadata: map[string]string{
+			"originator": string(orig.ID),
+			"customerID": orig.CustomerID,
+		},
+	})
+}
+
 func deleteUserOriginator(logger log.Logger, originatorRepo originatorRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		responder := route.NewResponder(logger, w, r)
@@ -252,7 +487,7 @@ func deleteUserOriginator(logger log.Logger, originatorRepo originatorRepository
 		}
 
 		origID := getOriginatorId(r)
-		if err := originatorRepo.deleteUserOriginator(origID, responder.XUserID); err != nil {
+		if err := originatorRepo.deleteUserOriginator(r.Context(), origID, responder.XUserID); err != nil {
 			responder.Log("originators", fmt.Sprintf("problem deleting originator=%s: %v", origID, err))
 			responder.Problem(err)
 			return
@@ -271,35 +506,39 @@ func getOriginatorId(r *http.Request) OriginatorID {
 }
 
 type originatorRepository interface {
-	getUserOriginators(userID id.User) ([]*Originator, error)
-	getUserOriginator(id OriginatorID, userID id.User) (*Originator, error)
+	getUserOriginators(ctx context.Context, userID id.User) ([]*Originator, error)
+	getUserOriginator(ctx context.Context, id OriginatorID, userID id.User) (*Originator, error)
 
-	createUserOriginator(userID id.User, req originatorRequest) (*Originator, error)
-	deleteUserOriginator(id OriginatorID, userID id.User) error
+	createUserOriginator(ctx context.Context, userID id.User, req originatorRequest) (*Originator, error)
+	updateUserOriginator(ctx context.Context, userID id.User, orig *Originator) error
+	deleteUserOriginator(ctx context.Context, id OriginatorID, userID id.User) error
 }
 
-func NewOriginatorRepo(logger log.Logger, db *sql.DB) *SQLOriginatorRepo {
-	return &SQLOriginatorRepo{log: logger, db: db}
+func NewOriginatorRepo(logger log.Logger, db *sql.DB, keeper *secrets.StringKeeper) *SQLOriginatorRepo {
+	return &SQLOriginatorRepo{log: logger, db: db, keeper: keeper}
 }
 
 type SQLOriginatorRepo struct {
 	db  *sql.DB
 	log log.Logger
+
+	// keeper encrypts and decrypts Identification
+	keeper *secrets.StringKeeper
 }
 
 func (r *SQLOriginatorRepo) Close() error {
 	return r.db.Close()
 }
 
-func (r *SQLOriginatorRepo) getUserOriginators(userID id.User) ([]*Originator, error) {
+func (r *SQLOriginatorRepo) getUserOriginators(ctx context.Context, userID id.User) ([]*Originator, error) {
 	query := `select originator_id from originators where user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(userID)
+	rows, err := stmt.QueryContext(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -318,7 +557,7 @@ func (r *SQLOriginatorRepo) getUserOriginators(userID id.User) ([]*Originator, e
 
 	var originators []*Originator
 	for i := range originatorIds {
-		orig, err := r.getUserOriginator(OriginatorID(originatorIds[i]), userID)
+		orig, err := r.getUserOriginator(ctx, OriginatorID(originatorIds[i]), userID)
 		if err == nil && orig.ID != "" {
 			originators = append(originators, orig)
 		}
@@ -326,28 +565,35 @@ func (r *SQLOriginatorRepo) getUserOriginators(userID id.User) ([]*Originator, e
 	return originators, rows.Err()
 }
 
-func (r *SQLOriginatorRepo) getUserOriginator(id OriginatorID, userID id.User) (*Originator, error) {
-	query := `select originator_id, default_depository, identification, customer_id, metadata, created_at, last_updated_at
+func (r *SQLOriginatorRepo) getUserOriginator(ctx context.Context, id OriginatorID, userID id.User) (*Originator, error) {
+	query := `select originator_id, default_depository, identification, identification_encrypted, customer_id, metadata, created_at, last_updated_at
 from originators
 where originator_id = ? and user_id = ? and deleted_at is null
 limit 1`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(id, userID)
+	row := stmt.QueryRowContext(ctx, id, userID)
 
 	orig := &Originator{}
 	var (
-		created time.Time
-		updated time.Time
+		encrypted string
+		created   time.Time
+		updated   time.Time
 	)
-	err = row.Scan(&orig.ID, &orig.DefaultDepository, &orig.Identification, &orig.CustomerID, &orig.Metadata, &created, &updated)
+	err = row.Scan(&orig.ID, &orig.DefaultDepository, &orig.Identification, &encrypted, &orig.CustomerID, &orig.Metadata, &created, &updated)
 	if err != nil {
 		return nil, err
 	}
+	if encrypted != "" {
+		// Rows which haven't been migrated by EncryptStoredPII still have a plaintext Identification
+		if orig.Identification, err = r.keeper.DecryptString(encrypted); err != nil {
+			return nil, fmt.Errorf("problem decrypting originator=%s identification: %v", orig.ID, err)
+		}
+	}
 	orig.Created = base.NewTime(created)
 	orig.Updated = base.NewTime(updated)
 	if orig.ID == "" {
@@ -356,7 +602,12 @@ limit 1`
 	return orig, nil
 }
 
-func (r *SQLOriginatorRepo) createUserOriginator(userID id.User, req originatorRequest) (*Originator, error) {
+func (r *SQLOriginatorRepo) createUserOriginator(ctx context.Context, userID id.User, req originatorRequest) (_ *Originator, err error) {
+	ctx, span := tracing.Start(ctx, "originatorRepo.createUserOriginator")
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	now := time.Now()
 	orig := &Originator{
 		ID:                OriginatorID(base.ID()),
@@ -371,28 +622,67 @@ func (r *SQLOriginatorRepo) createUserOriginator(userID id.User, req originatorR
 		return nil, err
 	}
 
-	query := `insert into originators (originator_id, user_id, default_depository, identification, customer_id, metadata, created_at, last_updated_at) values (?, ?, ?, ?, ?, ?, ?, ?)`
-	stmt, err := r.db.Prepare(query)
+	encrypted, err := r.keeper.EncryptString(orig.Identification)
+	if err != nil {
+		return nil, fmt.Errorf("problem encrypting identification: %v", err)
+	}
+
+	query := `insert into originators (originator_id, user_id, default_depository, identification, identification_encrypted, customer_id, metadata, created_at, last_updated_at) values (?, ?, ?, '', ?, ?, ?, ?, ?)`
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(orig.ID, userID, orig.DefaultDepository, orig.Identification, orig.CustomerID, orig.Metadata, now, now)
+	_, err = stmt.ExecContext(ctx, orig.ID, userID, orig.DefaultDepository, encrypted, orig.CustomerID, orig.Metadata, now, now)
 	if err != nil {
 		return nil, err
 	}
 	return orig, nil
 }
 
-func (r *SQLOriginatorRepo) deleteUserOriginator(id OriginatorID, userID id.User) error {
+func (r *SQLOriginatorRepo) updateUserOriginator(ctx context.Context, userID id.User, orig *Originator) (err error) {
+	ctx, span := tracing.Start(ctx, "originatorRepo.updateUserOriginator", "originatorID", orig.ID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	encrypted, err := r.keeper.EncryptString(orig.Identification)
+	if err != nil {
+		return fmt.Errorf("problem encrypting identification: %v", err)
+	}
+
+	query := `update originators set default_depository = ?, identification = '', identification_encrypted = ?, customer_id = ?, metadata = ?, last_updated_at = ?
+where originator_id = ? and user_id = ? and deleted_at is null`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, orig.DefaultDepository, encrypted, orig.CustomerID, orig.Metadata, orig.Updated.Time, orig.ID, userID)
+	if err != nil {
+		return fmt.Errorf("error updating originator=%s: %v", orig.ID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("originator %s not found", orig.ID)
+	}
+	return nil
+}
+
+func (r *SQLOriginatorRepo) deleteUserOriginator(ctx context.Context, id OriginatorID, userID id.User) (err error) {
+	ctx, span := tracing.Start(ctx, "originatorRepo.deleteUserOriginator", "originatorID", id)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update originators set deleted_at = ? where originator_id = ? and user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(time.Now(), id, userID)
+	_, err = stmt.ExecContext(ctx, time.Now(), id, userID)
 	return err
 }
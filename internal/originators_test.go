@@ -6,6 +6,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,13 +19,15 @@ import (
 
 	accounts "github.com/moov-io/accounts/client"
 	"github.com/moov-io/base"
+	moovcustomers "github.com/moov-io/customers/client"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/customers"
 	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/events"
 	"github.com/moov-io/paygate/internal/secrets"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
-	"github.com/gorilla/mux"
 )
 
 type mockOriginatorRepository struct {
@@ -32,14 +35,14 @@ type mockOriginatorRepository struct {
 	err         error
 }
 
-func (r *mockOriginatorRepository) getUserOriginators(userID id.User) ([]*Originator, error) {
+func (r *mockOriginatorRepository) getUserOriginators(ctx context.Context, userID id.User) ([]*Originator, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
 	return r.originators, nil
 }
 
-func (r *mockOriginatorRepository) getUserOriginator(id OriginatorID, userID id.User) (*Originator, error) {
+func (r *mockOriginatorRepository) getUserOriginator(ctx context.Context, id OriginatorID, userID id.User) (*Originator, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
@@ -49,14 +52,18 @@ func (r *mockOriginatorRepository) getUserOriginator(id OriginatorID, userID id.
 	return nil, nil
 }
 
-func (r *mockOriginatorRepository) createUserOriginator(userID id.User, req originatorRequest) (*Originator, error) {
+func (r *mockOriginatorRepository) createUserOriginator(ctx context.Context, userID id.User, req originatorRequest) (*Originator, error) {
 	if len(r.originators) > 0 {
 		return r.originators[0], nil
 	}
 	return nil, nil
 }
 
-func (r *mockOriginatorRepository) deleteUserOriginator(id OriginatorID, userID id.User) error {
+func (r *mockOriginatorRepository) updateUserOriginator(ctx context.Context, userID id.User, orig *Originator) error {
+	return r.err
+}
+
+func (r *mockOriginatorRepository) deleteUserOriginator(ctx context.Context, id OriginatorID, userID id.User) error {
 	return r.err
 }
 
@@ -105,7 +112,7 @@ func TestOriginators_getUserOriginators(t *testing.T) {
 			Metadata:          "extra data",
 			customerID:        "custID",
 		}
-		orig, err := repo.createUserOriginator(userID, req)
+		orig, err := repo.createUserOriginator(context.Background(), userID, req)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -139,12 +146,17 @@ func TestOriginators_getUserOriginators(t *testing.T) {
 	// SQLite tests
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
-	check(t, &SQLOriginatorRepo{sqliteDB.DB, log.NewNopLogger()})
+	check(t, NewOriginatorRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t)))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewOriginatorRepo(log.NewNopLogger(), postgresDB.DB, secrets.TestStringKeeper(t)))
 
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
-	check(t, &SQLOriginatorRepo{mysqlDB.DB, log.NewNopLogger()})
+	check(t, NewOriginatorRepo(log.NewNopLogger(), mysqlDB.DB, secrets.TestStringKeeper(t)))
 }
 
 func TestOriginators_CustomersError(t *testing.T) {
@@ -155,7 +167,7 @@ func TestOriginators_CustomersError(t *testing.T) {
 
 	keeper := secrets.TestStringKeeper(t)
 	depRepo := NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper)
-	origRepo := &SQLOriginatorRepo{db.DB, log.NewNopLogger()}
+	origRepo := NewOriginatorRepo(log.NewNopLogger(), db.DB, secrets.TestStringKeeper(t))
 
 	// Write Depository to repo
 	userID := id.User(base.ID())
@@ -169,7 +181,7 @@ func TestOriginators_CustomersError(t *testing.T) {
 		EncryptedAccountNumber: "151",
 		Status:                 DepositoryUnverified,
 	}
-	if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		t.Fatal(err)
 	}
 
@@ -228,8 +240,8 @@ func TestOriginators_HTTPGet(t *testing.T) {
 		originators: []*Originator{orig},
 	}
 
-	router := mux.NewRouter()
-	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, repo)
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, nil, repo)
 
 	req := httptest.NewRequest("GET", fmt.Sprintf("/originators/%s", orig.ID), nil)
 	req.Header.Set("x-user-id", userID)
@@ -251,11 +263,62 @@ func TestOriginators_HTTPGet(t *testing.T) {
 	}
 }
 
+func TestOriginators__HTTPGetMasked(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	userID := id.User(base.ID())
+	repo := NewOriginatorRepo(log.NewNopLogger(), db.DB, secrets.TestStringKeeper(t))
+	orig, err := repo.createUserOriginator(context.Background(), userID, originatorRequest{
+		DefaultDepository: id.Depository(base.ID()),
+		Identification:    "123456789",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, nil, repo)
+
+	get := func(query string) *Originator {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/originators/%s%s", orig.ID, query), nil)
+		req.Header.Set("x-user-id", userID.String())
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		var originator Originator
+		if err := json.NewDecoder(w.Body).Decode(&originator); err != nil {
+			t.Fatal(err)
+		}
+		return &originator
+	}
+	if o := get(""); o.Identification != "*****6789" {
+		t.Errorf("unexpected identification: %q", o.Identification)
+	}
+	if o := get("?unmask=true"); o.Identification != "123456789" {
+		t.Errorf("unexpected identification: %q", o.Identification)
+	}
+
+	// identification is only stored encrypted
+	var identification, encrypted string
+	if err := db.DB.QueryRow(`select identification, identification_encrypted from originators limit 1`).Scan(&identification, &encrypted); err != nil {
+		t.Fatal(err)
+	}
+	if identification != "" || encrypted == "" {
+		t.Errorf("identification=%q encrypted=%q", identification, encrypted)
+	}
+}
+
 func TestOriginators__HTTPGetAllNoUserID(t *testing.T) {
 	repo := &mockOriginatorRepository{}
 
-	router := mux.NewRouter()
-	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, repo)
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, nil, repo)
 
 	req := httptest.NewRequest("GET", "/originators", nil)
 
@@ -263,7 +326,7 @@ func TestOriginators__HTTPGetAllNoUserID(t *testing.T) {
 	router.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -271,8 +334,8 @@ func TestOriginators__HTTPGetAllNoUserID(t *testing.T) {
 func TestOriginators__HTTPGetNoUserID(t *testing.T) {
 	repo := &mockOriginatorRepository{}
 
-	router := mux.NewRouter()
-	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, repo)
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, nil, repo)
 
 	req := httptest.NewRequest("GET", "/originators/foo", nil)
 
@@ -280,7 +343,7 @@ func TestOriginators__HTTPGetNoUserID(t *testing.T) {
 	router.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -295,7 +358,7 @@ func TestOriginators__HTTPPost(t *testing.T) {
 	depRepo := NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
 	origRepo := &mockOriginatorRepository{}
 
-	if err := depRepo.UpsertUserDepository(userID, &Depository{
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, &Depository{
 		ID:            id.Depository("foo"),
 		RoutingNumber: "987654320",
 		Type:          Checking,
@@ -309,8 +372,8 @@ func TestOriginators__HTTPPost(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	router := mux.NewRouter()
-	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, depRepo, origRepo)
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, depRepo, nil, origRepo)
 
 	body := strings.NewReader(`{"defaultDepository": "foo", "identification": "baz", "metadata": "other"}`)
 	req := httptest.NewRequest("POST", "/originators", body)
@@ -336,8 +399,8 @@ func TestOriginators__HTTPPost(t *testing.T) {
 func TestOriginators__HTTPPostNoUserID(t *testing.T) {
 	repo := &mockOriginatorRepository{}
 
-	router := mux.NewRouter()
-	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, repo)
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, nil, repo)
 
 	req := httptest.NewRequest("POST", "/originators", nil)
 
@@ -345,7 +408,7 @@ func TestOriginators__HTTPPostNoUserID(t *testing.T) {
 	router.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -364,8 +427,8 @@ func TestOriginators__HTTPDelete(t *testing.T) {
 		originators: []*Originator{orig},
 	}
 
-	router := mux.NewRouter()
-	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, repo)
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, nil, repo)
 
 	req := httptest.NewRequest("DELETE", fmt.Sprintf("/originators/%s", orig.ID), nil)
 	req.Header.Set("x-user-id", userID)
@@ -382,8 +445,8 @@ func TestOriginators__HTTPDelete(t *testing.T) {
 func TestOriginators__HTTPDeleteNoUserID(t *testing.T) {
 	repo := &mockOriginatorRepository{}
 
-	router := mux.NewRouter()
-	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, repo)
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, nil, nil, nil, repo)
 
 	req := httptest.NewRequest("DELETE", "/originators/foo", nil)
 
@@ -391,7 +454,215 @@ func TestOriginators__HTTPDeleteNoUserID(t *testing.T) {
 	router.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
+
+func TestOriginators__update(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLOriginatorRepo) {
+		userID := id.User(base.ID())
+		orig, err := repo.createUserOriginator(context.Background(), userID, originatorRequest{
+			DefaultDepository: id.Depository("foo"),
+			Identification:    "123456789",
+			Metadata:          "data",
+			customerID:        "cust1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		orig.DefaultDepository = id.Depository("bar")
+		orig.Identification = "987654321"
+		orig.CustomerID = "cust2"
+		orig.Metadata = "other"
+		orig.Updated = base.NewTime(time.Now())
+		if err := repo.updateUserOriginator(context.Background(), userID, orig); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := repo.getUserOriginator(context.Background(), orig.ID, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.DefaultDepository != "bar" || found.Identification != "987654321" || found.CustomerID != "cust2" || found.Metadata != "other" {
+			t.Errorf("unexpected originator: %#v", found)
+		}
+
+		// other users can't update the Originator
+		if err := repo.updateUserOriginator(context.Background(), id.User(base.ID()), orig); err == nil {
+			t.Error("expected error")
+		}
+	}
+
+	keeper := secrets.TestStringKeeper(t)
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewOriginatorRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewOriginatorRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewOriginatorRepo(log.NewNopLogger(), mysqlDB.DB, keeper))
+}
+
+func TestOriginators__HTTPUpdate(t *testing.T) {
+	userID := id.User(base.ID())
+
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+
+	keeper := secrets.TestStringKeeper(t)
+	depRepo := NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
+	eventRepo := events.NewRepo(log.NewNopLogger(), sqliteDB.DB)
+	origRepo := NewOriginatorRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
+
+	for _, depID := range []id.Depository{"foo", "bar"} {
+		if err := depRepo.UpsertUserDepository(context.Background(), userID, &Depository{
+			ID:            depID,
+			RoutingNumber: "987654320",
+			Type:          Checking,
+			BankName:      "bank name",
+			Holder:        "holder",
+			HolderType:    Individual,
+			Status:        DepositoryVerified,
+			Created:       base.NewTime(time.Now().Add(-1 * time.Second)),
+			keeper:        keeper,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orig, err := origRepo.createUserOriginator(context.Background(), userID, originatorRequest{
+		DefaultDepository: id.Depository("foo"),
+		Identification:    "123456789",
+		customerID:        "cust1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customersClient := &customers.TestClient{
+		Customer: &moovcustomers.Customer{ID: "cust2"},
+	}
+
+	router := auth.NewTestRouter()
+	AddOriginatorRoutes(log.NewNopLogger(), router, nil, customersClient, depRepo, eventRepo, origRepo)
+
+	patch := func(body string) (int, *Originator) {
+		req := httptest.NewRequest("PATCH", fmt.Sprintf("/originators/%s", orig.ID), strings.NewReader(body))
+		req.Header.Set("x-user-id", userID.String())
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		var out Originator
+		if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, &out
+	}
+
+	// changes Customers doesn't store keep the Customer
+	code, out := patch(`{"metadata": "other"}`)
+	if code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d", code)
+	}
+	if out.Metadata != "other" || out.CustomerID != "cust1" || out.Identification != "*****6789" {
+		t.Errorf("unexpected originator: %#v", out)
+	}
+	code, out = patch(`{"defaultDepository": "bar", "address": {}}`)
+	if code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d", code)
+	}
+	if out.DefaultDepository != "bar" || out.CustomerID != "cust1" {
+		t.Errorf("unexpected originator: %#v", out)
+	}
+
+	// nothing is saved when Customers fails
+	customersClient.Err = errors.New("bad error")
+	if code, _ := patch(`{"metadata": "failed", "identification": "111223333"}`); code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", code)
+	}
+	if found, _ := origRepo.getUserOriginator(context.Background(), orig.ID, userID); found.Identification != "123456789" || found.Metadata != "other" || found.CustomerID != "cust1" {
+		t.Errorf("originator was updated: %#v", found)
+	}
+	customersClient.Err = nil
+
+	// the SSN, birth date and address are pushed to a replacement Customer
+	for _, body := range []string{
+		`{"identification": "111223333"}`,
+		`{"birthDate": "1990-01-01T00:00:00Z"}`,
+		`{"address": {"address1": "123 1st St", "city": "Anytown", "state": "CA", "postalCode": "90210"}}`,
+	} {
+		customersClient.Customer = &moovcustomers.Customer{ID: base.ID()}
+		code, out := patch(body)
+		if code != http.StatusOK {
+			t.Fatalf("%s: bogus HTTP status: %d", body, code)
+		}
+		if out.CustomerID != customersClient.Customer.ID {
+			t.Errorf("%s: unexpected originator: %#v", body, out)
+		}
+	}
+	if found, _ := origRepo.getUserOriginator(context.Background(), orig.ID, userID); found.Identification != "111223333" || found.CustomerID != customersClient.Customer.ID {
+		t.Errorf("unexpected originator: %#v", found)
+	}
+
+	evts, err := eventRepo.GetUserEventsByMetadata(context.Background(), userID, map[string]string{"originator": string(orig.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evts) != 5 || evts[0].Type != events.OriginatorEvent {
+		t.Errorf("unexpected events: %#v", evts)
+	}
+	for i := range evts {
+		if strings.Contains(evts[i].Message, "123456789") || strings.Contains(evts[i].Message, "111223333") {
+			t.Errorf("identification in event: %q", evts[i].Message)
+		}
+	}
+
+	// unknown Depositories are rejected
+	if code, _ := patch(`{"defaultDepository": "other"}`); code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", code)
+	}
+
+	// Originators without a Customer are linked to a new one
+	orig, err = origRepo.createUserOriginator(context.Background(), userID, originatorRequest{
+		DefaultDepository: id.Depository("foo"),
+		Identification:    "123456789",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	customersClient.Err = errors.New("bad error")
+	if code, _ := patch(`{"identification": "111223333"}`); code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d", code)
+	}
+	if found, _ := origRepo.getUserOriginator(context.Background(), orig.ID, userID); found.Identification != "123456789" || found.CustomerID != "" {
+		t.Errorf("originator was updated: %#v", found)
+	}
+	customersClient.Err = nil
+	customersClient.Customer = &moovcustomers.Customer{ID: "cust2"}
+	code, out = patch(`{"identification": "111223333"}`)
+	if code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d", code)
+	}
+	if out.CustomerID != "cust2" {
+		t.Errorf("unexpected originator: %#v", out)
+	}
+	if found, _ := origRepo.getUserOriginator(context.Background(), orig.ID, userID); found.Identification != "111223333" || found.CustomerID != "cust2" {
+		t.Errorf("unexpected originator: %#v", found)
+	}
+}
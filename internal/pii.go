@@ -0,0 +1,35 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Personally identifiable information (PII) of Originators and Receivers is encrypted at rest with the
+// same secrets.StringKeeper as Depository account numbers. Receiver emails are also stored as a SHA-256
+// hash for lookups, and values are masked in HTTP responses unless the caller asks for them.
+
+// hashEmail returns the hex encoded SHA-256 of the lowercase form of an email address for equality
+// lookups, as domains and (in practice) mailboxes are case insensitive.
+func hashEmail(email string) (string, error) {
+	return hashAccountNumber(strings.ToLower(strings.TrimSpace(email)))
+}
+
+// maskIdentification keeps the last four characters of an SSN or FEIN, e.g. *****6789
+func maskIdentification(identification string) string {
+	if len(identification) <= 4 {
+		return strings.Repeat("*", len(identification))
+	}
+	return strings.Repeat("*", len(identification)-4) + identification[len(identification)-4:]
+}
+
+// unmaskPII returns true when the request asks for PII to be returned as-is with ?unmask=true
+func unmaskPII(r *http.Request) bool {
+	unmask, _ := strconv.ParseBool(r.URL.Query().Get("unmask"))
+	return unmask
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/moov-io/paygate/internal/secrets"
+
+	"github.com/go-kit/kit/log"
+)
+
+// EncryptStoredPII encrypts plaintext Originator identification and Receiver email columns (hashing emails
+// for lookups), then clears the plaintext values. Rows are migrated in batches like EncryptStoredAccountNumbers.
+func EncryptStoredPII(logger log.Logger, db *sql.DB, keeper *secrets.StringKeeper) error {
+	migrations := []piiColumn{
+		{table: "originators", idColumn: "originator_id", column: "identification"},
+		{table: "receivers", idColumn: "receiver_id", column: "email", hash: hashEmail},
+	}
+	for i := range migrations {
+		n, err := migrations[i].encrypt(db, keeper)
+		if err != nil {
+			return fmt.Errorf("encrypting %s.%s: %v", migrations[i].table, migrations[i].column, err)
+		}
+		if n > 0 {
+			logger.Log("pii", fmt.Sprintf("encrypted %d %s.%s values", n, migrations[i].table, migrations[i].column))
+		}
+	}
+	return nil
+}
+
+// piiColumn is a plaintext column whose values are moved into the {column}_encrypted column and, when
+// hash is set, the {column}_hashed column.
+type piiColumn struct {
+	table    string
+	idColumn string
+	column   string
+
+	hash func(string) (string, error)
+}
+
+type encryptablePII struct {
+	id        string
+	value     string
+	createdAt time.Time
+}
+
+func (c piiColumn) encrypt(db *sql.DB, keeper *secrets.StringKeeper) (int, error) {
+	var cur migrationCursor
+	var total int
+	for {
+		rows, err := c.grabEncryptable(db, cur, 100)
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil // no more records, so done
+		}
+		last := rows[len(rows)-1]
+		cur = migrationCursor{createdAt: last.createdAt, id: last.id} // update our next starting point
+
+		for i := range rows {
+			encrypted, err := keeper.EncryptString(rows[i].value)
+			if err != nil {
+				return total, err
+			}
+			set, args := fmt.Sprintf("%s = '', %s_encrypted = ?", c.column, c.column), []interface{}{encrypted}
+			if c.hash != nil {
+				hashed, err := c.hash(rows[i].value)
+				if err != nil {
+					return total, err
+				}
+				set, args = set+fmt.Sprintf(", %s_hashed = ?", c.column), append(args, hashed)
+			}
+			// Only clear the plaintext value we read in case the row was updated since
+			query := fmt.Sprintf(`update %s set %s where %s = ? and %s = ?;`, c.table, set, c.idColumn, c.column)
+			if _, err := db.Exec(query, append(args, rows[i].id, rows[i].value)...); err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+}
+
+func (c piiColumn) grabEncryptable(db *sql.DB, cur migrationCursor, batchSize int) ([]encryptablePII, error) {
+	after, args := cur.where("created_at", c.idColumn)
+	query := fmt.Sprintf(`select %s, %s, created_at from %s
+where %s <> '' and %s_encrypted = '' and %s
+order by created_at asc, %s asc limit ?;`, c.idColumn, c.column, c.table, c.column, c.column, after, c.idColumn)
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(append(args, batchSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []encryptablePII
+	for rows.Next() {
+		var row encryptablePII
+		if err := rows.Scan(&row.id, &row.value, &row.createdAt); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestPII__EncryptStoredPII(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, db *sql.DB) {
+		keeper := secrets.TestStringKeeper(t)
+		userID := id.User(base.ID())
+		origID, recID := OriginatorID(base.ID()), ReceiverID(base.ID())
+
+		// write rows the way paygate did before encryption
+		now := time.Now().Add(-1 * time.Second)
+		query := `insert into originators (originator_id, user_id, default_depository, identification, customer_id, metadata, created_at, last_updated_at) values (?, ?, ?, ?, '', '', ?, ?)`
+		if _, err := db.Exec(query, origID, userID, base.ID(), "123456789", now, now); err != nil {
+			t.Fatal(err)
+		}
+		query = `insert into receivers (receiver_id, user_id, email, default_depository, customer_id, status, metadata, created_at, last_updated_at) values (?, ?, ?, ?, '', ?, '', ?, ?)`
+		if _, err := db.Exec(query, recID, userID, "john.doe@moov.io", base.ID(), ReceiverVerified, now, now); err != nil {
+			t.Fatal(err)
+		}
+
+		// unmigrated rows can still be read
+		origRepo := NewOriginatorRepo(log.NewNopLogger(), db, keeper)
+		if orig, err := origRepo.getUserOriginator(context.Background(), origID, userID); err != nil || orig.Identification != "123456789" {
+			t.Fatalf("originator=%#v error=%v", orig, err)
+		}
+
+		if err := EncryptStoredPII(log.NewNopLogger(), db, keeper); err != nil {
+			t.Fatal(err)
+		}
+
+		var identification string
+		if err := db.QueryRow(`select identification from originators where originator_id = ?`, origID).Scan(&identification); err != nil || identification != "" {
+			t.Errorf("identification=%q error=%v", identification, err)
+		}
+		if orig, err := origRepo.getUserOriginator(context.Background(), origID, userID); err != nil || orig.Identification != "123456789" {
+			t.Errorf("originator=%#v error=%v", orig, err)
+		}
+
+		recRepo := NewReceiverRepo(log.NewNopLogger(), db, keeper)
+		if rec, err := recRepo.getUserReceiver(context.Background(), recID, userID); err != nil || rec == nil || rec.Email != "john.doe@moov.io" {
+			t.Errorf("receiver=%#v error=%v", rec, err)
+		}
+		if recs, err := recRepo.getUserReceiversByEmail(context.Background(), userID, "john.doe@moov.io"); err != nil || len(recs) != 1 {
+			t.Errorf("got %d receivers: %v", len(recs), err)
+		}
+	}
+
+	// SQLite
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, sqliteDB.DB)
+
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
+	// MySQL
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, mysqlDB.DB)
+}
+
+func TestPII__grabEncryptable(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, db *sql.DB) {
+		// every Originator is created in the same second so batches have to be split by ID
+		userID, created := id.User(base.ID()), time.Now().Add(-5*time.Second).Truncate(time.Second)
+		query := `insert into originators (originator_id, user_id, default_depository, identification, customer_id, metadata, created_at, last_updated_at) values (?, ?, ?, ?, '', '', ?, ?)`
+		for i := 0; i < 3; i++ {
+			if _, err := db.Exec(query, base.ID(), userID, base.ID(), "123456789", created, created); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		col := piiColumn{table: "originators", idColumn: "originator_id", column: "identification"}
+		seen := make(map[string]bool)
+		var cur migrationCursor
+		for {
+			rows, err := col.grabEncryptable(db, cur, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) == 0 {
+				break
+			}
+			for i := range rows {
+				seen[rows[i].id] = true
+			}
+			last := rows[len(rows)-1]
+			cur = migrationCursor{createdAt: last.createdAt, id: last.id}
+		}
+		if len(seen) != 3 {
+			t.Errorf("found %d originators", len(seen))
+		}
+	}
+
+	// SQLite
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, sqliteDB.DB)
+
+	// PostgreSQL
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
+	// MySQL
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, mysqlDB.DB)
+}
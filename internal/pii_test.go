@@ -0,0 +1,44 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPII__mask(t *testing.T) {
+	identifications := map[string]string{
+		"123456789":  "*****6789",
+		"12-3456789": "******6789",
+		"1234":       "****",
+		"":           "",
+	}
+	for in, expected := range identifications {
+		if v := maskIdentification(in); v != expected {
+			t.Errorf("maskIdentification(%q)=%q", in, v)
+		}
+	}
+}
+
+func TestPII__hashEmail(t *testing.T) {
+	a, _ := hashEmail("John.Doe@moov.io")
+	b, _ := hashEmail(" john.doe@MOOV.io")
+	if a == "" || a != b {
+		t.Errorf("a=%q b=%q", a, b)
+	}
+}
+
+func TestPII__unmaskPII(t *testing.T) {
+	if unmaskPII(httptest.NewRequest("GET", "/receivers", nil)) {
+		t.Error("expected masking")
+	}
+	if unmaskPII(httptest.NewRequest("GET", "/receivers?unmask=no", nil)) {
+		t.Error("expected masking")
+	}
+	if !unmaskPII(httptest.NewRequest("GET", "/receivers?unmask=true", nil)) {
+		t.Error("expected no masking")
+	}
+}
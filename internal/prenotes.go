@@ -0,0 +1,265 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/tracing"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+// Prenote is a zero dollar entry sent to a Depository to check its account and routing numbers
+// are valid. The Depository can be verified once the prenote has gone a few banking days without
+// being returned.
+type Prenote struct {
+	FileID      string
+	TraceNumber string
+	ReturnCode  string
+
+	// MergedAt is when the prenote was merged into a file for upload to the ODFI, nil until then.
+	MergedAt  *time.Time
+	CreatedAt time.Time
+}
+
+// submitPrenote creates the ACH file for a prenote from the ODFI's account to dep.
+func (r *DepositoryRouter) submitPrenote(ctx context.Context, userID id.User, requestID string, dep *Depository) (*Prenote, error) {
+	odfiOriginator, odfiDepository := r.odfiAccount.metadata()
+	if odfiOriginator == nil || odfiDepository == nil {
+		return nil, errors.New("unable to find ODFI originator or depository")
+	}
+
+	traceNumber, err := r.traceNumbers.reserve(ctx, odfiDepository.RoutingNumber, 1)
+	if err != nil {
+		return nil, fmt.Errorf("problem reserving prenote trace number: %v", err)
+	}
+
+	amount, _ := NewAmount("USD", "0.00")
+	rec := &Receiver{
+		ID:       ReceiverID(fmt.Sprintf("%s-prenote-verify", base.ID())),
+		Status:   ReceiverVerified, // Something to pass constructACHFile validation logic
+		Metadata: dep.Holder,
+	}
+	req := &transferRequest{
+		Type:                   PushTransfer,
+		Amount:                 *amount,
+		Originator:             odfiOriginator.ID,
+		OriginatorDepository:   odfiDepository.ID,
+		Receiver:               rec.ID,
+		ReceiverDepository:     dep.ID,
+		Description:            "PRENOTE",
+		StandardEntryClassCode: ach.PPD,
+		traceNumber:            traceNumber,
+	}
+
+	idempotencyKey := base.ID()
+	file, err := constructACHFile(string(rec.ID), idempotencyKey, userID, req.asTransfer(string(rec.ID)), rec, dep, odfiOriginator, odfiDepository)
+	if err != nil {
+		return nil, fmt.Errorf("problem constructing prenote ACH file for userID=%s: %v", userID, err)
+	}
+	if err := convertToPrenote(file, dep); err != nil {
+		return nil, err
+	}
+
+	fileID, err := r.achClient.CreateFile(ctx, idempotencyKey, file)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating prenote ACH file for userID=%s: %v", userID, err)
+	}
+	if err := checkACHFile(ctx, r.logger, r.achClient, fileID, userID); err != nil {
+		return nil, err
+	}
+	r.logger.Log("prenotes", fmt.Sprintf("created prenote ACH file=%s for depository=%s", fileID, dep.ID), "requestID", requestID, "userID", userID)
+
+	return &Prenote{
+		FileID:      fileID,
+		TraceNumber: traceNumber,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// convertToPrenote replaces the credit TransactionCode of a single entry file with the prenote code
+// for the receiving account's type.
+func convertToPrenote(file *ach.File, dep *Depository) error {
+	if file == nil || len(file.Batches) != 1 || len(file.Batches[0].GetEntries()) != 1 {
+		return errors.New("invalid prenote ACH file")
+	}
+	ed := file.Batches[0].GetEntries()[0]
+	if ed.Amount != 0 {
+		return fmt.Errorf("prenote has non-zero amount: %d", ed.Amount)
+	}
+	if dep.Type == Savings {
+		ed.TransactionCode = ach.SavingsPrenoteCredit
+	} else {
+		ed.TransactionCode = ach.CheckingPrenoteCredit
+	}
+	return file.Batches[0].Create()
+}
+
+func (r *SQLDepositoryRepo) createPrenote(ctx context.Context, id id.Depository, userID id.User, prenote *Prenote) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.createPrenote", "depositoryID", id)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `insert into depository_prenotes (depository_id, user_id, file_id, trace_number, created_at) values (?, ?, ?, ?, ?);`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, id, userID, prenote.FileID, prenote.TraceNumber, prenote.CreatedAt)
+	return err
+}
+
+// getLatestPrenote returns the most recent prenote sent to a Depository, or nil if there are none.
+func (r *SQLDepositoryRepo) getLatestPrenote(ctx context.Context, id id.Depository, userID id.User) (*Prenote, error) {
+	query := `select file_id, trace_number, return_code, merged_at, created_at from depository_prenotes
+where depository_id = ? and user_id = ? and deleted_at is null order by created_at desc limit 1;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var prenote Prenote
+	var returnCode *string
+	err = stmt.QueryRowContext(ctx, id, userID).Scan(&prenote.FileID, &prenote.TraceNumber, &returnCode, &prenote.MergedAt, &prenote.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if returnCode != nil {
+		prenote.ReturnCode = *returnCode
+	}
+	return &prenote, nil
+}
+
+// LookupPrenoteFromReturn finds the prenote sent to a Depository with the given trace number.
+func (r *SQLDepositoryRepo) LookupPrenoteFromReturn(ctx context.Context, id id.Depository, traceNumber string) (*Prenote, error) {
+	query := `select file_id, created_at from depository_prenotes where depository_id = ? and trace_number = ? and deleted_at is null limit 1;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("LookupPrenoteFromReturn prepare: %v", err)
+	}
+	defer stmt.Close()
+
+	prenote := Prenote{TraceNumber: traceNumber}
+	if err := stmt.QueryRowContext(ctx, id, traceNumber).Scan(&prenote.FileID, &prenote.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("LookupPrenoteFromReturn scan: %v", err)
+	}
+	return &prenote, nil
+}
+
+// SetPrenoteReturnCode will write the given returnCode (e.g. "R03") onto a Depository's prenote.
+func (r *SQLDepositoryRepo) SetPrenoteReturnCode(ctx context.Context, id id.Depository, traceNumber string, returnCode string) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.SetPrenoteReturnCode", "depositoryID", id, "returnCode", returnCode)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `update depository_prenotes set return_code = ? where depository_id = ? and trace_number = ? and deleted_at is null;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, returnCode, id, traceNumber)
+	return err
+}
+
+// GetPrenoteCursor returns a PrenoteCursor for iterating through prenotes in ascending order (by CreatedAt)
+// beginning at the start of the current day.
+func (r *SQLDepositoryRepo) GetPrenoteCursor(batchSize int) *PrenoteCursor {
+	now := time.Now()
+	return &PrenoteCursor{
+		BatchSize: batchSize,
+		DepRepo:   r,
+		newerThan: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// PrenoteCursor allows for iterating through prenotes in ascending order (by CreatedAt)
+// to merge into files uploaded to an ODFI.
+type PrenoteCursor struct {
+	BatchSize int
+
+	DepRepo *SQLDepositoryRepo
+
+	// newerThan represents the minimum (oldest) created_at value to return in the batch.
+	newerThan time.Time
+}
+
+type UploadablePrenote struct {
+	DepositoryID string
+	UserID       string
+	FileID       string
+	TraceNumber  string
+	CreatedAt    time.Time
+}
+
+// Next returns a slice of prenotes from the current day which haven't been merged.
+func (cur *PrenoteCursor) Next(ctx context.Context) ([]UploadablePrenote, error) {
+	query := `select depository_id, user_id, file_id, trace_number, created_at from depository_prenotes
+where deleted_at is null and merged_filename is null and created_at > ? order by created_at asc limit ?`
+	stmt, err := cur.DepRepo.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("prenoteCursor.Next: prepare: %v", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, cur.newerThan, cur.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("prenoteCursor.Next: query: %v", err)
+	}
+	defer rows.Close()
+
+	max := cur.newerThan
+	var prenotes []UploadablePrenote
+	for rows.Next() {
+		var p UploadablePrenote
+		if err := rows.Scan(&p.DepositoryID, &p.UserID, &p.FileID, &p.TraceNumber, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("prenoteCursor.Next: scan: %v", err)
+		}
+		if p.CreatedAt.After(max) {
+			max = p.CreatedAt // advance to latest timestamp
+		}
+		prenotes = append(prenotes, p)
+	}
+	cur.newerThan = max
+	return prenotes, rows.Err()
+}
+
+// MarkPrenoteAsMerged will set the merged_filename and merged_at on a prenote so it isn't merged into
+// multiple files and its waiting period can start.
+func (r *SQLDepositoryRepo) MarkPrenoteAsMerged(ctx context.Context, filename string, p UploadablePrenote) (err error) {
+	ctx, span := tracing.Start(ctx, "depositoryRepo.MarkPrenoteAsMerged", "depositoryID", p.DepositoryID, "filename", filename)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `update depository_prenotes set merged_filename = ?, merged_at = ?
+where depository_id = ? and trace_number = ? and merged_filename is null and deleted_at is null`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("MarkPrenoteAsMerged: filename=%s: %v", filename, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, filename, time.Now(), p.DepositoryID, p.TraceNumber)
+	return err
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestPrenotes__repository(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLDepositoryRepo) {
+		depID, userID := id.Depository(base.ID()), id.User(base.ID())
+
+		if p, err := repo.getLatestPrenote(context.Background(), depID, userID); p != nil || err != nil {
+			t.Fatalf("prenote=%#v error=%v", p, err)
+		}
+
+		first := &Prenote{FileID: base.ID(), TraceNumber: "121042880000001", CreatedAt: time.Now().Add(-1 * time.Minute)}
+		second := &Prenote{FileID: base.ID(), TraceNumber: "121042880000002", CreatedAt: time.Now()}
+		for _, p := range []*Prenote{first, second} {
+			if err := repo.createPrenote(context.Background(), depID, userID, p); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// our cursor returns both prenotes until they're merged
+		cur := repo.GetPrenoteCursor(5)
+		prenotes, err := cur.Next(context.Background())
+		if err != nil || len(prenotes) != 2 {
+			t.Fatalf("got %d prenotes: %v", len(prenotes), err)
+		}
+		if prenotes[0].TraceNumber != first.TraceNumber || prenotes[0].UserID != string(userID) {
+			t.Errorf("unexpected prenote: %#v", prenotes[0])
+		}
+		if err := repo.MarkPrenoteAsMerged(context.Background(), "filename", prenotes[1]); err != nil {
+			t.Fatal(err)
+		}
+		if prenotes, err := repo.GetPrenoteCursor(5).Next(context.Background()); err != nil || len(prenotes) != 1 {
+			t.Errorf("got %d prenotes: %v", len(prenotes), err)
+		}
+
+		latest, err := repo.getLatestPrenote(context.Background(), depID, userID)
+		if err != nil || latest == nil {
+			t.Fatalf("prenote=%#v error=%v", latest, err)
+		}
+		if latest.FileID != second.FileID || latest.MergedAt == nil || latest.ReturnCode != "" {
+			t.Errorf("unexpected prenote: %#v", latest)
+		}
+
+		// returns
+		if p, err := repo.LookupPrenoteFromReturn(context.Background(), depID, "121042880000009"); p != nil || err != nil {
+			t.Errorf("prenote=%#v error=%v", p, err)
+		}
+		p, err := repo.LookupPrenoteFromReturn(context.Background(), depID, second.TraceNumber)
+		if err != nil || p == nil || p.FileID != second.FileID {
+			t.Fatalf("prenote=%#v error=%v", p, err)
+		}
+		if err := repo.SetPrenoteReturnCode(context.Background(), depID, second.TraceNumber, "R03"); err != nil {
+			t.Fatal(err)
+		}
+		if latest, _ := repo.getLatestPrenote(context.Background(), depID, userID); latest.ReturnCode != "R03" {
+			t.Errorf("unexpected prenote: %#v", latest)
+		}
+	}
+
+	keeper := secrets.TestStringKeeper(t)
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewDepositoryRepo(log.NewNopLogger(), mysqlDB.DB, keeper))
+}
+
+func TestPrenotes__convertToPrenote(t *testing.T) {
+	keeper := secrets.TestStringKeeper(t)
+	num, _ := keeper.EncryptString("151")
+
+	odfiAccount := makeTestODFIAccount()
+	odfiAccount.keeper = keeper
+	orig, origDep := odfiAccount.metadata()
+
+	amount, _ := NewAmount("USD", "0.00")
+	for _, accountType := range []AccountType{Checking, Savings} {
+		dep := &Depository{ID: id.Depository(base.ID()), Type: accountType, RoutingNumber: "231380104", EncryptedAccountNumber: num, keeper: keeper}
+		xfer := &Transfer{
+			Type:                   PushTransfer,
+			Amount:                 *amount,
+			Description:            "PRENOTE",
+			StandardEntryClassCode: ach.PPD,
+			Status:                 TransferPending,
+			TraceNumber:            "121042880000001",
+		}
+		rec := &Receiver{ID: ReceiverID(base.ID()), Status: ReceiverVerified, Metadata: "john doe"}
+		file, err := constructACHFile(base.ID(), base.ID(), id.User(base.ID()), xfer, rec, dep, orig, origDep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := convertToPrenote(file, dep); err != nil {
+			t.Fatal(err)
+		}
+
+		ed := file.Batches[0].GetEntries()[0]
+		switch accountType {
+		case Checking:
+			if ed.TransactionCode != ach.CheckingPrenoteCredit {
+				t.Errorf("TransactionCode=%d", ed.TransactionCode)
+			}
+		case Savings:
+			if ed.TransactionCode != ach.SavingsPrenoteCredit {
+				t.Errorf("TransactionCode=%d", ed.TransactionCode)
+			}
+		}
+	}
+
+	if err := convertToPrenote(nil, &Depository{}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+// Package ratelimit throttles requests to the HTTP server with token buckets for each user and route.
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/prometheus"
+	"github.com/gorilla/mux"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// sweepInterval is how often buckets which have refilled are removed from the store.
+	sweepInterval = time.Minute
+
+	requestsThrottled = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: "http_requests_throttled",
+		Help: "Counter of HTTP requests rejected for going over a rate limit",
+	}, []string{"route", "limit"})
+
+	storeErrors = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: "rate_limit_store_errors",
+		Help: "Counter of rate limit checks which failed and let the request through",
+	}, nil)
+)
+
+// Store keeps token buckets.
+type Store interface {
+	// take removes a token from every bucket, creating full buckets when there aren't any. Nothing is
+	// taken when a bucket is empty and the index of the first empty bucket is returned along with how
+	// long it takes for a token to be added to it. The index is -1 when tokens were taken.
+	take(buckets []limitedBucket, now time.Time) (int, time.Duration, error)
+
+	// sweep removes buckets which haven't been used since before.
+	sweep(before time.Time) error
+}
+
+// limitedBucket is the bucket of one limit which applies to a request.
+type limitedBucket struct {
+	// name is the kind of limit ("route" or "user") for metrics
+	name  string
+	key   string
+	limit config.RateLimit
+}
+
+// refill returns the tokens in a bucket which had tokens at updated, after taking one if it can.
+// retryAfter is how long until a token is available when the bucket is empty.
+func refill(tokens float64, updated time.Time, limit config.RateLimit, now time.Time) (remaining float64, ok bool, retryAfter time.Duration) {
+	if elapsed := now.Sub(updated).Seconds(); elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+	}
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+	return tokens, false, time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+}
+
+// Limiter is HTTP middleware which rejects requests over a user's limits with a 429 response.
+type Limiter struct {
+	logger log.Logger
+	store  Store
+
+	user   config.RateLimit
+	routes map[string]config.RateLimit
+
+	// idle is how long it takes the slowest bucket to refill, buckets unused for longer are swept
+	idle time.Duration
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewLimiter returns a Limiter for the limits in cfg, keeping buckets in store.
+func NewLimiter(logger log.Logger, cfg *config.RateLimitConfig, store Store) *Limiter {
+	l := &Limiter{
+		logger:    logger,
+		store:     store,
+		user:      cfg.User,
+		routes:    make(map[string]config.RateLimit),
+		lastSweep: time.Now(),
+	}
+	l.idle = fillTime(cfg.User)
+	for route, limit := range cfg.Routes {
+		if limit.Rate > 0 {
+			l.routes[route] = limit
+		}
+		if d := fillTime(limit); d > l.idle {
+			l.idle = d
+		}
+	}
+	return l
+}
+
+func fillTime(limit config.RateLimit) time.Duration {
+	if limit.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+}
+
+// Handler wraps next and can be added to a router with its Use method after authentication.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeName(r)
+		who := requester(r)
+		now := time.Now()
+
+		var buckets []limitedBucket
+		if limit, exists := l.routes[route]; exists {
+			buckets = append(buckets, limitedBucket{name: "route", key: fmt.Sprintf("route:%s:%s", route, who), limit: limit})
+		}
+		if l.user.Rate > 0 {
+			buckets = append(buckets, limitedBucket{name: "user", key: "user:" + who, limit: l.user})
+		}
+		if len(buckets) > 0 && !l.allow(w, r, route, buckets, now) {
+			return
+		}
+		l.maybeSweep(now)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from each bucket and writes a 429 response when any of them are empty, in which
+// case no tokens are taken. Requests are allowed when the store fails so an outage doesn't block every request.
+func (l *Limiter) allow(w http.ResponseWriter, r *http.Request, route string, buckets []limitedBucket, now time.Time) bool {
+	empty, retryAfter, err := l.store.take(buckets, now)
+	if err != nil {
+		storeErrors.Add(1)
+		l.logger.Log("ratelimit", fmt.Sprintf("problem checking rate limits for %s: %v", route, err), "requestID", moovhttp.GetRequestID(r))
+		return true
+	}
+	if empty < 0 {
+		return true
+	}
+	requestsThrottled.With("route", route, "limit", buckets[empty].name).Add(1)
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": fmt.Sprintf("rate limit exceeded, retry after %d seconds", seconds),
+	})
+	return false
+}
+
+func (l *Limiter) maybeSweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	go func() {
+		if err := l.store.sweep(now.Add(-1 * l.idle)); err != nil {
+			l.logger.Log("ratelimit", fmt.Sprintf("problem sweeping rate limits: %v", err))
+		}
+	}()
+}
+
+// routeName returns the method and path template of the route r matched, e.g. "POST /transfers"
+func routeName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return r.Method + " " + tpl
+		}
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// requester returns the authenticated user, or the client's IP address without one.
+func requester(r *http.Request) string {
+	if userID := moovhttp.GetUserID(r); userID != "" {
+		return userID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewMemoryStore returns a Store which keeps buckets for this paygate instance.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[string]*bucket)}
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func (s *memoryStore) take(buckets []limitedBucket, now time.Time) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining := make([]float64, len(buckets))
+	for i := range buckets {
+		tokens, updated := float64(buckets[i].limit.Burst), now
+		if b, exists := s.buckets[buckets[i].key]; exists {
+			tokens, updated = b.tokens, b.updated
+		}
+		left, ok, retryAfter := refill(tokens, updated, buckets[i].limit, now)
+		if !ok {
+			return i, retryAfter, nil
+		}
+		remaining[i] = left
+	}
+	for i := range buckets {
+		s.buckets[buckets[i].key] = &bucket{tokens: remaining[i], updated: now}
+	}
+	return -1, 0, nil
+}
+
+func (s *memoryStore) sweep(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range s.buckets {
+		if b.updated.Before(before) {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
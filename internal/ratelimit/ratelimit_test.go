@@ -0,0 +1,188 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestRefill(t *testing.T) {
+	limit := config.RateLimit{Rate: 2, Burst: 4}
+	now := time.Now()
+
+	// full buckets give a token
+	remaining, ok, _ := refill(4, now, limit, now)
+	if !ok || remaining != 3 {
+		t.Errorf("remaining=%v ok=%v", remaining, ok)
+	}
+
+	// empty buckets say when a token will be added
+	remaining, ok, retryAfter := refill(0.5, now, limit, now)
+	if ok || remaining != 0.5 || retryAfter != 250*time.Millisecond {
+		t.Errorf("remaining=%v ok=%v retryAfter=%v", remaining, ok, retryAfter)
+	}
+
+	// buckets refill over time, up to their burst
+	if remaining, ok, _ := refill(0, now.Add(-1*time.Second), limit, now); !ok || remaining != 1 {
+		t.Errorf("remaining=%v ok=%v", remaining, ok)
+	}
+	if remaining, ok, _ := refill(0, now.Add(-1*time.Hour), limit, now); !ok || remaining != 3 {
+		t.Errorf("remaining=%v ok=%v", remaining, ok)
+	}
+}
+
+func testRouter(l *Limiter) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(l.Handler)
+	router.Methods("POST").Path("/transfers").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Methods("GET").Path("/transfers/{transferId}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return router
+}
+
+func TestLimiter(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		User: config.RateLimit{Rate: 0.001, Burst: 5},
+		Routes: map[string]config.RateLimit{
+			"POST /transfers": {Rate: 0.001, Burst: 2},
+		},
+	}
+	router := testRouter(NewLimiter(log.NewNopLogger(), cfg, NewMemoryStore()))
+
+	do := func(method, path, userID string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		if userID != "" {
+			req.Header.Set("X-User-Id", userID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// the route limit is hit first
+	for i := 0; i < 2; i++ {
+		if w := do("POST", "/transfers", "alice"); w.Code != http.StatusOK {
+			t.Errorf("bogus HTTP status: %d", w.Code)
+		}
+	}
+	w := do("POST", "/transfers", "alice")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// other routes use what's left of alice's user limit, where each transferId shares a bucket
+	for i := 0; i < 3; i++ {
+		if w := do("GET", "/transfers/"+string(rune('a'+i)), "alice"); w.Code != http.StatusOK {
+			t.Errorf("bogus HTTP status: %d", w.Code)
+		}
+	}
+	if w := do("GET", "/transfers/d", "alice"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+
+	// other users have their own buckets
+	if w := do("POST", "/transfers", "bob"); w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+
+	// requests without a user are limited by IP address
+	for i := 0; i < 2; i++ {
+		do("POST", "/transfers", "")
+	}
+	if w := do("POST", "/transfers", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+}
+
+func TestLimiter__disabled(t *testing.T) {
+	router := testRouter(NewLimiter(log.NewNopLogger(), &config.RateLimitConfig{}, NewMemoryStore()))
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/transfers", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus HTTP status: %d", w.Code)
+		}
+	}
+}
+
+type failingStore struct{}
+
+func (s *failingStore) take(buckets []limitedBucket, now time.Time) (int, time.Duration, error) {
+	return -1, 0, errors.New("bad thing")
+}
+
+func (s *failingStore) sweep(before time.Time) error {
+	return errors.New("bad thing")
+}
+
+func TestLimiter__storeErrors(t *testing.T) {
+	cfg := &config.RateLimitConfig{User: config.RateLimit{Rate: 1, Burst: 1}}
+	router := testRouter(NewLimiter(log.NewNopLogger(), cfg, &failingStore{}))
+
+	// requests are allowed when the store fails
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/transfers", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("bogus HTTP status: %d", w.Code)
+		}
+	}
+}
+
+func TestMemoryStore__sweep(t *testing.T) {
+	store := NewMemoryStore().(*memoryStore)
+	limit := config.RateLimit{Rate: 1, Burst: 1}
+	now := time.Now()
+
+	store.take([]limitedBucket{{key: "old", limit: limit}}, now.Add(-1*time.Hour))
+	store.take([]limitedBucket{{key: "new", limit: limit}}, now)
+	if err := store.sweep(now.Add(-1 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := store.buckets["old"]; exists || len(store.buckets) != 1 {
+		t.Errorf("unexpected buckets: %#v", store.buckets)
+	}
+}
+
+// testTakeAll checks tokens are only taken when every bucket has one.
+func testTakeAll(t *testing.T, store Store) {
+	t.Helper()
+
+	now := time.Now()
+	route := limitedBucket{name: "route", key: "route:POST /transfers:carol", limit: config.RateLimit{Rate: 1, Burst: 2}}
+	user := limitedBucket{name: "user", key: "user:carol", limit: config.RateLimit{Rate: 1, Burst: 1}}
+
+	if empty, _, err := store.take([]limitedBucket{route, user}, now); empty != -1 || err != nil {
+		t.Fatalf("empty=%d error=%v", empty, err)
+	}
+	// the user bucket is empty, so the route's token isn't taken
+	empty, retryAfter, err := store.take([]limitedBucket{route, user}, now)
+	if empty != 1 || retryAfter != time.Second || err != nil {
+		t.Errorf("empty=%d retryAfter=%v error=%v", empty, retryAfter, err)
+	}
+	if empty, _, err := store.take([]limitedBucket{route}, now); empty != -1 || err != nil {
+		t.Errorf("empty=%d error=%v", empty, err)
+	}
+	if empty, _, err := store.take([]limitedBucket{route}, now); empty != 0 || err != nil {
+		t.Errorf("empty=%d error=%v", empty, err)
+	}
+}
+
+func TestMemoryStore__takeAll(t *testing.T) {
+	testTakeAll(t, NewMemoryStore())
+}
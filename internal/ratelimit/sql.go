@@ -0,0 +1,120 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moov-io/paygate/internal/database"
+)
+
+// maxAttempts is how many times a bucket is re-read after another instance updates it first.
+const maxAttempts = 5
+
+// NewSQLStore returns a Store which keeps buckets in the database, so each paygate instance
+// shares the same limits. Instances need their clocks to be in sync.
+func NewSQLStore(db *sql.DB) Store {
+	return &sqlStore{db: db}
+}
+
+type sqlStore struct {
+	db *sql.DB
+}
+
+// errBucketChanged is returned when another instance updated a bucket after it was read.
+var errBucketChanged = errors.New("bucket was updated by another instance")
+
+// take reads the buckets and only writes them back if nobody else has updated them since, retrying
+// otherwise. This avoids row locks, which aren't supported the same way by every database.
+func (s *sqlStore) take(buckets []limitedBucket, now time.Time) (int, time.Duration, error) {
+	for i := 0; i < maxAttempts; i++ {
+		empty, retryAfter, err := s.tryTake(buckets, now)
+		if err == errBucketChanged {
+			continue // updated by another instance
+		}
+		return empty, retryAfter, err
+	}
+	return -1, 0, fmt.Errorf("buckets were updated by other instances %d times", maxAttempts)
+}
+
+// tryTake takes a token from every bucket in one transaction, so either every bucket or none of them
+// are written.
+func (s *sqlStore) tryTake(buckets []limitedBucket, now time.Time) (int, time.Duration, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return -1, 0, err
+	}
+
+	type write struct {
+		remaining float64
+		updated   int64
+		exists    bool
+	}
+	writes := make([]write, len(buckets))
+	for i := range buckets {
+		tokens, updated, err := read(tx, buckets[i].key)
+		exists := err == nil
+		if err == sql.ErrNoRows {
+			tokens, updated = float64(buckets[i].limit.Burst), now.UnixNano()
+		} else if err != nil {
+			return -1, 0, fmt.Errorf("%v (rollback=%v)", err, tx.Rollback())
+		}
+		remaining, ok, retryAfter := refill(tokens, time.Unix(0, updated), buckets[i].limit, now)
+		if !ok {
+			return i, retryAfter, tx.Rollback()
+		}
+		writes[i] = write{remaining: remaining, updated: updated, exists: exists}
+	}
+
+	for i := range buckets {
+		if !writes[i].exists {
+			query := `insert into rate_limit_buckets (bucket_key, tokens, updated_at) values (?, ?, ?);`
+			if _, err := tx.Exec(query, buckets[i].key, writes[i].remaining, now.UnixNano()); err != nil {
+				if database.UniqueViolation(err) {
+					err = errBucketChanged // created by another instance
+				}
+				tx.Rollback()
+				return -1, 0, err
+			}
+			continue
+		}
+		query := `update rate_limit_buckets set tokens = ?, updated_at = ? where bucket_key = ? and updated_at = ?;`
+		res, err := tx.Exec(query, writes[i].remaining, now.UnixNano(), buckets[i].key, writes[i].updated)
+		if err != nil {
+			return -1, 0, fmt.Errorf("%v (rollback=%v)", err, tx.Rollback())
+		}
+		if n, _ := res.RowsAffected(); n != 1 {
+			tx.Rollback()
+			return -1, 0, errBucketChanged
+		}
+	}
+	return -1, 0, tx.Commit()
+}
+
+func read(tx *sql.Tx, key string) (float64, int64, error) {
+	query := `select tokens, updated_at from rate_limit_buckets where bucket_key = ? limit 1;`
+	var tokens float64
+	var updated int64
+	err := tx.QueryRow(query, key).Scan(&tokens, &updated)
+	return tokens, updated, err
+}
+
+func (s *sqlStore) exec(query string, args ...interface{}) error {
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(args...)
+	return err
+}
+
+func (s *sqlStore) sweep(before time.Time) error {
+	return s.exec(`delete from rate_limit_buckets where updated_at < ?;`, before.UnixNano())
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/database"
+)
+
+func TestSQLStore(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, db *sql.DB) {
+		store := NewSQLStore(db)
+		limit := config.RateLimit{Rate: 1, Burst: 2}
+		now := time.Now()
+		take := func(store Store, key string, now time.Time) (bool, time.Duration, error) {
+			empty, retryAfter, err := store.take([]limitedBucket{{key: key, limit: limit}}, now)
+			return empty < 0, retryAfter, err
+		}
+
+		for i := 0; i < 2; i++ {
+			if ok, _, err := take(store, "user:alice", now); !ok || err != nil {
+				t.Fatalf("ok=%v error=%v", ok, err)
+			}
+		}
+		ok, retryAfter, err := take(store, "user:alice", now)
+		if ok || err != nil || retryAfter != time.Second {
+			t.Errorf("ok=%v retryAfter=%v error=%v", ok, retryAfter, err)
+		}
+
+		// a second later there's another token
+		if ok, _, err := take(store, "user:alice", now.Add(time.Second)); !ok || err != nil {
+			t.Errorf("ok=%v error=%v", ok, err)
+		}
+
+		// another instance sharing the database sees the same bucket
+		other := NewSQLStore(db)
+		if ok, _, err := take(other, "user:alice", now.Add(time.Second)); ok || err != nil {
+			t.Errorf("ok=%v error=%v", ok, err)
+		}
+		if ok, _, err := take(other, "user:bob", now); !ok || err != nil {
+			t.Errorf("ok=%v error=%v", ok, err)
+		}
+
+		// sweep buckets which haven't been used
+		if err := store.sweep(now.Add(time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		if err := db.QueryRow(`select count(*) from rate_limit_buckets;`).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("got %d buckets", count)
+		}
+
+		testTakeAll(t, store)
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, sqliteDB.DB)
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, mysqlDB.DB)
+}
@@ -5,6 +5,7 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -16,10 +17,14 @@ import (
 
 	"github.com/moov-io/base"
 	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/customers"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/internal/kyc"
 	"github.com/moov-io/paygate/internal/route"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/internal/tracing"
+	"github.com/moov-io/paygate/internal/util"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
@@ -39,11 +44,12 @@ type Receiver struct {
 	// Email address associated to Receiver
 	Email string `json:"email"`
 
-	// DefaultDepository is the Depository associated to this Receiver.
+	// DefaultDepository is the Depository associated to this Receiver. Other Depositories can be
+	// linked to a Receiver with PUT /receivers/{receiverId}/depositories/{depositoryId}
 	DefaultDepository id.Depository `json:"defaultDepository"`
 
-	// Status defines the current state of the Receiver
-	// TODO(adam): how does this status change? micro-deposit? email? both?
+	// Status defines the current state of the Receiver. Receivers start as unverified and are
+	// verified by confirming a token emailed to them, see POST /receivers/{receiverId}/verification/email
 	Status ReceiverStatus `json:"status"`
 
 	// BirthDate is an optional value required for Know Your Customer (KYC) validation of this Originator
@@ -92,6 +98,16 @@ func (c *Receiver) validate() error {
 	return c.Status.validate()
 }
 
+// masked returns a copy of the Receiver with its Email masked for HTTP responses.
+func (c *Receiver) masked() *Receiver {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.Email = util.MaskEmail(c.Email)
+	return &out
+}
+
 type ReceiverStatus string
 
 const (
@@ -141,12 +157,17 @@ func (r receiverRequest) missingFields() error {
 }
 
 func AddReceiverRoutes(logger log.Logger, r *mux.Router, customersClient customers.Client, depositoryRepo DepositoryRepository, receiverRepo receiverRepository) {
-	r.Methods("GET").Path("/receivers").HandlerFunc(getUserReceivers(logger, receiverRepo))
-	r.Methods("POST").Path("/receivers").HandlerFunc(createUserReceiver(logger, customersClient, depositoryRepo, receiverRepo))
+	r.Methods("GET").Path("/receivers").HandlerFunc(auth.Require(auth.ReceiversRead, getUserReceivers(logger, receiverRepo)))
+	r.Methods("POST").Path("/receivers").HandlerFunc(auth.Require(auth.ReceiversWrite, createUserReceiver(logger, customersClient, depositoryRepo, receiverRepo)))
+
+	r.Methods("GET").Path("/receivers/{receiverId}").HandlerFunc(auth.Require(auth.ReceiversRead, getUserReceiver(logger, receiverRepo)))
+	r.Methods("PATCH").Path("/receivers/{receiverId}").HandlerFunc(auth.Require(auth.ReceiversWrite, updateUserReceiver(logger, depositoryRepo, receiverRepo)))
+	r.Methods("DELETE").Path("/receivers/{receiverId}").HandlerFunc(auth.Require(auth.ReceiversWrite, deleteUserReceiver(logger, receiverRepo)))
 
-	r.Methods("GET").Path("/receivers/{receiverId}").HandlerFunc(getUserReceiver(logger, receiverRepo))
-	r.Methods("PATCH").Path("/receivers/{receiverId}").HandlerFunc(updateUserReceiver(logger, depositoryRepo, receiverRepo))
-	r.Methods("DELETE").Path("/receivers/{receiverId}").HandlerFunc(deleteUserReceiver(logger, receiverRepo))
+	r.Methods("GET").Path("/receivers/{receiverId}/depositories").HandlerFunc(auth.Require(auth.ReceiversRead, getReceiverDepositories(logger, depositoryRepo, receiverRepo)))
+	r.Methods("GET").Path("/receivers/{receiverId}/depositories/{depositoryId}").HandlerFunc(auth.Require(auth.ReceiversRead, getReceiverDepository(logger, depositoryRepo, receiverRepo)))
+	r.Methods("PUT").Path("/receivers/{receiverId}/depositories/{depositoryId}").HandlerFunc(auth.Require(auth.ReceiversWrite, linkReceiverDepository(logger, depositoryRepo, receiverRepo)))
+	r.Methods("DELETE").Path("/receivers/{receiverId}/depositories/{depositoryId}").HandlerFunc(auth.Require(auth.ReceiversWrite, unlinkReceiverDepository(logger, receiverRepo)))
 }
 
 func getUserReceivers(logger log.Logger, receiverRepo receiverRepository) http.HandlerFunc {
@@ -156,11 +177,22 @@ func getUserReceivers(logger log.Logger, receiverRepo receiverRepository) http.H
 			return
 		}
 
-		receivers, err := receiverRepo.getUserReceivers(responder.XUserID)
+		var receivers []*Receiver
+		var err error
+		if email := r.URL.Query().Get("email"); email != "" {
+			receivers, err = receiverRepo.getUserReceiversByEmail(r.Context(), responder.XUserID, email)
+		} else {
+			receivers, err = receiverRepo.getUserReceivers(r.Context(), responder.XUserID)
+		}
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
 		}
+		if !unmaskPII(r) {
+			for i := range receivers {
+				receivers[i] = receivers[i].masked()
+			}
+		}
 
 		responder.Respond(func(w http.ResponseWriter) {
 			w.WriteHeader(http.StatusOK)
@@ -184,7 +216,7 @@ func readReceiverRequest(r *http.Request) (receiverRequest, error) {
 func parseAndValidateEmail(raw string) (string, error) {
 	addr, err := mail.ParseAddress(raw)
 	if err != nil {
-		return "", fmt.Errorf("error parsing '%s': %v", raw, err)
+		return "", fmt.Errorf("error parsing '%s': %v", util.MaskEmail(raw), err)
 	}
 	return addr.Address, nil
 }
@@ -203,7 +235,7 @@ func createUserReceiver(logger log.Logger, customersClient customers.Client, dep
 			return
 		}
 
-		dep, err := depositoryRepo.GetUserDepository(req.DefaultDepository, responder.XUserID)
+		dep, err := depositoryRepo.GetUserDepository(r.Context(), req.DefaultDepository, responder.XUserID)
 		if err != nil || dep == nil {
 			responder.Log("receivers", "depository not found")
 			responder.Problem(errors.New("depository not found"))
@@ -234,7 +266,7 @@ func createUserReceiver(logger log.Logger, customersClient customers.Client, dep
 
 		// Add the Receiver into our Customers service
 		if customersClient != nil {
-			customer, err := customersClient.Create(&customers.Request{
+			customer, err := customersClient.Create(r.Context(), &customers.Request{
 				Name:      dep.Holder,
 				BirthDate: req.BirthDate,
 				Addresses: kyc.ConvertAddress(req.Address),
@@ -253,12 +285,15 @@ func createUserReceiver(logger log.Logger, customersClient customers.Client, dep
 			responder.Log("receivers", "skipped adding receiver into Customers")
 		}
 
-		if err := receiverRepo.upsertUserReceiver(responder.XUserID, receiver); err != nil {
+		if err := receiverRepo.upsertUserReceiver(r.Context(), responder.XUserID, receiver); err != nil {
 			err = fmt.Errorf("creating receiver=%s, user_id=%s: %v", receiver.ID, responder.XUserID, err)
 			responder.Log("receivers", fmt.Errorf("error inserting Receiver: %v", err))
 			responder.Problem(err)
 			return
 		}
+		if !unmaskPII(r) {
+			receiver = receiver.masked()
+		}
 
 		responder.Respond(func(w http.ResponseWriter) {
 			w.WriteHeader(http.StatusOK)
@@ -280,11 +315,14 @@ func getUserReceiver(logger log.Logger, receiverRepo receiverRepository) http.Ha
 			return
 		}
 
-		receiver, err := receiverRepo.getUserReceiver(receiverID, responder.XUserID)
+		receiver, err := receiverRepo.getUserReceiver(r.Context(), receiverID, responder.XUserID)
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
 		}
+		if !unmaskPII(r) {
+			receiver = receiver.masked()
+		}
 
 		responder.Respond(func(w http.ResponseWriter) {
 			w.WriteHeader(http.StatusOK)
@@ -312,7 +350,7 @@ func updateUserReceiver(logger log.Logger, depRepo DepositoryRepository, receive
 			return
 		}
 
-		receiver, err := receiverRepo.getUserReceiver(receiverID, responder.XUserID)
+		receiver, err := receiverRepo.getUserReceiver(r.Context(), receiverID, responder.XUserID)
 		if receiver == nil || err != nil {
 			responder.Log("receivers", fmt.Sprintf("problem getting receiver='%s': %v", receiverID, err))
 			responder.Problem(err)
@@ -320,7 +358,7 @@ func updateUserReceiver(logger log.Logger, depRepo DepositoryRepository, receive
 		}
 		if wrapper.DefaultDepository != "" {
 			// Verify the user controls the requested Depository
-			dep, err := depRepo.GetUserDepository(wrapper.DefaultDepository, responder.XUserID)
+			dep, err := depRepo.GetUserDepository(r.Context(), wrapper.DefaultDepository, responder.XUserID)
 			if err != nil || dep == nil {
 				responder.Log("receivers", "depository doesn't belong to user")
 				responder.Problem(errors.New("depository not found"))
@@ -340,11 +378,14 @@ func updateUserReceiver(logger log.Logger, depRepo DepositoryRepository, receive
 		}
 
 		// Perform update
-		if err := receiverRepo.upsertUserReceiver(responder.XUserID, receiver); err != nil {
+		if err := receiverRepo.upsertUserReceiver(r.Context(), responder.XUserID, receiver); err != nil {
 			responder.Log("receivers", fmt.Sprintf("problem upserting receiver=%s: %v", receiver.ID, err))
 			responder.Problem(err)
 			return
 		}
+		if !unmaskPII(r) {
+			receiver = receiver.masked()
+		}
 
 		responder.Respond(func(w http.ResponseWriter) {
 			w.WriteHeader(http.StatusOK)
@@ -364,7 +405,7 @@ func deleteUserReceiver(logger log.Logger, receiverRepo receiverRepository) http
 			w.WriteHeader(http.StatusNotFound)
 			return
 		} else {
-			if err := receiverRepo.deleteUserReceiver(receiverID, responder.XUserID); err != nil {
+			if err := receiverRepo.deleteUserReceiver(r.Context(), receiverID, responder.XUserID); err != nil {
 				responder.Problem(err)
 				return
 			}
@@ -377,6 +418,178 @@ func deleteUserReceiver(logger log.Logger, receiverRepo receiverRepository) http
 	}
 }
 
+var errReceiverNotFound = errors.New("receiver not found")
+
+// getLinkedReceiver returns the Receiver from the request path and the IDs of each Depository linked to it.
+func getLinkedReceiver(responder *route.Responder, r *http.Request, receiverRepo receiverRepository) (*Receiver, []id.Depository, error) {
+	receiverID := getReceiverID(r)
+	if receiverID == "" {
+		return nil, nil, errReceiverNotFound
+	}
+	receiver, err := receiverRepo.getUserReceiver(r.Context(), receiverID, responder.XUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if receiver == nil {
+		return nil, nil, errReceiverNotFound
+	}
+	depIDs, err := receiverRepo.getReceiverDepositories(r.Context(), receiver.ID, responder.XUserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem reading receiver=%s depositories: %v", receiver.ID, err)
+	}
+	return receiver, depIDs, nil
+}
+
+// problemWithLinkedReceiver responds with a 404 when the Receiver doesn't exist, like our other lookups.
+func problemWithLinkedReceiver(responder *route.Responder, r *http.Request, err error) {
+	responder.Log("receivers", fmt.Sprintf("receiver=%s: %v", getReceiverID(r), err))
+	if err == errReceiverNotFound {
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+		return
+	}
+	responder.Problem(err)
+}
+
+func containsDepository(depIDs []id.Depository, depID id.Depository) bool {
+	for i := range depIDs {
+		if depIDs[i] == depID {
+			return true
+		}
+	}
+	return false
+}
+
+func getReceiverDepositories(logger log.Logger, depositoryRepo DepositoryRepository, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		_, depIDs, err := getLinkedReceiver(responder, r, receiverRepo)
+		if err != nil {
+			problemWithLinkedReceiver(responder, r, err)
+			return
+		}
+		deps := make([]*Depository, 0, len(depIDs))
+		for i := range depIDs {
+			dep, err := depositoryRepo.GetUserDepository(r.Context(), depIDs[i], responder.XUserID)
+			if err != nil {
+				responder.Problem(err)
+				return
+			}
+			if dep != nil {
+				deps = append(deps, dep) // skip deleted Depositories
+			}
+		}
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(deps)
+		})
+	}
+}
+
+func getReceiverDepository(logger log.Logger, depositoryRepo DepositoryRepository, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		_, depIDs, err := getLinkedReceiver(responder, r, receiverRepo)
+		if err != nil {
+			problemWithLinkedReceiver(responder, r, err)
+			return
+		}
+		depID := GetDepositoryID(r)
+		if !containsDepository(depIDs, depID) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		dep, err := depositoryRepo.GetUserDepository(r.Context(), depID, responder.XUserID)
+		if err != nil {
+			responder.Problem(err)
+			return
+		}
+		if dep == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(dep)
+		})
+	}
+}
+
+func linkReceiverDepository(logger log.Logger, depositoryRepo DepositoryRepository, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		receiver, _, err := getLinkedReceiver(responder, r, receiverRepo)
+		if err != nil {
+			problemWithLinkedReceiver(responder, r, err)
+			return
+		}
+		// Verify the user controls the requested Depository
+		depID := GetDepositoryID(r)
+		dep, err := depositoryRepo.GetUserDepository(r.Context(), depID, responder.XUserID)
+		if err != nil || dep == nil {
+			responder.Log("receivers", fmt.Sprintf("depository=%s doesn't belong to user", depID))
+			responder.Problem(errors.New("depository not found"))
+			return
+		}
+		if err := receiverRepo.linkReceiverDepository(r.Context(), receiver.ID, dep.ID); err != nil {
+			responder.Log("receivers", fmt.Sprintf("problem linking depository=%s to receiver=%s: %v", dep.ID, receiver.ID, err))
+			responder.Problem(err)
+			return
+		}
+		responder.Log("receivers", fmt.Sprintf("linked depository=%s to receiver=%s", dep.ID, receiver.ID))
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(dep)
+		})
+	}
+}
+
+func unlinkReceiverDepository(logger log.Logger, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		receiver, _, err := getLinkedReceiver(responder, r, receiverRepo)
+		if err != nil {
+			problemWithLinkedReceiver(responder, r, err)
+			return
+		}
+		depID := GetDepositoryID(r)
+		if depID == receiver.DefaultDepository {
+			responder.Problem(fmt.Errorf("depository=%s is the default for receiver=%s", depID, receiver.ID))
+			return
+		}
+		if err := receiverRepo.unlinkReceiverDepository(r.Context(), receiver.ID, depID); err != nil {
+			responder.Log("receivers", fmt.Sprintf("problem unlinking depository=%s from receiver=%s: %v", depID, receiver.ID, err))
+			responder.Problem(err)
+			return
+		}
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+}
+
 // getReceiverID extracts the ReceiverID from the incoming request.
 func getReceiverID(r *http.Request) ReceiverID {
 	v := mux.Vars(r)
@@ -388,37 +601,59 @@ func getReceiverID(r *http.Request) ReceiverID {
 }
 
 type receiverRepository interface {
-	getUserReceivers(userID id.User) ([]*Receiver, error)
-	getUserReceiver(id ReceiverID, userID id.User) (*Receiver, error)
+	getUserReceivers(ctx context.Context, userID id.User) ([]*Receiver, error)
+	getUserReceiversByEmail(ctx context.Context, userID id.User, email string) ([]*Receiver, error)
+	getUserReceiver(ctx context.Context, id ReceiverID, userID id.User) (*Receiver, error)
 
-	updateReceiverStatus(id ReceiverID, status ReceiverStatus) error
+	updateReceiverStatus(ctx context.Context, id ReceiverID, status ReceiverStatus) error
 
-	upsertUserReceiver(userID id.User, receiver *Receiver) error
-	deleteUserReceiver(id ReceiverID, userID id.User) error
+	upsertUserReceiver(ctx context.Context, userID id.User, receiver *Receiver) error
+	deleteUserReceiver(ctx context.Context, id ReceiverID, userID id.User) error
+
+	// getReceiverDepositories returns the IDs of every Depository linked to a Receiver, which always
+	// includes its DefaultDepository.
+	getReceiverDepositories(ctx context.Context, id ReceiverID, userID id.User) ([]id.Depository, error)
+	linkReceiverDepository(ctx context.Context, id ReceiverID, depID id.Depository) error
+	unlinkReceiverDepository(ctx context.Context, id ReceiverID, depID id.Depository) error
 }
 
-func NewReceiverRepo(logger log.Logger, db *sql.DB) *SQLReceiverRepo {
-	return &SQLReceiverRepo{log: logger, db: db}
+func NewReceiverRepo(logger log.Logger, db *sql.DB, keeper *secrets.StringKeeper) *SQLReceiverRepo {
+	return &SQLReceiverRepo{log: logger, db: db, keeper: keeper}
 }
 
 type SQLReceiverRepo struct {
 	db  *sql.DB
 	log log.Logger
+
+	// keeper encrypts and decrypts Email
+	keeper *secrets.StringKeeper
 }
 
 func (r *SQLReceiverRepo) Close() error {
 	return r.db.Close()
 }
 
-func (r *SQLReceiverRepo) getUserReceivers(userID id.User) ([]*Receiver, error) {
-	query := `select receiver_id from receivers where user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+func (r *SQLReceiverRepo) getUserReceivers(ctx context.Context, userID id.User) ([]*Receiver, error) {
+	return r.queryUserReceivers(ctx, `select receiver_id from receivers where user_id = ? and deleted_at is null`, userID)
+}
+
+// getUserReceiversByEmail finds Receivers by the hash of their email address as it's stored encrypted.
+func (r *SQLReceiverRepo) getUserReceiversByEmail(ctx context.Context, userID id.User, email string) ([]*Receiver, error) {
+	hashed, err := hashEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryUserReceivers(ctx, `select receiver_id from receivers where user_id = ? and email_hashed = ? and deleted_at is null`, userID, hashed)
+}
+
+func (r *SQLReceiverRepo) queryUserReceivers(ctx context.Context, query string, userID id.User, args ...interface{}) ([]*Receiver, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(userID)
+	rows, err := stmt.QueryContext(ctx, append([]interface{}{userID}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -437,7 +672,7 @@ func (r *SQLReceiverRepo) getUserReceivers(userID id.User) ([]*Receiver, error)
 
 	var receivers []*Receiver
 	for i := range receiverIDs {
-		receiver, err := r.getUserReceiver(ReceiverID(receiverIDs[i]), userID)
+		receiver, err := r.getUserReceiver(ctx, ReceiverID(receiverIDs[i]), userID)
 		if err == nil && receiver != nil && receiver.Email != "" {
 			receivers = append(receivers, receiver)
 		}
@@ -445,65 +680,91 @@ func (r *SQLReceiverRepo) getUserReceivers(userID id.User) ([]*Receiver, error)
 	return receivers, rows.Err()
 }
 
-func (r *SQLReceiverRepo) getUserReceiver(id ReceiverID, userID id.User) (*Receiver, error) {
-	query := `select receiver_id, email, default_depository, customer_id, status, metadata, created_at, last_updated_at
+func (r *SQLReceiverRepo) getUserReceiver(ctx context.Context, id ReceiverID, userID id.User) (*Receiver, error) {
+	query := `select receiver_id, email, email_encrypted, default_depository, customer_id, status, metadata, created_at, last_updated_at
 from receivers
 where receiver_id = ?
 and user_id = ?
 and deleted_at is null
 limit 1`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(id, userID)
+	row := stmt.QueryRowContext(ctx, id, userID)
 
 	var receiver Receiver
-	err = row.Scan(&receiver.ID, &receiver.Email, &receiver.DefaultDepository, &receiver.CustomerID, &receiver.Status, &receiver.Metadata, &receiver.Created.Time, &receiver.Updated.Time)
+	var encrypted string
+	err = row.Scan(&receiver.ID, &receiver.Email, &encrypted, &receiver.DefaultDepository, &receiver.CustomerID, &receiver.Status, &receiver.Metadata, &receiver.Created.Time, &receiver.Updated.Time)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
+	if encrypted != "" {
+		// Rows which haven't been migrated by EncryptStoredPII still have a plaintext Email
+		if receiver.Email, err = r.keeper.DecryptString(encrypted); err != nil {
+			return nil, fmt.Errorf("problem decrypting receiver=%s email: %v", receiver.ID, err)
+		}
+	}
 	if receiver.ID == "" || receiver.Email == "" {
 		return nil, nil // no records found
 	}
 	return &receiver, nil
 }
 
-func (r *SQLReceiverRepo) updateReceiverStatus(id ReceiverID, status ReceiverStatus) error {
+func (r *SQLReceiverRepo) updateReceiverStatus(ctx context.Context, id ReceiverID, status ReceiverStatus) (err error) {
+	ctx, span := tracing.Start(ctx, "receiverRepo.updateReceiverStatus", "receiverID", id, "status", status)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update receivers set status = ?, last_updated_at = ? where receiver_id = ? and deleted_at is null;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.Exec(status, time.Now(), id); err != nil {
+	if _, err := stmt.ExecContext(ctx, status, time.Now(), id); err != nil {
 		return fmt.Errorf("error updating receiver=%s: %v", id, err)
 	}
 	return nil
 }
 
-func (r *SQLReceiverRepo) upsertUserReceiver(userID id.User, receiver *Receiver) error {
-	tx, err := r.db.Begin()
+func (r *SQLReceiverRepo) upsertUserReceiver(ctx context.Context, userID id.User, receiver *Receiver) (err error) {
+	ctx, span := tracing.Start(ctx, "receiverRepo.upsertUserReceiver", "receiverID", receiver.ID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	encrypted, err := r.keeper.EncryptString(receiver.Email)
+	if err != nil {
+		return fmt.Errorf("upsertUserReceiver: problem encrypting email: %v", err)
+	}
+	hashed, err := hashEmail(receiver.Email)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
 	receiver.Updated = base.NewTime(time.Now().Truncate(1 * time.Second))
 
-	query := `insert into receivers (receiver_id, user_id, email, default_depository, customer_id, status, metadata, created_at, last_updated_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?);`
-	stmt, err := r.db.Prepare(query)
+	query := `insert into receivers (receiver_id, user_id, email, email_encrypted, email_hashed, default_depository, customer_id, status, metadata, created_at, last_updated_at) values (?, ?, '', ?, ?, ?, ?, ?, ?, ?, ?);`
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("upsertUserReceiver: prepare err=%v: rollback=%v", err, tx.Rollback())
 	}
 	defer stmt.Close()
 
-	res, err := stmt.Exec(receiver.ID, userID, receiver.Email, receiver.DefaultDepository, receiver.CustomerID, receiver.Status, receiver.Metadata, receiver.Created.Time, receiver.Updated.Time)
+	res, err := stmt.ExecContext(ctx, receiver.ID, userID, encrypted, hashed, receiver.DefaultDepository, receiver.CustomerID, receiver.Status, receiver.Metadata, receiver.Created.Time, receiver.Updated.Time)
 	stmt.Close()
 	if err != nil && !database.UniqueViolation(err) {
 		return fmt.Errorf("problem upserting receiver=%q, userID=%q error=%v rollback=%v", receiver.ID, userID, err, tx.Rollback())
@@ -512,37 +773,127 @@ func (r *SQLReceiverRepo) upsertUserReceiver(userID id.User, receiver *Receiver)
 	// Check and skip ahead if the insert failed (to database.UniqueViolation)
 	if res != nil {
 		if n, _ := res.RowsAffected(); n != 0 {
-			return tx.Commit() // Receiver was inserted, so cleanup and exit
+			// Receiver was inserted, so link its Depository, cleanup and exit
+			if err := insertReceiverDepository(ctx, tx, receiver.ID, receiver.DefaultDepository); err != nil {
+				return fmt.Errorf("upsertUserReceiver: error=%v rollback=%v", err, tx.Rollback())
+			}
+			return tx.Commit()
 		}
 	}
 	query = `update receivers
-set email = ?, default_depository = ?, customer_id = ?, status = ?, metadata = ?, last_updated_at = ?
+set email = '', email_encrypted = ?, email_hashed = ?, default_depository = ?, customer_id = ?, status = ?, metadata = ?, last_updated_at = ?
 where receiver_id = ? and user_id = ? and deleted_at is null`
-	stmt, err = tx.Prepare(query)
+	stmt, err = tx.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(receiver.Email, receiver.DefaultDepository, receiver.CustomerID, receiver.Status, receiver.Metadata, receiver.Updated.Time, receiver.ID, userID)
+	_, err = stmt.ExecContext(ctx, encrypted, hashed, receiver.DefaultDepository, receiver.CustomerID, receiver.Status, receiver.Metadata, receiver.Updated.Time, receiver.ID, userID)
 	stmt.Close()
 	if err != nil {
 		return fmt.Errorf("upsertUserReceiver: exec error=%v rollback=%v", err, tx.Rollback())
 	}
+	if err := insertReceiverDepository(ctx, tx, receiver.ID, receiver.DefaultDepository); err != nil {
+		return fmt.Errorf("upsertUserReceiver: error=%v rollback=%v", err, tx.Rollback())
+	}
 	return tx.Commit()
 }
 
-func (r *SQLReceiverRepo) deleteUserReceiver(id ReceiverID, userID id.User) error {
+func (r *SQLReceiverRepo) deleteUserReceiver(ctx context.Context, id ReceiverID, userID id.User) (err error) {
+	ctx, span := tracing.Start(ctx, "receiverRepo.deleteUserReceiver", "receiverID", id)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	// TODO(adam): Should this just change the status to Deactivated?
 	query := `update receivers set deleted_at = ? where receiver_id = ? and user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.Exec(time.Now(), id, userID); err != nil {
+	if _, err := stmt.ExecContext(ctx, time.Now(), id, userID); err != nil {
 		return fmt.Errorf("error deleting receiver_id=%q, user_id=%q: %v", id, userID, err)
 	}
 	return nil
 }
+
+func (r *SQLReceiverRepo) getReceiverDepositories(ctx context.Context, receiverID ReceiverID, userID id.User) ([]id.Depository, error) {
+	query := `select rd.depository_id from receiver_depositories as rd
+inner join receivers as r on rd.receiver_id = r.receiver_id
+where rd.receiver_id = ? and r.user_id = ? and r.deleted_at is null
+order by rd.created_at asc;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, receiverID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var depIDs []id.Depository
+	for rows.Next() {
+		var depID string
+		if err := rows.Scan(&depID); err != nil {
+			return nil, fmt.Errorf("getReceiverDepositories scan: %v", err)
+		}
+		depIDs = append(depIDs, id.Depository(depID))
+	}
+	return depIDs, rows.Err()
+}
+
+func (r *SQLReceiverRepo) linkReceiverDepository(ctx context.Context, receiverID ReceiverID, depID id.Depository) (err error) {
+	ctx, span := tracing.Start(ctx, "receiverRepo.linkReceiverDepository", "receiverID", receiverID, "depositoryID", depID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := insertReceiverDepository(ctx, tx, receiverID, depID); err != nil {
+		return fmt.Errorf("linkReceiverDepository: error=%v rollback=%v", err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// insertReceiverDepository links a Depository to a Receiver, which is a no-op if they're already linked.
+func insertReceiverDepository(ctx context.Context, tx *sql.Tx, receiverID ReceiverID, depID id.Depository) error {
+	query := `insert into receiver_depositories (receiver_id, depository_id, created_at) values (?, ?, ?);`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, receiverID, depID, time.Now()); err != nil && !database.UniqueViolation(err) {
+		return fmt.Errorf("problem linking depository=%s to receiver=%s: %v", depID, receiverID, err)
+	}
+	return nil
+}
+
+func (r *SQLReceiverRepo) unlinkReceiverDepository(ctx context.Context, receiverID ReceiverID, depID id.Depository) (err error) {
+	ctx, span := tracing.Start(ctx, "receiverRepo.unlinkReceiverDepository", "receiverID", receiverID, "depositoryID", depID)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	query := `delete from receiver_depositories where receiver_id = ? and depository_id = ?;`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, receiverID, depID); err != nil {
+		return fmt.Errorf("error unlinking depository=%s from receiver=%s: %v", depID, receiverID, err)
+	}
+	return nil
+}
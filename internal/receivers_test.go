@@ -6,6 +6,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -18,28 +19,44 @@ import (
 	"time"
 
 	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/customers"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/internal/secrets"
 	"github.com/moov-io/paygate/pkg/id"
 
 	"github.com/go-kit/kit/log"
-	"github.com/gorilla/mux"
 )
 
 type mockReceiverRepository struct {
 	receivers []*Receiver
 	err       error
+
+	// depositories are linked to every Receiver, the first Receiver's DefaultDepository is used when empty
+	depositories []id.Depository
 }
 
-func (r *mockReceiverRepository) getUserReceivers(userID id.User) ([]*Receiver, error) {
+func (r *mockReceiverRepository) getUserReceivers(ctx context.Context, userID id.User) ([]*Receiver, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
 	return r.receivers, nil
 }
 
-func (r *mockReceiverRepository) getUserReceiver(id ReceiverID, userID id.User) (*Receiver, error) {
+func (r *mockReceiverRepository) getUserReceiversByEmail(ctx context.Context, userID id.User, email string) ([]*Receiver, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var out []*Receiver
+	for i := range r.receivers {
+		if strings.EqualFold(r.receivers[i].Email, email) {
+			out = append(out, r.receivers[i])
+		}
+	}
+	return out, nil
+}
+
+func (r *mockReceiverRepository) getUserReceiver(ctx context.Context, id ReceiverID, userID id.User) (*Receiver, error) {
 	if r.err != nil {
 		return nil, r.err
 	}
@@ -49,15 +66,33 @@ func (r *mockReceiverRepository) getUserReceiver(id ReceiverID, userID id.User)
 	return nil, nil
 }
 
-func (r *mockReceiverRepository) updateReceiverStatus(id ReceiverID, status ReceiverStatus) error {
+func (r *mockReceiverRepository) updateReceiverStatus(ctx context.Context, id ReceiverID, status ReceiverStatus) error {
 	return r.err
 }
 
-func (r *mockReceiverRepository) upsertUserReceiver(userID id.User, receiver *Receiver) error {
+func (r *mockReceiverRepository) upsertUserReceiver(ctx context.Context, userID id.User, receiver *Receiver) error {
 	return r.err
 }
 
-func (r *mockReceiverRepository) deleteUserReceiver(id ReceiverID, userID id.User) error {
+func (r *mockReceiverRepository) deleteUserReceiver(ctx context.Context, id ReceiverID, userID id.User) error {
+	return r.err
+}
+
+func (r *mockReceiverRepository) getReceiverDepositories(ctx context.Context, receiverID ReceiverID, userID id.User) ([]id.Depository, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.depositories) == 0 && len(r.receivers) > 0 {
+		return []id.Depository{r.receivers[0].DefaultDepository}, nil
+	}
+	return r.depositories, nil
+}
+
+func (r *mockReceiverRepository) linkReceiverDepository(ctx context.Context, receiverID ReceiverID, depID id.Depository) error {
+	return r.err
+}
+
+func (r *mockReceiverRepository) unlinkReceiverDepository(ctx context.Context, receiverID ReceiverID, depID id.Depository) error {
 	return r.err
 }
 
@@ -124,12 +159,12 @@ func TestReceivers__emptyDB(t *testing.T) {
 
 	check := func(t *testing.T, repo receiverRepository) {
 		userID := id.User(base.ID())
-		if err := repo.deleteUserReceiver(ReceiverID(base.ID()), userID); err != nil {
+		if err := repo.deleteUserReceiver(context.Background(), ReceiverID(base.ID()), userID); err != nil {
 			t.Errorf("expected no error, but got %v", err)
 		}
 
 		// all receivers for a user
-		receivers, err := repo.getUserReceivers(userID)
+		receivers, err := repo.getUserReceivers(context.Background(), userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -138,7 +173,7 @@ func TestReceivers__emptyDB(t *testing.T) {
 		}
 
 		// specific receiver
-		receiver, err := repo.getUserReceiver(ReceiverID(base.ID()), userID)
+		receiver, err := repo.getUserReceiver(context.Background(), ReceiverID(base.ID()), userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -150,12 +185,17 @@ func TestReceivers__emptyDB(t *testing.T) {
 	// SQLite tests
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
-	check(t, &SQLReceiverRepo{sqliteDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t)))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), postgresDB.DB, secrets.TestStringKeeper(t)))
 
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
-	check(t, &SQLReceiverRepo{mysqlDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), mysqlDB.DB, secrets.TestStringKeeper(t)))
 }
 
 func TestReceivers__upsert(t *testing.T) {
@@ -171,16 +211,16 @@ func TestReceivers__upsert(t *testing.T) {
 			Metadata:          "extra data",
 			Created:           base.NewTime(time.Now().Truncate(1 * time.Second)),
 		}
-		if c, err := repo.getUserReceiver(receiver.ID, userID); err != nil || c != nil {
+		if c, err := repo.getUserReceiver(context.Background(), receiver.ID, userID); err != nil || c != nil {
 			t.Errorf("expected empty, c=%v | err=%v", c, err)
 		}
 
 		// write, then verify
-		if err := repo.upsertUserReceiver(userID, receiver); err != nil {
+		if err := repo.upsertUserReceiver(context.Background(), userID, receiver); err != nil {
 			t.Error(err)
 		}
 
-		c, err := repo.getUserReceiver(receiver.ID, userID)
+		c, err := repo.getUserReceiver(context.Background(), receiver.ID, userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -204,7 +244,7 @@ func TestReceivers__upsert(t *testing.T) {
 		}
 
 		// get all for our user
-		receivers, err := repo.getUserReceivers(userID)
+		receivers, err := repo.getUserReceivers(context.Background(), userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -218,7 +258,7 @@ func TestReceivers__upsert(t *testing.T) {
 		// update, verify default depository changed
 		depositoryId := id.Depository(base.ID())
 		receiver.DefaultDepository = depositoryId
-		if err := repo.upsertUserReceiver(userID, receiver); err != nil {
+		if err := repo.upsertUserReceiver(context.Background(), userID, receiver); err != nil {
 			t.Error(err)
 		}
 		if receiver.DefaultDepository != depositoryId {
@@ -229,12 +269,17 @@ func TestReceivers__upsert(t *testing.T) {
 	// SQLite tests
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
-	check(t, &SQLReceiverRepo{sqliteDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t)))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), postgresDB.DB, secrets.TestStringKeeper(t)))
 
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
-	check(t, &SQLReceiverRepo{mysqlDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), mysqlDB.DB, secrets.TestStringKeeper(t)))
 }
 
 // TestReceivers__upsert2 uperts a Receiver twice, which
@@ -254,22 +299,22 @@ func TestReceivers__upsert2(t *testing.T) {
 			Metadata:          "extra data",
 			Created:           base.NewTime(time.Now()),
 		}
-		if c, err := repo.getUserReceiver(receiver.ID, userID); err != nil || c != nil {
+		if c, err := repo.getUserReceiver(context.Background(), receiver.ID, userID); err != nil || c != nil {
 			t.Errorf("expected empty, c=%v | err=%v", c, err)
 		}
 
 		// initial create, then update
-		if err := repo.upsertUserReceiver(userID, receiver); err != nil {
+		if err := repo.upsertUserReceiver(context.Background(), userID, receiver); err != nil {
 			t.Error(err)
 		}
 
 		receiver.DefaultDepository = id.Depository(base.ID())
 		receiver.Status = ReceiverVerified
-		if err := repo.upsertUserReceiver(userID, receiver); err != nil {
+		if err := repo.upsertUserReceiver(context.Background(), userID, receiver); err != nil {
 			t.Error(err)
 		}
 
-		r, err := repo.getUserReceiver(receiver.ID, userID)
+		r, err := repo.getUserReceiver(context.Background(), receiver.ID, userID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -288,12 +333,17 @@ func TestReceivers__upsert2(t *testing.T) {
 	// SQLite tests
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
-	check(t, &SQLReceiverRepo{sqliteDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t)))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), postgresDB.DB, secrets.TestStringKeeper(t)))
 
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
-	check(t, &SQLReceiverRepo{mysqlDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), mysqlDB.DB, secrets.TestStringKeeper(t)))
 }
 
 func TestReceivers__updateReceiverStatus(t *testing.T) {
@@ -309,12 +359,12 @@ func TestReceivers__updateReceiverStatus(t *testing.T) {
 			Metadata:          "extra data",
 			Created:           base.NewTime(time.Now()),
 		}
-		if err := repo.upsertUserReceiver(userID, receiver); err != nil {
+		if err := repo.upsertUserReceiver(context.Background(), userID, receiver); err != nil {
 			t.Error(err)
 		}
 
 		// verify before our update
-		r, err := repo.getUserReceiver(receiver.ID, userID)
+		r, err := repo.getUserReceiver(context.Background(), receiver.ID, userID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -323,10 +373,10 @@ func TestReceivers__updateReceiverStatus(t *testing.T) {
 		}
 
 		// update and verify
-		if err := repo.updateReceiverStatus(receiver.ID, ReceiverSuspended); err != nil {
+		if err := repo.updateReceiverStatus(context.Background(), receiver.ID, ReceiverSuspended); err != nil {
 			t.Fatal(err)
 		}
-		r, err = repo.getUserReceiver(receiver.ID, userID)
+		r, err = repo.getUserReceiver(context.Background(), receiver.ID, userID)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -339,12 +389,17 @@ func TestReceivers__updateReceiverStatus(t *testing.T) {
 	// SQLite tests
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
-	check(t, &SQLReceiverRepo{sqliteDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t)))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), postgresDB.DB, secrets.TestStringKeeper(t)))
 
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
-	check(t, &SQLReceiverRepo{mysqlDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), mysqlDB.DB, secrets.TestStringKeeper(t)))
 }
 
 func TestReceivers__delete(t *testing.T) {
@@ -360,28 +415,28 @@ func TestReceivers__delete(t *testing.T) {
 			Metadata:          "extra data",
 			Created:           base.NewTime(time.Now()),
 		}
-		if c, err := repo.getUserReceiver(receiver.ID, userID); err != nil || c != nil {
+		if c, err := repo.getUserReceiver(context.Background(), receiver.ID, userID); err != nil || c != nil {
 			t.Errorf("expected empty, c=%v | err=%v", c, err)
 		}
 
 		// write
-		if err := repo.upsertUserReceiver(userID, receiver); err != nil {
+		if err := repo.upsertUserReceiver(context.Background(), userID, receiver); err != nil {
 			t.Error(err)
 		}
 
 		// verify
-		c, err := repo.getUserReceiver(receiver.ID, userID)
+		c, err := repo.getUserReceiver(context.Background(), receiver.ID, userID)
 		if err != nil || c == nil {
 			t.Errorf("expected receiver, c=%v, err=%v", c, err)
 		}
 
 		// delete
-		if err := repo.deleteUserReceiver(receiver.ID, userID); err != nil {
+		if err := repo.deleteUserReceiver(context.Background(), receiver.ID, userID); err != nil {
 			t.Error(err)
 		}
 
 		// verify tombstoned
-		if c, err := repo.getUserReceiver(receiver.ID, userID); err != nil || c != nil {
+		if c, err := repo.getUserReceiver(context.Background(), receiver.ID, userID); err != nil || c != nil {
 			t.Errorf("expected empty, c=%v | err=%v", c, err)
 		}
 	}
@@ -389,12 +444,17 @@ func TestReceivers__delete(t *testing.T) {
 	// SQLite tests
 	sqliteDB := database.CreateTestSqliteDB(t)
 	defer sqliteDB.Close()
-	check(t, &SQLReceiverRepo{sqliteDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t)))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), postgresDB.DB, secrets.TestStringKeeper(t)))
 
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
-	check(t, &SQLReceiverRepo{mysqlDB.DB, log.NewNopLogger()})
+	check(t, NewReceiverRepo(log.NewNopLogger(), mysqlDB.DB, secrets.TestStringKeeper(t)))
 }
 
 func TestReceivers_CustomersError(t *testing.T) {
@@ -402,7 +462,7 @@ func TestReceivers_CustomersError(t *testing.T) {
 
 	keeper := secrets.TestStringKeeper(t)
 	check := func(t *testing.T, db *sql.DB) {
-		receiverRepo := &SQLReceiverRepo{db, log.NewNopLogger()}
+		receiverRepo := NewReceiverRepo(log.NewNopLogger(), db, secrets.TestStringKeeper(t))
 		depRepo := NewDepositoryRepo(log.NewNopLogger(), db, keeper)
 
 		// Write Depository to repo
@@ -417,7 +477,7 @@ func TestReceivers_CustomersError(t *testing.T) {
 			EncryptedAccountNumber: "151",
 			Status:                 DepositoryUnverified,
 		}
-		if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+		if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 			t.Fatal(err)
 		}
 
@@ -454,6 +514,11 @@ func TestReceivers_CustomersError(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, sqliteDB.DB)
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -501,7 +566,7 @@ func TestReceivers__HTTPGet(t *testing.T) {
 		receivers: []*Receiver{rec},
 	}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddReceiverRoutes(log.NewNopLogger(), router, nil, nil, repo)
 
 	req := httptest.NewRequest("GET", fmt.Sprintf("/receivers/%s", rec.ID), nil)
@@ -524,10 +589,81 @@ func TestReceivers__HTTPGet(t *testing.T) {
 	}
 }
 
+func TestReceivers__HTTPGetMasked(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	userID := id.User(base.ID())
+	repo := NewReceiverRepo(log.NewNopLogger(), db.DB, secrets.TestStringKeeper(t))
+	for _, email := range []string{"john.doe@moov.io", "jane@moov.io"} {
+		rec := &Receiver{
+			ID:                ReceiverID(base.ID()),
+			Email:             email,
+			DefaultDepository: id.Depository(base.ID()),
+			Status:            ReceiverVerified,
+			Created:           base.NewTime(time.Now()),
+		}
+		if err := repo.upsertUserReceiver(context.Background(), userID, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := auth.NewTestRouter()
+	AddReceiverRoutes(log.NewNopLogger(), router, nil, nil, repo)
+
+	get := func(query string) []*Receiver {
+		req := httptest.NewRequest("GET", "/receivers"+query, nil)
+		req.Header.Set("x-user-id", userID.String())
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		var receivers []*Receiver
+		if err := json.NewDecoder(w.Body).Decode(&receivers); err != nil {
+			t.Fatal(err)
+		}
+		return receivers
+	}
+
+	if receivers := get(""); len(receivers) != 2 {
+		t.Errorf("got %d receivers", len(receivers))
+	} else {
+		for i := range receivers {
+			if receivers[i].Email != "j*******@moov.io" && receivers[i].Email != "j***@moov.io" {
+				t.Errorf("unexpected email: %q", receivers[i].Email)
+			}
+		}
+	}
+
+	// search by email, which is case insensitive
+	if receivers := get("?email=John.Doe@moov.io"); len(receivers) != 1 || receivers[0].Email != "j*******@moov.io" {
+		t.Errorf("unexpected receivers: %#v", receivers)
+	}
+	if receivers := get("?email=John.Doe@moov.io&unmask=true"); len(receivers) != 1 || receivers[0].Email != "john.doe@moov.io" {
+		t.Errorf("unexpected receivers: %#v", receivers)
+	}
+	if receivers := get("?email=other@moov.io"); len(receivers) != 0 {
+		t.Errorf("unexpected receivers: %#v", receivers)
+	}
+
+	// emails are only stored encrypted
+	var email, encrypted string
+	if err := db.DB.QueryRow(`select email, email_encrypted from receivers limit 1`).Scan(&email, &encrypted); err != nil {
+		t.Fatal(err)
+	}
+	if email != "" || encrypted == "" || strings.Contains(encrypted, "moov.io") {
+		t.Errorf("email=%q encrypted=%q", email, encrypted)
+	}
+}
+
 func TestReceivers__HTTPGetNoUserID(t *testing.T) {
 	repo := &mockReceiverRepository{}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddReceiverRoutes(log.NewNopLogger(), router, nil, nil, repo)
 
 	req := httptest.NewRequest("GET", "/receivers/foo", nil)
@@ -536,7 +672,7 @@ func TestReceivers__HTTPGetNoUserID(t *testing.T) {
 	router.ServeHTTP(w, req)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
@@ -561,7 +697,7 @@ func TestReceivers__HTTPUpdate(t *testing.T) {
 		Status:                 DepositoryVerified,
 		Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
 	}
-	if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		t.Fatal(err)
 	}
 
@@ -579,7 +715,7 @@ func TestReceivers__HTTPUpdate(t *testing.T) {
 		},
 	}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddReceiverRoutes(log.NewNopLogger(), router, nil, depRepo, receiverRepo)
 
 	body := fmt.Sprintf(`{"defaultDepository": "%s", "metadata": "other data"}`, dep.ID)
@@ -613,7 +749,7 @@ func TestReceivers__HTTPUpdateError(t *testing.T) {
 
 	repo := &mockReceiverRepository{err: errors.New("bad error")}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddReceiverRoutes(log.NewNopLogger(), router, nil, nil, repo)
 
 	body := strings.NewReader(`{"defaultDepository": "foo", "metadata": "other data"}`)
@@ -644,7 +780,7 @@ func TestReceivers__HTTPDelete(t *testing.T) {
 		receivers: []*Receiver{rec},
 	}
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	AddReceiverRoutes(log.NewNopLogger(), router, nil, nil, repo)
 
 	req := httptest.NewRequest("DELETE", fmt.Sprintf("/receivers/%s", rec.ID), nil)
@@ -658,3 +794,189 @@ func TestReceivers__HTTPDelete(t *testing.T) {
 		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
 	}
 }
+
+func TestReceivers__depositories(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLReceiverRepo) {
+		userID := id.User(base.ID())
+		rec := &Receiver{
+			ID:                ReceiverID(base.ID()),
+			Email:             "test@moov.io",
+			DefaultDepository: id.Depository(base.ID()),
+			Status:            ReceiverVerified,
+			Created:           base.NewTime(time.Now()),
+		}
+		if err := repo.upsertUserReceiver(context.Background(), userID, rec); err != nil {
+			t.Fatal(err)
+		}
+
+		// the default Depository is always linked
+		depIDs, err := repo.getReceiverDepositories(context.Background(), rec.ID, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(depIDs) != 1 || depIDs[0] != rec.DefaultDepository {
+			t.Errorf("unexpected depositories: %v", depIDs)
+		}
+
+		// link another Depository, twice
+		other := id.Depository(base.ID())
+		for i := 0; i < 2; i++ {
+			if err := repo.linkReceiverDepository(context.Background(), rec.ID, other); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if depIDs, err := repo.getReceiverDepositories(context.Background(), rec.ID, userID); err != nil || len(depIDs) != 2 {
+			t.Errorf("depIDs=%v error=%v", depIDs, err)
+		}
+
+		// a new default Depository is linked on update
+		rec.DefaultDepository = id.Depository(base.ID())
+		if err := repo.upsertUserReceiver(context.Background(), userID, rec); err != nil {
+			t.Fatal(err)
+		}
+		if depIDs, err := repo.getReceiverDepositories(context.Background(), rec.ID, userID); err != nil || !containsDepository(depIDs, rec.DefaultDepository) || len(depIDs) != 3 {
+			t.Errorf("depIDs=%v error=%v", depIDs, err)
+		}
+
+		if err := repo.unlinkReceiverDepository(context.Background(), rec.ID, other); err != nil {
+			t.Fatal(err)
+		}
+		if depIDs, err := repo.getReceiverDepositories(context.Background(), rec.ID, userID); err != nil || containsDepository(depIDs, other) || len(depIDs) != 2 {
+			t.Errorf("depIDs=%v error=%v", depIDs, err)
+		}
+
+		// other users can't read the links
+		if depIDs, err := repo.getReceiverDepositories(context.Background(), rec.ID, id.User(base.ID())); err != nil || len(depIDs) != 0 {
+			t.Errorf("depIDs=%v error=%v", depIDs, err)
+		}
+	}
+
+	keeper := secrets.TestStringKeeper(t)
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, keeper))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), postgresDB.DB, keeper))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewReceiverRepo(log.NewNopLogger(), mysqlDB.DB, keeper))
+}
+
+func TestReceivers__HTTPDepositories(t *testing.T) {
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+
+	userID := id.User(base.ID())
+	keeper := secrets.TestStringKeeper(t)
+	depRepo := NewDepositoryRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
+	receiverRepo := NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, keeper)
+
+	encrypted, err := keeper.EncryptString("151")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deps []*Depository
+	for _, accountType := range []AccountType{Checking, Savings} {
+		dep := &Depository{
+			ID:                     id.Depository(base.ID()),
+			BankName:               "bank name",
+			Holder:                 "holder",
+			HolderType:             Individual,
+			Type:                   accountType,
+			RoutingNumber:          "121042882",
+			EncryptedAccountNumber: encrypted,
+			Status:                 DepositoryVerified,
+			Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
+		}
+		if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+			t.Fatal(err)
+		}
+		deps = append(deps, dep)
+	}
+	rec := &Receiver{
+		ID:                ReceiverID(base.ID()),
+		Email:             "test@moov.io",
+		DefaultDepository: deps[0].ID,
+		Status:            ReceiverVerified,
+		Created:           base.NewTime(time.Now()),
+	}
+	if err := receiverRepo.upsertUserReceiver(context.Background(), userID, rec); err != nil {
+		t.Fatal(err)
+	}
+
+	router := auth.NewTestRouter()
+	AddReceiverRoutes(log.NewNopLogger(), router, nil, depRepo, receiverRepo)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, fmt.Sprintf("/receivers/%s/depositories%s", rec.ID, path), nil)
+		req.Header.Set("x-user-id", userID.String())
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+		return w
+	}
+	list := func() []*Depository {
+		w := do("GET", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		var out []*Depository
+		if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if out := list(); len(out) != 1 || out[0].ID != deps[0].ID {
+		t.Errorf("unexpected depositories: %#v", out)
+	}
+
+	// link the savings account
+	if w := do("PUT", "/"+deps[1].ID.String()); w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if out := list(); len(out) != 2 {
+		t.Errorf("unexpected depositories: %#v", out)
+	}
+	if w := do("GET", "/"+deps[1].ID.String()); w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// Depositories of other users can't be linked
+	if w := do("PUT", "/"+base.ID()); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// the default Depository can't be unlinked
+	if w := do("DELETE", "/"+deps[0].ID.String()); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("DELETE", "/"+deps[1].ID.String()); w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/"+deps[1].ID.String()); w.Code != http.StatusNotFound {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// unknown Receivers aren't found
+	rec.ID = ReceiverID(base.ID())
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		path := ""
+		if method != "GET" {
+			path = "/" + deps[1].ID.String()
+		}
+		if w := do(method, path); w.Code != http.StatusNotFound {
+			t.Errorf("%s: bogus HTTP status: %d: %s", method, w.Code, w.Body.String())
+		}
+	}
+}
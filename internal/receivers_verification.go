@@ -0,0 +1,255 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/paygate/internal/auth"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/mailer"
+	"github.com/moov-io/paygate/internal/route"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errInvalidVerificationToken = errors.New("invalid email verification token")
+	errExpiredVerificationToken = errors.New("expired email verification token")
+)
+
+// ReceiverEmailVerifier sends signed, expiring tokens to a Receiver's email address. Confirming
+// the token proves control of the address and moves the Receiver to ReceiverVerified.
+type ReceiverEmailVerifier struct {
+	logger log.Logger
+	mailer mailer.Mailer
+
+	secret     []byte
+	expiration time.Duration
+
+	// url is the link included in emails, "{token}" is replaced with the token
+	url string
+}
+
+func NewReceiverEmailVerifier(logger log.Logger, cfg *config.EmailConfig, m mailer.Mailer) (*ReceiverEmailVerifier, error) {
+	if cfg == nil {
+		cfg = &config.EmailConfig{}
+	}
+	v := &ReceiverEmailVerifier{
+		logger:     logger,
+		mailer:     m,
+		secret:     []byte(cfg.Verification.Secret),
+		expiration: cfg.Verification.Expiration,
+		url:        cfg.Verification.URL,
+	}
+	if len(v.secret) == 0 {
+		// Tokens signed with a random secret are invalidated when paygate restarts.
+		v.secret = make([]byte, 32)
+		if _, err := rand.Read(v.secret); err != nil {
+			return nil, fmt.Errorf("generating email verification secret: %v", err)
+		}
+		logger.Log("receivers", "EMAIL_VERIFICATION_SECRET is not set, using a random secret")
+	}
+	if v.expiration <= 0 {
+		v.expiration = 72 * time.Hour
+	}
+	return v, nil
+}
+
+type verificationClaims struct {
+	ReceiverID ReceiverID `json:"r"`
+	UserID     id.User    `json:"u"`
+	EmailHash  string     `json:"e"`
+	Expires    int64      `json:"x"`
+}
+
+// createToken signs the Receiver, its owner and current email address. Changing the email address
+// invalidates any outstanding tokens.
+func (v *ReceiverEmailVerifier) createToken(userID id.User, receiver *Receiver, now time.Time) (string, error) {
+	hashed, err := hashEmail(receiver.Email)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(verificationClaims{
+		ReceiverID: receiver.ID,
+		UserID:     userID,
+		EmailHash:  hashed,
+		Expires:    now.Add(v.expiration).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + v.sign(encoded), nil
+}
+
+func (v *ReceiverEmailVerifier) sign(encoded string) string {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseToken checks the token's signature and expiration and returns its claims.
+func (v *ReceiverEmailVerifier) parseToken(token string, now time.Time) (*verificationClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, errInvalidVerificationToken
+	}
+	if !hmac.Equal([]byte(v.sign(parts[0])), []byte(parts[1])) {
+		return nil, errInvalidVerificationToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errInvalidVerificationToken
+	}
+	var claims verificationClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errInvalidVerificationToken
+	}
+	if now.After(time.Unix(claims.Expires, 0)) {
+		return nil, errExpiredVerificationToken
+	}
+	return &claims, nil
+}
+
+// sendEmail emails a verification token to the Receiver.
+func (v *ReceiverEmailVerifier) sendEmail(userID id.User, receiver *Receiver) error {
+	token, err := v.createToken(userID, receiver, time.Now())
+	if err != nil {
+		return err
+	}
+	var body string
+	if v.url != "" {
+		body = fmt.Sprintf("Confirm your email address by visiting:\n\n%s\n", strings.Replace(v.url, "{token}", token, -1))
+	} else {
+		body = fmt.Sprintf("Confirm your email address with the following token:\n\n%s\n", token)
+	}
+	body += fmt.Sprintf("\nThis link expires in %v.\n", v.expiration)
+
+	return v.mailer.Send(&mailer.Message{
+		To:      receiver.Email,
+		Subject: "Confirm your email address",
+		Body:    body,
+	})
+}
+
+// confirm validates token for the Receiver and marks it verified.
+func (v *ReceiverEmailVerifier) confirm(ctx context.Context, userID id.User, receiverID ReceiverID, token string, receiverRepo receiverRepository) error {
+	claims, err := v.parseToken(token, time.Now())
+	if err != nil {
+		return err
+	}
+	if claims.ReceiverID != receiverID || claims.UserID != userID {
+		return errInvalidVerificationToken
+	}
+	receiver, err := receiverRepo.getUserReceiver(ctx, receiverID, userID)
+	if err != nil {
+		return err
+	}
+	if receiver == nil {
+		return errInvalidVerificationToken
+	}
+	if hashed, err := hashEmail(receiver.Email); err != nil || hashed != claims.EmailHash {
+		return errInvalidVerificationToken
+	}
+	switch receiver.Status {
+	case ReceiverVerified:
+		return nil
+	case ReceiverUnverified:
+		return receiverRepo.updateReceiverStatus(ctx, receiverID, ReceiverVerified)
+	default:
+		return fmt.Errorf("receiver=%s is %s and cannot be verified", receiverID, receiver.Status)
+	}
+}
+
+func AddReceiverVerificationRoutes(logger log.Logger, r *mux.Router, verifier *ReceiverEmailVerifier, receiverRepo receiverRepository) {
+	r.Methods("POST").Path("/receivers/{receiverId}/verification/email").HandlerFunc(auth.Require(auth.ReceiversWrite, sendReceiverVerificationEmail(logger, verifier, receiverRepo)))
+	r.Methods("POST").Path("/receivers/{receiverId}/verification/email/confirm").HandlerFunc(auth.Require(auth.ReceiversWrite, confirmReceiverEmail(logger, verifier, receiverRepo)))
+}
+
+func sendReceiverVerificationEmail(logger log.Logger, verifier *ReceiverEmailVerifier, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		receiverID := getReceiverID(r)
+		if receiverID == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		receiver, err := receiverRepo.getUserReceiver(r.Context(), receiverID, responder.XUserID)
+		if err != nil {
+			responder.Problem(err)
+			return
+		}
+		if receiver == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if receiver.Status != ReceiverUnverified {
+			moovhttp.Problem(w, fmt.Errorf("receiver=%s is %s", receiverID, receiver.Status))
+			return
+		}
+		if err := verifier.sendEmail(responder.XUserID, receiver); err != nil {
+			responder.Log("receivers", fmt.Sprintf("problem sending verification email for receiver=%s: %v", receiverID, err))
+			responder.Problem(err)
+			return
+		}
+
+		responder.Log("receivers", fmt.Sprintf("sent verification email for receiver=%s", receiverID))
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+}
+
+type confirmReceiverEmailRequest struct {
+	Token string `json:"token"`
+}
+
+func confirmReceiverEmail(logger log.Logger, verifier *ReceiverEmailVerifier, receiverRepo receiverRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responder := route.NewResponder(logger, w, r)
+		if responder == nil {
+			return
+		}
+
+		receiverID := getReceiverID(r)
+		if receiverID == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req confirmReceiverEmailRequest
+		if err := json.NewDecoder(Read(r.Body)).Decode(&req); err != nil || req.Token == "" {
+			moovhttp.Problem(w, errInvalidVerificationToken)
+			return
+		}
+		if err := verifier.confirm(r.Context(), responder.XUserID, receiverID, req.Token, receiverRepo); err != nil {
+			responder.Log("receivers", fmt.Sprintf("problem confirming email for receiver=%s: %v", receiverID, err))
+			moovhttp.Problem(w, err)
+			return
+		}
+
+		responder.Log("receivers", fmt.Sprintf("receiver=%s verified by email", receiverID))
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+}
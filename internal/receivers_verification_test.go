@@ -0,0 +1,231 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/mailer"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+func testReceiverEmailVerifier(t *testing.T, m mailer.Mailer) *ReceiverEmailVerifier {
+	t.Helper()
+
+	cfg := &config.EmailConfig{}
+	cfg.Verification.Secret = "secret"
+	cfg.Verification.URL = "https://app.example.com/verify?token={token}"
+	v, err := NewReceiverEmailVerifier(log.NewNopLogger(), cfg, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestReceiverEmailVerifier__token(t *testing.T) {
+	v := testReceiverEmailVerifier(t, &mailer.TestMailer{})
+	userID := id.User(base.ID())
+	receiver := &Receiver{ID: ReceiverID(base.ID()), Email: "Jane@moov.io"}
+
+	now := time.Now()
+	token, err := v.createToken(userID, receiver, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := v.parseToken(token, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.ReceiverID != receiver.ID || claims.UserID != userID {
+		t.Errorf("unexpected claims: %#v", claims)
+	}
+	if hashed, _ := hashEmail("jane@moov.io"); claims.EmailHash != hashed {
+		t.Errorf("claims.EmailHash=%s", claims.EmailHash)
+	}
+
+	// expired
+	if _, err := v.parseToken(token, now.Add(v.expiration+time.Minute)); err != errExpiredVerificationToken {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// tampered payload or signature
+	parts := strings.Split(token, ".")
+	other, _ := v.createToken(id.User(base.ID()), receiver, now)
+	for _, bad := range []string{"", "abc", parts[0], strings.Split(other, ".")[0] + "." + parts[1], parts[0] + ".AAAA"} {
+		if _, err := v.parseToken(bad, now); err != errInvalidVerificationToken {
+			t.Errorf("token=%q: unexpected error: %v", bad, err)
+		}
+	}
+
+	// signed with a different secret
+	v2, _ := NewReceiverEmailVerifier(log.NewNopLogger(), nil, &mailer.TestMailer{})
+	if _, err := v2.parseToken(token, now); err != errInvalidVerificationToken {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReceiverEmailVerifier__confirm(t *testing.T) {
+	v := testReceiverEmailVerifier(t, &mailer.TestMailer{})
+	userID := id.User(base.ID())
+	receiver := &Receiver{ID: ReceiverID(base.ID()), Email: "jane@moov.io", Status: ReceiverUnverified}
+	repo := &mockReceiverRepository{receivers: []*Receiver{receiver}}
+
+	token, err := v.createToken(userID, receiver, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.confirm(context.Background(), userID, receiver.ID, token, repo); err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong user or receiver
+	if err := v.confirm(context.Background(), id.User(base.ID()), receiver.ID, token, repo); err != errInvalidVerificationToken {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := v.confirm(context.Background(), userID, ReceiverID(base.ID()), token, repo); err != errInvalidVerificationToken {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// email changed after the token was sent
+	receiver.Email = "john@moov.io"
+	if err := v.confirm(context.Background(), userID, receiver.ID, token, repo); err != errInvalidVerificationToken {
+		t.Errorf("unexpected error: %v", err)
+	}
+	receiver.Email = "jane@moov.io"
+
+	// suspended Receivers stay suspended
+	receiver.Status = ReceiverSuspended
+	if err := v.confirm(context.Background(), userID, receiver.ID, token, repo); err == nil {
+		t.Error("expected error")
+	}
+
+	repo.err = errors.New("bad error")
+	if err := v.confirm(context.Background(), userID, receiver.ID, token, repo); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestReceiverEmailVerifier__HTTP(t *testing.T) {
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+
+	userID := id.User(base.ID())
+	receiverRepo := NewReceiverRepo(log.NewNopLogger(), sqliteDB.DB, secrets.TestStringKeeper(t))
+	receiver := &Receiver{
+		ID:                ReceiverID(base.ID()),
+		Email:             "jane@moov.io",
+		DefaultDepository: id.Depository(base.ID()),
+		Status:            ReceiverUnverified,
+		Created:           base.NewTime(time.Now()),
+	}
+	if err := receiverRepo.upsertUserReceiver(context.Background(), userID, receiver); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mailer.TestMailer{}
+	verifier := testReceiverEmailVerifier(t, m)
+
+	router := auth.NewTestRouter()
+	AddReceiverVerificationRoutes(log.NewNopLogger(), router, verifier, receiverRepo)
+
+	// send the verification email
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", fmt.Sprintf("/receivers/%s/verification/email", receiver.ID), nil)
+	req.Header.Set("x-user-id", userID.String())
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if len(m.Messages) != 1 || m.Messages[0].To != "jane@moov.io" {
+		t.Fatalf("unexpected messages: %#v", m.Messages)
+	}
+	body := m.Messages[0].Body
+	idx := strings.Index(body, "?token=")
+	if idx < 0 {
+		t.Fatalf("missing token: %q", body)
+	}
+	token := strings.Fields(body[idx+len("?token="):])[0]
+
+	// a bad token is rejected
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", fmt.Sprintf("/receivers/%s/verification/email/confirm", receiver.ID), strings.NewReader(`{"token": "bad"}`))
+	req.Header.Set("x-user-id", userID.String())
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// confirm the token
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `{"token": %q}`, token)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", fmt.Sprintf("/receivers/%s/verification/email/confirm", receiver.ID), &buf)
+	req.Header.Set("x-user-id", userID.String())
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	rec, err := receiverRepo.getUserReceiver(context.Background(), receiver.ID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != ReceiverVerified {
+		t.Errorf("rec.Status=%s", rec.Status)
+	}
+
+	// verified Receivers aren't sent more emails
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", fmt.Sprintf("/receivers/%s/verification/email", receiver.ID), nil)
+	req.Header.Set("x-user-id", userID.String())
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	if len(m.Messages) != 1 {
+		t.Errorf("unexpected messages: %#v", m.Messages)
+	}
+}
+
+func TestReceiverEmailVerifier__HTTPMailerError(t *testing.T) {
+	userID := id.User(base.ID())
+	receiver := &Receiver{ID: ReceiverID(base.ID()), Email: "jane@moov.io", Status: ReceiverUnverified}
+	repo := &mockReceiverRepository{receivers: []*Receiver{receiver}}
+
+	router := auth.NewTestRouter()
+	AddReceiverVerificationRoutes(log.NewNopLogger(), router, testReceiverEmailVerifier(t, &mailer.TestMailer{Err: errors.New("bad error")}), repo)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", fmt.Sprintf("/receivers/%s/verification/email", receiver.ID), nil)
+	req.Header.Set("x-user-id", userID.String())
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -79,14 +79,14 @@ func (r *periodicRefresher) Start(interval time.Duration) error {
 		select {
 		case <-tick.C:
 			requestID := base.ID()
-			customers, err := r.cur.Next()
+			customers, err := r.cur.Next(r.ctx)
 			if err != nil {
 				r.logger.Log("customers", fmt.Sprintf("cursor error: %v", err), "requestID", requestID)
 				continue
 			}
 			r.logger.Log("customers", fmt.Sprintf("got %d customer records to refresh OFAC searches", len(customers)), "requestID", requestID)
 			for i := range customers {
-				if err := r.refreshSearchIfNeeded(customers[i], requestID); err != nil {
+				if err := r.refreshSearchIfNeeded(r.ctx, customers[i], requestID); err != nil {
 					r.logger.Log("customers", err.Error(), "requestID", requestID)
 				}
 			}
@@ -98,19 +98,19 @@ func (r *periodicRefresher) Start(interval time.Duration) error {
 	}
 }
 
-func (r *periodicRefresher) refreshSearchIfNeeded(cust customers.Cust, requestID string) error {
-	result, err := r.client.LatestOFACSearch(cust.ID, requestID, "")
+func (r *periodicRefresher) refreshSearchIfNeeded(ctx context.Context, cust customers.Cust, requestID string) error {
+	result, err := r.client.LatestOFACSearch(ctx, cust.ID, requestID, "")
 	if err != nil {
 		return fmt.Errorf("error getting latest ofac search for customer=%s", cust.ID)
 	}
 	if searchIsOldEnough(result.CreatedAt, r.minimumStaleness) {
 		r.logger.Log("customers", fmt.Sprintf("refreshing customer=%s ofac search", cust.ID), "requestID", requestID)
 
-		if _, err := r.client.RefreshOFACSearch(cust.ID, "requestID", "userID"); err != nil {
+		if _, err := r.client.RefreshOFACSearch(ctx, cust.ID, "requestID", "userID"); err != nil {
 			return fmt.Errorf("error refreshing ofac search for customer=%s: %v", cust.ID, err)
 		}
 
-		if err := rejectRelatedCustomerObjects(r.client, cust, requestID, r.depRepo, r.receiverRepo); err != nil {
+		if err := rejectRelatedCustomerObjects(ctx, r.client, cust, requestID, r.depRepo, r.receiverRepo); err != nil {
 			return fmt.Errorf("error rejecting customer=%s: %v", cust.ID, err)
 		}
 	}
@@ -121,8 +121,8 @@ func searchIsOldEnough(when time.Time, staleness time.Duration) bool {
 	return when.Add(staleness).Before(time.Now())
 }
 
-func rejectRelatedCustomerObjects(client customers.Client, c customers.Cust, requestID string, depRepo DepositoryRepository, receiverRepo receiverRepository) error {
-	cust, err := client.Lookup(c.ID, requestID, "")
+func rejectRelatedCustomerObjects(ctx context.Context, client customers.Client, c customers.Cust, requestID string, depRepo DepositoryRepository, receiverRepo receiverRepository) error {
+	cust, err := client.Lookup(ctx, c.ID, requestID, "")
 	if err != nil {
 		return fmt.Errorf("error looking up customer=%s: %v", c.ID, err)
 	}
@@ -134,11 +134,11 @@ func rejectRelatedCustomerObjects(client customers.Client, c customers.Cust, req
 	} else {
 		if *status == moovcustomers.Rejected {
 			if c.OriginatorID != "" {
-				if err := depRepo.UpdateDepositoryStatus(id.Depository(c.OriginatorDepository), DepositoryRejected); err != nil {
+				if err := depRepo.UpdateDepositoryStatus(ctx, id.Depository(c.OriginatorDepository), DepositoryRejected); err != nil {
 					return fmt.Errorf("error updating originator depository=%s: %v", c.OriginatorDepository, err)
 				}
 			} else {
-				if err := receiverRepo.updateReceiverStatus(ReceiverID(c.ReceiverID), ReceiverSuspended); err != nil {
+				if err := receiverRepo.updateReceiverStatus(ctx, ReceiverID(c.ReceiverID), ReceiverSuspended); err != nil {
 					return fmt.Errorf("error updating receiver=%s: %v", c.ReceiverID, err)
 				}
 			}
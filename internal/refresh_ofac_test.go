@@ -5,6 +5,7 @@
 package internal
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -79,7 +80,7 @@ func TestOFACRefresh__refreshSearchIfNeeded(t *testing.T) {
 	}
 	defer ref.Close()
 
-	err := ref.refreshSearchIfNeeded(customers.Cust{
+	err := ref.refreshSearchIfNeeded(context.Background(), customers.Cust{
 		ID: base.ID(),
 	}, "")
 	if err == nil {
@@ -87,7 +88,7 @@ func TestOFACRefresh__refreshSearchIfNeeded(t *testing.T) {
 	}
 
 	client.Err = nil
-	err = ref.refreshSearchIfNeeded(customers.Cust{
+	err = ref.refreshSearchIfNeeded(context.Background(), customers.Cust{
 		ID:        base.ID(),
 		CreatedAt: time.Now().Add(-1 * 30 * 24 * time.Hour),
 	}, "")
@@ -104,10 +105,10 @@ func TestOFACRefresh__rejectRelatedCustomerObjects(t *testing.T) {
 
 	keeper := secrets.TestStringKeeper(t)
 	depRepo := NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper)
-	receiverRepo := &SQLReceiverRepo{db.DB, log.NewNopLogger()}
+	receiverRepo := NewReceiverRepo(log.NewNopLogger(), db.DB, secrets.TestStringKeeper(t))
 
 	depID := base.ID()
-	err := depRepo.UpsertUserDepository(userID, &Depository{
+	err := depRepo.UpsertUserDepository(context.Background(), userID, &Depository{
 		ID:                     id.Depository(depID),
 		BankName:               "bank name",
 		Holder:                 "holder",
@@ -136,11 +137,11 @@ func TestOFACRefresh__rejectRelatedCustomerObjects(t *testing.T) {
 		OriginatorID:         base.ID(),
 		OriginatorDepository: depID,
 	}
-	if err := rejectRelatedCustomerObjects(client, cust, "", depRepo, receiverRepo); err != nil {
+	if err := rejectRelatedCustomerObjects(context.Background(), client, cust, "", depRepo, receiverRepo); err != nil {
 		t.Fatal(err)
 	}
 
-	dep, err := depRepo.GetUserDepository(id.Depository(depID), userID)
+	dep, err := depRepo.GetUserDepository(context.Background(), id.Depository(depID), userID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -154,7 +155,7 @@ func TestOFACRefresh__rejectRelatedCustomerObjects(t *testing.T) {
 	cust.OriginatorID = ""
 	cust.ReceiverID = receiverID
 
-	err = receiverRepo.upsertUserReceiver(userID, &Receiver{
+	err = receiverRepo.upsertUserReceiver(context.Background(), userID, &Receiver{
 		ID:                ReceiverID(receiverID),
 		Email:             "test@moov.io",
 		DefaultDepository: id.Depository(base.ID()),
@@ -166,11 +167,11 @@ func TestOFACRefresh__rejectRelatedCustomerObjects(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := rejectRelatedCustomerObjects(client, cust, "", depRepo, receiverRepo); err != nil {
+	if err := rejectRelatedCustomerObjects(context.Background(), client, cust, "", depRepo, receiverRepo); err != nil {
 		t.Fatal(err)
 	}
 
-	receiver, err := receiverRepo.getUserReceiver(ReceiverID(receiverID), userID)
+	receiver, err := receiverRepo.getUserReceiver(context.Background(), ReceiverID(receiverID), userID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -10,10 +10,11 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/moov-io/paygate/internal/config"
+
 	"github.com/hashicorp/vault/api"
 	"gocloud.dev/secrets"
 	"gocloud.dev/secrets/gcpkms"
@@ -24,23 +25,100 @@ import (
 // StringKeeper wraps a secrets.Keeper but accepts and returns strings, which are easier
 // to store in a database or pass around. Encrypted and decryptable values must be in
 // base64.StdEncoding format.
+//
+// Values are prefixed with the key version which encrypted them (e.g. "v2:ab12...") so
+// they can still be decrypted by a previous Keeper after the key is rotated. Values from
+// a Keeper without a version have no prefix.
 type StringKeeper struct {
-	keeper *secrets.Keeper
-	enc    *base64.Encoding
+	keeper  *secrets.Keeper
+	version string
 
+	// previous are Keepers of rotated keys by their version, they only decrypt values.
+	previous map[string]*secrets.Keeper
+
+	enc     *base64.Encoding
 	timeout time.Duration
 }
 
 func NewStringKeeper(keeper *secrets.Keeper, timeout time.Duration) *StringKeeper {
 	return &StringKeeper{
-		keeper:  keeper,
-		enc:     base64.StdEncoding,
-		timeout: timeout,
+		keeper:   keeper,
+		previous: make(map[string]*secrets.Keeper),
+		enc:      base64.StdEncoding,
+		timeout:  timeout,
+	}
+}
+
+// NewVersionedStringKeeper returns a StringKeeper which encrypts with keeper and tags each value with version.
+// Values from previous keys can be decrypted once added with AddPreviousKeeper.
+func NewVersionedStringKeeper(keeper *secrets.Keeper, version string, timeout time.Duration) (*StringKeeper, error) {
+	if err := validateKeyVersion(version); err != nil {
+		return nil, err
 	}
+	str := NewStringKeeper(keeper, timeout)
+	str.version = version
+	return str, nil
+}
+
+// AddPreviousKeeper allows values encrypted by keeper under version to be decrypted.
+// An empty version is for values written before key versions were tagged.
+func (str *StringKeeper) AddPreviousKeeper(version string, keeper *secrets.Keeper) error {
+	if str == nil {
+		return errors.New("nil StringKeeper")
+	}
+	if err := validateKeyVersion(version); err != nil {
+		return err
+	}
+	if version == str.version {
+		return fmt.Errorf("previous key version %q is the current version", version)
+	}
+	str.previous[version] = keeper
+	return nil
+}
+
+func validateKeyVersion(version string) error {
+	if strings.ContainsAny(version, ":,= ") {
+		return fmt.Errorf("invalid key version %q", version)
+	}
+	return nil
+}
+
+// Version returns the key version of the current Keeper which EncryptString uses.
+func (str *StringKeeper) Version() string {
+	if str == nil {
+		return ""
+	}
+	return str.version
+}
+
+// HasPreviousKeys returns true when values from rotated keys can be decrypted.
+func (str *StringKeeper) HasPreviousKeys() bool {
+	return str != nil && len(str.previous) > 0
+}
+
+// KeyVersion returns the version an encrypted value is tagged with.
+func KeyVersion(encrypted string) string {
+	if idx := strings.Index(encrypted, ":"); idx > 0 {
+		return encrypted[:idx]
+	}
+	return ""
+}
+
+// NeedsRotation returns true when an encrypted value is from a key other than the current one.
+func (str *StringKeeper) NeedsRotation(encrypted string) bool {
+	return encrypted != "" && KeyVersion(encrypted) != str.Version()
 }
 
 func (str *StringKeeper) Close() error {
-	if str == nil || str.keeper == nil {
+	if str == nil {
+		return nil
+	}
+	for _, keeper := range str.previous {
+		if keeper != nil {
+			keeper.Close()
+		}
+	}
+	if str.keeper == nil {
 		return nil
 	}
 	return str.keeper.Close()
@@ -51,7 +129,6 @@ func (str *StringKeeper) EncryptString(in string) (string, error) {
 	if str == nil {
 		return "", errors.New("nil StringKeeper")
 	}
-
 	ctx, cancelFn := context.WithTimeout(context.Background(), str.timeout)
 	defer cancelFn()
 
@@ -59,6 +136,9 @@ func (str *StringKeeper) EncryptString(in string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if str.version != "" {
+		return fmt.Sprintf("%s:%s", str.version, str.enc.EncodeToString(bs)), nil
+	}
 	return str.enc.EncodeToString(bs), nil
 }
 
@@ -67,49 +147,96 @@ func (str *StringKeeper) DecryptString(in string) (string, error) {
 	if str == nil {
 		return "", errors.New("nil StringKeeper")
 	}
-
 	ctx, cancelFn := context.WithTimeout(context.Background(), str.timeout)
 	defer cancelFn()
 
+	keeper, version := str.keeper, KeyVersion(in)
+	if version != "" {
+		in = strings.TrimPrefix(in, version+":")
+	}
+	if version != str.version {
+		if k, exists := str.previous[version]; exists {
+			keeper = k
+		} else {
+			return "", fmt.Errorf("unknown key version %q", version)
+		}
+	}
+
 	bs, err := str.enc.DecodeString(in)
 	if err != nil {
 		return "", err
 	}
-	bs, err = str.keeper.Decrypt(ctx, bs)
+	bs, err = keeper.Decrypt(ctx, bs)
 	if err != nil {
 		return "", err
 	}
 	return string(bs), nil
 }
 
-type SecretFunc func(path string) (*secrets.Keeper, error)
+// RotateString decrypts a value with the Keeper that encrypted it and encrypts it with the current Keeper.
+func (str *StringKeeper) RotateString(in string) (string, error) {
+	num, err := str.DecryptString(in)
+	if err != nil {
+		return "", err
+	}
+	return str.EncryptString(num)
+}
+
+type SecretFunc func(ctx context.Context, path string) (*secrets.Keeper, error)
 
 var (
-	GetSecretKeeper SecretFunc = func(path string) (*secrets.Keeper, error) {
+	// GetSecretKeeper opens a local Keeper for path.
+	GetSecretKeeper SecretFunc = func(ctx context.Context, path string) (*secrets.Keeper, error) {
 		if path == "" {
 			return nil, errors.New("GetSecretKeeper: nil path")
 		}
 
-		ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
+		ctx, cancelFn := context.WithTimeout(ctx, 10*time.Second)
 		defer cancelFn()
 
-		return OpenSecretKeeper(ctx, path, os.Getenv("CLOUD_PROVIDER"))
+		return OpenSecretKeeper(ctx, path, &config.SecretsConfig{})
 	}
 )
 
+// OpenStringKeeper returns a StringKeeper for the configured provider's Keeper along with any previous keys.
+//
+// cfg.KeyVersion tags values from the current key. cfg.PreviousKeys are Go CDK keeper URLs for each
+// version which are only used to decrypt (e.g. 'base64key://...' or 'gcpkms://projects/...').
+// An empty version is for values written without one.
+func OpenStringKeeper(ctx context.Context, path string, cfg *config.SecretsConfig, timeout time.Duration) (*StringKeeper, error) {
+	keeper, err := OpenSecretKeeper(ctx, path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	str, err := NewVersionedStringKeeper(keeper, cfg.KeyVersion, timeout)
+	if err != nil {
+		return nil, err
+	}
+	for version, url := range cfg.PreviousKeys {
+		previous, err := secrets.OpenKeeper(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("problem opening previous key version %q: %v", version, err)
+		}
+		if err := str.AddPreviousKeeper(version, previous); err != nil {
+			return nil, err
+		}
+	}
+	return str, nil
+}
+
 // OpenSecretKeeper returns a Go Cloud Development Kit (Go CDK) Keeper object which can be used
 // to encrypt and decrypt byte slices and stored in various services.
 // Checkout https://gocloud.dev/ref/secrets/ for more details.
-func OpenSecretKeeper(ctx context.Context, path, cloudProvider string) (*secrets.Keeper, error) {
-	switch strings.ToLower(cloudProvider) {
+func OpenSecretKeeper(ctx context.Context, path string, cfg *config.SecretsConfig) (*secrets.Keeper, error) {
+	switch strings.ToLower(cfg.Provider) {
 	case "", "local":
-		return OpenLocal(os.Getenv("SECRETS_LOCAL_BASE64_KEY"))
+		return OpenLocal(cfg.Local.Base64Key)
 	case "gcp":
-		return openGCPKMS()
+		return openGCPKMS(ctx, cfg.GCP.KeyResourceID)
 	case "vault":
-		return openVault(path)
+		return openVault(ctx, path, cfg.Vault)
 	}
-	return nil, fmt.Errorf("unknown secrets cloudProvider=%s", cloudProvider)
+	return nil, fmt.Errorf("unknown secrets provider=%s", cfg.Provider)
 }
 
 // OpenLocal returns an inmemory Keeper based on a provided key.
@@ -136,14 +263,14 @@ func OpenLocal(base64Key string) (*secrets.Keeper, error) {
 
 // openGCPKMS returns a Google Cloud Key Management Service Keeper for managing secrets in Google's cloud
 //
-// The environmental variable SECRETS_GCP_KEY_RESOURCE_ID is required and has the following form:
+// The keyResourceID is required and has the following form:
 //  'projects/MYPROJECT/locations/MYLOCATION/keyRings/MYKEYRING/cryptoKeys/MYKEY'
 //
 // See https://cloud.google.com/kms/docs/object-hierarchy#key for more information
 //
 // gcpkms://projects/[PROJECT_ID]/locations/[LOCATION]/keyRings/[KEY_RING]/cryptoKeys/[KEY]
-func openGCPKMS() (*secrets.Keeper, error) {
-	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Second)
+func openGCPKMS(ctx context.Context, keyResourceID string) (*secrets.Keeper, error) {
+	ctx, cancelFn := context.WithTimeout(ctx, 10*time.Second)
 	defer cancelFn()
 
 	client, done, err := gcpkms.Dial(ctx, nil)
@@ -152,20 +279,20 @@ func openGCPKMS() (*secrets.Keeper, error) {
 	}
 	defer done()
 
-	return gcpkms.OpenKeeper(client, os.Getenv("SECRETS_GCP_KEY_RESOURCE_ID"), nil), nil
+	return gcpkms.OpenKeeper(client, keyResourceID, nil), nil
 }
 
 // openVault returns a Keeper for storing values inside of a Vault instance.
 //
 // The scheme for key values should be: vault://mykey
-func openVault(path string) (*secrets.Keeper, error) {
+func openVault(ctx context.Context, path string, cfg config.VaultSecretsConfig) (*secrets.Keeper, error) {
 	serverURL := "http://127.0.0.1:8200"
-	if v := os.Getenv("VAULT_SERVER_URL"); v != "" {
-		serverURL = v
+	if cfg.ServerURL != "" {
+		serverURL = cfg.ServerURL
 	}
 
-	client, err := hashivault.Dial(context.Background(), &hashivault.Config{
-		Token: os.Getenv("VAULT_SERVER_TOKEN"),
+	client, err := hashivault.Dial(ctx, &hashivault.Config{
+		Token: cfg.ServerToken,
 		APIConfig: api.Config{
 			Address: serverURL,
 		},
@@ -5,15 +5,20 @@
 package secrets
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/moov-io/paygate/internal/config"
 )
 
 func TestSecrets(t *testing.T) {
 	// We assume CLOUD_PROVIDER is unset
-	keeper, err := GetSecretKeeper("foo")
+	keeper, err := GetSecretKeeper(context.Background(), "foo")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -80,6 +85,113 @@ func TestStringKeeper__cycle(t *testing.T) {
 	}
 }
 
+func TestStringKeeper__rotation(t *testing.T) {
+	oldKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("2"), 32))
+
+	// values written before and after versions were tagged
+	legacy, err := testSecretKeeper(oldKey)("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyStr := NewStringKeeper(legacy, 1*time.Second)
+	legacyEnc, err := legacyStr.EncryptString("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := KeyVersion(legacyEnc); v != "" {
+		t.Errorf("unexpected version %q", v)
+	}
+
+	v1, _ := testSecretKeeper(testSecretKey)("v1")
+	v1Str, err := NewVersionedStringKeeper(v1, "v1", 1*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Enc, err := v1Str.EncryptString("456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(v1Enc, "v1:") || KeyVersion(v1Enc) != "v1" {
+		t.Errorf("unexpected value %q", v1Enc)
+	}
+
+	// rotate to a new key
+	newKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("3"), 32))
+	v2, _ := testSecretKeeper(newKey)("v2")
+	str, err := NewVersionedStringKeeper(v2, "v2", 1*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer str.Close()
+
+	if _, err := str.DecryptString(v1Enc); err == nil || !strings.Contains(err.Error(), "unknown key version") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	legacy, _ = testSecretKeeper(oldKey)("legacy")
+	v1, _ = testSecretKeeper(testSecretKey)("v1")
+	if err := str.AddPreviousKeeper("", legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := str.AddPreviousKeeper("v1", v1); err != nil {
+		t.Fatal(err)
+	}
+	if err := str.AddPreviousKeeper("v2", v1); err == nil {
+		t.Error("expected error")
+	}
+
+	for enc, expected := range map[string]string{legacyEnc: "123", v1Enc: "456"} {
+		if !str.NeedsRotation(enc) {
+			t.Errorf("%q needs rotation", enc)
+		}
+		rotated, err := str.RotateString(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str.NeedsRotation(rotated) || KeyVersion(rotated) != "v2" {
+			t.Errorf("unexpected value %q", rotated)
+		}
+		if num, err := str.DecryptString(rotated); err != nil || num != expected {
+			t.Errorf("num=%q error=%v", num, err)
+		}
+	}
+}
+
+func TestSecrets__OpenStringKeeper(t *testing.T) {
+	oldKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("2"), 32))
+	cfg := &config.SecretsConfig{
+		KeyVersion: "v2",
+		PreviousKeys: map[string]string{
+			"":   fmt.Sprintf("base64key://%s", oldKey),
+			"v1": fmt.Sprintf("base64key://%s", testSecretKey),
+		},
+	}
+
+	str, err := OpenStringKeeper(context.Background(), "", cfg, 1*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer str.Close()
+
+	if str.Version() != "v2" || len(str.previous) != 2 {
+		t.Errorf("version=%q previous=%d", str.Version(), len(str.previous))
+	}
+
+	cfg.PreviousKeys = map[string]string{"v1": "other://key"}
+	if _, err := OpenStringKeeper(context.Background(), "", cfg, 1*time.Second); err == nil {
+		t.Error("expected error")
+	}
+	cfg.PreviousKeys = nil
+	cfg.KeyVersion = "v:2"
+	if _, err := OpenStringKeeper(context.Background(), "", cfg, 1*time.Second); err == nil {
+		t.Error("expected error")
+	}
+	cfg.KeyVersion = ""
+	cfg.Provider = "other"
+	if _, err := OpenStringKeeper(context.Background(), "", cfg, 1*time.Second); err == nil {
+		t.Error("expected error")
+	}
+}
+
 func TestStringKeeper__nil(t *testing.T) {
 	keeper := TestStringKeeper(t)
 	keeper.Close()
@@ -108,6 +220,6 @@ func TestOpenSecretKeeper(t *testing.T) {
 	// Just call these and make sure they don't panic.
 	//
 	// The result depends on env variables, which in TravisCI is different than local.
-	OpenSecretKeeper(ctx, "", "gcp")
-	OpenSecretKeeper(ctx, "", "vault")
+	OpenSecretKeeper(ctx, "", &config.SecretsConfig{Provider: "gcp"})
+	OpenSecretKeeper(ctx, "", &config.SecretsConfig{Provider: "vault"})
 }
@@ -0,0 +1,118 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/tracing"
+)
+
+// traceSequenceModulus bounds the 7-digit sequence which follows the ODFI's 8-digit
+// routing number in a 15-digit trace number.
+const traceSequenceModulus = 10000000
+
+var errTraceNumbersExhausted = errors.New("every trace number for the ODFI has been used")
+
+// traceNumberRepository hands out the trace numbers of EntryDetail records we originate.
+type traceNumberRepository interface {
+	// reserve allocates count consecutive trace numbers for the ODFI and returns the first.
+	// Callers can increment the returned trace number count-1 times.
+	reserve(ctx context.Context, odfiRoutingNumber string, count int) (string, error)
+}
+
+func NewTraceNumberRepo(logger log.Logger, db *sql.DB) *SQLTraceNumberRepo {
+	return &SQLTraceNumberRepo{logger: logger, db: db}
+}
+
+// SQLTraceNumberRepo keeps a sequence for each ODFI in the trace_numbers table. Sequences are
+// incremented inside of a transaction, so trace numbers are unique across goroutines and replicas
+// sharing the database. Sequences only ever increase, so a trace number is never reused for an ODFI
+// and returns can't be matched to an older transfer.
+type SQLTraceNumberRepo struct {
+	db     *sql.DB
+	logger log.Logger
+}
+
+func (r *SQLTraceNumberRepo) Close() error {
+	return r.db.Close()
+}
+
+func (r *SQLTraceNumberRepo) reserve(ctx context.Context, odfiRoutingNumber string, count int) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "traceNumberRepo.reserve", "odfi", odfiRoutingNumber, "count", count)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	if count < 1 || count >= traceSequenceModulus {
+		return "", fmt.Errorf("invalid trace number count: %d", count)
+	}
+	odfi := aba8(odfiRoutingNumber)
+	if len(odfi) != 8 {
+		return "", fmt.Errorf("invalid ODFI routing number: %q", odfiRoutingNumber)
+	}
+	for attempts := 0; attempts < 5; attempts++ {
+		last, err := r.increment(ctx, odfi, count)
+		if err != nil {
+			if database.UniqueViolation(err) {
+				continue // another replica created this ODFI's sequence first
+			}
+			if err == errTraceNumbersExhausted {
+				r.logger.Log("traceNumbers", fmt.Sprintf("ERROR: unable to reserve %d trace numbers for ODFI %s: %v", count, odfi, err))
+			}
+			return "", fmt.Errorf("trace numbers: %v", err)
+		}
+		return fmt.Sprintf("%s%07d", odfi, last-int64(count)+1), nil
+	}
+	return "", fmt.Errorf("trace numbers: unable to reserve %d for ODFI %s", count, odfi)
+}
+
+// increment advances the ODFI's sequence by count and returns its new value. errTraceNumbersExhausted
+// is returned instead of overflowing the 7-digit sequence.
+func (r *SQLTraceNumberRepo) increment(ctx context.Context, odfi string, count int) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Updating first locks the row until we commit.
+	now := time.Now()
+	query := `update trace_numbers set sequence = sequence + ?, updated_at = ? where odfi_identification = ?`
+	res, err := tx.ExecContext(ctx, query, count, now, odfi)
+	if err != nil {
+		return 0, r.rollback(tx, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := tx.ExecContext(ctx, `insert into trace_numbers (odfi_identification, sequence, updated_at) values (?, ?, ?)`, odfi, count, now); err != nil {
+			return 0, r.rollback(tx, err)
+		}
+		return int64(count), tx.Commit()
+	}
+
+	var sequence int64
+	if err := tx.QueryRowContext(ctx, `select sequence from trace_numbers where odfi_identification = ?`, odfi).Scan(&sequence); err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New("sequence not found")
+		}
+		return 0, r.rollback(tx, err)
+	}
+	if sequence >= traceSequenceModulus {
+		return 0, r.rollback(tx, errTraceNumbersExhausted)
+	}
+	return sequence, tx.Commit()
+}
+
+func (r *SQLTraceNumberRepo) rollback(tx *sql.Tx, err error) error {
+	if rollbackErr := tx.Rollback(); rollbackErr != nil {
+		return fmt.Errorf("%v (rollback: %v)", err, rollbackErr)
+	}
+	return err
+}
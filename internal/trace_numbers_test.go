@@ -0,0 +1,212 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/moov-io/paygate/internal/database"
+)
+
+type mockTraceNumberRepository struct {
+	mu        sync.Mutex
+	sequences map[string]int
+
+	Err error
+}
+
+func (r *mockTraceNumberRepository) reserve(ctx context.Context, odfiRoutingNumber string, count int) (string, error) {
+	if r.Err != nil {
+		return "", r.Err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sequences == nil {
+		r.sequences = make(map[string]int)
+	}
+	odfi := aba8(odfiRoutingNumber)
+	first := r.sequences[odfi] + 1
+	r.sequences[odfi] += count
+	return fmt.Sprintf("%s%07d", odfi, first), nil
+}
+
+func TestTraceNumbers__reserve(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLTraceNumberRepo) {
+		if v, err := repo.reserve(context.Background(), "121042882", 1); err != nil || v != "121042880000001" {
+			t.Fatalf("trace=%s error=%v", v, err)
+		}
+		if v, err := repo.reserve(context.Background(), "121042882", 3); err != nil || v != "121042880000002" {
+			t.Fatalf("trace=%s error=%v", v, err)
+		}
+		if v, err := repo.reserve(context.Background(), "121042882", 1); err != nil || v != "121042880000005" {
+			t.Fatalf("trace=%s error=%v", v, err)
+		}
+
+		// each ODFI has its own sequence
+		if v, err := repo.reserve(context.Background(), "231380104", 1); err != nil || v != "231380100000001" {
+			t.Fatalf("trace=%s error=%v", v, err)
+		}
+
+		// invalid input
+		if _, err := repo.reserve(context.Background(), "1234", 1); err == nil {
+			t.Error("expected error")
+		}
+		if _, err := repo.reserve(context.Background(), "121042882", 0); err == nil {
+			t.Error("expected error")
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewTraceNumberRepo(log.NewNopLogger(), sqliteDB.DB))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewTraceNumberRepo(log.NewNopLogger(), postgresDB.DB))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewTraceNumberRepo(log.NewNopLogger(), mysqlDB.DB))
+}
+
+func TestTraceNumbers__reserveExhausted(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	repo := NewTraceNumberRepo(log.NewNopLogger(), db.DB)
+	if _, err := repo.reserve(context.Background(), "121042882", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB.Exec(`update trace_numbers set sequence = ? where odfi_identification = ?`, traceSequenceModulus-3, "12104288"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The last trace numbers can be reserved
+	if v, err := repo.reserve(context.Background(), "121042882", 2); err != nil || v != "121042889999998" {
+		t.Fatalf("trace=%s error=%v", v, err)
+	}
+
+	// The sequence doesn't wrap around into trace numbers already used
+	if v, err := repo.reserve(context.Background(), "121042882", 1); err == nil || !strings.Contains(err.Error(), errTraceNumbersExhausted.Error()) {
+		t.Fatalf("trace=%s error=%v", v, err)
+	}
+	var sequence int64
+	if err := db.DB.QueryRow(`select sequence from trace_numbers where odfi_identification = ?`, "12104288").Scan(&sequence); err != nil {
+		t.Fatal(err)
+	}
+	if sequence != traceSequenceModulus-1 {
+		t.Errorf("sequence=%d", sequence)
+	}
+}
+
+func TestTraceNumbers__reserveNextDay(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	repo := NewTraceNumberRepo(log.NewNopLogger(), db.DB)
+	seen := make(map[string]bool)
+	reserve := func(count int) string {
+		t.Helper()
+		v, err := repo.reserve(context.Background(), "121042882", count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first, _ := strconv.ParseInt(v, 10, 64)
+		for i := int64(0); i < int64(count); i++ {
+			trace := strconv.FormatInt(first+i, 10)
+			if seen[trace] {
+				t.Fatalf("trace number %s was reused", trace)
+			}
+			seen[trace] = true
+		}
+		return v
+	}
+	reserve(3)
+	reserve(1)
+
+	// pretend the sequence was last used yesterday
+	yesterday := time.Now().Add(-24 * time.Hour)
+	if _, err := db.DB.Exec(`update trace_numbers set updated_at = ? where odfi_identification = ?`, yesterday, "12104288"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sequence keeps increasing across days
+	if v := reserve(2); v != "121042880000005" {
+		t.Errorf("trace=%s", v)
+	}
+	if v := reserve(1); v != "121042880000007" {
+		t.Errorf("trace=%s", v)
+	}
+}
+
+func TestTraceNumbers__reserveConcurrent(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLTraceNumberRepo) {
+		var (
+			mu     sync.Mutex
+			traces = make(map[string]bool)
+			wg     sync.WaitGroup
+		)
+		errs := make(chan error, 50)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := repo.reserve(context.Background(), "121042882", 1)
+				if err != nil {
+					errs <- err
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if traces[v] {
+					errs <- errors.New("duplicate trace number " + v)
+				}
+				traces[v] = true
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+		if len(traces) != 50 {
+			t.Errorf("got %d trace numbers", len(traces))
+		}
+		if !traces["121042880000001"] || !traces["121042880000050"] {
+			t.Errorf("unexpected trace numbers: %v", traces)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, NewTraceNumberRepo(log.NewNopLogger(), sqliteDB.DB))
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, NewTraceNumberRepo(log.NewNopLogger(), postgresDB.DB))
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, NewTraceNumberRepo(log.NewNopLogger(), mysqlDB.DB))
+}
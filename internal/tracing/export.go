@@ -0,0 +1,76 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var (
+	exportErrors = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Name: "tracing_export_errors",
+		Help: "Counter of errors from exporting spans",
+	}, nil)
+)
+
+// newExporter returns the SpanExporter cfg describes, or nil when tracing is off. Errors from
+// exporting spans in the background are logged.
+func newExporter(logger log.Logger, cfg *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	var exp sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "":
+		return nil, nil
+	case "stdout":
+		exp, err = stdouttrace.New()
+	case "otlp":
+		exp, err = newOTLPExporter(cfg.OTLP)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem creating %s tracing exporter: %v", cfg.Exporter, err)
+	}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		exportErrors.Add(1)
+		logger.Log("tracing", fmt.Sprintf("problem exporting spans: %v", err))
+	}))
+	return exp, nil
+}
+
+// newOTLPExporter sends spans to the collector at cfg.Endpoint with OTLP over HTTP.
+func newOTLPExporter(cfg config.OTLPConfig) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(u.Path, "/") + "/v1/traces"),
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, otlptracehttp.WithTimeout(cfg.Timeout))
+	}
+	return otlptracehttp.New(context.Background(), opts...)
+}
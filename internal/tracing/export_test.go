@@ -0,0 +1,100 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewExporter(t *testing.T) {
+	if exp, err := newExporter(log.NewNopLogger(), &config.TracingConfig{}); exp != nil || err != nil {
+		t.Errorf("exp=%#v error=%v", exp, err)
+	}
+	if exp, err := newExporter(log.NewNopLogger(), &config.TracingConfig{Exporter: "zipkin"}); exp != nil || err == nil {
+		t.Errorf("exp=%#v error=%v", exp, err)
+	}
+	exp, err := newExporter(log.NewNopLogger(), &config.TracingConfig{Exporter: "stdout"})
+	if exp == nil || err != nil {
+		t.Fatalf("exp=%#v error=%v", exp, err)
+	}
+	exp.Shutdown(context.Background())
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body coltracepb.ExportTraceServiceRequest
+	var apiKey string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("unexpected request: %s %v", r.URL.Path, r.Header)
+		}
+		apiKey = r.Header.Get("X-Api-Key")
+		bs, _ := ioutil.ReadAll(r.Body)
+		if err := proto.Unmarshal(bs, &body); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	exp, err := newOTLPExporter(config.OTLPConfig{
+		Endpoint: collector.URL + "/",
+		Headers:  map[string]string{"X-Api-Key": "secret"},
+		Timeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newProvider("paygate", 1.0, sdktrace.NewSimpleSpanProcessor(exp))
+	install(p)
+	_, span := Start(context.Background(), "test", "name", "alice")
+	span.End()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if apiKey != "secret" {
+		t.Errorf("unexpected X-Api-Key: %q", apiKey)
+	}
+	if len(body.ResourceSpans) != 1 || len(body.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected request: %v", &body)
+	}
+	resource := body.ResourceSpans[0].Resource.Attributes
+	if len(resource) == 0 || resource[0].Key != "service.name" || resource[0].Value.GetStringValue() != "paygate" {
+		t.Errorf("unexpected resource: %v", resource)
+	}
+	spans := body.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 || spans[0].Name != "test" || spans[0].Attributes[0].Value.GetStringValue() != "alice" {
+		t.Errorf("unexpected spans: %v", spans)
+	}
+}
+
+func TestOTLPExporter__error(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer collector.Close()
+
+	exp, err := newOTLPExporter(config.OTLPConfig{Endpoint: collector.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stubs := tracetest.SpanStubs{{Name: "test"}}
+	if err := exp.ExportSpans(context.Background(), stubs.Snapshots()); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// propagator carries the trace a request is part of between services in the W3C traceparent header.
+//
+// See: https://www.w3.org/TR/trace-context/#traceparent-header
+var propagator = propagation.TraceContext{}
+
+// Handler is HTTP middleware which starts a Span for each request, continuing any trace the caller
+// started. The request's X-Request-ID is carried along with the Span so the services paygate calls
+// see the same ID.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if current() == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx = WithRequestID(ctx, moovhttp.GetRequestID(r))
+		ctx, span := start(ctx, routeName(r), trace.SpanKindServer, "http.method", r.Method, "http.target", r.URL.Path)
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes("http.status_code", sw.status)
+		if userID := moovhttp.GetUserID(r); userID != "" {
+			span.SetAttributes("enduser.id", userID)
+		}
+		if sw.status >= 500 {
+			span.RecordError(fmt.Errorf("HTTP status %d", sw.status))
+		}
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// routeName returns the method and path template of the route r matched, e.g. "POST /transfers"
+func routeName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return r.Method + " " + tpl
+		}
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// Transport wraps rt so requests made with a Span in their context get a client Span of their own
+// and pass the trace and X-Request-ID along to the server. A nil rt uses http.DefaultTransport.
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{next: rt}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if FromContext(req.Context()) == nil {
+		return t.next.RoundTrip(req)
+	}
+	ctx, span := start(req.Context(), fmt.Sprintf("%s %s", req.Method, req.URL.Host), trace.SpanKindClient,
+		"http.method", req.Method, "http.url", req.URL.Scheme+"://"+req.URL.Host+req.URL.Path)
+	defer span.End()
+
+	// RoundTrippers can't modify the request they're given
+	req = req.WithContext(ctx)
+	req.Header = req.Header.Clone()
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if requestID := RequestID(ctx); requestID != "" && req.Header.Get("X-Request-ID") == "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return resp, err
+	}
+	span.SetAttributes("http.status_code", resp.StatusCode)
+	if resp.StatusCode >= 500 {
+		span.RecordError(fmt.Errorf("HTTP status %d", resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHandler(t *testing.T) {
+	p, recorder := testProvider(1.0)
+
+	// the service we call sees our trace and request ID
+	var traceparent, requestID string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent, requestID = r.Header.Get("traceparent"), r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+	httpClient := &http.Client{Transport: Transport(nil)}
+
+	router := mux.NewRouter()
+	router.Use(Handler)
+	router.Methods("POST").Path("/transfers/{transferId}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequest("GET", upstream.URL+"/ping", nil)
+		resp, err := httpClient.Do(req.WithContext(r.Context()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	req := httptest.NewRequest("POST", "/transfers/foo", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "request")
+	req.Header.Set("X-User-ID", "alice")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	server, client := find(recorder, "POST /transfers/{transferId}"), find(recorder, "GET "+strings.TrimPrefix(upstream.URL, "http://"))
+	if server == nil || client == nil {
+		t.Fatalf("missing spans: %#v", recorder.Ended())
+	}
+
+	// the server span continues the caller's trace
+	if server.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.Parent().SpanID().String() != "00f067aa0ba902b7" || server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("unexpected server span: %#v", server)
+	}
+	if attr(server, "http.status_code") != int64(http.StatusServiceUnavailable) || attr(server, "http.request_id") != "request" || attr(server, "enduser.id") != "alice" {
+		t.Errorf("unexpected attributes: %#v", server.Attributes())
+	}
+	if server.Status().Code != codes.Error {
+		t.Error("expected 503 to be recorded as an error")
+	}
+
+	// the client span is a child of the server span
+	if client.SpanContext().TraceID() != server.SpanContext().TraceID() || client.Parent().SpanID() != server.SpanContext().SpanID() || client.SpanKind() != trace.SpanKindClient {
+		t.Errorf("unexpected client span: %#v", client)
+	}
+	if attr(client, "http.status_code") != int64(http.StatusTeapot) {
+		t.Errorf("unexpected attributes: %#v", client.Attributes())
+	}
+	want := fmt.Sprintf("00-%s-%s-01", client.SpanContext().TraceID(), client.SpanContext().SpanID())
+	if traceparent != want || requestID != "request" {
+		t.Errorf("traceparent=%q requestID=%q", traceparent, requestID)
+	}
+}
+
+func TestHandler__disabled(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(Handler)
+	router.Methods("GET").Path("/ping").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span := FromContext(r.Context()); span != nil {
+			t.Errorf("unexpected span: %#v", span)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+}
+
+func TestTransport__noSpan(t *testing.T) {
+	p, recorder := testProvider(1.0)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := r.Header.Get("traceparent"); v != "" {
+			t.Errorf("unexpected traceparent: %q", v)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	// requests made outside of a trace don't start one
+	client := &http.Client{Transport: Transport(nil)}
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	p.Shutdown(context.Background())
+	if n := len(recorder.Ended()); n != 0 {
+		t.Errorf("got %d spans", n)
+	}
+}
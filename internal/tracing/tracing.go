@@ -0,0 +1,213 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+// Package tracing records spans for a request as it moves through paygate and the services
+// it calls with the OpenTelemetry SDK. Spans are carried in a context.Context and propagated
+// to other services with the W3C traceparent header. Tracing is off, and every Span is nil,
+// until Init is called.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/moov-io/paygate"
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans paygate records.
+const instrumentationName = "github.com/moov-io/paygate"
+
+// Span is one timed operation in a trace. A nil Span is valid and records nothing, which is
+// what Start returns when tracing is off.
+type Span struct {
+	span trace.Span
+}
+
+// TraceID returns the hex encoded ID of the trace s belongs to.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.span.SpanContext().TraceID().String()
+}
+
+// SetAttributes records keyvals, alternating keys and values like a log.Logger, on s.
+func (s *Span) SetAttributes(keyvals ...interface{}) {
+	if s == nil || !s.span.IsRecording() {
+		return
+	}
+	s.span.SetAttributes(attributes(keyvals)...)
+}
+
+// RecordError marks s as failed with err. Nil errors are ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End finishes s and queues it for export. Only the first call has any effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.span.End()
+}
+
+func attributes(keyvals []interface{}) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprintf("%v", keyvals[i])
+		if i+1 >= len(keyvals) {
+			attrs = append(attrs, attribute.String(key, "(MISSING)"))
+			continue
+		}
+		switch v := keyvals[i+1].(type) {
+		case string:
+			attrs = append(attrs, attribute.String(key, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(key, v))
+		case int:
+			attrs = append(attrs, attribute.Int(key, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(key, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(key, v))
+		default:
+			attrs = append(attrs, attribute.String(key, fmt.Sprintf("%v", v)))
+		}
+	}
+	return attrs
+}
+
+type requestIDKey struct{}
+
+// FromContext returns the Span started in ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	if current() == nil {
+		return nil
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil
+	}
+	return &Span{span: span}
+}
+
+// WithRequestID returns a copy of ctx carrying the X-Request-ID of the request being served.
+// It's recorded on every Span started from ctx and passed along to other services.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the X-Request-ID set on ctx with WithRequestID.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Start begins a Span called name as a child of the Span in ctx, or of the trace a caller
+// propagated to us. keyvals are recorded as attributes. The returned context carries the new
+// Span and End must be called on it.
+func Start(ctx context.Context, name string, keyvals ...interface{}) (context.Context, *Span) {
+	return start(ctx, name, trace.SpanKindInternal, keyvals...)
+}
+
+func start(ctx context.Context, name string, kind trace.SpanKind, keyvals ...interface{}) (context.Context, *Span) {
+	p := current()
+	if p == nil {
+		return ctx, nil
+	}
+	ctx, span := p.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+	s := &Span{span: span}
+	if requestID := RequestID(ctx); requestID != "" {
+		s.SetAttributes("http.request_id", requestID)
+	}
+	s.SetAttributes(keyvals...)
+	return ctx, s
+}
+
+// Provider exports the spans started while it's installed.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+var (
+	providerMu sync.RWMutex
+	provider   *Provider
+)
+
+func current() *Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return provider
+}
+
+func install(p *Provider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	provider = p
+}
+
+// Init starts exporting spans as cfg describes and installs the Provider used by Start. Nothing
+// is installed, and a nil Provider is returned, when cfg has no exporter.
+func Init(logger log.Logger, cfg *config.TracingConfig) (*Provider, error) {
+	exp, err := newExporter(logger, cfg)
+	if exp == nil || err != nil {
+		return nil, err
+	}
+	logger.Log("tracing", fmt.Sprintf("exporting %v of traces to %s", cfg.SampleRatio, cfg.Exporter))
+
+	p := newProvider(cfg.ServiceName, cfg.SampleRatio, sdktrace.NewBatchSpanProcessor(exp))
+	install(p)
+	return p, nil
+}
+
+// newProvider records a sampleRatio of new traces, and follows the caller's decision for
+// traces propagated to us, handing ended spans to processor.
+func newProvider(serviceName string, sampleRatio float64, processor sdktrace.SpanProcessor) *Provider {
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(paygate.Version),
+		)),
+	)
+	return &Provider{
+		provider: tp,
+		tracer:   tp.Tracer(instrumentationName, trace.WithInstrumentationVersion(paygate.Version)),
+	}
+}
+
+// Shutdown uninstalls p and exports the spans which have ended, waiting until ctx is done.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	providerMu.Lock()
+	if provider == p {
+		provider = nil
+	}
+	providerMu.Unlock()
+
+	return p.provider.Shutdown(ctx)
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moov-io/paygate/internal/config"
+
+	"github.com/go-kit/kit/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// testProvider installs a Provider which records ended spans in memory.
+func testProvider(sampleRatio float64) (*Provider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	p := newProvider("paygate", sampleRatio, recorder)
+	install(p)
+	return p, recorder
+}
+
+func find(recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	spans := recorder.Ended()
+	for i := range spans {
+		if spans[i].Name() == name {
+			return spans[i]
+		}
+	}
+	return nil
+}
+
+func attr(span sdktrace.ReadOnlySpan, key string) interface{} {
+	attrs := span.Attributes()
+	for i := range attrs {
+		if attrs[i].Key == attribute.Key(key) {
+			return attrs[i].Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+func TestStart__disabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "test")
+	if span != nil || FromContext(ctx) != nil {
+		t.Fatalf("unexpected span: %#v", span)
+	}
+	// nil spans can be used
+	span.SetAttributes("key", "value")
+	span.RecordError(errors.New("bad thing"))
+	span.End()
+	if span.TraceID() != "" {
+		t.Errorf("unexpected trace ID: %q", span.TraceID())
+	}
+}
+
+func TestStart(t *testing.T) {
+	p, recorder := testProvider(1.0)
+
+	ctx := WithRequestID(context.Background(), "request")
+	ctx, parent := Start(ctx, "parent", "userID", "alice", "transfers", 2)
+	_, child := Start(ctx, "child", "missing")
+	child.RecordError(errors.New("bad thing"))
+	child.End()
+	child.End() // only exported once
+	parent.End()
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if current() != nil {
+		t.Error("expected provider to be uninstalled")
+	}
+	if n := len(recorder.Ended()); n != 2 {
+		t.Fatalf("got %d spans", n)
+	}
+	parentSpan, childSpan := find(recorder, "parent"), find(recorder, "child")
+
+	if parent.TraceID() != child.TraceID() || childSpan.Parent().SpanID() != parentSpan.SpanContext().SpanID() || parentSpan.Parent().IsValid() {
+		t.Errorf("child isn't part of parent's trace: %#v %#v", parentSpan.SpanContext(), childSpan.Parent())
+	}
+	if v := attr(parentSpan, "userID"); v != "alice" {
+		t.Errorf("unexpected userID: %v", v)
+	}
+	if v := attr(parentSpan, "transfers"); v != int64(2) {
+		t.Errorf("unexpected transfers: %#v", v)
+	}
+	if v := attr(childSpan, "http.request_id"); v != "request" {
+		t.Errorf("unexpected requestID: %v", v)
+	}
+	if v := attr(childSpan, "missing"); v != "(MISSING)" {
+		t.Errorf("unexpected value: %v", v)
+	}
+	if status := childSpan.Status(); status.Code != codes.Error || status.Description != "bad thing" {
+		t.Errorf("unexpected status: %#v", status)
+	}
+	if v := childSpan.Resource().Attributes(); len(v) == 0 {
+		t.Error("missing resource attributes")
+	}
+
+	// new traces start once the provider is gone
+	if _, span := Start(ctx, "after"); span != nil {
+		t.Errorf("unexpected span: %#v", span)
+	}
+}
+
+func TestProvider__sample(t *testing.T) {
+	p, recorder := testProvider(0)
+	defer p.Shutdown(context.Background())
+
+	// new traces follow the sample ratio
+	_, span := Start(context.Background(), "new")
+	span.End()
+
+	// unsampled traces from a caller are propagated, but not exported
+	carrier := propagation.HeaderCarrier{}
+	carrier.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx := propagator.Extract(context.Background(), carrier)
+	_, span = Start(ctx, "unsampled")
+	span.SetAttributes("key", "value")
+	span.End()
+	if span == nil || span.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected span: %#v", span)
+	}
+
+	// sampled traces from a caller are exported
+	carrier.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span = Start(propagator.Extract(context.Background(), carrier), "sampled")
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "sampled" {
+		t.Errorf("unexpected spans: %#v", spans)
+	}
+}
+
+func TestInit(t *testing.T) {
+	p, err := Init(log.NewNopLogger(), &config.TracingConfig{})
+	if p != nil || err != nil {
+		t.Errorf("p=%#v error=%v", p, err)
+	}
+	if p, err := Init(log.NewNopLogger(), &config.TracingConfig{Exporter: "zipkin"}); p != nil || err == nil {
+		t.Errorf("p=%#v error=%v", p, err)
+	}
+
+	p, err = Init(log.NewNopLogger(), &config.TracingConfig{Exporter: "stdout", SampleRatio: 1})
+	if p == nil || err != nil {
+		t.Fatalf("p=%#v error=%v", p, err)
+	}
+	if current() != p {
+		t.Error("expected provider to be installed")
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,12 +5,12 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"strings"
 	"time"
@@ -21,9 +21,12 @@ import (
 	"github.com/moov-io/base"
 	"github.com/moov-io/base/idempotent"
 	moovcustomers "github.com/moov-io/customers"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/customers"
 	"github.com/moov-io/paygate/internal/events"
 	"github.com/moov-io/paygate/internal/route"
+	"github.com/moov-io/paygate/internal/tracing"
+	"github.com/moov-io/paygate/internal/util"
 	"github.com/moov-io/paygate/pkg/achclient"
 	"github.com/moov-io/paygate/pkg/id"
 
@@ -31,10 +34,6 @@ import (
 	"github.com/gorilla/mux"
 )
 
-var (
-	traceNumberSource = rand.NewSource(time.Now().Unix())
-)
-
 type TransferID string
 
 func (id TransferID) Equal(s string) bool {
@@ -63,6 +62,9 @@ type Transfer struct {
 	// ReceiverDepository is the id.Depository associated with this transaction
 	ReceiverDepository id.Depository `json:"receiverDepository"`
 
+	// Authorization is the Receiver's Authorization for the Originator to debit them, required for pull transfers
+	Authorization AuthorizationID `json:"authorization,omitempty"`
+
 	// Description is a brief summary of the transaction that may appear on the receiving entity’s financial statement
 	Description string `json:"description"`
 
@@ -75,15 +77,44 @@ type Transfer struct {
 	// SameDay indicates that the transfer should be processed the same day if possible.
 	SameDay bool `json:"sameDay"`
 
+	// PaymentRelatedInformation is optional text sent to the Receiver in an Addenda05 record, up to 80 characters.
+	PaymentRelatedInformation string `json:"paymentRelatedInformation,omitempty"`
+
+	// IdentificationNumber optionally identifies the Receiver to the Originator (e.g. an invoice or employee ID), up to 15 characters.
+	// A random value is used when it's not set.
+	IdentificationNumber string `json:"identificationNumber,omitempty"`
+
+	// DiscretionaryData is optional data for the Originator and ODFI's use, up to 2 characters.
+	DiscretionaryData string `json:"discretionaryData,omitempty"`
+
+	// TraceNumber is assigned when the Transfer is created and uniquely identifies its EntryDetail record.
+	// It's the ODFI's 8-digit routing number followed by a 7-digit sequence.
+	TraceNumber string `json:"traceNumber,omitempty"`
+
 	// Created a timestamp representing the initial creation date of the object in ISO 8601
 	Created base.Time `json:"created"`
 
+	// ARCDetail is an optional struct which enables sending ARC ACH transfers.
+	ARCDetail *ARCDetail `json:"ARCDetail,omitempty"`
+
+	// BOCDetail is an optional struct which enables sending BOC ACH transfers.
+	BOCDetail *BOCDetail `json:"BOCDetail,omitempty"`
+
 	// CCDDetail is an optional struct which enables sending CCD ACH transfers.
 	CCDDetail *CCDDetail `json:"CCDDetail,omitempty"`
 
+	// CTXDetail is an optional struct which enables sending CTX ACH transfers.
+	CTXDetail *CTXDetail `json:"CTXDetail,omitempty"`
+
 	// IATDetail is an optional struct which enables sending IAT ACH transfers.
 	IATDetail *IATDetail `json:"IATDetail,omitempty"`
 
+	// POPDetail is an optional struct which enables sending POP ACH transfers.
+	POPDetail *POPDetail `json:"POPDetail,omitempty"`
+
+	// RCKDetail is an optional struct which enables sending RCK ACH transfers.
+	RCKDetail *RCKDetail `json:"RCKDetail,omitempty"`
+
 	// TELDetail is an optional struct which enables sending TEL ACH transfers.
 	TELDetail *TELDetail `json:"TELDetail,omitempty"`
 
@@ -115,24 +146,39 @@ func (t *Transfer) validate() error {
 }
 
 type transferRequest struct {
-	Type                   TransferType  `json:"transferType"`
-	Amount                 Amount        `json:"amount"`
-	Originator             OriginatorID  `json:"originator"`
-	OriginatorDepository   id.Depository `json:"originatorDepository"`
-	Receiver               ReceiverID    `json:"receiver"`
-	ReceiverDepository     id.Depository `json:"receiverDepository"`
-	Description            string        `json:"description,omitempty"`
-	StandardEntryClassCode string        `json:"standardEntryClassCode"`
-	SameDay                bool          `json:"sameDay,omitempty"`
-
+	Type                   TransferType    `json:"transferType"`
+	Amount                 Amount          `json:"amount"`
+	Originator             OriginatorID    `json:"originator"`
+	OriginatorDepository   id.Depository   `json:"originatorDepository"`
+	Receiver               ReceiverID      `json:"receiver"`
+	ReceiverDepository     id.Depository   `json:"receiverDepository"`
+	Authorization          AuthorizationID `json:"authorization,omitempty"`
+	Description            string          `json:"description,omitempty"`
+	StandardEntryClassCode string          `json:"standardEntryClassCode"`
+	SameDay                bool            `json:"sameDay,omitempty"`
+
+	PaymentRelatedInformation string `json:"paymentRelatedInformation,omitempty"`
+	IdentificationNumber      string `json:"identificationNumber,omitempty"`
+	DiscretionaryData         string `json:"discretionaryData,omitempty"`
+
+	ARCDetail *ARCDetail `json:"ARCDetail,omitempty"`
+	BOCDetail *BOCDetail `json:"BOCDetail,omitempty"`
 	CCDDetail *CCDDetail `json:"CCDDetail,omitempty"`
+	CTXDetail *CTXDetail `json:"CTXDetail,omitempty"`
 	IATDetail *IATDetail `json:"IATDetail,omitempty"`
+	POPDetail *POPDetail `json:"POPDetail,omitempty"`
+	RCKDetail *RCKDetail `json:"RCKDetail,omitempty"`
 	TELDetail *TELDetail `json:"TELDetail,omitempty"`
 	WEBDetail *WEBDetail `json:"WEBDetail,omitempty"`
 
 	// Internal fields for auditing and tracing
 	fileID        string
 	transactionID string
+	traceNumber   string
+
+	// consumeAuthorization is set for single use Authorizations, which are marked as used
+	// in the same transaction the Transfer is saved in.
+	consumeAuthorization bool
 }
 
 func (r transferRequest) missingFields() error {
@@ -149,6 +195,9 @@ func (r transferRequest) missingFields() error {
 	check("receiver", string(r.Receiver))
 	check("receiverDepository", string(r.ReceiverDepository))
 	check("standardEntryClassCode", string(r.StandardEntryClassCode))
+	if r.Type == PullTransfer {
+		check("authorization", string(r.Authorization))
+	}
 
 	if len(missing) > 0 {
 		return fmt.Errorf("missing %s JSON field(s)", strings.Join(missing, ", "))
@@ -165,19 +214,35 @@ func (r transferRequest) asTransfer(id string) *Transfer {
 		OriginatorDepository:   r.OriginatorDepository,
 		Receiver:               r.Receiver,
 		ReceiverDepository:     r.ReceiverDepository,
+		Authorization:          r.Authorization,
 		Description:            r.Description,
 		StandardEntryClassCode: r.StandardEntryClassCode,
 		Status:                 TransferPending,
 		SameDay:                r.SameDay,
+		TraceNumber:            r.traceNumber,
 		Created:                base.Now(),
+
+		PaymentRelatedInformation: r.PaymentRelatedInformation,
+		IdentificationNumber:      r.IdentificationNumber,
+		DiscretionaryData:         r.DiscretionaryData,
 	}
 	// Copy along the YYYDetail sub-object for specific SEC codes
 	// where we expect one in the JSON request body.
 	switch xfer.StandardEntryClassCode {
+	case ach.ARC:
+		xfer.ARCDetail = r.ARCDetail
+	case ach.BOC:
+		xfer.BOCDetail = r.BOCDetail
 	case ach.CCD:
 		xfer.CCDDetail = r.CCDDetail
+	case ach.CTX:
+		xfer.CTXDetail = r.CTXDetail
 	case ach.IAT:
 		xfer.IATDetail = r.IATDetail
+	case ach.POP:
+		xfer.POPDetail = r.POPDetail
+	case ach.RCK:
+		xfer.RCKDetail = r.RCKDetail
 	case ach.TEL:
 		xfer.TELDetail = r.TELDetail
 	case ach.WEB:
@@ -228,6 +293,17 @@ func (ts TransferStatus) Equal(other TransferStatus) bool {
 	return strings.EqualFold(string(ts), string(other))
 }
 
+// releasesAuthorization returns true for statuses of Transfers which didn't move funds, so their
+// single use Authorization can be used again.
+func (ts TransferStatus) releasesAuthorization() bool {
+	switch ts {
+	case TransferCanceled, TransferFailed, TransferReclaimed:
+		return true
+	default:
+		return false
+	}
+}
+
 func (ts TransferStatus) validate() error {
 	switch ts {
 	case TransferCanceled, TransferFailed, TransferPending, TransferProcessed, TransferReclaimed:
@@ -255,8 +331,10 @@ type TransferRouter struct {
 	depRepo            DepositoryRepository
 	eventRepo          events.Repository
 	receiverRepository receiverRepository
+	authorizationRepo  AuthorizationRepository
 	origRepo           originatorRepository
 	transferRepo       TransferRepository
+	traceNumbers       traceNumberRepository
 
 	achClientFactory func(userID id.User) *achclient.ACH
 
@@ -269,8 +347,10 @@ func NewTransferRouter(
 	depositoryRepo DepositoryRepository,
 	eventRepo events.Repository,
 	receiverRepo receiverRepository,
+	authorizationRepo AuthorizationRepository,
 	originatorsRepo originatorRepository,
 	transferRepo TransferRepository,
+	traceNumbers traceNumberRepository,
 	achClientFactory func(userID id.User) *achclient.ACH,
 	accountsClient AccountsClient,
 	customersClient customers.Client,
@@ -280,8 +360,10 @@ func NewTransferRouter(
 		depRepo:            depositoryRepo,
 		eventRepo:          eventRepo,
 		receiverRepository: receiverRepo,
+		authorizationRepo:  authorizationRepo,
 		origRepo:           originatorsRepo,
 		transferRepo:       transferRepo,
+		traceNumbers:       traceNumbers,
 		achClientFactory:   achClientFactory,
 		accountsClient:     accountsClient,
 		customersClient:    customersClient,
@@ -289,17 +371,17 @@ func NewTransferRouter(
 }
 
 func (c *TransferRouter) RegisterRoutes(router *mux.Router) {
-	router.Methods("GET").Path("/transfers").HandlerFunc(c.getUserTransfers())
-	router.Methods("GET").Path("/transfers/{transferId}").HandlerFunc(c.getUserTransfer())
+	router.Methods("GET").Path("/transfers").HandlerFunc(auth.Require(auth.TransfersRead, c.getUserTransfers()))
+	router.Methods("GET").Path("/transfers/{transferId}").HandlerFunc(auth.Require(auth.TransfersRead, c.getUserTransfer()))
 
-	router.Methods("POST").Path("/transfers").HandlerFunc(c.createUserTransfers())
-	router.Methods("POST").Path("/transfers/batch").HandlerFunc(c.createUserTransfers())
+	router.Methods("POST").Path("/transfers").HandlerFunc(auth.Require(auth.TransfersWrite, c.createUserTransfers()))
+	router.Methods("POST").Path("/transfers/batch").HandlerFunc(auth.Require(auth.TransfersWrite, c.createUserTransfers()))
 
-	router.Methods("DELETE").Path("/transfers/{transferId}").HandlerFunc(c.deleteUserTransfer())
+	router.Methods("DELETE").Path("/transfers/{transferId}").HandlerFunc(auth.Require(auth.TransfersWrite, c.deleteUserTransfer()))
 
-	router.Methods("GET").Path("/transfers/{transferId}/events").HandlerFunc(c.getUserTransferEvents())
-	router.Methods("POST").Path("/transfers/{transferId}/failed").HandlerFunc(c.validateUserTransfer())
-	router.Methods("POST").Path("/transfers/{transferId}/files").HandlerFunc(c.getUserTransferFiles())
+	router.Methods("GET").Path("/transfers/{transferId}/events").HandlerFunc(auth.Require(auth.TransfersRead, c.getUserTransferEvents()))
+	router.Methods("POST").Path("/transfers/{transferId}/failed").HandlerFunc(auth.Require(auth.TransfersWrite, c.validateUserTransfer()))
+	router.Methods("POST").Path("/transfers/{transferId}/files").HandlerFunc(auth.Require(auth.TransfersWrite, c.getUserTransferFiles()))
 }
 
 func getTransferID(r *http.Request) TransferID {
@@ -318,7 +400,7 @@ func (c *TransferRouter) getUserTransfers() http.HandlerFunc {
 			return
 		}
 
-		transfers, err := c.transferRepo.getUserTransfers(responder.XUserID)
+		transfers, err := c.transferRepo.getUserTransfers(r.Context(), responder.XUserID)
 		if err != nil {
 			responder.Log("transfers", fmt.Sprintf("error getting user transfers: %v", err))
 			responder.Problem(err)
@@ -341,7 +423,7 @@ func (c *TransferRouter) getUserTransfer() http.HandlerFunc {
 		}
 
 		transferID := getTransferID(r)
-		transfer, err := c.transferRepo.getUserTransfer(transferID, responder.XUserID)
+		transfer, err := c.transferRepo.getUserTransfer(r.Context(), transferID, responder.XUserID)
 		if err != nil {
 			responder.Log("transfers", fmt.Sprintf("error reading transfer=%s: %v", transferID, err))
 			responder.Problem(err)
@@ -383,6 +465,56 @@ func readTransferRequests(r *http.Request) ([]*transferRequest, error) {
 	return requests, nil
 }
 
+// batchMode controls how a failure of one transfer in a batch affects the others.
+type batchMode string
+
+const (
+	// batchAtomic validates every transfer before creating any of them and reverses
+	// the side effects (Accounts transactions, ACH files) of each transfer when any fail.
+	batchAtomic batchMode = "atomic"
+
+	// batchPartial creates each transfer independently and responds with the outcome
+	// of every transfer in the request.
+	batchPartial batchMode = "partial"
+)
+
+// readBatchMode returns the batchMode requested with the 'mode' query parameter, defaulting to batchAtomic.
+func readBatchMode(r *http.Request) (batchMode, error) {
+	switch mode := batchMode(strings.ToLower(strings.TrimSpace(r.URL.Query().Get("mode")))); mode {
+	case "", batchAtomic:
+		return batchAtomic, nil
+	case batchPartial:
+		return batchPartial, nil
+	default:
+		return "", fmt.Errorf("unknown batch mode %q", mode)
+	}
+}
+
+// batchTransferResult is the outcome of one transfer in a partial batch create.
+type batchTransferResult struct {
+	// Transfer is the created Transfer, nil if there was an Error.
+	Transfer *Transfer `json:"transfer,omitempty"`
+
+	// Error describes why the Transfer wasn't created.
+	Error string `json:"error,omitempty"`
+}
+
+// pendingTransfer holds the objects used to create a Transfer so its side effects can
+// be reversed if the Transfer (or the batch it's in) fails.
+type pendingTransfer struct {
+	id  string
+	req *transferRequest
+
+	receiver    *Receiver
+	receiverDep *Depository
+	orig        *Originator
+	origDep     *Depository
+
+	authorization *Authorization
+
+	file *ach.File
+}
+
 func (c *TransferRouter) createUserTransfers() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		responder := route.NewResponder(c.logger, w, r)
@@ -390,13 +522,18 @@ func (c *TransferRouter) createUserTransfers() http.HandlerFunc {
 			return
 		}
 
+		ctx := r.Context()
+
 		requests, err := readTransferRequests(r)
 		if err != nil {
 			responder.Problem(err)
 			return
 		}
-
-		ach := c.achClientFactory(responder.XUserID)
+		mode, err := readBatchMode(r)
+		if err != nil {
+			responder.Problem(err)
+			return
+		}
 
 		// Carry over any incoming idempotency key and set one otherwise
 		idempotencyKey := idempotent.Header(r)
@@ -404,80 +541,46 @@ func (c *TransferRouter) createUserTransfers() http.HandlerFunc {
 			idempotencyKey = base.ID()
 		}
 
-		for i := range requests {
-			transferID, req := base.ID(), requests[i]
-			if err := req.missingFields(); err != nil {
-				responder.Problem(err)
-				return
-			}
-
-			// Grab and validate objects required for this transfer.
-			receiver, receiverDep, orig, origDep, err := getTransferObjects(req, responder.XUserID, c.depRepo, c.receiverRepository, c.origRepo)
-			if err != nil {
-				objects := fmt.Sprintf("receiver=%v, receiverDep=%v, orig=%v, origDep=%v, err: %v", receiver, receiverDep, orig, origDep, err)
-				responder.Log("transfers", fmt.Sprintf("Unable to find all objects during transfer create for user_id=%s, %s", responder.XUserID, objects))
-
-				// Respond back to user
-				responder.Problem(fmt.Errorf("missing data to create transfer: %s", err))
-				return
-			}
-
-			// Post the Transfer's transaction against the Accounts
-			var transactionID string
-			if c.accountsClient != nil {
-				tx, err := c.postAccountTransaction(responder.XUserID, origDep, receiverDep, req.Amount, req.Type, responder.XRequestID)
-				if err != nil {
-					responder.Log("transfers", err.Error())
-					responder.Problem(err)
-					return
-				}
-				transactionID = tx.ID
-			}
-
-			// Verify Customer statuses related to this transfer
-			if c.customersClient != nil {
-				if err := verifyCustomerStatuses(orig, receiver, c.customersClient, responder.XRequestID, responder.XUserID); err != nil {
-					responder.Log("transfers", "problem with Customer checks", "error", err.Error())
-					responder.Problem(err)
-					return
-				} else {
-					responder.Log("transfers", "Customer check passed")
-				}
-
-				// Check disclaimers for Originator and Receiver
-				if err := verifyDisclaimersAreAccepted(orig, receiver, c.customersClient, responder.XRequestID, responder.XUserID); err != nil {
-					responder.Log("transfers", "problem with disclaimers", "error", err.Error())
-					responder.Problem(err)
-					return
-				} else {
-					responder.Log("transfers", "Disclaimer checks passed")
+		if mode == batchPartial {
+			results := c.createPartialTransfers(ctx, responder, idempotencyKey, requests)
+			status := http.StatusOK
+			for i := range results {
+				if results[i].Error != "" {
+					status = http.StatusMultiStatus
+					break
 				}
 			}
+			responder.Respond(func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(results)
+			})
+			return
+		}
 
-			// Save Transfer object
-			transfer := req.asTransfer(transferID)
-			file, err := constructACHFile(transferID, idempotencyKey, responder.XUserID, transfer, receiver, receiverDep, orig, origDep)
-			if err != nil {
-				responder.Problem(err)
-				return
-			}
-			fileID, err := ach.CreateFile(idempotencyKey, file)
+		// Validate every transfer before we create anything
+		pending := make([]*pendingTransfer, len(requests))
+		singleUse := make(map[AuthorizationID]bool)
+		for i := range requests {
+			p, err := c.prepareTransfer(ctx, responder, idempotencyKey, requests[i])
 			if err != nil {
 				responder.Problem(err)
 				return
 			}
-			if err := checkACHFile(c.logger, ach, fileID, responder.XUserID); err != nil {
-				responder.Problem(err)
-				return
+			if auth := p.authorization; auth != nil && auth.Scope == AuthorizationSingle {
+				if singleUse[auth.ID] {
+					responder.Problem(errAuthorizationUsed(auth.ID))
+					return
+				}
+				singleUse[auth.ID] = true
 			}
+			pending[i] = p
+		}
 
-			// Add internal ID's (fileID, transaction.ID) onto our request so we can store them in our database
-			req.fileID = fileID
-			req.transactionID = transactionID
-
-			// Write events for our audit/history log
-			if err := writeTransferEvent(responder.XUserID, req, c.eventRepo); err != nil {
-				responder.Log("transfers", fmt.Sprintf("error writing transfer=%s event: %v", transferID, err))
+		achClient := c.achClientFactory(responder.XUserID)
+		for i := range pending {
+			if err := c.submitTransfer(ctx, responder, achClient, idempotencyKey, pending[i]); err != nil {
+				c.compensateTransfers(ctx, responder, achClient, pending[:i+1])
 				responder.Problem(err)
 				return
 			}
@@ -487,21 +590,211 @@ func (c *TransferRouter) createUserTransfers() http.HandlerFunc {
 		// into an ACH file. Should we check that case in this method and reject Transfers whose Depositories micro-deposts
 		// haven't even been merged yet?
 
-		transfers, err := c.transferRepo.createUserTransfers(responder.XUserID, requests)
+		transfers, err := c.transferRepo.createUserTransfers(ctx, responder.XUserID, requests)
 		if err != nil {
 			responder.Log("transfers", fmt.Sprintf("error creating transfers: %v", err))
+			c.compensateTransfers(ctx, responder, achClient, pending)
 			responder.Problem(err)
 			return
 		}
+		c.writeTransferEvents(ctx, responder, requests, transfers)
 
 		writeResponse(c.logger, w, len(requests), transfers)
 		responder.Log("transfers", fmt.Sprintf("Created transfers for user_id=%s request=%s", responder.XUserID, responder.XRequestID))
 	}
 }
 
+// createPartialTransfers attempts to create each transfer on its own, reversing the side effects
+// of those which fail, and returns the outcome of each request in order.
+func (c *TransferRouter) createPartialTransfers(ctx context.Context, responder *route.Responder, idempotencyKey string, requests []*transferRequest) []batchTransferResult {
+	achClient := c.achClientFactory(responder.XUserID)
+
+	results := make([]batchTransferResult, len(requests))
+	for i := range requests {
+		p, err := c.prepareTransfer(ctx, responder, idempotencyKey, requests[i])
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if err := c.submitTransfer(ctx, responder, achClient, idempotencyKey, p); err != nil {
+			c.compensateTransfers(ctx, responder, achClient, []*pendingTransfer{p})
+			results[i].Error = err.Error()
+			continue
+		}
+		transfers, err := c.transferRepo.createUserTransfers(ctx, responder.XUserID, []*transferRequest{p.req})
+		if err != nil {
+			responder.Log("transfers", fmt.Sprintf("error creating transfer %d of %d: %v", i+1, len(requests), err))
+			c.compensateTransfers(ctx, responder, achClient, []*pendingTransfer{p})
+			results[i].Error = err.Error()
+			continue
+		}
+		c.writeTransferEvents(ctx, responder, []*transferRequest{p.req}, transfers)
+		results[i].Transfer = transfers[0]
+	}
+	responder.Log("transfers", fmt.Sprintf("Processed %d partial transfers for user_id=%s request=%s", len(requests), responder.XUserID, responder.XRequestID))
+	return results
+}
+
+// prepareTransfer reads and validates everything needed to create a Transfer, including its ACH file,
+// without making any changes to other services.
+func (c *TransferRouter) prepareTransfer(ctx context.Context, responder *route.Responder, idempotencyKey string, req *transferRequest) (*pendingTransfer, error) {
+	if err := req.missingFields(); err != nil {
+		return nil, err
+	}
+
+	// Grab and validate objects required for this transfer.
+	receiver, receiverDep, orig, origDep, err := getTransferObjects(ctx, req, responder.XUserID, c.depRepo, c.receiverRepository, c.origRepo)
+	if err != nil {
+		// Only log IDs as the objects hold decrypted PII
+		objects := fmt.Sprintf("receiver=%s, receiverDep=%s, orig=%s, origDep=%s, err: %v", req.Receiver, req.ReceiverDepository, req.Originator, req.OriginatorDepository, err)
+		responder.Log("transfers", fmt.Sprintf("Unable to find all objects during transfer create for user_id=%s, %s", responder.XUserID, objects))
+		return nil, fmt.Errorf("missing data to create transfer: %s", err)
+	}
+
+	// Debits need the Receiver's authorization
+	var auth *Authorization
+	if req.Type == PullTransfer || req.Authorization != "" {
+		auth, err = c.authorizationRepo.getUserAuthorization(ctx, req.Authorization, responder.XUserID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizeTransfer(auth, req); err != nil {
+			responder.Log("transfers", fmt.Sprintf("problem with authorization=%s: %v", req.Authorization, err))
+			return nil, err
+		}
+	}
+
+	// Verify Customer statuses related to this transfer
+	if c.customersClient != nil {
+		spanCtx, span := tracing.Start(ctx, "customers.verifyCustomerStatuses", "originator", orig.CustomerID, "receiver", receiver.CustomerID)
+		err := verifyCustomerStatuses(spanCtx, orig, receiver, c.customersClient, responder.XRequestID, responder.XUserID)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			responder.Log("transfers", "problem with Customer checks", "error", err.Error())
+			return nil, err
+		} else {
+			responder.Log("transfers", "Customer check passed")
+		}
+
+		// Check disclaimers for Originator and Receiver
+		spanCtx, span = tracing.Start(ctx, "customers.verifyDisclaimersAreAccepted", "originator", orig.CustomerID, "receiver", receiver.CustomerID)
+		err = verifyDisclaimersAreAccepted(spanCtx, orig, receiver, c.customersClient, responder.XRequestID, responder.XUserID)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			responder.Log("transfers", "problem with disclaimers", "error", err.Error())
+			return nil, err
+		} else {
+			responder.Log("transfers", "Disclaimer checks passed")
+		}
+	}
+
+	traceNumber, err := c.traceNumbers.reserve(ctx, origDep.RoutingNumber, 1)
+	if err != nil {
+		responder.Log("transfers", fmt.Sprintf("problem reserving trace number: %v", err))
+		return nil, errors.New("unable to assign trace number")
+	}
+	req.traceNumber = traceNumber
+
+	transferID := base.ID()
+	file, err := constructACHFile(transferID, idempotencyKey, responder.XUserID, req.asTransfer(transferID), receiver, receiverDep, orig, origDep)
+	if err != nil {
+		return nil, err
+	}
+	return &pendingTransfer{
+		id:            transferID,
+		req:           req,
+		receiver:      receiver,
+		receiverDep:   receiverDep,
+		orig:          orig,
+		origDep:       origDep,
+		authorization: auth,
+		file:          file,
+	}, nil
+}
+
+// submitTransfer posts the Accounts transaction and creates the ACH file for a prepared Transfer.
+// The IDs of each are recorded on the transferRequest so compensateTransfers can undo them.
+func (c *TransferRouter) submitTransfer(ctx context.Context, responder *route.Responder, achClient *achclient.ACH, idempotencyKey string, p *pendingTransfer) (err error) {
+	ctx, submitSpan := tracing.Start(ctx, "transfers.submitTransfer", "transferID", p.id)
+	defer func() {
+		submitSpan.RecordError(err)
+		submitSpan.End()
+	}()
+
+	// Post the Transfer's transaction against the Accounts
+	if c.accountsClient != nil {
+		spanCtx, span := tracing.Start(ctx, "accounts.PostTransaction")
+		tx, err := c.postAccountTransaction(spanCtx, responder.XUserID, p.origDep, p.receiverDep, p.req.Amount, p.req.Type, responder.XRequestID)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			responder.Log("transfers", err.Error())
+			return err
+		}
+		p.req.transactionID = tx.ID
+	}
+
+	spanCtx, span := tracing.Start(ctx, "achclient.CreateFile")
+	fileID, err := achClient.CreateFile(spanCtx, idempotencyKey, p.file)
+	span.SetAttributes("fileID", fileID)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		return err
+	}
+	p.req.fileID = fileID
+
+	spanCtx, span = tracing.Start(ctx, "achclient.ValidateFile", "fileID", fileID)
+	err = checkACHFile(spanCtx, c.logger, achClient, fileID, responder.XUserID)
+	span.RecordError(err)
+	span.End()
+	return err
+}
+
+// writeTransferEvents records the created transfers in our audit/history log. The transfers have
+// already been saved, so failures are only logged.
+func (c *TransferRouter) writeTransferEvents(ctx context.Context, responder *route.Responder, requests []*transferRequest, transfers []*Transfer) {
+	for i := range transfers {
+		if err := writeTransferEvent(ctx, responder.XUserID, requests[i], c.eventRepo); err != nil {
+			responder.Log("transfers", fmt.Sprintf("error writing transfer=%s event: %v", transfers[i].ID, err))
+		}
+	}
+}
+
+// compensateTransfers reverses the Accounts transactions and deletes the ACH files created for each transfer.
+// Failures are logged as there's nothing more we can do for the caller.
+func (c *TransferRouter) compensateTransfers(ctx context.Context, responder *route.Responder, achClient *achclient.ACH, pending []*pendingTransfer) {
+	ctx, span := tracing.Start(ctx, "transfers.compensateTransfers", "transfers", len(pending))
+	defer span.End()
+
+	// Cleanup still happens when the request was cancelled or ran out of time.
+	ctx, cancelFn := context.WithTimeout(util.Detach(ctx), 30*time.Second)
+	defer cancelFn()
+
+	for i := range pending {
+		req := pending[i].req
+		if req.transactionID != "" && c.accountsClient != nil {
+			if err := c.accountsClient.ReverseTransaction(ctx, responder.XRequestID, responder.XUserID, req.transactionID); err != nil {
+				responder.Log("transfers", fmt.Sprintf("problem reversing transaction=%s: %v", req.transactionID, err))
+			} else {
+				req.transactionID = ""
+			}
+		}
+		if req.fileID != "" {
+			if err := achClient.DeleteFile(ctx, req.fileID); err != nil {
+				responder.Log("transfers", fmt.Sprintf("problem deleting fileID=%s: %v", req.fileID, err))
+			} else {
+				req.fileID = ""
+			}
+		}
+	}
+}
+
 // postAccountTransaction will lookup the Accounts for Depositories involved in a transfer and post the
 // transaction against them in order to confirm, when possible, sufficient funds and other checks.
-func (c *TransferRouter) postAccountTransaction(userID id.User, origDep *Depository, recDep *Depository, amount Amount, transferType TransferType, requestID string) (*accounts.Transaction, error) {
+func (c *TransferRouter) postAccountTransaction(ctx context.Context, userID id.User, origDep *Depository, recDep *Depository, amount Amount, transferType TransferType, requestID string) (*accounts.Transaction, error) {
 	if c.accountsClient == nil {
 		return nil, errors.New("Accounts enabled but nil client")
 	}
@@ -510,16 +803,16 @@ func (c *TransferRouter) postAccountTransaction(userID id.User, origDep *Deposit
 	// When the routing numbers don't match we can't do much verify the remote account as we likely don't have Account-level access.
 	//
 	// TODO(adam): What about an FI that handles multiple routing numbers? Should Accounts expose which routing numbers it currently supports?
-	receiverAccount, err := c.accountsClient.SearchAccounts(requestID, userID, recDep)
+	receiverAccount, err := c.accountsClient.SearchAccounts(ctx, requestID, userID, recDep)
 	if err != nil || receiverAccount == nil {
 		return nil, fmt.Errorf("error reading account user=%s receiver depository=%s: %v", userID, recDep.ID, err)
 	}
-	origAccount, err := c.accountsClient.SearchAccounts(requestID, userID, origDep)
+	origAccount, err := c.accountsClient.SearchAccounts(ctx, requestID, userID, origDep)
 	if err != nil || origAccount == nil {
 		return nil, fmt.Errorf("error reading account user=%s originator depository=%s: %v", userID, origDep.ID, err)
 	}
 	// Submit the transactions to Accounts (only after can we go ahead and save off the Transfer)
-	transaction, err := c.accountsClient.PostTransaction(requestID, userID, createTransactionLines(origAccount, receiverAccount, amount, transferType))
+	transaction, err := c.accountsClient.PostTransaction(ctx, requestID, userID, createTransactionLines(origAccount, receiverAccount, amount, transferType))
 	if err != nil {
 		return nil, fmt.Errorf("error creating transaction for transfer user=%s: %v", userID, err)
 	}
@@ -548,7 +841,7 @@ func (c *TransferRouter) deleteUserTransfer() http.HandlerFunc {
 		}
 
 		transferID := getTransferID(r)
-		transfer, err := c.transferRepo.getUserTransfer(transferID, responder.XUserID)
+		transfer, err := c.transferRepo.getUserTransfer(r.Context(), transferID, responder.XUserID)
 		if err != nil {
 			responder.Log("transfers", fmt.Sprintf("error reading transfer=%s for deletion: %v", transferID, err))
 			responder.Problem(err)
@@ -560,19 +853,19 @@ func (c *TransferRouter) deleteUserTransfer() http.HandlerFunc {
 		}
 
 		// Delete from our database
-		if err := c.transferRepo.deleteUserTransfer(transferID, responder.XUserID); err != nil {
+		if err := c.transferRepo.deleteUserTransfer(r.Context(), transferID, responder.XUserID); err != nil {
 			responder.Problem(err)
 			return
 		}
 
 		// Delete from our ACH service
-		fileID, err := c.transferRepo.GetFileIDForTransfer(transferID, responder.XUserID)
+		fileID, err := c.transferRepo.GetFileIDForTransfer(r.Context(), transferID, responder.XUserID)
 		if err != nil && err != sql.ErrNoRows {
 			responder.Problem(err)
 			return
 		}
 		if fileID != "" {
-			if err := c.achClientFactory(responder.XUserID).DeleteFile(fileID); err != nil {
+			if err := c.achClientFactory(responder.XUserID).DeleteFile(r.Context(), fileID); err != nil {
 				responder.Problem(err)
 				return
 			}
@@ -593,7 +886,7 @@ func (c *TransferRouter) validateUserTransfer() http.HandlerFunc {
 
 		// Grab the TransferID and responder.XUserID
 		transferId := getTransferID(r)
-		fileID, err := c.transferRepo.GetFileIDForTransfer(transferId, responder.XUserID)
+		fileID, err := c.transferRepo.GetFileIDForTransfer(r.Context(), transferId, responder.XUserID)
 		if err != nil {
 			responder.Log("transfers", fmt.Sprintf("error getting fileID for transfer=%s: %v", transferId, err))
 			responder.Problem(err)
@@ -605,7 +898,7 @@ func (c *TransferRouter) validateUserTransfer() http.HandlerFunc {
 		}
 
 		// Check our ACH file status/validity
-		if err := checkACHFile(c.logger, c.achClientFactory(responder.XUserID), fileID, responder.XUserID); err != nil {
+		if err := checkACHFile(r.Context(), c.logger, c.achClientFactory(responder.XUserID), fileID, responder.XUserID); err != nil {
 			responder.Problem(err)
 		} else {
 			w.WriteHeader(http.StatusOK)
@@ -622,7 +915,7 @@ func (c *TransferRouter) getUserTransferFiles() http.HandlerFunc {
 
 		// Grab the TransferID and responder.XUserID
 		transferId := getTransferID(r)
-		fileID, err := c.transferRepo.GetFileIDForTransfer(transferId, responder.XUserID)
+		fileID, err := c.transferRepo.GetFileIDForTransfer(r.Context(), transferId, responder.XUserID)
 		if err != nil {
 			responder.Log("transfers", fmt.Sprintf("error reading fileID for transfer=%s: %v", transferId, err))
 			responder.Problem(err)
@@ -634,7 +927,7 @@ func (c *TransferRouter) getUserTransferFiles() http.HandlerFunc {
 		}
 
 		// Grab Transfer file(s)
-		file, err := c.achClientFactory(responder.XUserID).GetFile(fileID)
+		file, err := c.achClientFactory(responder.XUserID).GetFile(r.Context(), fileID)
 		if err != nil {
 			responder.Log("transfers", fmt.Sprintf("error getting ACH files for transfer=%s: %v", transferId, err))
 			responder.Problem(err)
@@ -657,7 +950,7 @@ func (c *TransferRouter) getUserTransferEvents() http.HandlerFunc {
 		}
 
 		transferID := getTransferID(r)
-		transfer, err := c.transferRepo.getUserTransfer(transferID, responder.XUserID)
+		transfer, err := c.transferRepo.getUserTransfer(r.Context(), transferID, responder.XUserID)
 		if err != nil {
 			responder.Problem(err)
 			return
@@ -666,7 +959,7 @@ func (c *TransferRouter) getUserTransferEvents() http.HandlerFunc {
 		metadata := make(map[string]string)
 		metadata["transferID"] = fmt.Sprintf("%v", transfer.ID)
 
-		events, err := c.eventRepo.GetUserEventsByMetadata(responder.XUserID, metadata)
+		events, err := c.eventRepo.GetUserEventsByMetadata(r.Context(), responder.XUserID, metadata)
 		if err != nil {
 			responder.Problem(err)
 			return
@@ -681,14 +974,14 @@ func (c *TransferRouter) getUserTransferEvents() http.HandlerFunc {
 }
 
 type TransferRepository interface {
-	getUserTransfers(userID id.User) ([]*Transfer, error)
-	getUserTransfer(id TransferID, userID id.User) (*Transfer, error)
-	UpdateTransferStatus(id TransferID, status TransferStatus) error
+	getUserTransfers(ctx context.Context, userID id.User) ([]*Transfer, error)
+	getUserTransfer(ctx context.Context, id TransferID, userID id.User) (*Transfer, error)
+	UpdateTransferStatus(ctx context.Context, id TransferID, status TransferStatus) error
 
-	GetFileIDForTransfer(id TransferID, userID id.User) (string, error)
+	GetFileIDForTransfer(ctx context.Context, id TransferID, userID id.User) (string, error)
 
-	LookupTransferFromReturn(sec string, amount *Amount, traceNumber string, effectiveEntryDate time.Time) (*Transfer, error)
-	SetReturnCode(id TransferID, returnCode string) error
+	LookupTransferFromReturn(ctx context.Context, sec string, amount *Amount, traceNumber string, effectiveEntryDate time.Time) (*Transfer, error)
+	SetReturnCode(ctx context.Context, id TransferID, returnCode string) error
 
 	// GetTransferCursor returns a database cursor for Transfer objects that need to be
 	// posted today.
@@ -696,10 +989,10 @@ type TransferRepository interface {
 	// We currently default EffectiveEntryDate to tomorrow for any transfer and thus a
 	// transfer created today needs to be posted.
 	GetTransferCursor(batchSize int, depRepo DepositoryRepository) *TransferCursor
-	MarkTransferAsMerged(id TransferID, filename string, traceNumber string) error
+	MarkTransferAsMerged(ctx context.Context, id TransferID, filename string, traceNumber string) error
 
-	createUserTransfers(userID id.User, requests []*transferRequest) ([]*Transfer, error)
-	deleteUserTransfer(id TransferID, userID id.User) error
+	createUserTransfers(ctx context.Context, userID id.User, requests []*transferRequest) ([]*Transfer, error)
+	deleteUserTransfer(ctx context.Context, id TransferID, userID id.User) error
 }
 
 func NewTransferRepo(logger log.Logger, db *sql.DB) *SQLTransferRepo {
@@ -715,15 +1008,15 @@ func (r *SQLTransferRepo) Close() error {
 	return r.db.Close()
 }
 
-func (r *SQLTransferRepo) getUserTransfers(userID id.User) ([]*Transfer, error) {
+func (r *SQLTransferRepo) getUserTransfers(ctx context.Context, userID id.User) ([]*Transfer, error) {
 	query := `select transfer_id from transfers where user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(userID)
+	rows, err := stmt.QueryContext(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -745,7 +1038,7 @@ func (r *SQLTransferRepo) getUserTransfers(userID id.User) ([]*Transfer, error)
 
 	var transfers []*Transfer
 	for i := range transferIDs {
-		t, err := r.getUserTransfer(TransferID(transferIDs[i]), userID)
+		t, err := r.getUserTransfer(ctx, TransferID(transferIDs[i]), userID)
 		if err == nil && t.ID != "" {
 			transfers = append(transfers, t)
 		}
@@ -753,29 +1046,49 @@ func (r *SQLTransferRepo) getUserTransfers(userID id.User) ([]*Transfer, error)
 	return transfers, rows.Err()
 }
 
-func (r *SQLTransferRepo) getUserTransfer(id TransferID, userID id.User) (*Transfer, error) {
-	query := `select transfer_id, type, amount, originator_id, originator_depository, receiver, receiver_depository, description, standard_entry_class_code, status, same_day, return_code, created_at
+func (r *SQLTransferRepo) getUserTransfer(ctx context.Context, id TransferID, userID id.User) (*Transfer, error) {
+	query := `select transfer_id, type, amount, originator_id, originator_depository, receiver, receiver_depository, authorization_id, description, standard_entry_class_code, status, same_day, payment_related_information, identification_number, discretionary_data, trace_number, return_code, created_at
 from transfers
 where transfer_id = ? and user_id = ? and deleted_at is null
 limit 1`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(id, userID)
+	row := stmt.QueryRowContext(ctx, id, userID)
 
 	transfer := &Transfer{}
 	var (
-		amt        string
-		returnCode *string
-		created    time.Time
+		amt                  string
+		authorizationID      *string
+		paymentInfo          *string
+		identificationNumber *string
+		discretionaryData    *string
+		traceNumber          *string
+		returnCode           *string
+		created              time.Time
 	)
-	err = row.Scan(&transfer.ID, &transfer.Type, &amt, &transfer.Originator, &transfer.OriginatorDepository, &transfer.Receiver, &transfer.ReceiverDepository, &transfer.Description, &transfer.StandardEntryClassCode, &transfer.Status, &transfer.SameDay, &returnCode, &created)
+	err = row.Scan(&transfer.ID, &transfer.Type, &amt, &transfer.Originator, &transfer.OriginatorDepository, &transfer.Receiver, &transfer.ReceiverDepository, &authorizationID, &transfer.Description, &transfer.StandardEntryClassCode, &transfer.Status, &transfer.SameDay, &paymentInfo, &identificationNumber, &discretionaryData, &traceNumber, &returnCode, &created)
 	if err != nil {
 		return nil, err
 	}
+	if authorizationID != nil {
+		transfer.Authorization = AuthorizationID(*authorizationID)
+	}
+	if paymentInfo != nil {
+		transfer.PaymentRelatedInformation = *paymentInfo
+	}
+	if identificationNumber != nil {
+		transfer.IdentificationNumber = *identificationNumber
+	}
+	if discretionaryData != nil {
+		transfer.DiscretionaryData = *discretionaryData
+	}
+	if traceNumber != nil {
+		transfer.TraceNumber = *traceNumber
+	}
 	if returnCode != nil {
 		transfer.ReturnCode = ach.LookupReturnCode(*returnCode)
 	}
@@ -787,30 +1100,159 @@ limit 1`
 	if transfer.ID == "" {
 		return nil, nil // not found
 	}
+	switch transfer.StandardEntryClassCode {
+	case ach.CTX:
+		detail, err := r.getCTXDetail(ctx, transfer.ID)
+		if err != nil {
+			return nil, err
+		}
+		transfer.CTXDetail = detail
+	case ach.ARC, ach.BOC, ach.POP, ach.RCK:
+		if err := r.getCheckDetail(ctx, transfer); err != nil {
+			return nil, err
+		}
+	}
 	return transfer, nil
 }
 
-func (r *SQLTransferRepo) UpdateTransferStatus(id TransferID, status TransferStatus) error {
-	query := `update transfers set status = ? where transfer_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+// getCTXDetail reassembles the CTXDetail of a Transfer from its stored Addenda05 records.
+func (r *SQLTransferRepo) getCTXDetail(ctx context.Context, id TransferID) (*CTXDetail, error) {
+	query := `select payment_related_information from transfer_addenda where transfer_id = ? order by sequence_number asc`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buf strings.Builder
+	for rows.Next() {
+		var info string
+		if err := rows.Scan(&info); err != nil {
+			return nil, fmt.Errorf("getCTXDetail scan: %v", err)
+		}
+		buf.WriteString(info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("getCTXDetail: rows.Err=%v", err)
+	}
+	return &CTXDetail{PaymentInformation: buf.String()}, nil
+}
+
+// writeCTXAddenda stores each Addenda05 record of a CTX transfer so they can be read back later.
+func (r *SQLTransferRepo) writeCTXAddenda(ctx context.Context, tx *sql.Tx, id TransferID, detail *CTXDetail, now time.Time) error {
+	query := `insert into transfer_addenda (transfer_id, sequence_number, payment_related_information, created_at) values (?, ?, ?, ?)`
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(status, id)
-	return err
+	records := detail.addendaRecords()
+	for i := range records {
+		if _, err := stmt.ExecContext(ctx, id, i+1, records[i], now); err != nil {
+			return fmt.Errorf("writeCTXAddenda: transfer=%s sequence=%d: %v", id, i+1, err)
+		}
+	}
+	return nil
 }
 
-func (r *SQLTransferRepo) GetFileIDForTransfer(id TransferID, userID id.User) (string, error) {
+// getCheckDetail sets the ARCDetail, BOCDetail, POPDetail or RCKDetail of a converted check Transfer
+// from its stored check details.
+func (r *SQLTransferRepo) getCheckDetail(ctx context.Context, transfer *Transfer) error {
+	query := `select check_serial_number, terminal_city, terminal_state from transfer_check_details where transfer_id = ? limit 1`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var serial, city, state string
+	if err := stmt.QueryRowContext(ctx, transfer.ID).Scan(&serial, &city, &state); err != nil {
+		if err == sql.ErrNoRows {
+			return nil // created before check details were stored
+		}
+		return fmt.Errorf("getCheckDetail: transfer=%s: %v", transfer.ID, err)
+	}
+	switch transfer.StandardEntryClassCode {
+	case ach.ARC:
+		transfer.ARCDetail = &ARCDetail{CheckSerialNumber: serial}
+	case ach.BOC:
+		transfer.BOCDetail = &BOCDetail{CheckSerialNumber: serial}
+	case ach.POP:
+		transfer.POPDetail = &POPDetail{CheckSerialNumber: serial, TerminalCity: city, TerminalState: state}
+	case ach.RCK:
+		transfer.RCKDetail = &RCKDetail{CheckSerialNumber: serial}
+	}
+	return nil
+}
+
+// writeCheckDetail stores the SEC specific detail of a converted check (ARC, BOC, POP or RCK) Transfer so it can
+// be read back later.
+func (r *SQLTransferRepo) writeCheckDetail(ctx context.Context, tx *sql.Tx, xfer *Transfer, now time.Time) error {
+	var serial, city, state string
+	switch {
+	case xfer.ARCDetail != nil:
+		serial = xfer.ARCDetail.CheckSerialNumber
+	case xfer.BOCDetail != nil:
+		serial = xfer.BOCDetail.CheckSerialNumber
+	case xfer.POPDetail != nil:
+		serial, city, state = xfer.POPDetail.CheckSerialNumber, xfer.POPDetail.TerminalCity, xfer.POPDetail.TerminalState
+	case xfer.RCKDetail != nil:
+		serial = xfer.RCKDetail.CheckSerialNumber
+	default:
+		return nil
+	}
+	query := `insert into transfer_check_details (transfer_id, check_serial_number, terminal_city, terminal_state, created_at) values (?, ?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, xfer.ID, serial, city, state, now); err != nil {
+		return fmt.Errorf("writeCheckDetail: transfer=%s: %v", xfer.ID, err)
+	}
+	return nil
+}
+
+func (r *SQLTransferRepo) UpdateTransferStatus(ctx context.Context, id TransferID, status TransferStatus) (err error) {
+	ctx, span := tracing.Start(ctx, "transferRepo.UpdateTransferStatus", "transferID", id, "status", status)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	query := `update transfers set status = ? where transfer_id = ? and status <> ? and deleted_at is null`
+	res, err := tx.ExecContext(ctx, query, status, id, status)
+	if err != nil {
+		return fmt.Errorf("UpdateTransferStatus: error=%v rollback=%v", err, tx.Rollback())
+	}
+
+	// A canceled, failed or returned Transfer no longer uses its single use Authorization, which
+	// is only released as the Transfer changes status so it can't release another Transfer's use.
+	if n, _ := res.RowsAffected(); n > 0 && status.releasesAuthorization() {
+		query = `update authorizations set consumed_at = null, last_updated_at = ? where consumed_at is not null and authorization_id in
+(select authorization_id from transfers where transfer_id = ? and deleted_at is null);`
+		if _, err := tx.ExecContext(ctx, query, time.Now(), id); err != nil {
+			return fmt.Errorf("UpdateTransferStatus: releasing authorization error=%v rollback=%v", err, tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLTransferRepo) GetFileIDForTransfer(ctx context.Context, id TransferID, userID id.User) (string, error) {
 	query := `select file_id from transfers where transfer_id = ? and user_id = ? and deleted_at is null limit 1;`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return "", err
 	}
 	defer stmt.Close()
 
-	row := stmt.QueryRow(id, userID)
+	row := stmt.QueryRowContext(ctx, id, userID)
 
 	var fileID string
 	if err := row.Scan(&fileID); err != nil {
@@ -819,14 +1261,14 @@ func (r *SQLTransferRepo) GetFileIDForTransfer(id TransferID, userID id.User) (s
 	return fileID, nil
 }
 
-func (r *SQLTransferRepo) LookupTransferFromReturn(sec string, amount *Amount, traceNumber string, effectiveEntryDate time.Time) (*Transfer, error) {
+func (r *SQLTransferRepo) LookupTransferFromReturn(ctx context.Context, sec string, amount *Amount, traceNumber string, effectiveEntryDate time.Time) (*Transfer, error) {
 	// To match returned files we take a few values which are assumed to uniquely identify a Transfer.
 	// traceNumber, per NACHA guidelines, should be globally unique (routing number + random value),
 	// but we are going to filter to only select Transfers created within a few days of the EffectiveEntryDate
 	// to avoid updating really old (or future, I suppose) objects.
 	query := `select transfer_id, user_id, transaction_id from transfers
 where standard_entry_class_code = ? and amount = ? and trace_number = ? and status = ? and (created_at > ? and created_at < ?) and deleted_at is null limit 1`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -838,37 +1280,74 @@ where standard_entry_class_code = ? and amount = ? and trace_number = ? and stat
 	min = min.Add(-5 * 24 * time.Hour)
 	max = max.Add(5 * 24 * time.Hour)
 
-	row := stmt.QueryRow(sec, amount.String(), traceNumber, TransferProcessed, min, max)
+	row := stmt.QueryRowContext(ctx, sec, amount.String(), traceNumber, TransferProcessed, min, max)
 	if err := row.Scan(&transferId, &userID, &transactionID); err != nil {
 		return nil, err
 	}
 
-	xfer, err := r.getUserTransfer(TransferID(transferId), id.User(userID))
+	xfer, err := r.getUserTransfer(ctx, TransferID(transferId), id.User(userID))
 	xfer.TransactionID = transactionID
 	xfer.UserID = userID
 	return xfer, err
 }
 
-func (r *SQLTransferRepo) SetReturnCode(id TransferID, returnCode string) error {
+func (r *SQLTransferRepo) SetReturnCode(ctx context.Context, id TransferID, returnCode string) (err error) {
+	ctx, span := tracing.Start(ctx, "transferRepo.SetReturnCode", "transferID", id, "returnCode", returnCode)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update transfers set return_code = ? where transfer_id = ? and return_code is null and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(returnCode, id)
+	_, err = stmt.ExecContext(ctx, returnCode, id)
 	return err
 }
 
-func (r *SQLTransferRepo) createUserTransfers(userID id.User, requests []*transferRequest) ([]*Transfer, error) {
-	query := `insert into transfers (transfer_id, user_id, type, amount, originator_id, originator_depository, receiver, receiver_depository, description, standard_entry_class_code, status, same_day, file_id, transaction_id, created_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	stmt, err := r.db.Prepare(query)
+// createUserTransfers writes every Transfer (and their CTX addenda) in one transaction so a failed batch leaves nothing behind.
+func (r *SQLTransferRepo) createUserTransfers(ctx context.Context, userID id.User, requests []*transferRequest) (_ []*Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "transferRepo.createUserTransfers", "transfers", len(requests))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := r.insertUserTransfers(ctx, tx, userID, requests)
+	if err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			return nil, fmt.Errorf("%v (rollback=%v)", err, rerr)
+		}
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (r *SQLTransferRepo) insertUserTransfers(ctx context.Context, tx *sql.Tx, userID id.User, requests []*transferRequest) ([]*Transfer, error) {
+	query := `insert into transfers (transfer_id, user_id, type, amount, originator_id, originator_depository, receiver, receiver_depository, authorization_id, description, standard_entry_class_code, status, same_day, payment_related_information, identification_number, discretionary_data, trace_number, file_id, transaction_id, created_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
+	// Only one Transfer can consume a single use Authorization, even across concurrent requests
+	query = `update authorizations set consumed_at = ?, last_updated_at = ? where authorization_id = ? and user_id = ? and consumed_at is null and deleted_at is null;`
+	consumeStmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer consumeStmt.Close()
+
 	var transfers []*Transfer
 
 	now := time.Now()
@@ -883,36 +1362,85 @@ func (r *SQLTransferRepo) createUserTransfers(userID id.User, requests []*transf
 			OriginatorDepository:   req.OriginatorDepository,
 			Receiver:               req.Receiver,
 			ReceiverDepository:     req.ReceiverDepository,
+			Authorization:          req.Authorization,
 			Description:            req.Description,
 			StandardEntryClassCode: req.StandardEntryClassCode,
 			Status:                 status,
 			SameDay:                req.SameDay,
+			TraceNumber:            req.traceNumber,
 			Created:                base.NewTime(now),
+
+			PaymentRelatedInformation: req.PaymentRelatedInformation,
+			IdentificationNumber:      req.IdentificationNumber,
+			DiscretionaryData:         req.DiscretionaryData,
 		}
 		if err := xfer.validate(); err != nil {
 			return nil, fmt.Errorf("validation failed for transfer Originator=%s, Receiver=%s, Description=%s %v", xfer.Originator, xfer.Receiver, xfer.Description, err)
 		}
 
+		if req.consumeAuthorization {
+			res, err := consumeStmt.ExecContext(ctx, now, now, req.Authorization, userID)
+			if err != nil {
+				return nil, err
+			}
+			if n, _ := res.RowsAffected(); n != 1 {
+				return nil, errAuthorizationUsed(req.Authorization)
+			}
+		}
+
 		// write transfer
-		_, err := stmt.Exec(transferId, userID, req.Type, req.Amount.String(), req.Originator, req.OriginatorDepository, req.Receiver, req.ReceiverDepository, req.Description, req.StandardEntryClassCode, status, req.SameDay, req.fileID, req.transactionID, now)
+		_, err := stmt.ExecContext(ctx, transferId, userID, req.Type, req.Amount.String(), req.Originator, req.OriginatorDepository, req.Receiver, req.ReceiverDepository, req.Authorization, req.Description, req.StandardEntryClassCode, status, req.SameDay, req.PaymentRelatedInformation, req.IdentificationNumber, req.DiscretionaryData, req.traceNumber, req.fileID, req.transactionID, now)
 		if err != nil {
 			return nil, err
 		}
+		if req.StandardEntryClassCode == ach.CTX && req.CTXDetail != nil {
+			if err := r.writeCTXAddenda(ctx, tx, xfer.ID, req.CTXDetail, now); err != nil {
+				return nil, err
+			}
+			xfer.CTXDetail = req.CTXDetail
+		}
+		switch req.StandardEntryClassCode {
+		case ach.ARC:
+			xfer.ARCDetail = req.ARCDetail
+		case ach.BOC:
+			xfer.BOCDetail = req.BOCDetail
+		case ach.POP:
+			xfer.POPDetail = req.POPDetail
+		case ach.RCK:
+			xfer.RCKDetail = req.RCKDetail
+		}
+		if err := r.writeCheckDetail(ctx, tx, xfer, now); err != nil {
+			return nil, err
+		}
 		transfers = append(transfers, xfer)
 	}
 	return transfers, nil
 }
 
-func (r *SQLTransferRepo) deleteUserTransfer(id TransferID, userID id.User) error {
-	query := `update transfers set deleted_at = ? where transfer_id = ? and user_id = ? and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+func (r *SQLTransferRepo) deleteUserTransfer(ctx context.Context, id TransferID, userID id.User) (err error) {
+	ctx, span := tracing.Start(ctx, "transferRepo.deleteUserTransfer", "transferID", id)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	now := time.Now()
 
-	_, err = stmt.Exec(time.Now(), id, userID)
-	return err
+	// A deleted Transfer no longer uses its single use Authorization
+	query := `update authorizations set consumed_at = null, last_updated_at = ? where consumed_at is not null and authorization_id in
+(select authorization_id from transfers where transfer_id = ? and user_id = ? and deleted_at is null);`
+	if _, err := tx.ExecContext(ctx, query, now, id, userID); err != nil {
+		return fmt.Errorf("deleteUserTransfer: releasing authorization error=%v rollback=%v", err, tx.Rollback())
+	}
+
+	query = `update transfers set deleted_at = ? where transfer_id = ? and user_id = ? and deleted_at is null`
+	if _, err := tx.ExecContext(ctx, query, now, id, userID); err != nil {
+		return fmt.Errorf("deleteUserTransfer: error=%v rollback=%v", err, tx.Rollback())
+	}
+	return tx.Commit()
 }
 
 // TransferCursor allows for iterating through Transfers in ascending order (by CreatedAt)
@@ -950,15 +1478,15 @@ func (t GroupableTransfer) UserID() string {
 //
 // TODO(adam): should we have a field on transfers for marking when the ACH file is uploaded?
 // "after the file is uploaded we mark the items in the DB with the batch number and upload time and update the status" -- Wade
-func (cur *TransferCursor) Next() ([]*GroupableTransfer, error) {
+func (cur *TransferCursor) Next(ctx context.Context) ([]*GroupableTransfer, error) {
 	query := `select transfer_id, user_id, created_at from transfers where status = ? and merged_filename is null and created_at > ? and deleted_at is null order by created_at asc limit ?`
-	stmt, err := cur.TransferRepo.db.Prepare(query)
+	stmt, err := cur.TransferRepo.db.PrepareContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("TransferCursor.Next: prepare: %v", err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(TransferPending, cur.newerThan, cur.BatchSize) // only Pending transfers
+	rows, err := stmt.QueryContext(ctx, TransferPending, cur.newerThan, cur.BatchSize) // only Pending transfers
 	if err != nil {
 		return nil, fmt.Errorf("TransferCursor.Next: query: %v", err)
 	}
@@ -983,11 +1511,11 @@ func (cur *TransferCursor) Next() ([]*GroupableTransfer, error) {
 
 	var transfers []*GroupableTransfer
 	for i := range xfers {
-		t, err := cur.TransferRepo.getUserTransfer(TransferID(xfers[i].transferId), id.User(xfers[i].userID))
+		t, err := cur.TransferRepo.getUserTransfer(ctx, TransferID(xfers[i].transferId), id.User(xfers[i].userID))
 		if err != nil {
 			continue
 		}
-		destDep, err := cur.DepRepo.GetUserDepository(t.ReceiverDepository, id.User(xfers[i].userID))
+		destDep, err := cur.DepRepo.GetUserDepository(ctx, t.ReceiverDepository, id.User(xfers[i].userID))
 		if err != nil || destDep == nil {
 			continue
 		}
@@ -1018,16 +1546,21 @@ func (r *SQLTransferRepo) GetTransferCursor(batchSize int, depRepo DepositoryRep
 
 // MarkTransferAsMerged will set the merged_filename on Pending transfers so they aren't merged into multiple files
 // and the file uploaded to the FED can be tracked.
-func (r *SQLTransferRepo) MarkTransferAsMerged(id TransferID, filename string, traceNumber string) error {
+func (r *SQLTransferRepo) MarkTransferAsMerged(ctx context.Context, id TransferID, filename string, traceNumber string) (err error) {
+	ctx, span := tracing.Start(ctx, "transferRepo.MarkTransferAsMerged", "transferID", id, "filename", filename)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	query := `update transfers set merged_filename = ?, trace_number = ?, status = ?
 where status = ? and transfer_id = ? and (merged_filename is null or merged_filename = '') and deleted_at is null`
-	stmt, err := r.db.Prepare(query)
+	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("MarkTransferAsMerged: transfer=%s filename=%s: %v", id, filename, err)
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(filename, traceNumber, TransferProcessed, TransferPending, id)
+	_, err = stmt.ExecContext(ctx, filename, traceNumber, TransferProcessed, TransferPending, id)
 	return err
 }
 
@@ -1057,12 +1590,12 @@ func abaCheckDigit(rtn string) string {
 
 // getTransferObjects performs database lookups to grab all the objects needed to make a transfer.
 //
-// This method also verifies the status of the Receiver, Receiver Depository and Originator Repository
+// This method also verifies the status of the Receiver, Receiver Depository (which must be linked to the Receiver) and Originator Repository.
 //
 // All return values are either nil or non-nil and the error will be the opposite.
-func getTransferObjects(req *transferRequest, userID id.User, depRepo DepositoryRepository, receiverRepository receiverRepository, origRepo originatorRepository) (*Receiver, *Depository, *Originator, *Depository, error) {
+func getTransferObjects(ctx context.Context, req *transferRequest, userID id.User, depRepo DepositoryRepository, receiverRepository receiverRepository, origRepo originatorRepository) (*Receiver, *Depository, *Originator, *Depository, error) {
 	// Receiver
-	receiver, err := receiverRepository.getUserReceiver(req.Receiver, userID)
+	receiver, err := receiverRepository.getUserReceiver(ctx, req.Receiver, userID)
 	if err != nil {
 		return nil, nil, nil, nil, errors.New("receiver not found")
 	}
@@ -1070,7 +1603,14 @@ func getTransferObjects(req *transferRequest, userID id.User, depRepo Depository
 		return nil, nil, nil, nil, fmt.Errorf("receiver: %v", err)
 	}
 
-	receiverDep, err := depRepo.GetUserDepository(req.ReceiverDepository, userID)
+	depIDs, err := receiverRepository.getReceiverDepositories(ctx, receiver.ID, userID)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("receiver depositories: %v", err)
+	}
+	if !containsDepository(depIDs, req.ReceiverDepository) {
+		return nil, nil, nil, nil, fmt.Errorf("receiver depository %s is not linked to receiver %s", req.ReceiverDepository, receiver.ID)
+	}
+	receiverDep, err := depRepo.GetUserDepository(ctx, req.ReceiverDepository, userID)
 	if err != nil {
 		return nil, nil, nil, nil, errors.New("receiver depository not found")
 	}
@@ -1082,7 +1622,7 @@ func getTransferObjects(req *transferRequest, userID id.User, depRepo Depository
 	}
 
 	// Originator
-	orig, err := origRepo.getUserOriginator(req.Originator, userID)
+	orig, err := origRepo.getUserOriginator(ctx, req.Originator, userID)
 	if err != nil {
 		return nil, nil, nil, nil, errors.New("originator not found")
 	}
@@ -1090,7 +1630,7 @@ func getTransferObjects(req *transferRequest, userID id.User, depRepo Depository
 		return nil, nil, nil, nil, fmt.Errorf("originator: %v", err)
 	}
 
-	origDep, err := depRepo.GetUserDepository(req.OriginatorDepository, userID)
+	origDep, err := depRepo.GetUserDepository(ctx, req.OriginatorDepository, userID)
 	if err != nil {
 		return nil, nil, nil, nil, errors.New("originator Depository not found")
 	}
@@ -1104,8 +1644,8 @@ func getTransferObjects(req *transferRequest, userID id.User, depRepo Depository
 	return receiver, receiverDep, orig, origDep, nil
 }
 
-func verifyCustomerStatuses(orig *Originator, rec *Receiver, client customers.Client, requestID string, userID id.User) error {
-	cust, err := client.Lookup(orig.CustomerID, requestID, userID)
+func verifyCustomerStatuses(ctx context.Context, orig *Originator, rec *Receiver, client customers.Client, requestID string, userID id.User) error {
+	cust, err := client.Lookup(ctx, orig.CustomerID, requestID, userID)
 	if err != nil {
 		return fmt.Errorf("verifyCustomerStatuses: originator: %v", err)
 	}
@@ -1117,7 +1657,7 @@ func verifyCustomerStatuses(orig *Originator, rec *Receiver, client customers.Cl
 		return fmt.Errorf("verifyCustomerStatuses: customer=%s has unacceptable status=%s for Transfers", cust.ID, cust.Status)
 	}
 
-	cust, err = client.Lookup(rec.CustomerID, requestID, userID)
+	cust, err = client.Lookup(ctx, rec.CustomerID, requestID, userID)
 	if err != nil {
 		return fmt.Errorf("verifyCustomerStatuses: receiver: %v", err)
 	}
@@ -1132,11 +1672,11 @@ func verifyCustomerStatuses(orig *Originator, rec *Receiver, client customers.Cl
 	return nil
 }
 
-func verifyDisclaimersAreAccepted(orig *Originator, receiver *Receiver, client customers.Client, requestID string, userID id.User) error {
-	if err := customers.HasAcceptedAllDisclaimers(client, orig.CustomerID, requestID, userID); err != nil {
+func verifyDisclaimersAreAccepted(ctx context.Context, orig *Originator, receiver *Receiver, client customers.Client, requestID string, userID id.User) error {
+	if err := customers.HasAcceptedAllDisclaimers(ctx, client, orig.CustomerID, requestID, userID); err != nil {
 		return fmt.Errorf("originator=%s: %v", orig.ID, err)
 	}
-	if err := customers.HasAcceptedAllDisclaimers(client, receiver.CustomerID, requestID, userID); err != nil {
+	if err := customers.HasAcceptedAllDisclaimers(ctx, client, receiver.CustomerID, requestID, userID); err != nil {
 		return fmt.Errorf("receiver=%s: %v", receiver.ID, err)
 	}
 	return nil
@@ -1147,7 +1687,8 @@ func constructACHFile(id, idempotencyKey string, userID id.User, transfer *Trans
 	// TODO(adam): KYC (via Customers) is needed before we validate / reject Receivers
 	// TODO(adam): why are these checks in this method?
 	if transfer.Type == PullTransfer && receiver.Status != ReceiverVerified {
-		// TODO(adam): "additional checks" - check Receiver.Status ???
+		// Debiting a Receiver requires they've confirmed their email address and (checked earlier)
+		// that their Depository is verified.
 		// https://github.com/moov-io/paygate/issues/18#issuecomment-432066045
 		return nil, fmt.Errorf("receiver_id=%s is not Verified user_id=%s", receiver.ID, userID)
 	}
@@ -1155,6 +1696,13 @@ func constructACHFile(id, idempotencyKey string, userID id.User, transfer *Trans
 		return nil, fmt.Errorf("transfer_id=%s is not Pending (status=%s)", transfer.ID, transfer.Status)
 	}
 
+	if err := transfer.validateEntryFields(); err != nil {
+		return nil, err
+	}
+	if err := validateTraceNumber(transfer.TraceNumber, origDep.RoutingNumber); err != nil {
+		return nil, fmt.Errorf("transfer_id=%s %v", transfer.ID, err)
+	}
+
 	// Create our ACH file
 	file, now := ach.NewFile(), time.Now()
 	file.ID = id
@@ -1171,24 +1719,54 @@ func constructACHFile(id, idempotencyKey string, userID id.User, transfer *Trans
 
 	// Add batch to our ACH file
 	switch transfer.StandardEntryClassCode {
+	case ach.ARC:
+		batch, err := createARCBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
+		if err != nil {
+			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
+		}
+		file.AddBatch(batch)
+	case ach.BOC:
+		batch, err := createBOCBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
+		if err != nil {
+			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
+		}
+		file.AddBatch(batch)
 	case ach.CCD: // TODO(adam): Do we need to handle ACK also?
 		batch, err := createCCDBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
 		if err != nil {
 			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
 		}
 		file.AddBatch(batch)
+	case ach.CTX:
+		batch, err := createCTXBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
+		if err != nil {
+			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
+		}
+		file.AddBatch(batch)
 	case ach.IAT:
 		batch, err := createIATBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
 		if err != nil {
 			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
 		}
 		file.AddIATBatch(*batch)
+	case ach.POP:
+		batch, err := createPOPBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
+		if err != nil {
+			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
+		}
+		file.AddBatch(batch)
 	case ach.PPD:
 		batch, err := createPPDBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
 		if err != nil {
 			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
 		}
 		file.AddBatch(batch)
+	case ach.RCK:
+		batch, err := createRCKBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
+		if err != nil {
+			return nil, fmt.Errorf("constructACHFile: %s: %v", transfer.StandardEntryClassCode, err)
+		}
+		file.AddBatch(batch)
 	case ach.TEL:
 		batch, err := createTELBatch(id, userID, transfer, receiver, receiverDep, orig, origDep)
 		if err != nil {
@@ -1209,13 +1787,13 @@ func constructACHFile(id, idempotencyKey string, userID id.User, transfer *Trans
 
 // checkACHFile calls out to our ACH service to build and validate the ACH file,
 // "build" involves the ACH service computing some file/batch level totals and checksums.
-func checkACHFile(logger log.Logger, client *achclient.ACH, fileID string, userID id.User) error {
+func checkACHFile(ctx context.Context, logger log.Logger, client *achclient.ACH, fileID string, userID id.User) error {
 	// We don't care about the resposne, just the side-effect build tabulations.
-	if _, err := client.GetFileContents(fileID); err != nil && logger != nil {
+	if _, err := client.GetFileContents(ctx, fileID); err != nil && logger != nil {
 		logger.Log("transfers", fmt.Sprintf("responder.XUserID=%s fileID=%s err=%v", userID, fileID, err))
 	}
 	// ValidateFile will return specific file-level information about what's wrong.
-	return client.ValidateFile(fileID)
+	return client.ValidateFile(ctx, fileID)
 }
 
 func determineServiceClassCode(t *Transfer) int {
@@ -1257,20 +1835,193 @@ func determineTransactionCode(t *Transfer, origDep *Depository) int {
 	// Prenote for debit to savings account ‘38’
 }
 
+// validateCheckConversion checks the rules shared by SEC codes for converted checks (ARC, BOC, POP and RCK).
+// These entries can only debit a consumer's account and must be at or under maxAmount (in cents).
+func validateCheckConversion(transfer *Transfer, receiverDep *Depository, maxAmount int) error {
+	if transfer.Type != PullTransfer {
+		return fmt.Errorf("%s transfers can only be %s", transfer.StandardEntryClassCode, PullTransfer)
+	}
+	if receiverDep.HolderType != Individual {
+		return fmt.Errorf("%s transfers can only debit %s accounts, receiver depository=%s is %s", transfer.StandardEntryClassCode, Individual, receiverDep.ID, receiverDep.HolderType)
+	}
+	if n := transfer.Amount.Int(); n > maxAmount {
+		return fmt.Errorf("%s transfer amount of %d cents is over the limit of %d cents", transfer.StandardEntryClassCode, n, maxAmount)
+	}
+	return nil
+}
+
+// validateCheckSerialNumber ensures a check serial number is present and fits within maxLength characters
+// of the EntryDetail record.
+func validateCheckSerialNumber(serial string, maxLength int) error {
+	if serial == "" {
+		return errors.New("missing CheckSerialNumber")
+	}
+	if n := utf8.RuneCountInString(serial); n > maxLength {
+		return fmt.Errorf("CheckSerialNumber has %d characters, but only %d are allowed", n, maxLength)
+	}
+	for _, r := range serial {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return fmt.Errorf("CheckSerialNumber has invalid character %q", r)
+		}
+	}
+	return nil
+}
+
+// createCheckConversionBatch creates the debit batch of a converted check (ARC, BOC, POP or RCK) with a single
+// EntryDetail. setFields is called to fill in the values specific to the Transfer's SEC code.
+func createCheckConversionBatch(id string, userID id.User, transfer *Transfer, receiver *Receiver, receiverDep *Depository, orig *Originator, origDep *Depository, setFields func(*ach.BatchHeader, *ach.EntryDetail)) (ach.Batcher, error) {
+	sec := transfer.StandardEntryClassCode
+
+	batchHeader := ach.NewBatchHeader()
+	batchHeader.ID = id
+	batchHeader.ServiceClassCode = ach.DebitsOnly
+	batchHeader.CompanyName = orig.Metadata
+	batchHeader.StandardEntryClassCode = sec
+	batchHeader.CompanyIdentification = orig.Identification
+	batchHeader.CompanyEntryDescription = transfer.Description
+	batchHeader.CompanyDescriptiveDate = time.Now().Format("060102")
+	batchHeader.EffectiveEntryDate = base.Now().AddBankingDay(1).Format("060102") // Date to be posted, YYMMDD
+	batchHeader.ODFIIdentification = aba8(origDep.RoutingNumber)
+
+	entryDetail := ach.NewEntryDetail()
+	entryDetail.ID = id
+	entryDetail.TransactionCode = determineTransactionCode(transfer, origDep)
+	entryDetail.RDFIIdentification = aba8(receiverDep.RoutingNumber)
+	entryDetail.CheckDigit = abaCheckDigit(receiverDep.RoutingNumber)
+	entryDetail.Amount = transfer.Amount.Int()
+	entryDetail.IndividualName = receiver.Metadata
+	entryDetail.DiscretionaryData = transfer.DiscretionaryData
+	entryDetail.TraceNumber = transfer.TraceNumber
+
+	if num, err := receiverDep.DecryptAccountNumber(); err != nil {
+		return nil, fmt.Errorf("%s: receiver account number decrypt failed: %v", sec, err)
+	} else {
+		entryDetail.DFIAccountNumber = num
+	}
+	setFields(batchHeader, entryDetail)
+
+	batch, err := ach.NewBatch(batchHeader)
+	if err != nil {
+		return nil, fmt.Errorf("ACH file %s (userID=%s): failed to create batch: %v", id, userID, err)
+	}
+	batch.AddEntry(entryDetail)
+	batch.SetControl(ach.NewBatchControl())
+
+	if err := batch.Create(); err != nil {
+		return batch, err
+	}
+	return batch, nil
+}
+
+// entryFields are the optional EntryDetail and Addenda05 values a SEC code lets users set on their Transfers.
+type entryFields struct {
+	paymentRelatedInformation bool
+	identificationNumber      bool
+	discretionaryData         bool
+}
+
+// entryFieldsBySEC lists which optional values are allowed by each SEC code. Other fields are used for
+// SEC specific data (e.g. check serial numbers or payment type codes) and codes not listed allow none.
+var entryFieldsBySEC = map[string]entryFields{
+	ach.ARC: {discretionaryData: true},
+	ach.BOC: {discretionaryData: true},
+	ach.CCD: {paymentRelatedInformation: true, identificationNumber: true, discretionaryData: true},
+	ach.CTX: {identificationNumber: true, discretionaryData: true},
+	ach.POP: {discretionaryData: true},
+	ach.PPD: {paymentRelatedInformation: true, identificationNumber: true, discretionaryData: true},
+	ach.RCK: {discretionaryData: true},
+	ach.TEL: {identificationNumber: true},
+	ach.WEB: {paymentRelatedInformation: true, identificationNumber: true},
+}
+
+// validateEntryFields checks the user supplied PaymentRelatedInformation, IdentificationNumber and DiscretionaryData
+// are allowed for the Transfer's SEC code and will fit in their NACHA fields.
+func (t *Transfer) validateEntryFields() error {
+	allowed := entryFieldsBySEC[t.StandardEntryClassCode]
+	check := func(name, value string, allowed bool, maxLength int) error {
+		if value == "" {
+			return nil
+		}
+		if !allowed {
+			return fmt.Errorf("%s is not allowed on %s transfers", name, t.StandardEntryClassCode)
+		}
+		if n := utf8.RuneCountInString(value); n > maxLength {
+			return fmt.Errorf("%s has %d characters, but only %d are allowed", name, n, maxLength)
+		}
+		if err := validateACHAlphanumeric(value); err != nil {
+			return fmt.Errorf("%s %v", name, err)
+		}
+		return nil
+	}
+	if err := check("paymentRelatedInformation", t.PaymentRelatedInformation, allowed.paymentRelatedInformation, 80); err != nil {
+		return err
+	}
+	if err := check("identificationNumber", t.IdentificationNumber, allowed.identificationNumber, 15); err != nil {
+		return err
+	}
+	if err := check("discretionaryData", t.DiscretionaryData, allowed.discretionaryData, 2); err != nil {
+		return err
+	}
+	if t.PaymentRelatedInformation != "" {
+		if (t.CCDDetail != nil && t.CCDDetail.PaymentInformation != "") || (t.WEBDetail != nil && t.WEBDetail.PaymentInformation != "") {
+			return fmt.Errorf("%s transfers can only set one of paymentRelatedInformation or paymentInformation", t.StandardEntryClassCode)
+		}
+	}
+	return nil
+}
+
+// validateACHAlphanumeric returns an error if s has characters which can't be written into an ACH file.
+func validateACHAlphanumeric(s string) error {
+	for i, r := range s {
+		if r < ' ' || r > '~' {
+			return fmt.Errorf("has invalid character %q at position %d", r, i)
+		}
+	}
+	return nil
+}
+
+// identificationNumber returns the user supplied IdentificationNumber or a random one if it's empty.
+func (t *Transfer) identificationNumber() string {
+	if t.IdentificationNumber != "" {
+		return t.IdentificationNumber
+	}
+	return createIdentificationNumber()
+}
+
+// paymentRelatedInformation returns the user supplied PaymentRelatedInformation falling back to detail,
+// which is read from the SEC specific detail object.
+func (t *Transfer) paymentRelatedInformation(detail string) string {
+	if t.PaymentRelatedInformation != "" {
+		return t.PaymentRelatedInformation
+	}
+	return detail
+}
+
 func createIdentificationNumber() string {
 	return base.ID()[:15]
 }
 
-func createTraceNumber(odfiRoutingNumber string) string {
-	v := fmt.Sprintf("%s%d", aba8(odfiRoutingNumber), traceNumberSource.Int63())
-	if utf8.RuneCountInString(v) > 15 {
-		return v[:15]
+// validateTraceNumber ensures a trace number was reserved for the ODFI which originates the entry.
+func validateTraceNumber(traceNumber, odfiRoutingNumber string) error {
+	if traceNumber == "" {
+		return errors.New("missing trace number")
 	}
-	return v
+	if n := utf8.RuneCountInString(traceNumber); n != 15 {
+		return fmt.Errorf("trace number %q is %d characters, expected 15", traceNumber, n)
+	}
+	for _, r := range traceNumber {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("trace number %q is not numeric", traceNumber)
+		}
+	}
+	if odfi := aba8(odfiRoutingNumber); !strings.HasPrefix(traceNumber, odfi) {
+		return fmt.Errorf("trace number %q does not start with ODFI %s", traceNumber, odfi)
+	}
+	return nil
 }
 
-func writeTransferEvent(userID id.User, req *transferRequest, eventRepo events.Repository) error {
-	return eventRepo.WriteEvent(userID, &events.Event{
+func writeTransferEvent(ctx context.Context, userID id.User, req *transferRequest, eventRepo events.Repository) error {
+	return eventRepo.WriteEvent(ctx, userID, &events.Event{
 		ID:      events.EventID(base.ID()),
 		Topic:   fmt.Sprintf("%s transfer to %s", req.Type, req.Description),
 		Message: req.Description,
@@ -0,0 +1,42 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+type ARCDetail struct {
+	// CheckSerialNumber is the serial number of the source check, up to 15 characters.
+	CheckSerialNumber string `json:"checkSerialNumber"`
+}
+
+func (d *ARCDetail) validate() error {
+	if d == nil {
+		return errors.New("ARC: missing ARCDetail")
+	}
+	if err := validateCheckSerialNumber(d.CheckSerialNumber, 15); err != nil {
+		return fmt.Errorf("ARC: %v", err)
+	}
+	return nil
+}
+
+// createARCBatch creates and returns an ARC ACH batch for a consumer check received by mail, or at a dropbox, which is
+// converted into a one-time debit. ARC entries are debits only and capped at $25,000.
+func createARCBatch(id string, userID id.User, transfer *Transfer, receiver *Receiver, receiverDep *Depository, orig *Originator, origDep *Depository) (ach.Batcher, error) {
+	if err := validateCheckConversion(transfer, receiverDep, 2500000); err != nil {
+		return nil, err
+	}
+	if err := transfer.ARCDetail.validate(); err != nil {
+		return nil, err
+	}
+	return createCheckConversionBatch(id, userID, transfer, receiver, receiverDep, orig, origDep, func(_ *ach.BatchHeader, ed *ach.EntryDetail) {
+		ed.SetCheckSerialNumber(transfer.ARCDetail.CheckSerialNumber)
+	})
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"testing"
+)
+
+func TestARCDetail__validate(t *testing.T) {
+	var detail *ARCDetail
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+	detail = &ARCDetail{}
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+	detail.CheckSerialNumber = "1234567890123456" // too long
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+	detail.CheckSerialNumber = "123-45"
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+	detail.CheckSerialNumber = "123456789012345"
+	if err := detail.validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+type BOCDetail struct {
+	// CheckSerialNumber is the serial number of the source check, up to 15 characters.
+	CheckSerialNumber string `json:"checkSerialNumber"`
+}
+
+func (d *BOCDetail) validate() error {
+	if d == nil {
+		return errors.New("BOC: missing BOCDetail")
+	}
+	if err := validateCheckSerialNumber(d.CheckSerialNumber, 15); err != nil {
+		return fmt.Errorf("BOC: %v", err)
+	}
+	return nil
+}
+
+// createBOCBatch creates and returns a BOC ACH batch for a check presented at the point of purchase, or a manned
+// bill payment location, which is converted into a one-time debit during back office processing. BOC entries are debits only
+// and capped at $25,000.
+func createBOCBatch(id string, userID id.User, transfer *Transfer, receiver *Receiver, receiverDep *Depository, orig *Originator, origDep *Depository) (ach.Batcher, error) {
+	if err := validateCheckConversion(transfer, receiverDep, 2500000); err != nil {
+		return nil, err
+	}
+	if err := transfer.BOCDetail.validate(); err != nil {
+		return nil, err
+	}
+	return createCheckConversionBatch(id, userID, transfer, receiver, receiverDep, orig, origDep, func(_ *ach.BatchHeader, ed *ach.EntryDetail) {
+		ed.SetCheckSerialNumber(transfer.BOCDetail.CheckSerialNumber)
+	})
+}
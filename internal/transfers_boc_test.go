@@ -0,0 +1,28 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"testing"
+)
+
+func TestBOCDetail__validate(t *testing.T) {
+	var detail *BOCDetail
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+	detail = &BOCDetail{}
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+	detail.CheckSerialNumber = "1234567890123456" // too long
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+	detail.CheckSerialNumber = "123456789012345"
+	if err := detail.validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -18,7 +18,12 @@ type CCDDetail struct {
 }
 
 func createCCDBatch(id string, userID id.User, transfer *Transfer, receiver *Receiver, receiverDep *Depository, orig *Originator, origDep *Depository) (ach.Batcher, error) {
-	if transfer.CCDDetail.PaymentInformation == "" {
+	var paymentInformation string
+	if transfer.CCDDetail != nil {
+		paymentInformation = transfer.CCDDetail.PaymentInformation
+	}
+	paymentInformation = transfer.paymentRelatedInformation(paymentInformation)
+	if paymentInformation == "" {
 		return nil, fmt.Errorf("transfer=%s CCD transfer is missing PaymentInformation", id)
 	}
 
@@ -40,9 +45,10 @@ func createCCDBatch(id string, userID id.User, transfer *Transfer, receiver *Rec
 	entryDetail.RDFIIdentification = aba8(receiverDep.RoutingNumber)
 	entryDetail.CheckDigit = abaCheckDigit(receiverDep.RoutingNumber)
 	entryDetail.Amount = transfer.Amount.Int()
-	entryDetail.IdentificationNumber = createIdentificationNumber()
+	entryDetail.IdentificationNumber = transfer.identificationNumber()
 	entryDetail.IndividualName = receiver.Metadata
-	entryDetail.TraceNumber = createTraceNumber(origDep.RoutingNumber)
+	entryDetail.DiscretionaryData = transfer.DiscretionaryData
+	entryDetail.TraceNumber = transfer.TraceNumber
 
 	if num, err := receiverDep.DecryptAccountNumber(); err != nil {
 		return nil, fmt.Errorf("CCD: receiver account number decrypt failed: %v", err)
@@ -53,7 +59,7 @@ func createCCDBatch(id string, userID id.User, transfer *Transfer, receiver *Rec
 	// Add Addenda05
 	addenda05 := ach.NewAddenda05()
 	addenda05.ID = id
-	addenda05.PaymentRelatedInformation = transfer.CCDDetail.PaymentInformation
+	addenda05.PaymentRelatedInformation = paymentInformation
 	addenda05.SequenceNumber = 1
 	addenda05.EntryDetailSequenceNumber = 1
 	entryDetail.AddAddenda05(addenda05)
@@ -65,6 +65,7 @@ func TestCCD__createCCDBatch(t *testing.T) {
 		Description:            "sending money",
 		StandardEntryClassCode: "CCD",
 		Status:                 TransferPending,
+		TraceNumber:            "231380100000001",
 		CCDDetail: &CCDDetail{
 			PaymentInformation: "test payment",
 		},
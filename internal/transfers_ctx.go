@@ -0,0 +1,154 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+const (
+	// ctxAddendaLength is the size of PaymentRelatedInformation in each Addenda05 record
+	ctxAddendaLength = 80
+
+	// ctxMaxAddenda is the most Addenda05 records NACHA allows on a CTX entry
+	ctxMaxAddenda = 9999
+)
+
+type CTXDetail struct {
+	// PaymentInformation is ANSI ASC X12 820 remittance data where each segment is terminated by
+	// a backslash and data elements are separated by an asterisk. For example:
+	//
+	//   ST*820*0001\BPR*C*1500*C*ACH*CTX\RMR*IV*1234**1500\SE*4*0001\
+	//
+	// It is split across as many Addenda05 records as needed.
+	PaymentInformation string `json:"paymentInformation"`
+}
+
+func (ctx *CTXDetail) validate() error {
+	if ctx == nil {
+		return errors.New("CTX: missing CTXDetail")
+	}
+	info := ctx.PaymentInformation
+	if info == "" {
+		return errors.New("CTX: missing PaymentInformation")
+	}
+	if n := len(info); n > ctxAddendaLength*ctxMaxAddenda {
+		return fmt.Errorf("CTX: PaymentInformation is %d characters which exceeds %d addenda records", n, ctxMaxAddenda)
+	}
+	if err := validateACHAlphanumeric(info); err != nil {
+		return fmt.Errorf("CTX: PaymentInformation %v", err)
+	}
+	if !strings.HasSuffix(info, `\`) {
+		return errors.New(`CTX: PaymentInformation must end with a segment terminator (\)`)
+	}
+	segments := strings.Split(strings.TrimSuffix(info, `\`), `\`)
+	for i := range segments {
+		elements := strings.Split(segments[i], "*")
+		if !validEDISegmentID(elements[0]) {
+			return fmt.Errorf("CTX: segment %d has invalid identifier %q", i+1, elements[0])
+		}
+		if elements[0] == "ST" && (len(elements) < 2 || elements[1] != "820") {
+			return fmt.Errorf("CTX: segment %d is not an 820 transaction set", i+1)
+		}
+	}
+	return nil
+}
+
+// validEDISegmentID returns true for X12 segment identifiers, which are two or three
+// uppercase letters or digits starting with a letter.
+func validEDISegmentID(s string) bool {
+	if len(s) < 2 || len(s) > 3 || s[0] < 'A' || s[0] > 'Z' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if (s[i] < 'A' || s[i] > 'Z') && (s[i] < '0' || s[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// addendaRecords splits PaymentInformation into the PaymentRelatedInformation of each Addenda05 record.
+func (ctx *CTXDetail) addendaRecords() []string {
+	var records []string
+	for info := ctx.PaymentInformation; info != ""; {
+		n := ctxAddendaLength
+		if len(info) < n {
+			n = len(info)
+		}
+		records = append(records, info[:n])
+		info = info[n:]
+	}
+	return records
+}
+
+func createCTXBatch(id string, userID id.User, transfer *Transfer, receiver *Receiver, receiverDep *Depository, orig *Originator, origDep *Depository) (ach.Batcher, error) {
+	if err := transfer.CTXDetail.validate(); err != nil {
+		return nil, fmt.Errorf("transfer=%s %v", id, err)
+	}
+
+	batchHeader := ach.NewBatchHeader()
+	batchHeader.ID = id
+	batchHeader.ServiceClassCode = determineServiceClassCode(transfer)
+	batchHeader.CompanyName = orig.Metadata
+	batchHeader.StandardEntryClassCode = ach.CTX
+	batchHeader.CompanyIdentification = orig.Identification
+	batchHeader.CompanyEntryDescription = transfer.Description
+	batchHeader.CompanyDescriptiveDate = time.Now().Format("060102")
+	batchHeader.EffectiveEntryDate = base.Now().AddBankingDay(1).Format("060102") // Date to be posted, YYMMDD
+	batchHeader.ODFIIdentification = aba8(origDep.RoutingNumber)
+
+	// Add EntryDetail to CTX batch
+	entryDetail := ach.NewEntryDetail()
+	entryDetail.ID = id
+	entryDetail.TransactionCode = determineTransactionCode(transfer, origDep)
+	entryDetail.RDFIIdentification = aba8(receiverDep.RoutingNumber)
+	entryDetail.CheckDigit = abaCheckDigit(receiverDep.RoutingNumber)
+	entryDetail.Amount = transfer.Amount.Int()
+	entryDetail.IdentificationNumber = transfer.identificationNumber()
+	entryDetail.DiscretionaryData = transfer.DiscretionaryData
+	entryDetail.TraceNumber = transfer.TraceNumber
+
+	if num, err := receiverDep.DecryptAccountNumber(); err != nil {
+		return nil, fmt.Errorf("CTX: receiver account number decrypt failed: %v", err)
+	} else {
+		entryDetail.DFIAccountNumber = num
+	}
+
+	// Add an Addenda05 for every chunk of remittance data
+	records := transfer.CTXDetail.addendaRecords()
+	for i := range records {
+		addenda05 := ach.NewAddenda05()
+		addenda05.ID = id
+		addenda05.PaymentRelatedInformation = records[i]
+		addenda05.SequenceNumber = i + 1
+		addenda05.EntryDetailSequenceNumber = 1
+		entryDetail.AddAddenda05(addenda05)
+	}
+	entryDetail.AddendaRecordIndicator = 1
+
+	// CTX entries carry the addenda count and receiving company in IndividualName
+	entryDetail.SetCATXAddendaRecords(len(records))
+	entryDetail.SetCATXReceivingCompany(receiver.Metadata)
+
+	batch, err := ach.NewBatch(batchHeader)
+	if err != nil {
+		return nil, fmt.Errorf("ACH file %s (userID=%s): failed to create batch: %v", id, userID, err)
+	}
+	batch.AddEntry(entryDetail)
+	batch.SetControl(ach.NewBatchControl())
+
+	if err := batch.Create(); err != nil {
+		return batch, err
+	}
+	return batch, nil
+}
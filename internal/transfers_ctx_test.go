@@ -0,0 +1,209 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	ctxRemittance = `ST*820*0001\BPR*C*1500*C*ACH*CTX*01*121042882*DA*123456789\TRN*1*12345\RMR*IV*INV-1001**1000\RMR*IV*INV-1002**500\SE*6*0001\`
+)
+
+func TestCTXDetail__validate(t *testing.T) {
+	detail := &CTXDetail{PaymentInformation: ctxRemittance}
+	if err := detail.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"empty":          "",
+		"no terminator":  "RMR*IV*1234**1500",
+		"bad segment":    `RMR*IV*1234**1500\rmr*IV*1\`,
+		"not 820":        `ST*810*0001\SE*2*0001\`,
+		"bad characters": "RMR*IV*1234**1500\t\\",
+		"too long":       strings.Repeat(`RMR*IV*1234**1500\`, 80*9999/18+1),
+	}
+	for name, info := range cases {
+		detail := &CTXDetail{PaymentInformation: info}
+		if err := detail.validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	var nilDetail *CTXDetail
+	if err := nilDetail.validate(); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCTXDetail__addendaRecords(t *testing.T) {
+	detail := &CTXDetail{PaymentInformation: strings.Repeat(`RMR*IV*1234**1500\`, 10)} // 180 characters
+	records := detail.addendaRecords()
+	if len(records) != 3 {
+		t.Fatalf("got %d records", len(records))
+	}
+	if len(records[0]) != 80 || len(records[1]) != 80 || len(records[2]) != 20 {
+		t.Errorf("unexpected record lengths: %d, %d, %d", len(records[0]), len(records[1]), len(records[2]))
+	}
+	if v := strings.Join(records, ""); v != detail.PaymentInformation {
+		t.Errorf("got %q", v)
+	}
+}
+
+func TestCTX__createCTXBatch(t *testing.T) {
+	depID, userID := base.ID(), id.User(base.ID())
+	keeper := secrets.TestStringKeeper(t)
+
+	receiverDep := &Depository{
+		ID:            id.Depository(base.ID()),
+		BankName:      "foo bank",
+		Holder:        "Acme Supply",
+		HolderType:    Business,
+		Type:          Checking,
+		RoutingNumber: "121042882",
+		Status:        DepositoryVerified,
+		Metadata:      "acme checking",
+		keeper:        keeper,
+	}
+	receiverDep.ReplaceAccountNumber("2")
+	receiver := &Receiver{
+		ID:                ReceiverID(base.ID()),
+		Email:             "ap@example.com",
+		DefaultDepository: receiverDep.ID,
+		Status:            ReceiverVerified,
+		Metadata:          "Acme Supply",
+	}
+	origDep := &Depository{
+		ID:            id.Depository(base.ID()),
+		BankName:      "foo bank",
+		Holder:        "Widgets Inc",
+		HolderType:    Business,
+		Type:          Checking,
+		RoutingNumber: "231380104",
+		Status:        DepositoryVerified,
+		Metadata:      "widgets checking",
+		keeper:        keeper,
+	}
+	origDep.ReplaceAccountNumber("2")
+	orig := &Originator{
+		ID:                OriginatorID(base.ID()),
+		DefaultDepository: origDep.ID,
+		Identification:    "dddd",
+		Metadata:          "Widgets Inc",
+	}
+	amt, _ := NewAmount("USD", "1500.00")
+	transfer := &Transfer{
+		ID:                     TransferID(base.ID()),
+		Type:                   PushTransfer,
+		Amount:                 *amt,
+		Originator:             orig.ID,
+		OriginatorDepository:   origDep.ID,
+		Receiver:               receiver.ID,
+		ReceiverDepository:     receiverDep.ID,
+		Description:            "invoices",
+		StandardEntryClassCode: ach.CTX,
+		Status:                 TransferPending,
+		TraceNumber:            "231380100000001",
+		CTXDetail: &CTXDetail{
+			PaymentInformation: ctxRemittance,
+		},
+	}
+
+	batch, err := createCTXBatch(depID, userID, transfer, receiver, receiverDep, orig, origDep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := batch.GetEntries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if n := len(entries[0].Addenda05); n != 2 {
+		t.Errorf("got %d Addenda05 records", n)
+	}
+	if v := entries[0].CATXAddendaRecordsField(); v != "0002" {
+		t.Errorf("CATXAddendaRecordsField=%s", v)
+	}
+
+	file, err := constructACHFile(depID, "", userID, transfer, receiver, receiverDep, orig, origDep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file == nil {
+		t.Error("nil CTX ach.File")
+	}
+
+	// sad path, invalid remittance
+	transfer.CTXDetail.PaymentInformation = "invoice 1234"
+	batch, err = createCTXBatch(depID, userID, transfer, receiver, receiverDep, orig, origDep)
+	if err == nil || batch != nil {
+		t.Fatalf("expected error: batch=%#v", batch)
+	}
+}
+
+func TestCTX__addendaStorage(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLTransferRepo) {
+		amt, _ := NewAmount("USD", "1500.00")
+		userID := id.User(base.ID())
+		req := &transferRequest{
+			Type:                   PushTransfer,
+			Amount:                 *amt,
+			Originator:             OriginatorID("originator"),
+			OriginatorDepository:   id.Depository("originator"),
+			Receiver:               ReceiverID("receiver"),
+			ReceiverDepository:     id.Depository("receiver"),
+			Description:            "invoices",
+			StandardEntryClassCode: ach.CTX,
+			CTXDetail: &CTXDetail{
+				PaymentInformation: strings.Repeat(`RMR*IV*1234**1500\`, 10) + ctxRemittance,
+			},
+		}
+		transfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(transfers) != 1 || transfers[0].CTXDetail == nil {
+			t.Fatalf("unexpected transfers: %#v", transfers)
+		}
+
+		xfer, err := repo.getUserTransfer(context.Background(), transfers[0].ID, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xfer.CTXDetail == nil {
+			t.Fatal("nil CTXDetail")
+		}
+		if xfer.CTXDetail.PaymentInformation != req.CTXDetail.PaymentInformation {
+			t.Errorf("got %q", xfer.CTXDetail.PaymentInformation)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, &SQLTransferRepo{sqliteDB.DB, log.NewNopLogger()})
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, &SQLTransferRepo{postgresDB.DB, log.NewNopLogger()})
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, &SQLTransferRepo{mysqlDB.DB, log.NewNopLogger()})
+}
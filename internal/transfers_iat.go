@@ -114,7 +114,7 @@ func createIATBatch(id string, userId id.User, transfer *Transfer, receiver *Rec
 	entryDetail.CheckDigit = abaCheckDigit(receiverDep.RoutingNumber)
 	entryDetail.Amount = transfer.Amount.Int()
 	entryDetail.AddendaRecordIndicator = 1
-	entryDetail.TraceNumber = createTraceNumber(origDep.RoutingNumber)
+	entryDetail.TraceNumber = transfer.TraceNumber
 	entryDetail.Category = "Forward"
 	entryDetail.SecondaryOFACScreeningIndicator = "1" // Set because we (paygate) checks the OFAC list
 
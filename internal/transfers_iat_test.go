@@ -111,6 +111,7 @@ func TestIAT__createIATBatch(t *testing.T) {
 		Description:            "sending money",
 		StandardEntryClassCode: "IAT",
 		Status:                 TransferPending,
+		TraceNumber:            "231380100000001",
 		IATDetail: &IATDetail{
 			OriginatorName:               orig.Metadata,
 			OriginatorAddress:            "123 1st st",
@@ -0,0 +1,62 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+type POPDetail struct {
+	// CheckSerialNumber is the serial number of the source check, up to 9 characters.
+	CheckSerialNumber string `json:"checkSerialNumber"`
+
+	// TerminalCity is an abbreviation of the city where the check was converted, up to 4 characters.
+	TerminalCity string `json:"terminalCity"`
+
+	// TerminalState is the two letter abbreviation of the state where the check was converted.
+	TerminalState string `json:"terminalState"`
+}
+
+func (d *POPDetail) validate() error {
+	if d == nil {
+		return errors.New("POP: missing POPDetail")
+	}
+	if err := validateCheckSerialNumber(d.CheckSerialNumber, 9); err != nil {
+		return fmt.Errorf("POP: %v", err)
+	}
+	if n := utf8.RuneCountInString(d.TerminalCity); n == 0 || n > 4 {
+		return fmt.Errorf("POP: TerminalCity must be 1 to 4 characters, got %q", d.TerminalCity)
+	}
+	if len(d.TerminalState) != 2 {
+		return fmt.Errorf("POP: TerminalState must be 2 characters, got %q", d.TerminalState)
+	}
+	for _, r := range d.TerminalState {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return fmt.Errorf("POP: TerminalState must be letters, got %q", d.TerminalState)
+		}
+	}
+	return nil
+}
+
+// createPOPBatch creates and returns a POP ACH batch for a check presented at the point of purchase which
+// is converted into a one-time debit at the terminal. POP entries are debits only and capped at $25,000.
+func createPOPBatch(id string, userID id.User, transfer *Transfer, receiver *Receiver, receiverDep *Depository, orig *Originator, origDep *Depository) (ach.Batcher, error) {
+	if err := validateCheckConversion(transfer, receiverDep, 2500000); err != nil {
+		return nil, err
+	}
+	if err := transfer.POPDetail.validate(); err != nil {
+		return nil, err
+	}
+	return createCheckConversionBatch(id, userID, transfer, receiver, receiverDep, orig, origDep, func(_ *ach.BatchHeader, ed *ach.EntryDetail) {
+		ed.SetPOPCheckSerialNumber(transfer.POPDetail.CheckSerialNumber)
+		ed.SetPOPTerminalCity(transfer.POPDetail.TerminalCity)
+		ed.SetPOPTerminalState(transfer.POPDetail.TerminalState)
+	})
+}
@@ -0,0 +1,35 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"testing"
+)
+
+func TestPOPDetail__validate(t *testing.T) {
+	var detail *POPDetail
+	if err := detail.validate(); err == nil {
+		t.Error("expected error")
+	}
+
+	cases := []POPDetail{
+		{CheckSerialNumber: "", TerminalCity: "PHIL", TerminalState: "PA"},
+		{CheckSerialNumber: "1234567890", TerminalCity: "PHIL", TerminalState: "PA"},
+		{CheckSerialNumber: "123456789", TerminalCity: "", TerminalState: "PA"},
+		{CheckSerialNumber: "123456789", TerminalCity: "PHILA", TerminalState: "PA"},
+		{CheckSerialNumber: "123456789", TerminalCity: "PHIL", TerminalState: "P"},
+		{CheckSerialNumber: "123456789", TerminalCity: "PHIL", TerminalState: "P1"},
+	}
+	for i := range cases {
+		if err := cases[i].validate(); err == nil {
+			t.Errorf("%d: expected error: %#v", i, cases[i])
+		}
+	}
+
+	detail = &POPDetail{CheckSerialNumber: "123456789", TerminalCity: "PHIL", TerminalState: "PA"}
+	if err := detail.validate(); err != nil {
+		t.Error(err)
+	}
+}
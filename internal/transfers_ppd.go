@@ -32,10 +32,10 @@ func createPPDBatch(id string, userId id.User, transfer *Transfer, receiver *Rec
 	entryDetail.RDFIIdentification = aba8(receiverDep.RoutingNumber)
 	entryDetail.CheckDigit = abaCheckDigit(receiverDep.RoutingNumber)
 	entryDetail.Amount = transfer.Amount.Int()
-	entryDetail.IdentificationNumber = createIdentificationNumber()
+	entryDetail.IdentificationNumber = transfer.identificationNumber()
 	entryDetail.IndividualName = receiver.Metadata
-	entryDetail.DiscretionaryData = transfer.Description
-	entryDetail.TraceNumber = createTraceNumber(origDep.RoutingNumber)
+	entryDetail.DiscretionaryData = transfer.DiscretionaryData
+	entryDetail.TraceNumber = transfer.TraceNumber
 
 	if num, err := receiverDep.DecryptAccountNumber(); err != nil {
 		return nil, fmt.Errorf("PPD: receiver account number decrypt failed: %v", err)
@@ -43,14 +43,16 @@ func createPPDBatch(id string, userId id.User, transfer *Transfer, receiver *Rec
 		entryDetail.DFIAccountNumber = num
 	}
 
-	// Add Addenda05
-	addenda05 := ach.NewAddenda05()
-	addenda05.ID = id
-	addenda05.PaymentRelatedInformation = "paygate transaction"
-	addenda05.SequenceNumber = 1
-	addenda05.EntryDetailSequenceNumber = 1
-	entryDetail.AddAddenda05(addenda05)
-	entryDetail.AddendaRecordIndicator = 1
+	// Add Addenda05 when there's payment related information for the Receiver
+	if transfer.PaymentRelatedInformation != "" {
+		addenda05 := ach.NewAddenda05()
+		addenda05.ID = id
+		addenda05.PaymentRelatedInformation = transfer.PaymentRelatedInformation
+		addenda05.SequenceNumber = 1
+		addenda05.EntryDetailSequenceNumber = 1
+		entryDetail.AddAddenda05(addenda05)
+		entryDetail.AddendaRecordIndicator = 1
+	}
 
 	// For now just create PPD
 	batch, err := ach.NewBatch(batchHeader)
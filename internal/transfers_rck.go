@@ -0,0 +1,43 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/moov-io/ach"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+type RCKDetail struct {
+	// CheckSerialNumber is the serial number of the source check, up to 15 characters.
+	CheckSerialNumber string `json:"checkSerialNumber"`
+}
+
+func (d *RCKDetail) validate() error {
+	if d == nil {
+		return errors.New("RCK: missing RCKDetail")
+	}
+	if err := validateCheckSerialNumber(d.CheckSerialNumber, 15); err != nil {
+		return fmt.Errorf("RCK: %v", err)
+	}
+	return nil
+}
+
+// createRCKBatch creates and returns an RCK ACH batch to collect a consumer check which has been returned for insufficient
+// or uncollected funds. RCK entries are debits only and capped at $2,500.
+func createRCKBatch(id string, userID id.User, transfer *Transfer, receiver *Receiver, receiverDep *Depository, orig *Originator, origDep *Depository) (ach.Batcher, error) {
+	if err := validateCheckConversion(transfer, receiverDep, 250000); err != nil {
+		return nil, err
+	}
+	if err := transfer.RCKDetail.validate(); err != nil {
+		return nil, err
+	}
+	return createCheckConversionBatch(id, userID, transfer, receiver, receiverDep, orig, origDep, func(bh *ach.BatchHeader, ed *ach.EntryDetail) {
+		bh.CompanyEntryDescription = "REDEPCHECK" // required by NACHA for RCK
+		ed.SetCheckSerialNumber(transfer.RCKDetail.CheckSerialNumber)
+	})
+}
@@ -72,14 +72,16 @@ func createTELBatch(id string, userId id.User, transfer *Transfer, receiver *Rec
 	entryDetail.RDFIIdentification = aba8(receiverDep.RoutingNumber)
 	entryDetail.CheckDigit = abaCheckDigit(receiverDep.RoutingNumber)
 	entryDetail.Amount = transfer.Amount.Int()
-	if transfer.Description != "" {
+	if transfer.IdentificationNumber != "" {
+		entryDetail.IdentificationNumber = transfer.IdentificationNumber
+	} else if transfer.Description != "" {
 		r := strings.NewReplacer("-", "", ".", "", " ", "")
 		entryDetail.IdentificationNumber = r.Replace(transfer.Description) // phone number (which TEL requires)
 	} else {
 		entryDetail.IdentificationNumber = createIdentificationNumber()
 	}
 	entryDetail.IndividualName = receiver.Metadata
-	entryDetail.TraceNumber = createTraceNumber(origDep.RoutingNumber)
+	entryDetail.TraceNumber = transfer.TraceNumber
 
 	if num, err := receiverDep.DecryptAccountNumber(); err != nil {
 		return nil, fmt.Errorf("TEL: receiver account number decrypt failed: %v", err)
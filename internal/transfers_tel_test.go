@@ -84,6 +84,7 @@ func TestTEL__createTELBatch(t *testing.T) {
 		Description:            "sending money",
 		StandardEntryClassCode: "TEL",
 		Status:                 TransferPending,
+		TraceNumber:            "231380100000001",
 		TELDetail: &TELDetail{
 			PaymentType: "single",
 		},
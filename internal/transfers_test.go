@@ -6,6 +6,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -21,6 +22,7 @@ import (
 	"github.com/moov-io/ach"
 	"github.com/moov-io/base"
 	moovcustomers "github.com/moov-io/customers/client"
+	"github.com/moov-io/paygate/internal/auth"
 	"github.com/moov-io/paygate/internal/customers"
 	"github.com/moov-io/paygate/internal/database"
 	"github.com/moov-io/paygate/internal/events"
@@ -66,8 +68,10 @@ func CreateTestTransferRouter(
 			depRepo:            dep,
 			eventRepo:          evt,
 			receiverRepository: rec,
+			authorizationRepo:  &MockAuthorizationRepository{},
 			origRepo:           ori,
 			transferRepo:       xfr,
+			traceNumbers:       &mockTraceNumberRepository{},
 			achClientFactory: func(_ id.User) *achclient.ACH {
 				return ach
 			},
@@ -523,12 +527,468 @@ func TestTransfers__create(t *testing.T) {
 	}
 }
 
+func TestTransfers__createPullAuthorization(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	now := base.NewTime(time.Now())
+	keeper := secrets.TestStringKeeper(t)
+
+	depRepo := &MockDepositoryRepository{
+		Depositories: []*Depository{
+			{
+				ID:            id.Depository("originator"),
+				BankName:      "orig bank",
+				Holder:        "orig",
+				HolderType:    Individual,
+				Type:          Checking,
+				RoutingNumber: "121421212",
+				Status:        DepositoryVerified,
+				Created:       now,
+				Updated:       now,
+				keeper:        keeper,
+			},
+			{
+				ID:            id.Depository("receiver"),
+				BankName:      "receiver bank",
+				Holder:        "receiver",
+				HolderType:    Individual,
+				Type:          Checking,
+				RoutingNumber: "121421212",
+				Status:        DepositoryVerified,
+				Created:       now,
+				Updated:       now,
+				keeper:        keeper,
+			},
+		},
+	}
+	depRepo.Depositories[0].ReplaceAccountNumber("1321")
+	depRepo.Depositories[1].ReplaceAccountNumber("323431")
+
+	recRepo := &mockReceiverRepository{
+		receivers: []*Receiver{
+			{
+				ID:                ReceiverID("receiver"),
+				Email:             "foo@moov.io",
+				DefaultDepository: id.Depository("receiver"),
+				Status:            ReceiverVerified,
+				Metadata:          "Jane Doe",
+				Created:           now,
+				Updated:           now,
+			},
+		},
+	}
+	origRepo := &mockOriginatorRepository{
+		originators: []*Originator{
+			{
+				ID:                OriginatorID("originator"),
+				DefaultDepository: id.Depository("originator"),
+				Identification:    "id",
+				Metadata:          "Acme Corp",
+				Created:           now,
+				Updated:           now,
+			},
+		},
+	}
+	amountCap, _ := NewAmount("USD", "20.00")
+	authRepo := NewAuthorizationRepo(log.NewNopLogger(), db.DB)
+	if err := authRepo.createUserAuthorization(context.Background(), id.User("test"), &Authorization{
+		ID:         AuthorizationID("authorization"),
+		Receiver:   ReceiverID("receiver"),
+		Originator: OriginatorID("originator"),
+		Type:       AuthorizationWritten,
+		Scope:      AuthorizationSingle,
+		AmountCap:  amountCap,
+		Created:    now,
+		Updated:    now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	eventRepo := events.NewRepo(log.NewNopLogger(), db.DB)
+	repo := &SQLTransferRepo{db.DB, log.NewNopLogger()}
+
+	amt, _ := NewAmount("USD", "18.61")
+	pull := transferRequest{
+		Type:                   PullTransfer,
+		Amount:                 *amt,
+		Originator:             OriginatorID("originator"),
+		OriginatorDepository:   id.Depository("originator"),
+		Receiver:               ReceiverID("receiver"),
+		ReceiverDepository:     id.Depository("receiver"),
+		Authorization:          AuthorizationID("authorization"),
+		Description:            "money",
+		StandardEntryClassCode: "PPD",
+	}
+	create := func(t *testing.T, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router := CreateTestTransferRouter(depRepo, eventRepo, recRepo, origRepo, repo, func(r *mux.Router) {
+			achclient.AddCreateRoute(nil, r)
+		}, achclient.AddValidateRoute)
+		defer router.close()
+		router.TransferRouter.accountsClient = nil
+		router.TransferRouter.authorizationRepo = authRepo
+
+		req, _ := http.NewRequest("POST", "/transfers", &buf)
+		req.Header.Set("x-user-id", "test")
+		router.createUserTransfers()(w, req)
+		w.Flush()
+		return w
+	}
+
+	// missing authorization
+	noAuth := pull
+	noAuth.Authorization = ""
+	if w := create(t, noAuth); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "missing authorization") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// over the amount cap
+	tooMuch := pull
+	tooMuch.Amount = Amount{symbol: "USD", number: 2001}
+	if w := create(t, tooMuch); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "exceeds authorization") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// single use authorizations can't be used twice in a batch
+	if w := create(t, []transferRequest{pull, pull}); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "already used") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := create(t, pull); w.Code != http.StatusOK {
+		t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+	transfers, err := repo.getUserTransfers(context.Background(), id.User("test"))
+	if err != nil || len(transfers) != 1 {
+		t.Fatalf("transfers=%#v error=%v", transfers, err)
+	}
+	if transfers[0].Authorization != AuthorizationID("authorization") {
+		t.Errorf("transfers[0].Authorization=%s", transfers[0].Authorization)
+	}
+
+	// the single use authorization was consumed
+	if w := create(t, pull); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "already used") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+
+	// revoked authorizations are rejected
+	authRepo.RevokeAuthorization(context.Background(), AuthorizationID("authorization"), "revoked by user")
+	if w := create(t, pull); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "was revoked") {
+		t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTransfers__getTransferObjectsLinkedDepository(t *testing.T) {
+	keeper := secrets.TestStringKeeper(t)
+	dep := &Depository{
+		ID:            id.Depository("receiver"),
+		BankName:      "receiver bank",
+		Holder:        "receiver",
+		HolderType:    Individual,
+		Type:          Savings,
+		RoutingNumber: "121421212",
+		Status:        DepositoryVerified,
+		keeper:        keeper,
+	}
+	dep.ReplaceAccountNumber("323431")
+	depRepo := &MockDepositoryRepository{Depositories: []*Depository{dep}}
+
+	recRepo := &mockReceiverRepository{
+		receivers: []*Receiver{
+			{
+				ID:                ReceiverID("receiver"),
+				Email:             "foo@moov.io",
+				DefaultDepository: id.Depository("checking"),
+				Status:            ReceiverVerified,
+			},
+		},
+		depositories: []id.Depository{"checking"},
+	}
+	origRepo := &mockOriginatorRepository{
+		originators: []*Originator{
+			{
+				ID:                OriginatorID("originator"),
+				DefaultDepository: id.Depository("receiver"),
+				Identification:    "id",
+			},
+		},
+	}
+	req := &transferRequest{
+		Originator:           OriginatorID("originator"),
+		OriginatorDepository: id.Depository("receiver"),
+		Receiver:             ReceiverID("receiver"),
+		ReceiverDepository:   dep.ID,
+	}
+
+	// the Receiver's savings account isn't linked yet
+	_, _, _, _, err := getTransferObjects(context.Background(), req, id.User("test"), depRepo, recRepo, origRepo)
+	if err == nil || !strings.Contains(err.Error(), "is not linked to receiver") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	recRepo.depositories = append(recRepo.depositories, dep.ID)
+	if _, receiverDep, _, _, err := getTransferObjects(context.Background(), req, id.User("test"), depRepo, recRepo, origRepo); err != nil {
+		t.Fatal(err)
+	} else if receiverDep.ID != dep.ID {
+		t.Errorf("unexpected receiver depository: %#v", receiverDep)
+	}
+}
+
+func TestTransfers__createBatch(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	logger := log.NewNopLogger()
+	now := base.NewTime(time.Now())
+	keeper := secrets.TestStringKeeper(t)
+
+	depRepo := &MockDepositoryRepository{
+		Depositories: []*Depository{
+			{
+				ID:            id.Depository("originator"),
+				BankName:      "orig bank",
+				Holder:        "orig",
+				HolderType:    Individual,
+				Type:          Checking,
+				RoutingNumber: "121421212",
+				Status:        DepositoryVerified,
+				Created:       now,
+				Updated:       now,
+				keeper:        keeper,
+			},
+			{
+				ID:            id.Depository("receiver"),
+				BankName:      "receiver bank",
+				Holder:        "receiver",
+				HolderType:    Individual,
+				Type:          Checking,
+				RoutingNumber: "121421212",
+				Status:        DepositoryVerified,
+				Created:       now,
+				Updated:       now,
+				keeper:        keeper,
+			},
+		},
+	}
+	depRepo.Depositories[0].ReplaceAccountNumber("1321")
+	depRepo.Depositories[1].ReplaceAccountNumber("323431")
+
+	eventRepo := events.NewRepo(logger, db.DB)
+	recRepo := &mockReceiverRepository{
+		receivers: []*Receiver{
+			{
+				ID:                ReceiverID("receiver"),
+				Email:             "foo@moov.io",
+				DefaultDepository: id.Depository("receiver"),
+				Status:            ReceiverVerified,
+				Metadata:          "Jane Doe",
+				Created:           now,
+				Updated:           now,
+			},
+		},
+	}
+	origRepo := &mockOriginatorRepository{
+		originators: []*Originator{
+			{
+				ID:                OriginatorID("originator"),
+				DefaultDepository: id.Depository("originator"),
+				Identification:    "id",
+				Metadata:          "Acme Corp",
+				Created:           now,
+				Updated:           now,
+			},
+		},
+	}
+	repo := &SQLTransferRepo{db.DB, log.NewNopLogger()}
+
+	amt, _ := NewAmount("USD", "18.61")
+	good := transferRequest{
+		Type:                   PushTransfer,
+		Amount:                 *amt,
+		Originator:             OriginatorID("originator"),
+		OriginatorDepository:   id.Depository("originator"),
+		Receiver:               ReceiverID("receiver"),
+		ReceiverDepository:     id.Depository("receiver"),
+		Description:            "money",
+		StandardEntryClassCode: "PPD",
+	}
+	bad := good
+	bad.StandardEntryClassCode = ""
+
+	encode := func(t *testing.T, requests ...transferRequest) *bytes.Buffer {
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(requests); err != nil {
+			t.Fatal(err)
+		}
+		return &body
+	}
+
+	var created, deleted int
+	countingRoutes := func(r *mux.Router) {
+		r.Methods("POST").Path("/files/create").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			created++
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(fmt.Sprintf(`{"id": "file-%d", "error": null}`, created)))
+		})
+		r.Methods("DELETE").Path("/files/{fileId}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deleted++
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{}"))
+		})
+	}
+	setup := func(validate func(*mux.Router)) (*testTransferRouter, *testAccountsClient) {
+		created, deleted = 0, 0
+		router := CreateTestTransferRouter(depRepo, eventRepo, recRepo, origRepo, repo, countingRoutes, validate)
+		accountsClient := router.accountsClient.(*testAccountsClient)
+		accountsClient.accounts = []accounts.Account{{ID: base.ID()}}
+		accountsClient.transaction = &accounts.Transaction{ID: base.ID()}
+		return router, accountsClient
+	}
+
+	t.Run("atomic validation", func(t *testing.T) {
+		router, accountsClient := setup(achclient.AddValidateRoute)
+		defer router.close()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/transfers/batch", encode(t, good, bad))
+		req.Header.Set("x-user-id", "test")
+		router.createUserTransfers()(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		if created != 0 || len(accountsClient.postedTransactions) != 0 {
+			t.Errorf("created=%d files and %d transactions", created, len(accountsClient.postedTransactions))
+		}
+	})
+
+	t.Run("atomic compensation", func(t *testing.T) {
+		router, accountsClient := setup(achclient.AddInvalidRoute)
+		defer router.close()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/transfers/batch?mode=atomic", encode(t, good, good))
+		req.Header.Set("x-user-id", "test")
+		router.createUserTransfers()(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		if created != 1 || deleted != 1 {
+			t.Errorf("created=%d deleted=%d", created, deleted)
+		}
+		if n := len(accountsClient.reversedTransactions); n != 1 {
+			t.Errorf("reversed %d transactions", n)
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		router, accountsClient := setup(achclient.AddValidateRoute)
+		defer router.close()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/transfers/batch?mode=partial", encode(t, good, bad, good))
+		req.Header.Set("x-user-id", "partial")
+		router.createUserTransfers()(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusMultiStatus {
+			t.Fatalf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		var results []batchTransferResult
+		if err := json.NewDecoder(w.Body).Decode(&results); err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("got %d results", len(results))
+		}
+		if results[0].Transfer == nil || results[0].Error != "" {
+			t.Errorf("results[0]: %#v", results[0])
+		}
+		if results[1].Transfer != nil || !strings.Contains(results[1].Error, "standardEntryClassCode") {
+			t.Errorf("results[1]: %#v", results[1])
+		}
+		if results[2].Transfer == nil || results[2].Error != "" {
+			t.Errorf("results[2]: %#v", results[2])
+		}
+		if created != 2 || deleted != 0 || len(accountsClient.reversedTransactions) != 0 {
+			t.Errorf("created=%d deleted=%d reversed=%d", created, deleted, len(accountsClient.reversedTransactions))
+		}
+		if events, err := eventRepo.GetUserEvents(context.Background(), id.User("partial")); err != nil || len(events) != 2 {
+			t.Errorf("got %d events: %v", len(events), err)
+		}
+	})
+
+	t.Run("partial success", func(t *testing.T) {
+		router, _ := setup(achclient.AddValidateRoute)
+		defer router.close()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/transfers/batch?mode=partial", encode(t, good, good))
+		req.Header.Set("x-user-id", "test")
+		router.createUserTransfers()(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("insert failure", func(t *testing.T) {
+		created, deleted = 0, 0
+		router := CreateTestTransferRouter(depRepo, eventRepo, recRepo, origRepo, &MockTransferRepository{Err: errors.New("bad error")}, countingRoutes, achclient.AddValidateRoute)
+		defer router.close()
+		accountsClient := router.accountsClient.(*testAccountsClient)
+		accountsClient.accounts = []accounts.Account{{ID: base.ID()}}
+		accountsClient.transaction = &accounts.Transaction{ID: base.ID()}
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/transfers/batch", encode(t, good, good))
+		req.Header.Set("x-user-id", "insert-failure")
+		router.createUserTransfers()(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+		if created != 2 || deleted != 2 || len(accountsClient.reversedTransactions) != 2 {
+			t.Errorf("created=%d deleted=%d reversed=%d", created, deleted, len(accountsClient.reversedTransactions))
+		}
+		if events, err := eventRepo.GetUserEvents(context.Background(), id.User("insert-failure")); err != nil || len(events) != 0 {
+			t.Errorf("got %d events: %v", len(events), err)
+		}
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		router, _ := setup(achclient.AddValidateRoute)
+		defer router.close()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/transfers/batch?mode=other", encode(t, good))
+		req.Header.Set("x-user-id", "test")
+		router.createUserTransfers()(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("bogus HTTP status: %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
 func TestTransfers__idempotency(t *testing.T) {
 	// The repositories aren't used, aka idempotency check needs to be first.
 	xferRouter := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xferRouter.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	xferRouter.RegisterRoutes(router)
 
 	req := httptest.NewRequest("POST", "/transfers", nil)
@@ -568,9 +1028,14 @@ func TestTransfers__getUserTransfer(t *testing.T) {
 		Description:            "money",
 		StandardEntryClassCode: "PPD",
 		fileID:                 "test-file",
+		traceNumber:            "121042880000001",
+
+		PaymentRelatedInformation: "invoice 1234",
+		IdentificationNumber:      "emp-42",
+		DiscretionaryData:         "AB",
 	}
 
-	xfers, err := repo.createUserTransfers(userID, []*transferRequest{req})
+	xfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -585,7 +1050,7 @@ func TestTransfers__getUserTransfer(t *testing.T) {
 	xferRouter := CreateTestTransferRouter(nil, nil, nil, nil, repo)
 	defer xferRouter.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	xferRouter.RegisterRoutes(router)
 	router.ServeHTTP(w, r)
 	w.Flush()
@@ -605,8 +1070,14 @@ func TestTransfers__getUserTransfer(t *testing.T) {
 	if v := transfer.Amount.String(); v != "USD 18.61" {
 		t.Errorf("got %q", v)
 	}
+	if transfer.PaymentRelatedInformation != "invoice 1234" || transfer.IdentificationNumber != "emp-42" || transfer.DiscretionaryData != "AB" {
+		t.Errorf("unexpected entry fields: %#v", transfer)
+	}
+	if transfer.TraceNumber != "121042880000001" {
+		t.Errorf("TraceNumber=%q", transfer.TraceNumber)
+	}
 
-	fileID, _ := repo.GetFileIDForTransfer(transfer.ID, userID)
+	fileID, _ := repo.GetFileIDForTransfer(context.Background(), transfer.ID, userID)
 	if fileID != "test-file" {
 		t.Error("no fileID found in transfers table")
 	}
@@ -643,7 +1114,7 @@ func TestTransfers__getUserTransfers(t *testing.T) {
 		fileID:                 "test-file",
 	}
 
-	if _, err := repo.createUserTransfers(userID, []*transferRequest{req}); err != nil {
+	if _, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req}); err != nil {
 		t.Fatal(err)
 	}
 
@@ -654,7 +1125,7 @@ func TestTransfers__getUserTransfers(t *testing.T) {
 	xferRouter := CreateTestTransferRouter(nil, nil, nil, nil, repo)
 	defer xferRouter.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	xferRouter.RegisterRoutes(router)
 	router.ServeHTTP(w, r)
 	w.Flush()
@@ -677,7 +1148,7 @@ func TestTransfers__getUserTransfers(t *testing.T) {
 		t.Errorf("got %q", v)
 	}
 
-	fileID, _ := repo.GetFileIDForTransfer(transfers[0].ID, userID)
+	fileID, _ := repo.GetFileIDForTransfer(context.Background(), transfers[0].ID, userID)
 	if fileID != "test-file" {
 		t.Error("no fileID found in transfers table")
 	}
@@ -694,6 +1165,53 @@ func TestTransfers__getUserTransfers(t *testing.T) {
 	}
 }
 
+func TestTransfers__createUserTransfersRollback(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+
+	repo := &SQLTransferRepo{db.DB, log.NewNopLogger()}
+
+	amt, _ := NewAmount("USD", "12.42")
+	userID := id.User(base.ID())
+	requests := []*transferRequest{
+		{
+			Type:                   PushTransfer,
+			Amount:                 *amt,
+			Originator:             OriginatorID("originator"),
+			OriginatorDepository:   id.Depository("originator"),
+			Receiver:               ReceiverID("receiver"),
+			ReceiverDepository:     id.Depository("receiver"),
+			Description:            "money",
+			StandardEntryClassCode: ach.CTX,
+			CTXDetail:              &CTXDetail{PaymentInformation: `RMR*IV*1234**1500\`},
+		},
+		{
+			Type:                   PushTransfer,
+			Amount:                 *amt,
+			Originator:             OriginatorID("originator"),
+			OriginatorDepository:   id.Depository("originator"),
+			Receiver:               ReceiverID("receiver"),
+			ReceiverDepository:     id.Depository("receiver"),
+			StandardEntryClassCode: "PPD", // missing Description fails validation
+		},
+	}
+	if _, err := repo.createUserTransfers(context.Background(), userID, requests); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// the first Transfer and its addenda should be rolled back
+	var transfers, addenda int
+	if err := db.DB.QueryRow(`select count(*) from transfers where user_id = ?;`, userID).Scan(&transfers); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DB.QueryRow(`select count(*) from transfer_addenda;`).Scan(&addenda); err != nil {
+		t.Fatal(err)
+	}
+	if transfers != 0 || addenda != 0 {
+		t.Errorf("found transfers=%d addenda=%d", transfers, addenda)
+	}
+}
+
 func TestTransfers__deleteUserTransfer(t *testing.T) {
 	db := database.CreateTestSqliteDB(t)
 	defer db.Close()
@@ -714,7 +1232,7 @@ func TestTransfers__deleteUserTransfer(t *testing.T) {
 		fileID:                 "test-file",
 	}
 
-	transfers, err := repo.createUserTransfers(userID, []*transferRequest{req})
+	transfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -729,7 +1247,7 @@ func TestTransfers__deleteUserTransfer(t *testing.T) {
 	xferRouter := CreateTestTransferRouter(nil, nil, nil, nil, repo, achclient.AddDeleteRoute)
 	defer xferRouter.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	xferRouter.RegisterRoutes(router)
 	router.ServeHTTP(w, r)
 	w.Flush()
@@ -769,7 +1287,7 @@ func TestTransfers__validateUserTransfer(t *testing.T) {
 		StandardEntryClassCode: "PPD",
 		fileID:                 "test-file",
 	}
-	transfers, err := repo.createUserTransfers(userID, []*transferRequest{req})
+	transfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -784,7 +1302,7 @@ func TestTransfers__validateUserTransfer(t *testing.T) {
 	xferRouter := CreateTestTransferRouter(nil, nil, nil, nil, repo, achclient.AddValidateRoute)
 	defer xferRouter.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	xferRouter.RegisterRoutes(router)
 	router.ServeHTTP(w, r)
 	w.Flush()
@@ -809,7 +1327,7 @@ func TestTransfers__validateUserTransfer(t *testing.T) {
 	mockRepo.Err = nil
 	xferRouter2 := CreateTestTransferRouter(nil, nil, nil, nil, repo, achclient.AddInvalidRoute)
 
-	router = mux.NewRouter()
+	router = auth.NewTestRouter()
 	xferRouter2.RegisterRoutes(router)
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, r)
@@ -839,7 +1357,7 @@ func TestTransfers__getUserTransferFiles(t *testing.T) {
 		StandardEntryClassCode: "PPD",
 		fileID:                 "test-file",
 	}
-	transfers, err := repo.createUserTransfers(userID, []*transferRequest{req})
+	transfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -854,7 +1372,7 @@ func TestTransfers__getUserTransferFiles(t *testing.T) {
 	xferRouter := CreateTestTransferRouter(nil, nil, nil, nil, repo, achclient.AddGetFileRoute)
 	defer xferRouter.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 	xferRouter.RegisterRoutes(router)
 	router.ServeHTTP(w, r)
 	w.Flush()
@@ -955,9 +1473,331 @@ func TestTransfers__writeResponse(t *testing.T) {
 	}
 }
 
-func TestTransfers__createTraceNumber(t *testing.T) {
-	if v := createTraceNumber("121042882"); v == "" {
-		t.Error("empty trace number")
+func TestTransfers__validateEntryFields(t *testing.T) {
+	transfer := &Transfer{
+		StandardEntryClassCode:    ach.PPD,
+		PaymentRelatedInformation: "invoice 1234",
+		IdentificationNumber:      "emp-42",
+		DiscretionaryData:         "AB",
+	}
+	if err := transfer.validateEntryFields(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]*Transfer{
+		"addenda too long":        {StandardEntryClassCode: ach.PPD, PaymentRelatedInformation: strings.Repeat("a", 81)},
+		"identification too long": {StandardEntryClassCode: ach.PPD, IdentificationNumber: strings.Repeat("1", 16)},
+		"discretionary too long":  {StandardEntryClassCode: ach.PPD, DiscretionaryData: "ABC"},
+		"invalid characters":      {StandardEntryClassCode: ach.PPD, PaymentRelatedInformation: "café"},
+		"TEL addenda":             {StandardEntryClassCode: ach.TEL, PaymentRelatedInformation: "invoice"},
+		"WEB discretionary data":  {StandardEntryClassCode: ach.WEB, DiscretionaryData: "AB"},
+		"ARC identification":      {StandardEntryClassCode: ach.ARC, IdentificationNumber: "1234"},
+		"IAT anything":            {StandardEntryClassCode: ach.IAT, DiscretionaryData: "AB"},
+		"CCD paymentInformation":  {StandardEntryClassCode: ach.CCD, PaymentRelatedInformation: "a", CCDDetail: &CCDDetail{PaymentInformation: "b"}},
+		"WEB paymentInformation":  {StandardEntryClassCode: ach.WEB, PaymentRelatedInformation: "a", WEBDetail: &WEBDetail{PaymentInformation: "b"}},
+	}
+	for name, xfer := range cases {
+		if err := xfer.validateEntryFields(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// checkConversionTransfer returns the objects for a pull transfer of amount from a consumer's checking account
+// to be used in ARC, BOC, POP and RCK tests.
+func checkConversionTransfer(t *testing.T, sec string, amount string) (*Transfer, *Receiver, *Depository, *Originator, *Depository) {
+	t.Helper()
+
+	keeper := secrets.TestStringKeeper(t)
+
+	receiverDep := &Depository{
+		ID:            id.Depository(base.ID()),
+		BankName:      "foo bank",
+		Holder:        "jane doe",
+		HolderType:    Individual,
+		Type:          Checking,
+		RoutingNumber: "121042882",
+		Status:        DepositoryVerified,
+		Metadata:      "jane doe checking",
+		keeper:        keeper,
+	}
+	receiverDep.ReplaceAccountNumber("2")
+	receiver := &Receiver{
+		ID:                ReceiverID(base.ID()),
+		Email:             "jane.doe@example.com",
+		DefaultDepository: receiverDep.ID,
+		Status:            ReceiverVerified,
+		Metadata:          "jane doe",
+	}
+	origDep := &Depository{
+		ID:            id.Depository(base.ID()),
+		BankName:      "foo bank",
+		Holder:        "john doe",
+		HolderType:    Business,
+		Type:          Checking,
+		RoutingNumber: "231380104",
+		Status:        DepositoryVerified,
+		Metadata:      "john doe checking",
+		keeper:        keeper,
+	}
+	origDep.ReplaceAccountNumber("2")
+	orig := &Originator{
+		ID:                OriginatorID(base.ID()),
+		DefaultDepository: origDep.ID,
+		Identification:    "dddd",
+		Metadata:          "john doe",
+	}
+	amt, err := NewAmount("USD", amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := &Transfer{
+		ID:                     TransferID(base.ID()),
+		Type:                   PullTransfer,
+		Amount:                 *amt,
+		Originator:             orig.ID,
+		OriginatorDepository:   origDep.ID,
+		Receiver:               receiver.ID,
+		ReceiverDepository:     receiverDep.ID,
+		Description:            "check pymt",
+		StandardEntryClassCode: sec,
+		Status:                 TransferPending,
+		TraceNumber:            "231380100000001",
+	}
+	return transfer, receiver, receiverDep, orig, origDep
+}
+
+func TestTransfers__checkConversionBatches(t *testing.T) {
+	cases := []struct {
+		sec       string
+		maxAmount string
+		detail    func(*Transfer)
+		check     func(*testing.T, ach.Batcher)
+	}{
+		{
+			sec:       ach.ARC,
+			maxAmount: "25000.00",
+			detail:    func(xfer *Transfer) { xfer.ARCDetail = &ARCDetail{CheckSerialNumber: "123456789"} },
+			check: func(t *testing.T, batch ach.Batcher) {
+				if v := batch.GetEntries()[0].CheckSerialNumberField(); v != "123456789      " {
+					t.Errorf("CheckSerialNumber=%q", v)
+				}
+				if v := batch.GetHeader().CompanyEntryDescription; v != "check pymt" {
+					t.Errorf("CompanyEntryDescription=%q", v)
+				}
+			},
+		},
+		{
+			sec:       ach.BOC,
+			maxAmount: "25000.00",
+			detail:    func(xfer *Transfer) { xfer.BOCDetail = &BOCDetail{CheckSerialNumber: "987654321012345"} },
+			check: func(t *testing.T, batch ach.Batcher) {
+				if v := batch.GetEntries()[0].CheckSerialNumberField(); v != "987654321012345" {
+					t.Errorf("CheckSerialNumber=%q", v)
+				}
+			},
+		},
+		{
+			sec:       ach.POP,
+			maxAmount: "25000.00",
+			detail: func(xfer *Transfer) {
+				xfer.POPDetail = &POPDetail{CheckSerialNumber: "123456789", TerminalCity: "PHIL", TerminalState: "PA"}
+			},
+			check: func(t *testing.T, batch ach.Batcher) {
+				entry := batch.GetEntries()[0]
+				if v := entry.POPCheckSerialNumberField(); v != "123456789" {
+					t.Errorf("POPCheckSerialNumber=%q", v)
+				}
+				if v := entry.POPTerminalCityField(); v != "PHIL" {
+					t.Errorf("POPTerminalCity=%q", v)
+				}
+				if v := entry.POPTerminalStateField(); v != "PA" {
+					t.Errorf("POPTerminalState=%q", v)
+				}
+			},
+		},
+		{
+			sec:       ach.RCK,
+			maxAmount: "2500.00",
+			detail:    func(xfer *Transfer) { xfer.RCKDetail = &RCKDetail{CheckSerialNumber: "555"} },
+			check: func(t *testing.T, batch ach.Batcher) {
+				if v := batch.GetEntries()[0].CheckSerialNumberField(); v != "555            " {
+					t.Errorf("CheckSerialNumber=%q", v)
+				}
+				if v := batch.GetHeader().CompanyEntryDescription; v != "REDEPCHECK" {
+					t.Errorf("CompanyEntryDescription=%q", v)
+				}
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.sec, func(t *testing.T) {
+			fileID, userID := base.ID(), id.User(base.ID())
+			transfer, receiver, receiverDep, orig, origDep := checkConversionTransfer(t, tc.sec, tc.maxAmount)
+			tc.detail(transfer)
+
+			file, err := constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			batch := file.Batches[0]
+			if v := batch.GetHeader().StandardEntryClassCode; v != tc.sec {
+				t.Errorf("StandardEntryClassCode=%q", v)
+			}
+			if v := batch.GetHeader().ServiceClassCode; v != ach.DebitsOnly {
+				t.Errorf("ServiceClassCode=%d", v)
+			}
+			if v := batch.GetEntries()[0].TransactionCode; v != ach.CheckingDebit {
+				t.Errorf("TransactionCode=%d", v)
+			}
+			tc.check(t, batch)
+
+			// over the amount limit
+			over := transfer.Amount.Int() + 1
+			transfer.Amount.FromString(fmt.Sprintf("USD %d.%02d", over/100, over%100))
+			if _, err := constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep); err == nil {
+				t.Error("expected error")
+			}
+			transfer.Amount.FromString("USD 100.00")
+
+			// converted checks only debit consumers
+			transfer.Type = PushTransfer
+			if _, err := constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep); err == nil {
+				t.Error("expected error")
+			}
+			transfer.Type = PullTransfer
+			receiverDep.HolderType = Business
+			if _, err := constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep); err == nil {
+				t.Error("expected error")
+			}
+			receiverDep.HolderType = Individual
+
+			// the SEC's detail is required
+			transfer.ARCDetail, transfer.BOCDetail, transfer.POPDetail, transfer.RCKDetail = nil, nil, nil, nil
+			if _, err := constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestTransfers__checkDetailStorage(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *SQLTransferRepo) {
+		amt, _ := NewAmount("USD", "12.34")
+		userID := id.User(base.ID())
+		newRequest := func(sec string) *transferRequest {
+			return &transferRequest{
+				Type:                   PullTransfer,
+				Amount:                 *amt,
+				Originator:             OriginatorID("originator"),
+				OriginatorDepository:   id.Depository("originator"),
+				Receiver:               ReceiverID("receiver"),
+				ReceiverDepository:     id.Depository("receiver"),
+				Description:            "check pymt",
+				StandardEntryClassCode: sec,
+			}
+		}
+		arc, boc, pop, rck := newRequest(ach.ARC), newRequest(ach.BOC), newRequest(ach.POP), newRequest(ach.RCK)
+		arc.ARCDetail = &ARCDetail{CheckSerialNumber: "1001"}
+		boc.BOCDetail = &BOCDetail{CheckSerialNumber: "1002"}
+		pop.POPDetail = &POPDetail{CheckSerialNumber: "1003", TerminalCity: "PHIL", TerminalState: "PA"}
+		rck.RCKDetail = &RCKDetail{CheckSerialNumber: "1004"}
+
+		transfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{arc, boc, pop, rck})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(transfers) != 4 {
+			t.Fatalf("got %d transfers", len(transfers))
+		}
+		read := func(i int) *Transfer {
+			xfer, err := repo.getUserTransfer(context.Background(), transfers[i].ID, userID)
+			if err != nil || xfer == nil {
+				t.Fatalf("transfer=%s: %v", transfers[i].ID, err)
+			}
+			return xfer
+		}
+		if xfer := read(0); xfer.ARCDetail == nil || *xfer.ARCDetail != *arc.ARCDetail {
+			t.Errorf("ARCDetail=%#v", xfer.ARCDetail)
+		}
+		if xfer := read(1); xfer.BOCDetail == nil || *xfer.BOCDetail != *boc.BOCDetail {
+			t.Errorf("BOCDetail=%#v", xfer.BOCDetail)
+		}
+		if xfer := read(2); xfer.POPDetail == nil || *xfer.POPDetail != *pop.POPDetail {
+			t.Errorf("POPDetail=%#v", xfer.POPDetail)
+		}
+		if xfer := read(3); xfer.RCKDetail == nil || *xfer.RCKDetail != *rck.RCKDetail || xfer.ARCDetail != nil {
+			t.Errorf("RCKDetail=%#v ARCDetail=%#v", xfer.RCKDetail, xfer.ARCDetail)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, &SQLTransferRepo{sqliteDB.DB, log.NewNopLogger()})
+
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, &SQLTransferRepo{postgresDB.DB, log.NewNopLogger()})
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, &SQLTransferRepo{mysqlDB.DB, log.NewNopLogger()})
+}
+
+func TestTransfers__entryFields(t *testing.T) {
+	fileID, userID := base.ID(), id.User(base.ID())
+	transfer, receiver, receiverDep, orig, origDep := checkConversionTransfer(t, ach.PPD, "12.34")
+	transfer.PaymentRelatedInformation = "invoice 1234"
+	transfer.IdentificationNumber = "emp-42"
+	transfer.DiscretionaryData = "AB"
+
+	file, err := constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := file.Batches[0].GetEntries()[0]
+	if entry.IdentificationNumber != "emp-42" || entry.DiscretionaryData != "AB" {
+		t.Errorf("IdentificationNumber=%q DiscretionaryData=%q", entry.IdentificationNumber, entry.DiscretionaryData)
+	}
+	if len(entry.Addenda05) != 1 || entry.Addenda05[0].PaymentRelatedInformation != "invoice 1234" {
+		t.Errorf("unexpected Addenda05: %#v", entry.Addenda05)
+	}
+	if entry.TraceNumber != transfer.TraceNumber {
+		t.Errorf("TraceNumber=%q", entry.TraceNumber)
+	}
+
+	// Without user supplied values there's no addenda and a random IdentificationNumber
+	transfer.PaymentRelatedInformation, transfer.IdentificationNumber, transfer.DiscretionaryData = "", "", ""
+	file, err = constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry = file.Batches[0].GetEntries()[0]
+	if len(entry.Addenda05) != 0 || entry.IdentificationNumber == "" || entry.DiscretionaryData != "" {
+		t.Errorf("unexpected entry: %#v", entry)
+	}
+
+	// Invalid values are rejected
+	transfer.DiscretionaryData = "ABC"
+	if _, err := constructACHFile(fileID, "", userID, transfer, receiver, receiverDep, orig, origDep); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestTransfers__validateTraceNumber(t *testing.T) {
+	if err := validateTraceNumber("121042880000001", "121042882"); err != nil {
+		t.Error(err)
+	}
+	cases := []string{"", "12104288000001", "12104288000000A", "231380100000001"}
+	for i := range cases {
+		if err := validateTraceNumber(cases[i], "121042882"); err == nil {
+			t.Errorf("expected error for %q", cases[i])
+		}
 	}
 }
 
@@ -987,7 +1827,7 @@ func TestTransfers_transferCursor(t *testing.T) {
 		Status:                 DepositoryUnverified,
 		Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
 	}
-	if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		t.Fatal(err)
 	}
 
@@ -1010,7 +1850,7 @@ func TestTransfers_transferCursor(t *testing.T) {
 			fileID:                 "test-file1",
 		},
 	}
-	if _, err := transferRepo.createUserTransfers(userID, requests); err != nil {
+	if _, err := transferRepo.createUserTransfers(context.Background(), userID, requests); err != nil {
 		t.Fatal(err)
 	}
 	requests = []*transferRequest{
@@ -1026,7 +1866,7 @@ func TestTransfers_transferCursor(t *testing.T) {
 			fileID:                 "test-file2",
 		},
 	}
-	if _, err := transferRepo.createUserTransfers(userID, requests); err != nil {
+	if _, err := transferRepo.createUserTransfers(context.Background(), userID, requests); err != nil {
 		t.Fatal(err)
 	}
 	requests = []*transferRequest{
@@ -1042,13 +1882,13 @@ func TestTransfers_transferCursor(t *testing.T) {
 			fileID:                 "test-file3",
 		},
 	}
-	if _, err := transferRepo.createUserTransfers(userID, requests); err != nil {
+	if _, err := transferRepo.createUserTransfers(context.Background(), userID, requests); err != nil {
 		t.Fatal(err)
 	}
 
 	// Now verify the cursor pulls those transfers out
 	cur := transferRepo.GetTransferCursor(2, depRepo) // batch size
-	firstBatch, err := cur.Next()
+	firstBatch, err := cur.Next(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1057,7 +1897,7 @@ func TestTransfers_transferCursor(t *testing.T) {
 			t.Errorf("firstBatch[%d]=%#v", i, firstBatch[i])
 		}
 	}
-	secondBatch, err := cur.Next()
+	secondBatch, err := cur.Next(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1093,7 +1933,7 @@ func TestTransfers_MarkTransferAsMerged(t *testing.T) {
 		Status:                 DepositoryVerified,
 		Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
 	}
-	if err := depRepo.UpsertUserDepository(userID, dep); err != nil {
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
 		t.Fatal(err)
 	}
 
@@ -1116,13 +1956,13 @@ func TestTransfers_MarkTransferAsMerged(t *testing.T) {
 			fileID:                 "test-file1",
 		},
 	}
-	if _, err := transferRepo.createUserTransfers(userID, requests); err != nil {
+	if _, err := transferRepo.createUserTransfers(context.Background(), userID, requests); err != nil {
 		t.Fatal(err)
 	}
 
 	// Now verify the cursor pulls those transfers out
 	cur := transferRepo.GetTransferCursor(2, depRepo) // batch size
-	firstBatch, err := cur.Next()
+	firstBatch, err := cur.Next(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1134,7 +1974,7 @@ func TestTransfers_MarkTransferAsMerged(t *testing.T) {
 	}
 
 	// mark our transfer as merged, so we don't see it (in a new transferCursor we create)
-	if err := transferRepo.MarkTransferAsMerged(firstBatch[0].ID, "merged-file.ach", "traceNumber"); err != nil {
+	if err := transferRepo.MarkTransferAsMerged(context.Background(), firstBatch[0].ID, "merged-file.ach", "traceNumber"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -1153,11 +1993,11 @@ func TestTransfers_MarkTransferAsMerged(t *testing.T) {
 			fileID:                 "test-file2",
 		},
 	}
-	if _, err := transferRepo.createUserTransfers(userID, requests); err != nil {
+	if _, err := transferRepo.createUserTransfers(context.Background(), userID, requests); err != nil {
 		t.Fatal(err)
 	}
 	cur = transferRepo.GetTransferCursor(2, depRepo) // batch size
-	firstBatch, err = cur.Next()
+	firstBatch, err = cur.Next(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1244,7 +2084,7 @@ func TestTransfers__postAccountTransaction(t *testing.T) {
 	}
 
 	userID, requestID := id.User(base.ID()), base.ID()
-	tx, err := xferRouter.postAccountTransaction(userID, origDep, recDep, *amt, PullTransfer, requestID)
+	tx, err := xferRouter.postAccountTransaction(context.Background(), userID, origDep, recDep, *amt, PullTransfer, requestID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1270,16 +2110,16 @@ func TestTransfers__UpdateTransferStatus(t *testing.T) {
 			StandardEntryClassCode: "PPD",
 			fileID:                 "test-file",
 		}
-		transfers, err := repo.createUserTransfers(userID, []*transferRequest{req})
+		transfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req})
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		if err := repo.UpdateTransferStatus(transfers[0].ID, TransferReclaimed); err != nil {
+		if err := repo.UpdateTransferStatus(context.Background(), transfers[0].ID, TransferReclaimed); err != nil {
 			t.Fatal(err)
 		}
 
-		xfer, err := repo.getUserTransfer(transfers[0].ID, userID)
+		xfer, err := repo.getUserTransfer(context.Background(), transfers[0].ID, userID)
 		if err != nil {
 			t.Error(err)
 		}
@@ -1293,6 +2133,11 @@ func TestTransfers__UpdateTransferStatus(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, &SQLTransferRepo{sqliteDB.DB, log.NewNopLogger()})
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, &SQLTransferRepo{postgresDB.DB, log.NewNopLogger()})
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -1321,11 +2166,11 @@ func TestTransfers__transactionID(t *testing.T) {
 				transactionID:          transactionID,
 			},
 		}
-		if _, err := repo.createUserTransfers(userID, requests); err != nil {
+		if _, err := repo.createUserTransfers(context.Background(), userID, requests); err != nil {
 			t.Fatal(err)
 		}
 
-		transfers, err := repo.getUserTransfers(userID)
+		transfers, err := repo.getUserTransfers(context.Background(), userID)
 		if err != nil || len(transfers) != 1 {
 			t.Errorf("got %d Transfers (error=%v): %v", len(transfers), err, transfers)
 		}
@@ -1352,6 +2197,11 @@ func TestTransfers__transactionID(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, sqliteDB.DB)
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -1375,18 +2225,18 @@ func TestTransfers__LookupTransferFromReturn(t *testing.T) {
 			StandardEntryClassCode: "PPD",
 			fileID:                 "test-file",
 		}
-		transfers, err := repo.createUserTransfers(userID, []*transferRequest{req})
+		transfers, err := repo.createUserTransfers(context.Background(), userID, []*transferRequest{req})
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		// set metadata after transfer is merged into an ACH file for the FED
-		if err := repo.MarkTransferAsMerged(transfers[0].ID, "merged.ach", "traceNumber"); err != nil {
+		if err := repo.MarkTransferAsMerged(context.Background(), transfers[0].ID, "merged.ach", "traceNumber"); err != nil {
 			t.Fatal(err)
 		}
 
 		// Now grab the transfer back
-		xfer, err := repo.LookupTransferFromReturn("PPD", amt, "traceNumber", time.Now()) // EffectiveEntryDate is bounded by start and end of a day
+		xfer, err := repo.LookupTransferFromReturn(context.Background(), "PPD", amt, "traceNumber", time.Now()) // EffectiveEntryDate is bounded by start and end of a day
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -1400,6 +2250,11 @@ func TestTransfers__LookupTransferFromReturn(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, &SQLTransferRepo{sqliteDB.DB, log.NewNopLogger()})
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, &SQLTransferRepo{postgresDB.DB, log.NewNopLogger()})
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -1427,22 +2282,22 @@ func TestTransfers__SetReturnCode(t *testing.T) {
 				StandardEntryClassCode: "PPD",
 			},
 		}
-		if _, err := repo.createUserTransfers(userID, requests); err != nil {
+		if _, err := repo.createUserTransfers(context.Background(), userID, requests); err != nil {
 			t.Fatal(err)
 		}
 
-		transfers, err := repo.getUserTransfers(userID)
+		transfers, err := repo.getUserTransfers(context.Background(), userID)
 		if err != nil || len(transfers) != 1 {
 			t.Errorf("got %d Transfers (error=%v): %v", len(transfers), err, transfers)
 		}
 
 		// Set ReturnCode
-		if err := repo.SetReturnCode(transfers[0].ID, returnCode); err != nil {
+		if err := repo.SetReturnCode(context.Background(), transfers[0].ID, returnCode); err != nil {
 			t.Fatal(err)
 		}
 
 		// Verify
-		transfers, err = repo.getUserTransfers(userID)
+		transfers, err = repo.getUserTransfers(context.Background(), userID)
 		if err != nil || len(transfers) != 1 {
 			t.Errorf("got %d Transfers (error=%v): %v", len(transfers), err, transfers)
 		}
@@ -1461,6 +2316,11 @@ func TestTransfers__SetReturnCode(t *testing.T) {
 	defer sqliteDB.Close()
 	check(t, sqliteDB.DB)
 
+	// PostgreSQL tests
+	postgresDB := database.CreateTestPostgresDB(t)
+	defer postgresDB.Close()
+	check(t, postgresDB.DB)
+
 	// MySQL tests
 	mysqlDB := database.CreateTestMySQLDB(t)
 	defer mysqlDB.Close()
@@ -1483,6 +2343,7 @@ func TestTransfers__constructACHFile(t *testing.T) {
 		Type:                   PushTransfer,
 		Status:                 TransferPending,
 		StandardEntryClassCode: "AAA", // invalid
+		TraceNumber:            "231380100000001",
 	}
 
 	file, err := constructACHFile("", "", "", transfer, receiver, receiverDep, orig, origDep)
@@ -1510,25 +2371,25 @@ func TestTransfers__verifyCustomerStatus(t *testing.T) {
 
 	userID := id.User(base.ID())
 
-	if err := verifyCustomerStatuses(orig, rec, client, base.ID(), userID); err != nil {
+	if err := verifyCustomerStatuses(context.Background(), orig, rec, client, base.ID(), userID); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 
 	// set an unacceptable status
 	client.Customer.Status = "reviewrequired"
-	if err := verifyCustomerStatuses(orig, rec, client, base.ID(), userID); err == nil {
+	if err := verifyCustomerStatuses(context.Background(), orig, rec, client, base.ID(), userID); err == nil {
 		t.Error("expected error")
 	}
 
 	// set an invalid status
 	client.Customer.Status = "invalid"
-	if err := verifyCustomerStatuses(orig, rec, client, base.ID(), userID); err == nil {
+	if err := verifyCustomerStatuses(context.Background(), orig, rec, client, base.ID(), userID); err == nil {
 		t.Error("expected error")
 	}
 
 	// set an error and handle it
 	client.Err = errors.New("bad error")
-	if err := verifyCustomerStatuses(orig, rec, client, base.ID(), userID); err == nil {
+	if err := verifyCustomerStatuses(context.Background(), orig, rec, client, base.ID(), userID); err == nil {
 		t.Error("expected error")
 	}
 }
@@ -1540,12 +2401,12 @@ func TestTransfers__verifyDisclaimersAreAccepted(t *testing.T) {
 
 	client := &customers.TestClient{}
 
-	if err := verifyDisclaimersAreAccepted(orig, rec, client, requestID, userID); err != nil {
+	if err := verifyDisclaimersAreAccepted(context.Background(), orig, rec, client, requestID, userID); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 
 	client.Err = errors.New("bad error")
-	if err := verifyDisclaimersAreAccepted(orig, rec, client, requestID, userID); err == nil {
+	if err := verifyDisclaimersAreAccepted(context.Background(), orig, rec, client, requestID, userID); err == nil {
 		t.Error("expected error")
 	}
 }
@@ -1592,7 +2453,7 @@ func TestTransfers__HTTPGetNoUserID(t *testing.T) {
 	xfer := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xfer.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 
 	xfer.RegisterRoutes(router)
 
@@ -1601,7 +2462,7 @@ func TestTransfers__HTTPGetNoUserID(t *testing.T) {
 	router.ServeHTTP(w, r)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("got %d", w.Code)
 	}
 }
@@ -1610,7 +2471,7 @@ func TestTransfers__HTTPCreateNoUserID(t *testing.T) {
 	xfer := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xfer.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 
 	xfer.RegisterRoutes(router)
 
@@ -1619,7 +2480,7 @@ func TestTransfers__HTTPCreateNoUserID(t *testing.T) {
 	router.ServeHTTP(w, r)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("got %d", w.Code)
 	}
 }
@@ -1628,7 +2489,7 @@ func TestTransfers__HTTPCreateBatchNoUserID(t *testing.T) {
 	xfer := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xfer.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 
 	xfer.RegisterRoutes(router)
 
@@ -1637,7 +2498,7 @@ func TestTransfers__HTTPCreateBatchNoUserID(t *testing.T) {
 	router.ServeHTTP(w, r)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("got %d", w.Code)
 	}
 }
@@ -1646,7 +2507,7 @@ func TestTransfers__HTTPDeleteNoUserID(t *testing.T) {
 	xfer := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xfer.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 
 	xfer.RegisterRoutes(router)
 
@@ -1655,7 +2516,7 @@ func TestTransfers__HTTPDeleteNoUserID(t *testing.T) {
 	router.ServeHTTP(w, r)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("got %d", w.Code)
 	}
 }
@@ -1664,7 +2525,7 @@ func TestTransfers__HTTPGetEventsNoUserID(t *testing.T) {
 	xfer := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xfer.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 
 	xfer.RegisterRoutes(router)
 
@@ -1673,7 +2534,7 @@ func TestTransfers__HTTPGetEventsNoUserID(t *testing.T) {
 	router.ServeHTTP(w, r)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("got %d", w.Code)
 	}
 }
@@ -1682,7 +2543,7 @@ func TestTransfers__HTTPValidateNoUserID(t *testing.T) {
 	xfer := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xfer.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 
 	xfer.RegisterRoutes(router)
 
@@ -1691,7 +2552,7 @@ func TestTransfers__HTTPValidateNoUserID(t *testing.T) {
 	router.ServeHTTP(w, r)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("got %d", w.Code)
 	}
 }
@@ -1700,7 +2561,7 @@ func TestTransfers__HTTPFilesNoUserID(t *testing.T) {
 	xfer := CreateTestTransferRouter(nil, nil, nil, nil, nil)
 	defer xfer.close()
 
-	router := mux.NewRouter()
+	router := auth.NewTestRouter()
 
 	xfer.RegisterRoutes(router)
 
@@ -1709,7 +2570,7 @@ func TestTransfers__HTTPFilesNoUserID(t *testing.T) {
 	router.ServeHTTP(w, r)
 	w.Flush()
 
-	if w.Code != http.StatusForbidden {
+	if w.Code != http.StatusUnauthorized {
 		t.Errorf("got %d", w.Code)
 	}
 }
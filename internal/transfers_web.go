@@ -67,9 +67,9 @@ func createWEBBatch(id string, userId id.User, transfer *Transfer, receiver *Rec
 	entryDetail.RDFIIdentification = aba8(receiverDep.RoutingNumber)
 	entryDetail.CheckDigit = abaCheckDigit(receiverDep.RoutingNumber)
 	entryDetail.Amount = transfer.Amount.Int()
-	entryDetail.IdentificationNumber = createIdentificationNumber()
+	entryDetail.IdentificationNumber = transfer.identificationNumber()
 	entryDetail.IndividualName = receiver.Metadata
-	entryDetail.TraceNumber = createTraceNumber(origDep.RoutingNumber)
+	entryDetail.TraceNumber = transfer.TraceNumber
 
 	if num, err := receiverDep.DecryptAccountNumber(); err != nil {
 		return nil, fmt.Errorf("WEB: receiver account number decrypt failed: %v", err)
@@ -88,7 +88,7 @@ func createWEBBatch(id string, userId id.User, transfer *Transfer, receiver *Rec
 	// Add Addenda05
 	addenda05 := ach.NewAddenda05()
 	addenda05.ID = id
-	addenda05.PaymentRelatedInformation = transfer.WEBDetail.PaymentInformation
+	addenda05.PaymentRelatedInformation = transfer.paymentRelatedInformation(transfer.WEBDetail.PaymentInformation)
 	addenda05.SequenceNumber = 1
 	addenda05.EntryDetailSequenceNumber = 1
 	entryDetail.AddAddenda05(addenda05)
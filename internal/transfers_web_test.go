@@ -83,6 +83,7 @@ func TestWEB__createWEBBatch(t *testing.T) {
 		Description:            "sending money",
 		StandardEntryClassCode: "WEB",
 		Status:                 TransferPending,
+		TraceNumber:            "231380100000001",
 		WEBDetail: &WEBDetail{
 			PaymentInformation: "test payment",
 			PaymentType:        WEBSingle,
@@ -0,0 +1,25 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// Detach returns a context carrying the values of ctx (e.g. its trace) which is never cancelled
+// and has no deadline. It's for cleanup work which must finish after ctx is done.
+func Detach(ctx context.Context) context.Context {
+	return detached{parent: ctx}
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detached) Done() <-chan struct{}               { return nil }
+func (detached) Err() error                          { return nil }
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
@@ -0,0 +1,28 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"context"
+	"testing"
+)
+
+type key struct{}
+
+func TestDetach(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	cancel()
+
+	detached := Detach(ctx)
+	if err := detached.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("unexpected deadline")
+	}
+	if v := detached.Value(key{}); v != "value" {
+		t.Errorf("unexpected value: %v", v)
+	}
+}
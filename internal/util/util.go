@@ -21,3 +21,19 @@ func Or(primary, backup string) string {
 func Yes(v string) bool {
 	return strings.EqualFold(strings.TrimSpace(v), "yes")
 }
+
+// MaskEmail keeps the first character and domain of an email address, e.g. j*******@moov.io
+func MaskEmail(email string) string {
+	idx := strings.LastIndex(email, "@")
+	if idx < 0 {
+		return maskAllButFirst(email)
+	}
+	return maskAllButFirst(email[:idx]) + email[idx:]
+}
+
+func maskAllButFirst(s string) string {
+	if len(s) <= 1 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:1] + strings.Repeat("*", len(s)-1)
+}
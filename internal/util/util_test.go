@@ -31,3 +31,17 @@ func TestYes(t *testing.T) {
 		t.Error("expected no")
 	}
 }
+
+func TestMaskEmail(t *testing.T) {
+	emails := map[string]string{
+		"john.doe@moov.io": "j*******@moov.io",
+		"j@moov.io":        "*@moov.io",
+		"invalid":          "i******",
+		"":                 "",
+	}
+	for in, expected := range emails {
+		if v := MaskEmail(in); v != expected {
+			t.Errorf("MaskEmail(%q)=%q", in, v)
+		}
+	}
+}
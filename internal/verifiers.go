@@ -0,0 +1,287 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/iav"
+	"github.com/moov-io/paygate/internal/route"
+	"github.com/moov-io/paygate/pkg/id"
+)
+
+// VerificationStrategy is how a Depository moves from DepositoryUnverified to DepositoryVerified.
+type VerificationStrategy string
+
+const (
+	// MicroDepositVerification sends two small credits which the user confirms the amounts of.
+	MicroDepositVerification VerificationStrategy = "micro-deposits"
+
+	// PrenoteVerification sends a zero dollar prenote and verifies the Depository once it
+	// goes a few banking days without being returned.
+	PrenoteVerification VerificationStrategy = "prenote"
+
+	// InstantVerification checks the Depository against the account a user linked with an
+	// instant account verification provider.
+	InstantVerification VerificationStrategy = "instant"
+)
+
+type verifyRequest struct {
+	// Amounts are the micro-deposit amounts a user is confirming.
+	Amounts []string `json:"amounts,omitempty"`
+
+	// Token is from the instant account verification provider after a user links their account.
+	Token string `json:"token,omitempty"`
+}
+
+type depositoryVerification struct {
+	Strategy VerificationStrategy `json:"strategy"`
+	Status   DepositoryStatus     `json:"status"`
+
+	// Message describes what the user needs to do next, if anything.
+	Message string `json:"message,omitempty"`
+}
+
+// Verifier implements a VerificationStrategy. Verify is called for each POST /depositories/{id}/verify
+// and a strategy can take several calls to complete, for example sending micro-deposits and then
+// confirming their amounts.
+type Verifier interface {
+	Verify(ctx context.Context, responder *route.Responder, dep *Depository, req *verifyRequest) (*depositoryVerification, error)
+}
+
+// verificationPolicy picks the VerificationStrategy for a Depository. Policies for a specific
+// user take precedence over those for a HolderType.
+type verificationPolicy struct {
+	defaultStrategy VerificationStrategy
+
+	users       map[id.User]VerificationStrategy
+	holderTypes map[HolderType]VerificationStrategy
+}
+
+func newVerificationPolicy(cfg *config.VerificationConfig) *verificationPolicy {
+	policy := &verificationPolicy{
+		defaultStrategy: VerificationStrategy(cfg.Default),
+		users:           make(map[id.User]VerificationStrategy),
+		holderTypes:     make(map[HolderType]VerificationStrategy),
+	}
+	if policy.defaultStrategy == "" {
+		policy.defaultStrategy = MicroDepositVerification
+	}
+	for userID, strategy := range cfg.Users {
+		policy.users[id.User(userID)] = VerificationStrategy(strategy)
+	}
+	for holderType, strategy := range cfg.HolderTypes {
+		policy.holderTypes[HolderType(strings.ToLower(holderType))] = VerificationStrategy(strategy)
+	}
+	return policy
+}
+
+func (p *verificationPolicy) strategy(userID id.User, dep *Depository) VerificationStrategy {
+	if p == nil {
+		return MicroDepositVerification
+	}
+	if strategy, exists := p.users[userID]; exists {
+		return strategy
+	}
+	if strategy, exists := p.holderTypes[dep.HolderType]; exists {
+		return strategy
+	}
+	return p.defaultStrategy
+}
+
+// verifyDepository moves an unverified Depository towards DepositoryVerified with the strategy chosen
+// by the router's verificationPolicy.
+func (r *DepositoryRouter) verifyDepository() http.HandlerFunc {
+	return func(w http.ResponseWriter, httpReq *http.Request) {
+		responder := route.NewResponder(r.logger, w, httpReq)
+		if responder == nil {
+			return
+		}
+
+		depID := GetDepositoryID(httpReq)
+		if depID == "" {
+			// 404 - A depository with the specified ID was not found.
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "depository not found"}`))
+			return
+		}
+
+		dep, err := r.depositoryRepo.GetUserDepository(httpReq.Context(), depID, responder.XUserID)
+		if err != nil {
+			responder.Log("verifyDepository", err)
+			responder.Problem(err)
+			return
+		}
+		if dep == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		dep.keeper = r.keeper
+		if dep.Status != DepositoryUnverified {
+			err = fmt.Errorf("depository %s in bogus status %s", dep.ID, dep.Status)
+			responder.Log("verifyDepository", err)
+			responder.Problem(err)
+			return
+		}
+
+		// The request body is optional as some steps need nothing from the user
+		var req verifyRequest
+		if err := json.NewDecoder(io.LimitReader(httpReq.Body, maxReadBytes)).Decode(&req); err != nil && err != io.EOF {
+			responder.Log("verifyDepository", fmt.Sprintf("problem reading request: %v", err))
+			responder.Problem(err)
+			return
+		}
+
+		strategy := r.verificationPolicy.strategy(responder.XUserID, dep)
+		verifier, exists := r.verifiers[strategy]
+		if !exists || verifier == nil {
+			err = fmt.Errorf("verification strategy %s is unavailable", strategy)
+			responder.Log("verifyDepository", err)
+			responder.Problem(err)
+			return
+		}
+		result, err := verifier.Verify(httpReq.Context(), responder, dep, &req)
+		if err != nil {
+			responder.Log("verifyDepository", fmt.Sprintf("%s verification of depository=%s failed: %v", strategy, dep.ID, err))
+			responder.Problem(err)
+			return
+		}
+		responder.Log("verifyDepository", fmt.Sprintf("%s verification of depository=%s is %s", strategy, dep.ID, result.Status))
+
+		responder.Respond(func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(result)
+		})
+	}
+}
+
+// microDepositVerifier sends micro-deposits when called without amounts and confirms them otherwise.
+// Expired micro-deposits are replaced with new ones.
+type microDepositVerifier struct {
+	router *DepositoryRouter
+}
+
+func (v *microDepositVerifier) Verify(ctx context.Context, responder *route.Responder, dep *Depository, req *verifyRequest) (*depositoryVerification, error) {
+	if len(req.Amounts) > 0 {
+		if err := v.router.confirmDepositoryMicroDeposits(ctx, responder, dep, req.Amounts); err != nil {
+			return nil, err
+		}
+		return &depositoryVerification{
+			Strategy: MicroDepositVerification,
+			Status:   DepositoryVerified,
+		}, nil
+	}
+
+	microDeposits, err := v.router.depositoryRepo.getMicroDepositsForUser(ctx, dep.ID, responder.XUserID)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading micro-deposits: %v", err)
+	}
+	if len(microDeposits) > 0 && !v.router.microDepositsExpired(microDeposits) {
+		return nil, errors.New("micro-deposits have already been sent, confirm their amounts")
+	}
+	if err := v.router.sendMicroDeposits(ctx, responder, dep, len(microDeposits) > 0); err != nil {
+		return nil, err
+	}
+	return &depositoryVerification{
+		Strategy: MicroDepositVerification,
+		Status:   DepositoryUnverified,
+		Message:  "micro-deposits sent, confirm their amounts once they post",
+	}, nil
+}
+
+// prenoteVerifier sends a prenote to the Depository and verifies it once bankingDays have passed
+// since the prenote was merged for upload without it being returned. Returned prenotes reject the
+// Depository when the return file is processed.
+type prenoteVerifier struct {
+	router      *DepositoryRouter
+	bankingDays int
+}
+
+func (v *prenoteVerifier) Verify(ctx context.Context, responder *route.Responder, dep *Depository, req *verifyRequest) (*depositoryVerification, error) {
+	prenote, err := v.router.depositoryRepo.getLatestPrenote(ctx, dep.ID, responder.XUserID)
+	if err != nil {
+		return nil, fmt.Errorf("problem reading prenote: %v", err)
+	}
+	if prenote == nil {
+		prenote, err = v.router.submitPrenote(ctx, responder.XUserID, responder.XRequestID, dep)
+		if err != nil {
+			return nil, fmt.Errorf("problem submitting prenote: %v", err)
+		}
+		if err := v.router.depositoryRepo.createPrenote(ctx, dep.ID, responder.XUserID, prenote); err != nil {
+			return nil, fmt.Errorf("problem storing prenote: %v", err)
+		}
+		return &depositoryVerification{
+			Strategy: PrenoteVerification,
+			Status:   DepositoryUnverified,
+			Message:  "prenote sent",
+		}, nil
+	}
+	if prenote.ReturnCode != "" {
+		return nil, fmt.Errorf("prenote was returned with %s", prenote.ReturnCode)
+	}
+	if prenote.MergedAt == nil {
+		return &depositoryVerification{
+			Strategy: PrenoteVerification,
+			Status:   DepositoryUnverified,
+			Message:  "prenote has not been uploaded yet",
+		}, nil
+	}
+
+	verifiableAt := base.NewTime(*prenote.MergedAt).AddBankingDay(v.bankingDays)
+	if time.Now().Before(verifiableAt.Time) {
+		return &depositoryVerification{
+			Strategy: PrenoteVerification,
+			Status:   DepositoryUnverified,
+			Message:  fmt.Sprintf("waiting for prenote returns until %s", verifiableAt.Format("2006-01-02")),
+		}, nil
+	}
+	if err := markDepositoryVerified(ctx, v.router.depositoryRepo, dep.ID, responder.XUserID); err != nil {
+		return nil, err
+	}
+	return &depositoryVerification{
+		Strategy: PrenoteVerification,
+		Status:   DepositoryVerified,
+	}, nil
+}
+
+// instantVerifier compares a Depository against the account a user linked with the instant account
+// verification provider.
+type instantVerifier struct {
+	router *DepositoryRouter
+	client iav.Client
+}
+
+func (v *instantVerifier) Verify(ctx context.Context, responder *route.Responder, dep *Depository, req *verifyRequest) (*depositoryVerification, error) {
+	if req.Token == "" {
+		return nil, errors.New("missing instant account verification token")
+	}
+	acct, err := v.client.LookupAccount(ctx, responder.XRequestID, responder.XUserID, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	num, err := dep.DecryptAccountNumber()
+	if err != nil {
+		return nil, fmt.Errorf("problem decrypting account number: %v", err)
+	}
+	if acct.RoutingNumber != dep.RoutingNumber || acct.AccountNumber != num {
+		return nil, errors.New("linked account does not match depository")
+	}
+	if err := markDepositoryVerified(ctx, v.router.depositoryRepo, dep.ID, responder.XUserID); err != nil {
+		return nil, err
+	}
+	return &depositoryVerification{
+		Strategy: InstantVerification,
+		Status:   DepositoryVerified,
+	}, nil
+}
@@ -0,0 +1,272 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	accounts "github.com/moov-io/accounts/client"
+	"github.com/moov-io/base"
+	"github.com/moov-io/paygate/internal/auth"
+	"github.com/moov-io/paygate/internal/config"
+	"github.com/moov-io/paygate/internal/database"
+	"github.com/moov-io/paygate/internal/events"
+	"github.com/moov-io/paygate/internal/fed"
+	"github.com/moov-io/paygate/internal/iav"
+	"github.com/moov-io/paygate/internal/secrets"
+	"github.com/moov-io/paygate/pkg/achclient"
+	"github.com/moov-io/paygate/pkg/id"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestVerificationPolicy(t *testing.T) {
+	policy := newVerificationPolicy(&config.VerificationConfig{
+		Default:     "prenote",
+		Users:       map[string]string{"jane": "micro-deposits"},
+		HolderTypes: map[string]string{"Business": "instant"},
+	})
+
+	business, individual := &Depository{HolderType: Business}, &Depository{HolderType: Individual}
+	if v := policy.strategy("john", business); v != InstantVerification {
+		t.Errorf("got %s", v)
+	}
+	if v := policy.strategy("john", individual); v != PrenoteVerification {
+		t.Errorf("got %s", v)
+	}
+	if v := policy.strategy("jane", business); v != MicroDepositVerification {
+		t.Errorf("got %s", v)
+	}
+
+	// an empty policy uses micro-deposits
+	if v := newVerificationPolicy(&config.VerificationConfig{}).strategy("john", individual); v != MicroDepositVerification {
+		t.Errorf("got %s", v)
+	}
+	var nilPolicy *verificationPolicy
+	if v := nilPolicy.strategy("john", individual); v != MicroDepositVerification {
+		t.Errorf("got %s", v)
+	}
+}
+
+type verifyTest struct {
+	db      *database.TestSQLiteDB
+	depRepo *SQLDepositoryRepo
+	dep     *Depository
+	userID  id.User
+
+	handler *mux.Router
+}
+
+func setupVerifyTest(t *testing.T, cfg *config.Config, iavClient iav.Client) (*verifyTest, func()) {
+	t.Helper()
+
+	db := database.CreateTestSqliteDB(t)
+	keeper := secrets.TestStringKeeper(t)
+	depRepo := NewDepositoryRepo(log.NewNopLogger(), db.DB, keeper)
+
+	userID := id.User(base.ID())
+	num, _ := keeper.EncryptString("151")
+	dep := &Depository{
+		ID:                     id.Depository(base.ID()),
+		BankName:               "bank name",
+		Holder:                 "holder",
+		HolderType:             Individual,
+		Type:                   Checking,
+		RoutingNumber:          "121042882",
+		EncryptedAccountNumber: num,
+		Status:                 DepositoryUnverified,
+		Created:                base.NewTime(time.Now().Add(-1 * time.Second)),
+		keeper:                 keeper,
+	}
+	if err := depRepo.UpsertUserDepository(context.Background(), userID, dep); err != nil {
+		t.Fatal(err)
+	}
+
+	accountsClient := &testAccountsClient{
+		accounts:    []accounts.Account{{ID: base.ID()}},
+		transaction: &accounts.Transaction{ID: base.ID()},
+	}
+	achClient, _, server := achclient.MockClientServer("verify", func(r *mux.Router) {
+		achclient.AddCreateRoute(nil, r)
+		achclient.AddValidateRoute(r)
+	})
+
+	odfiAccount := makeTestODFIAccount()
+	odfiAccount.keeper = keeper
+
+	router := NewDepositoryRouter(cfg, odfiAccount, accountsClient, achClient, &fed.TestClient{}, iavClient, depRepo, &events.TestRepository{}, NewTraceNumberRepo(log.NewNopLogger(), db.DB), keeper)
+	handler := auth.NewTestRouter()
+	router.RegisterRoutes(handler)
+
+	vt := &verifyTest{db: db, depRepo: depRepo, dep: dep, userID: userID, handler: handler}
+	return vt, func() {
+		server.Close()
+		db.Close()
+	}
+}
+
+func (vt *verifyTest) verify(t *testing.T, body string) (int, *depositoryVerification, string) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", fmt.Sprintf("/depositories/%s/verify", vt.dep.ID), strings.NewReader(body))
+	req.Header.Set("x-user-id", vt.userID.String())
+	vt.handler.ServeHTTP(w, req)
+	w.Flush()
+
+	var result depositoryVerification
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w.Code, &result, w.Body.String()
+}
+
+func TestDepositories__verifyMicroDeposits(t *testing.T) {
+	cfg := config.Empty()
+	vt, cleanup := setupVerifyTest(t, cfg, nil)
+	defer cleanup()
+
+	// send micro-deposits
+	code, result, _ := vt.verify(t, "")
+	if code != http.StatusOK || result.Strategy != MicroDepositVerification || result.Status != DepositoryUnverified {
+		t.Fatalf("got %d: %#v", code, result)
+	}
+	microDeposits, err := vt.depRepo.getMicroDepositsForUser(context.Background(), vt.dep.ID, vt.userID)
+	if err != nil || len(microDeposits) != 2 {
+		t.Fatalf("got %d micro-deposits: %v", len(microDeposits), err)
+	}
+
+	// they aren't sent twice
+	if code, _, body := vt.verify(t, "{}"); code != http.StatusBadRequest || !strings.Contains(body, "already been sent") {
+		t.Errorf("got %d: %s", code, body)
+	}
+
+	// confirm their amounts
+	body := fmt.Sprintf(`{"amounts": ["%s", "%s"]}`, microDeposits[0].Amount.String(), microDeposits[1].Amount.String())
+	code, result, _ = vt.verify(t, body)
+	if code != http.StatusOK || result.Status != DepositoryVerified {
+		t.Fatalf("got %d: %#v", code, result)
+	}
+	if dep, _ := vt.depRepo.GetUserDepository(context.Background(), vt.dep.ID, vt.userID); dep.Status != DepositoryVerified {
+		t.Errorf("unexpected status: %s", dep.Status)
+	}
+
+	// verified Depositories are rejected
+	if code, _, _ := vt.verify(t, ""); code != http.StatusBadRequest {
+		t.Errorf("got %d", code)
+	}
+}
+
+func TestDepositories__verifyPrenote(t *testing.T) {
+	cfg := config.Empty()
+	cfg.Verification.Default = "prenote"
+	cfg.Verification.PrenoteBankingDays = 3
+	vt, cleanup := setupVerifyTest(t, cfg, nil)
+	defer cleanup()
+
+	// send a prenote
+	code, result, _ := vt.verify(t, "")
+	if code != http.StatusOK || result.Strategy != PrenoteVerification || result.Status != DepositoryUnverified {
+		t.Fatalf("got %d: %#v", code, result)
+	}
+	prenote, err := vt.depRepo.getLatestPrenote(context.Background(), vt.dep.ID, vt.userID)
+	if err != nil || prenote == nil {
+		t.Fatalf("prenote=%#v error=%v", prenote, err)
+	}
+	if prenote.FileID == "" || prenote.TraceNumber != "121042880000001" {
+		t.Errorf("unexpected prenote: %#v", prenote)
+	}
+
+	// waiting for the prenote to be merged
+	code, result, _ = vt.verify(t, "")
+	if code != http.StatusOK || result.Status != DepositoryUnverified || !strings.Contains(result.Message, "not been uploaded") {
+		t.Fatalf("got %d: %#v", code, result)
+	}
+
+	// then for its returns
+	if err := vt.depRepo.MarkPrenoteAsMerged(context.Background(), "filename", UploadablePrenote{DepositoryID: string(vt.dep.ID), TraceNumber: prenote.TraceNumber}); err != nil {
+		t.Fatal(err)
+	}
+	code, result, _ = vt.verify(t, "")
+	if code != http.StatusOK || result.Status != DepositoryUnverified || !strings.Contains(result.Message, "waiting for prenote returns") {
+		t.Fatalf("got %d: %#v", code, result)
+	}
+
+	// after the waiting period the Depository is verified
+	if _, err := vt.db.DB.Exec(`update depository_prenotes set merged_at = ?`, time.Now().Add(-14*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	code, result, _ = vt.verify(t, "")
+	if code != http.StatusOK || result.Status != DepositoryVerified {
+		t.Fatalf("got %d: %#v", code, result)
+	}
+}
+
+func TestDepositories__verifyPrenoteReturned(t *testing.T) {
+	cfg := config.Empty()
+	cfg.Verification.Default = "prenote"
+	vt, cleanup := setupVerifyTest(t, cfg, nil)
+	defer cleanup()
+
+	if code, _, body := vt.verify(t, ""); code != http.StatusOK {
+		t.Fatalf("got %d: %s", code, body)
+	}
+	prenote, _ := vt.depRepo.getLatestPrenote(context.Background(), vt.dep.ID, vt.userID)
+	if err := vt.depRepo.SetPrenoteReturnCode(context.Background(), vt.dep.ID, prenote.TraceNumber, "R03"); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, body := vt.verify(t, ""); code != http.StatusBadRequest || !strings.Contains(body, "R03") {
+		t.Errorf("got %d: %s", code, body)
+	}
+}
+
+func TestDepositories__verifyInstant(t *testing.T) {
+	stub := iav.NewStub()
+	stub.Link("good", &iav.Account{RoutingNumber: "121042882", AccountNumber: "151"})
+	stub.Link("other", &iav.Account{RoutingNumber: "121042882", AccountNumber: "152"})
+	svc := httptest.NewServer(stub)
+	defer svc.Close()
+
+	cfg := config.Empty()
+	cfg.Verification.HolderTypes = map[string]string{"individual": "instant"}
+	vt, cleanup := setupVerifyTest(t, cfg, iav.NewClient(log.NewNopLogger(), svc.URL, nil))
+	defer cleanup()
+
+	if code, _, body := vt.verify(t, ""); code != http.StatusBadRequest || !strings.Contains(body, "missing instant account verification token") {
+		t.Errorf("got %d: %s", code, body)
+	}
+	if code, _, body := vt.verify(t, `{"token": "unknown"}`); code != http.StatusBadRequest {
+		t.Errorf("got %d: %s", code, body)
+	}
+	if code, _, body := vt.verify(t, `{"token": "other"}`); code != http.StatusBadRequest || !strings.Contains(body, "does not match") {
+		t.Errorf("got %d: %s", code, body)
+	}
+
+	code, result, _ := vt.verify(t, `{"token": "good"}`)
+	if code != http.StatusOK || result.Strategy != InstantVerification || result.Status != DepositoryVerified {
+		t.Fatalf("got %d: %#v", code, result)
+	}
+}
+
+func TestDepositories__verifyUnavailable(t *testing.T) {
+	cfg := config.Empty()
+	cfg.Verification.Default = "instant"
+	vt, cleanup := setupVerifyTest(t, cfg, nil)
+	defer cleanup()
+
+	if code, _, body := vt.verify(t, `{"token": "good"}`); code != http.StatusBadRequest || !strings.Contains(body, "unavailable") {
+		t.Errorf("got %d: %s", code, body)
+	}
+}
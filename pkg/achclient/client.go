@@ -5,6 +5,7 @@
 package achclient
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -74,8 +75,8 @@ type ACH struct {
 }
 
 // Ping makes an HTTP GET /ping request to the ACH service and returns any errors encountered.
-func (a *ACH) Ping() error {
-	resp, err := a.GET("/ping")
+func (a *ACH) Ping(ctx context.Context) error {
+	resp, err := a.GET(ctx, "/ping")
 	if err != nil {
 		return fmt.Errorf("error getting /ping from ACH service: %v", err)
 	}
@@ -117,8 +118,8 @@ func (a *ACH) addRequestHeaders(idempotencyKey, requestID string, r *http.Reques
 // GET performs a HTTP GET request against the a.endpoint and relPath.
 // Retries are supported and handled within this method, so if you can't block
 // run this method in a goroutine.
-func (a *ACH) GET(relPath string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", a.buildAddress(relPath), nil)
+func (a *ACH) GET(ctx context.Context, relPath string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", a.buildAddress(relPath), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,8 +132,8 @@ func (a *ACH) GET(relPath string) (*http.Response, error) {
 	return resp, nil
 }
 
-func (a *ACH) DELETE(relPath string) (*http.Response, error) {
-	req, err := http.NewRequest("DELETE", a.buildAddress(relPath), nil)
+func (a *ACH) DELETE(ctx context.Context, relPath string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", a.buildAddress(relPath), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -149,8 +150,8 @@ func (a *ACH) DELETE(relPath string) (*http.Response, error) {
 // Retries are supported only if idempotencyKey is non-empty, otherwise only one attempt is made.
 //
 // This method assumes a non-nil body is JSON.
-func (a *ACH) POST(relPath string, idempotencyKey string, body io.ReadCloser) (*http.Response, error) {
-	req, err := http.NewRequest("POST", a.buildAddress(relPath), body)
+func (a *ACH) POST(ctx context.Context, relPath string, idempotencyKey string, body io.ReadCloser) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", a.buildAddress(relPath), body)
 	if err != nil {
 		return nil, err
 	}
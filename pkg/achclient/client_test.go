@@ -5,6 +5,7 @@
 package achclient
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -19,16 +20,23 @@ func TestACH__pingRoute(t *testing.T) {
 	defer server.Close()
 
 	// Make our ping request
-	if err := achClient.Ping(); err != nil {
+	if err := achClient.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
+
+	// requests aren't made once their context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := achClient.Ping(ctx); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("unexpected error: %v", err)
+	}
 }
 
 func TestACH__delete(t *testing.T) {
 	achClient, _, server := MockClientServer("delete", AddDeleteRoute)
 	defer server.Close()
 
-	resp, err := achClient.DELETE("/files/delete")
+	resp, err := achClient.DELETE(context.Background(), "/files/delete")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -50,7 +58,7 @@ func TestACH__post(t *testing.T) {
 
 	body := strings.NewReader(`{"id": "foo"}`) // partial ach.File JSON
 
-	resp, err := achClient.POST("/files/create", "unique", ioutil.NopCloser(body))
+	resp, err := achClient.POST(context.Background(), "/files/create", "unique", ioutil.NopCloser(body))
 	if err != nil {
 		t.Fatal(err)
 	}
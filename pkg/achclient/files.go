@@ -6,6 +6,7 @@ package achclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -24,12 +25,12 @@ type createFileResponse struct {
 //
 // These Files have many fields associated, but this method performs no validation. However, the
 // ACH service might return an error that callers should check.
-func (a *ACH) CreateFile(idempotencyKey string, req *ach.File) (string, error) {
+func (a *ACH) CreateFile(ctx context.Context, idempotencyKey string, req *ach.File) (string, error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(&req); err != nil || buf.Len() == 0 {
 		return "", fmt.Errorf("CreateFile: file ID %s json encoding error: %v", req.ID, err)
 	}
-	resp, err := a.POST("/files/create", idempotencyKey, ioutil.NopCloser(&buf))
+	resp, err := a.POST(ctx, "/files/create", idempotencyKey, ioutil.NopCloser(&buf))
 	if err != nil {
 		return "", fmt.Errorf("CreateFile: error file ID %s : %v", req.ID, err)
 	}
@@ -52,8 +53,8 @@ type validateFileResponse struct {
 
 // ValidateFile makes an HTTP request to our ACH service which performs checks on the
 // file to ensure correctness.
-func (a *ACH) ValidateFile(fileId string) error {
-	resp, err := a.GET(fmt.Sprintf("/files/%s/validate", fileId))
+func (a *ACH) ValidateFile(ctx context.Context, fileId string) error {
+	resp, err := a.GET(ctx, fmt.Sprintf("/files/%s/validate", fileId))
 	defer func() {
 		if resp != nil && resp.Body != nil {
 			resp.Body.Close()
@@ -76,8 +77,8 @@ func (a *ACH) ValidateFile(fileId string) error {
 	return nil
 }
 
-func (a *ACH) GetFile(fileId string) (*ach.File, error) {
-	resp, err := a.GET(fmt.Sprintf("/files/%s", fileId))
+func (a *ACH) GetFile(ctx context.Context, fileId string) (*ach.File, error) {
+	resp, err := a.GET(ctx, fmt.Sprintf("/files/%s", fileId))
 	if err != nil {
 		return nil, fmt.Errorf("GetFile: error making HTTP request: %v", err)
 	}
@@ -96,8 +97,8 @@ func (a *ACH) GetFile(fileId string) (*ach.File, error) {
 }
 
 // GetFileContents makes an HTTP request to our ACH service and returns the plaintext ACH file.
-func (a *ACH) GetFileContents(fileId string) (*bytes.Buffer, error) {
-	resp, err := a.GET(fmt.Sprintf("/files/%s/contents", fileId))
+func (a *ACH) GetFileContents(ctx context.Context, fileId string) (*bytes.Buffer, error) {
+	resp, err := a.GET(ctx, fmt.Sprintf("/files/%s/contents", fileId))
 	if err != nil {
 		return nil, fmt.Errorf("GetFileContents: error making HTTP request: %v", err)
 	}
@@ -112,8 +113,8 @@ func (a *ACH) GetFileContents(fileId string) (*bytes.Buffer, error) {
 }
 
 // DeleteFile makes an HTTP request to our ACH service to delete a file.
-func (a *ACH) DeleteFile(fileId string) error {
-	resp, err := a.DELETE(fmt.Sprintf("/files/%s", fileId))
+func (a *ACH) DeleteFile(ctx context.Context, fileId string) error {
+	resp, err := a.DELETE(ctx, fmt.Sprintf("/files/%s", fileId))
 	if resp != nil && resp.StatusCode == http.StatusNotFound {
 		resp.Body.Close()
 		return nil // ach.File not found
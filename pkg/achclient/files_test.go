@@ -5,6 +5,7 @@
 package achclient
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -38,7 +39,7 @@ func TestFiles__CreateFile(t *testing.T) {
 
 	id := file.ID
 
-	fileId, err := achClient.CreateFile("unique", file)
+	fileId, err := achClient.CreateFile(context.Background(), "unique", file)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -111,12 +112,12 @@ func TestFiles__DeleteFile(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := achClient.CreateFile("create", file); err != nil {
+	if _, err := achClient.CreateFile(context.Background(), "create", file); err != nil {
 		t.Fatal(err)
 	}
 
 	// Delete File
-	if err := achClient.DeleteFile("delete"); err != nil {
+	if err := achClient.DeleteFile(context.Background(), "delete"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -132,7 +133,7 @@ func TestFiles__Delete404(t *testing.T) {
 	defer server.Close()
 
 	// Delete File (expect no error though)
-	if err := achClient.DeleteFile("delete"); err != nil {
+	if err := achClient.DeleteFile(context.Background(), "delete"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -143,7 +144,7 @@ func TestFiles__GetFile(t *testing.T) {
 	})
 	defer server.Close()
 
-	file, err := achClient.GetFile("fileId")
+	file, err := achClient.GetFile(context.Background(), "fileId")
 	if err != nil || file == nil {
 		t.Fatalf("file=%v err=%v", file, err)
 	}